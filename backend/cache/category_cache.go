@@ -6,11 +6,12 @@ import (
 
 	"github.com/macar-x/cashlens/model"
 	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // CategoryCache provides thread-safe in-memory caching for categories
 type CategoryCache struct {
-	byName    map[string]*model.CategoryEntity
+	byName    map[string]*model.CategoryEntity // keyed by owner and name, see nameKey
 	byID      map[string]*model.CategoryEntity
 	mu        sync.RWMutex
 	hits      int64
@@ -38,24 +39,29 @@ func GetCategoryCache() *CategoryCache {
 	return instance
 }
 
-// GetByName retrieves a category by name from cache
-func (c *CategoryCache) GetByName(name string) (*model.CategoryEntity, bool) {
+// nameKey keys a category by its owner and name, as every owner has categories of their own
+func nameKey(ownerId primitive.ObjectID, name string) string {
+	return ownerId.Hex() + "/" + name
+}
+
+// GetByName retrieves an owner's category by name from cache
+func (c *CategoryCache) GetByName(ownerPlainId, name string) (*model.CategoryEntity, bool) {
 	if !c.enabled {
 		return nil, false
 	}
 
 	c.mu.RLock()
-	entity, ok := c.byName[name]
+	entity, ok := c.byName[nameKey(util.ConvertOwner2ObjectId(ownerPlainId), name)]
 	c.mu.RUnlock()
 
 	// Update stats outside of read lock to avoid race
 	c.mu.Lock()
 	if ok {
 		c.hits++
-		util.Logger.Debugw("Category cache hit", "name", name)
+		util.Logger.Debugw("Category cache hit", "owner", ownerPlainId, "name", name)
 	} else {
 		c.misses++
-		util.Logger.Debugw("Category cache miss", "name", name)
+		util.Logger.Debugw("Category cache miss", "owner", ownerPlainId, "name", name)
 	}
 	c.mu.Unlock()
 
@@ -93,21 +99,22 @@ func (c *CategoryCache) Set(entity *model.CategoryEntity) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.byName[entity.Name] = entity
+	c.byName[nameKey(entity.OwnerId, entity.Name)] = entity
 	c.byID[entity.Id.Hex()] = entity
 	util.Logger.Debugw("Category cached", "name", entity.Name, "id", entity.Id.Hex())
 }
 
-// Invalidate removes a category from cache by name
-func (c *CategoryCache) Invalidate(name string) {
+// Invalidate removes an owner's category from cache by name
+func (c *CategoryCache) Invalidate(ownerPlainId, name string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if entity, ok := c.byName[name]; ok {
+	key := nameKey(util.ConvertOwner2ObjectId(ownerPlainId), name)
+	if entity, ok := c.byName[key]; ok {
 		delete(c.byID, entity.Id.Hex())
-		util.Logger.Debugw("Category invalidated", "name", name)
+		util.Logger.Debugw("Category invalidated", "owner", ownerPlainId, "name", name)
 	}
-	delete(c.byName, name)
+	delete(c.byName, key)
 }
 
 // InvalidateByID removes a category from cache by ID
@@ -116,7 +123,7 @@ func (c *CategoryCache) InvalidateByID(id string) {
 	defer c.mu.Unlock()
 
 	if entity, ok := c.byID[id]; ok {
-		delete(c.byName, entity.Name)
+		delete(c.byName, nameKey(entity.OwnerId, entity.Name))
 		util.Logger.Debugw("Category invalidated", "id", id)
 	}
 	delete(c.byID, id)
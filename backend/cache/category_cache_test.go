@@ -20,7 +20,7 @@ func TestCategoryCache_SetAndGet(t *testing.T) {
 	cache.Set(entity)
 
 	// Get by name
-	retrieved, ok := cache.GetByName("TestCategory")
+	retrieved, ok := cache.GetByName("", "TestCategory")
 	if !ok {
 		t.Error("Expected to find category by name")
 	}
@@ -50,21 +50,54 @@ func TestCategoryCache_Invalidate(t *testing.T) {
 	cache.Set(entity)
 
 	// Verify it's cached
-	_, ok := cache.GetByName("TestCategory")
+	_, ok := cache.GetByName("", "TestCategory")
 	if !ok {
 		t.Error("Expected category to be cached")
 	}
 
 	// Invalidate
-	cache.Invalidate("TestCategory")
+	cache.Invalidate("", "TestCategory")
 
 	// Verify it's removed
-	_, ok = cache.GetByName("TestCategory")
+	_, ok = cache.GetByName("", "TestCategory")
 	if ok {
 		t.Error("Expected category to be removed from cache")
 	}
 }
 
+func TestCategoryCache_OwnerKeys(t *testing.T) {
+	cache := GetCategoryCache()
+	cache.Clear()
+
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	aliceFood := &model.CategoryEntity{Id: primitive.NewObjectID(), OwnerId: alice, Name: "Food"}
+	bobFood := &model.CategoryEntity{Id: primitive.NewObjectID(), OwnerId: bob, Name: "Food"}
+	cache.Set(aliceFood)
+	cache.Set(bobFood)
+
+	// Same name, one entry per owner
+	retrieved, ok := cache.GetByName(alice.Hex(), "Food")
+	if !ok || retrieved.Id != aliceFood.Id {
+		t.Error("Expected alice's category")
+	}
+	retrieved, ok = cache.GetByName(bob.Hex(), "Food")
+	if !ok || retrieved.Id != bobFood.Id {
+		t.Error("Expected bob's category")
+	}
+	if _, ok := cache.GetByName("", "Food"); ok {
+		t.Error("Expected no category without owner")
+	}
+
+	// Invalidating one owner's category keeps the other's
+	cache.InvalidateByID(aliceFood.Id.Hex())
+	if _, ok := cache.GetByName(alice.Hex(), "Food"); ok {
+		t.Error("Expected alice's category to be removed from cache")
+	}
+	if _, ok := cache.GetByName(bob.Hex(), "Food"); !ok {
+		t.Error("Expected bob's category to stay cached")
+	}
+}
+
 func TestCategoryCache_Clear(t *testing.T) {
 	cache := GetCategoryCache()
 	cache.Clear()
@@ -103,9 +136,9 @@ func TestCategoryCache_Stats(t *testing.T) {
 	cache.Set(entity)
 
 	// Generate some hits and misses
-	cache.GetByName("TestCategory") // hit
-	cache.GetByName("TestCategory") // hit
-	cache.GetByName("NonExistent")  // miss
+	cache.GetByName("", "TestCategory") // hit
+	cache.GetByName("", "TestCategory") // hit
+	cache.GetByName("", "NonExistent")  // miss
 
 	stats := cache.GetStats()
 
@@ -138,7 +171,7 @@ func TestCategoryCache_Disable(t *testing.T) {
 	cache.Disable()
 
 	// Try to get - should return false
-	_, ok := cache.GetByName("TestCategory")
+	_, ok := cache.GetByName("", "TestCategory")
 	if ok {
 		t.Error("Expected cache to be disabled")
 	}
@@ -164,7 +197,7 @@ func TestCategoryCache_Singleton(t *testing.T) {
 	}
 	cache1.Set(entity)
 
-	retrieved, ok := cache2.GetByName("SingletonTest")
+	retrieved, ok := cache2.GetByName("", "SingletonTest")
 	if !ok {
 		t.Error("Expected to find category set via cache1 in cache2")
 	}
@@ -209,13 +242,13 @@ func TestCategoryCache_ConcurrentAccess(t *testing.T) {
 				switch j % 4 {
 				case 0: // Read
 					idx := j % len(entities)
-					_, _ = cache.GetByName(entities[idx].Name)
+					_, _ = cache.GetByName("", entities[idx].Name)
 				case 1: // Write
 					idx := j % len(entities)
 					cache.Set(entities[idx])
 				case 2: // Invalidate
 					idx := j % len(entities)
-					cache.Invalidate(entities[idx].Name)
+					cache.Invalidate("", entities[idx].Name)
 				case 3: // Get stats
 					_ = cache.GetStats()
 				}
@@ -252,7 +285,7 @@ func TestCategoryCache_InvalidationAcrossThreads(t *testing.T) {
 	cache.Set(entity)
 
 	// Verify it's cached
-	_, ok := cache.GetByName("SharedCategory")
+	_, ok := cache.GetByName("", "SharedCategory")
 	if !ok {
 		t.Error("Expected category to be cached")
 	}
@@ -260,13 +293,13 @@ func TestCategoryCache_InvalidationAcrossThreads(t *testing.T) {
 	// Invalidate from one goroutine
 	done := make(chan bool)
 	go func() {
-		cache.Invalidate("SharedCategory")
+		cache.Invalidate("", "SharedCategory")
 		done <- true
 	}()
 	<-done
 
 	// Verify invalidation is visible from main goroutine
-	_, ok = cache.GetByName("SharedCategory")
+	_, ok = cache.GetByName("", "SharedCategory")
 	if ok {
 		t.Error("Expected category to be invalidated across goroutines")
 	}
@@ -307,7 +340,7 @@ func TestCategoryCache_ClearAcrossThreads(t *testing.T) {
 	// Verify all categories are gone
 	for i := 0; i < 5; i++ {
 		name := "Category" + string(rune('A'+i))
-		_, ok := cache.GetByName(name)
+		_, ok := cache.GetByName("", name)
 		if ok {
 			t.Errorf("Expected category %s to be cleared", name)
 		}
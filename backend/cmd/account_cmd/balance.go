@@ -0,0 +1,55 @@
+package account_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/account_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var balanceCmd = &cobra.Command{
+	Use:   "balance",
+	Short: "show balance per account",
+	Long: `Show what each account holds: its opening balance plus income minus expenses.
+Pass --id or --name to show a single account.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var balanceList []account_service.AccountBalance
+		if plainId != "" || accountName != "" {
+			accountBalance, err := account_service.GetBalanceService(util.GetConfigByKey("cli.owner.id"), plainId, accountName)
+			if err != nil {
+				return err
+			}
+			balanceList = append(balanceList, accountBalance)
+		} else {
+			var err error
+			balanceList, err = account_service.GetBalancesService(util.GetConfigByKey("cli.owner.id"))
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(balanceList) == 0 {
+			fmt.Println("No accounts found")
+			return nil
+		}
+
+		fmt.Println("=== Account Balances ===")
+		for _, accountBalance := range balanceList {
+			fmt.Printf("\n%s (%s, %s)\n", accountBalance.AccountName, accountBalance.AccountType, accountBalance.Currency)
+			fmt.Printf("  Opening Balance: %s\n", accountBalance.OpeningBalance.StringFixed(2))
+			fmt.Printf("  Income:          %s\n", accountBalance.TotalIncome.StringFixed(2))
+			fmt.Printf("  Expenses:        %s\n", accountBalance.TotalExpense.StringFixed(2))
+			fmt.Printf("  Balance:         %s (%d cash_flows)\n", accountBalance.Balance.StringFixed(2), accountBalance.CashFlowCount)
+		}
+		return nil
+	},
+}
+
+func init() {
+	balanceCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "show a single account by id (optional)")
+	balanceCmd.Flags().StringVarP(
+		&accountName, "name", "n", "", "show a single account by name (optional)")
+	AccountCmd.AddCommand(balanceCmd)
+}
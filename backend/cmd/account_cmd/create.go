@@ -0,0 +1,40 @@
+package account_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/account_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create new account",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountEntity, err := account_service.CreateService(util.GetConfigByKey("cli.owner.id"),
+			accountName, accountType, currency, decimal.NewFromFloat(openingBalance), remark)
+		if err != nil {
+			return err
+		}
+		fmt.Println("account ", 0, ": ", accountEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	createCmd.Flags().StringVarP(
+		&accountName, "name", "n", "", "account's name (required)")
+	createCmd.Flags().StringVarP(
+		&accountType, "type", "t", "", "account's type: BANK, CARD, CASH or OTHER (optional, default OTHER)")
+	createCmd.Flags().Float64VarP(
+		&openingBalance, "opening-balance", "o", 0.00, "balance before the first recorded cash_flow (optional)")
+	createCmd.Flags().StringVar(
+		&currency, "currency", "", "account's currency code (optional, default currency when blank)")
+	createCmd.Flags().StringVarP(
+		&remark, "remark", "r", "", "account's remark (optional)")
+
+	createCmd.MarkFlagRequired("name")
+	AccountCmd.AddCommand(createCmd)
+}
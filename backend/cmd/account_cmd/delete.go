@@ -0,0 +1,32 @@
+package account_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/account_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "delete account",
+	Long: `Delete an account by its ID.
+An account that cash_flows still refer to can not be deleted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountEntity, err := account_service.DeleteService(util.GetConfigByKey("cli.owner.id"), plainId)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Deleted account:", accountEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	deleteCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "account id (required)")
+
+	deleteCmd.MarkFlagRequired("id")
+	AccountCmd.AddCommand(deleteCmd)
+}
@@ -0,0 +1,35 @@
+package account_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/account_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list all accounts",
+	Long:  `List all accounts in the system.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountEntityList, totalCount, err := account_service.ListAllService(util.GetConfigByKey("cli.owner.id"), 0, 0)
+		if err != nil {
+			return err
+		}
+
+		if len(accountEntityList) == 0 {
+			fmt.Println("No accounts found")
+			return nil
+		}
+		for index, accountEntity := range accountEntityList {
+			fmt.Println("account ", index, ": ", accountEntity.ToString())
+		}
+		fmt.Printf("\nTotal accounts: %d\n", totalCount)
+		return nil
+	},
+}
+
+func init() {
+	AccountCmd.AddCommand(listCmd)
+}
@@ -0,0 +1,30 @@
+package account_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/account_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "query for account data",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountEntity, err := account_service.QueryService(util.GetConfigByKey("cli.owner.id"), plainId, accountName)
+		if err != nil {
+			return err
+		}
+		fmt.Println("account ", 0, ": ", accountEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	queryCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "query by id")
+	queryCmd.Flags().StringVarP(
+		&accountName, "name", "n", "", "query by name")
+	AccountCmd.AddCommand(queryCmd)
+}
@@ -0,0 +1,34 @@
+package account_cmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	plainId        string
+	accountName    string
+	accountType    string
+	openingBalance float64
+	currency       string
+	remark         string
+)
+
+var AccountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "manage bank accounts, cards and wallets",
+	Long: `Manage the accounts money moves through (bank accounts, cards, cash wallets).
+
+Available sub-commands:
+  create  - Create new account
+  update  - Update existing account
+  delete  - Delete account
+  query   - Query account by id or name
+  list    - List all accounts
+  balance - Show balance per account`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.New("must provide a valid sub command")
+	},
+}
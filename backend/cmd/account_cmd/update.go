@@ -0,0 +1,57 @@
+package account_cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/account_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "update existing account",
+	Long: `Update an existing account by its ID.
+You can update the name, type, currency, opening balance and remark.
+The currency can only change while no cash_flow refers to the account.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// zero is a valid opening balance, so only pass it on when the flag is given
+		var newOpeningBalance *decimal.Decimal
+		if cmd.Flags().Changed("opening-balance") {
+			value := decimal.NewFromFloat(openingBalance)
+			newOpeningBalance = &value
+		}
+
+		if accountName == "" && accountType == "" && currency == "" && newOpeningBalance == nil && remark == "" {
+			return errors.New("at least one field to update must be provided (name, type, currency, opening-balance or remark)")
+		}
+
+		accountEntity, err := account_service.UpdateService(util.GetConfigByKey("cli.owner.id"), plainId, accountName, accountType, currency, newOpeningBalance, remark)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Updated account:", accountEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	updateCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "account id (required)")
+	updateCmd.Flags().StringVarP(
+		&accountName, "name", "n", "", "new account name (optional)")
+	updateCmd.Flags().StringVarP(
+		&accountType, "type", "t", "", "new account type (optional)")
+	updateCmd.Flags().Float64VarP(
+		&openingBalance, "opening-balance", "o", 0.00, "new opening balance (optional)")
+	updateCmd.Flags().StringVar(
+		&currency, "currency", "", "new currency code (optional)")
+	updateCmd.Flags().StringVarP(
+		&remark, "remark", "r", "", "new remark (optional)")
+
+	updateCmd.MarkFlagRequired("id")
+	AccountCmd.AddCommand(updateCmd)
+}
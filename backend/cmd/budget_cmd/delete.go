@@ -0,0 +1,31 @@
+package budget_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/budget_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "delete budget",
+	Long:  `Delete a budget by its ID, the cash_flows it tracked are kept.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		budgetEntity, err := budget_service.DeleteService(util.GetConfigByKey("cli.owner.id"), plainId)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Deleted budget:", budgetEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	deleteCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "budget id (required)")
+
+	deleteCmd.MarkFlagRequired("id")
+	BudgetCmd.AddCommand(deleteCmd)
+}
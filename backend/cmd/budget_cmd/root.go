@@ -0,0 +1,33 @@
+package budget_cmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	plainId      string
+	categoryName string
+	period       string
+	limit        float64
+	currency     string
+	rollover     bool
+	startDate    string
+	statusDate   string
+)
+
+var BudgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "manage spending limits per category",
+	Long: `Manage budgets: the most a category, its subcategories included, may spend per month or year.
+
+Available sub-commands:
+  set    - Create or replace the budget of a category and period
+  status - Show spent, remaining and percent used for the current period
+  delete - Delete budget`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.New("must provide a valid sub command")
+	},
+}
@@ -0,0 +1,52 @@
+package budget_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/budget_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var setCmd = &cobra.Command{
+	Use:   "set",
+	Short: "create or replace a budget",
+	Long: `Set the limit of a category for every month or year.
+Setting the same category and period again replaces the budget and keeps its start date.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		budgetEntity, err := budget_service.SetService(util.GetConfigByKey("cli.owner.id"), model.BudgetDTO{
+			CategoryName: categoryName,
+			Period:       period,
+			Limit:        decimal.NewFromFloat(limit),
+			Currency:     currency,
+			Rollover:     rollover,
+			StartDate:    startDate,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println("budget ", 0, ": ", budgetEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	setCmd.Flags().StringVarP(
+		&categoryName, "category", "c", "", "category the budget limits (required)")
+	setCmd.Flags().StringVarP(
+		&period, "period", "p", model.BudgetPeriodMonthly, "MONTHLY or YEARLY")
+	setCmd.Flags().Float64VarP(
+		&limit, "amount", "a", 0.00, "most the category may spend per period (required)")
+	setCmd.Flags().StringVar(
+		&currency, "currency", "", "currency of the limit (optional, the default currency)")
+	setCmd.Flags().BoolVarP(
+		&rollover, "rollover", "r", false, "carry unspent amounts into the next period")
+	setCmd.Flags().StringVarP(
+		&startDate, "start", "s", "", "first period the budget applies to (optional, blank for the current one)")
+
+	setCmd.MarkFlagRequired("category")
+	setCmd.MarkFlagRequired("amount")
+	BudgetCmd.AddCommand(setCmd)
+}
@@ -0,0 +1,51 @@
+package budget_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/budget_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show how much of each budget is used",
+	Long: `Show spent, remaining and percent used of every budget for the current period.
+Spending of subcategories counts toward the budget of their parent.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statusList, err := budget_service.StatusService(util.GetConfigByKey("cli.owner.id"), categoryName, statusDate)
+		if err != nil {
+			return err
+		}
+
+		if len(statusList) == 0 {
+			fmt.Println("No budgets found")
+			return nil
+		}
+
+		fmt.Println("=== Budget Status ===")
+		for _, status := range statusList {
+			fmt.Printf("\n%s (%s, %s to %s, %s)\n", status.CategoryName, status.Period,
+				status.PeriodStart, status.PeriodEnd, status.Currency)
+			fmt.Printf("  Limit:       %s\n", status.Limit.StringFixed(2))
+			if !status.RolledOver.IsZero() {
+				fmt.Printf("  Rolled Over: %s\n", status.RolledOver.StringFixed(2))
+			}
+			fmt.Printf("  Spent:       %s (%s%%)\n", status.Spent.StringFixed(2), status.PercentUsed.StringFixed(2))
+			fmt.Printf("  Remaining:   %s\n", status.Remaining.StringFixed(2))
+			if status.Overspent {
+				fmt.Printf("  ⚠️  Overspent by %s\n", status.Remaining.Neg().StringFixed(2))
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	statusCmd.Flags().StringVarP(
+		&categoryName, "category", "c", "", "show only the budgets of this category (optional)")
+	statusCmd.Flags().StringVarP(
+		&statusDate, "date", "b", "", "a date in the period to report (optional, blank for today)")
+	BudgetCmd.AddCommand(statusCmd)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
 	"github.com/spf13/cobra"
 )
 
@@ -19,7 +20,7 @@ var deleteCmd = &cobra.Command{
 
 		if plainId != "" {
 
-			cashFlowEntity, err := cash_flow_service.DeleteById(plainId)
+			cashFlowEntity, err := cash_flow_service.DeleteById(util.GetConfigByKey("cli.owner.id"), plainId)
 			if err != nil {
 				return err
 			}
@@ -28,7 +29,7 @@ var deleteCmd = &cobra.Command{
 		}
 
 		if belongsDate != "" {
-			cashFlowEntityList, err := cash_flow_service.DeleteByDate(belongsDate)
+			cashFlowEntityList, err := cash_flow_service.DeleteByDate(util.GetConfigByKey("cli.owner.id"), belongsDate)
 			if err != nil {
 				return err
 			}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 )
 
@@ -12,10 +14,11 @@ var incomeCmd = &cobra.Command{
 	Use:   "income",
 	Short: "add new income cash_flow",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if !cash_flow_service.IsIncomeRequiredFiledSatisfied(categoryName, amount) {
+		if !cash_flow_service.IsIncomeRequiredFiledSatisfied(decimal.NewFromFloat(amount)) {
 			return errors.New("some required fields are empty")
 		}
-		cashFlowEntity, err := cash_flow_service.SaveIncome(belongsDate, categoryName, amount, descriptionExact)
+		cashFlowEntity, err := cash_flow_service.SaveIncome(util.GetConfigByKey("cli.owner.id"), util.GetConfigByKey("cli.user.id"),
+			belongsDate, categoryName, accountName, currency, decimal.NewFromFloat(amount), descriptionExact, payeeName, tagList)
 		if err != nil {
 			return err
 		}
@@ -28,10 +31,18 @@ func init() {
 	incomeCmd.Flags().StringVarP(
 		&belongsDate, "date", "b", "", "flow's belongs-date (optional, blank for today)")
 	incomeCmd.Flags().StringVarP(
-		&categoryName, "category", "c", "", "flow's category name (required)")
+		&categoryName, "category", "c", "", "flow's category name (optional, picked by the categorization rules when blank)")
+	incomeCmd.Flags().StringVar(
+		&accountName, "account", "", "account the money moved through (optional)")
+	incomeCmd.Flags().StringVar(
+		&currency, "currency", "", "flow's currency code (optional, the account's or the default currency)")
 	incomeCmd.Flags().Float64VarP(
 		&amount, "amount", "a", 0.00, "flow's amount (required)")
 	incomeCmd.Flags().StringVarP(
 		&descriptionExact, "description", "d", "", "flow's description (optional, could be blank)")
+	incomeCmd.Flags().StringVar(
+		&payeeName, "payee", "", "flow's payee name (optional, recognised from the description when blank)")
+	incomeCmd.Flags().StringSliceVar(
+		&tagList, "tag", nil, "flow's tags, repeat or separate by comma (optional)")
 	CashCmd.AddCommand(incomeCmd)
 }
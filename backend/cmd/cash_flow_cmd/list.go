@@ -5,45 +5,59 @@ import (
 
 	"github.com/macar-x/cashlens/model"
 	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 )
 
 var (
-	limit    int
-	offset   int
-	cashType string
+	limit      int
+	offset     int
+	cashType   string
+	listTag    string
+	listSortBy string
+	listOrder  string
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "list all cash_flow records",
 	Long: `List all cash flow records with optional filtering and pagination.
-Use --type to filter by income/outcome, --limit for pagination.`,
+Use --type to filter by income/outcome, --tag to keep only one tag, --sort and --order
+to change the newest-first order, --limit and --offset or --cursor for pagination,
+--all to read every page one after the other.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cashFlowEntityList, err := cash_flow_service.QueryAll(cashType, limit, offset)
+		shownCount := 0
+		totalIncome := decimal.Zero
+		totalExpense := decimal.Zero
+		lastPage, err := readPages(offset, func(cursor string, pageOffset int) (model.CashFlowPage, error) {
+			return cash_flow_service.QueryAll(util.GetConfigByKey("cli.owner.id"), cashType, listTag, listSortBy, listOrder, cursor, limit, pageOffset)
+		}, func(page model.CashFlowPage) {
+			for _, cashFlowEntity := range page.Data {
+				fmt.Println("cash_flow", shownCount+offset, ":", cashFlowEntity.ToString())
+				shownCount++
+				switch cashFlowEntity.FlowType {
+				case model.FlowTypeIncome:
+					totalIncome = totalIncome.Add(cashFlowEntity.Amount)
+				case model.FlowTypeOutcome:
+					totalExpense = totalExpense.Add(cashFlowEntity.Amount)
+				}
+			}
+		})
 		if err != nil {
 			return err
 		}
 
-		if len(cashFlowEntityList) == 0 {
+		if shownCount == 0 {
 			fmt.Println("No cash flows found")
 			return nil
 		}
 
-		var totalIncome, totalExpense float64
-		for index, cashFlowEntity := range cashFlowEntityList {
-			fmt.Println("cash_flow", index+offset, ":", cashFlowEntity.ToString())
-			if cashFlowEntity.FlowType == model.FlowTypeIncome {
-				totalIncome += cashFlowEntity.Amount
-			} else {
-				totalExpense += cashFlowEntity.Amount
-			}
-		}
-
-		fmt.Printf("\n--- Summary (showing %d records) ---\n", len(cashFlowEntityList))
-		fmt.Printf("Total Income: %.2f\n", totalIncome)
-		fmt.Printf("Total Expense: %.2f\n", totalExpense)
-		fmt.Printf("Balance: %.2f\n", totalIncome-totalExpense)
+		fmt.Printf("\n--- Summary (showing %d of %d records) ---\n", shownCount, lastPage.TotalCount)
+		fmt.Printf("Total Income: %s\n", totalIncome.StringFixed(2))
+		fmt.Printf("Total Expense: %s\n", totalExpense.StringFixed(2))
+		fmt.Printf("Balance: %s\n", totalIncome.Sub(totalExpense).StringFixed(2))
+		printPageCursors(lastPage)
 
 		return nil
 	},
@@ -55,7 +69,17 @@ func init() {
 	listCmd.Flags().IntVarP(
 		&offset, "offset", "o", 0, "number of records to skip")
 	listCmd.Flags().StringVarP(
-		&cashType, "type", "t", "", "filter by type (income/outcome)")
+		&cashType, "type", "t", "", "filter by type (income/outcome/transfer)")
+	listCmd.Flags().StringVar(
+		&listTag, "tag", "", "filter by tag (optional)")
+	listCmd.Flags().StringVar(
+		&listSortBy, "sort", "date", "sort by date or amount")
+	listCmd.Flags().StringVar(
+		&listOrder, "order", "desc", "asc or desc")
+	listCmd.Flags().StringVar(
+		&pageCursor, "cursor", "", "continue from the next or previous page cursor of an earlier listing")
+	listCmd.Flags().BoolVar(
+		&isAllPages, "all", false, "read every page, --limit records at a time")
 
 	CashCmd.AddCommand(listCmd)
 }
@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 )
 
@@ -12,10 +14,11 @@ var outcomeCmd = &cobra.Command{
 	Use:   "outcome",
 	Short: "add new outcome cash_flow",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if !cash_flow_service.IsOutcomeRequiredFiledSatisfied(categoryName, amount) {
+		if !cash_flow_service.IsOutcomeRequiredFiledSatisfied(decimal.NewFromFloat(amount)) {
 			return errors.New("some required fields are empty")
 		}
-		cashFlowEntity, err := cash_flow_service.SaveOutcome(belongsDate, categoryName, amount, descriptionExact)
+		cashFlowEntity, err := cash_flow_service.SaveOutcome(util.GetConfigByKey("cli.owner.id"), util.GetConfigByKey("cli.user.id"),
+			belongsDate, categoryName, accountName, currency, decimal.NewFromFloat(amount), descriptionExact, payeeName, tagList)
 		if err != nil {
 			return err
 		}
@@ -28,10 +31,18 @@ func init() {
 	outcomeCmd.Flags().StringVarP(
 		&belongsDate, "date", "b", "", "flow's belongs-date (optional, blank for today)")
 	outcomeCmd.Flags().StringVarP(
-		&categoryName, "category", "c", "", "flow's category name (required)")
+		&categoryName, "category", "c", "", "flow's category name (optional, picked by the categorization rules when blank)")
+	outcomeCmd.Flags().StringVar(
+		&accountName, "account", "", "account the money moved through (optional)")
+	outcomeCmd.Flags().StringVar(
+		&currency, "currency", "", "flow's currency code (optional, the account's or the default currency)")
 	outcomeCmd.Flags().Float64VarP(
 		&amount, "amount", "a", 0.00, "flow's amount (required)")
 	outcomeCmd.Flags().StringVarP(
 		&descriptionExact, "description", "d", "", "flow's description (optional, could be blank)")
+	outcomeCmd.Flags().StringVar(
+		&payeeName, "payee", "", "flow's payee name (optional, recognised from the description when blank)")
+	outcomeCmd.Flags().StringSliceVar(
+		&tagList, "tag", nil, "flow's tags, repeat or separate by comma (optional)")
 	CashCmd.AddCommand(outcomeCmd)
 }
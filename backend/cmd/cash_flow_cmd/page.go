@@ -0,0 +1,51 @@
+package cash_flow_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/model"
+)
+
+var (
+	pageCursor string
+	isAllPages bool
+)
+
+// readPages hands the page at the cursor or offset to handlePage, or with --all every page of the
+// listing as soon as it is read. The date order follows next_cursor, the other orders move the offset on.
+// It returns the last page read.
+func readPages(startOffset int, readPage func(cursor string, offset int) (model.CashFlowPage, error),
+	handlePage func(page model.CashFlowPage)) (model.CashFlowPage, error) {
+	cursor, pageOffset := pageCursor, startOffset
+	for {
+		page, err := readPage(cursor, pageOffset)
+		if err != nil {
+			return model.CashFlowPage{}, err
+		}
+		handlePage(page)
+
+		switch {
+		case !isAllPages:
+			return page, nil
+		case page.NextCursor != "":
+			cursor, pageOffset = page.NextCursor, 0
+		case cursor == "" && page.Limit > 0 && len(page.Data) > 0 && int64(pageOffset+len(page.Data)) < page.TotalCount:
+			pageOffset += len(page.Data)
+		default:
+			return page, nil
+		}
+	}
+}
+
+// printPageCursors tells how to go on from a single page
+func printPageCursors(page model.CashFlowPage) {
+	if isAllPages {
+		return
+	}
+	if page.PrevCursor != "" {
+		fmt.Println("Previous page: --cursor", page.PrevCursor)
+	}
+	if page.NextCursor != "" {
+		fmt.Println("Next page: --cursor", page.NextCursor)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
 	"github.com/spf13/cobra"
 )
 
@@ -19,7 +20,7 @@ var queryCmd = &cobra.Command{
 
 		// if id is not empty, use it for query.
 		if plainId != "" {
-			cashFlowEntity, err := cash_flow_service.QueryById(plainId)
+			cashFlowEntity, err := cash_flow_service.QueryById(util.GetConfigByKey("cli.owner.id"), plainId)
 			if err != nil {
 				return err
 			}
@@ -29,7 +30,7 @@ var queryCmd = &cobra.Command{
 
 		// else if date is not empty, use it for query.
 		if belongsDate != "" {
-			cashFlowEntityList, err := cash_flow_service.QueryByDate(belongsDate)
+			cashFlowEntityList, err := cash_flow_service.QueryByDate(util.GetConfigByKey("cli.owner.id"), belongsDate)
 			if err != nil {
 				return err
 			}
@@ -45,7 +46,7 @@ var queryCmd = &cobra.Command{
 
 		// else if exact_desc is not empty, use it for query.
 		if descriptionExact != "" {
-			cashFlowEntityList, err := cash_flow_service.QueryByExactDescription(descriptionExact)
+			cashFlowEntityList, err := cash_flow_service.QueryByExactDescription(util.GetConfigByKey("cli.owner.id"), descriptionExact)
 			if err != nil {
 				return err
 			}
@@ -61,7 +62,7 @@ var queryCmd = &cobra.Command{
 
 		// else if fuzzy_desc is not empty, use it for query.
 		if descriptionFuzzy != "" {
-			cashFlowEntityList, err := cash_flow_service.QueryByFuzzyDescription(descriptionFuzzy)
+			cashFlowEntityList, err := cash_flow_service.QueryByFuzzyDescription(util.GetConfigByKey("cli.owner.id"), descriptionFuzzy)
 			if err != nil {
 				return err
 			}
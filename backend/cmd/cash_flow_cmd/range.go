@@ -6,6 +6,8 @@ import (
 
 	"github.com/macar-x/cashlens/model"
 	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 )
 
@@ -24,7 +26,7 @@ Displays all transactions between from-date and to-date (inclusive).`,
 			return errors.New("both from-date and to-date are required")
 		}
 
-		cashFlowEntityList, err := cash_flow_service.QueryByDateRange(fromDate, toDate)
+		cashFlowEntityList, err := cash_flow_service.QueryByDateRange(util.GetConfigByKey("cli.owner.id"), fromDate, toDate)
 		if err != nil {
 			return err
 		}
@@ -34,22 +36,24 @@ Displays all transactions between from-date and to-date (inclusive).`,
 			return nil
 		}
 
-		var totalIncome, totalExpense float64
+		totalIncome := decimal.Zero
+		totalExpense := decimal.Zero
 		for index, cashFlowEntity := range cashFlowEntityList {
 			fmt.Println("cash_flow", index, ":", cashFlowEntity.ToString())
-			if cashFlowEntity.FlowType == model.FlowTypeIncome {
-				totalIncome += cashFlowEntity.Amount
-			} else {
-				totalExpense += cashFlowEntity.Amount
+			switch cashFlowEntity.FlowType {
+			case model.FlowTypeIncome:
+				totalIncome = totalIncome.Add(cashFlowEntity.Amount)
+			case model.FlowTypeOutcome:
+				totalExpense = totalExpense.Add(cashFlowEntity.Amount)
 			}
 		}
 
 		fmt.Printf("\n--- Summary ---\n")
 		fmt.Printf("Period: %s to %s\n", fromDate, toDate)
 		fmt.Printf("Total Records: %d\n", len(cashFlowEntityList))
-		fmt.Printf("Total Income: %.2f\n", totalIncome)
-		fmt.Printf("Total Expense: %.2f\n", totalExpense)
-		fmt.Printf("Balance: %.2f\n", totalIncome-totalExpense)
+		fmt.Printf("Total Income: %s\n", totalIncome.StringFixed(2))
+		fmt.Printf("Total Expense: %s\n", totalExpense.StringFixed(2))
+		fmt.Printf("Balance: %s\n", totalIncome.Sub(totalExpense).StringFixed(2))
 
 		return nil
 	},
@@ -11,8 +11,14 @@ var (
 	amount           float64
 	belongsDate      string
 	categoryName     string
+	accountName      string
+	currency         string
+	fromAccountName  string
+	toAccountName    string
 	descriptionExact string
 	descriptionFuzzy string
+	payeeName        string
+	tagList          []string
 )
 
 var CashCmd = &cobra.Command{
@@ -23,12 +29,16 @@ var CashCmd = &cobra.Command{
 Available sub-commands:
   income   - Add new income transaction
   outcome  - Add new expense transaction
+  transfer - Move money between two accounts
   update   - Update existing transaction
+  split    - Share a transaction among several categories
   delete   - Delete transaction(s)
   query    - Query transactions by filters
   list     - List all transactions with pagination
   range    - Query transactions by date range
-  summary  - Show financial summary`,
+  search   - Search transactions by text and combined filters
+  summary  - Show financial summary
+  suggest-category - Suggest categories learned from past transactions`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return errors.New("must provide a valid sub command")
@@ -0,0 +1,100 @@
+package cash_flow_cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchMinAmount float64
+	searchMaxAmount float64
+	searchType      string
+	searchSortBy    string
+	searchOrder     string
+	searchLimit     int
+	searchOffset    int
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search [text]",
+	Short: "search cash_flow records by text and filters",
+	Long: `Search cash flow records whose description holds every word of the text, combined with
+any of the filters below. A category also matches everything below it.
+  cashlens cash search starbucks --from 2024-01-01 --to 2024-03-31 -c Food --tag work --sort amount`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		searchDTO := model.CashFlowSearchDTO{
+			Text:         strings.Join(args, " "),
+			FromDate:     fromDate,
+			ToDate:       toDate,
+			MinAmount:    decimal.NewFromFloat(searchMinAmount),
+			MaxAmount:    decimal.NewFromFloat(searchMaxAmount),
+			FlowType:     searchType,
+			CategoryName: categoryName,
+			Tags:         tagList,
+			AccountName:  accountName,
+			SortBy:       searchSortBy,
+			Order:        searchOrder,
+			Limit:        searchLimit,
+		}
+		shownCount := 0
+		lastPage, err := readPages(searchOffset, func(cursor string, pageOffset int) (model.CashFlowPage, error) {
+			searchDTO.Cursor, searchDTO.Offset = cursor, pageOffset
+			return cash_flow_service.SearchService(util.GetConfigByKey("cli.owner.id"), searchDTO)
+		}, func(page model.CashFlowPage) {
+			for _, cashFlowEntity := range page.Data {
+				fmt.Println("cash_flow", shownCount+searchOffset, ":", cashFlowEntity.ToString())
+				shownCount++
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		if shownCount == 0 {
+			fmt.Println("No cash flows found")
+			return nil
+		}
+		fmt.Printf("\n--- Showing %d of %d matching records ---\n", shownCount, lastPage.TotalCount)
+		printPageCursors(lastPage)
+		return nil
+	},
+}
+
+func init() {
+	searchCmd.Flags().StringVar(
+		&fromDate, "from", "", "earliest belongs-date, YYYYMMDD or YYYY-MM-DD (optional)")
+	searchCmd.Flags().StringVar(
+		&toDate, "to", "", "latest belongs-date, YYYYMMDD or YYYY-MM-DD (optional)")
+	searchCmd.Flags().Float64Var(
+		&searchMinAmount, "min", 0, "smallest amount (optional)")
+	searchCmd.Flags().Float64Var(
+		&searchMaxAmount, "max", 0, "largest amount (optional)")
+	searchCmd.Flags().StringVarP(
+		&searchType, "type", "t", "", "INCOME, OUTCOME or TRANSFER (optional)")
+	searchCmd.Flags().StringVarP(
+		&categoryName, "category", "c", "", "category name, its sub-categories included (optional)")
+	searchCmd.Flags().StringSliceVar(
+		&tagList, "tag", nil, "tags that must all be present, repeat or separate by comma (optional)")
+	searchCmd.Flags().StringVar(
+		&accountName, "account", "", "account the money moved through (optional)")
+	searchCmd.Flags().StringVar(
+		&searchSortBy, "sort", "date", "sort by date, amount or relevance")
+	searchCmd.Flags().StringVar(
+		&searchOrder, "order", "desc", "asc or desc, relevance is always the best match first")
+	searchCmd.Flags().IntVarP(
+		&searchLimit, "limit", "l", 20, "maximum number of records to return")
+	searchCmd.Flags().IntVarP(
+		&searchOffset, "offset", "o", 0, "number of records to skip")
+	searchCmd.Flags().StringVar(
+		&pageCursor, "cursor", "", "continue from the next or previous page cursor of an earlier search")
+	searchCmd.Flags().BoolVar(
+		&isAllPages, "all", false, "read every page, --limit records at a time")
+
+	CashCmd.AddCommand(searchCmd)
+}
@@ -0,0 +1,77 @@
+package cash_flow_cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var splitLineList []string
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "share a cash_flow among several categories",
+	Long: `Share a cash flow among several categories, one --line per category.
+A line reads "category:amount" or "category:amount:description", the amounts must add up to the cash flow amount.
+Run it without any --line to turn the cash flow back into a single category.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if plainId == "" {
+			return errors.New("id is required for split operation")
+		}
+
+		lines := make([]model.CashFlowSplitDTO, 0, len(splitLineList))
+		for _, plainLine := range splitLineList {
+			line, err := parseSplitLine(plainLine)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, line)
+		}
+
+		cashFlowEntity, err := cash_flow_service.SplitById(util.GetConfigByKey("cli.owner.id"), util.GetConfigByKey("cli.user.id"), plainId, lines)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Split cash_flow:", cashFlowEntity.ToString())
+		return nil
+	},
+}
+
+// parseSplitLine reads "category:amount[:description]", the description may contain ':' itself
+func parseSplitLine(plainLine string) (model.CashFlowSplitDTO, error) {
+	parts := strings.SplitN(plainLine, ":", 3)
+	if len(parts) < 2 {
+		return model.CashFlowSplitDTO{}, fmt.Errorf("invalid line %q, use category:amount[:description]", plainLine)
+	}
+
+	lineAmount, err := decimal.NewFromString(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return model.CashFlowSplitDTO{}, fmt.Errorf("invalid amount in line %q", plainLine)
+	}
+
+	line := model.CashFlowSplitDTO{
+		CategoryName: strings.TrimSpace(parts[0]),
+		Amount:       lineAmount,
+	}
+	if len(parts) == 3 {
+		line.Description = parts[2]
+	}
+	return line, nil
+}
+
+func init() {
+	splitCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "cash_flow id (required)")
+	splitCmd.Flags().StringArrayVarP(
+		&splitLineList, "line", "l", nil, "one line as category:amount[:description], repeat for each line")
+
+	splitCmd.MarkFlagRequired("id")
+	CashCmd.AddCommand(splitCmd)
+}
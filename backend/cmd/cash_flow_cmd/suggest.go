@@ -0,0 +1,53 @@
+package cash_flow_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	suggestLimit int
+	suggestType  string
+)
+
+var suggestCategoryCmd = &cobra.Command{
+	Use:   "suggest-category",
+	Short: "suggest categories learned from past transactions",
+	Long: `Rank the categories a transaction likely belongs to, learning from the descriptions and
+amounts of the transactions already saved. Nothing is saved and nothing leaves the database.
+  cashlens cash suggest-category -d "STARBUCKS #1234" -a 4.5`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		suggestionList, err := cash_flow_service.SuggestCategoryService(util.GetConfigByKey("cli.owner.id"),
+			suggestType, descriptionExact, decimal.NewFromFloat(amount), suggestLimit)
+		if err != nil {
+			return err
+		}
+
+		if len(suggestionList) == 0 {
+			fmt.Println("No suggestion, no past transaction looks like this one")
+			return nil
+		}
+		for index, suggestion := range suggestionList {
+			fmt.Printf("%d. %s (confidence: %.2f%%)\n", index+1, suggestion.CategoryName, suggestion.Confidence*100)
+		}
+		return nil
+	},
+}
+
+func init() {
+	suggestCategoryCmd.Flags().StringVarP(
+		&descriptionExact, "description", "d", "", "flow's description (required)")
+	suggestCategoryCmd.Flags().Float64VarP(
+		&amount, "amount", "a", 0.00, "flow's amount (optional)")
+	suggestCategoryCmd.Flags().StringVarP(
+		&suggestType, "type", "t", "", "INCOME or OUTCOME (optional, blank for OUTCOME)")
+	suggestCategoryCmd.Flags().IntVarP(
+		&suggestLimit, "limit", "l", 3, "maximum number of categories to suggest")
+
+	suggestCategoryCmd.MarkFlagRequired("description")
+	CashCmd.AddCommand(suggestCategoryCmd)
+}
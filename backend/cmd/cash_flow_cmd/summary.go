@@ -5,12 +5,14 @@ import (
 	"fmt"
 
 	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
 	"github.com/spf13/cobra"
 )
 
 var (
-	summaryPeriod string
-	summaryDate   string
+	summaryPeriod   string
+	summaryDate     string
+	summaryCurrency string
 )
 
 var summaryCmd = &cobra.Command{
@@ -21,7 +23,8 @@ Periods: daily, monthly, yearly
 Examples:
   cashlens cash summary --period daily --date 2024-01-15
   cashlens cash summary --period monthly --date 2024-01
-  cashlens cash summary --period yearly --date 2024`,
+  cashlens cash summary --period yearly --date 2024
+  cashlens cash summary --period monthly --date 2024-01 --currency EUR`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if summaryPeriod == "" {
 			return errors.New("period is required (daily, monthly, yearly)")
@@ -31,21 +34,33 @@ Examples:
 			return errors.New("date is required (format depends on period)")
 		}
 
-		summary, err := cash_flow_service.GetSummary(summaryPeriod, summaryDate)
+		summary, err := cash_flow_service.GetSummary(util.GetConfigByKey("cli.owner.id"), summaryPeriod, summaryDate, summaryCurrency)
 		if err != nil {
 			return err
 		}
 
 		fmt.Printf("\n=== %s Summary for %s ===\n", summaryPeriod, summaryDate)
-		fmt.Printf("Total Income:  %.2f\n", summary.TotalIncome)
-		fmt.Printf("Total Expense: %.2f\n", summary.TotalExpense)
-		fmt.Printf("Balance:       %.2f\n", summary.Balance)
 		fmt.Printf("Transactions:  %d\n", summary.TransactionCount)
 
-		if len(summary.CategoryBreakdown) > 0 {
-			fmt.Printf("\n--- Category Breakdown ---\n")
-			for category, amount := range summary.CategoryBreakdown {
-				fmt.Printf("  %-20s: %.2f\n", category, amount)
+		// Amounts in different currencies are never added together
+		for _, totals := range summary.Totals {
+			fmt.Printf("\n--- %s ---\n", totals.Currency)
+			fmt.Printf("Total Income:  %s\n", totals.TotalIncome.StringFixed(2))
+			fmt.Printf("Total Expense: %s\n", totals.TotalExpense.StringFixed(2))
+			fmt.Printf("Balance:       %s\n", totals.Balance.StringFixed(2))
+
+			if len(totals.CategoryBreakdown) > 0 {
+				fmt.Printf("Category Breakdown:\n")
+				for category, amount := range totals.CategoryBreakdown {
+					fmt.Printf("  %-20s: %s\n", category, amount.StringFixed(2))
+				}
+			}
+
+			if len(totals.TagBreakdown) > 0 {
+				fmt.Printf("Tag Breakdown:\n")
+				for tag, amount := range totals.TagBreakdown {
+					fmt.Printf("  %-20s: %s\n", tag, amount.StringFixed(2))
+				}
 			}
 		}
 
@@ -59,6 +74,9 @@ func init() {
 	summaryCmd.Flags().StringVarP(
 		&summaryDate, "date", "d", "", "date for summary (format: YYYY-MM-DD for daily, YYYY-MM for monthly, YYYY for yearly) (required)")
 
+	summaryCmd.Flags().StringVar(
+		&summaryCurrency, "currency", "", "report in this currency, converting at each flow's date (optional)")
+
 	summaryCmd.MarkFlagRequired("period")
 	summaryCmd.MarkFlagRequired("date")
 	CashCmd.AddCommand(summaryCmd)
@@ -0,0 +1,48 @@
+package cash_flow_cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var transferCmd = &cobra.Command{
+	Use:   "transfer",
+	Short: "move money between two accounts",
+	Long: `Move money between two accounts.
+Two linked TRANSFER records are saved, one leaving the source account and one
+entering the target account. Transfers are left out of income/expense summaries,
+and updating or deleting either record also updates or deletes the other.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cash_flow_service.IsTransferRequiredFiledSatisfied(fromAccountName, toAccountName, decimal.NewFromFloat(amount)) {
+			return errors.New("some required fields are empty")
+		}
+		cashFlowEntityList, err := cash_flow_service.SaveTransfer(util.GetConfigByKey("cli.owner.id"), util.GetConfigByKey("cli.user.id"),
+			belongsDate, fromAccountName, toAccountName, decimal.NewFromFloat(amount), descriptionExact)
+		if err != nil {
+			return err
+		}
+		for index, cashFlowEntity := range cashFlowEntityList {
+			fmt.Println("cash_flow ", index, ": ", cashFlowEntity.ToString())
+		}
+		return nil
+	},
+}
+
+func init() {
+	transferCmd.Flags().StringVarP(
+		&belongsDate, "date", "b", "", "transfer's belongs-date (optional, blank for today)")
+	transferCmd.Flags().StringVar(
+		&fromAccountName, "from", "", "account the money leaves (required)")
+	transferCmd.Flags().StringVar(
+		&toAccountName, "to", "", "account the money enters (required)")
+	transferCmd.Flags().Float64VarP(
+		&amount, "amount", "a", 0.00, "transfer's amount (required)")
+	transferCmd.Flags().StringVarP(
+		&descriptionExact, "description", "d", "", "transfer's description (optional, could be blank)")
+	CashCmd.AddCommand(transferCmd)
+}
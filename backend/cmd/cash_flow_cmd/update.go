@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 )
 
@@ -12,18 +14,26 @@ var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "update existing cash_flow by id",
 	Long: `Update an existing cash flow record by its ID.
-You can update amount, category, date, and description.`,
+You can update amount, category, account, currency, date, description, payee, and tags.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if plainId == "" {
 			return errors.New("id is required for update operation")
 		}
 
 		// Check if at least one field to update is provided
-		if amount == 0 && categoryName == "" && belongsDate == "" && descriptionExact == "" {
-			return errors.New("at least one field to update must be provided (amount, category, date, or description)")
+		isTagChanged := cmd.Flags().Changed("tag")
+		if amount == 0 && categoryName == "" && accountName == "" && currency == "" && belongsDate == "" && descriptionExact == "" && payeeName == "" && !isTagChanged {
+			return errors.New("at least one field to update must be provided (amount, category, account, currency, date, description, payee, or tag)")
 		}
 
-		cashFlowEntity, err := cash_flow_service.UpdateById(plainId, belongsDate, categoryName, amount, descriptionExact)
+		// Tags are only replaced when the flag is given, --tag "" clears them
+		var tags []string
+		if isTagChanged {
+			tags = append([]string{}, tagList...)
+		}
+
+		cashFlowEntity, err := cash_flow_service.UpdateById(util.GetConfigByKey("cli.owner.id"), util.GetConfigByKey("cli.user.id"),
+			plainId, belongsDate, categoryName, accountName, currency, decimal.NewFromFloat(amount), descriptionExact, payeeName, tags)
 		if err != nil {
 			return err
 		}
@@ -40,10 +50,18 @@ func init() {
 		&belongsDate, "date", "b", "", "new belongs-date (optional)")
 	updateCmd.Flags().StringVarP(
 		&categoryName, "category", "c", "", "new category name (optional)")
+	updateCmd.Flags().StringVar(
+		&accountName, "account", "", "new account name (optional)")
+	updateCmd.Flags().StringVar(
+		&currency, "currency", "", "new currency code (optional, must match the account's)")
 	updateCmd.Flags().Float64VarP(
 		&amount, "amount", "a", 0.00, "new amount (optional)")
 	updateCmd.Flags().StringVarP(
 		&descriptionExact, "description", "d", "", "new description (optional)")
+	updateCmd.Flags().StringVar(
+		&payeeName, "payee", "", "new payee name (optional)")
+	updateCmd.Flags().StringSliceVar(
+		&tagList, "tag", nil, "replace the tags, repeat or separate by comma (optional, blank clears them)")
 
 	updateCmd.MarkFlagRequired("id")
 	CashCmd.AddCommand(updateCmd)
@@ -1,7 +1,10 @@
 package category_cmd
 
 import (
+	"fmt"
+
 	"github.com/macar-x/cashlens/service/category_service"
+	"github.com/macar-x/cashlens/util"
 	"github.com/spf13/cobra"
 )
 
@@ -9,7 +12,19 @@ var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "create new category",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return category_service.CreateService(parentPlainId, categoryName)
+		newCategoryPlainId, err := category_service.CreateService(util.GetConfigByKey("cli.owner.id"), parentPlainId, categoryName)
+		if err != nil {
+			return err
+		}
+
+		categoryEntityList, err := category_service.QueryService(util.GetConfigByKey("cli.owner.id"), newCategoryPlainId, "", "")
+		if err != nil {
+			return err
+		}
+		for index, categoryEntity := range categoryEntityList {
+			fmt.Println("category ", index, ": ", categoryEntity.ToString())
+		}
+		return nil
 	},
 }
 
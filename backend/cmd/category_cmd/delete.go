@@ -2,6 +2,7 @@ package category_cmd
 
 import (
 	"github.com/macar-x/cashlens/service/category_service"
+	"github.com/macar-x/cashlens/util"
 	"github.com/spf13/cobra"
 )
 
@@ -9,7 +10,7 @@ var deleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "delete category data",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return category_service.DeleteService(plainId, categoryName)
+		return category_service.DeleteService(util.GetConfigByKey("cli.owner.id"), plainId, categoryName)
 	},
 }
 
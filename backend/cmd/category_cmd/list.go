@@ -1,7 +1,10 @@
 package category_cmd
 
 import (
+	"fmt"
+
 	"github.com/macar-x/cashlens/service/category_service"
+	"github.com/macar-x/cashlens/util"
 	"github.com/spf13/cobra"
 )
 
@@ -10,7 +13,20 @@ var listCmd = &cobra.Command{
 	Short: "list all categories",
 	Long:  `List all categories in the system.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return category_service.ListAllService()
+		categoryEntityList, totalCount, err := category_service.ListAllService(util.GetConfigByKey("cli.owner.id"), 0, 0)
+		if err != nil {
+			return err
+		}
+
+		if len(categoryEntityList) == 0 {
+			fmt.Println("No categories found")
+			return nil
+		}
+		for index, categoryEntity := range categoryEntityList {
+			fmt.Println("category ", index, ": ", categoryEntity.ToString())
+		}
+		fmt.Printf("\nTotal categories: %d\n", totalCount)
+		return nil
 	},
 }
 
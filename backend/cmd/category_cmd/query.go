@@ -1,7 +1,10 @@
 package category_cmd
 
 import (
+	"fmt"
+
 	"github.com/macar-x/cashlens/service/category_service"
+	"github.com/macar-x/cashlens/util"
 	"github.com/spf13/cobra"
 )
 
@@ -9,7 +12,19 @@ var queryCmd = &cobra.Command{
 	Use:   "query",
 	Short: "query for category data",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return category_service.QueryService(plainId, parentPlainId, categoryName)
+		categoryEntityList, err := category_service.QueryService(util.GetConfigByKey("cli.owner.id"), plainId, parentPlainId, categoryName)
+		if err != nil {
+			return err
+		}
+
+		if len(categoryEntityList) == 0 {
+			fmt.Println("no matched categories")
+			return nil
+		}
+		for index, categoryEntity := range categoryEntityList {
+			fmt.Println("category ", index, ": ", categoryEntity.ToString())
+		}
+		return nil
 	},
 }
 
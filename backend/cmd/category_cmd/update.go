@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/macar-x/cashlens/service/category_service"
+	"github.com/macar-x/cashlens/util"
 	"github.com/spf13/cobra"
 )
 
@@ -22,7 +23,7 @@ You can update the category name and parent.`,
 			return errors.New("at least one field to update must be provided (name or parent)")
 		}
 
-		err := category_service.UpdateService(plainId, parentPlainId, categoryName)
+		err := category_service.UpdateService(util.GetConfigByKey("cli.owner.id"), plainId, parentPlainId, categoryName)
 		if err != nil {
 			return err
 		}
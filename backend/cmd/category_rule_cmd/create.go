@@ -0,0 +1,56 @@
+package category_rule_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/category_rule_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create new categorization rule",
+	Long: `Create a categorization rule, for example
+  cashlens rules create -n Coffee -c Food --contains coffee --max 20 --tag coffee`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conditionDTO, err := buildConditions()
+		if err != nil {
+			return err
+		}
+
+		ruleDTO := model.CategoryRuleDTO{
+			Name:         ruleName,
+			CategoryName: categoryName,
+			Conditions:   &conditionDTO,
+			Tags:         tagList,
+		}
+		if cmd.Flags().Changed("priority") {
+			ruleDTO.Priority = &priority
+		}
+
+		ruleEntity, err := category_rule_service.CreateService(util.GetConfigByKey("cli.owner.id"), ruleDTO)
+		if err != nil {
+			return err
+		}
+		fmt.Println("category_rule ", 0, ": ", ruleEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	createCmd.Flags().StringVarP(
+		&ruleName, "name", "n", "", "rule's name (required)")
+	createCmd.Flags().StringVarP(
+		&categoryName, "category", "c", "", "category given to matching cash_flows (required)")
+	createCmd.Flags().IntVar(
+		&priority, "priority", 0, "rules with a higher priority are tried first (optional)")
+	addConditionFlags(createCmd)
+	createCmd.Flags().StringSliceVar(
+		&tagList, "tag", nil, "tags added to matching cash_flows, repeat or separate by comma (optional)")
+
+	createCmd.MarkFlagRequired("name")
+	createCmd.MarkFlagRequired("category")
+	RulesCmd.AddCommand(createCmd)
+}
@@ -0,0 +1,32 @@
+package category_rule_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/category_rule_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "delete categorization rule",
+	Long: `Delete a categorization rule by its ID.
+cash_flows already categorized by the rule keep their category.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ruleEntity, err := category_rule_service.DeleteService(util.GetConfigByKey("cli.owner.id"), plainId)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Deleted category_rule:", ruleEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	deleteCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "rule id (required)")
+
+	deleteCmd.MarkFlagRequired("id")
+	RulesCmd.AddCommand(deleteCmd)
+}
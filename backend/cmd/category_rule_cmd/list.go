@@ -0,0 +1,35 @@
+package category_rule_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/category_rule_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list all categorization rules",
+	Long:  `List all categorization rules in the order they are tried.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ruleEntityList, totalCount, err := category_rule_service.ListAllService(util.GetConfigByKey("cli.owner.id"), 0, 0)
+		if err != nil {
+			return err
+		}
+
+		if len(ruleEntityList) == 0 {
+			fmt.Println("No category rules found")
+			return nil
+		}
+		for index, ruleEntity := range ruleEntityList {
+			fmt.Println("category_rule ", index, ": ", ruleEntity.ToString())
+		}
+		fmt.Printf("\nTotal category rules: %d\n", totalCount)
+		return nil
+	},
+}
+
+func init() {
+	RulesCmd.AddCommand(listCmd)
+}
@@ -0,0 +1,30 @@
+package category_rule_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/category_rule_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "query for categorization rule data",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ruleEntity, err := category_rule_service.QueryService(util.GetConfigByKey("cli.owner.id"), plainId, ruleName)
+		if err != nil {
+			return err
+		}
+		fmt.Println("category_rule ", 0, ": ", ruleEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	queryCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "query by id")
+	queryCmd.Flags().StringVarP(
+		&ruleName, "name", "n", "", "query by name")
+	RulesCmd.AddCommand(queryCmd)
+}
@@ -0,0 +1,96 @@
+package category_rule_cmd
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	plainId         string
+	ruleName        string
+	categoryName    string
+	priority        int
+	flowType        string
+	containsPattern string
+	regexPattern    string
+	minAmount       float64
+	maxAmount       float64
+	payeeName       string
+	accountName     string
+	tagList         []string
+	isClearTags     bool
+	amount          float64
+)
+
+var RulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "manage categorization rules",
+	Long: `Manage categorization rules: conditions on description, amount range, payee or account,
+each picking a category and tags. They are tried by priority, the highest first, when a cash_flow
+is created without a category and on every imported row.
+
+Available sub-commands:
+  create - Create new rule
+  update - Update existing rule
+  delete - Delete rule
+  list   - List all rules in the order they are tried
+  query  - Query rule by id or name
+  test   - Show which rule would match a description`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.New("must provide a valid sub command")
+	},
+}
+
+// buildConditions collects the condition flags, blank ones match anything
+func buildConditions() (model.CategoryRuleConditionDTO, error) {
+	if containsPattern != "" && regexPattern != "" {
+		return model.CategoryRuleConditionDTO{}, errors.New("--contains can not be used together with --regex")
+	}
+
+	conditionDTO := model.CategoryRuleConditionDTO{
+		FlowType:    flowType,
+		MatchType:   model.PayeeRuleContains,
+		Pattern:     containsPattern,
+		MinAmount:   decimal.NewFromFloat(minAmount),
+		MaxAmount:   decimal.NewFromFloat(maxAmount),
+		PayeeName:   payeeName,
+		AccountName: accountName,
+	}
+	if regexPattern != "" {
+		conditionDTO.MatchType = model.PayeeRuleRegex
+		conditionDTO.Pattern = regexPattern
+	}
+	return conditionDTO, nil
+}
+
+// addConditionFlags registers the flags describing a rule's conditions
+func addConditionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&flowType, "type", "", "only match INCOME or OUTCOME (optional, blank for both)")
+	cmd.Flags().StringVar(
+		&containsPattern, "contains", "", "description contains this text, ignoring case (optional)")
+	cmd.Flags().StringVar(
+		&regexPattern, "regex", "", "description matches this regular expression, add (?i) to ignore case (optional)")
+	cmd.Flags().Float64Var(
+		&minAmount, "min", 0, "smallest amount matched (optional, 0 for no lower bound)")
+	cmd.Flags().Float64Var(
+		&maxAmount, "max", 0, "largest amount matched (optional, 0 for no upper bound)")
+	cmd.Flags().StringVar(
+		&payeeName, "payee", "", "only match cash_flows of this payee (optional)")
+	cmd.Flags().StringVar(
+		&accountName, "account", "", "only match cash_flows of this account (optional)")
+}
+
+// isConditionChanged tells whether any condition flag was given
+func isConditionChanged(cmd *cobra.Command) bool {
+	for _, flagName := range []string{"type", "contains", "regex", "min", "max", "payee", "account"} {
+		if cmd.Flags().Changed(flagName) {
+			return true
+		}
+	}
+	return false
+}
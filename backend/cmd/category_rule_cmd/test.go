@@ -0,0 +1,48 @@
+package category_rule_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/category_rule_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test <description>",
+	Short: "show which categorization rule would match",
+	Long: `Show which categorization rule a cash_flow with this description would get, nothing is saved.
+  cashlens rules test "STARBUCKS #1234" --amount 4.5`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ruleMatch, err := category_rule_service.MatchService(util.GetConfigByKey("cli.owner.id"),
+			flowType, args[0], decimal.NewFromFloat(amount), payeeName, accountName)
+		if err != nil {
+			return err
+		}
+
+		if ruleMatch.PayeeName != "" {
+			fmt.Println("Payee:", ruleMatch.PayeeName)
+		}
+		if ruleMatch.Rule.IsEmpty() {
+			fmt.Println("No rule matches, a category_name is required")
+			return nil
+		}
+		fmt.Println("Matched category_rule:", ruleMatch.Rule.ToString())
+		fmt.Println("Category:", ruleMatch.CategoryName)
+		return nil
+	},
+}
+
+func init() {
+	testCmd.Flags().Float64VarP(
+		&amount, "amount", "a", 0.00, "flow's amount (optional)")
+	testCmd.Flags().StringVar(
+		&flowType, "type", "", "INCOME or OUTCOME (optional, blank for OUTCOME)")
+	testCmd.Flags().StringVar(
+		&payeeName, "payee", "", "flow's payee name (optional, recognised from the description when blank)")
+	testCmd.Flags().StringVar(
+		&accountName, "account", "", "flow's account name (optional)")
+	RulesCmd.AddCommand(testCmd)
+}
@@ -0,0 +1,71 @@
+package category_rule_cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/category_rule_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "update existing categorization rule",
+	Long: `Update an existing categorization rule by its ID, blank fields are kept.
+Condition flags replace all the current conditions, --clear-tags removes the tags;
+cash_flows already saved keep their category.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("tag") && isClearTags {
+			return errors.New("--clear-tags can not be used together with --tag")
+		}
+
+		ruleDTO := model.CategoryRuleDTO{
+			Name:         ruleName,
+			CategoryName: categoryName,
+		}
+		if cmd.Flags().Changed("priority") {
+			ruleDTO.Priority = &priority
+		}
+		// Conditions and tags are only replaced when asked for, an empty tag list clears them
+		if isConditionChanged(cmd) {
+			conditionDTO, err := buildConditions()
+			if err != nil {
+				return err
+			}
+			ruleDTO.Conditions = &conditionDTO
+		}
+		if cmd.Flags().Changed("tag") {
+			ruleDTO.Tags = tagList
+		} else if isClearTags {
+			ruleDTO.Tags = []string{}
+		}
+
+		ruleEntity, err := category_rule_service.UpdateService(util.GetConfigByKey("cli.owner.id"), plainId, ruleDTO)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Updated category_rule:", ruleEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	updateCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "rule id (required)")
+	updateCmd.Flags().StringVarP(
+		&ruleName, "name", "n", "", "new name (optional)")
+	updateCmd.Flags().StringVarP(
+		&categoryName, "category", "c", "", "new category (optional)")
+	updateCmd.Flags().IntVar(
+		&priority, "priority", 0, "new priority (optional)")
+	addConditionFlags(updateCmd)
+	updateCmd.Flags().StringSliceVar(
+		&tagList, "tag", nil, "new tags, repeat or separate by comma (optional)")
+	updateCmd.Flags().BoolVar(
+		&isClearTags, "clear-tags", false, "remove every tag of the rule")
+
+	updateCmd.MarkFlagRequired("id")
+	RulesCmd.AddCommand(updateCmd)
+}
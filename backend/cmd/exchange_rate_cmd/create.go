@@ -0,0 +1,46 @@
+package exchange_rate_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create or replace an exchange rate",
+	Long: `Save how many to-currency one from-currency buys from the effective date on.
+A rate already set for the same pair and date is replaced.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rate, err := decimal.NewFromString(plainRate)
+		if err != nil {
+			return fmt.Errorf("invalid rate %q", plainRate)
+		}
+
+		exchangeRateEntity, err := exchange_rate_service.CreateService(util.GetConfigByKey("cli.owner.id"), fromCurrency, toCurrency, rate, effectiveDate)
+		if err != nil {
+			return err
+		}
+		fmt.Println("exchange_rate ", 0, ": ", exchangeRateEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	createCmd.Flags().StringVarP(
+		&fromCurrency, "from", "f", "", "currency converted from, e.g. EUR (required)")
+	createCmd.Flags().StringVarP(
+		&toCurrency, "to", "t", "", "currency converted into, e.g. USD (required)")
+	createCmd.Flags().StringVarP(
+		&plainRate, "rate", "r", "", "amount of to-currency one from-currency buys (required)")
+	createCmd.Flags().StringVarP(
+		&effectiveDate, "date", "b", "", "date the rate takes effect (optional, blank for today)")
+
+	createCmd.MarkFlagRequired("from")
+	createCmd.MarkFlagRequired("to")
+	createCmd.MarkFlagRequired("rate")
+	RateCmd.AddCommand(createCmd)
+}
@@ -0,0 +1,31 @@
+package exchange_rate_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "delete exchange rate",
+	Long:  `Delete an exchange rate by its ID.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exchangeRateEntity, err := exchange_rate_service.DeleteService(util.GetConfigByKey("cli.owner.id"), plainId)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Deleted exchange_rate:", exchangeRateEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	deleteCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "exchange rate id (required)")
+
+	deleteCmd.MarkFlagRequired("id")
+	RateCmd.AddCommand(deleteCmd)
+}
@@ -0,0 +1,41 @@
+package exchange_rate_cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "import exchange rates from a CSV file",
+	Long: `Import exchange rates from a CSV file with the columns
+from_currency,to_currency,rate,effective_date (the header row is optional).
+Rates already set for the same pair and date are replaced,
+and nothing is saved when any row is invalid.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		importResult, err := exchange_rate_service.ImportService(util.GetConfigByKey("cli.owner.id"), file)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported exchange rates: %d created, %d updated\n", importResult.Created, importResult.Updated)
+		return nil
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVarP(
+		&filePath, "input", "i", "", "CSV file to import (required)")
+
+	importCmd.MarkFlagRequired("input")
+	RateCmd.AddCommand(importCmd)
+}
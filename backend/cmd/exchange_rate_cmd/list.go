@@ -0,0 +1,35 @@
+package exchange_rate_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list all exchange rates",
+	Long:  `List all exchange rates, grouped by pair with the newest rate first.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exchangeRateEntityList, totalCount, err := exchange_rate_service.ListAllService(util.GetConfigByKey("cli.owner.id"), 0, 0)
+		if err != nil {
+			return err
+		}
+
+		if len(exchangeRateEntityList) == 0 {
+			fmt.Println("No exchange rates found")
+			return nil
+		}
+		for index, exchangeRateEntity := range exchangeRateEntityList {
+			fmt.Println("exchange_rate ", index, ": ", exchangeRateEntity.ToString())
+		}
+		fmt.Printf("\nTotal exchange rates: %d\n", totalCount)
+		return nil
+	},
+}
+
+func init() {
+	RateCmd.AddCommand(listCmd)
+}
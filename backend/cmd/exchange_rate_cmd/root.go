@@ -0,0 +1,33 @@
+package exchange_rate_cmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	plainId       string
+	fromCurrency  string
+	toCurrency    string
+	plainRate     string
+	effectiveDate string
+	filePath      string
+)
+
+var RateCmd = &cobra.Command{
+	Use:   "rate",
+	Short: "manage exchange rates between currencies",
+	Long: `Manage the locally kept exchange rates used to report in another currency.
+A rate applies from its effective date until the next rate of the same pair.
+
+Available sub-commands:
+  create - Create or replace the rate of a pair on a date
+  import - Import rates from a CSV file
+  list   - List all rates
+  delete - Delete rate`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.New("must provide a valid sub command")
+	},
+}
@@ -0,0 +1,36 @@
+package goal_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/goal_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var contributionsCmd = &cobra.Command{
+	Use:   "contributions",
+	Short: "list the cash_flows counting toward one goal",
+	Long: `List the cash_flows booked to the goal's account from its start date up to today,
+including the outcomes and transfers out that count against it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cashFlowList, err := goal_service.ContributionsService(util.GetConfigByKey("cli.owner.id"), plainId, goalName)
+		if err != nil {
+			return err
+		}
+
+		if len(cashFlowList) == 0 {
+			fmt.Println("No contributions found")
+			return nil
+		}
+		for index, cashFlowEntity := range cashFlowList {
+			fmt.Println("cash_flow", index, ":", cashFlowEntity.ToString())
+		}
+		return nil
+	},
+}
+
+func init() {
+	addQueryFlags(contributionsCmd)
+	GoalCmd.AddCommand(contributionsCmd)
+}
@@ -0,0 +1,34 @@
+package goal_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/goal_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create new goal",
+	Long: `Create a savings goal on an account.
+The target is in the account's currency and must be reached by the deadline.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		goalEntity, err := goal_service.CreateService(util.GetConfigByKey("cli.owner.id"), buildGoalDTO())
+		if err != nil {
+			return err
+		}
+		fmt.Println("goal ", 0, ": ", goalEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	addGoalFlags(createCmd)
+
+	createCmd.MarkFlagRequired("name")
+	createCmd.MarkFlagRequired("account")
+	createCmd.MarkFlagRequired("amount")
+	createCmd.MarkFlagRequired("deadline")
+	GoalCmd.AddCommand(createCmd)
+}
@@ -0,0 +1,32 @@
+package goal_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/goal_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "delete goal",
+	Long: `Delete a goal by its ID.
+The cash_flows that counted toward it are kept.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		goalEntity, err := goal_service.DeleteService(util.GetConfigByKey("cli.owner.id"), plainId)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Deleted goal:", goalEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	deleteCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "goal id (required)")
+
+	deleteCmd.MarkFlagRequired("id")
+	GoalCmd.AddCommand(deleteCmd)
+}
@@ -0,0 +1,37 @@
+package goal_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/goal_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list all goals with their progress",
+	Long:  `List all goals with how much is saved and whether they are on track.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		progressList, err := goal_service.ListProgressService(util.GetConfigByKey("cli.owner.id"))
+		if err != nil {
+			return err
+		}
+
+		if len(progressList) == 0 {
+			fmt.Println("No goals found")
+			return nil
+		}
+		for _, progress := range progressList {
+			fmt.Printf("%-20s %s %12s / %-12s %6s%%  due %s  %s\n", progress.Name, progress.Currency,
+				progress.Saved.StringFixed(2), progress.TargetAmount.StringFixed(2),
+				progress.PercentComplete.StringFixed(2), progress.Deadline, progress.Status)
+		}
+		fmt.Printf("\nTotal goals: %d\n", len(progressList))
+		return nil
+	},
+}
+
+func init() {
+	GoalCmd.AddCommand(listCmd)
+}
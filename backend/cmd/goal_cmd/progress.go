@@ -0,0 +1,42 @@
+package goal_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/goal_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var progressCmd = &cobra.Command{
+	Use:   "progress",
+	Short: "show the progress of one goal",
+	Long: `Show how much of a goal is saved, the monthly contribution still required
+to reach it by the deadline, and whether it is on track.
+A goal is on track while it has saved at least as much as an even pace would have by today.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		progress, err := goal_service.ProgressService(util.GetConfigByKey("cli.owner.id"), plainId, goalName)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("=== Goal: %s ===\n", progress.Name)
+		fmt.Printf("Account:          %s (%s)\n", progress.AccountName, progress.Currency)
+		fmt.Printf("Period:           %s to %s\n", progress.StartDate, progress.Deadline)
+		fmt.Printf("Target:           %s\n", progress.TargetAmount.StringFixed(2))
+		fmt.Printf("Contributed:      %s (%d contributions)\n", progress.Contributed.StringFixed(2), progress.ContributionCount)
+		fmt.Printf("Withdrawn:        %s\n", progress.Withdrawn.StringFixed(2))
+		fmt.Printf("Saved:            %s (%s%%)\n", progress.Saved.StringFixed(2), progress.PercentComplete.StringFixed(2))
+		fmt.Printf("Expected by now:  %s\n", progress.ExpectedSaved.StringFixed(2))
+		fmt.Printf("Remaining:        %s\n", progress.Remaining.StringFixed(2))
+		fmt.Printf("Months left:      %d\n", progress.MonthsLeft)
+		fmt.Printf("Required monthly: %s\n", progress.RequiredMonthly.StringFixed(2))
+		fmt.Printf("Status:           %s\n", progress.Status)
+		return nil
+	},
+}
+
+func init() {
+	addQueryFlags(progressCmd)
+	GoalCmd.AddCommand(progressCmd)
+}
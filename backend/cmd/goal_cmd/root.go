@@ -0,0 +1,71 @@
+package goal_cmd
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	plainId      string
+	goalName     string
+	accountName  string
+	targetAmount float64
+	startDate    string
+	deadline     string
+)
+
+var GoalCmd = &cobra.Command{
+	Use:   "goal",
+	Short: "manage savings goals",
+	Long: `Manage savings goals: a target amount to save into an account by a deadline.
+Every cash_flow booked to the account from the start date on counts toward the goal,
+outcomes and transfers out count against it.
+
+Available sub-commands:
+  create        - Create new goal
+  update        - Update existing goal
+  delete        - Delete goal
+  list          - List all goals with their progress
+  progress      - Show the progress of one goal
+  contributions - List the cash_flows counting toward one goal`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.New("must provide a valid sub command")
+	},
+}
+
+// buildGoalDTO collects the flags shared by create and update
+func buildGoalDTO() model.GoalDTO {
+	return model.GoalDTO{
+		Name:         goalName,
+		AccountName:  accountName,
+		TargetAmount: decimal.NewFromFloat(targetAmount),
+		StartDate:    startDate,
+		Deadline:     deadline,
+	}
+}
+
+// addGoalFlags registers the goal's fields, required marks are left to each command
+func addGoalFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(
+		&goalName, "name", "n", "", "goal's name")
+	cmd.Flags().StringVar(
+		&accountName, "account", "", "account the savings are kept in, the goal takes its currency")
+	cmd.Flags().Float64VarP(
+		&targetAmount, "amount", "a", 0.00, "target amount to save")
+	cmd.Flags().StringVarP(
+		&startDate, "start", "s", "", "first date contributions count (optional, blank for today)")
+	cmd.Flags().StringVarP(
+		&deadline, "deadline", "e", "", "date the target should be reached by")
+}
+
+// addQueryFlags registers the flags that find one goal by either its id or its name
+func addQueryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "goal id")
+	cmd.Flags().StringVarP(
+		&goalName, "name", "n", "", "goal's name")
+}
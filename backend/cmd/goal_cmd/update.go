@@ -0,0 +1,33 @@
+package goal_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/goal_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "update existing goal",
+	Long: `Update an existing goal by its ID, blank fields are kept.
+Moving the goal to another account also takes that account's currency.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		goalEntity, err := goal_service.UpdateService(util.GetConfigByKey("cli.owner.id"), plainId, buildGoalDTO())
+		if err != nil {
+			return err
+		}
+		fmt.Println("Updated goal:", goalEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	updateCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "goal id (required)")
+	addGoalFlags(updateCmd)
+
+	updateCmd.MarkFlagRequired("id")
+	GoalCmd.AddCommand(updateCmd)
+}
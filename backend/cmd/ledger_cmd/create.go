@@ -0,0 +1,53 @@
+package ledger_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/ledger_service"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create a shared ledger",
+	Long: `Create a ledger to share with others, for example
+  cashlens --user alice ledger create -n Household
+The user becomes its owner.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userPlainId, err := currentUserPlainId()
+		if err != nil {
+			return err
+		}
+		ledgerView, err := ledger_service.CreateService(userPlainId, model.LedgerDTO{Name: ledgerName})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("ledger 0: %s (%s), role %s\n", ledgerView.Name, ledgerView.Id, ledgerView.Role)
+		return nil
+	},
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the user's ledgers",
+	Long:  `List the ledgers the user can work on, their personal ledger first.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userPlainId, err := currentUserPlainId()
+		if err != nil {
+			return err
+		}
+		for index, ledgerView := range ledger_service.ListService(userPlainId) {
+			fmt.Printf("ledger %d: %s (%s), role %s\n", index, ledgerView.Name, ledgerView.Id, ledgerView.Role)
+		}
+		return nil
+	},
+}
+
+func init() {
+	createCmd.Flags().StringVarP(&ledgerName, "name", "n", "", "name of the ledger")
+	createCmd.MarkFlagRequired("name")
+
+	LedgerCmd.AddCommand(createCmd)
+	LedgerCmd.AddCommand(listCmd)
+}
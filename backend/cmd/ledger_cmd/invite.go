@@ -0,0 +1,62 @@
+package ledger_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/ledger_service"
+	"github.com/spf13/cobra"
+)
+
+var inviteCmd = &cobra.Command{
+	Use:   "invite",
+	Short: "invite someone to a ledger",
+	Long: `Invite someone to a shared ledger with a role, for example
+  cashlens --user alice ledger invite -i <ledger id> -r EDITOR
+Only owners may invite. The token is printed only once, hand it over to the invited user;
+it can be used once within 7 days.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userPlainId, err := currentUserPlainId()
+		if err != nil {
+			return err
+		}
+		invitationView, err := ledger_service.InviteService(userPlainId, plainId, model.LedgerRoleDTO{Role: role})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("invitation 0: %s as %s, expires %s\n",
+			invitationView.LedgerId, invitationView.Role, invitationView.ExpireTime.Format("2006-01-02 15:04"))
+		fmt.Println("Token:", invitationView.Token)
+		return nil
+	},
+}
+
+var acceptCmd = &cobra.Command{
+	Use:   "accept",
+	Short: "join a ledger with an invitation token",
+	Long: `Join the ledger of an invitation, for example
+  cashlens --user bob ledger accept -t clinv_...`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userPlainId, err := currentUserPlainId()
+		if err != nil {
+			return err
+		}
+		ledgerView, err := ledger_service.AcceptInvitationService(userPlainId, model.LedgerInvitationAcceptDTO{Token: token})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("ledger 0: %s (%s), role %s\n", ledgerView.Name, ledgerView.Id, ledgerView.Role)
+		return nil
+	},
+}
+
+func init() {
+	inviteCmd.Flags().StringVarP(&plainId, "id", "i", "", "id of the ledger")
+	inviteCmd.Flags().StringVarP(&role, "role", "r", model.LedgerRoleViewer, "role of the invited user: OWNER, EDITOR or VIEWER")
+	inviteCmd.MarkFlagRequired("id")
+	acceptCmd.Flags().StringVarP(&token, "token", "t", "", "invitation token")
+	acceptCmd.MarkFlagRequired("token")
+
+	LedgerCmd.AddCommand(inviteCmd)
+	LedgerCmd.AddCommand(acceptCmd)
+}
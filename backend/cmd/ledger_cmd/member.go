@@ -0,0 +1,101 @@
+package ledger_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/ledger_service"
+	"github.com/macar-x/cashlens/service/user_service"
+	"github.com/spf13/cobra"
+)
+
+var membersCmd = &cobra.Command{
+	Use:   "members",
+	Short: "list the members of a ledger",
+	Long:  `List the members of a ledger the user belongs to and their roles, oldest first.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userPlainId, err := currentUserPlainId()
+		if err != nil {
+			return err
+		}
+		memberList, err := ledger_service.ListMembersService(userPlainId, plainId)
+		if err != nil {
+			return err
+		}
+		for index, memberView := range memberList {
+			fmt.Printf("member %d: %s (%s), role %s\n", index, memberView.Username, memberView.UserId, memberView.Role)
+		}
+		return nil
+	},
+}
+
+var memberRoleCmd = &cobra.Command{
+	Use:   "member-role",
+	Short: "change the role of a member",
+	Long: `Change the role of a ledger member, for example
+  cashlens --user alice ledger member-role -i <ledger id> -m bob -r EDITOR
+Only owners may do so, and a ledger keeps at least one owner.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userPlainId, err := currentUserPlainId()
+		if err != nil {
+			return err
+		}
+		if err := resolveMember(); err != nil {
+			return err
+		}
+		memberView, err := ledger_service.UpdateMemberService(userPlainId, plainId, memberPlainId, model.LedgerRoleDTO{Role: role})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("member 0: %s (%s), role %s\n", memberView.Username, memberView.UserId, memberView.Role)
+		return nil
+	},
+}
+
+var memberRemoveCmd = &cobra.Command{
+	Use:   "member-remove",
+	Short: "remove a member from a ledger",
+	Long: `Remove a member from a ledger. Owners may remove anyone, other members only themselves
+to leave the ledger; a ledger keeps at least one owner.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userPlainId, err := currentUserPlainId()
+		if err != nil {
+			return err
+		}
+		if err := resolveMember(); err != nil {
+			return err
+		}
+		if err := ledger_service.RemoveMemberService(userPlainId, plainId, memberPlainId); err != nil {
+			return err
+		}
+		fmt.Println("Removed member:", memberName)
+		return nil
+	},
+}
+
+// resolveMember looks the id of the member up by their username
+func resolveMember() error {
+	userEntity, err := user_service.QueryUserByUsername(memberName)
+	if err != nil {
+		return err
+	}
+	memberPlainId = userEntity.Id.Hex()
+	return nil
+}
+
+func init() {
+	for _, command := range []*cobra.Command{membersCmd, memberRoleCmd, memberRemoveCmd} {
+		command.Flags().StringVarP(&plainId, "id", "i", "", "id of the ledger")
+		command.MarkFlagRequired("id")
+	}
+	for _, command := range []*cobra.Command{memberRoleCmd, memberRemoveCmd} {
+		command.Flags().StringVarP(&memberName, "member", "m", "", "username of the member")
+		command.MarkFlagRequired("member")
+	}
+	memberRoleCmd.Flags().StringVarP(&role, "role", "r", "", "new role: OWNER, EDITOR or VIEWER")
+	memberRoleCmd.MarkFlagRequired("role")
+
+	LedgerCmd.AddCommand(membersCmd)
+	LedgerCmd.AddCommand(memberRoleCmd)
+	LedgerCmd.AddCommand(memberRemoveCmd)
+}
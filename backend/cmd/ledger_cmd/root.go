@@ -0,0 +1,47 @@
+package ledger_cmd
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	plainId       string
+	ledgerName    string
+	role          string
+	token         string
+	memberName    string
+	memberPlainId string
+)
+
+var LedgerCmd = &cobra.Command{
+	Use:   "ledger",
+	Short: "manage shared ledgers, their members and invitations",
+	Long: `Manage the ledgers shared between users, acting as the user given by --user.
+Every user has a personal ledger; a shared ledger has owners, editors and viewers.
+Pick a shared ledger for the other commands with --ledger <id>.
+
+Available sub-commands:
+  create        - Create a shared ledger
+  list          - List the user's ledgers
+  invite        - Invite someone to a ledger, prints a token to hand over
+  accept        - Join a ledger with an invitation token
+  members       - List the members of a ledger
+  member-role   - Change the role of a member
+  member-remove - Remove a member, or leave a ledger`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.New("must provide a valid sub command")
+	},
+}
+
+// currentUserPlainId returns the user the command acts as, every ledger command needs one
+func currentUserPlainId() (string, error) {
+	userPlainId := util.GetConfigByKey("cli.user.id")
+	if userPlainId == "" {
+		return "", errors.New("ledger commands need a user, give --user or set CLI_USER")
+	}
+	return userPlainId, nil
+}
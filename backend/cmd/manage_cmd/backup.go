@@ -20,12 +20,25 @@ If no path is specified, creates backup in current directory with timestamp.`,
 			backupPath = fmt.Sprintf("cashlens_backup_%s.json", time.Now().Format("20060102_150405"))
 		}
 
-		err := manage_service.CreateBackup(backupPath)
+		backup, err := manage_service.CreateBackup(backupPath)
 		if err != nil {
 			return err
 		}
 
 		fmt.Printf("Backup created successfully: %s\n", backupPath)
+		fmt.Printf("  - Categories: %d\n", len(backup.Categories))
+		fmt.Printf("  - Accounts:   %d\n", len(backup.Accounts))
+		fmt.Printf("  - Cash flows: %d\n", len(backup.CashFlows))
+		fmt.Printf("  - Exchange rates: %d\n", len(backup.ExchangeRates))
+		fmt.Printf("  - Recurring rules: %d\n", len(backup.RecurringRules))
+		fmt.Printf("  - Budgets: %d\n", len(backup.Budgets))
+		fmt.Printf("  - Goals: %d\n", len(backup.Goals))
+		fmt.Printf("  - Payees: %d\n", len(backup.Payees))
+		fmt.Printf("  - Category rules: %d\n", len(backup.CategoryRules))
+		fmt.Printf("  - Users: %d\n", len(backup.Users))
+		fmt.Printf("  - API tokens: %d\n", len(backup.ApiTokens))
+		fmt.Printf("  - Ledgers: %d (%d members, %d invitations)\n",
+			len(backup.Ledgers), len(backup.LedgerMembers), len(backup.LedgerInvitations))
 		return nil
 	},
 }
@@ -2,6 +2,7 @@ package manage_cmd
 
 import (
 	"github.com/macar-x/cashlens/service/manage_service"
+	"github.com/macar-x/cashlens/util"
 	"github.com/spf13/cobra"
 )
 
@@ -9,7 +10,7 @@ var exportCmd = &cobra.Command{
 	Use:   "export",
 	Short: "export data to excel",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return manage_service.ExportService(fromDate, toDate, filePath)
+		return manage_service.ExportService(util.GetConfigByKey("cli.owner.id"), fromDate, toDate, filePath, currency)
 	},
 }
 
@@ -17,5 +18,6 @@ func init() {
 	exportCmd.Flags().StringVarP(&fromDate, "from", "f", "", "from date(include), e.x. 19700101")
 	exportCmd.Flags().StringVarP(&toDate, "to", "t", "", "to date(include), e.x. 19700101")
 	exportCmd.Flags().StringVarP(&filePath, "output", "o", "", "output path, default ./export.xlsx")
+	exportCmd.Flags().StringVar(&currency, "currency", "", "add each amount converted into this currency, e.x. USD")
 	ManageCmd.AddCommand(exportCmd)
 }
@@ -2,6 +2,7 @@ package manage_cmd
 
 import (
 	"github.com/macar-x/cashlens/service/manage_service"
+	"github.com/macar-x/cashlens/util"
 	"github.com/spf13/cobra"
 )
 
@@ -9,11 +10,13 @@ var importCmd = &cobra.Command{
 	Use:   "import",
 	Short: "import data from excel",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return manage_service.ImportService(filePath)
+		return manage_service.ImportService(util.GetConfigByKey("cli.owner.id"), util.GetConfigByKey("cli.user.id"), filePath, minConfidence)
 	},
 }
 
 func init() {
 	importCmd.Flags().StringVarP(&filePath, "input", "i", "", "input path, e.x. ~/export.xlsx")
+	importCmd.Flags().Float64Var(&minConfidence, "min-confidence", 0,
+		"apply the suggested category to rows without one when its confidence is at least this, 0 to 1 (optional, 0 never applies)")
 	ManageCmd.AddCommand(importCmd)
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/macar-x/cashlens/service/manage_service"
+	"github.com/macar-x/cashlens/util"
 	"github.com/spf13/cobra"
 )
 
@@ -13,7 +14,7 @@ var initCmd = &cobra.Command{
 	Long: `Initialize the database with demo categories and sample transactions.
 Useful for testing and development.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		err := manage_service.InitializeDemoData()
+		err := manage_service.InitializeDemoData(util.GetConfigByKey("cli.owner.id"))
 		if err != nil {
 			return err
 		}
@@ -5,47 +5,96 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/macar-x/cashlens/service/manage_service"
+	"github.com/macar-x/cashlens/util"
 	"github.com/spf13/cobra"
 )
 
-var forceReset bool
+var (
+	forceReset      bool
+	resetScope      string
+	resetBackupPath string
+)
 
 var resetCmd = &cobra.Command{
 	Use:   "reset",
 	Short: "clear all database data",
-	Long: `Clear all data from the database.
-WARNING: This operation cannot be undone. Create a backup first!`,
+	Long: `Clear data from the database.
+A backup is always written before anything is deleted, and the database cleared
+must be typed to confirm: its name, the SQLite file path, or "memory" for the
+in-memory database. Use --scope to clear only cash_flows or categories.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if resetBackupPath == "" {
+			resetBackupPath = fmt.Sprintf("cashlens_pre_reset_%s.json", time.Now().Format("20060102_150405"))
+		}
+
 		if !forceReset {
-			fmt.Println("⚠️  WARNING: This will DELETE ALL DATA from the database!")
-			fmt.Println("This operation cannot be undone.")
-			fmt.Print("Type 'DELETE ALL' to confirm: ")
+			targetLabel, confirmation := describeResetTarget()
+			fmt.Printf("⚠️  WARNING: This will DELETE %s from %s!\n", describeResetScope(resetScope), targetLabel)
+			fmt.Printf("A backup will be written to %s first.\n", resetBackupPath)
+			fmt.Printf("Type '%s' to confirm: ", confirmation)
 
 			reader := bufio.NewReader(os.Stdin)
 			response, _ := reader.ReadString('\n')
 			response = strings.TrimSpace(response)
 
-			if response != "DELETE ALL" {
+			if confirmation == "" || response != confirmation {
 				fmt.Println("Reset cancelled")
 				return nil
 			}
 		}
 
-		err := manage_service.ResetDatabase()
+		result, err := manage_service.ResetDatabase(resetScope, resetBackupPath)
 		if err != nil {
+			if result != nil {
+				fmt.Printf("Reset failed, data can be recovered from: %s\n", result.BackupPath)
+			}
 			return err
 		}
 
-		fmt.Println("✅ Database reset successfully - all data cleared")
+		fmt.Printf("✅ Database reset successfully (scope: %s)\n", result.Scope)
+		fmt.Printf("  - Backup:  %s\n", result.BackupPath)
+		fmt.Printf("  - Deleted: %d categories, %d accounts, %d cash flows, %d exchange rates, %d recurring rules, %d budgets, %d goals, %d payees, %d category rules\n",
+			result.CategoriesDeleted, result.AccountsDeleted, result.CashFlowsDeleted, result.ExchangeRatesDeleted,
+			result.RecurringRulesDeleted, result.BudgetsDeleted, result.GoalsDeleted, result.PayeesDeleted, result.CategoryRulesDeleted)
 		return nil
 	},
 }
 
+// describeResetTarget names the data the reset destroys and what has to be typed to confirm it
+func describeResetTarget() (string, string) {
+	switch util.GetConfigByKey("db.type") {
+	case "sqlite":
+		sqlitePath := util.GetConfigByKey("db.sqlite.path")
+		return fmt.Sprintf("SQLite file '%s'", sqlitePath), sqlitePath
+	case "memory":
+		return "the in-memory database", "memory"
+	default:
+		databaseName := util.GetConfigByKey("db.name")
+		return fmt.Sprintf("database '%s'", databaseName), databaseName
+	}
+}
+
+func describeResetScope(scope string) string {
+	switch scope {
+	case manage_service.ResetScopeCashFlows:
+		return "ALL CASH FLOWS"
+	case manage_service.ResetScopeCategories:
+		return "ALL CATEGORIES"
+	default:
+		return "ALL DATA"
+	}
+}
+
 func init() {
 	resetCmd.Flags().BoolVarP(
 		&forceReset, "force", "f", false, "skip confirmation prompt (dangerous!)")
+	resetCmd.Flags().StringVarP(
+		&resetScope, "scope", "s", manage_service.ResetScopeAll, "what to clear: all, cash_flows or categories")
+	resetCmd.Flags().StringVarP(
+		&resetBackupPath, "output", "o", "", "pre-reset backup file path (optional, default: cashlens_pre_reset_TIMESTAMP.json)")
 
 	ManageCmd.AddCommand(resetCmd)
 }
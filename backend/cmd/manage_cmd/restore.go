@@ -14,6 +14,7 @@ import (
 var (
 	restorePath  string
 	forceRestore bool
+	mergeRestore bool
 )
 
 var restoreCmd = &cobra.Command{
@@ -26,7 +27,12 @@ WARNING: This will replace all existing data unless --merge is used.`,
 			return errors.New("backup file path is required")
 		}
 
-		if !forceRestore {
+		restoreMode := manage_service.RestoreModeReplace
+		if mergeRestore {
+			restoreMode = manage_service.RestoreModeMerge
+		}
+
+		if !forceRestore && restoreMode == manage_service.RestoreModeReplace {
 			fmt.Println("WARNING: This will replace all existing data!")
 			fmt.Print("Are you sure you want to continue? (yes/no): ")
 
@@ -40,12 +46,37 @@ WARNING: This will replace all existing data unless --merge is used.`,
 			}
 		}
 
-		err := manage_service.RestoreBackup(restorePath)
+		result, err := manage_service.RestoreBackup(restorePath, restoreMode)
 		if err != nil {
+			if result != nil && result.RolledBack {
+				fmt.Println("Restore failed, all changes have been rolled back")
+			}
 			return err
 		}
 
-		fmt.Printf("Database restored successfully from: %s\n", restorePath)
+		fmt.Printf("Database restored successfully from: %s (mode: %s)\n", restorePath, result.Mode)
+		if result.Mode == manage_service.RestoreModeReplace {
+			fmt.Printf("  - Cleared:  %d categories, %d accounts, %d cash flows, %d exchange rates, %d recurring rules, %d budgets, %d goals, %d payees, %d category rules\n",
+				result.CategoriesCleared, result.AccountsCleared, result.CashFlowsCleared, result.ExchangeRatesCleared,
+				result.RecurringRulesCleared, result.BudgetsCleared, result.GoalsCleared, result.PayeesCleared, result.CategoryRulesCleared)
+			fmt.Printf("            %d users, %d API tokens, %d ledgers, %d ledger members, %d ledger invitations\n",
+				result.UsersCleared, result.ApiTokensCleared, result.LedgersCleared,
+				result.LedgerMembersCleared, result.LedgerInvitationsCleared)
+		}
+		fmt.Printf("  - Restored: %d categories, %d accounts, %d cash flows, %d exchange rates, %d recurring rules, %d budgets, %d goals, %d payees, %d category rules\n",
+			result.CategoriesRestored, result.AccountsRestored, result.CashFlowsRestored, result.ExchangeRatesRestored,
+			result.RecurringRulesRestored, result.BudgetsRestored, result.GoalsRestored, result.PayeesRestored, result.CategoryRulesRestored)
+		fmt.Printf("            %d users, %d API tokens, %d ledgers, %d ledger members, %d ledger invitations\n",
+			result.UsersRestored, result.ApiTokensRestored, result.LedgersRestored,
+			result.LedgerMembersRestored, result.LedgerInvitationsRestored)
+		if result.Mode == manage_service.RestoreModeMerge {
+			fmt.Printf("  - Skipped:  %d categories, %d accounts, %d cash flows, %d exchange rates, %d recurring rules, %d budgets, %d goals, %d payees, %d category rules (already present)\n",
+				result.CategoriesSkipped, result.AccountsSkipped, result.CashFlowsSkipped, result.ExchangeRatesSkipped,
+				result.RecurringRulesSkipped, result.BudgetsSkipped, result.GoalsSkipped, result.PayeesSkipped, result.CategoryRulesSkipped)
+			fmt.Printf("            %d users, %d API tokens, %d ledgers, %d ledger members, %d ledger invitations (already present)\n",
+				result.UsersSkipped, result.ApiTokensSkipped, result.LedgersSkipped,
+				result.LedgerMembersSkipped, result.LedgerInvitationsSkipped)
+		}
 		return nil
 	},
 }
@@ -55,6 +86,8 @@ func init() {
 		&restorePath, "input", "i", "", "backup file path (required)")
 	restoreCmd.Flags().BoolVarP(
 		&forceRestore, "force", "f", false, "skip confirmation prompt")
+	restoreCmd.Flags().BoolVarP(
+		&mergeRestore, "merge", "m", false, "keep existing data and only add records missing from the database")
 
 	restoreCmd.MarkFlagRequired("input")
 	ManageCmd.AddCommand(restoreCmd)
@@ -7,9 +7,11 @@ import (
 )
 
 var (
-	fromDate string
-	toDate   string
-	filePath string
+	fromDate      string
+	toDate        string
+	filePath      string
+	currency      string
+	minConfidence float64
 )
 
 var ManageCmd = &cobra.Command{
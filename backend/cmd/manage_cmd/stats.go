@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/macar-x/cashlens/service/manage_service"
+	"github.com/macar-x/cashlens/util"
 	"github.com/spf13/cobra"
 )
 
@@ -12,7 +13,7 @@ var statsCmd = &cobra.Command{
 	Short: "show database statistics",
 	Long:  `Display statistics about the database including record counts and storage info.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		stats, err := manage_service.GetDatabaseStats()
+		stats, err := manage_service.GetDatabaseStats(util.GetConfigByKey("cli.owner.id"))
 		if err != nil {
 			return err
 		}
@@ -22,14 +23,27 @@ var statsCmd = &cobra.Command{
 		fmt.Printf("  - Income:         %d\n", stats.IncomeCount)
 		fmt.Printf("  - Expense:        %d\n", stats.ExpenseCount)
 		fmt.Printf("Categories:         %d\n", stats.CategoryCount)
-		fmt.Printf("\nFinancial Summary:\n")
-		fmt.Printf("  Total Income:     %.2f\n", stats.TotalIncome)
-		fmt.Printf("  Total Expense:    %.2f\n", stats.TotalExpense)
-		fmt.Printf("  Balance:          %.2f\n", stats.Balance)
+		for _, totals := range stats.Totals {
+			fmt.Printf("\nFinancial Summary (%s):\n", totals.Currency)
+			fmt.Printf("  Total Income:     %s\n", totals.TotalIncome.StringFixed(2))
+			fmt.Printf("  Total Expense:    %s\n", totals.TotalExpense.StringFixed(2))
+			fmt.Printf("  Balance:          %s\n", totals.Balance.StringFixed(2))
+		}
 		fmt.Printf("\nDate Range:\n")
 		fmt.Printf("  Earliest:         %s\n", stats.EarliestDate)
 		fmt.Printf("  Latest:           %s\n", stats.LatestDate)
 
+		if len(stats.Categories) > 0 {
+			fmt.Printf("\nCash Flows by Category:\n")
+			for _, categoryStats := range stats.Categories {
+				categoryName := categoryStats.CategoryName
+				if categoryName == "" {
+					categoryName = "(unknown " + categoryStats.CategoryId + ")"
+				}
+				fmt.Printf("  %-18s %6d  %12s %s\n", categoryName, categoryStats.CashFlowCount, categoryStats.TotalAmount.StringFixed(2), categoryStats.Currency)
+			}
+		}
+
 		return nil
 	},
 }
@@ -0,0 +1,31 @@
+package payee_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/payee_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var applyRulesCmd = &cobra.Command{
+	Use:   "apply-rules",
+	Short: "run the payee rules over the existing cash_flows",
+	Long: `Run the payee rules over the descriptions of the existing cash_flows.
+Only cash_flows without a payee are changed, unless --overwrite lets the rules replace a payee.
+Running again is safe, a cash_flow already assigned to the matching payee is left as is.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		updatedCount, err := payee_service.ApplyRulesService(util.GetConfigByKey("cli.owner.id"), isOverwrite)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Updated cash_flows: %d\n", updatedCount)
+		return nil
+	},
+}
+
+func init() {
+	applyRulesCmd.Flags().BoolVar(
+		&isOverwrite, "overwrite", false, "also replace the payee of cash_flows which already have one")
+	PayeeCmd.AddCommand(applyRulesCmd)
+}
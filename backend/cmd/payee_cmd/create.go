@@ -0,0 +1,37 @@
+package payee_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/payee_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create new payee",
+	Long: `Create a payee with optional normalization rules, for example
+  cashlens payee create -n Starbucks --contains starbucks`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		payeeEntity, err := payee_service.CreateService(util.GetConfigByKey("cli.owner.id"), model.PayeeDTO{
+			Name:  payeeName,
+			Rules: buildRules(),
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println("payee ", 0, ": ", payeeEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	createCmd.Flags().StringVarP(
+		&payeeName, "name", "n", "", "payee's name (required)")
+	addRuleFlags(createCmd)
+
+	createCmd.MarkFlagRequired("name")
+	PayeeCmd.AddCommand(createCmd)
+}
@@ -0,0 +1,32 @@
+package payee_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/payee_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "delete payee",
+	Long: `Delete a payee by its ID.
+A payee can not be deleted while cash_flows refer to it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		payeeEntity, err := payee_service.DeleteService(util.GetConfigByKey("cli.owner.id"), plainId)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Deleted payee:", payeeEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	deleteCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "payee id (required)")
+
+	deleteCmd.MarkFlagRequired("id")
+	PayeeCmd.AddCommand(deleteCmd)
+}
@@ -0,0 +1,35 @@
+package payee_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/payee_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list all payees",
+	Long:  `List all payees with their rules.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		payeeEntityList, totalCount, err := payee_service.ListAllService(util.GetConfigByKey("cli.owner.id"), 0, 0)
+		if err != nil {
+			return err
+		}
+
+		if len(payeeEntityList) == 0 {
+			fmt.Println("No payees found")
+			return nil
+		}
+		for index, payeeEntity := range payeeEntityList {
+			fmt.Println("payee ", index, ": ", payeeEntity.ToString())
+		}
+		fmt.Printf("\nTotal payees: %d\n", totalCount)
+		return nil
+	},
+}
+
+func init() {
+	PayeeCmd.AddCommand(listCmd)
+}
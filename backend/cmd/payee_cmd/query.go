@@ -0,0 +1,30 @@
+package payee_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/payee_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "query for payee data",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		payeeEntity, err := payee_service.QueryService(util.GetConfigByKey("cli.owner.id"), plainId, payeeName)
+		if err != nil {
+			return err
+		}
+		fmt.Println("payee ", 0, ": ", payeeEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	queryCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "query by id")
+	queryCmd.Flags().StringVarP(
+		&payeeName, "name", "n", "", "query by name")
+	PayeeCmd.AddCommand(queryCmd)
+}
@@ -0,0 +1,61 @@
+package payee_cmd
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	plainId      string
+	payeeName    string
+	containsList []string
+	regexList    []string
+	isClearRules bool
+	isOverwrite  bool
+	fromDate     string
+	toDate       string
+	currency     string
+)
+
+var PayeeCmd = &cobra.Command{
+	Use:   "payee",
+	Short: "manage payees and their normalization rules",
+	Long: `Manage payees: the merchants or people cash_flows are paid to or received from.
+A payee's rules recognise it in raw descriptions such as "STARBUCKS #1234", they are applied
+when a cash_flow is created or imported without a payee.
+
+Available sub-commands:
+  create      - Create new payee
+  update      - Update existing payee
+  delete      - Delete payee
+  list        - List all payees
+  query       - Query payee by id or name
+  apply-rules - Run the rules over the existing cash_flows
+  spend       - Show how much was spent per payee`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.New("must provide a valid sub command")
+	},
+}
+
+// buildRules collects the rule flags, CONTAINS rules are tried before REGEX ones
+func buildRules() []model.PayeeRule {
+	var ruleList []model.PayeeRule
+	for _, pattern := range containsList {
+		ruleList = append(ruleList, model.PayeeRule{MatchType: model.PayeeRuleContains, Pattern: pattern})
+	}
+	for _, pattern := range regexList {
+		ruleList = append(ruleList, model.PayeeRule{MatchType: model.PayeeRuleRegex, Pattern: pattern})
+	}
+	return ruleList
+}
+
+// addRuleFlags registers the flags describing a payee's rules
+func addRuleFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(
+		&containsList, "contains", nil, "description contains this text, ignoring case (repeatable)")
+	cmd.Flags().StringArrayVar(
+		&regexList, "regex", nil, "description matches this regular expression, add (?i) to ignore case (repeatable)")
+}
@@ -0,0 +1,51 @@
+package payee_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/payee_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var spendCmd = &cobra.Command{
+	Use:   "spend",
+	Short: "show how much was spent per payee",
+	Long: `Show the outcomes between two dates added up per payee, biggest spend first.
+Amounts are converted into one currency at the rate of each cash_flow's date.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spendList, err := payee_service.SpendByPayeeService(util.GetConfigByKey("cli.owner.id"), fromDate, toDate, currency)
+		if err != nil {
+			return err
+		}
+
+		if len(spendList) == 0 {
+			fmt.Println("No outcomes found")
+			return nil
+		}
+
+		fmt.Printf("=== Spend by Payee (%s to %s) ===\n", fromDate, toDate)
+		for _, payeeSpend := range spendList {
+			payeeLabel := payeeSpend.PayeeName
+			if payeeSpend.PayeeId == "" {
+				payeeLabel = "(no payee)"
+			}
+			fmt.Printf("%-30s %s %12s  %d transactions\n", payeeLabel, payeeSpend.Currency,
+				payeeSpend.TotalAmount.StringFixed(2), payeeSpend.TransactionCount)
+		}
+		return nil
+	},
+}
+
+func init() {
+	spendCmd.Flags().StringVarP(
+		&fromDate, "from", "f", "", "first date of the report (required)")
+	spendCmd.Flags().StringVarP(
+		&toDate, "to", "t", "", "last date of the report (required)")
+	spendCmd.Flags().StringVar(
+		&currency, "currency", "", "currency of the report (optional, the default currency)")
+
+	spendCmd.MarkFlagRequired("from")
+	spendCmd.MarkFlagRequired("to")
+	PayeeCmd.AddCommand(spendCmd)
+}
@@ -0,0 +1,52 @@
+package payee_cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/payee_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "update existing payee",
+	Long: `Update an existing payee by its ID, a blank name is kept.
+Rule flags replace all the current rules, --clear-rules removes them; cash_flows keep their payee.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		isRuleChanged := cmd.Flags().Changed("contains") || cmd.Flags().Changed("regex")
+		if isRuleChanged && isClearRules {
+			return errors.New("--clear-rules can not be used together with rule flags")
+		}
+
+		// Rules are only replaced when asked for, an empty list clears them
+		payeeDTO := model.PayeeDTO{Name: payeeName}
+		if isRuleChanged {
+			payeeDTO.Rules = buildRules()
+		} else if isClearRules {
+			payeeDTO.Rules = []model.PayeeRule{}
+		}
+
+		payeeEntity, err := payee_service.UpdateService(util.GetConfigByKey("cli.owner.id"), plainId, payeeDTO)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Updated payee:", payeeEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	updateCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "payee id (required)")
+	updateCmd.Flags().StringVarP(
+		&payeeName, "name", "n", "", "new name (optional)")
+	addRuleFlags(updateCmd)
+	updateCmd.Flags().BoolVar(
+		&isClearRules, "clear-rules", false, "remove every rule of the payee")
+
+	updateCmd.MarkFlagRequired("id")
+	PayeeCmd.AddCommand(updateCmd)
+}
@@ -0,0 +1,35 @@
+package recurring_rule_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/recurring_rule_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create new recurring rule",
+	Long: `Create a rule for a cash_flow that repeats.
+Nothing is booked until the rules run, by 'cashlens recurring run' or the api server.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ruleEntity, err := recurring_rule_service.CreateService(util.GetConfigByKey("cli.owner.id"), buildRuleDTO(cmd))
+		if err != nil {
+			return err
+		}
+		fmt.Println("recurring_rule ", 0, ": ", ruleEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	addRuleFlags(createCmd)
+
+	createCmd.MarkFlagRequired("name")
+	createCmd.MarkFlagRequired("type")
+	createCmd.MarkFlagRequired("category")
+	createCmd.MarkFlagRequired("amount")
+	createCmd.MarkFlagRequired("frequency")
+	RecurringCmd.AddCommand(createCmd)
+}
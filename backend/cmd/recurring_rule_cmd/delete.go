@@ -0,0 +1,32 @@
+package recurring_rule_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/recurring_rule_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "delete recurring rule",
+	Long: `Delete a recurring rule by its ID.
+The cash_flows it already booked are kept.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ruleEntity, err := recurring_rule_service.DeleteService(util.GetConfigByKey("cli.owner.id"), plainId)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Deleted recurring_rule:", ruleEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	deleteCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "recurring rule id (required)")
+
+	deleteCmd.MarkFlagRequired("id")
+	RecurringCmd.AddCommand(deleteCmd)
+}
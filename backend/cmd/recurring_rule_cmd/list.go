@@ -0,0 +1,35 @@
+package recurring_rule_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/recurring_rule_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list all recurring rules",
+	Long:  `List all recurring rules in the system.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ruleEntityList, totalCount, err := recurring_rule_service.ListAllService(util.GetConfigByKey("cli.owner.id"), 0, 0)
+		if err != nil {
+			return err
+		}
+
+		if len(ruleEntityList) == 0 {
+			fmt.Println("No recurring rules found")
+			return nil
+		}
+		for index, ruleEntity := range ruleEntityList {
+			fmt.Println("recurring_rule ", index, ": ", ruleEntity.ToString())
+		}
+		fmt.Printf("\nTotal recurring rules: %d\n", totalCount)
+		return nil
+	},
+}
+
+func init() {
+	RecurringCmd.AddCommand(listCmd)
+}
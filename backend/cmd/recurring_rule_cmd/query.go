@@ -0,0 +1,30 @@
+package recurring_rule_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/recurring_rule_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "query for recurring rule data",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ruleEntity, err := recurring_rule_service.QueryService(util.GetConfigByKey("cli.owner.id"), plainId, ruleName)
+		if err != nil {
+			return err
+		}
+		fmt.Println("recurring_rule ", 0, ": ", ruleEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	queryCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "query by id")
+	queryCmd.Flags().StringVarP(
+		&ruleName, "name", "n", "", "query by name")
+	RecurringCmd.AddCommand(queryCmd)
+}
@@ -0,0 +1,96 @@
+package recurring_rule_cmd
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	plainId         string
+	ruleName        string
+	flowType        string
+	categoryName    string
+	accountName     string
+	currency        string
+	amount          float64
+	description     string
+	frequency       string
+	dayOfMonth      int
+	startDate       string
+	endDate         string
+	occurrenceLimit int
+	untilDate       string
+)
+
+var RecurringCmd = &cobra.Command{
+	Use:   "recurring",
+	Short: "manage recurring income and outcome",
+	Long: `Manage rules for cash_flows that repeat, like rent, salary and subscriptions.
+A rule repeats DAILY, WEEKLY, MONTHLY on a day of month or on the LAST_BUSINESS_DAY,
+and may stop at an end date or after a number of occurrences.
+
+Available sub-commands:
+  create - Create new recurring rule
+  update - Update existing recurring rule
+  delete - Delete recurring rule
+  query  - Query recurring rule by id or name
+  list   - List all recurring rules
+  run    - Book the due occurrences as cash_flows`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.New("must provide a valid sub command")
+	},
+}
+
+// buildRuleDTO collects the flags shared by create and update,
+// the occurrence limit is only passed on when the flag is given because zero means unlimited
+func buildRuleDTO(cmd *cobra.Command) model.RecurringRuleDTO {
+	ruleDTO := model.RecurringRuleDTO{
+		Name:         ruleName,
+		FlowType:     flowType,
+		CategoryName: categoryName,
+		AccountName:  accountName,
+		Amount:       decimal.NewFromFloat(amount),
+		Currency:     currency,
+		Description:  description,
+		Frequency:    frequency,
+		DayOfMonth:   dayOfMonth,
+		StartDate:    startDate,
+		EndDate:      endDate,
+	}
+	if cmd.Flags().Changed("limit") {
+		ruleDTO.OccurrenceLimit = &occurrenceLimit
+	}
+	return ruleDTO
+}
+
+// addRuleFlags registers the rule's fields, required marks are left to each command
+func addRuleFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(
+		&ruleName, "name", "n", "", "rule's name")
+	cmd.Flags().StringVarP(
+		&flowType, "type", "t", "", "INCOME or OUTCOME")
+	cmd.Flags().StringVarP(
+		&categoryName, "category", "c", "", "category name of the booked cash_flows")
+	cmd.Flags().StringVar(
+		&accountName, "account", "", "account the money moves through (optional)")
+	cmd.Flags().StringVar(
+		&currency, "currency", "", "currency code (optional, the account's or the default currency)")
+	cmd.Flags().Float64VarP(
+		&amount, "amount", "a", 0.00, "amount of each occurrence")
+	cmd.Flags().StringVarP(
+		&description, "description", "d", "", "description of the booked cash_flows (optional)")
+	cmd.Flags().StringVarP(
+		&frequency, "frequency", "f", "", "DAILY, WEEKLY, MONTHLY or LAST_BUSINESS_DAY")
+	cmd.Flags().IntVar(
+		&dayOfMonth, "day", 0, "day of month for MONTHLY rules, the last day in shorter months")
+	cmd.Flags().StringVarP(
+		&startDate, "start", "s", "", "first date the rule applies (optional, blank for today)")
+	cmd.Flags().StringVarP(
+		&endDate, "end", "e", "", "last date the rule applies (optional)")
+	cmd.Flags().IntVarP(
+		&occurrenceLimit, "limit", "l", 0, "number of occurrences to book, 0 for unlimited (optional)")
+}
@@ -0,0 +1,32 @@
+package recurring_rule_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/recurring_rule_service"
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "book the due occurrences as cash_flows",
+	Long: `Book every occurrence of every rule due on or before the until date as a cash_flow.
+Running again is safe, an occurrence already booked is never booked twice.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bookedList, err := recurring_rule_service.RunAllService(untilDate)
+		for index, cashFlowEntity := range bookedList {
+			fmt.Println("cash_flow ", index, ": ", cashFlowEntity.ToString())
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nBooked cash_flows: %d\n", len(bookedList))
+		return nil
+	},
+}
+
+func init() {
+	runCmd.Flags().StringVarP(
+		&untilDate, "until", "u", "", "book occurrences up to this date (optional, blank for today)")
+	RecurringCmd.AddCommand(runCmd)
+}
@@ -0,0 +1,34 @@
+package recurring_rule_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/service/recurring_rule_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/spf13/cobra"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "update existing recurring rule",
+	Long: `Update an existing recurring rule by its ID, blank fields are kept.
+Cash_flows already booked are not changed. The schedule (frequency, day and start)
+can only change while nothing has been booked.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ruleEntity, err := recurring_rule_service.UpdateService(util.GetConfigByKey("cli.owner.id"), plainId, buildRuleDTO(cmd))
+		if err != nil {
+			return err
+		}
+		fmt.Println("Updated recurring_rule:", ruleEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	updateCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "recurring rule id (required)")
+	addRuleFlags(updateCmd)
+
+	updateCmd.MarkFlagRequired("id")
+	RecurringCmd.AddCommand(updateCmd)
+}
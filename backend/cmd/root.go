@@ -1,21 +1,47 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
+	"github.com/macar-x/cashlens/cmd/account_cmd"
+	"github.com/macar-x/cashlens/cmd/budget_cmd"
 	"github.com/macar-x/cashlens/cmd/cash_flow_cmd"
 	"github.com/macar-x/cashlens/cmd/category_cmd"
+	"github.com/macar-x/cashlens/cmd/category_rule_cmd"
 	"github.com/macar-x/cashlens/cmd/db_cmd"
+	"github.com/macar-x/cashlens/cmd/exchange_rate_cmd"
+	"github.com/macar-x/cashlens/cmd/goal_cmd"
+	"github.com/macar-x/cashlens/cmd/ledger_cmd"
 	"github.com/macar-x/cashlens/cmd/manage_cmd"
+	"github.com/macar-x/cashlens/cmd/payee_cmd"
+	"github.com/macar-x/cashlens/cmd/recurring_rule_cmd"
 	"github.com/macar-x/cashlens/cmd/server_cmd"
+	"github.com/macar-x/cashlens/cmd/user_cmd"
+	"github.com/macar-x/cashlens/service/ledger_service"
+	"github.com/macar-x/cashlens/service/user_service"
 	"github.com/macar-x/cashlens/util"
 	"github.com/macar-x/cashlens/util/database"
 	"github.com/spf13/cobra"
 )
 
+var (
+	cliUsername string
+	cliLedgerId string
+)
+
+// cliReadOnlyCommands only read the ledger, every other command changes it and needs an owner or editor
+var cliReadOnlyCommands = map[string]bool{
+	"list": true, "query": true, "search": true, "range": true, "summary": true, "suggest-category": true,
+	"balance": true, "status": true, "contributions": true, "progress": true, "spend": true,
+	"test": true, "export": true, "backup": true, "stats": true, "connect": true,
+	"version": true, "help": true,
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "cashlens",
 	Short: "Personal finance management - See your money clearly",
@@ -23,7 +49,7 @@ var rootCmd = &cobra.Command{
 	
 Track your daily cash flow, manage categories, and gain insights into your spending habits.
 Use 'cashlens [command] --help' for more information about a command.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Initialize database connection pool
 		dbType := util.GetConfigByKey("db.type")
 		if dbType == "mongodb" {
@@ -31,6 +57,7 @@ Use 'cashlens [command] --help' for more information about a command.`,
 				util.Logger.Errorw("Failed to initialize MongoDB connection", "error", err)
 			}
 		}
+		return resolveCliOwner(cmd)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Cashlens - See your money clearly")
@@ -38,6 +65,59 @@ Use 'cashlens [command] --help' for more information about a command.`,
 	},
 }
 
+// resolveCliOwner looks up the user the command acts as and the ledger it works on, the user's personal
+// ledger unless --ledger picks a shared one they belong to. A blank user means the records no user owns.
+// The user commands manage the users themselves and skip it. Commands that change the ledger
+// need an owner or editor, like the API requests that do.
+func resolveCliOwner(cmd *cobra.Command) error {
+	username := util.GetConfigByKey("cli.user")
+	if cmd.Flags().Changed("user") {
+		username = cliUsername
+	}
+	ledgerPlainId := util.GetConfigByKey("cli.ledger")
+	if cmd.Flags().Changed("ledger") {
+		ledgerPlainId = cliLedgerId
+	}
+	if strings.HasPrefix(cmd.CommandPath(), user_cmd.UserCmd.CommandPath()) {
+		return nil
+	}
+	if username == "" {
+		if ledgerPlainId != "" {
+			return errors.New("a ledger can only be picked together with a user")
+		}
+		return nil
+	}
+
+	userEntity, err := user_service.QueryUserByUsername(username)
+	if err != nil {
+		return errors.New("user " + username + " does not exist")
+	}
+	role, err := ledger_service.RoleService(userEntity.Id.Hex(), ledgerPlainId)
+	if err != nil {
+		return err
+	}
+	if isCliLedgerChange(cmd) && !ledger_service.CanEdit(role) {
+		return errors.New("a " + strings.ToLower(role) + " cannot change the ledger")
+	}
+	if ledgerPlainId == "" {
+		ledgerPlainId = userEntity.Id.Hex()
+	}
+	util.SetConfigByKey("cli.user.id", userEntity.Id.Hex())
+	util.SetConfigByKey("cli.owner.id", ledgerPlainId)
+	return nil
+}
+
+// isCliLedgerChange tells whether the command changes the records of the ledger worked on.
+// The server checks the role of every request itself.
+func isCliLedgerChange(cmd *cobra.Command) bool {
+	for _, selfCheckedCmd := range []*cobra.Command{ledger_cmd.LedgerCmd, server_cmd.ServerCmd} {
+		if strings.HasPrefix(cmd.CommandPath(), selfCheckedCmd.CommandPath()) {
+			return false
+		}
+	}
+	return !cliReadOnlyCommands[cmd.Name()]
+}
+
 func Execute() {
 	// Setup graceful shutdown
 	setupGracefulShutdown()
@@ -55,8 +135,11 @@ func setupGracefulShutdown() {
 
 		// Close database connections
 		dbType := util.GetConfigByKey("db.type")
-		if dbType == "mongodb" {
+		switch dbType {
+		case "mongodb":
 			database.ShutdownMongoDbConnection()
+		case "sqlite":
+			database.ShutdownSqliteConnection()
 		}
 
 		util.Logger.Info("Cleanup complete, exiting")
@@ -65,9 +148,22 @@ func setupGracefulShutdown() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(
+		&cliUsername, "user", "", "username whose ledger to work on (default $CLI_USER)")
+	rootCmd.PersistentFlags().StringVar(
+		&cliLedgerId, "ledger", "", "id of a shared ledger of the user to work on instead (default $CLI_LEDGER)")
 	rootCmd.AddCommand(server_cmd.ServerCmd)
 	rootCmd.AddCommand(cash_flow_cmd.CashCmd)
 	rootCmd.AddCommand(category_cmd.CategoryCmd)
+	rootCmd.AddCommand(account_cmd.AccountCmd)
+	rootCmd.AddCommand(exchange_rate_cmd.RateCmd)
+	rootCmd.AddCommand(recurring_rule_cmd.RecurringCmd)
+	rootCmd.AddCommand(budget_cmd.BudgetCmd)
+	rootCmd.AddCommand(goal_cmd.GoalCmd)
+	rootCmd.AddCommand(payee_cmd.PayeeCmd)
+	rootCmd.AddCommand(category_rule_cmd.RulesCmd)
+	rootCmd.AddCommand(user_cmd.UserCmd)
+	rootCmd.AddCommand(ledger_cmd.LedgerCmd)
 	rootCmd.AddCommand(manage_cmd.ManageCmd)
 	rootCmd.AddCommand(db_cmd.DbCmd)
 }
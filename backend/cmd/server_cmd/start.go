@@ -1,22 +1,48 @@
 package server_cmd
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/macar-x/cashlens/controller"
+	"github.com/macar-x/cashlens/service/manage_service"
+	"github.com/macar-x/cashlens/service/recurring_rule_service"
 	"github.com/spf13/cobra"
 )
 
-var port int32
+var (
+	port              int32
+	demoMode          bool
+	recurringInterval time.Duration
+)
 
 var startCmd4ApiServer = &cobra.Command{
 	Use:   "start",
 	Short: "start the api server",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if demoMode {
+			if err := manage_service.StartDemoMode(); err != nil {
+				return err
+			}
+			fmt.Println("Demo mode: serving seeded in-memory data, changes are lost on exit")
+		}
+
+		if recurringInterval > 0 {
+			recurring_rule_service.StartScheduler(recurringInterval)
+			fmt.Printf("Recurring rules are booked every %s\n", recurringInterval)
+		}
+
 		controller.StartServer(port)
+		return nil
 	},
 }
 
 func init() {
 	startCmd4ApiServer.Flags().Int32VarP(
 		&port, "port", "p", 8080, "api server port, default 8080")
+	startCmd4ApiServer.Flags().BoolVar(
+		&demoMode, "demo", false, "serve seeded in-memory data instead of the configured database")
+	startCmd4ApiServer.Flags().DurationVar(
+		&recurringInterval, "recurring-interval", time.Hour, "how often due recurring rules are booked, 0 to disable")
 	ServerCmd.AddCommand(startCmd4ApiServer)
 }
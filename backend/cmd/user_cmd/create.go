@@ -0,0 +1,48 @@
+package user_cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/user_service"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create new user",
+	Long: `Create a user who can sign in to the REST API, for example
+  cashlens user create -u alice
+The password is read from the standard input unless --password is given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if password == "" {
+			fmt.Print("Password: ")
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				return err
+			}
+			password = strings.TrimRight(line, "\r\n")
+		}
+
+		userEntity, err := user_service.RegisterService(model.UserDTO{
+			Username: username,
+			Password: password,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println("user ", 0, ": ", userEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	addUsernameFlag(createCmd)
+	createCmd.Flags().StringVarP(
+		&password, "password", "p", "", "password, at least 8 characters (read from the standard input when empty)")
+
+	UserCmd.AddCommand(createCmd)
+}
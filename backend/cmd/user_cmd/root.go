@@ -0,0 +1,38 @@
+package user_cmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	plainId   string
+	username  string
+	password  string
+	tokenName string
+)
+
+var UserCmd = &cobra.Command{
+	Use:   "user",
+	Short: "manage API users and their personal tokens",
+	Long: `Manage the users who may sign in to the REST API, and their personal API tokens.
+Scripts send a token as "Authorization: Bearer <token>" instead of signing in with a password.
+
+Available sub-commands:
+  create       - Create new user
+  token-create - Create a personal API token, shown only once
+  token-list   - List a user's API tokens
+  token-revoke - Revoke an API token`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.New("must provide a valid sub command")
+	},
+}
+
+// addUsernameFlag registers the required flag naming the user a command acts for
+func addUsernameFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(
+		&username, "username", "u", "", "username (required)")
+	cmd.MarkFlagRequired("username")
+}
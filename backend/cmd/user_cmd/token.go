@@ -0,0 +1,87 @@
+package user_cmd
+
+import (
+	"fmt"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/user_service"
+	"github.com/spf13/cobra"
+)
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "token-create",
+	Short: "create a personal API token",
+	Long: `Create a long-lived personal API token for scripts, for example
+  cashlens user token-create -u alice -n "nightly import"
+The token is printed only once, keep it somewhere safe. It lasts until revoked.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userEntity, err := user_service.QueryUserByUsername(username)
+		if err != nil {
+			return err
+		}
+		apiTokenEntity, token, err := user_service.CreateApiTokenService(userEntity.Id.Hex(), model.ApiTokenDTO{Name: tokenName})
+		if err != nil {
+			return err
+		}
+		fmt.Println("api_token ", 0, ": ", apiTokenEntity.ToString())
+		fmt.Println("Token:", token)
+		return nil
+	},
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:   "token-list",
+	Short: "list a user's API tokens",
+	Long:  `List the personal API tokens of a user, oldest first. The tokens themselves are not kept.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userEntity, err := user_service.QueryUserByUsername(username)
+		if err != nil {
+			return err
+		}
+
+		apiTokenList := user_service.ListApiTokensService(userEntity.Id.Hex())
+		if len(apiTokenList) == 0 {
+			fmt.Println("No api tokens found")
+			return nil
+		}
+		for index, apiTokenEntity := range apiTokenList {
+			fmt.Println("api_token ", index, ": ", apiTokenEntity.ToString())
+		}
+		return nil
+	},
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:   "token-revoke",
+	Short: "revoke an API token",
+	Long:  `Revoke one of a user's personal API tokens by its ID, scripts using it are turned away from then on.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userEntity, err := user_service.QueryUserByUsername(username)
+		if err != nil {
+			return err
+		}
+		apiTokenEntity, err := user_service.RevokeApiTokenService(userEntity.Id.Hex(), plainId)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Revoked api_token:", apiTokenEntity.ToString())
+		return nil
+	},
+}
+
+func init() {
+	addUsernameFlag(tokenCreateCmd)
+	tokenCreateCmd.Flags().StringVarP(
+		&tokenName, "name", "n", "", "what the token is for (required)")
+	tokenCreateCmd.MarkFlagRequired("name")
+	UserCmd.AddCommand(tokenCreateCmd)
+
+	addUsernameFlag(tokenListCmd)
+	UserCmd.AddCommand(tokenListCmd)
+
+	addUsernameFlag(tokenRevokeCmd)
+	tokenRevokeCmd.Flags().StringVarP(
+		&plainId, "id", "i", "", "api token id (required)")
+	tokenRevokeCmd.MarkFlagRequired("id")
+	UserCmd.AddCommand(tokenRevokeCmd)
+}
@@ -0,0 +1,38 @@
+package account_controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/account_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// GetBalances returns the balance of every account
+func GetBalances(w http.ResponseWriter, r *http.Request) {
+	balanceList, err := account_service.GetBalancesService(middleware.CurrentLedger(r))
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, balanceList)
+}
+
+// GetBalanceById returns the balance of one account
+func GetBalanceById(w http.ResponseWriter, r *http.Request) {
+	plainId := mux.Vars(r)["id"]
+	if plainId == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
+		return
+	}
+
+	accountBalance, err := account_service.GetBalanceService(middleware.CurrentLedger(r), plainId, "")
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, accountBalance)
+}
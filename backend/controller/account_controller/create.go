@@ -0,0 +1,33 @@
+package account_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/account_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Create creates a new account
+func Create(w http.ResponseWriter, r *http.Request) {
+	var requestBody model.AccountDTO
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if requestBody.Name == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "account name is required"})
+		return
+	}
+
+	accountEntity, err := account_service.CreateService(middleware.CurrentLedger(r),
+		requestBody.Name, requestBody.Type, requestBody.Currency, requestBody.OpeningBalance, requestBody.Remark)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, accountEntity)
+}
@@ -0,0 +1,26 @@
+package account_controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/account_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// DeleteById deletes an account by ID
+func DeleteById(w http.ResponseWriter, r *http.Request) {
+	plainId := mux.Vars(r)["id"]
+	if plainId == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
+		return
+	}
+
+	if _, err := account_service.DeleteService(middleware.CurrentLedger(r), plainId); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]string{"message": "account deleted successfully"})
+}
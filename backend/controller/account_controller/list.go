@@ -0,0 +1,46 @@
+package account_controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/account_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// ListAll returns paginated list of all accounts
+func ListAll(w http.ResponseWriter, r *http.Request) {
+	// Parse query parameters for pagination
+	limitStr := r.URL.Query().Get("limit")
+	offsetStr := r.URL.Query().Get("offset")
+
+	limit := 50 // Default limit for accounts
+	offset := 0 // Default offset
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil {
+			offset = o
+		}
+	}
+
+	accounts, totalCount, err := account_service.ListAllService(middleware.CurrentLedger(r), limit, offset)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return with pagination metadata
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":        accounts,
+		"total_count": totalCount,
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
@@ -0,0 +1,44 @@
+package account_controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/account_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// QueryById queries an account by ID
+func QueryById(w http.ResponseWriter, r *http.Request) {
+	plainId := mux.Vars(r)["id"]
+	if plainId == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
+		return
+	}
+
+	accountEntity, err := account_service.QueryService(middleware.CurrentLedger(r), plainId, "")
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, accountEntity)
+}
+
+// QueryByName queries an account by name
+func QueryByName(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if name == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "name is required"})
+		return
+	}
+
+	accountEntity, err := account_service.QueryService(middleware.CurrentLedger(r), "", name)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, accountEntity)
+}
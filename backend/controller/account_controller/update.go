@@ -0,0 +1,59 @@
+package account_controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/account_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+)
+
+// UpdateById updates an account by ID
+func UpdateById(w http.ResponseWriter, r *http.Request) {
+	plainId := mux.Vars(r)["id"]
+	if plainId == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
+		return
+	}
+
+	// Parse JSON body for update fields
+	var requestBody map[string]interface{}
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	// Extract optional fields
+	accountName, _ := requestBody["name"].(string)
+	accountType, _ := requestBody["type"].(string)
+	currency, _ := requestBody["currency"].(string)
+	remark, _ := requestBody["remark"].(string)
+
+	// zero is a valid opening balance, so it is only updated when the key is present
+	var openingBalance *decimal.Decimal
+	if openingBalanceVal, ok := requestBody["opening_balance"]; ok {
+		var value decimal.Decimal
+		switch v := openingBalanceVal.(type) {
+		case float64:
+			value = decimal.NewFromFloat(v)
+		case string:
+			parsed, err := decimal.NewFromString(v)
+			if err != nil {
+				util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid opening_balance"})
+				return
+			}
+			value = parsed
+		}
+		openingBalance = &value
+	}
+
+	updatedEntity, err := account_service.UpdateService(middleware.CurrentLedger(r), plainId, accountName, accountType, currency, openingBalance, remark)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, updatedEntity)
+}
@@ -0,0 +1,26 @@
+package budget_controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/budget_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// DeleteById deletes a budget by ID, the cash_flows it tracked are kept
+func DeleteById(w http.ResponseWriter, r *http.Request) {
+	plainId := mux.Vars(r)["id"]
+	if plainId == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
+		return
+	}
+
+	if _, err := budget_service.DeleteService(middleware.CurrentLedger(r), plainId); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]string{"message": "budget deleted successfully"})
+}
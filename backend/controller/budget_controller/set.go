@@ -0,0 +1,32 @@
+package budget_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/budget_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Set creates the budget of a category and period, or replaces the existing one
+func Set(w http.ResponseWriter, r *http.Request) {
+	var requestBody model.BudgetDTO
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if requestBody.CategoryName == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "some required fields are empty"})
+		return
+	}
+
+	budgetEntity, err := budget_service.SetService(middleware.CurrentLedger(r), requestBody)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, budgetEntity)
+}
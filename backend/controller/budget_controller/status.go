@@ -0,0 +1,27 @@
+package budget_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/budget_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Status reports spent, remaining and percent used of every budget,
+// optional query parameters category and date narrow it to one category and pick the period
+func Status(w http.ResponseWriter, r *http.Request) {
+	categoryName := r.URL.Query().Get("category")
+	date := r.URL.Query().Get("date")
+
+	statusList, err := budget_service.StatusService(middleware.CurrentLedger(r), categoryName, date)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":  statusList,
+		"count": len(statusList),
+	})
+}
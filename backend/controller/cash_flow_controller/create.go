@@ -2,6 +2,7 @@ package cash_flow_controller
 
 import (
 	"errors"
+	"github.com/macar-x/cashlens/middleware"
 	"net/http"
 
 	"github.com/macar-x/cashlens/model"
@@ -16,7 +17,7 @@ func CreateOutcome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cashFlowEntity, err := cash_flow_service.SaveOutcome(requestBody.BelongsDate, requestBody.CategoryName, requestBody.Amount, requestBody.Description)
+	cashFlowEntity, err := cash_flow_service.SaveOutcome(middleware.CurrentLedger(r), middleware.CurrentUser(r).Id.Hex(), requestBody.BelongsDate, requestBody.CategoryName, requestBody.AccountName, requestBody.Currency, requestBody.Amount, requestBody.Description, requestBody.PayeeName, requestBody.Tags)
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusOK, map[string]string{"error": err.Error()})
 		return
@@ -31,7 +32,7 @@ func CreateIncome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cashFlowEntity, err := cash_flow_service.SaveIncome(requestBody.BelongsDate, requestBody.CategoryName, requestBody.Amount, requestBody.Description)
+	cashFlowEntity, err := cash_flow_service.SaveIncome(middleware.CurrentLedger(r), middleware.CurrentUser(r).Id.Hex(), requestBody.BelongsDate, requestBody.CategoryName, requestBody.AccountName, requestBody.Currency, requestBody.Amount, requestBody.Description, requestBody.PayeeName, requestBody.Tags)
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusOK, map[string]string{"error": err.Error()})
 		return
@@ -46,7 +47,7 @@ func validCashFlowDTO(r *http.Request) (model.CashFlowDTO, error) {
 		return model.CashFlowDTO{}, err
 	}
 
-	if !cash_flow_service.IsOutcomeRequiredFiledSatisfied(requestBody.CategoryName, requestBody.Amount) {
+	if !cash_flow_service.IsOutcomeRequiredFiledSatisfied(requestBody.Amount) {
 		return model.CashFlowDTO{}, errors.New("some required fields are empty")
 	}
 	return requestBody, nil
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
 	"github.com/macar-x/cashlens/service/cash_flow_service"
 	"github.com/macar-x/cashlens/util"
 )
@@ -14,7 +15,7 @@ func DeleteById(w http.ResponseWriter, r *http.Request) {
 	if plainId == "" {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "id is empty"})
 	}
-	cashFlowEntity, err := cash_flow_service.DeleteById(plainId)
+	cashFlowEntity, err := cash_flow_service.DeleteById(middleware.CurrentLedger(r), plainId)
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusOK, map[string]string{"error": err.Error()})
 		return
@@ -28,7 +29,7 @@ func DeleteByDate(w http.ResponseWriter, r *http.Request) {
 	if date == "" {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "date is empty"})
 	}
-	cashFlowEntityList, err := cash_flow_service.DeleteByDate(date)
+	cashFlowEntityList, err := cash_flow_service.DeleteByDate(middleware.CurrentLedger(r), date)
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusOK, map[string]string{"error": err.Error()})
 		return
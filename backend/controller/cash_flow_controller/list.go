@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/macar-x/cashlens/middleware"
 	"github.com/macar-x/cashlens/service/cash_flow_service"
 	"github.com/macar-x/cashlens/util"
 )
@@ -13,7 +14,11 @@ func ListAll(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters for pagination
 	limitStr := r.URL.Query().Get("limit")
 	offsetStr := r.URL.Query().Get("offset")
-	cashType := r.URL.Query().Get("type") // Optional: INCOME or OUTCOME
+	cashType := r.URL.Query().Get("type") // Optional: INCOME, OUTCOME or TRANSFER
+	tag := r.URL.Query().Get("tag")       // Optional: only cash flows carrying this tag
+	sortBy := r.URL.Query().Get("sort")   // Optional: date (default) or amount
+	order := r.URL.Query().Get("order")   // Optional: asc or desc (default)
+	cursor := r.URL.Query().Get("cursor") // Optional: next_cursor or prev_cursor of an earlier page
 
 	limit := 20 // Default limit
 	offset := 0 // Default offset
@@ -31,17 +36,12 @@ func ListAll(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call service to get paginated results
-	cashFlows, totalCount, err := cash_flow_service.QueryAll(cashType, limit, offset)
+	page, err := cash_flow_service.QueryAll(middleware.CurrentLedger(r), cashType, tag, sortBy, order, cursor, limit, offset)
 	if err != nil {
-		util.ComposeJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
 
-	// Return with pagination metadata
-	util.ComposeJSONResponse(w, http.StatusOK, map[string]interface{}{
-		"data":        cashFlows,
-		"total_count": totalCount,
-		"limit":       limit,
-		"offset":      offset,
-	})
+	// Return with pagination metadata and cursors
+	util.ComposeJSONResponse(w, http.StatusOK, page)
 }
@@ -2,16 +2,26 @@ package cash_flow_controller
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/macar-x/cashlens/middleware"
 	"github.com/macar-x/cashlens/service/cash_flow_service"
 	"github.com/macar-x/cashlens/util"
 )
 
-// QueryByDateRange queries cash flows between two dates
+// QueryByDateRange queries cash flows between two dates oldest first, the whole range unless a limit is given
 func QueryByDateRange(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	fromDate := r.URL.Query().Get("from")
 	toDate := r.URL.Query().Get("to")
+	cursor := r.URL.Query().Get("cursor") // Optional: next_cursor or prev_cursor of an earlier page
+
+	limit := 0 // Default: no limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = l
+		}
+	}
 
 	if fromDate == "" || toDate == "" {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "from and to dates are required"})
@@ -19,11 +29,11 @@ func QueryByDateRange(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call service to get records in range
-	cashFlowEntities, err := cash_flow_service.QueryByRange(fromDate, toDate)
+	page, err := cash_flow_service.QueryPageByDateRange(middleware.CurrentLedger(r), fromDate, toDate, cursor, limit)
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
 
-	util.ComposeJSONResponse(w, http.StatusOK, cashFlowEntities)
+	util.ComposeJSONResponse(w, http.StatusOK, page)
 }
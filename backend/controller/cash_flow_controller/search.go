@@ -0,0 +1,70 @@
+package cash_flow_controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+)
+
+// Search returns one page of the cash flows matching the query parameters text, from, to, min_amount,
+// max_amount, type, category, tag (repeated or comma separated), account, sort, order, limit, offset and cursor
+func Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	searchDTO := model.CashFlowSearchDTO{
+		Text:         query.Get("text"),
+		FromDate:     query.Get("from"),
+		ToDate:       query.Get("to"),
+		FlowType:     query.Get("type"),
+		CategoryName: query.Get("category"),
+		AccountName:  query.Get("account"),
+		SortBy:       query.Get("sort"),
+		Order:        query.Get("order"),
+		Cursor:       query.Get("cursor"),
+		Limit:        20, // Default limit
+	}
+	for _, tags := range query["tag"] {
+		searchDTO.Tags = append(searchDTO.Tags, strings.Split(tags, ",")...)
+	}
+
+	for _, amountParam := range []struct {
+		name   string
+		target *decimal.Decimal
+	}{
+		{"min_amount", &searchDTO.MinAmount},
+		{"max_amount", &searchDTO.MaxAmount},
+	} {
+		if amountStr := query.Get(amountParam.name); amountStr != "" {
+			parsedAmount, err := decimal.NewFromString(amountStr)
+			if err != nil {
+				util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid " + amountParam.name})
+				return
+			}
+			*amountParam.target = parsedAmount
+		}
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			searchDTO.Limit = l
+		}
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil {
+			searchDTO.Offset = o
+		}
+	}
+
+	page, err := cash_flow_service.SearchService(middleware.CurrentLedger(r), searchDTO)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, page)
+}
@@ -0,0 +1,37 @@
+package cash_flow_controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// SplitById shares a cash flow among the categories of its lines, an empty list of lines undoes the split
+func SplitById(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	plainId := vars["id"]
+	if plainId == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
+		return
+	}
+
+	var requestBody struct {
+		Lines []model.CashFlowSplitDTO `json:"lines"`
+	}
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	updatedEntity, err := cash_flow_service.SplitById(middleware.CurrentLedger(r), middleware.CurrentUser(r).Id.Hex(), plainId, requestBody.Lines)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, updatedEntity)
+}
@@ -0,0 +1,47 @@
+package cash_flow_controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+)
+
+// SuggestCategory ranks the categories learned from past cash flows for the query parameters
+// description, optional amount, type (INCOME or OUTCOME) and limit
+func SuggestCategory(w http.ResponseWriter, r *http.Request) {
+	description := r.URL.Query().Get("description")
+	amountStr := r.URL.Query().Get("amount")
+	limitStr := r.URL.Query().Get("limit")
+
+	amount := decimal.Zero
+	if amountStr != "" {
+		parsedAmount, err := decimal.NewFromString(amountStr)
+		if err != nil {
+			util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid amount"})
+			return
+		}
+		amount = parsedAmount
+	}
+
+	limit := 0 // the service default
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = l
+		}
+	}
+
+	suggestionList, err := cash_flow_service.SuggestCategoryService(middleware.CurrentLedger(r), r.URL.Query().Get("type"), description, amount, limit)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":  suggestionList,
+		"count": len(suggestionList),
+	})
+}
@@ -4,11 +4,12 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
 	"github.com/macar-x/cashlens/service/cash_flow_service"
 	"github.com/macar-x/cashlens/util"
 )
 
-// GetDailySummary returns summary for a specific day
+// GetDailySummary returns summary for a specific day, ?currency= reports it in that currency
 func GetDailySummary(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	date := vars["date"]
@@ -18,7 +19,7 @@ func GetDailySummary(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	summary, err := cash_flow_service.GetSummary(date, date, "daily")
+	summary, err := cash_flow_service.GetSummary(middleware.CurrentLedger(r), "daily", date, r.URL.Query().Get("currency"))
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
@@ -37,7 +38,7 @@ func GetMonthlySummary(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	summary, err := cash_flow_service.GetSummaryByMonth(month)
+	summary, err := cash_flow_service.GetSummaryByMonth(middleware.CurrentLedger(r), month, r.URL.Query().Get("currency"))
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
@@ -56,7 +57,7 @@ func GetYearlySummary(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	summary, err := cash_flow_service.GetSummaryByYear(year)
+	summary, err := cash_flow_service.GetSummaryByYear(middleware.CurrentLedger(r), year, r.URL.Query().Get("currency"))
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
@@ -0,0 +1,32 @@
+package cash_flow_controller
+
+import (
+	"errors"
+	"github.com/macar-x/cashlens/middleware"
+	"net/http"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+func CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	var requestBody model.TransferDTO
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusOK, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if !cash_flow_service.IsTransferRequiredFiledSatisfied(requestBody.FromAccountName, requestBody.ToAccountName, requestBody.Amount) {
+		util.ComposeJSONResponse(w, http.StatusOK, map[string]string{"error": errors.New("some required fields are empty").Error()})
+		return
+	}
+
+	cashFlowEntityList, err := cash_flow_service.SaveTransfer(middleware.CurrentLedger(r), middleware.CurrentUser(r).Id.Hex(), requestBody.BelongsDate, requestBody.FromAccountName,
+		requestBody.ToAccountName, requestBody.Amount, requestBody.Description)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusOK, map[string]string{"error": err.Error()})
+		return
+	}
+	util.ComposeJSONResponse(w, http.StatusOK, cashFlowEntityList)
+}
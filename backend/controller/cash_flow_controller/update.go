@@ -2,11 +2,12 @@ package cash_flow_controller
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
 	"github.com/macar-x/cashlens/service/cash_flow_service"
 	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
 )
 
 // UpdateById updates a cash flow record by ID
@@ -28,20 +29,42 @@ func UpdateById(w http.ResponseWriter, r *http.Request) {
 	// Extract optional fields
 	belongsDate, _ := requestBody["belongs_date"].(string)
 	categoryName, _ := requestBody["category_name"].(string)
+	accountName, _ := requestBody["account_name"].(string)
+	currency, _ := requestBody["currency"].(string)
 	description, _ := requestBody["description"].(string)
+	payeeName, _ := requestBody["payee_name"].(string)
 
-	var amount float64
+	// Tags are only replaced when the field is sent, an empty list clears them
+	var tags []string
+	if tagsVal, ok := requestBody["tags"]; ok && tagsVal != nil {
+		tagValues, isList := tagsVal.([]interface{})
+		if !isList {
+			util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "tags must be a list of strings"})
+			return
+		}
+		tags = make([]string, 0, len(tagValues))
+		for _, tagValue := range tagValues {
+			tag, isString := tagValue.(string)
+			if !isString {
+				util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "tags must be a list of strings"})
+				return
+			}
+			tags = append(tags, tag)
+		}
+	}
+
+	var amount decimal.Decimal
 	if amountVal, ok := requestBody["amount"]; ok {
 		switch v := amountVal.(type) {
 		case float64:
-			amount = v
+			amount = decimal.NewFromFloat(v)
 		case string:
-			amount, _ = strconv.ParseFloat(v, 64)
+			amount, _ = decimal.NewFromString(v)
 		}
 	}
 
 	// Call service to update
-	updatedEntity, err := cash_flow_service.UpdateById(plainId, belongsDate, categoryName, amount, description)
+	updatedEntity, err := cash_flow_service.UpdateById(middleware.CurrentLedger(r), middleware.CurrentUser(r).Id.Hex(), plainId, belongsDate, categoryName, accountName, currency, amount, description, payeeName, tags)
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
@@ -3,6 +3,7 @@ package category_controller
 import (
 	"net/http"
 
+	"github.com/macar-x/cashlens/middleware"
 	"github.com/macar-x/cashlens/model"
 	"github.com/macar-x/cashlens/service/category_service"
 	"github.com/macar-x/cashlens/util"
@@ -21,7 +22,7 @@ func Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plainId, err := category_service.CreateService(requestBody.ParentName, requestBody.Name)
+	plainId, err := category_service.CreateService(middleware.CurrentLedger(r), requestBody.ParentName, requestBody.Name)
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
 	"github.com/macar-x/cashlens/service/category_service"
 	"github.com/macar-x/cashlens/util"
 )
@@ -18,7 +19,7 @@ func DeleteById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := category_service.DeleteService(plainId)
+	err := category_service.DeleteService(middleware.CurrentLedger(r), plainId, "")
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
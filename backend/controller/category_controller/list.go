@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/macar-x/cashlens/middleware"
 	"github.com/macar-x/cashlens/service/category_service"
 	"github.com/macar-x/cashlens/util"
 )
@@ -30,7 +31,7 @@ func ListAll(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call service to get paginated results
-	categories, totalCount, err := category_service.ListAllService(limit, offset)
+	categories, totalCount, err := category_service.ListAllService(middleware.CurrentLedger(r), limit, offset)
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
 	"github.com/macar-x/cashlens/service/category_service"
 	"github.com/macar-x/cashlens/util"
 )
@@ -18,7 +19,7 @@ func QueryById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	categoryEntities, err := category_service.QueryService(plainId, "", "")
+	categoryEntities, err := category_service.QueryService(middleware.CurrentLedger(r), plainId, "", "")
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
@@ -42,7 +43,7 @@ func QueryByName(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	categoryEntities, err := category_service.QueryService("", name, "")
+	categoryEntities, err := category_service.QueryService(middleware.CurrentLedger(r), "", "", name)
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
@@ -61,7 +62,7 @@ func QueryChildren(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	categoryEntities, err := category_service.QueryService("", "", parentId)
+	categoryEntities, err := category_service.QueryService(middleware.CurrentLedger(r), "", parentId, "")
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
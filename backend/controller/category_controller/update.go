@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
 	"github.com/macar-x/cashlens/service/category_service"
 	"github.com/macar-x/cashlens/util"
 )
@@ -30,7 +31,7 @@ func UpdateById(w http.ResponseWriter, r *http.Request) {
 	categoryName, _ := requestBody["name"].(string)
 
 	// Call service to update
-	err := category_service.UpdateService(plainId, parentPlainId, categoryName)
+	err := category_service.UpdateService(middleware.CurrentLedger(r), plainId, parentPlainId, categoryName)
 	if err != nil {
 		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
@@ -0,0 +1,32 @@
+package category_rule_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/category_rule_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Create creates a new categorization rule with its conditions
+func Create(w http.ResponseWriter, r *http.Request) {
+	var requestBody model.CategoryRuleDTO
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if requestBody.Name == "" || requestBody.CategoryName == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "some required fields are empty"})
+		return
+	}
+
+	ruleEntity, err := category_rule_service.CreateService(middleware.CurrentLedger(r), requestBody)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, ruleEntity)
+}
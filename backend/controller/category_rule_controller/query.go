@@ -0,0 +1,44 @@
+package category_rule_controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/category_rule_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// QueryById queries a categorization rule by ID
+func QueryById(w http.ResponseWriter, r *http.Request) {
+	plainId := mux.Vars(r)["id"]
+	if plainId == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
+		return
+	}
+
+	ruleEntity, err := category_rule_service.QueryService(middleware.CurrentLedger(r), plainId, "")
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, ruleEntity)
+}
+
+// QueryByName queries a categorization rule by name
+func QueryByName(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if name == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "name is required"})
+		return
+	}
+
+	ruleEntity, err := category_rule_service.QueryService(middleware.CurrentLedger(r), "", name)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, ruleEntity)
+}
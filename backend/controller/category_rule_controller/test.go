@@ -0,0 +1,36 @@
+package category_rule_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/category_rule_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+)
+
+// Test reports which rule a cash_flow would get, from the query parameters description,
+// amount, type, payee and account; nothing is saved
+func Test(w http.ResponseWriter, r *http.Request) {
+	description := r.URL.Query().Get("description")
+	amountStr := r.URL.Query().Get("amount")
+
+	amount := decimal.Zero
+	if amountStr != "" {
+		parsedAmount, err := decimal.NewFromString(amountStr)
+		if err != nil {
+			util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid amount"})
+			return
+		}
+		amount = parsedAmount
+	}
+
+	ruleMatch, err := category_rule_service.MatchService(middleware.CurrentLedger(r), r.URL.Query().Get("type"), description, amount,
+		r.URL.Query().Get("payee"), r.URL.Query().Get("account"))
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, ruleMatch)
+}
@@ -0,0 +1,28 @@
+package exchange_rate_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Create creates or replaces the rate of a currency pair on a date
+func Create(w http.ResponseWriter, r *http.Request) {
+	var requestBody model.ExchangeRateDTO
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	exchangeRateEntity, err := exchange_rate_service.CreateService(middleware.CurrentLedger(r),
+		requestBody.FromCurrency, requestBody.ToCurrency, requestBody.Rate, requestBody.EffectiveDate)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, exchangeRateEntity)
+}
@@ -0,0 +1,20 @@
+package exchange_rate_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Import reads exchange rates from a CSV request body
+func Import(w http.ResponseWriter, r *http.Request) {
+	importResult, err := exchange_rate_service.ImportService(middleware.CurrentLedger(r), r.Body)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, importResult)
+}
@@ -0,0 +1,61 @@
+package goal_controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/goal_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// ListAll reports the progress of every goal as of today
+func ListAll(w http.ResponseWriter, r *http.Request) {
+	progressList, err := goal_service.ListProgressService(middleware.CurrentLedger(r))
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":  progressList,
+		"count": len(progressList),
+	})
+}
+
+// ProgressById reports the progress of one goal: saved, required monthly contribution and whether it is on track
+func ProgressById(w http.ResponseWriter, r *http.Request) {
+	plainId := mux.Vars(r)["id"]
+	if plainId == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
+		return
+	}
+
+	progress, err := goal_service.ProgressService(middleware.CurrentLedger(r), plainId, "")
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, progress)
+}
+
+// ContributionsById lists the cash_flows counting toward one goal
+func ContributionsById(w http.ResponseWriter, r *http.Request) {
+	plainId := mux.Vars(r)["id"]
+	if plainId == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
+		return
+	}
+
+	cashFlowList, err := goal_service.ContributionsService(middleware.CurrentLedger(r), plainId, "")
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":  cashFlowList,
+		"count": len(cashFlowList),
+	})
+}
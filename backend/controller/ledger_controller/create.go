@@ -0,0 +1,40 @@
+package ledger_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/errors"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/ledger_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Create makes a shared ledger owned by the signed-in user
+func Create(w http.ResponseWriter, r *http.Request) {
+	var requestBody model.LedgerDTO
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	ledgerView, err := ledger_service.CreateService(middleware.CurrentUser(r).Id.Hex(), requestBody)
+	if err != nil {
+		composeErrorResponse(w, err)
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, ledgerView)
+}
+
+// composeErrorResponse answers with 403 when the role falls short, 404 for a ledger or member the user cannot see
+// and 400 for everything else
+func composeErrorResponse(w http.ResponseWriter, err error) {
+	statusCode := http.StatusBadRequest
+	if errors.IsForbidden(err) {
+		statusCode = http.StatusForbidden
+	} else if errors.IsNotFound(err) {
+		statusCode = http.StatusNotFound
+	}
+	util.ComposeJSONResponse(w, statusCode, map[string]string{"error": err.Error()})
+}
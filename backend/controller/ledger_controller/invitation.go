@@ -0,0 +1,45 @@
+package ledger_controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/ledger_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Invite makes an invitation to a ledger, the token is only shown in this response
+func Invite(w http.ResponseWriter, r *http.Request) {
+	var requestBody model.LedgerRoleDTO
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	invitationView, err := ledger_service.InviteService(middleware.CurrentUser(r).Id.Hex(), mux.Vars(r)["id"], requestBody)
+	if err != nil {
+		composeErrorResponse(w, err)
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, invitationView)
+}
+
+// AcceptInvitation joins the signed-in user to the ledger of an invitation token
+func AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var requestBody model.LedgerInvitationAcceptDTO
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	ledgerView, err := ledger_service.AcceptInvitationService(middleware.CurrentUser(r).Id.Hex(), requestBody)
+	if err != nil {
+		composeErrorResponse(w, err)
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, ledgerView)
+}
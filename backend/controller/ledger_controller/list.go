@@ -0,0 +1,18 @@
+package ledger_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/ledger_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// ListAll lists the ledgers of the signed-in user, their personal ledger first
+func ListAll(w http.ResponseWriter, r *http.Request) {
+	ledgerList := ledger_service.ListService(middleware.CurrentUser(r).Id.Hex())
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":        ledgerList,
+		"total_count": len(ledgerList),
+	})
+}
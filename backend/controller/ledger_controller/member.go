@@ -0,0 +1,54 @@
+package ledger_controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/ledger_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// ListMembers lists the members of a ledger and their roles
+func ListMembers(w http.ResponseWriter, r *http.Request) {
+	memberList, err := ledger_service.ListMembersService(middleware.CurrentUser(r).Id.Hex(), mux.Vars(r)["id"])
+	if err != nil {
+		composeErrorResponse(w, err)
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":        memberList,
+		"total_count": len(memberList),
+	})
+}
+
+// UpdateMember changes the role of a ledger member
+func UpdateMember(w http.ResponseWriter, r *http.Request) {
+	var requestBody model.LedgerRoleDTO
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	vars := mux.Vars(r)
+	memberView, err := ledger_service.UpdateMemberService(middleware.CurrentUser(r).Id.Hex(), vars["id"], vars["user_id"], requestBody)
+	if err != nil {
+		composeErrorResponse(w, err)
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, memberView)
+}
+
+// RemoveMember takes a member out of a ledger, members may also remove themselves to leave it
+func RemoveMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := ledger_service.RemoveMemberService(middleware.CurrentUser(r).Id.Hex(), vars["id"], vars["user_id"]); err != nil {
+		composeErrorResponse(w, err)
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]string{"message": "member removed successfully"})
+}
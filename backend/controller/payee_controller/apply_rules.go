@@ -0,0 +1,28 @@
+package payee_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/payee_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// ApplyRules runs the payee rules over the existing cash_flows,
+// the optional query parameter overwrite=true also replaces payees already set
+func ApplyRules(w http.ResponseWriter, r *http.Request) {
+	isOverwrite := r.URL.Query().Get("overwrite") == "true"
+
+	updatedCount, err := payee_service.ApplyRulesService(middleware.CurrentLedger(r), isOverwrite)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]interface{}{
+			"error":         err.Error(),
+			"updated_count": updatedCount,
+		})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"updated_count": updatedCount,
+	})
+}
@@ -0,0 +1,28 @@
+package payee_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/payee_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Spend reports the outcomes between the query parameters from and to per payee,
+// converted into the optional currency (the default currency when blank)
+func Spend(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	currency := r.URL.Query().Get("currency")
+
+	spendList, err := payee_service.SpendByPayeeService(middleware.CurrentLedger(r), from, to, currency)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":  spendList,
+		"count": len(spendList),
+	})
+}
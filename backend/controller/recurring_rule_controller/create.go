@@ -0,0 +1,32 @@
+package recurring_rule_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/recurring_rule_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Create creates a new recurring rule
+func Create(w http.ResponseWriter, r *http.Request) {
+	var requestBody model.RecurringRuleDTO
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if requestBody.Name == "" || requestBody.CategoryName == "" || requestBody.Frequency == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "some required fields are empty"})
+		return
+	}
+
+	ruleEntity, err := recurring_rule_service.CreateService(middleware.CurrentLedger(r), requestBody)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, ruleEntity)
+}
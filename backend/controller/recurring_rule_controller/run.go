@@ -0,0 +1,35 @@
+package recurring_rule_controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/ledger_service"
+	"github.com/macar-x/cashlens/service/recurring_rule_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Run books the occurrences of the current ledger's rules due on or before the optional until date
+// (today when blank). Only owners and editors of the ledger may run them.
+func Run(w http.ResponseWriter, r *http.Request) {
+	role := middleware.CurrentLedgerRole(r)
+	if !ledger_service.CanEdit(role) {
+		util.ComposeJSONResponse(w, http.StatusForbidden, map[string]string{"error": "a " + strings.ToLower(role) + " cannot change the ledger"})
+		return
+	}
+
+	bookedList, err := recurring_rule_service.RunService(middleware.CurrentLedger(r), r.URL.Query().Get("until"))
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]interface{}{
+			"error":  err.Error(),
+			"booked": bookedList,
+		})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"booked":       bookedList,
+		"booked_count": len(bookedList),
+	})
+}
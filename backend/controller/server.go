@@ -6,25 +6,49 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/controller/account_controller"
+	"github.com/macar-x/cashlens/controller/budget_controller"
 	"github.com/macar-x/cashlens/controller/cash_flow_controller"
 	"github.com/macar-x/cashlens/controller/category_controller"
+	"github.com/macar-x/cashlens/controller/category_rule_controller"
+	"github.com/macar-x/cashlens/controller/exchange_rate_controller"
+	"github.com/macar-x/cashlens/controller/goal_controller"
+	"github.com/macar-x/cashlens/controller/ledger_controller"
+	"github.com/macar-x/cashlens/controller/payee_controller"
+	"github.com/macar-x/cashlens/controller/recurring_rule_controller"
+	"github.com/macar-x/cashlens/controller/stats_controller"
+	"github.com/macar-x/cashlens/controller/user_controller"
 	"github.com/macar-x/cashlens/middleware"
 )
 
 func StartServer(port int32) {
+	addr := fmt.Sprintf(":%d", port)
+	fmt.Printf("API server is running on http://localhost%s\n", addr)
+	http.ListenAndServe(addr, NewHandler())
+}
+
+// NewHandler returns the API routes wrapped in middleware
+func NewHandler() http.Handler {
 	r := mux.NewRouter()
 
 	// Register routes
 	registerHealthRoutes(r)
+	registerAuthRoute(r)
+	registerLedgerRoute(r)
 	registerCashRoute(r)
 	registerCategoryRoute(r)
+	registerAccountRoute(r)
+	registerExchangeRateRoute(r)
+	registerRecurringRuleRoute(r)
+	registerBudgetRoute(r)
+	registerGoalRoute(r)
+	registerPayeeRoute(r)
+	registerCategoryRuleRoute(r)
+	registerStatsRoute(r)
 
-	// Apply middleware
-	handler := middleware.Logging(middleware.CORS(r))
-
-	addr := fmt.Sprintf(":%d", port)
-	fmt.Printf("API server is running on http://localhost%s\n", addr)
-	http.ListenAndServe(addr, handler)
+	// Apply middleware, every route but health, version and sign-in needs a token,
+	// and the user's role in the ledger of the request decides whether it may write
+	return middleware.Logging(middleware.CORS(middleware.Auth(middleware.Ledger(r))))
 }
 
 func registerHealthRoutes(r *mux.Router) {
@@ -32,16 +56,48 @@ func registerHealthRoutes(r *mux.Router) {
 	r.HandleFunc("/api/version", versionInfo).Methods("GET")
 }
 
+func registerAuthRoute(r *mux.Router) {
+	// Sign-in
+	r.HandleFunc("/api/auth/register", user_controller.Register).Methods("POST")
+	r.HandleFunc("/api/auth/login", user_controller.Login).Methods("POST")
+	r.HandleFunc("/api/auth/me", user_controller.Me).Methods("GET")
+
+	// Personal API tokens
+	r.HandleFunc("/api/auth/tokens", user_controller.CreateApiToken).Methods("POST")
+	r.HandleFunc("/api/auth/tokens", user_controller.ListApiTokens).Methods("GET")
+	r.HandleFunc("/api/auth/tokens/{id}", user_controller.RevokeApiTokenById).Methods("DELETE")
+}
+
+func registerLedgerRoute(r *mux.Router) {
+	// Create
+	r.HandleFunc("/api/ledgers", ledger_controller.Create).Methods("POST")
+
+	// Read
+	r.HandleFunc("/api/ledgers", ledger_controller.ListAll).Methods("GET")
+	r.HandleFunc("/api/ledgers/{id}/members", ledger_controller.ListMembers).Methods("GET")
+
+	// Invitations
+	r.HandleFunc("/api/ledgers/invitations/accept", ledger_controller.AcceptInvitation).Methods("POST")
+	r.HandleFunc("/api/ledgers/{id}/invitations", ledger_controller.Invite).Methods("POST")
+
+	// Members
+	r.HandleFunc("/api/ledgers/{id}/members/{user_id}", ledger_controller.UpdateMember).Methods("PUT")
+	r.HandleFunc("/api/ledgers/{id}/members/{user_id}", ledger_controller.RemoveMember).Methods("DELETE")
+}
+
 func registerCashRoute(r *mux.Router) {
 	// Create
 	r.HandleFunc("/api/cash/outcome", cash_flow_controller.CreateOutcome).Methods("POST")
 	r.HandleFunc("/api/cash/income", cash_flow_controller.CreateIncome).Methods("POST")
+	r.HandleFunc("/api/cash/transfer", cash_flow_controller.CreateTransfer).Methods("POST")
 
 	// Read
 	r.HandleFunc("/api/cash/list", cash_flow_controller.ListAll).Methods("GET")
+	r.HandleFunc("/api/cash/suggest-category", cash_flow_controller.SuggestCategory).Methods("GET")
+	r.HandleFunc("/api/cash/search", cash_flow_controller.Search).Methods("GET")
+	r.HandleFunc("/api/cash/range", cash_flow_controller.QueryByDateRange).Methods("GET")
 	r.HandleFunc("/api/cash/{id}", cash_flow_controller.QueryById).Methods("GET")
 	r.HandleFunc("/api/cash/date/{date}", cash_flow_controller.QueryByDate).Methods("GET")
-	r.HandleFunc("/api/cash/range", cash_flow_controller.QueryByDateRange).Methods("GET")
 
 	// Summary endpoints
 	r.HandleFunc("/api/cash/summary/daily/{date}", cash_flow_controller.GetDailySummary).Methods("GET")
@@ -50,6 +106,7 @@ func registerCashRoute(r *mux.Router) {
 
 	// Update
 	r.HandleFunc("/api/cash/{id}", cash_flow_controller.UpdateById).Methods("PUT")
+	r.HandleFunc("/api/cash/{id}/split", cash_flow_controller.SplitById).Methods("PUT")
 
 	// Delete
 	r.HandleFunc("/api/cash/{id}", cash_flow_controller.DeleteById).Methods("DELETE")
@@ -73,6 +130,119 @@ func registerCategoryRoute(r *mux.Router) {
 	r.HandleFunc("/api/category/{id}", category_controller.DeleteById).Methods("DELETE")
 }
 
+func registerAccountRoute(r *mux.Router) {
+	// Create
+	r.HandleFunc("/api/account", account_controller.Create).Methods("POST")
+
+	// Read
+	r.HandleFunc("/api/account/list", account_controller.ListAll).Methods("GET")
+	r.HandleFunc("/api/account/balance", account_controller.GetBalances).Methods("GET")
+	r.HandleFunc("/api/account/{id}", account_controller.QueryById).Methods("GET")
+	r.HandleFunc("/api/account/{id}/balance", account_controller.GetBalanceById).Methods("GET")
+	r.HandleFunc("/api/account/name/{name}", account_controller.QueryByName).Methods("GET")
+
+	// Update
+	r.HandleFunc("/api/account/{id}", account_controller.UpdateById).Methods("PUT")
+
+	// Delete
+	r.HandleFunc("/api/account/{id}", account_controller.DeleteById).Methods("DELETE")
+}
+
+func registerExchangeRateRoute(r *mux.Router) {
+	// Create
+	r.HandleFunc("/api/rate", exchange_rate_controller.Create).Methods("POST")
+	r.HandleFunc("/api/rate/import", exchange_rate_controller.Import).Methods("POST")
+
+	// Read
+	r.HandleFunc("/api/rate/list", exchange_rate_controller.ListAll).Methods("GET")
+
+	// Delete
+	r.HandleFunc("/api/rate/{id}", exchange_rate_controller.DeleteById).Methods("DELETE")
+}
+
+func registerRecurringRuleRoute(r *mux.Router) {
+	// Create
+	r.HandleFunc("/api/recurring", recurring_rule_controller.Create).Methods("POST")
+	r.HandleFunc("/api/recurring/run", recurring_rule_controller.Run).Methods("POST")
+
+	// Read
+	r.HandleFunc("/api/recurring/list", recurring_rule_controller.ListAll).Methods("GET")
+	r.HandleFunc("/api/recurring/{id}", recurring_rule_controller.QueryById).Methods("GET")
+	r.HandleFunc("/api/recurring/name/{name}", recurring_rule_controller.QueryByName).Methods("GET")
+
+	// Update
+	r.HandleFunc("/api/recurring/{id}", recurring_rule_controller.UpdateById).Methods("PUT")
+
+	// Delete
+	r.HandleFunc("/api/recurring/{id}", recurring_rule_controller.DeleteById).Methods("DELETE")
+}
+
+func registerBudgetRoute(r *mux.Router) {
+	// Create or replace
+	r.HandleFunc("/api/budget", budget_controller.Set).Methods("POST")
+
+	// Read
+	r.HandleFunc("/api/budget/status", budget_controller.Status).Methods("GET")
+
+	// Delete
+	r.HandleFunc("/api/budget/{id}", budget_controller.DeleteById).Methods("DELETE")
+}
+
+func registerGoalRoute(r *mux.Router) {
+	// Create
+	r.HandleFunc("/api/goals", goal_controller.Create).Methods("POST")
+
+	// Read
+	r.HandleFunc("/api/goals", goal_controller.ListAll).Methods("GET")
+	r.HandleFunc("/api/goals/{id}", goal_controller.ProgressById).Methods("GET")
+	r.HandleFunc("/api/goals/{id}/contributions", goal_controller.ContributionsById).Methods("GET")
+
+	// Update
+	r.HandleFunc("/api/goals/{id}", goal_controller.UpdateById).Methods("PUT")
+
+	// Delete
+	r.HandleFunc("/api/goals/{id}", goal_controller.DeleteById).Methods("DELETE")
+}
+
+func registerPayeeRoute(r *mux.Router) {
+	// Create
+	r.HandleFunc("/api/payee", payee_controller.Create).Methods("POST")
+	r.HandleFunc("/api/payee/apply-rules", payee_controller.ApplyRules).Methods("POST")
+
+	// Read
+	r.HandleFunc("/api/payee/list", payee_controller.ListAll).Methods("GET")
+	r.HandleFunc("/api/payee/spend", payee_controller.Spend).Methods("GET")
+	r.HandleFunc("/api/payee/{id}", payee_controller.QueryById).Methods("GET")
+	r.HandleFunc("/api/payee/name/{name}", payee_controller.QueryByName).Methods("GET")
+
+	// Update
+	r.HandleFunc("/api/payee/{id}", payee_controller.UpdateById).Methods("PUT")
+
+	// Delete
+	r.HandleFunc("/api/payee/{id}", payee_controller.DeleteById).Methods("DELETE")
+}
+
+func registerCategoryRuleRoute(r *mux.Router) {
+	// Create
+	r.HandleFunc("/api/rules", category_rule_controller.Create).Methods("POST")
+
+	// Read
+	r.HandleFunc("/api/rules", category_rule_controller.ListAll).Methods("GET")
+	r.HandleFunc("/api/rules/test", category_rule_controller.Test).Methods("GET")
+	r.HandleFunc("/api/rules/{id}", category_rule_controller.QueryById).Methods("GET")
+	r.HandleFunc("/api/rules/name/{name}", category_rule_controller.QueryByName).Methods("GET")
+
+	// Update
+	r.HandleFunc("/api/rules/{id}", category_rule_controller.UpdateById).Methods("PUT")
+
+	// Delete
+	r.HandleFunc("/api/rules/{id}", category_rule_controller.DeleteById).Methods("DELETE")
+}
+
+func registerStatsRoute(r *mux.Router) {
+	r.HandleFunc("/api/stats/overview", stats_controller.GetOverview).Methods("GET")
+}
+
 // Health check endpoint
 func healthCheck(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -91,17 +261,29 @@ func versionInfo(w http.ResponseWriter, r *http.Request) {
 		"name":        "Cashlens API",
 		"description": "Personal finance management API",
 		"endpoints": map[string][]string{
+			"auth": {
+				"POST /api/auth/register",
+				"POST /api/auth/login",
+				"GET /api/auth/me",
+				"POST /api/auth/tokens",
+				"GET /api/auth/tokens",
+				"DELETE /api/auth/tokens/{id}",
+			},
 			"cash_flow": {
 				"POST /api/cash/outcome",
 				"POST /api/cash/income",
-				"GET /api/cash/list",
+				"POST /api/cash/transfer",
+				"GET /api/cash/list?type=&tag=&sort=&order=&limit=&offset=&cursor=",
+				"GET /api/cash/suggest-category?description=&amount=&type=&limit=",
+				"GET /api/cash/search?text=&from=&to=&min_amount=&max_amount=&type=&category=&tag=&account=&sort=&order=&limit=&offset=&cursor=",
 				"GET /api/cash/{id}",
 				"GET /api/cash/date/{date}",
-				"GET /api/cash/range?from=YYYYMMDD&to=YYYYMMDD",
-				"GET /api/cash/summary/daily/{date}",
-				"GET /api/cash/summary/monthly/{month}",
-				"GET /api/cash/summary/yearly/{year}",
+				"GET /api/cash/range?from=YYYYMMDD&to=YYYYMMDD&limit=&cursor=",
+				"GET /api/cash/summary/daily/{date}?currency=",
+				"GET /api/cash/summary/monthly/{month}?currency=",
+				"GET /api/cash/summary/yearly/{year}?currency=",
 				"PUT /api/cash/{id}",
+				"PUT /api/cash/{id}/split",
 				"DELETE /api/cash/{id}",
 				"DELETE /api/cash/date/{date}",
 			},
@@ -114,6 +296,66 @@ func versionInfo(w http.ResponseWriter, r *http.Request) {
 				"PUT /api/category/{id}",
 				"DELETE /api/category/{id}",
 			},
+			"account": {
+				"POST /api/account",
+				"GET /api/account/list",
+				"GET /api/account/balance",
+				"GET /api/account/{id}",
+				"GET /api/account/{id}/balance",
+				"GET /api/account/name/{name}",
+				"PUT /api/account/{id}",
+				"DELETE /api/account/{id}",
+			},
+			"exchange_rate": {
+				"POST /api/rate",
+				"POST /api/rate/import",
+				"GET /api/rate/list",
+				"DELETE /api/rate/{id}",
+			},
+			"recurring_rule": {
+				"POST /api/recurring",
+				"POST /api/recurring/run?until=YYYYMMDD",
+				"GET /api/recurring/list",
+				"GET /api/recurring/{id}",
+				"GET /api/recurring/name/{name}",
+				"PUT /api/recurring/{id}",
+				"DELETE /api/recurring/{id}",
+			},
+			"budget": {
+				"POST /api/budget",
+				"GET /api/budget/status?category=NAME&date=YYYYMMDD",
+				"DELETE /api/budget/{id}",
+			},
+			"goal": {
+				"POST /api/goals",
+				"GET /api/goals",
+				"GET /api/goals/{id}",
+				"GET /api/goals/{id}/contributions",
+				"PUT /api/goals/{id}",
+				"DELETE /api/goals/{id}",
+			},
+			"payee": {
+				"POST /api/payee",
+				"POST /api/payee/apply-rules?overwrite=true",
+				"GET /api/payee/list",
+				"GET /api/payee/spend?from=YYYYMMDD&to=YYYYMMDD&currency=USD",
+				"GET /api/payee/{id}",
+				"GET /api/payee/name/{name}",
+				"PUT /api/payee/{id}",
+				"DELETE /api/payee/{id}",
+			},
+			"category_rule": {
+				"POST /api/rules",
+				"GET /api/rules",
+				"GET /api/rules/test?description=&amount=&type=&payee=&account=",
+				"GET /api/rules/{id}",
+				"GET /api/rules/name/{name}",
+				"PUT /api/rules/{id}",
+				"DELETE /api/rules/{id}",
+			},
+			"stats": {
+				"GET /api/stats/overview",
+			},
 			"health": {
 				"GET /api/health",
 				"GET /api/version",
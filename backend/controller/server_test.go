@@ -0,0 +1,704 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/api_token_mapper"
+	"github.com/macar-x/cashlens/mapper/budget_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/mapper/ledger_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/user_mapper"
+)
+
+// signedInToken is sent by doRequest, signIn sets it
+var signedInToken string
+
+// signIn registers the first user, who needs no token for it, and signs in as them
+func signIn(t *testing.T, server *httptest.Server) {
+	t.Helper()
+	var user map[string]interface{}
+	signedInToken = ""
+	if statusCode := doRequestAs(t, server, "", "POST", "/api/auth/register",
+		map[string]string{"username": "alice", "password": "correct horse"}, &user); statusCode != http.StatusOK {
+		t.Fatalf("POST /api/auth/register returned status %d: %v", statusCode, user)
+	}
+	var accessToken map[string]interface{}
+	if statusCode := doRequestAs(t, server, "", "POST", "/api/auth/login",
+		map[string]string{"username": "alice", "password": "correct horse"}, &accessToken); statusCode != http.StatusOK ||
+		accessToken["token_type"] != "Bearer" || accessToken["expire_time"] == nil {
+		t.Fatalf("POST /api/auth/login returned status %d: %v", statusCode, accessToken)
+	}
+	signedInToken = accessToken["token"].(string)
+}
+
+func TestApiAuth(t *testing.T) {
+	user_mapper.INSTANCE = user_mapper.NewUserMemoryMapper()
+	api_token_mapper.INSTANCE = api_token_mapper.NewApiTokenMemoryMapper()
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	budget_mapper.INSTANCE = budget_mapper.NewBudgetMemoryMapper()
+	account_mapper.INSTANCE = account_mapper.NewAccountMemoryMapper()
+	exchange_rate_mapper.INSTANCE = exchange_rate_mapper.NewExchangeRateMemoryMapper()
+	recurring_rule_mapper.INSTANCE = recurring_rule_mapper.NewRecurringRuleMemoryMapper()
+	goal_mapper.INSTANCE = goal_mapper.NewGoalMemoryMapper()
+	payee_mapper.INSTANCE = payee_mapper.NewPayeeMemoryMapper()
+	category_rule_mapper.INSTANCE = category_rule_mapper.NewCategoryRuleMemoryMapper()
+
+	server := httptest.NewServer(NewHandler())
+	defer server.Close()
+
+	var health, failure map[string]interface{}
+	if statusCode := doRequestAs(t, server, "", "GET", "/api/health", nil, &health); statusCode != http.StatusOK {
+		t.Errorf("GET /api/health without a token returned status %d, want 200", statusCode)
+	}
+	if statusCode := doRequestAs(t, server, "", "GET", "/api/category/list", nil, &failure); statusCode != http.StatusUnauthorized {
+		t.Errorf("GET /api/category/list without a token returned status %d, want 401", statusCode)
+	}
+	signIn(t, server)
+
+	tests := []struct {
+		name       string
+		token      string
+		method     string
+		path       string
+		body       interface{}
+		wantStatus int
+	}{
+		{"signed in", signedInToken, "GET", "/api/category/list", nil, http.StatusOK},
+		{"tampered token", signedInToken + "x", "GET", "/api/category/list", nil, http.StatusUnauthorized},
+		{"unknown api token", "cl_unknown", "DELETE", "/api/category/000000000000000000000001", nil, http.StatusUnauthorized},
+		{"wrong password", "", "POST", "/api/auth/login", map[string]string{"username": "alice", "password": "wrong horse"}, http.StatusUnauthorized},
+		{"unknown user", "", "POST", "/api/auth/login", map[string]string{"username": "bob", "password": "correct horse"}, http.StatusUnauthorized},
+		{"registration closed", "", "POST", "/api/auth/register", map[string]string{"username": "bob", "password": "battery staple"}, http.StatusUnauthorized},
+		{"added by a user", signedInToken, "POST", "/api/auth/register", map[string]string{"username": "bob", "password": "battery staple"}, http.StatusOK},
+		{"taken username", signedInToken, "POST", "/api/auth/register", map[string]string{"username": "bob", "password": "battery staple"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response interface{}
+			if statusCode := doRequestAs(t, server, tt.token, tt.method, tt.path, tt.body, &response); statusCode != tt.wantStatus {
+				t.Errorf("%s %s returned status %d, want %d: %v", tt.method, tt.path, statusCode, tt.wantStatus, response)
+			}
+		})
+	}
+
+	var me map[string]interface{}
+	doRequest(t, server, "GET", "/api/auth/me", nil, &me)
+	if me["username"] != "alice" || me["password_hash"] != nil {
+		t.Errorf("GET /api/auth/me returned %v, want alice without the password hash", me)
+	}
+
+	// A personal API token works until it is revoked
+	var apiToken map[string]interface{}
+	doRequest(t, server, "POST", "/api/auth/tokens", map[string]string{"name": "nightly import"}, &apiToken)
+	scriptToken, _ := apiToken["token"].(string)
+	if scriptToken == "" || apiToken["name"] != "nightly import" {
+		t.Fatalf("POST /api/auth/tokens returned %v", apiToken)
+	}
+	if statusCode := doRequestAs(t, server, scriptToken, "GET", "/api/auth/me", nil, &me); statusCode != http.StatusOK || me["username"] != "alice" {
+		t.Errorf("GET /api/auth/me with the api token returned status %d: %v", statusCode, me)
+	}
+	var tokenList struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	doRequest(t, server, "GET", "/api/auth/tokens", nil, &tokenList)
+	if len(tokenList.Data) != 1 || tokenList.Data[0]["name"] != "nightly import" || tokenList.Data[0]["token_hash"] != nil {
+		t.Errorf("GET /api/auth/tokens returned %+v, want the token without its hash", tokenList.Data)
+	}
+	doRequest(t, server, "DELETE", "/api/auth/tokens/"+apiToken["id"].(string), nil, &failure)
+	if statusCode := doRequestAs(t, server, scriptToken, "GET", "/api/auth/me", nil, &failure); statusCode != http.StatusUnauthorized {
+		t.Errorf("GET /api/auth/me with a revoked api token returned status %d, want 401", statusCode)
+	}
+}
+
+func TestApiEndToEnd(t *testing.T) {
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	account_mapper.INSTANCE = account_mapper.NewAccountMemoryMapper()
+	exchange_rate_mapper.INSTANCE = exchange_rate_mapper.NewExchangeRateMemoryMapper()
+	recurring_rule_mapper.INSTANCE = recurring_rule_mapper.NewRecurringRuleMemoryMapper()
+	budget_mapper.INSTANCE = budget_mapper.NewBudgetMemoryMapper()
+	goal_mapper.INSTANCE = goal_mapper.NewGoalMemoryMapper()
+	payee_mapper.INSTANCE = payee_mapper.NewPayeeMemoryMapper()
+	category_rule_mapper.INSTANCE = category_rule_mapper.NewCategoryRuleMemoryMapper()
+	user_mapper.INSTANCE = user_mapper.NewUserMemoryMapper()
+	api_token_mapper.INSTANCE = api_token_mapper.NewApiTokenMemoryMapper()
+
+	server := httptest.NewServer(NewHandler())
+	defer server.Close()
+	signIn(t, server)
+
+	var created map[string]string
+	doRequest(t, server, "POST", "/api/category", map[string]string{"name": "Food"}, &created)
+	if created["id"] == "" {
+		t.Fatalf("POST /api/category returned %v, want an id", created)
+	}
+
+	var account map[string]interface{}
+	doRequest(t, server, "POST", "/api/account", map[string]interface{}{
+		"name":            "Wallet",
+		"type":            "cash",
+		"opening_balance": 100,
+	}, &account)
+	if account["type"] != "CASH" {
+		t.Fatalf("POST /api/account returned %v", account)
+	}
+
+	var cashFlow map[string]interface{}
+	doRequest(t, server, "POST", "/api/cash/outcome", map[string]interface{}{
+		"belongs_date":  "20241201",
+		"category_name": "Food",
+		"account_name":  "Wallet",
+		"amount":        12.5,
+		"description":   "lunch",
+		"tags":          []string{"Trip-Japan"},
+	}, &cashFlow)
+	if cashFlow["amount"] != 12.5 {
+		t.Fatalf("POST /api/cash/outcome returned %v", cashFlow)
+	}
+
+	var list struct {
+		Data       []map[string]interface{} `json:"data"`
+		TotalCount int64                    `json:"total_count"`
+	}
+	doRequest(t, server, "GET", "/api/cash/list", nil, &list)
+	if list.TotalCount != 1 || len(list.Data) != 1 {
+		t.Errorf("GET /api/cash/list returned %d of %d records, want 1 of 1", len(list.Data), list.TotalCount)
+	}
+	var taggedList struct {
+		Data       []map[string]interface{} `json:"data"`
+		TotalCount int64                    `json:"total_count"`
+	}
+	doRequest(t, server, "GET", "/api/cash/list?tag=trip-japan", nil, &taggedList)
+	if taggedList.TotalCount != 1 || len(taggedList.Data) != 1 {
+		t.Errorf("GET /api/cash/list?tag=trip-japan returned %d of %d records, want 1 of 1", len(taggedList.Data), taggedList.TotalCount)
+	}
+	doRequest(t, server, "GET", "/api/cash/list?tag=reimbursable", nil, &taggedList)
+	if taggedList.TotalCount != 0 {
+		t.Errorf("GET /api/cash/list?tag=reimbursable returned %d records, want 0", taggedList.TotalCount)
+	}
+
+	var balanceList []struct {
+		AccountName string  `json:"account_name"`
+		Balance     float64 `json:"balance"`
+	}
+	doRequest(t, server, "GET", "/api/account/balance", nil, &balanceList)
+	if len(balanceList) != 1 || balanceList[0].AccountName != "Wallet" || balanceList[0].Balance != 87.5 {
+		t.Errorf("GET /api/account/balance returned %+v, want Wallet at 87.50", balanceList)
+	}
+
+	var summary struct {
+		TransactionCount int
+		Totals           []struct {
+			Currency     string
+			TotalExpense float64
+			TagBreakdown map[string]float64
+		}
+	}
+	doRequest(t, server, "GET", "/api/cash/summary/monthly/202412", nil, &summary)
+	if summary.TransactionCount != 1 || len(summary.Totals) != 1 || summary.Totals[0].Currency != "USD" ||
+		summary.Totals[0].TotalExpense != 12.5 || summary.Totals[0].TagBreakdown["trip-japan"] != 12.5 {
+		t.Errorf("GET /api/cash/summary/monthly returned %+v", summary)
+	}
+
+	var exchangeRate map[string]interface{}
+	doRequest(t, server, "POST", "/api/rate", map[string]interface{}{
+		"from_currency":  "USD",
+		"to_currency":    "EUR",
+		"rate":           0.9,
+		"effective_date": "2024-01-01",
+	}, &exchangeRate)
+	if exchangeRate["rate"] != 0.9 {
+		t.Fatalf("POST /api/rate returned %v", exchangeRate)
+	}
+	var convertedSummary struct {
+		Currency string
+		Totals   []struct {
+			TotalExpense float64
+		}
+	}
+	doRequest(t, server, "GET", "/api/cash/summary/monthly/202412?currency=EUR", nil, &convertedSummary)
+	if convertedSummary.Currency != "EUR" || len(convertedSummary.Totals) != 1 || convertedSummary.Totals[0].TotalExpense != 11.25 {
+		t.Errorf("GET /api/cash/summary/monthly?currency=EUR returned %+v", convertedSummary)
+	}
+
+	var overview struct {
+		CashFlowCount int64  `json:"cash_flow_count"`
+		CategoryCount int64  `json:"category_count"`
+		EarliestDate  string `json:"earliest_date"`
+	}
+	doRequest(t, server, "GET", "/api/stats/overview", nil, &overview)
+	if overview.CashFlowCount != 1 || overview.CategoryCount != 1 || overview.EarliestDate != "2024-12-01" {
+		t.Errorf("GET /api/stats/overview returned %+v", overview)
+	}
+
+	var recurringRule map[string]interface{}
+	doRequest(t, server, "POST", "/api/recurring", map[string]interface{}{
+		"name":             "Gym",
+		"flow_type":        "OUTCOME",
+		"category_name":    "Food",
+		"account_name":     "Wallet",
+		"amount":           "30.00",
+		"frequency":        "WEEKLY",
+		"start_date":       "2024-12-02",
+		"occurrence_limit": 2,
+	}, &recurringRule)
+	if recurringRule["frequency"] != "WEEKLY" || recurringRule["currency"] != "USD" {
+		t.Fatalf("POST /api/recurring returned %v", recurringRule)
+	}
+	var run struct {
+		BookedCount int `json:"booked_count"`
+	}
+	doRequest(t, server, "POST", "/api/recurring/run?until=20241231", nil, &run)
+	doRequest(t, server, "POST", "/api/recurring/run?until=20241231", nil, &run)
+	if run.BookedCount != 0 {
+		t.Errorf("POST /api/recurring/run again booked %d, want 0", run.BookedCount)
+	}
+	doRequest(t, server, "GET", "/api/cash/list", nil, &list)
+	if list.TotalCount != 3 {
+		t.Errorf("GET /api/cash/list after the recurring run returned %d records, want 3", list.TotalCount)
+	}
+	// The filter is applied before paging, so the page is full and the count is the filtered one
+	doRequest(t, server, "GET", "/api/cash/list?type=outcome&sort=amount&order=asc&limit=2", nil, &list)
+	if list.TotalCount != 3 || len(list.Data) != 2 || list.Data[0]["description"] != "lunch" {
+		t.Errorf("GET /api/cash/list?type=outcome returned %+v", list)
+	}
+	doRequest(t, server, "GET", "/api/cash/list?type=income", nil, &list)
+	if list.TotalCount != 0 || len(list.Data) != 0 {
+		t.Errorf("GET /api/cash/list?type=income returned %d of %d records, want none", len(list.Data), list.TotalCount)
+	}
+	type cursorPage struct {
+		Data       []map[string]interface{} `json:"data"`
+		NextCursor string                   `json:"next_cursor"`
+		PrevCursor string                   `json:"prev_cursor"`
+	}
+	// The list pages newest first and the range oldest first, each cursor continues its own order
+	for _, path := range []string{"/api/cash/list?limit=2", "/api/cash/range?from=20241201&to=20241231&limit=2"} {
+		var firstPage, nextPage cursorPage
+		doRequest(t, server, "GET", path, nil, &firstPage)
+		if len(firstPage.Data) != 2 || firstPage.NextCursor == "" || firstPage.PrevCursor != "" {
+			t.Fatalf("GET %s returned %+v", path, firstPage)
+		}
+		doRequest(t, server, "GET", path+"&cursor="+firstPage.NextCursor, nil, &nextPage)
+		if len(nextPage.Data) != 1 || nextPage.NextCursor != "" || nextPage.PrevCursor == "" ||
+			nextPage.Data[0]["Id"] == firstPage.Data[0]["Id"] || nextPage.Data[0]["Id"] == firstPage.Data[1]["Id"] {
+			t.Errorf("GET %s next page returned %+v", path, nextPage)
+		}
+	}
+
+	var budget map[string]interface{}
+	doRequest(t, server, "POST", "/api/budget", map[string]interface{}{
+		"category_name": "Food",
+		"limit":         100,
+		"start_date":    "2024-12-01",
+	}, &budget)
+	if budget["period"] != "MONTHLY" || budget["currency"] != "USD" {
+		t.Fatalf("POST /api/budget returned %v", budget)
+	}
+	var status struct {
+		Data []struct {
+			Spent       float64 `json:"spent"`
+			Remaining   float64 `json:"remaining"`
+			PercentUsed float64 `json:"percent_used"`
+		} `json:"data"`
+	}
+	doRequest(t, server, "GET", "/api/budget/status?category=Food&date=20241215", nil, &status)
+	// The lunch and both gym sessions of December count
+	if len(status.Data) != 1 || status.Data[0].Spent != 72.5 || status.Data[0].Remaining != 27.5 || status.Data[0].PercentUsed != 72.5 {
+		t.Errorf("GET /api/budget/status returned %+v", status)
+	}
+
+	var goal map[string]interface{}
+	doRequest(t, server, "POST", "/api/goals", map[string]interface{}{
+		"name":          "Trip",
+		"account_name":  "Wallet",
+		"target_amount": 1000,
+		"start_date":    "2024-12-01",
+		"deadline":      "2099-12-31",
+	}, &goal)
+	if goal["currency"] != "USD" || goal["Id"] == nil {
+		t.Fatalf("POST /api/goals returned %v", goal)
+	}
+	var progress struct {
+		ContributionCount int     `json:"contribution_count"`
+		Withdrawn         float64 `json:"withdrawn"`
+		Remaining         float64 `json:"remaining"`
+		OnTrack           bool    `json:"on_track"`
+	}
+	doRequest(t, server, "GET", "/api/goals/"+goal["Id"].(string), nil, &progress)
+	// Only outcomes went through the wallet, so nothing is saved yet
+	if progress.ContributionCount != 0 || progress.Withdrawn != 72.5 || progress.Remaining != 1072.5 || progress.OnTrack {
+		t.Errorf("GET /api/goals/{id} returned %+v", progress)
+	}
+	var contributions struct {
+		Count int `json:"count"`
+	}
+	doRequest(t, server, "GET", "/api/goals/"+goal["Id"].(string)+"/contributions", nil, &contributions)
+	if contributions.Count != 3 {
+		t.Errorf("GET /api/goals/{id}/contributions returned %d records, want 3", contributions.Count)
+	}
+
+	doRequest(t, server, "POST", "/api/category", map[string]string{"name": "Household"}, &created)
+	var splitCashFlow struct {
+		CategoryId string
+		Splits     []map[string]interface{} `json:"splits"`
+	}
+	doRequest(t, server, "PUT", "/api/cash/"+cashFlow["Id"].(string)+"/split", map[string]interface{}{
+		"lines": []map[string]interface{}{
+			{"category_name": "Food", "amount": 10},
+			{"category_name": "Household", "amount": "2.50", "description": "napkins"},
+		},
+	}, &splitCashFlow)
+	if len(splitCashFlow.Splits) != 2 || splitCashFlow.Splits[1]["description"] != "napkins" {
+		t.Fatalf("PUT /api/cash/{id}/split returned %+v", splitCashFlow)
+	}
+	var splitSummary struct {
+		TransactionCount int
+		Totals           []struct {
+			TotalExpense      float64
+			CategoryBreakdown map[string]float64
+		}
+	}
+	doRequest(t, server, "GET", "/api/cash/summary/monthly/202412", nil, &splitSummary)
+	// The lunch is still one transaction, its napkins move to Household
+	if splitSummary.TransactionCount != 3 || len(splitSummary.Totals) != 1 || splitSummary.Totals[0].TotalExpense != 72.5 ||
+		splitSummary.Totals[0].CategoryBreakdown["Food"] != 70 || splitSummary.Totals[0].CategoryBreakdown["Household"] != 2.5 {
+		t.Errorf("GET /api/cash/summary/monthly after the split returned %+v", splitSummary)
+	}
+
+	var payee map[string]interface{}
+	doRequest(t, server, "POST", "/api/payee", map[string]interface{}{
+		"name":  "Starbucks",
+		"rules": []map[string]string{{"match_type": "contains", "pattern": "starbucks"}},
+	}, &payee)
+	var coffee map[string]interface{}
+	doRequest(t, server, "POST", "/api/cash/outcome", map[string]interface{}{
+		"belongs_date":  "20241220",
+		"category_name": "Food",
+		"amount":        4.5,
+		"description":   "STARBUCKS #1234",
+	}, &coffee)
+	if coffee["payee_id"] != payee["Id"] {
+		t.Fatalf("POST /api/cash/outcome returned payee %v, want %v", coffee["payee_id"], payee["Id"])
+	}
+	var spend struct {
+		Data  []map[string]interface{} `json:"data"`
+		Count int                      `json:"count"`
+	}
+	doRequest(t, server, "GET", "/api/payee/spend?from=20241201&to=20241231", nil, &spend)
+	if spend.Count != 2 || spend.Data[1]["payee_name"] != "Starbucks" || spend.Data[1]["total_amount"] != 4.5 {
+		t.Errorf("GET /api/payee/spend returned %+v", spend)
+	}
+
+	var rule map[string]interface{}
+	doRequest(t, server, "POST", "/api/rules", map[string]interface{}{
+		"name":          "Coffee",
+		"category_name": "Food",
+		"priority":      10,
+		"conditions":    map[string]interface{}{"payee_name": "Starbucks", "max_amount": 20},
+		"tags":          []string{"coffee"},
+	}, &rule)
+	var ruleMatch struct {
+		Rule         map[string]interface{} `json:"rule"`
+		CategoryName string                 `json:"category_name"`
+		PayeeName    string                 `json:"payee_name"`
+	}
+	doRequest(t, server, "GET", "/api/rules/test?description=STARBUCKS%20%235678&amount=6", nil, &ruleMatch)
+	if ruleMatch.CategoryName != "Food" || ruleMatch.PayeeName != "Starbucks" || ruleMatch.Rule["name"] != "Coffee" {
+		t.Fatalf("GET /api/rules/test returned %+v", ruleMatch)
+	}
+	// The category is left out, the rule picks it and adds its tag
+	var latte map[string]interface{}
+	doRequest(t, server, "POST", "/api/cash/outcome", map[string]interface{}{
+		"belongs_date": "20241221",
+		"amount":       6,
+		"description":  "STARBUCKS #5678",
+	}, &latte)
+	latteTags, _ := latte["tags"].([]interface{})
+	if latte["category_id"] != rule["category_id"] || len(latteTags) != 1 || latteTags[0] != "coffee" {
+		t.Errorf("POST /api/cash/outcome without category returned %+v", latte)
+	}
+
+	var suggestions struct {
+		Data  []map[string]interface{} `json:"data"`
+		Count int                      `json:"count"`
+	}
+	doRequest(t, server, "GET", "/api/cash/suggest-category?description=starbucks%20mocha&amount=5&limit=1", nil, &suggestions)
+	if suggestions.Count != 1 || suggestions.Data[0]["category_name"] != "Food" {
+		t.Errorf("GET /api/cash/suggest-category returned %+v", suggestions)
+	}
+
+	var searchPage struct {
+		Data       []map[string]interface{} `json:"data"`
+		TotalCount int                      `json:"total_count"`
+		Limit      int                      `json:"limit"`
+	}
+	doRequest(t, server, "GET", "/api/cash/search?text=starbucks&category=Food&sort=amount&order=asc&limit=1", nil, &searchPage)
+	if searchPage.TotalCount != 2 || searchPage.Limit != 1 || len(searchPage.Data) != 1 ||
+		searchPage.Data[0]["description"] != "STARBUCKS #1234" {
+		t.Errorf("GET /api/cash/search returned %+v", searchPage)
+	}
+}
+
+func TestApiOwnerIsolation(t *testing.T) {
+	user_mapper.INSTANCE = user_mapper.NewUserMemoryMapper()
+	api_token_mapper.INSTANCE = api_token_mapper.NewApiTokenMemoryMapper()
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	budget_mapper.INSTANCE = budget_mapper.NewBudgetMemoryMapper()
+	account_mapper.INSTANCE = account_mapper.NewAccountMemoryMapper()
+	exchange_rate_mapper.INSTANCE = exchange_rate_mapper.NewExchangeRateMemoryMapper()
+	recurring_rule_mapper.INSTANCE = recurring_rule_mapper.NewRecurringRuleMemoryMapper()
+	goal_mapper.INSTANCE = goal_mapper.NewGoalMemoryMapper()
+	payee_mapper.INSTANCE = payee_mapper.NewPayeeMemoryMapper()
+	category_rule_mapper.INSTANCE = category_rule_mapper.NewCategoryRuleMemoryMapper()
+
+	server := httptest.NewServer(NewHandler())
+	defer server.Close()
+	signIn(t, server)
+	aliceToken := signedInToken
+
+	var bob, accessToken map[string]interface{}
+	doRequest(t, server, "POST", "/api/auth/register", map[string]string{"username": "bob", "password": "battery staple"}, &bob)
+	if statusCode := doRequestAs(t, server, "", "POST", "/api/auth/login",
+		map[string]string{"username": "bob", "password": "battery staple"}, &accessToken); statusCode != http.StatusOK {
+		t.Fatalf("POST /api/auth/login as bob returned status %d: %v", statusCode, accessToken)
+	}
+	bobToken := accessToken["token"].(string)
+
+	// Both users keep a category with the same name
+	for _, token := range []string{aliceToken, bobToken} {
+		var created map[string]interface{}
+		if statusCode := doRequestAs(t, server, token, "POST", "/api/category", map[string]string{"name": "Food"}, &created); statusCode != http.StatusOK {
+			t.Fatalf("POST /api/category returned status %d: %v", statusCode, created)
+		}
+	}
+	// and an account with the same name
+	for _, token := range []string{aliceToken, bobToken} {
+		var created map[string]interface{}
+		if statusCode := doRequestAs(t, server, token, "POST", "/api/account", map[string]string{"name": "Wallet"}, &created); statusCode != http.StatusOK {
+			t.Fatalf("POST /api/account returned status %d: %v", statusCode, created)
+		}
+	}
+	var aliceAccount map[string]interface{}
+	doRequestAs(t, server, aliceToken, "GET", "/api/account/name/Wallet", nil, &aliceAccount)
+	aliceAccountId, _ := aliceAccount["Id"].(string)
+	var alicePayee map[string]interface{}
+	if statusCode := doRequestAs(t, server, aliceToken, "POST", "/api/payee", map[string]string{"name": "Bakery"}, &alicePayee); statusCode != http.StatusOK {
+		t.Fatalf("POST /api/payee returned status %d: %v", statusCode, alicePayee)
+	}
+	alicePayeeId, _ := alicePayee["Id"].(string)
+	var aliceRule map[string]interface{}
+	if statusCode := doRequestAs(t, server, aliceToken, "POST", "/api/recurring", map[string]interface{}{
+		"name": "Lunch", "flow_type": "outcome", "category_name": "Food", "amount": 8,
+		"frequency": "daily", "start_date": "20241201",
+	}, &aliceRule); statusCode != http.StatusOK {
+		t.Fatalf("POST /api/recurring returned status %d: %v", statusCode, aliceRule)
+	}
+
+	var cashFlow map[string]interface{}
+	doRequest(t, server, "POST", "/api/cash/outcome", map[string]interface{}{
+		"belongs_date":  "20241201",
+		"category_name": "Food",
+		"amount":        12.5,
+		"description":   "lunch",
+	}, &cashFlow)
+	cashFlowId, _ := cashFlow["Id"].(string)
+	if cashFlowId == "" {
+		t.Fatalf("POST /api/cash/outcome returned %v, want an id", cashFlow)
+	}
+
+	var list struct {
+		Data       []map[string]interface{} `json:"data"`
+		TotalCount int64                    `json:"total_count"`
+	}
+	doRequestAs(t, server, bobToken, "GET", "/api/cash/list", nil, &list)
+	if list.TotalCount != 0 {
+		t.Errorf("GET /api/cash/list as bob returned %d records, want none of alice's", list.TotalCount)
+	}
+	doRequestAs(t, server, bobToken, "GET", "/api/category/list", nil, &list)
+	if list.TotalCount != 1 {
+		t.Errorf("GET /api/category/list as bob returned %d categories, want only his own", list.TotalCount)
+	}
+	doRequestAs(t, server, bobToken, "GET", "/api/account/list", nil, &list)
+	if list.TotalCount != 1 {
+		t.Errorf("GET /api/account/list as bob returned %d accounts, want only his own", list.TotalCount)
+	}
+	doRequestAs(t, server, bobToken, "GET", "/api/payee/list", nil, &list)
+	if list.TotalCount != 0 {
+		t.Errorf("GET /api/payee/list as bob returned %d payees, want none of alice's", list.TotalCount)
+	}
+	var run map[string]interface{}
+	doRequestAs(t, server, bobToken, "POST", "/api/recurring/run?until=20241203", nil, &run)
+	if run["booked_count"] != float64(0) {
+		t.Errorf("POST /api/recurring/run as bob returned %v, want none of alice's rules booked", run)
+	}
+	var failure map[string]interface{}
+	if statusCode := doRequestAs(t, server, bobToken, "GET", "/api/account/"+aliceAccountId, nil, &failure); statusCode == http.StatusOK {
+		t.Errorf("GET /api/account/%s as bob returned alice's account: %v", aliceAccountId, failure)
+	}
+	if statusCode := doRequestAs(t, server, bobToken, "DELETE", "/api/payee/"+alicePayeeId, nil, &failure); statusCode == http.StatusOK {
+		t.Errorf("DELETE /api/payee/%s as bob deleted alice's payee", alicePayeeId)
+	}
+	doRequestAs(t, server, bobToken, "GET", "/api/cash/"+cashFlowId, nil, &failure)
+	if failure["error"] == nil {
+		t.Errorf("GET /api/cash/%s as bob returned alice's record: %v", cashFlowId, failure)
+	}
+	if statusCode := doRequestAs(t, server, aliceToken, "GET", "/api/cash/"+cashFlowId, nil, &cashFlow); statusCode != http.StatusOK {
+		t.Errorf("GET /api/cash/%s as alice returned status %d", cashFlowId, statusCode)
+	}
+}
+
+func TestApiSharedLedger(t *testing.T) {
+	user_mapper.INSTANCE = user_mapper.NewUserMemoryMapper()
+	api_token_mapper.INSTANCE = api_token_mapper.NewApiTokenMemoryMapper()
+	ledger_mapper.INSTANCE = ledger_mapper.NewLedgerMemoryMapper()
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	budget_mapper.INSTANCE = budget_mapper.NewBudgetMemoryMapper()
+	account_mapper.INSTANCE = account_mapper.NewAccountMemoryMapper()
+	exchange_rate_mapper.INSTANCE = exchange_rate_mapper.NewExchangeRateMemoryMapper()
+	recurring_rule_mapper.INSTANCE = recurring_rule_mapper.NewRecurringRuleMemoryMapper()
+	goal_mapper.INSTANCE = goal_mapper.NewGoalMemoryMapper()
+	payee_mapper.INSTANCE = payee_mapper.NewPayeeMemoryMapper()
+	category_rule_mapper.INSTANCE = category_rule_mapper.NewCategoryRuleMemoryMapper()
+
+	server := httptest.NewServer(NewHandler())
+	defer server.Close()
+	signIn(t, server)
+	aliceToken := signedInToken
+
+	var bob, accessToken map[string]interface{}
+	doRequest(t, server, "POST", "/api/auth/register", map[string]string{"username": "bob", "password": "battery staple"}, &bob)
+	doRequestAs(t, server, "", "POST", "/api/auth/login", map[string]string{"username": "bob", "password": "battery staple"}, &accessToken)
+	bobToken := accessToken["token"].(string)
+	bobId := bob["Id"].(string)
+
+	// alice shares a household ledger with bob as a viewer
+	var ledger, invitation, joined map[string]interface{}
+	doRequest(t, server, "POST", "/api/ledgers", map[string]string{"name": "Household"}, &ledger)
+	ledgerId, _ := ledger["id"].(string)
+	if ledgerId == "" || ledger["role"] != "OWNER" {
+		t.Fatalf("POST /api/ledgers returned %v, want an owned ledger", ledger)
+	}
+	doRequest(t, server, "POST", "/api/ledgers/"+ledgerId+"/invitations", map[string]string{}, &invitation)
+	invitationToken, _ := invitation["token"].(string)
+	if invitationToken == "" || invitation["role"] != "VIEWER" {
+		t.Fatalf("POST /api/ledgers/%s/invitations returned %v, want a viewer token", ledgerId, invitation)
+	}
+	var failure map[string]interface{}
+	if statusCode := doLedgerRequestAs(t, server, bobToken, ledgerId, "GET", "/api/cash/list", nil, &failure); statusCode != http.StatusNotFound {
+		t.Errorf("GET /api/cash/list on a ledger bob has not joined returned status %d, want 404", statusCode)
+	}
+	if statusCode := doRequestAs(t, server, bobToken, "POST", "/api/ledgers/invitations/accept",
+		map[string]string{"token": invitationToken}, &joined); statusCode != http.StatusOK || joined["id"] != ledgerId {
+		t.Fatalf("POST /api/ledgers/invitations/accept returned status %d: %v", statusCode, joined)
+	}
+
+	doLedgerRequestAs(t, server, aliceToken, ledgerId, "POST", "/api/category", map[string]string{"name": "Food"}, &failure)
+	outcome := map[string]interface{}{"belongs_date": "20241201", "category_name": "Food", "amount": 30, "description": "groceries"}
+	var cashFlow map[string]interface{}
+	if statusCode := doLedgerRequestAs(t, server, aliceToken, ledgerId, "POST", "/api/cash/outcome", outcome, &cashFlow); statusCode != http.StatusOK {
+		t.Fatalf("POST /api/cash/outcome on the ledger returned status %d: %v", statusCode, cashFlow)
+	}
+
+	var list struct {
+		Data       []map[string]interface{} `json:"data"`
+		TotalCount int64                    `json:"total_count"`
+	}
+	if statusCode := doLedgerRequestAs(t, server, bobToken, ledgerId, "GET", "/api/cash/list", nil, &list); statusCode != http.StatusOK || list.TotalCount != 1 {
+		t.Errorf("GET /api/cash/list on the ledger as bob returned status %d with %d records, want alice's one", statusCode, list.TotalCount)
+	}
+	if statusCode := doLedgerRequestAs(t, server, bobToken, ledgerId, "POST", "/api/cash/outcome", outcome, &failure); statusCode != http.StatusForbidden {
+		t.Errorf("POST /api/cash/outcome as a viewer returned status %d, want 403", statusCode)
+	}
+	if statusCode := doLedgerRequestAs(t, server, bobToken, ledgerId, "POST", "/api/recurring/run", nil, &failure); statusCode != http.StatusForbidden {
+		t.Errorf("POST /api/recurring/run as a viewer returned status %d, want 403", statusCode)
+	}
+	if statusCode := doRequestAs(t, server, bobToken, "PUT", "/api/ledgers/"+ledgerId+"/members/"+bobId,
+		map[string]string{"role": "EDITOR"}, &failure); statusCode != http.StatusForbidden {
+		t.Errorf("PUT /api/ledgers/%s/members as a viewer returned status %d, want 403", ledgerId, statusCode)
+	}
+
+	// Promoted to editor, bob can book into the ledger and is recorded as the creator
+	var member map[string]interface{}
+	doRequest(t, server, "PUT", "/api/ledgers/"+ledgerId+"/members/"+bobId, map[string]string{"role": "EDITOR"}, &member)
+	if statusCode := doLedgerRequestAs(t, server, bobToken, ledgerId, "POST", "/api/cash/outcome", outcome, &cashFlow); statusCode != http.StatusOK {
+		t.Fatalf("POST /api/cash/outcome as an editor returned status %d: %v", statusCode, cashFlow)
+	}
+	if cashFlow["created_by"] != bobId || cashFlow["modified_by"] != bobId {
+		t.Errorf("POST /api/cash/outcome as bob returned created_by %v, modified_by %v, want %s", cashFlow["created_by"], cashFlow["modified_by"], bobId)
+	}
+	doRequestAs(t, server, bobToken, "GET", "/api/cash/list", nil, &list)
+	if list.TotalCount != 0 {
+		t.Errorf("GET /api/cash/list on bob's personal ledger returned %d records, want the shared ones kept apart", list.TotalCount)
+	}
+
+	var members struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	doRequestAs(t, server, bobToken, "GET", "/api/ledgers/"+ledgerId+"/members", nil, &members)
+	if len(members.Data) != 2 || members.Data[1]["username"] != "bob" || members.Data[1]["role"] != "EDITOR" {
+		t.Errorf("GET /api/ledgers/%s/members returned %v, want alice and bob as editor", ledgerId, members.Data)
+	}
+}
+
+// doRequest sends the request with the signed-in token and fails the test unless it succeeds
+func doRequest(t *testing.T, server *httptest.Server, method, path string, body, response interface{}) {
+	t.Helper()
+	if statusCode := doRequestAs(t, server, signedInToken, method, path, body, response); statusCode != http.StatusOK {
+		t.Fatalf("%s %s returned status %d", method, path, statusCode)
+	}
+}
+
+// doRequestAs sends the request with the given token, none when blank, and returns the status code
+func doRequestAs(t *testing.T, server *httptest.Server, token, method, path string, body, response interface{}) int {
+	t.Helper()
+	return doLedgerRequestAs(t, server, token, "", method, path, body, response)
+}
+
+// doLedgerRequestAs sends the request like doRequestAs, on the given ledger unless blank
+func doLedgerRequestAs(t *testing.T, server *httptest.Server, token, ledgerPlainId, method, path string, body, response interface{}) int {
+	t.Helper()
+
+	var requestBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&requestBody).Encode(body); err != nil {
+			t.Fatalf("encode %s %s body: %v", method, path, err)
+		}
+	}
+
+	request, err := http.NewRequest(method, server.URL+path, &requestBody)
+	if err != nil {
+		t.Fatalf("build %s %s: %v", method, path, err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	if ledgerPlainId != "" {
+		request.Header.Set("X-Ledger-Id", ledgerPlainId)
+	}
+
+	httpResponse, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer httpResponse.Body.Close()
+
+	if err := json.NewDecoder(httpResponse.Body).Decode(response); err != nil {
+		t.Fatalf("decode %s %s response: %v", method, path, err)
+	}
+	return httpResponse.StatusCode
+}
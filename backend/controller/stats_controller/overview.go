@@ -0,0 +1,20 @@
+package stats_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/service/manage_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// GetOverview returns record counts, per-currency totals and balance, date span and per-category counts
+func GetOverview(w http.ResponseWriter, r *http.Request) {
+	stats, err := manage_service.GetDatabaseStats(middleware.CurrentLedger(r))
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, stats)
+}
@@ -0,0 +1,59 @@
+package user_controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/user_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// CreateApiToken makes a personal API token for the signed-in user, the token is only shown in this response
+func CreateApiToken(w http.ResponseWriter, r *http.Request) {
+	var requestBody model.ApiTokenDTO
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	apiTokenEntity, token, err := user_service.CreateApiTokenService(middleware.CurrentUser(r).Id.Hex(), requestBody)
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"id":          apiTokenEntity.Id.Hex(),
+		"name":        apiTokenEntity.Name,
+		"create_time": apiTokenEntity.CreateTime,
+		"token":       token,
+		"token_type":  "Bearer",
+	})
+}
+
+// ListApiTokens lists the API tokens of the signed-in user, without the tokens themselves
+func ListApiTokens(w http.ResponseWriter, r *http.Request) {
+	apiTokenList := user_service.ListApiTokensService(middleware.CurrentUser(r).Id.Hex())
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":        apiTokenList,
+		"total_count": len(apiTokenList),
+	})
+}
+
+// RevokeApiTokenById deletes one of the signed-in user's API tokens
+func RevokeApiTokenById(w http.ResponseWriter, r *http.Request) {
+	plainId := mux.Vars(r)["id"]
+	if plainId == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
+		return
+	}
+
+	if _, err := user_service.RevokeApiTokenService(middleware.CurrentUser(r).Id.Hex(), plainId); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, map[string]string{"message": "api token revoked successfully"})
+}
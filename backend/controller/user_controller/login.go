@@ -0,0 +1,42 @@
+package user_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/errors"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/user_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Login exchanges a username and password for a sign-in token
+func Login(w http.ResponseWriter, r *http.Request) {
+	var requestBody model.UserDTO
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if requestBody.Username == "" || requestBody.Password == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "some required fields are empty"})
+		return
+	}
+
+	accessToken, err := user_service.LoginService(requestBody)
+	if errors.IsUnauthorized(err) {
+		util.ComposeJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		util.ComposeJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, accessToken)
+}
+
+// Me returns the signed-in user
+func Me(w http.ResponseWriter, r *http.Request) {
+	util.ComposeJSONResponse(w, http.StatusOK, middleware.CurrentUser(r))
+}
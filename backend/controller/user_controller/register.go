@@ -0,0 +1,47 @@
+package user_controller
+
+import (
+	"net/http"
+
+	"github.com/macar-x/cashlens/errors"
+	"github.com/macar-x/cashlens/middleware"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/user_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Register creates a user. Anyone may create the first one, after that a signed-in user has to.
+func Register(w http.ResponseWriter, r *http.Request) {
+	var requestBody model.UserDTO
+	if err := util.ParseJSONRequest(r, &requestBody); err != nil {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if requestBody.Username == "" || requestBody.Password == "" {
+		util.ComposeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "some required fields are empty"})
+		return
+	}
+
+	if middleware.CurrentUser(r).IsEmpty() && !user_service.IsRegistrationOpen() {
+		util.ComposeJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "sign in to add more users"})
+		return
+	}
+
+	registerService := user_service.RegisterService
+	if middleware.CurrentUser(r).IsEmpty() {
+		// 另一個註冊可能剛好搶先建立了第一個使用者
+		registerService = user_service.RegisterFirstService
+	}
+	userEntity, err := registerService(requestBody)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if errors.IsUnauthorized(err) {
+			statusCode = http.StatusUnauthorized
+		}
+		util.ComposeJSONResponse(w, statusCode, map[string]string{"error": err.Error()})
+		return
+	}
+
+	util.ComposeJSONResponse(w, http.StatusOK, userEntity)
+}
@@ -10,6 +10,7 @@ const (
 	ErrInvalidInput     ErrorCode = "INVALID_INPUT"
 	ErrDatabase         ErrorCode = "DATABASE_ERROR"
 	ErrUnauthorized     ErrorCode = "UNAUTHORIZED"
+	ErrForbidden        ErrorCode = "FORBIDDEN"
 	ErrAlreadyExists    ErrorCode = "ALREADY_EXISTS"
 	ErrInternal         ErrorCode = "INTERNAL_ERROR"
 	ErrValidation       ErrorCode = "VALIDATION_ERROR"
@@ -77,6 +78,22 @@ func NewValidationError(message string) *AppError {
 	}
 }
 
+// NewUnauthorizedError creates an UNAUTHORIZED error
+func NewUnauthorizedError(message string) *AppError {
+	return &AppError{
+		Code:    ErrUnauthorized,
+		Message: message,
+	}
+}
+
+// NewForbiddenError creates a FORBIDDEN error
+func NewForbiddenError(message string) *AppError {
+	return &AppError{
+		Code:    ErrForbidden,
+		Message: message,
+	}
+}
+
 // NewAlreadyExistsError creates an ALREADY_EXISTS error
 func NewAlreadyExistsError(message string) *AppError {
 	return &AppError{
@@ -126,3 +143,19 @@ func IsDatabaseError(err error) bool {
 	}
 	return false
 }
+
+// IsUnauthorized checks if error is an UNAUTHORIZED error
+func IsUnauthorized(err error) bool {
+	if appErr, ok := err.(*AppError); ok {
+		return appErr.Code == ErrUnauthorized
+	}
+	return false
+}
+
+// IsForbidden checks if error is a FORBIDDEN error
+func IsForbidden(err error) bool {
+	if appErr, ok := err.(*AppError); ok {
+		return appErr.Code == ErrForbidden
+	}
+	return false
+}
@@ -119,6 +119,70 @@ func TestIsValidationError(t *testing.T) {
 	}
 }
 
+func TestIsUnauthorized(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "AppError with UNAUTHORIZED code",
+			err:  NewUnauthorizedError("invalid token"),
+			want: true,
+		},
+		{
+			name: "AppError with different code",
+			err:  NewValidationError("validation failed"),
+			want: false,
+		},
+		{
+			name: "Standard error",
+			err:  errors.New("standard error"),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsUnauthorized(tt.err); got != tt.want {
+				t.Errorf("IsUnauthorized() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsForbidden(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "AppError with FORBIDDEN code",
+			err:  NewForbiddenError("viewers cannot change the ledger"),
+			want: true,
+		},
+		{
+			name: "AppError with UNAUTHORIZED code",
+			err:  NewUnauthorizedError("invalid token"),
+			want: false,
+		},
+		{
+			name: "Standard error",
+			err:  errors.New("standard error"),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsForbidden(tt.err); got != tt.want {
+				t.Errorf("IsForbidden() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestAppError_Unwrap(t *testing.T) {
 	cause := errors.New("underlying error")
 	err := NewDatabaseError("database error", cause)
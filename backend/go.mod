@@ -17,6 +17,8 @@ require (
 	go.mongodb.org/mongo-driver v1.13.1
 	go.uber.org/multierr v1.11.0 // indirect
 	go.uber.org/zap v1.26.0
+	golang.org/x/crypto v0.18.0
 	golang.org/x/net v0.20.0 // indirect
 	golang.org/x/sync v0.6.0 // indirect
+	modernc.org/sqlite v1.28.0
 )
@@ -0,0 +1,54 @@
+package account_mapper
+
+import (
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+)
+
+var INSTANCE AccountMapper
+
+// AccountMapper stores accounts. Every owner has accounts of their own, a blank owner stands
+// for the accounts no user owns; lookups by id and the GetAll/DeleteAll methods span all owners.
+type AccountMapper interface {
+	GetAccountByObjectId(plainId string) model.AccountEntity
+	GetAccountByName(ownerPlainId, accountName string) model.AccountEntity
+	GetAccountsByOwnerId(ownerPlainId string, limit, offset int) []model.AccountEntity
+	CountAccountsByOwnerId(ownerPlainId string) int64
+	InsertAccountByEntity(newEntity model.AccountEntity) string
+	BulkInsertAccounts(entities []model.AccountEntity) ([]string, error)
+	UpdateAccountByEntity(plainId string, updatedEntity model.AccountEntity) model.AccountEntity
+	GetAllAccounts(limit, offset int) []model.AccountEntity
+	CountAllAccounts() int64
+	DeleteAccountByObjectId(plainId string) model.AccountEntity
+	DeleteAllAccounts() (int64, error)
+	AssignUnownedAccounts(ownerPlainId string) (int64, error)
+}
+
+func init() {
+	switch util.GetConfigByKey("db.type") {
+	case "mongodb":
+		INSTANCE = AccountMongoDbMapper{}
+	case "mysql":
+		INSTANCE = AccountMySqlMapper{}
+	case "sqlite":
+		INSTANCE = AccountSqliteMapper{}
+	case "memory":
+		INSTANCE = NewAccountMemoryMapper()
+	default:
+		panic("database type not supported")
+	}
+}
+
+// fillOperatingTime keeps create/modify times carried over from a backup,
+// and stamps the operating time on brand-new records.
+func fillOperatingTime(entity model.AccountEntity, operatingTime time.Time) model.AccountEntity {
+	if entity.CreateTime.IsZero() {
+		entity.CreateTime = operatingTime
+	}
+	if entity.ModifyTime.IsZero() {
+		entity.ModifyTime = operatingTime
+	}
+	return entity
+}
@@ -0,0 +1,214 @@
+package account_mapper
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccountMemoryMapper keeps accounts in process memory, nothing is persisted.
+// It is safe for concurrent use; copies of the mapper share the same data.
+type AccountMemoryMapper struct {
+	store *accountMemoryStore
+}
+
+type accountMemoryStore struct {
+	mutex   sync.RWMutex
+	records map[primitive.ObjectID]model.AccountEntity
+}
+
+// NewAccountMemoryMapper returns an empty in-memory mapper
+func NewAccountMemoryMapper() AccountMemoryMapper {
+	return AccountMemoryMapper{
+		store: &accountMemoryStore{
+			records: make(map[primitive.ObjectID]model.AccountEntity),
+		},
+	}
+}
+
+func (mapper AccountMemoryMapper) GetAccountByObjectId(plainId string) model.AccountEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("account's id is not acceptable")
+		return model.AccountEntity{}
+	}
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return mapper.store.records[objectId]
+}
+
+func (mapper AccountMemoryMapper) GetAccountByName(ownerPlainId, accountName string) model.AccountEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.AccountEntity) bool {
+		return entity.OwnerId == ownerId && entity.Name == accountName
+	})
+	if len(targetEntityList) == 0 {
+		return model.AccountEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (mapper AccountMemoryMapper) InsertAccountByEntity(newEntity model.AccountEntity) string {
+	newPlainIdList, err := mapper.BulkInsertAccounts([]model.AccountEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+	return newPlainIdList[0]
+}
+
+func (mapper AccountMemoryMapper) BulkInsertAccounts(entities []model.AccountEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	operatingTime := time.Now()
+	newEntityList := make([]model.AccountEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.records[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate account id: " + entity.Id.Hex())
+		}
+		batchIds[entity.Id] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.records[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper AccountMemoryMapper) UpdateAccountByEntity(plainId string, updatedEntity model.AccountEntity) model.AccountEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("account is not exist")
+		return model.AccountEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+	mapper.store.records[objectId] = updatedEntity
+	return updatedEntity
+}
+
+func (mapper AccountMemoryMapper) GetAllAccounts(limit, offset int) []model.AccountEntity {
+	targetEntityList := mapper.filter(func(model.AccountEntity) bool { return true })
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper AccountMemoryMapper) GetAccountsByOwnerId(ownerPlainId string, limit, offset int) []model.AccountEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.AccountEntity) bool {
+		return entity.OwnerId == ownerId
+	})
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper AccountMemoryMapper) CountAllAccounts() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.records))
+}
+
+func (mapper AccountMemoryMapper) CountAccountsByOwnerId(ownerPlainId string) int64 {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	return int64(len(mapper.filter(func(entity model.AccountEntity) bool {
+		return entity.OwnerId == ownerId
+	})))
+}
+
+func (mapper AccountMemoryMapper) DeleteAccountByObjectId(plainId string) model.AccountEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("account is not exist")
+		return model.AccountEntity{}
+	}
+
+	delete(mapper.store.records, objectId)
+	return targetEntity
+}
+
+func (mapper AccountMemoryMapper) DeleteAllAccounts() (int64, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	deletedCount := int64(len(mapper.store.records))
+	mapper.store.records = make(map[primitive.ObjectID]model.AccountEntity)
+	return deletedCount, nil
+}
+
+func (mapper AccountMemoryMapper) AssignUnownedAccounts(ownerPlainId string) (int64, error) {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	var assignedCount int64
+	for id, entity := range mapper.store.records {
+		if entity.OwnerId == primitive.NilObjectID {
+			entity.OwnerId = ownerId
+			mapper.store.records[id] = entity
+			assignedCount++
+		}
+	}
+	return assignedCount, nil
+}
+
+// paginate cuts one page out of the ordered accounts, a zero limit keeps all of them
+func paginate(targetEntityList []model.AccountEntity, limit, offset int) []model.AccountEntity {
+	if offset >= len(targetEntityList) {
+		return []model.AccountEntity{}
+	}
+	targetEntityList = targetEntityList[offset:]
+	if limit > 0 && limit < len(targetEntityList) {
+		targetEntityList = targetEntityList[:limit]
+	}
+	return targetEntityList
+}
+
+// filter returns the matching accounts ordered by name, like the database mappers
+func (mapper AccountMemoryMapper) filter(isMatched func(entity model.AccountEntity) bool) []model.AccountEntity {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	var targetEntityList []model.AccountEntity
+	for _, entity := range mapper.store.records {
+		if isMatched(entity) {
+			targetEntityList = append(targetEntityList, entity)
+		}
+	}
+
+	sort.Slice(targetEntityList, func(i, j int) bool {
+		if targetEntityList[i].Name != targetEntityList[j].Name {
+			return targetEntityList[i].Name < targetEntityList[j].Name
+		}
+		return targetEntityList[i].Id.Hex() < targetEntityList[j].Id.Hex()
+	})
+	return targetEntityList
+}
@@ -0,0 +1,257 @@
+package account_mapper
+
+import (
+	"context"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AccountMongoDbMapper struct{}
+
+func (AccountMongoDbMapper) GetAccountByObjectId(plainId string) model.AccountEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("account's id is not acceptable")
+		return model.AccountEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.AccountTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2AccountEntity(database.GetOneInMongoDB(filter))
+}
+
+func (AccountMongoDbMapper) GetAccountByName(ownerPlainId, accountName string) model.AccountEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+		primitive.E{Key: "name", Value: accountName},
+	}
+
+	database.OpenMongoDbConnection(database.AccountTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2AccountEntity(database.GetOneInMongoDB(filter))
+}
+
+func (AccountMongoDbMapper) InsertAccountByEntity(newEntity model.AccountEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	database.OpenMongoDbConnection(database.AccountTableName)
+	defer database.CloseMongoDbConnection()
+
+	newAccountId := database.InsertOneInMongoDB(convertAccountEntity2BsonD(newEntity))
+	return newAccountId.Hex()
+}
+
+func (AccountMongoDbMapper) BulkInsertAccounts(entities []model.AccountEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		documents[i] = convertAccountEntity2BsonD(fillOperatingTime(entity, operatingTime))
+	}
+
+	collection := database.GetMongoCollection(database.AccountTableName)
+	result, err := collection.InsertMany(context.TODO(), documents)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	ids := make([]string, len(result.InsertedIDs))
+	for i, id := range result.InsertedIDs {
+		ids[i] = id.(primitive.ObjectID).Hex()
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (AccountMongoDbMapper) UpdateAccountByEntity(plainId string, updatedEntity model.AccountEntity) model.AccountEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("account's id is not acceptable")
+		return model.AccountEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.AccountTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2AccountEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("account is not exist")
+		return model.AccountEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	rowsAffected := database.UpdateManyInMongoDB(filter, convertAccountEntity2BsonD(updatedEntity))
+	if rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "rows_affected", rowsAffected)
+		return model.AccountEntity{}
+	}
+	return updatedEntity
+}
+
+func (AccountMongoDbMapper) GetAllAccounts(limit, offset int) []model.AccountEntity {
+	// Empty filter to get all documents, with pagination
+	return findMongoDbAccounts(bson.D{}, limit, offset)
+}
+
+func (AccountMongoDbMapper) GetAccountsByOwnerId(ownerPlainId string, limit, offset int) []model.AccountEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+	return findMongoDbAccounts(filter, limit, offset)
+}
+
+func (AccountMongoDbMapper) CountAccountsByOwnerId(ownerPlainId string) int64 {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+
+	database.OpenMongoDbConnection(database.AccountTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+func (AccountMongoDbMapper) CountAllAccounts() int64 {
+	database.OpenMongoDbConnection(database.AccountTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(bson.D{})
+}
+
+func (AccountMongoDbMapper) DeleteAccountByObjectId(plainId string) model.AccountEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("account's id is not acceptable")
+		return model.AccountEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.AccountTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2AccountEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("account is not exist")
+		return model.AccountEntity{}
+	}
+
+	rowsAffected := database.DeleteManyInMongoDB(filter)
+	if rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "rows_affected", rowsAffected)
+		return model.AccountEntity{}
+	}
+	return targetEntity
+}
+
+func (AccountMongoDbMapper) DeleteAllAccounts() (int64, error) {
+	collection := database.GetMongoCollection(database.AccountTableName)
+	result, err := collection.DeleteMany(context.TODO(), bson.D{})
+	if err != nil {
+		util.Logger.Errorw("delete all accounts failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all accounts deleted", "count", result.DeletedCount)
+	return result.DeletedCount, nil
+}
+
+func (AccountMongoDbMapper) AssignUnownedAccounts(ownerPlainId string) (int64, error) {
+	collection := database.GetMongoCollection(database.AccountTableName)
+	result, err := collection.UpdateMany(context.TODO(),
+		bson.D{database.OwnerFilterInMongoDB("")},
+		bson.D{primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "owner_id", Value: util.ConvertOwner2ObjectId(ownerPlainId)},
+		}}})
+	if err != nil {
+		util.Logger.Errorw("assign unowned accounts failed", "error", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// findMongoDbAccounts returns a page of the matching accounts ordered by name
+func findMongoDbAccounts(filter bson.D, limit, offset int) []model.AccountEntity {
+	collection := database.GetMongoCollection(database.AccountTableName)
+
+	ctx := context.TODO()
+	findOptions := database.GetFindOptions()
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		findOptions.SetSkip(int64(offset))
+	}
+	// Sort by name ascending
+	findOptions.SetSort(bson.D{primitive.E{Key: "name", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		util.Logger.Errorw("query accounts failed", "error", err)
+		return []model.AccountEntity{}
+	}
+	defer cursor.Close(ctx)
+
+	var targetEntityList []model.AccountEntity
+	for cursor.Next(ctx) {
+		var bsonM bson.M
+		if err := cursor.Decode(&bsonM); err != nil {
+			util.Logger.Errorw("decode failed", "error", err)
+			continue
+		}
+		targetEntityList = append(targetEntityList, convertBsonM2AccountEntity(bsonM))
+	}
+	return targetEntityList
+}
+
+func convertAccountEntity2BsonD(entity model.AccountEntity) bson.D {
+	// 为空时自动生成新Id
+	if entity.Id == primitive.NilObjectID {
+		entity.Id = primitive.NewObjectID()
+	}
+
+	return bson.D{
+		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "owner_id", Value: entity.OwnerId},
+		primitive.E{Key: "name", Value: entity.Name},
+		primitive.E{Key: "type", Value: entity.Type},
+		primitive.E{Key: "opening_balance", Value: entity.OpeningBalance},
+		primitive.E{Key: "currency", Value: entity.Currency},
+		primitive.E{Key: "remark", Value: entity.Remark},
+		primitive.E{Key: "create_time", Value: entity.CreateTime},
+		primitive.E{Key: "modify_time", Value: entity.ModifyTime},
+	}
+}
+
+func convertBsonM2AccountEntity(bsonM bson.M) model.AccountEntity {
+	var newEntity model.AccountEntity
+	if err := database.DecodeBsonM(bsonM, &newEntity); err != nil {
+		panic(err)
+	}
+	return newEntity
+}
@@ -0,0 +1,342 @@
+package account_mapper
+
+import (
+	"bytes"
+	"database/sql"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AccountMySqlMapper struct{}
+
+const mySqlAccountColumns = "ID, OWNER_ID, NAME, TYPE, OPENING_BALANCE, CURRENCY, REMARK, CREATE_TIME, MODIFY_TIME"
+
+func (AccountMySqlMapper) GetAccountByObjectId(plainId string) model.AccountEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlAccountColumns + " FROM ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := queryMySqlAccounts(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.AccountEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (AccountMySqlMapper) GetAccountByName(ownerPlainId, accountName string) model.AccountEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlAccountColumns + " FROM ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND NAME = ? ")
+
+	targetEntityList := queryMySqlAccounts(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), accountName)
+	if len(targetEntityList) == 0 {
+		return model.AccountEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (AccountMySqlMapper) InsertAccountByEntity(newEntity model.AccountEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" (" + mySqlAccountColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := connection.Exec(sqlString.String(), newPlainId, newEntity.OwnerId.Hex(), newEntity.Name, newEntity.Type,
+		newEntity.OpeningBalance, newEntity.Currency, newEntity.Remark, newEntity.CreateTime, newEntity.ModifyTime)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (AccountMySqlMapper) BulkInsertAccounts(entities []model.AccountEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" (" + mySqlAccountColumns + ") VALUES ")
+
+	ids := make([]string, len(entities))
+	values := make([]interface{}, 0, len(entities)*9)
+
+	for i, entity := range entities {
+		if i > 0 {
+			sqlString.WriteString(", ")
+		}
+		sqlString.WriteString("(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		values = append(values, ids[i], entity.OwnerId.Hex(), entity.Name, entity.Type, entity.OpeningBalance, entity.Currency,
+			entity.Remark, entity.CreateTime, entity.ModifyTime)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), values...)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != int64(len(entities)) {
+		util.Logger.Errorw("bulk insert incomplete", "error", err, "expected", len(entities), "actual", rowsAffected)
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (AccountMySqlMapper) UpdateAccountByEntity(plainId string, updatedEntity model.AccountEntity) model.AccountEntity {
+	targetEntity := INSTANCE.GetAccountByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("account is not exist")
+		return model.AccountEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" SET NAME = ?, ")
+	sqlString.WriteString(" TYPE = ?, ")
+	sqlString.WriteString(" OPENING_BALANCE = ?, ")
+	sqlString.WriteString(" CURRENCY = ?, ")
+	sqlString.WriteString(" REMARK = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), updatedEntity.Name, updatedEntity.Type,
+		updatedEntity.OpeningBalance, updatedEntity.Currency, updatedEntity.Remark, updatedEntity.ModifyTime, plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.AccountEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (AccountMySqlMapper) GetAllAccounts(limit, offset int) []model.AccountEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlAccountColumns + " FROM ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlAccounts(sqlString.String(), limit, offset)
+	}
+	return queryMySqlAccounts(sqlString.String())
+}
+
+func (AccountMySqlMapper) GetAccountsByOwnerId(ownerPlainId string, limit, offset int) []model.AccountEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlAccountColumns + " FROM ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlAccounts(sqlString.String(), ownerId, limit, offset)
+	}
+	return queryMySqlAccounts(sqlString.String(), ownerId)
+}
+
+func (AccountMySqlMapper) CountAccountsByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	err := connection.QueryRow(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count accounts by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (AccountMySqlMapper) CountAllAccounts() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.AccountTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all accounts failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (AccountMySqlMapper) DeleteAccountByObjectId(plainId string) model.AccountEntity {
+	targetEntity := INSTANCE.GetAccountByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("account is not exist")
+		return model.AccountEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.AccountEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (AccountMySqlMapper) DeleteAllAccounts() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.AccountTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all accounts failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all accounts failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all accounts deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (AccountMySqlMapper) AssignUnownedAccounts(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned accounts failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func queryMySqlAccounts(sqlString string, args ...interface{}) []model.AccountEntity {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.AccountEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2AccountEntity(rows))
+	}
+	return targetEntityList
+}
+
+// generatePlainId keeps a preset id (e.g. restored from backup) and generates one otherwise
+func generatePlainId(objectId primitive.ObjectID) string {
+	if objectId == primitive.NilObjectID {
+		return primitive.NewObjectID().Hex()
+	}
+	return objectId.Hex()
+}
+
+func convertRow2AccountEntity(rows *sql.Rows) model.AccountEntity {
+	var id string
+	var ownerId string
+	var name string
+	var accountType string
+	var openingBalance decimal.Decimal
+	var currency sql.NullString
+	var remark sql.NullString
+	var createTime string
+	var modifyTime string
+
+	err := rows.Scan(&id, &ownerId, &name, &accountType, &openingBalance, &currency, &remark, &createTime, &modifyTime)
+	if err != nil {
+		util.Logger.Errorw("covert into entity failed", "error", err)
+	}
+
+	return model.AccountEntity{
+		Id:             util.Convert2ObjectId(id),
+		OwnerId:        util.Convert2ObjectId(ownerId),
+		Name:           name,
+		Type:           accountType,
+		OpeningBalance: openingBalance,
+		Currency:       currency.String,
+		Remark:         remark.String,
+		CreateTime:     util.FormatDateTimeFromString(createTime),
+		ModifyTime:     util.FormatDateTimeFromString(modifyTime),
+	}
+}
@@ -0,0 +1,298 @@
+package account_mapper
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AccountSqliteMapper struct{}
+
+const sqliteAccountColumns = "ID, OWNER_ID, NAME, TYPE, OPENING_BALANCE, CURRENCY, REMARK, CREATE_TIME, MODIFY_TIME"
+
+func (AccountSqliteMapper) GetAccountByObjectId(plainId string) model.AccountEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteAccountColumns + " FROM ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := querySqliteAccounts(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.AccountEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (AccountSqliteMapper) GetAccountByName(ownerPlainId, accountName string) model.AccountEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteAccountColumns + " FROM ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND NAME = ? ")
+
+	targetEntityList := querySqliteAccounts(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), accountName)
+	if len(targetEntityList) == 0 {
+		return model.AccountEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (AccountSqliteMapper) InsertAccountByEntity(newEntity model.AccountEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" (" + sqliteAccountColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) ")
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		convertAccountEntity2SqliteValues(newPlainId, newEntity)...)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (AccountSqliteMapper) BulkInsertAccounts(entities []model.AccountEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" (" + sqliteAccountColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) ")
+
+	// One transaction keeps the batch all-or-nothing and avoids a disk sync per row
+	transaction, err := database.GetSqliteConnection().Begin()
+	if err != nil {
+		util.Logger.Errorw("bulk insert begin failed", "error", err)
+		return nil, err
+	}
+
+	statement, err := transaction.Prepare(sqlString.String())
+	if err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert prepare failed", "error", err)
+		return nil, err
+	}
+	defer statement.Close()
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		if _, err := statement.Exec(convertAccountEntity2SqliteValues(ids[i], entity)...); err != nil {
+			transaction.Rollback()
+			util.Logger.Errorw("bulk insert failed", "error", err)
+			return nil, err
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		util.Logger.Errorw("bulk insert commit failed", "error", err)
+		return nil, err
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (AccountSqliteMapper) UpdateAccountByEntity(plainId string, updatedEntity model.AccountEntity) model.AccountEntity {
+	targetEntity := INSTANCE.GetAccountByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("account is not exist")
+		return model.AccountEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" SET NAME = ?, ")
+	sqlString.WriteString(" TYPE = ?, ")
+	sqlString.WriteString(" OPENING_BALANCE = ?, ")
+	sqlString.WriteString(" CURRENCY = ?, ")
+	sqlString.WriteString(" REMARK = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		updatedEntity.Name, updatedEntity.Type, updatedEntity.OpeningBalance, updatedEntity.Currency, updatedEntity.Remark,
+		util.FormatDateTimeToString(updatedEntity.ModifyTime), plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.AccountEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (AccountSqliteMapper) GetAllAccounts(limit, offset int) []model.AccountEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteAccountColumns + " FROM ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteAccounts(sqlString.String(), limit, offset)
+	}
+	return querySqliteAccounts(sqlString.String())
+}
+
+func (AccountSqliteMapper) GetAccountsByOwnerId(ownerPlainId string, limit, offset int) []model.AccountEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteAccountColumns + " FROM ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteAccounts(sqlString.String(), ownerId, limit, offset)
+	}
+	return querySqliteAccounts(sqlString.String(), ownerId)
+}
+
+func (AccountSqliteMapper) CountAccountsByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	var count int64
+	err := database.GetSqliteConnection().QueryRow(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count accounts by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (AccountSqliteMapper) CountAllAccounts() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.AccountTableName)
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all accounts failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (AccountSqliteMapper) DeleteAccountByObjectId(plainId string) model.AccountEntity {
+	targetEntity := INSTANCE.GetAccountByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("account is not exist")
+		return model.AccountEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.AccountEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (AccountSqliteMapper) DeleteAllAccounts() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.AccountTableName)
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all accounts failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all accounts failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all accounts deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (AccountSqliteMapper) AssignUnownedAccounts(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.AccountTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned accounts failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func querySqliteAccounts(sqlString string, args ...interface{}) []model.AccountEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.AccountEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2AccountEntity(rows))
+	}
+	return targetEntityList
+}
+
+// convertAccountEntity2SqliteValues lists the column values in sqliteAccountColumns order
+func convertAccountEntity2SqliteValues(plainId string, entity model.AccountEntity) []interface{} {
+	return []interface{}{
+		plainId,
+		entity.OwnerId.Hex(),
+		entity.Name,
+		entity.Type,
+		entity.OpeningBalance,
+		entity.Currency,
+		entity.Remark,
+		util.FormatDateTimeToString(entity.CreateTime),
+		util.FormatDateTimeToString(entity.ModifyTime),
+	}
+}
@@ -0,0 +1,122 @@
+package account_mapper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMain(m *testing.M) {
+	tempDir, err := os.MkdirTemp("", "cashlens-sqlite-")
+	if err != nil {
+		panic(err)
+	}
+	util.SetConfigByKey("db.sqlite.path", filepath.Join(tempDir, "account_test.db"))
+	INSTANCE = AccountSqliteMapper{}
+
+	code := m.Run()
+	os.RemoveAll(tempDir)
+	os.Exit(code)
+}
+
+func TestSqliteAccountLifecycle(t *testing.T) {
+	mapper := AccountSqliteMapper{}
+	if _, err := mapper.DeleteAllAccounts(); err != nil {
+		t.Fatalf("DeleteAllAccounts() error = %v", err)
+	}
+
+	bankId := mapper.InsertAccountByEntity(model.AccountEntity{
+		Name:           "Bank",
+		Type:           model.AccountTypeBank,
+		OpeningBalance: decimal.NewFromFloat(1000.5),
+	})
+	otherIds, err := mapper.BulkInsertAccounts([]model.AccountEntity{
+		{Name: "Wallet", Type: model.AccountTypeCash},
+		{Name: "Credit Card", Type: model.AccountTypeCard, OpeningBalance: decimal.NewFromInt(-20)},
+	})
+	if err != nil || len(otherIds) != 2 {
+		t.Fatalf("BulkInsertAccounts() = %v, %v", otherIds, err)
+	}
+
+	bank := mapper.GetAccountByObjectId(bankId)
+	if bank.Name != "Bank" || bank.Type != model.AccountTypeBank || !bank.OpeningBalance.Equal(decimal.NewFromFloat(1000.5)) {
+		t.Errorf("GetAccountByObjectId() = %+v", bank)
+	}
+	if account := mapper.GetAccountByName("", "Wallet"); account.Id.Hex() != otherIds[0] {
+		t.Errorf("GetAccountByName() = %+v, want id %s", account, otherIds[0])
+	}
+	if count := mapper.CountAllAccounts(); count != 3 {
+		t.Errorf("CountAllAccounts() = %d, want 3", count)
+	}
+
+	pageList := mapper.GetAllAccounts(2, 1)
+	if len(pageList) != 2 || pageList[0].Name != "Credit Card" {
+		t.Errorf("GetAllAccounts(2, 1) = %+v, want page sorted by name", pageList)
+	}
+
+	updated := mapper.UpdateAccountByEntity(otherIds[0], model.AccountEntity{
+		Name:           "Pocket",
+		Type:           model.AccountTypeCash,
+		OpeningBalance: decimal.NewFromInt(50),
+	})
+	if updated.Name != "Pocket" || !mapper.GetAccountByObjectId(otherIds[0]).OpeningBalance.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("UpdateAccountByEntity() did not update the account")
+	}
+
+	if deleted := mapper.DeleteAccountByObjectId(otherIds[1]); deleted.Name != "Credit Card" {
+		t.Errorf("DeleteAccountByObjectId() = %+v", deleted)
+	}
+
+	deletedCount, err := mapper.DeleteAllAccounts()
+	if err != nil || deletedCount != 2 {
+		t.Errorf("DeleteAllAccounts() = %d, %v, want 2, nil", deletedCount, err)
+	}
+}
+
+func TestSqliteAccountOwners(t *testing.T) {
+	mapper := AccountSqliteMapper{}
+	if _, err := mapper.DeleteAllAccounts(); err != nil {
+		t.Fatalf("DeleteAllAccounts() error = %v", err)
+	}
+
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	unownedId := mapper.InsertAccountByEntity(model.AccountEntity{Name: "Savings", Type: model.AccountTypeBank})
+	bobId := mapper.InsertAccountByEntity(model.AccountEntity{OwnerId: bob, Name: "Savings", Type: model.AccountTypeBank})
+
+	// Owners look names up in their own accounts only
+	if account := mapper.GetAccountByName(bob.Hex(), "Savings"); account.Id.Hex() != bobId {
+		t.Errorf("GetAccountByName(bob) = %+v, want id %s", account, bobId)
+	}
+	if account := mapper.GetAccountByName(alice.Hex(), "Savings"); !account.IsEmpty() {
+		t.Errorf("GetAccountByName(alice) = %+v, want none", account)
+	}
+
+	// An update keeps the owner
+	bobAccount := mapper.GetAccountByObjectId(bobId)
+	bobAccount.OwnerId = primitive.NilObjectID
+	mapper.UpdateAccountByEntity(bobId, bobAccount)
+	if owner := mapper.GetAccountByObjectId(bobId).OwnerId; owner != bob {
+		t.Errorf("UpdateAccountByEntity() changed the owner to %s", owner.Hex())
+	}
+
+	// Only the accounts without owner are handed over
+	assignedCount, err := mapper.AssignUnownedAccounts(alice.Hex())
+	if err != nil || assignedCount != 1 {
+		t.Fatalf("AssignUnownedAccounts() = %d, %v, want 1, nil", assignedCount, err)
+	}
+	aliceList := mapper.GetAccountsByOwnerId(alice.Hex(), 0, 0)
+	if len(aliceList) != 1 || aliceList[0].Id.Hex() != unownedId {
+		t.Errorf("GetAccountsByOwnerId(alice) = %+v, want the unowned account", aliceList)
+	}
+	if bobList := mapper.GetAccountsByOwnerId(bob.Hex(), 0, 0); len(bobList) != 1 || bobList[0].Id.Hex() != bobId {
+		t.Errorf("GetAccountsByOwnerId(bob) = %+v, want bob's account", bobList)
+	}
+	if count := mapper.CountAccountsByOwnerId(bob.Hex()); count != 1 {
+		t.Errorf("CountAccountsByOwnerId(bob) = %d, want 1", count)
+	}
+}
@@ -0,0 +1,48 @@
+package api_token_mapper
+
+import (
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+)
+
+var INSTANCE ApiTokenMapper
+
+type ApiTokenMapper interface {
+	GetApiTokenByObjectId(plainId string) model.ApiTokenEntity
+	GetApiTokenByHash(tokenHash string) model.ApiTokenEntity
+	GetApiTokensByUserId(userPlainId string) []model.ApiTokenEntity
+	InsertApiTokenByEntity(newEntity model.ApiTokenEntity) string
+	BulkInsertApiTokens(entities []model.ApiTokenEntity) ([]string, error)
+	GetAllApiTokens(limit, offset int) []model.ApiTokenEntity
+	CountAllApiTokens() int64
+	DeleteApiTokenByObjectId(plainId string) model.ApiTokenEntity
+	DeleteAllApiTokens() (int64, error)
+}
+
+func init() {
+	switch util.GetConfigByKey("db.type") {
+	case "mongodb":
+		INSTANCE = ApiTokenMongoDbMapper{}
+	case "mysql":
+		INSTANCE = ApiTokenMySqlMapper{}
+	case "sqlite":
+		INSTANCE = ApiTokenSqliteMapper{}
+	case "memory":
+		INSTANCE = NewApiTokenMemoryMapper()
+	default:
+		panic("database type not supported")
+	}
+}
+
+// fillOperatingTime keeps create/modify times already set, and stamps the operating time on brand-new records.
+func fillOperatingTime(entity model.ApiTokenEntity, operatingTime time.Time) model.ApiTokenEntity {
+	if entity.CreateTime.IsZero() {
+		entity.CreateTime = operatingTime
+	}
+	if entity.ModifyTime.IsZero() {
+		entity.ModifyTime = operatingTime
+	}
+	return entity
+}
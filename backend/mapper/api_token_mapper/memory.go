@@ -0,0 +1,176 @@
+package api_token_mapper
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ApiTokenMemoryMapper keeps API tokens in process memory, nothing is persisted.
+// It is safe for concurrent use; copies of the mapper share the same data.
+type ApiTokenMemoryMapper struct {
+	store *apiTokenMemoryStore
+}
+
+type apiTokenMemoryStore struct {
+	mutex   sync.RWMutex
+	records map[primitive.ObjectID]model.ApiTokenEntity
+}
+
+// NewApiTokenMemoryMapper returns an empty in-memory mapper
+func NewApiTokenMemoryMapper() ApiTokenMemoryMapper {
+	return ApiTokenMemoryMapper{
+		store: &apiTokenMemoryStore{
+			records: make(map[primitive.ObjectID]model.ApiTokenEntity),
+		},
+	}
+}
+
+func (mapper ApiTokenMemoryMapper) GetApiTokenByObjectId(plainId string) model.ApiTokenEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("api token's id is not acceptable")
+		return model.ApiTokenEntity{}
+	}
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return mapper.store.records[objectId]
+}
+
+func (mapper ApiTokenMemoryMapper) GetApiTokenByHash(tokenHash string) model.ApiTokenEntity {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	for _, entity := range mapper.store.records {
+		if entity.TokenHash == tokenHash {
+			return entity
+		}
+	}
+	return model.ApiTokenEntity{}
+}
+
+func (mapper ApiTokenMemoryMapper) GetApiTokensByUserId(userPlainId string) []model.ApiTokenEntity {
+	userId := util.Convert2ObjectId(userPlainId)
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	var targetEntityList []model.ApiTokenEntity
+	for _, entity := range mapper.store.records {
+		if entity.UserId == userId {
+			targetEntityList = append(targetEntityList, entity)
+		}
+	}
+	sortApiTokens(targetEntityList)
+	return targetEntityList
+}
+
+func (mapper ApiTokenMemoryMapper) InsertApiTokenByEntity(newEntity model.ApiTokenEntity) string {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	newEntity = fillOperatingTime(newEntity, time.Now())
+	if newEntity.Id == primitive.NilObjectID {
+		newEntity.Id = primitive.NewObjectID()
+	}
+	if _, isExist := mapper.store.records[newEntity.Id]; isExist {
+		util.Logger.Errorw("insert failed", "error", "duplicate api token id: "+newEntity.Id.Hex())
+		return ""
+	}
+	mapper.store.records[newEntity.Id] = newEntity
+	return newEntity.Id.Hex()
+}
+
+func (mapper ApiTokenMemoryMapper) BulkInsertApiTokens(entities []model.ApiTokenEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	operatingTime := time.Now()
+	newEntityList := make([]model.ApiTokenEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.records[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate api token id: " + entity.Id.Hex())
+		}
+		batchIds[entity.Id] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.records[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper ApiTokenMemoryMapper) GetAllApiTokens(limit, offset int) []model.ApiTokenEntity {
+	mapper.store.mutex.RLock()
+	targetEntityList := make([]model.ApiTokenEntity, 0, len(mapper.store.records))
+	for _, entity := range mapper.store.records {
+		targetEntityList = append(targetEntityList, entity)
+	}
+	mapper.store.mutex.RUnlock()
+
+	sortApiTokens(targetEntityList)
+	if offset >= len(targetEntityList) {
+		return []model.ApiTokenEntity{}
+	}
+	targetEntityList = targetEntityList[offset:]
+	if limit > 0 && limit < len(targetEntityList) {
+		targetEntityList = targetEntityList[:limit]
+	}
+	return targetEntityList
+}
+
+func (mapper ApiTokenMemoryMapper) CountAllApiTokens() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.records))
+}
+
+func (mapper ApiTokenMemoryMapper) DeleteApiTokenByObjectId(plainId string) model.ApiTokenEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("api token is not exist")
+		return model.ApiTokenEntity{}
+	}
+
+	delete(mapper.store.records, objectId)
+	return targetEntity
+}
+
+func (mapper ApiTokenMemoryMapper) DeleteAllApiTokens() (int64, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	deletedCount := int64(len(mapper.store.records))
+	mapper.store.records = make(map[primitive.ObjectID]model.ApiTokenEntity)
+	return deletedCount, nil
+}
+
+// sortApiTokens puts the oldest first, like the database mappers
+func sortApiTokens(entityList []model.ApiTokenEntity) {
+	sort.Slice(entityList, func(i, j int) bool {
+		if !entityList[i].CreateTime.Equal(entityList[j].CreateTime) {
+			return entityList[i].CreateTime.Before(entityList[j].CreateTime)
+		}
+		return entityList[i].Id.Hex() < entityList[j].Id.Hex()
+	})
+}
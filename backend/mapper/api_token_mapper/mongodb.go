@@ -0,0 +1,203 @@
+package api_token_mapper
+
+import (
+	"context"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ApiTokenMongoDbMapper struct{}
+
+func (ApiTokenMongoDbMapper) GetApiTokenByObjectId(plainId string) model.ApiTokenEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("api token's id is not acceptable")
+		return model.ApiTokenEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.ApiTokenTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2ApiTokenEntity(database.GetOneInMongoDB(filter))
+}
+
+func (ApiTokenMongoDbMapper) GetApiTokenByHash(tokenHash string) model.ApiTokenEntity {
+	filter := bson.D{
+		primitive.E{Key: "token_hash", Value: tokenHash},
+	}
+
+	database.OpenMongoDbConnection(database.ApiTokenTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2ApiTokenEntity(database.GetOneInMongoDB(filter))
+}
+
+func (ApiTokenMongoDbMapper) GetApiTokensByUserId(userPlainId string) []model.ApiTokenEntity {
+	filter := bson.D{
+		primitive.E{Key: "user_id", Value: util.Convert2ObjectId(userPlainId)},
+	}
+	findOptions := database.GetFindOptions()
+	// Oldest first
+	findOptions.SetSort(bson.D{
+		primitive.E{Key: "create_time", Value: 1},
+		primitive.E{Key: "_id", Value: 1},
+	})
+
+	return findMongoApiTokens(filter, findOptions)
+}
+
+func (ApiTokenMongoDbMapper) InsertApiTokenByEntity(newEntity model.ApiTokenEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	database.OpenMongoDbConnection(database.ApiTokenTableName)
+	defer database.CloseMongoDbConnection()
+
+	newApiTokenId := database.InsertOneInMongoDB(convertApiTokenEntity2BsonD(newEntity))
+	return newApiTokenId.Hex()
+}
+
+func (ApiTokenMongoDbMapper) BulkInsertApiTokens(entities []model.ApiTokenEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		documents[i] = convertApiTokenEntity2BsonD(fillOperatingTime(entity, operatingTime))
+	}
+
+	collection := database.GetMongoCollection(database.ApiTokenTableName)
+	result, err := collection.InsertMany(context.TODO(), documents)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	ids := make([]string, len(result.InsertedIDs))
+	for i, id := range result.InsertedIDs {
+		ids[i] = id.(primitive.ObjectID).Hex()
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (ApiTokenMongoDbMapper) GetAllApiTokens(limit, offset int) []model.ApiTokenEntity {
+	findOptions := database.GetFindOptions()
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		findOptions.SetSkip(int64(offset))
+	}
+	// Oldest first
+	findOptions.SetSort(bson.D{
+		primitive.E{Key: "create_time", Value: 1},
+		primitive.E{Key: "_id", Value: 1},
+	})
+
+	return findMongoApiTokens(bson.D{}, findOptions)
+}
+
+func (ApiTokenMongoDbMapper) CountAllApiTokens() int64 {
+	database.OpenMongoDbConnection(database.ApiTokenTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(bson.D{})
+}
+
+func (ApiTokenMongoDbMapper) DeleteApiTokenByObjectId(plainId string) model.ApiTokenEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("api token's id is not acceptable")
+		return model.ApiTokenEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.ApiTokenTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2ApiTokenEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("api token is not exist")
+		return model.ApiTokenEntity{}
+	}
+
+	rowsAffected := database.DeleteManyInMongoDB(filter)
+	if rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "rows_affected", rowsAffected)
+		return model.ApiTokenEntity{}
+	}
+	return targetEntity
+}
+
+func (ApiTokenMongoDbMapper) DeleteAllApiTokens() (int64, error) {
+	collection := database.GetMongoCollection(database.ApiTokenTableName)
+	result, err := collection.DeleteMany(context.TODO(), bson.D{})
+	if err != nil {
+		util.Logger.Errorw("delete all api tokens failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all api tokens deleted", "count", result.DeletedCount)
+	return result.DeletedCount, nil
+}
+
+func findMongoApiTokens(filter bson.D, findOptions *options.FindOptions) []model.ApiTokenEntity {
+	collection := database.GetMongoCollection(database.ApiTokenTableName)
+
+	ctx := context.TODO()
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		util.Logger.Errorw("query api tokens failed", "error", err)
+		return []model.ApiTokenEntity{}
+	}
+	defer cursor.Close(ctx)
+
+	var targetEntityList []model.ApiTokenEntity
+	for cursor.Next(ctx) {
+		var bsonM bson.M
+		if err := cursor.Decode(&bsonM); err != nil {
+			util.Logger.Errorw("decode failed", "error", err)
+			continue
+		}
+		targetEntityList = append(targetEntityList, convertBsonM2ApiTokenEntity(bsonM))
+	}
+	return targetEntityList
+}
+
+func convertApiTokenEntity2BsonD(entity model.ApiTokenEntity) bson.D {
+	// 为空时自动生成新Id
+	if entity.Id == primitive.NilObjectID {
+		entity.Id = primitive.NewObjectID()
+	}
+
+	return bson.D{
+		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "user_id", Value: entity.UserId},
+		primitive.E{Key: "name", Value: entity.Name},
+		primitive.E{Key: "token_hash", Value: entity.TokenHash},
+		primitive.E{Key: "create_time", Value: entity.CreateTime},
+		primitive.E{Key: "modify_time", Value: entity.ModifyTime},
+	}
+}
+
+func convertBsonM2ApiTokenEntity(bsonM bson.M) model.ApiTokenEntity {
+	var newEntity model.ApiTokenEntity
+	if err := database.DecodeBsonM(bsonM, &newEntity); err != nil {
+		panic(err)
+	}
+	return newEntity
+}
@@ -0,0 +1,252 @@
+package api_token_mapper
+
+import (
+	"bytes"
+	"database/sql"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ApiTokenMySqlMapper struct{}
+
+const mySqlApiTokenColumns = "ID, USER_ID, NAME, TOKEN_HASH, CREATE_TIME, MODIFY_TIME"
+
+func (ApiTokenMySqlMapper) GetApiTokenByObjectId(plainId string) model.ApiTokenEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlApiTokenColumns + " FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := queryMySqlApiTokens(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.ApiTokenEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (ApiTokenMySqlMapper) GetApiTokenByHash(tokenHash string) model.ApiTokenEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlApiTokenColumns + " FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" WHERE TOKEN_HASH = ? ")
+
+	targetEntityList := queryMySqlApiTokens(sqlString.String(), tokenHash)
+	if len(targetEntityList) == 0 {
+		return model.ApiTokenEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (ApiTokenMySqlMapper) GetApiTokensByUserId(userPlainId string) []model.ApiTokenEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlApiTokenColumns + " FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" WHERE USER_ID = ? ")
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	return queryMySqlApiTokens(sqlString.String(), userPlainId)
+}
+
+func (ApiTokenMySqlMapper) InsertApiTokenByEntity(newEntity model.ApiTokenEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" (" + mySqlApiTokenColumns + ") VALUES (?, ?, ?, ?, ?, ?)")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := connection.Exec(sqlString.String(), newPlainId, newEntity.UserId.Hex(), newEntity.Name,
+		newEntity.TokenHash, newEntity.CreateTime, newEntity.ModifyTime)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (ApiTokenMySqlMapper) BulkInsertApiTokens(entities []model.ApiTokenEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" (" + mySqlApiTokenColumns + ") VALUES ")
+
+	ids := make([]string, len(entities))
+	values := make([]interface{}, 0, len(entities)*6)
+
+	for i, entity := range entities {
+		if i > 0 {
+			sqlString.WriteString(", ")
+		}
+		sqlString.WriteString("(?, ?, ?, ?, ?, ?)")
+
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		values = append(values, ids[i], entity.UserId.Hex(), entity.Name, entity.TokenHash,
+			entity.CreateTime, entity.ModifyTime)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), values...)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != int64(len(entities)) {
+		util.Logger.Errorw("bulk insert incomplete", "error", err, "expected", len(entities), "actual", rowsAffected)
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (ApiTokenMySqlMapper) GetAllApiTokens(limit, offset int) []model.ApiTokenEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlApiTokenColumns + " FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlApiTokens(sqlString.String(), limit, offset)
+	}
+	return queryMySqlApiTokens(sqlString.String())
+}
+
+func (ApiTokenMySqlMapper) CountAllApiTokens() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all api tokens failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (ApiTokenMySqlMapper) DeleteApiTokenByObjectId(plainId string) model.ApiTokenEntity {
+	targetEntity := INSTANCE.GetApiTokenByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("api token is not exist")
+		return model.ApiTokenEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.ApiTokenEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (ApiTokenMySqlMapper) DeleteAllApiTokens() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all api tokens failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all api tokens failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all api tokens deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func queryMySqlApiTokens(sqlString string, args ...interface{}) []model.ApiTokenEntity {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.ApiTokenEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2ApiTokenEntity(rows))
+	}
+	return targetEntityList
+}
+
+// generatePlainId keeps a preset id and generates one otherwise
+func generatePlainId(objectId primitive.ObjectID) string {
+	if objectId == primitive.NilObjectID {
+		return primitive.NewObjectID().Hex()
+	}
+	return objectId.Hex()
+}
+
+func convertRow2ApiTokenEntity(rows *sql.Rows) model.ApiTokenEntity {
+	var id string
+	var userId string
+	var name string
+	var tokenHash string
+	var createTime string
+	var modifyTime string
+
+	err := rows.Scan(&id, &userId, &name, &tokenHash, &createTime, &modifyTime)
+	if err != nil {
+		util.Logger.Errorw("covert into entity failed", "error", err)
+	}
+
+	return model.ApiTokenEntity{
+		Id:         util.Convert2ObjectId(id),
+		UserId:     util.Convert2ObjectId(userId),
+		Name:       name,
+		TokenHash:  tokenHash,
+		CreateTime: util.FormatDateTimeFromString(createTime),
+		ModifyTime: util.FormatDateTimeFromString(modifyTime),
+	}
+}
@@ -0,0 +1,208 @@
+package api_token_mapper
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+)
+
+type ApiTokenSqliteMapper struct{}
+
+const sqliteApiTokenColumns = "ID, USER_ID, NAME, TOKEN_HASH, CREATE_TIME, MODIFY_TIME"
+
+func (ApiTokenSqliteMapper) GetApiTokenByObjectId(plainId string) model.ApiTokenEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteApiTokenColumns + " FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := querySqliteApiTokens(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.ApiTokenEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (ApiTokenSqliteMapper) GetApiTokenByHash(tokenHash string) model.ApiTokenEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteApiTokenColumns + " FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" WHERE TOKEN_HASH = ? ")
+
+	targetEntityList := querySqliteApiTokens(sqlString.String(), tokenHash)
+	if len(targetEntityList) == 0 {
+		return model.ApiTokenEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (ApiTokenSqliteMapper) GetApiTokensByUserId(userPlainId string) []model.ApiTokenEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteApiTokenColumns + " FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" WHERE USER_ID = ? ")
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	return querySqliteApiTokens(sqlString.String(), userPlainId)
+}
+
+func (ApiTokenSqliteMapper) InsertApiTokenByEntity(newEntity model.ApiTokenEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" (" + sqliteApiTokenColumns + ") VALUES (?, ?, ?, ?, ?, ?) ")
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		newPlainId, newEntity.UserId.Hex(), newEntity.Name, newEntity.TokenHash,
+		util.FormatDateTimeToString(newEntity.CreateTime), util.FormatDateTimeToString(newEntity.ModifyTime))
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (ApiTokenSqliteMapper) BulkInsertApiTokens(entities []model.ApiTokenEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" (" + sqliteApiTokenColumns + ") VALUES (?, ?, ?, ?, ?, ?) ")
+
+	// One transaction keeps the batch all-or-nothing
+	transaction, err := database.GetSqliteConnection().Begin()
+	if err != nil {
+		util.Logger.Errorw("bulk insert begin failed", "error", err)
+		return nil, err
+	}
+
+	statement, err := transaction.Prepare(sqlString.String())
+	if err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert prepare failed", "error", err)
+		return nil, err
+	}
+	defer statement.Close()
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		if _, err := statement.Exec(ids[i], entity.UserId.Hex(), entity.Name, entity.TokenHash,
+			util.FormatDateTimeToString(entity.CreateTime), util.FormatDateTimeToString(entity.ModifyTime)); err != nil {
+			transaction.Rollback()
+			util.Logger.Errorw("bulk insert failed", "error", err)
+			return nil, err
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		util.Logger.Errorw("bulk insert commit failed", "error", err)
+		return nil, err
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (ApiTokenSqliteMapper) GetAllApiTokens(limit, offset int) []model.ApiTokenEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteApiTokenColumns + " FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteApiTokens(sqlString.String(), limit, offset)
+	}
+	return querySqliteApiTokens(sqlString.String())
+}
+
+func (ApiTokenSqliteMapper) CountAllApiTokens() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all api tokens failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (ApiTokenSqliteMapper) DeleteApiTokenByObjectId(plainId string) model.ApiTokenEntity {
+	targetEntity := INSTANCE.GetApiTokenByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("api token is not exist")
+		return model.ApiTokenEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.ApiTokenEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (ApiTokenSqliteMapper) DeleteAllApiTokens() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.ApiTokenTableName)
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all api tokens failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all api tokens failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all api tokens deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func querySqliteApiTokens(sqlString string, args ...interface{}) []model.ApiTokenEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.ApiTokenEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2ApiTokenEntity(rows))
+	}
+	return targetEntityList
+}
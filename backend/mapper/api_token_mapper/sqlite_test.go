@@ -0,0 +1,100 @@
+package api_token_mapper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMain(m *testing.M) {
+	tempDir, err := os.MkdirTemp("", "cashlens-sqlite-")
+	if err != nil {
+		panic(err)
+	}
+	util.SetConfigByKey("db.sqlite.path", filepath.Join(tempDir, "api_token_test.db"))
+	INSTANCE = ApiTokenSqliteMapper{}
+
+	code := m.Run()
+	os.RemoveAll(tempDir)
+	os.Exit(code)
+}
+
+func TestSqliteApiTokenLifecycle(t *testing.T) {
+	mapper := ApiTokenSqliteMapper{}
+	if _, err := mapper.DeleteAllApiTokens(); err != nil {
+		t.Fatalf("DeleteAllApiTokens() error = %v", err)
+	}
+
+	aliceId := primitive.NewObjectID()
+	createTime := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	laterId := mapper.InsertApiTokenByEntity(model.ApiTokenEntity{UserId: aliceId, Name: "backup script",
+		TokenHash: "hash-2", CreateTime: createTime.Add(time.Hour)})
+	earlierId := mapper.InsertApiTokenByEntity(model.ApiTokenEntity{UserId: aliceId, Name: "importer",
+		TokenHash: "hash-1", CreateTime: createTime})
+	mapper.InsertApiTokenByEntity(model.ApiTokenEntity{UserId: primitive.NewObjectID(), Name: "other", TokenHash: "hash-3"})
+	if duplicateId := mapper.InsertApiTokenByEntity(model.ApiTokenEntity{UserId: aliceId, Name: "copy", TokenHash: "hash-1"}); duplicateId != "" {
+		t.Errorf("InsertApiTokenByEntity() with a taken hash = %q, want no id", duplicateId)
+	}
+
+	if token := mapper.GetApiTokenByHash("hash-2"); token.Id.Hex() != laterId || token.UserId != aliceId || token.Name != "backup script" {
+		t.Errorf("GetApiTokenByHash() = %+v", token)
+	}
+	if token := mapper.GetApiTokenByHash("unknown"); !token.IsEmpty() {
+		t.Errorf("GetApiTokenByHash() of an unknown hash = %+v, want empty", token)
+	}
+	tokenList := mapper.GetApiTokensByUserId(aliceId.Hex())
+	if len(tokenList) != 2 || tokenList[0].Id.Hex() != earlierId || tokenList[1].Id.Hex() != laterId {
+		t.Errorf("GetApiTokensByUserId() = %+v, want the two tokens oldest first", tokenList)
+	}
+
+	if deleted := mapper.DeleteApiTokenByObjectId(earlierId); deleted.Name != "importer" {
+		t.Errorf("DeleteApiTokenByObjectId() = %+v", deleted)
+	}
+	if token := mapper.GetApiTokenByHash("hash-1"); !token.IsEmpty() {
+		t.Errorf("GetApiTokenByHash() of a deleted token = %+v, want empty", token)
+	}
+
+	deletedCount, err := mapper.DeleteAllApiTokens()
+	if err != nil || deletedCount != 2 {
+		t.Errorf("DeleteAllApiTokens() = %d, %v, want 2, nil", deletedCount, err)
+	}
+}
+
+func TestSqliteApiTokenBulkInsert(t *testing.T) {
+	mapper := ApiTokenSqliteMapper{}
+	if _, err := mapper.DeleteAllApiTokens(); err != nil {
+		t.Fatalf("DeleteAllApiTokens() error = %v", err)
+	}
+
+	aliceId := primitive.NewObjectID()
+	tokenId := primitive.NewObjectID()
+	createTime := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	ids, err := mapper.BulkInsertApiTokens([]model.ApiTokenEntity{
+		{Id: tokenId, UserId: aliceId, Name: "importer", TokenHash: "hash-1", CreateTime: createTime.Add(time.Hour)},
+		{UserId: aliceId, Name: "backup script", TokenHash: "hash-2", CreateTime: createTime},
+	})
+	if err != nil || len(ids) != 2 || ids[0] != tokenId.Hex() {
+		t.Fatalf("BulkInsertApiTokens() = %v, %v, want the preset id kept", ids, err)
+	}
+	if _, err := mapper.BulkInsertApiTokens([]model.ApiTokenEntity{
+		{UserId: aliceId, Name: "new", TokenHash: "hash-3"},
+		{UserId: aliceId, Name: "copy", TokenHash: "hash-1"},
+	}); err == nil {
+		t.Errorf("BulkInsertApiTokens() with a taken hash should fail")
+	}
+	if token := mapper.GetApiTokenByHash("hash-3"); !token.IsEmpty() {
+		t.Errorf("a failed BulkInsertApiTokens() left %+v behind", token)
+	}
+
+	if count := mapper.CountAllApiTokens(); count != 2 {
+		t.Errorf("CountAllApiTokens() = %d, want 2", count)
+	}
+	if pageList := mapper.GetAllApiTokens(1, 1); len(pageList) != 1 || pageList[0].Id != tokenId {
+		t.Errorf("GetAllApiTokens(1, 1) = %+v, want the newer token", pageList)
+	}
+}
@@ -0,0 +1,52 @@
+package budget_mapper
+
+import (
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+)
+
+var INSTANCE BudgetMapper
+
+type BudgetMapper interface {
+	GetBudgetByObjectId(plainId string) model.BudgetEntity
+	GetBudgetByCategoryIdAndPeriod(categoryPlainId, period string) model.BudgetEntity
+	InsertBudgetByEntity(newEntity model.BudgetEntity) string
+	BulkInsertBudgets(entities []model.BudgetEntity) ([]string, error)
+	UpdateBudgetByEntity(plainId string, updatedEntity model.BudgetEntity) model.BudgetEntity
+	GetAllBudgets(limit, offset int) []model.BudgetEntity
+	GetBudgetsByOwnerId(ownerPlainId string, limit, offset int) []model.BudgetEntity
+	CountAllBudgets() int64
+	CountBudgetsByCategoryId(categoryPlainId string) int64
+	DeleteBudgetByObjectId(plainId string) model.BudgetEntity
+	DeleteAllBudgets() (int64, error)
+	AssignUnownedBudgets(ownerPlainId string) (int64, error)
+}
+
+func init() {
+	switch util.GetConfigByKey("db.type") {
+	case "mongodb":
+		INSTANCE = BudgetMongoDbMapper{}
+	case "mysql":
+		INSTANCE = BudgetMySqlMapper{}
+	case "sqlite":
+		INSTANCE = BudgetSqliteMapper{}
+	case "memory":
+		INSTANCE = NewBudgetMemoryMapper()
+	default:
+		panic("database type not supported")
+	}
+}
+
+// fillOperatingTime keeps create/modify times carried over from a backup,
+// and stamps the operating time on brand-new records.
+func fillOperatingTime(entity model.BudgetEntity, operatingTime time.Time) model.BudgetEntity {
+	if entity.CreateTime.IsZero() {
+		entity.CreateTime = operatingTime
+	}
+	if entity.ModifyTime.IsZero() {
+		entity.ModifyTime = operatingTime
+	}
+	return entity
+}
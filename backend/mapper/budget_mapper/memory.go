@@ -0,0 +1,214 @@
+package budget_mapper
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BudgetMemoryMapper keeps budgets in process memory, nothing is persisted.
+// It is safe for concurrent use; copies of the mapper share the same data.
+type BudgetMemoryMapper struct {
+	store *budgetMemoryStore
+}
+
+type budgetMemoryStore struct {
+	mutex   sync.RWMutex
+	records map[primitive.ObjectID]model.BudgetEntity
+}
+
+// NewBudgetMemoryMapper returns an empty in-memory mapper
+func NewBudgetMemoryMapper() BudgetMemoryMapper {
+	return BudgetMemoryMapper{
+		store: &budgetMemoryStore{
+			records: make(map[primitive.ObjectID]model.BudgetEntity),
+		},
+	}
+}
+
+func (mapper BudgetMemoryMapper) GetBudgetByObjectId(plainId string) model.BudgetEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("budget's id is not acceptable")
+		return model.BudgetEntity{}
+	}
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return mapper.store.records[objectId]
+}
+
+func (mapper BudgetMemoryMapper) GetBudgetByCategoryIdAndPeriod(categoryPlainId, period string) model.BudgetEntity {
+	categoryId := util.Convert2ObjectId(categoryPlainId)
+	targetEntityList := mapper.filter(func(entity model.BudgetEntity) bool {
+		return entity.CategoryId == categoryId && entity.Period == period
+	})
+	if len(targetEntityList) == 0 {
+		return model.BudgetEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (mapper BudgetMemoryMapper) InsertBudgetByEntity(newEntity model.BudgetEntity) string {
+	newPlainIdList, err := mapper.BulkInsertBudgets([]model.BudgetEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+	return newPlainIdList[0]
+}
+
+func (mapper BudgetMemoryMapper) BulkInsertBudgets(entities []model.BudgetEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	operatingTime := time.Now()
+	newEntityList := make([]model.BudgetEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.records[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate budget id: " + entity.Id.Hex())
+		}
+		batchIds[entity.Id] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.records[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper BudgetMemoryMapper) UpdateBudgetByEntity(plainId string, updatedEntity model.BudgetEntity) model.BudgetEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("budget is not exist")
+		return model.BudgetEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+	mapper.store.records[objectId] = updatedEntity
+	return updatedEntity
+}
+
+func (mapper BudgetMemoryMapper) GetAllBudgets(limit, offset int) []model.BudgetEntity {
+	targetEntityList := mapper.filter(func(model.BudgetEntity) bool { return true })
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper BudgetMemoryMapper) GetBudgetsByOwnerId(ownerPlainId string, limit, offset int) []model.BudgetEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.BudgetEntity) bool {
+		return entity.OwnerId == ownerId
+	})
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper BudgetMemoryMapper) CountAllBudgets() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.records))
+}
+
+func (mapper BudgetMemoryMapper) CountBudgetsByCategoryId(categoryPlainId string) int64 {
+	categoryId := util.Convert2ObjectId(categoryPlainId)
+	return int64(len(mapper.filter(func(entity model.BudgetEntity) bool {
+		return entity.CategoryId == categoryId
+	})))
+}
+
+func (mapper BudgetMemoryMapper) DeleteBudgetByObjectId(plainId string) model.BudgetEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("budget is not exist")
+		return model.BudgetEntity{}
+	}
+
+	delete(mapper.store.records, objectId)
+	return targetEntity
+}
+
+func (mapper BudgetMemoryMapper) DeleteAllBudgets() (int64, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	deletedCount := int64(len(mapper.store.records))
+	mapper.store.records = make(map[primitive.ObjectID]model.BudgetEntity)
+	return deletedCount, nil
+}
+
+func (mapper BudgetMemoryMapper) AssignUnownedBudgets(ownerPlainId string) (int64, error) {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	var assignedCount int64
+	for id, entity := range mapper.store.records {
+		if entity.OwnerId == primitive.NilObjectID {
+			entity.OwnerId = ownerId
+			mapper.store.records[id] = entity
+			assignedCount++
+		}
+	}
+	return assignedCount, nil
+}
+
+// paginate cuts one page out of the ordered budgets, a zero limit keeps all of them
+func paginate(targetEntityList []model.BudgetEntity, limit, offset int) []model.BudgetEntity {
+	if offset >= len(targetEntityList) {
+		return []model.BudgetEntity{}
+	}
+	targetEntityList = targetEntityList[offset:]
+	if limit > 0 && limit < len(targetEntityList) {
+		targetEntityList = targetEntityList[:limit]
+	}
+	return targetEntityList
+}
+
+// filter returns the matching budgets ordered by category and period, like the database mappers
+func (mapper BudgetMemoryMapper) filter(isMatched func(entity model.BudgetEntity) bool) []model.BudgetEntity {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	var targetEntityList []model.BudgetEntity
+	for _, entity := range mapper.store.records {
+		if isMatched(entity) {
+			targetEntityList = append(targetEntityList, entity)
+		}
+	}
+
+	sort.Slice(targetEntityList, func(i, j int) bool {
+		if targetEntityList[i].CategoryId != targetEntityList[j].CategoryId {
+			return targetEntityList[i].CategoryId.Hex() < targetEntityList[j].CategoryId.Hex()
+		}
+		return targetEntityList[i].Period < targetEntityList[j].Period
+	})
+	return targetEntityList
+}
@@ -0,0 +1,264 @@
+package budget_mapper
+
+import (
+	"context"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type BudgetMongoDbMapper struct{}
+
+func (BudgetMongoDbMapper) GetBudgetByObjectId(plainId string) model.BudgetEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("budget's id is not acceptable")
+		return model.BudgetEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.BudgetTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2BudgetEntity(database.GetOneInMongoDB(filter))
+}
+
+func (BudgetMongoDbMapper) GetBudgetByCategoryIdAndPeriod(categoryPlainId, period string) model.BudgetEntity {
+	filter := bson.D{
+		primitive.E{Key: "category_id", Value: util.Convert2ObjectId(categoryPlainId)},
+		primitive.E{Key: "period", Value: period},
+	}
+
+	database.OpenMongoDbConnection(database.BudgetTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2BudgetEntity(database.GetOneInMongoDB(filter))
+}
+
+func (BudgetMongoDbMapper) InsertBudgetByEntity(newEntity model.BudgetEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	database.OpenMongoDbConnection(database.BudgetTableName)
+	defer database.CloseMongoDbConnection()
+
+	newBudgetId := database.InsertOneInMongoDB(convertBudgetEntity2BsonD(newEntity))
+	return newBudgetId.Hex()
+}
+
+func (BudgetMongoDbMapper) BulkInsertBudgets(entities []model.BudgetEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		documents[i] = convertBudgetEntity2BsonD(fillOperatingTime(entity, operatingTime))
+	}
+
+	collection := database.GetMongoCollection(database.BudgetTableName)
+	result, err := collection.InsertMany(context.TODO(), documents)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	ids := make([]string, len(result.InsertedIDs))
+	for i, id := range result.InsertedIDs {
+		ids[i] = id.(primitive.ObjectID).Hex()
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (BudgetMongoDbMapper) UpdateBudgetByEntity(plainId string, updatedEntity model.BudgetEntity) model.BudgetEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("budget's id is not acceptable")
+		return model.BudgetEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.BudgetTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2BudgetEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("budget is not exist")
+		return model.BudgetEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	rowsAffected := database.UpdateManyInMongoDB(filter, convertBudgetEntity2BsonD(updatedEntity))
+	if rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "rows_affected", rowsAffected)
+		return model.BudgetEntity{}
+	}
+	return updatedEntity
+}
+
+func (BudgetMongoDbMapper) GetAllBudgets(limit, offset int) []model.BudgetEntity {
+	return findMongoBudgets(bson.D{}, budgetPageOptions(limit, offset))
+}
+
+func (BudgetMongoDbMapper) GetBudgetsByOwnerId(ownerPlainId string, limit, offset int) []model.BudgetEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+	return findMongoBudgets(filter, budgetPageOptions(limit, offset))
+}
+
+// budgetPageOptions reads a page of budgets ordered by category, then period
+func budgetPageOptions(limit, offset int) *options.FindOptions {
+	findOptions := database.GetFindOptions()
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		findOptions.SetSkip(int64(offset))
+	}
+	// Sort by category, then period
+	findOptions.SetSort(bson.D{
+		primitive.E{Key: "category_id", Value: 1},
+		primitive.E{Key: "period", Value: 1},
+	})
+	return findOptions
+}
+
+func (BudgetMongoDbMapper) CountAllBudgets() int64 {
+	database.OpenMongoDbConnection(database.BudgetTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(bson.D{})
+}
+
+func (BudgetMongoDbMapper) CountBudgetsByCategoryId(categoryPlainId string) int64 {
+	filter := bson.D{
+		primitive.E{Key: "category_id", Value: util.Convert2ObjectId(categoryPlainId)},
+	}
+
+	database.OpenMongoDbConnection(database.BudgetTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+func (BudgetMongoDbMapper) DeleteBudgetByObjectId(plainId string) model.BudgetEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("budget's id is not acceptable")
+		return model.BudgetEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.BudgetTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2BudgetEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("budget is not exist")
+		return model.BudgetEntity{}
+	}
+
+	rowsAffected := database.DeleteManyInMongoDB(filter)
+	if rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "rows_affected", rowsAffected)
+		return model.BudgetEntity{}
+	}
+	return targetEntity
+}
+
+func (BudgetMongoDbMapper) DeleteAllBudgets() (int64, error) {
+	collection := database.GetMongoCollection(database.BudgetTableName)
+	result, err := collection.DeleteMany(context.TODO(), bson.D{})
+	if err != nil {
+		util.Logger.Errorw("delete all budgets failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all budgets deleted", "count", result.DeletedCount)
+	return result.DeletedCount, nil
+}
+
+func (BudgetMongoDbMapper) AssignUnownedBudgets(ownerPlainId string) (int64, error) {
+	collection := database.GetMongoCollection(database.BudgetTableName)
+	result, err := collection.UpdateMany(context.TODO(),
+		bson.D{database.OwnerFilterInMongoDB("")},
+		bson.D{primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "owner_id", Value: util.ConvertOwner2ObjectId(ownerPlainId)},
+		}}})
+	if err != nil {
+		util.Logger.Errorw("assign unowned budgets failed", "error", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func findMongoBudgets(filter bson.D, findOptions *options.FindOptions) []model.BudgetEntity {
+	collection := database.GetMongoCollection(database.BudgetTableName)
+
+	ctx := context.TODO()
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		util.Logger.Errorw("query budgets failed", "error", err)
+		return []model.BudgetEntity{}
+	}
+	defer cursor.Close(ctx)
+
+	var targetEntityList []model.BudgetEntity
+	for cursor.Next(ctx) {
+		var bsonM bson.M
+		if err := cursor.Decode(&bsonM); err != nil {
+			util.Logger.Errorw("decode failed", "error", err)
+			continue
+		}
+		targetEntityList = append(targetEntityList, convertBsonM2BudgetEntity(bsonM))
+	}
+	return targetEntityList
+}
+
+func convertBudgetEntity2BsonD(entity model.BudgetEntity) bson.D {
+	// 为空时自动生成新Id
+	if entity.Id == primitive.NilObjectID {
+		entity.Id = primitive.NewObjectID()
+	}
+
+	return bson.D{
+		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "owner_id", Value: entity.OwnerId},
+		primitive.E{Key: "category_id", Value: entity.CategoryId},
+		primitive.E{Key: "period", Value: entity.Period},
+		primitive.E{Key: "limit", Value: entity.Limit},
+		primitive.E{Key: "currency", Value: entity.Currency},
+		primitive.E{Key: "rollover", Value: entity.Rollover},
+		primitive.E{Key: "start_date", Value: entity.StartDate},
+		primitive.E{Key: "create_time", Value: entity.CreateTime},
+		primitive.E{Key: "modify_time", Value: entity.ModifyTime},
+	}
+}
+
+func convertBsonM2BudgetEntity(bsonM bson.M) model.BudgetEntity {
+	var newEntity model.BudgetEntity
+	if err := database.DecodeBsonM(bsonM, &newEntity); err != nil {
+		panic(err)
+	}
+	return newEntity
+}
@@ -0,0 +1,346 @@
+package budget_mapper
+
+import (
+	"bytes"
+	"database/sql"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BudgetMySqlMapper struct{}
+
+const mySqlBudgetColumns = "ID, OWNER_ID, CATEGORY_ID, PERIOD, LIMIT_AMOUNT, CURRENCY, ROLLOVER, START_DATE, CREATE_TIME, MODIFY_TIME"
+
+func (BudgetMySqlMapper) GetBudgetByObjectId(plainId string) model.BudgetEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlBudgetColumns + " FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := queryMySqlBudgets(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.BudgetEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (BudgetMySqlMapper) GetBudgetByCategoryIdAndPeriod(categoryPlainId, period string) model.BudgetEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlBudgetColumns + " FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" WHERE CATEGORY_ID = ? AND PERIOD = ? ")
+
+	targetEntityList := queryMySqlBudgets(sqlString.String(), categoryPlainId, period)
+	if len(targetEntityList) == 0 {
+		return model.BudgetEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (BudgetMySqlMapper) InsertBudgetByEntity(newEntity model.BudgetEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" (" + mySqlBudgetColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := connection.Exec(sqlString.String(), newPlainId, newEntity.OwnerId.Hex(), newEntity.CategoryId.Hex(), newEntity.Period,
+		newEntity.Limit, newEntity.Currency, newEntity.Rollover, newEntity.StartDate,
+		newEntity.CreateTime, newEntity.ModifyTime)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (BudgetMySqlMapper) BulkInsertBudgets(entities []model.BudgetEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" (" + mySqlBudgetColumns + ") VALUES ")
+
+	ids := make([]string, len(entities))
+	values := make([]interface{}, 0, len(entities)*10)
+
+	for i, entity := range entities {
+		if i > 0 {
+			sqlString.WriteString(", ")
+		}
+		sqlString.WriteString("(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		values = append(values, ids[i], entity.OwnerId.Hex(), entity.CategoryId.Hex(), entity.Period, entity.Limit, entity.Currency,
+			entity.Rollover, entity.StartDate, entity.CreateTime, entity.ModifyTime)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), values...)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != int64(len(entities)) {
+		util.Logger.Errorw("bulk insert incomplete", "error", err, "expected", len(entities), "actual", rowsAffected)
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (BudgetMySqlMapper) UpdateBudgetByEntity(plainId string, updatedEntity model.BudgetEntity) model.BudgetEntity {
+	targetEntity := INSTANCE.GetBudgetByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("budget is not exist")
+		return model.BudgetEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" SET CATEGORY_ID = ?, ")
+	sqlString.WriteString(" PERIOD = ?, ")
+	sqlString.WriteString(" LIMIT_AMOUNT = ?, ")
+	sqlString.WriteString(" CURRENCY = ?, ")
+	sqlString.WriteString(" ROLLOVER = ?, ")
+	sqlString.WriteString(" START_DATE = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), updatedEntity.CategoryId.Hex(), updatedEntity.Period,
+		updatedEntity.Limit, updatedEntity.Currency, updatedEntity.Rollover, updatedEntity.StartDate,
+		updatedEntity.ModifyTime, plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.BudgetEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (BudgetMySqlMapper) GetAllBudgets(limit, offset int) []model.BudgetEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlBudgetColumns + " FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" ORDER BY CATEGORY_ID ASC, PERIOD ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlBudgets(sqlString.String(), limit, offset)
+	}
+	return queryMySqlBudgets(sqlString.String())
+}
+
+func (BudgetMySqlMapper) GetBudgetsByOwnerId(ownerPlainId string, limit, offset int) []model.BudgetEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlBudgetColumns + " FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY CATEGORY_ID ASC, PERIOD ASC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlBudgets(sqlString.String(), ownerId, limit, offset)
+	}
+	return queryMySqlBudgets(sqlString.String(), ownerId)
+}
+
+func (BudgetMySqlMapper) CountAllBudgets() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all budgets failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (BudgetMySqlMapper) CountBudgetsByCategoryId(categoryPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" WHERE CATEGORY_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow(sqlString.String(), categoryPlainId).Scan(&count); err != nil {
+		util.Logger.Errorw("count budgets failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (BudgetMySqlMapper) DeleteBudgetByObjectId(plainId string) model.BudgetEntity {
+	targetEntity := INSTANCE.GetBudgetByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("budget is not exist")
+		return model.BudgetEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.BudgetEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (BudgetMySqlMapper) DeleteAllBudgets() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all budgets failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all budgets failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all budgets deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (BudgetMySqlMapper) AssignUnownedBudgets(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned budgets failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func queryMySqlBudgets(sqlString string, args ...interface{}) []model.BudgetEntity {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.BudgetEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2BudgetEntity(rows))
+	}
+	return targetEntityList
+}
+
+// generatePlainId keeps a preset id (e.g. restored from backup) and generates one otherwise
+func generatePlainId(objectId primitive.ObjectID) string {
+	if objectId == primitive.NilObjectID {
+		return primitive.NewObjectID().Hex()
+	}
+	return objectId.Hex()
+}
+
+func convertRow2BudgetEntity(rows *sql.Rows) model.BudgetEntity {
+	var id string
+	var ownerId string
+	var categoryId string
+	var period string
+	var limit decimal.Decimal
+	var currency string
+	var rollover bool
+	var startDate string
+	var createTime string
+	var modifyTime string
+
+	err := rows.Scan(&id, &ownerId, &categoryId, &period, &limit, &currency, &rollover, &startDate, &createTime, &modifyTime)
+	if err != nil {
+		util.Logger.Errorw("covert into entity failed", "error", err)
+	}
+
+	return model.BudgetEntity{
+		Id:         util.Convert2ObjectId(id),
+		OwnerId:    util.Convert2ObjectId(ownerId),
+		CategoryId: util.Convert2ObjectId(categoryId),
+		Period:     period,
+		Limit:      limit,
+		Currency:   currency,
+		Rollover:   rollover,
+		StartDate:  util.FormatDateTimeFromString(startDate),
+		CreateTime: util.FormatDateTimeFromString(createTime),
+		ModifyTime: util.FormatDateTimeFromString(modifyTime),
+	}
+}
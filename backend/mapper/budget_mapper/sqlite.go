@@ -0,0 +1,300 @@
+package budget_mapper
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BudgetSqliteMapper struct{}
+
+const sqliteBudgetColumns = "ID, OWNER_ID, CATEGORY_ID, PERIOD, LIMIT_AMOUNT, CURRENCY, ROLLOVER, START_DATE, CREATE_TIME, MODIFY_TIME"
+
+func (BudgetSqliteMapper) GetBudgetByObjectId(plainId string) model.BudgetEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteBudgetColumns + " FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := querySqliteBudgets(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.BudgetEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (BudgetSqliteMapper) GetBudgetByCategoryIdAndPeriod(categoryPlainId, period string) model.BudgetEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteBudgetColumns + " FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" WHERE CATEGORY_ID = ? AND PERIOD = ? ")
+
+	targetEntityList := querySqliteBudgets(sqlString.String(), categoryPlainId, period)
+	if len(targetEntityList) == 0 {
+		return model.BudgetEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (BudgetSqliteMapper) InsertBudgetByEntity(newEntity model.BudgetEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" (" + sqliteBudgetColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ")
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		convertBudgetEntity2SqliteValues(newPlainId, newEntity)...)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (BudgetSqliteMapper) BulkInsertBudgets(entities []model.BudgetEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" (" + sqliteBudgetColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ")
+
+	// One transaction keeps the batch all-or-nothing and avoids a disk sync per row
+	transaction, err := database.GetSqliteConnection().Begin()
+	if err != nil {
+		util.Logger.Errorw("bulk insert begin failed", "error", err)
+		return nil, err
+	}
+
+	statement, err := transaction.Prepare(sqlString.String())
+	if err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert prepare failed", "error", err)
+		return nil, err
+	}
+	defer statement.Close()
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		if _, err := statement.Exec(convertBudgetEntity2SqliteValues(ids[i], entity)...); err != nil {
+			transaction.Rollback()
+			util.Logger.Errorw("bulk insert failed", "error", err)
+			return nil, err
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		util.Logger.Errorw("bulk insert commit failed", "error", err)
+		return nil, err
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (BudgetSqliteMapper) UpdateBudgetByEntity(plainId string, updatedEntity model.BudgetEntity) model.BudgetEntity {
+	targetEntity := INSTANCE.GetBudgetByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("budget is not exist")
+		return model.BudgetEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" SET CATEGORY_ID = ?, ")
+	sqlString.WriteString(" PERIOD = ?, ")
+	sqlString.WriteString(" LIMIT_AMOUNT = ?, ")
+	sqlString.WriteString(" CURRENCY = ?, ")
+	sqlString.WriteString(" ROLLOVER = ?, ")
+	sqlString.WriteString(" START_DATE = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), updatedEntity.CategoryId.Hex(),
+		updatedEntity.Period, updatedEntity.Limit, updatedEntity.Currency, updatedEntity.Rollover,
+		util.FormatDateToStringWithDash(updatedEntity.StartDate), util.FormatDateTimeToString(updatedEntity.ModifyTime),
+		plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.BudgetEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (BudgetSqliteMapper) GetAllBudgets(limit, offset int) []model.BudgetEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteBudgetColumns + " FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" ORDER BY CATEGORY_ID ASC, PERIOD ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteBudgets(sqlString.String(), limit, offset)
+	}
+	return querySqliteBudgets(sqlString.String())
+}
+
+func (BudgetSqliteMapper) GetBudgetsByOwnerId(ownerPlainId string, limit, offset int) []model.BudgetEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteBudgetColumns + " FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY CATEGORY_ID ASC, PERIOD ASC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteBudgets(sqlString.String(), ownerId, limit, offset)
+	}
+	return querySqliteBudgets(sqlString.String(), ownerId)
+}
+
+func (BudgetSqliteMapper) CountAllBudgets() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all budgets failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (BudgetSqliteMapper) CountBudgetsByCategoryId(categoryPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" WHERE CATEGORY_ID = ? ")
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String(), categoryPlainId).Scan(&count); err != nil {
+		util.Logger.Errorw("count budgets failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (BudgetSqliteMapper) DeleteBudgetByObjectId(plainId string) model.BudgetEntity {
+	targetEntity := INSTANCE.GetBudgetByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("budget is not exist")
+		return model.BudgetEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.BudgetEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (BudgetSqliteMapper) DeleteAllBudgets() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.BudgetTableName)
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all budgets failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all budgets failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all budgets deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (BudgetSqliteMapper) AssignUnownedBudgets(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.BudgetTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned budgets failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func querySqliteBudgets(sqlString string, args ...interface{}) []model.BudgetEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.BudgetEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2BudgetEntity(rows))
+	}
+	return targetEntityList
+}
+
+// convertBudgetEntity2SqliteValues lists the column values in sqliteBudgetColumns order,
+// dates are written as text so that comparisons and ORDER BY work on them.
+func convertBudgetEntity2SqliteValues(plainId string, entity model.BudgetEntity) []interface{} {
+	return []interface{}{
+		plainId,
+		entity.OwnerId.Hex(),
+		entity.CategoryId.Hex(),
+		entity.Period,
+		entity.Limit,
+		entity.Currency,
+		entity.Rollover,
+		util.FormatDateToStringWithDash(entity.StartDate),
+		util.FormatDateTimeToString(entity.CreateTime),
+		util.FormatDateTimeToString(entity.ModifyTime),
+	}
+}
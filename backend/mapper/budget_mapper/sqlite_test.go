@@ -0,0 +1,122 @@
+package budget_mapper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMain(m *testing.M) {
+	tempDir, err := os.MkdirTemp("", "cashlens-sqlite-")
+	if err != nil {
+		panic(err)
+	}
+	util.SetConfigByKey("db.sqlite.path", filepath.Join(tempDir, "budget_test.db"))
+	INSTANCE = BudgetSqliteMapper{}
+
+	code := m.Run()
+	os.RemoveAll(tempDir)
+	os.Exit(code)
+}
+
+func TestSqliteBudgetLifecycle(t *testing.T) {
+	mapper := BudgetSqliteMapper{}
+	if _, err := mapper.DeleteAllBudgets(); err != nil {
+		t.Fatalf("DeleteAllBudgets() error = %v", err)
+	}
+
+	foodId := primitive.NewObjectID()
+	rentId := primitive.NewObjectID()
+	monthlyFoodId := mapper.InsertBudgetByEntity(model.BudgetEntity{
+		CategoryId: foodId,
+		Period:     model.BudgetPeriodMonthly,
+		Limit:      decimal.RequireFromString("450.50"),
+		Currency:   "USD",
+		Rollover:   true,
+		StartDate:  util.FormatDateFromStringWithDash("2024-01-01"),
+	})
+	otherIds, err := mapper.BulkInsertBudgets([]model.BudgetEntity{
+		{CategoryId: foodId, Period: model.BudgetPeriodYearly, Limit: decimal.NewFromInt(5000), Currency: "USD",
+			StartDate: util.FormatDateFromStringWithDash("2024-01-01")},
+		{CategoryId: rentId, Period: model.BudgetPeriodMonthly, Limit: decimal.NewFromInt(1200), Currency: "EUR",
+			StartDate: util.FormatDateFromStringWithDash("2024-03-01")},
+	})
+	if err != nil || len(otherIds) != 2 {
+		t.Fatalf("BulkInsertBudgets() = %v, %v", otherIds, err)
+	}
+
+	monthlyFood := mapper.GetBudgetByObjectId(monthlyFoodId)
+	if !monthlyFood.Limit.Equal(decimal.RequireFromString("450.5")) || monthlyFood.CategoryId != foodId ||
+		!monthlyFood.Rollover || util.FormatDateToStringWithDash(monthlyFood.StartDate) != "2024-01-01" {
+		t.Errorf("GetBudgetByObjectId() = %+v", monthlyFood)
+	}
+	if yearlyFood := mapper.GetBudgetByCategoryIdAndPeriod(foodId.Hex(), model.BudgetPeriodYearly); yearlyFood.Id.Hex() != otherIds[0] ||
+		yearlyFood.Rollover {
+		t.Errorf("GetBudgetByCategoryIdAndPeriod() = %+v", yearlyFood)
+	}
+	if count := mapper.CountBudgetsByCategoryId(foodId.Hex()); count != 2 {
+		t.Errorf("CountBudgetsByCategoryId() = %d, want 2", count)
+	}
+	if count := mapper.CountAllBudgets(); count != 3 {
+		t.Errorf("CountAllBudgets() = %d, want 3", count)
+	}
+	if allList := mapper.GetAllBudgets(2, 1); len(allList) != 2 {
+		t.Errorf("GetAllBudgets() = %+v, want two budgets", allList)
+	}
+
+	monthlyFood.Limit = decimal.NewFromInt(500)
+	monthlyFood.Rollover = false
+	mapper.UpdateBudgetByEntity(monthlyFoodId, monthlyFood)
+	updated := mapper.GetBudgetByObjectId(monthlyFoodId)
+	if !updated.Limit.Equal(decimal.NewFromInt(500)) || updated.Rollover {
+		t.Errorf("UpdateBudgetByEntity() did not save, got %+v", updated)
+	}
+
+	if deleted := mapper.DeleteBudgetByObjectId(otherIds[1]); deleted.CategoryId != rentId {
+		t.Errorf("DeleteBudgetByObjectId() = %+v", deleted)
+	}
+
+	deletedCount, err := mapper.DeleteAllBudgets()
+	if err != nil || deletedCount != 2 {
+		t.Errorf("DeleteAllBudgets() = %d, %v, want 2, nil", deletedCount, err)
+	}
+}
+
+func TestSqliteBudgetOwners(t *testing.T) {
+	mapper := BudgetSqliteMapper{}
+	if _, err := mapper.DeleteAllBudgets(); err != nil {
+		t.Fatalf("DeleteAllBudgets() error = %v", err)
+	}
+
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	unownedId := mapper.InsertBudgetByEntity(model.BudgetEntity{CategoryId: primitive.NewObjectID(),
+		Period: model.BudgetPeriodMonthly, Limit: decimal.NewFromInt(100), Currency: "USD"})
+	bobId := mapper.InsertBudgetByEntity(model.BudgetEntity{OwnerId: bob, CategoryId: primitive.NewObjectID(),
+		Period: model.BudgetPeriodMonthly, Limit: decimal.NewFromInt(200), Currency: "USD"})
+
+	// An update keeps the owner
+	bobBudget := mapper.GetBudgetByObjectId(bobId)
+	bobBudget.OwnerId = primitive.NilObjectID
+	mapper.UpdateBudgetByEntity(bobId, bobBudget)
+	if owner := mapper.GetBudgetByObjectId(bobId).OwnerId; owner != bob {
+		t.Errorf("UpdateBudgetByEntity() changed the owner to %s", owner.Hex())
+	}
+
+	// Only the budgets without owner are handed over
+	assignedCount, err := mapper.AssignUnownedBudgets(alice.Hex())
+	if err != nil || assignedCount != 1 {
+		t.Fatalf("AssignUnownedBudgets() = %d, %v, want 1, nil", assignedCount, err)
+	}
+	aliceList := mapper.GetBudgetsByOwnerId(alice.Hex(), 0, 0)
+	if len(aliceList) != 1 || aliceList[0].Id.Hex() != unownedId {
+		t.Errorf("GetBudgetsByOwnerId(alice) = %+v, want the unowned budget", aliceList)
+	}
+	if bobList := mapper.GetBudgetsByOwnerId(bob.Hex(), 0, 0); len(bobList) != 1 || bobList[0].Id.Hex() != bobId {
+		t.Errorf("GetBudgetsByOwnerId(bob) = %+v, want bob's budget", bobList)
+	}
+}
@@ -9,22 +9,40 @@ import (
 
 var INSTANCE CashFlowMapper
 
+// CashFlowMapper stores cash flows. The lookups by date, description, tag or query and the stats
+// cover the ledger of one owner, a blank owner stands for the cash flows no user owns; lookups by id,
+// category, account or payee and the GetAll/CountAll/DeleteAll methods span all owners.
 type CashFlowMapper interface {
 	GetCashFlowByObjectId(plainId string) model.CashFlowEntity
 	GetCashFlowsByObjectIdArray(plainIdList []string) []model.CashFlowEntity
-	GetCashFlowsByBelongsDate(belongsDate time.Time) []model.CashFlowEntity
-	GetCashFlowsByDateRange(from, to time.Time) []model.CashFlowEntity
+	GetCashFlowsByBelongsDate(ownerPlainId string, belongsDate time.Time) []model.CashFlowEntity
+	GetCashFlowsByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowEntity
 	GetCashFlowsByCategoryId(categoryPlainId string) []model.CashFlowEntity
-	GetCashFlowsByExactDesc(description string) []model.CashFlowEntity
-	GetCashFlowsByFuzzyDesc(description string) []model.CashFlowEntity
+	GetCashFlowsByExactDesc(ownerPlainId, description string) []model.CashFlowEntity
+	GetCashFlowsByFuzzyDesc(ownerPlainId, description string) []model.CashFlowEntity
+	GetCashFlowsByTag(ownerPlainId, tag string, limit, offset int) []model.CashFlowEntity
+	CountCashFlowsByTag(ownerPlainId, tag string) int64
+	GetCashFlowsByOwnerId(ownerPlainId string, limit, offset int) []model.CashFlowEntity
+	QueryCashFlows(ownerPlainId string, querySpec model.CashFlowQuerySpec) []model.CashFlowEntity
+	CountCashFlowsByQuery(ownerPlainId string, querySpec model.CashFlowQuerySpec) int64
 	CountCashFLowsByCategoryId(categoryPlainId string) int64
+	CountCashFlowsByAccountId(accountPlainId string) int64
+	CountCashFlowsByPayeeId(payeePlainId string) int64
 	InsertCashFlowByEntity(newEntity model.CashFlowEntity) string
 	BulkInsertCashFlows(entities []model.CashFlowEntity) ([]string, error)
 	UpdateCashFlowByEntity(plainId string, updatedEntity model.CashFlowEntity) model.CashFlowEntity
 	GetAllCashFlows(limit, offset int) []model.CashFlowEntity
 	CountAllCashFlows() int64
+	GetCashFlowTypeStats(ownerPlainId string) []model.CashFlowTypeStat
+	GetCashFlowCategoryStats(ownerPlainId string) []model.CashFlowCategoryStat
+	GetCashFlowAccountStats(ownerPlainId string) []model.CashFlowAccountStat
+	GetCashFlowDateSpan(ownerPlainId string) (earliest, latest time.Time)
+	GetCashFlowSummaryByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowSummaryStat
+	GetCashFlowTagSummaryByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowTagSummaryStat
 	DeleteCashFlowByObjectId(plainId string) model.CashFlowEntity
-	DeleteCashFlowByBelongsDate(belongsDate time.Time) []model.CashFlowEntity
+	DeleteCashFlowByBelongsDate(ownerPlainId string, belongsDate time.Time) []model.CashFlowEntity
+	DeleteAllCashFlows() (int64, error)
+	AssignUnownedCashFlows(ownerPlainId string) (int64, error)
 }
 
 func init() {
@@ -33,7 +51,23 @@ func init() {
 		INSTANCE = CashFlowMongoDbMapper{}
 	case "mysql":
 		INSTANCE = CashFlowMySqlMapper{}
+	case "sqlite":
+		INSTANCE = CashFlowSqliteMapper{}
+	case "memory":
+		INSTANCE = NewCashFlowMemoryMapper()
 	default:
 		panic("database type not supported")
 	}
 }
+
+// fillOperatingTime keeps create/modify times carried over from a backup or import,
+// and stamps the operating time on brand-new records.
+func fillOperatingTime(entity model.CashFlowEntity, operatingTime time.Time) model.CashFlowEntity {
+	if entity.CreateTime.IsZero() {
+		entity.CreateTime = operatingTime
+	}
+	if entity.ModifyTime.IsZero() {
+		entity.ModifyTime = operatingTime
+	}
+	return entity
+}
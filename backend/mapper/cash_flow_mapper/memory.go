@@ -0,0 +1,647 @@
+package cash_flow_mapper
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CashFlowMemoryMapper keeps cash flows in process memory, nothing is persisted.
+// It is safe for concurrent use; copies of the mapper share the same data.
+type CashFlowMemoryMapper struct {
+	store *cashFlowMemoryStore
+}
+
+type cashFlowMemoryStore struct {
+	mutex   sync.RWMutex
+	records map[primitive.ObjectID]model.CashFlowEntity
+}
+
+// NewCashFlowMemoryMapper returns an empty in-memory mapper
+func NewCashFlowMemoryMapper() CashFlowMemoryMapper {
+	return CashFlowMemoryMapper{
+		store: &cashFlowMemoryStore{
+			records: make(map[primitive.ObjectID]model.CashFlowEntity),
+		},
+	}
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowByObjectId(plainId string) model.CashFlowEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("cash_flow's id is not acceptable")
+		return model.CashFlowEntity{}
+	}
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return mapper.store.records[objectId]
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowsByObjectIdArray(plainIdList []string) []model.CashFlowEntity {
+	plainIdSet := make(map[string]bool, len(plainIdList))
+	for _, plainId := range plainIdList {
+		plainIdSet[plainId] = true
+	}
+	return mapper.filter(func(entity model.CashFlowEntity) bool {
+		return plainIdSet[entity.Id.Hex()]
+	})
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowsByBelongsDate(ownerPlainId string, belongsDate time.Time) []model.CashFlowEntity {
+	return mapper.filterByOwner(ownerPlainId, func(entity model.CashFlowEntity) bool {
+		return entity.BelongsDate.Equal(belongsDate)
+	})
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowsByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowEntity {
+	return mapper.filterByOwner(ownerPlainId, func(entity model.CashFlowEntity) bool {
+		return isInDateRange(entity.BelongsDate, from, to)
+	})
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowsByCategoryId(categoryPlainId string) []model.CashFlowEntity {
+	return mapper.filter(func(entity model.CashFlowEntity) bool {
+		if entity.CategoryId.Hex() == categoryPlainId {
+			return true
+		}
+		for _, split := range entity.Splits {
+			if split.CategoryId.Hex() == categoryPlainId {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowsByExactDesc(ownerPlainId, description string) []model.CashFlowEntity {
+	return mapper.filterByOwner(ownerPlainId, func(entity model.CashFlowEntity) bool {
+		return entity.Description == description
+	})
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowsByFuzzyDesc(ownerPlainId, description string) []model.CashFlowEntity {
+	return mapper.filterByOwner(ownerPlainId, func(entity model.CashFlowEntity) bool {
+		return strings.Contains(entity.Description, description)
+	})
+}
+
+func (mapper CashFlowMemoryMapper) CountCashFLowsByCategoryId(categoryPlainId string) int64 {
+	return int64(len(mapper.GetCashFlowsByCategoryId(categoryPlainId)))
+}
+
+func (mapper CashFlowMemoryMapper) CountCashFlowsByAccountId(accountPlainId string) int64 {
+	accountObjectId := util.Convert2ObjectId(accountPlainId)
+	if accountPlainId == "" || accountObjectId == primitive.NilObjectID {
+		util.Logger.Warnln("account's id is not acceptable")
+		return 0
+	}
+
+	return int64(len(mapper.filter(func(entity model.CashFlowEntity) bool {
+		return entity.AccountId == accountObjectId
+	})))
+}
+
+func (mapper CashFlowMemoryMapper) CountCashFlowsByPayeeId(payeePlainId string) int64 {
+	payeeObjectId := util.Convert2ObjectId(payeePlainId)
+	if payeePlainId == "" || payeeObjectId == primitive.NilObjectID {
+		util.Logger.Warnln("payee's id is not acceptable")
+		return 0
+	}
+
+	return int64(len(mapper.filter(func(entity model.CashFlowEntity) bool {
+		return entity.PayeeId == payeeObjectId
+	})))
+}
+
+func (mapper CashFlowMemoryMapper) InsertCashFlowByEntity(newEntity model.CashFlowEntity) string {
+	newPlainIdList, err := mapper.BulkInsertCashFlows([]model.CashFlowEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+	return newPlainIdList[0]
+}
+
+func (mapper CashFlowMemoryMapper) BulkInsertCashFlows(entities []model.CashFlowEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	operatingTime := time.Now()
+	newEntityList := make([]model.CashFlowEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.records[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate cash_flow id: " + entity.Id.Hex())
+		}
+		batchIds[entity.Id] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.records[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper CashFlowMemoryMapper) UpdateCashFlowByEntity(plainId string, updatedEntity model.CashFlowEntity) model.CashFlowEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("cash_flow's id is not acceptable")
+		return model.CashFlowEntity{}
+	}
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("cash_flow is not exist")
+		return model.CashFlowEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner, creator and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreatedBy = targetEntity.CreatedBy
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+	mapper.store.records[objectId] = updatedEntity
+	return updatedEntity
+}
+
+func (mapper CashFlowMemoryMapper) GetAllCashFlows(limit, offset int) []model.CashFlowEntity {
+	targetEntityList := mapper.filter(func(model.CashFlowEntity) bool { return true })
+
+	// Newest first, like the database mappers
+	reverseCashFlows(targetEntityList)
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowsByOwnerId(ownerPlainId string, limit, offset int) []model.CashFlowEntity {
+	targetEntityList := mapper.filterByOwner(ownerPlainId, func(model.CashFlowEntity) bool { return true })
+
+	// Newest first, like GetAllCashFlows
+	reverseCashFlows(targetEntityList)
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper CashFlowMemoryMapper) CountAllCashFlows() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.records))
+}
+
+func (mapper CashFlowMemoryMapper) DeleteCashFlowByObjectId(plainId string) model.CashFlowEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("cash_flow is not exist")
+		return model.CashFlowEntity{}
+	}
+
+	delete(mapper.store.records, objectId)
+	return targetEntity
+}
+
+func (mapper CashFlowMemoryMapper) DeleteCashFlowByBelongsDate(ownerPlainId string, belongsDate time.Time) []model.CashFlowEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	var cashFlowList []model.CashFlowEntity
+	for objectId, entity := range mapper.store.records {
+		if entity.OwnerId == ownerId && entity.BelongsDate.Equal(belongsDate) {
+			cashFlowList = append(cashFlowList, entity)
+			delete(mapper.store.records, objectId)
+		}
+	}
+
+	if cashFlowList == nil {
+		util.Logger.Infoln("no cash_flow(s) found")
+	}
+	return cashFlowList
+}
+
+func (mapper CashFlowMemoryMapper) DeleteAllCashFlows() (int64, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	deletedCount := int64(len(mapper.store.records))
+	mapper.store.records = make(map[primitive.ObjectID]model.CashFlowEntity)
+	return deletedCount, nil
+}
+
+func (mapper CashFlowMemoryMapper) AssignUnownedCashFlows(ownerPlainId string) (int64, error) {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	var assignedCount int64
+	for objectId, entity := range mapper.store.records {
+		if entity.OwnerId == primitive.NilObjectID {
+			entity.OwnerId = ownerId
+			mapper.store.records[objectId] = entity
+			assignedCount++
+		}
+	}
+	return assignedCount, nil
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowTypeStats(ownerPlainId string) []model.CashFlowTypeStat {
+	type typeStatKey struct {
+		flowType string
+		currency string
+	}
+
+	typeStatMap := make(map[typeStatKey]*model.CashFlowTypeStat)
+	for _, entity := range mapper.filterByOwner(ownerPlainId, func(model.CashFlowEntity) bool { return true }) {
+		key := typeStatKey{flowType: entity.FlowType, currency: entity.Currency}
+		typeStat, isExist := typeStatMap[key]
+		if !isExist {
+			typeStat = &model.CashFlowTypeStat{FlowType: entity.FlowType, Currency: entity.Currency}
+			typeStatMap[key] = typeStat
+		}
+		typeStat.Count++
+		typeStat.TotalAmount = typeStat.TotalAmount.Add(entity.Amount)
+	}
+
+	typeStatList := make([]model.CashFlowTypeStat, 0, len(typeStatMap))
+	for _, typeStat := range typeStatMap {
+		typeStatList = append(typeStatList, *typeStat)
+	}
+	sort.Slice(typeStatList, func(i, j int) bool {
+		if typeStatList[i].FlowType != typeStatList[j].FlowType {
+			return typeStatList[i].FlowType < typeStatList[j].FlowType
+		}
+		return typeStatList[i].Currency < typeStatList[j].Currency
+	})
+	return typeStatList
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowCategoryStats(ownerPlainId string) []model.CashFlowCategoryStat {
+	type categoryStatKey struct {
+		categoryId primitive.ObjectID
+		currency   string
+	}
+
+	categoryStatMap := make(map[categoryStatKey]*model.CashFlowCategoryStat)
+	for _, entity := range mapper.filterByOwner(ownerPlainId, func(model.CashFlowEntity) bool { return true }) {
+		for lineNo, line := range entity.CategoryLines() {
+			key := categoryStatKey{categoryId: line.CategoryId, currency: entity.Currency}
+			categoryStat, isExist := categoryStatMap[key]
+			if !isExist {
+				categoryStat = &model.CashFlowCategoryStat{CategoryId: line.CategoryId, Currency: entity.Currency}
+				categoryStatMap[key] = categoryStat
+			}
+			// As in the database mappers, a split cash flow is counted on its first line only
+			if lineNo == 0 {
+				categoryStat.Count++
+			}
+			categoryStat.TotalAmount = categoryStat.TotalAmount.Add(line.Amount)
+		}
+	}
+
+	categoryStatList := make([]model.CashFlowCategoryStat, 0, len(categoryStatMap))
+	for _, categoryStat := range categoryStatMap {
+		categoryStatList = append(categoryStatList, *categoryStat)
+	}
+	sort.Slice(categoryStatList, func(i, j int) bool {
+		if categoryStatList[i].Count != categoryStatList[j].Count {
+			return categoryStatList[i].Count > categoryStatList[j].Count
+		}
+		if categoryStatList[i].CategoryId != categoryStatList[j].CategoryId {
+			return categoryStatList[i].CategoryId.Hex() < categoryStatList[j].CategoryId.Hex()
+		}
+		return categoryStatList[i].Currency < categoryStatList[j].Currency
+	})
+	return categoryStatList
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowAccountStats(ownerPlainId string) []model.CashFlowAccountStat {
+	type accountStatKey struct {
+		accountId primitive.ObjectID
+		flowType  string
+	}
+
+	accountStatMap := make(map[accountStatKey]*model.CashFlowAccountStat)
+	for _, entity := range mapper.filterByOwner(ownerPlainId, func(model.CashFlowEntity) bool { return true }) {
+		key := accountStatKey{accountId: entity.AccountId, flowType: entity.FlowType}
+		accountStat, isExist := accountStatMap[key]
+		if !isExist {
+			accountStat = &model.CashFlowAccountStat{AccountId: entity.AccountId, FlowType: entity.FlowType}
+			accountStatMap[key] = accountStat
+		}
+		accountStat.Count++
+		accountStat.TotalAmount = accountStat.TotalAmount.Add(entity.Amount)
+	}
+
+	accountStatList := make([]model.CashFlowAccountStat, 0, len(accountStatMap))
+	for _, accountStat := range accountStatMap {
+		accountStatList = append(accountStatList, *accountStat)
+	}
+	sort.Slice(accountStatList, func(i, j int) bool {
+		if accountStatList[i].AccountId != accountStatList[j].AccountId {
+			return accountStatList[i].AccountId.Hex() < accountStatList[j].AccountId.Hex()
+		}
+		return accountStatList[i].FlowType < accountStatList[j].FlowType
+	})
+	return accountStatList
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowDateSpan(ownerPlainId string) (earliest, latest time.Time) {
+	// filter sorts by belongs_date ascending
+	targetEntityList := mapper.filterByOwner(ownerPlainId, func(model.CashFlowEntity) bool { return true })
+	if len(targetEntityList) == 0 {
+		return time.Time{}, time.Time{}
+	}
+	return targetEntityList[0].BelongsDate, targetEntityList[len(targetEntityList)-1].BelongsDate
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowSummaryByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowSummaryStat {
+	type summaryKey struct {
+		flowType   string
+		currency   string
+		categoryId primitive.ObjectID
+	}
+
+	summaryStatMap := make(map[summaryKey]*model.CashFlowSummaryStat)
+	var summaryKeyList []summaryKey
+	for _, entity := range mapper.GetCashFlowsByDateRange(ownerPlainId, from, to) {
+		for lineNo, line := range entity.CategoryLines() {
+			key := summaryKey{flowType: entity.FlowType, currency: entity.Currency, categoryId: line.CategoryId}
+			summaryStat, isExist := summaryStatMap[key]
+			if !isExist {
+				summaryStat = &model.CashFlowSummaryStat{
+					FlowType:   entity.FlowType,
+					Currency:   entity.Currency,
+					CategoryId: line.CategoryId,
+				}
+				summaryStatMap[key] = summaryStat
+				summaryKeyList = append(summaryKeyList, key)
+			}
+			// As in the database mappers, a split cash flow is counted on its first line only
+			if lineNo == 0 {
+				summaryStat.Count++
+			}
+			summaryStat.TotalAmount = summaryStat.TotalAmount.Add(line.Amount)
+		}
+	}
+
+	// Category names are joined once per group, as the database mappers do
+	summaryStatList := make([]model.CashFlowSummaryStat, 0, len(summaryKeyList))
+	for _, key := range summaryKeyList {
+		summaryStat := summaryStatMap[key]
+		if key.categoryId != primitive.NilObjectID {
+			summaryStat.CategoryName = category_mapper.INSTANCE.GetCategoryByObjectId(key.categoryId.Hex()).Name
+		}
+		summaryStatList = append(summaryStatList, *summaryStat)
+	}
+	return summaryStatList
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowsByTag(ownerPlainId, tag string, limit, offset int) []model.CashFlowEntity {
+	targetEntityList := mapper.filterByOwner(ownerPlainId, func(entity model.CashFlowEntity) bool {
+		return hasTag(entity, tag)
+	})
+
+	// Newest first, like GetAllCashFlows
+	reverseCashFlows(targetEntityList)
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper CashFlowMemoryMapper) CountCashFlowsByTag(ownerPlainId, tag string) int64 {
+	return int64(len(mapper.filterByOwner(ownerPlainId, func(entity model.CashFlowEntity) bool {
+		return hasTag(entity, tag)
+	})))
+}
+
+func (mapper CashFlowMemoryMapper) GetCashFlowTagSummaryByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowTagSummaryStat {
+	type tagSummaryKey struct {
+		flowType string
+		currency string
+		tag      string
+	}
+
+	tagStatMap := make(map[tagSummaryKey]*model.CashFlowTagSummaryStat)
+	for _, entity := range mapper.GetCashFlowsByDateRange(ownerPlainId, from, to) {
+		for _, tag := range entity.Tags {
+			key := tagSummaryKey{flowType: entity.FlowType, currency: entity.Currency, tag: tag}
+			tagStat, isExist := tagStatMap[key]
+			if !isExist {
+				tagStat = &model.CashFlowTagSummaryStat{FlowType: entity.FlowType, Currency: entity.Currency, Tag: tag}
+				tagStatMap[key] = tagStat
+			}
+			tagStat.Count++
+			tagStat.TotalAmount = tagStat.TotalAmount.Add(entity.Amount)
+		}
+	}
+
+	tagStatList := make([]model.CashFlowTagSummaryStat, 0, len(tagStatMap))
+	for _, tagStat := range tagStatMap {
+		tagStatList = append(tagStatList, *tagStat)
+	}
+	sort.Slice(tagStatList, func(i, j int) bool {
+		if tagStatList[i].Tag != tagStatList[j].Tag {
+			return tagStatList[i].Tag < tagStatList[j].Tag
+		}
+		if tagStatList[i].FlowType != tagStatList[j].FlowType {
+			return tagStatList[i].FlowType < tagStatList[j].FlowType
+		}
+		return tagStatList[i].Currency < tagStatList[j].Currency
+	})
+	return tagStatList
+}
+
+func (mapper CashFlowMemoryMapper) QueryCashFlows(ownerPlainId string, querySpec model.CashFlowQuerySpec) []model.CashFlowEntity {
+	textTerms := querySpec.TextTerms()
+	targetEntityList := mapper.filterByOwner(ownerPlainId, func(entity model.CashFlowEntity) bool {
+		return isQueryMatched(entity, querySpec, textTerms)
+	})
+	if querySpec.CursorApplies() {
+		return pageFromCursor(targetEntityList, querySpec)
+	}
+
+	// The filter already orders by date then id ascending, a stable sort keeps that order between equal keys
+	isDesc := querySpec.SortDesc
+	switch {
+	case querySpec.SortBy == model.CashFlowSortByAmount:
+		sort.SliceStable(targetEntityList, func(i, j int) bool {
+			return targetEntityList[i].Amount.LessThan(targetEntityList[j].Amount)
+		})
+	case querySpec.SortBy == model.CashFlowSortByRelevance && len(textTerms) > 0:
+		// Most relevant first, then newest first, like the MySQL and MongoDB text scores
+		isDesc = true
+		sort.SliceStable(targetEntityList, func(i, j int) bool {
+			return searchScore(targetEntityList[i], textTerms) < searchScore(targetEntityList[j], textTerms)
+		})
+	}
+	if isDesc {
+		reverseCashFlows(targetEntityList)
+	}
+
+	return paginate(targetEntityList, querySpec.Limit, querySpec.Offset)
+}
+
+func (mapper CashFlowMemoryMapper) CountCashFlowsByQuery(ownerPlainId string, querySpec model.CashFlowQuerySpec) int64 {
+	textTerms := querySpec.TextTerms()
+	return int64(len(mapper.filterByOwner(ownerPlainId, func(entity model.CashFlowEntity) bool {
+		return isQueryMatched(entity, querySpec, textTerms)
+	})))
+}
+
+// filter returns the matching cash flows ordered by belongs_date then id, both ascending
+func (mapper CashFlowMemoryMapper) filter(isMatched func(entity model.CashFlowEntity) bool) []model.CashFlowEntity {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	var targetEntityList []model.CashFlowEntity
+	for _, entity := range mapper.store.records {
+		if isMatched(entity) {
+			targetEntityList = append(targetEntityList, entity)
+		}
+	}
+
+	sort.Slice(targetEntityList, func(i, j int) bool {
+		if !targetEntityList[i].BelongsDate.Equal(targetEntityList[j].BelongsDate) {
+			return targetEntityList[i].BelongsDate.Before(targetEntityList[j].BelongsDate)
+		}
+		return targetEntityList[i].Id.Hex() < targetEntityList[j].Id.Hex()
+	})
+	return targetEntityList
+}
+
+// filterByOwner is filter limited to the cash flows of one owner, blank for those no user owns
+func (mapper CashFlowMemoryMapper) filterByOwner(ownerPlainId string, isMatched func(entity model.CashFlowEntity) bool) []model.CashFlowEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	return mapper.filter(func(entity model.CashFlowEntity) bool {
+		return entity.OwnerId == ownerId && isMatched(entity)
+	})
+}
+
+// paginate cuts one page out of the ordered cash flows, a zero limit keeps all of them
+func paginate(targetEntityList []model.CashFlowEntity, limit, offset int) []model.CashFlowEntity {
+	if offset >= len(targetEntityList) {
+		return []model.CashFlowEntity{}
+	}
+	targetEntityList = targetEntityList[offset:]
+	if limit > 0 && limit < len(targetEntityList) {
+		targetEntityList = targetEntityList[:limit]
+	}
+	return targetEntityList
+}
+
+func hasTag(entity model.CashFlowEntity, tag string) bool {
+	for _, entityTag := range entity.Tags {
+		if entityTag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func isInDateRange(date, from, to time.Time) bool {
+	return !date.Before(from) && !date.After(to)
+}
+
+func isQueryMatched(entity model.CashFlowEntity, querySpec model.CashFlowQuerySpec, textTerms []string) bool {
+	description := strings.ToLower(entity.Description)
+	for _, term := range textTerms {
+		if !strings.Contains(description, term) {
+			return false
+		}
+	}
+	if !querySpec.FromDate.IsZero() && entity.BelongsDate.Before(querySpec.FromDate) ||
+		!querySpec.ToDate.IsZero() && entity.BelongsDate.After(querySpec.ToDate) {
+		return false
+	}
+	if !querySpec.MinAmount.IsZero() && entity.Amount.LessThan(querySpec.MinAmount) ||
+		!querySpec.MaxAmount.IsZero() && entity.Amount.GreaterThan(querySpec.MaxAmount) {
+		return false
+	}
+	if querySpec.FlowType != "" && entity.FlowType != querySpec.FlowType {
+		return false
+	}
+	if !querySpec.AccountId.IsZero() && entity.AccountId != querySpec.AccountId {
+		return false
+	}
+	for _, tag := range querySpec.Tags {
+		if !hasTag(entity, tag) {
+			return false
+		}
+	}
+	if len(querySpec.CategoryIds) == 0 {
+		return true
+	}
+	for _, categoryId := range querySpec.CategoryIds {
+		if entity.CategoryId == categoryId {
+			return true
+		}
+		for _, split := range entity.Splits {
+			if split.CategoryId == categoryId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pageFromCursor takes the records next to the cursor from a list in ascending date order,
+// they come back in listing order whichever way the page goes
+func pageFromCursor(ascendingList []model.CashFlowEntity, querySpec model.CashFlowQuerySpec) []model.CashFlowEntity {
+	isAscending := querySpec.ScansAscending()
+	pageList := []model.CashFlowEntity{}
+	for _, entity := range ascendingList {
+		if entity.Id != querySpec.Cursor.Id && querySpec.Cursor.IsAfter(entity.BelongsDate, entity.Id) == isAscending {
+			pageList = append(pageList, entity)
+		}
+	}
+
+	// Closest to the cursor first, so that the limit keeps the right end
+	if !isAscending {
+		reverseCashFlows(pageList)
+	}
+	if querySpec.Limit > 0 && querySpec.Limit < len(pageList) {
+		pageList = pageList[:querySpec.Limit]
+	}
+	if querySpec.Cursor.Backward {
+		reverseCashFlows(pageList)
+	}
+	return pageList
+}
+
+func reverseCashFlows(entityList []model.CashFlowEntity) {
+	for i, j := 0, len(entityList)-1; i < j; i, j = i+1, j-1 {
+		entityList[i], entityList[j] = entityList[j], entityList[i]
+	}
+}
+
+// searchScore counts how often the search words appear in the description
+func searchScore(entity model.CashFlowEntity, textTerms []string) int {
+	description := strings.ToLower(entity.Description)
+	score := 0
+	for _, term := range textTerms {
+		score += strings.Count(description, term)
+	}
+	return score
+}
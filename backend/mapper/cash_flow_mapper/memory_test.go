@@ -0,0 +1,71 @@
+package cash_flow_mapper
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+)
+
+func TestMemoryCashFlowConcurrentInsert(t *testing.T) {
+	mapper := NewCashFlowMemoryMapper()
+	belongsDate := util.FormatDateFromStringWithDash("2024-12-01")
+
+	var waitGroup sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			mapper.InsertCashFlowByEntity(model.CashFlowEntity{
+				BelongsDate: belongsDate,
+				FlowType:    model.FlowTypeOutcome,
+				Amount:      decimal.NewFromInt(1),
+			})
+			mapper.GetCashFlowsByBelongsDate("", belongsDate)
+		}()
+	}
+	waitGroup.Wait()
+
+	if count := mapper.CountAllCashFlows(); count != 50 {
+		t.Errorf("CountAllCashFlows() = %d, want 50", count)
+	}
+}
+
+func TestMemoryCashFlowBulkInsertIsAllOrNothing(t *testing.T) {
+	mapper := NewCashFlowMemoryMapper()
+	ids, err := mapper.BulkInsertCashFlows([]model.CashFlowEntity{{Description: "first"}})
+	if err != nil {
+		t.Fatalf("BulkInsertCashFlows() error = %v", err)
+	}
+
+	_, err = mapper.BulkInsertCashFlows([]model.CashFlowEntity{
+		{Description: "new"},
+		{Id: util.Convert2ObjectId(ids[0]), Description: "duplicate"},
+	})
+	if err == nil {
+		t.Errorf("BulkInsertCashFlows() with a duplicate id expected error, got nil")
+	}
+	if count := mapper.CountAllCashFlows(); count != 1 {
+		t.Errorf("CountAllCashFlows() = %d after failed batch, want 1", count)
+	}
+}
+
+func TestMemoryCashFlowGetAllIsNewestFirst(t *testing.T) {
+	mapper := NewCashFlowMemoryMapper()
+	for _, date := range []string{"2024-12-02", "2024-12-03", "2024-12-01"} {
+		mapper.InsertCashFlowByEntity(model.CashFlowEntity{
+			BelongsDate: util.FormatDateFromStringWithDash(date),
+			Description: date,
+		})
+	}
+
+	pageList := mapper.GetAllCashFlows(2, 1)
+	if len(pageList) != 2 || pageList[0].Description != "2024-12-02" || pageList[1].Description != "2024-12-01" {
+		t.Errorf("GetAllCashFlows(2, 1) = %+v, want 2024-12-02 then 2024-12-01", pageList)
+	}
+	if pageList = mapper.GetAllCashFlows(2, 5); len(pageList) != 0 {
+		t.Errorf("GetAllCashFlows(2, 5) returned %d records, want 0", len(pageList))
+	}
+}
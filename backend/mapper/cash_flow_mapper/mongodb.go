@@ -2,6 +2,7 @@ package cash_flow_mapper
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/macar-x/cashlens/model"
@@ -9,6 +10,7 @@ import (
 	"github.com/macar-x/cashlens/util/database"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type CashFlowMongoDbMapper struct{}
@@ -53,8 +55,9 @@ func (CashFlowMongoDbMapper) GetCashFlowsByObjectIdArray(plainIdList []string) [
 	return targetEntityList
 }
 
-func (CashFlowMongoDbMapper) GetCashFlowsByBelongsDate(belongsDate time.Time) []model.CashFlowEntity {
+func (CashFlowMongoDbMapper) GetCashFlowsByBelongsDate(ownerPlainId string, belongsDate time.Time) []model.CashFlowEntity {
 	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
 		primitive.E{Key: "belongs_date", Value: belongsDate},
 	}
 
@@ -71,8 +74,9 @@ func (CashFlowMongoDbMapper) GetCashFlowsByBelongsDate(belongsDate time.Time) []
 	return targetEntityList
 }
 
-func (CashFlowMongoDbMapper) GetCashFlowsByDateRange(from, to time.Time) []model.CashFlowEntity {
+func (CashFlowMongoDbMapper) GetCashFlowsByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowEntity {
 	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
 		primitive.E{Key: "belongs_date", Value: bson.M{
 			"$gte": from,
 			"$lte": to,
@@ -97,9 +101,7 @@ func (CashFlowMongoDbMapper) GetCashFlowsByCategoryId(categoryPlainId string) []
 		return nil
 	}
 
-	filter := bson.D{
-		primitive.E{Key: "category_id", Value: categoryObjectId},
-	}
+	filter := cashFlowCategoryFilter(categoryObjectId)
 
 	database.OpenMongoDbConnection(database.CashFlowTableName)
 	defer database.CloseMongoDbConnection()
@@ -119,8 +121,23 @@ func (CashFlowMongoDbMapper) CountCashFLowsByCategoryId(categoryPlainId string)
 		return 0
 	}
 
+	filter := cashFlowCategoryFilter(categoryObjectId)
+
+	database.OpenMongoDbConnection(database.CashFlowTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+func (CashFlowMongoDbMapper) CountCashFlowsByAccountId(accountPlainId string) int64 {
+	accountObjectId := util.Convert2ObjectId(accountPlainId)
+	if accountPlainId == "" || accountObjectId == primitive.NilObjectID {
+		util.Logger.Warnln("account's id is not acceptable")
+		return 0
+	}
+
 	filter := bson.D{
-		primitive.E{Key: "category_id", Value: categoryObjectId},
+		primitive.E{Key: "account_id", Value: accountObjectId},
 	}
 
 	database.OpenMongoDbConnection(database.CashFlowTableName)
@@ -129,8 +146,26 @@ func (CashFlowMongoDbMapper) CountCashFLowsByCategoryId(categoryPlainId string)
 	return database.CountInMongoDB(filter)
 }
 
-func (CashFlowMongoDbMapper) GetCashFlowsByExactDesc(description string) []model.CashFlowEntity {
+func (CashFlowMongoDbMapper) CountCashFlowsByPayeeId(payeePlainId string) int64 {
+	payeeObjectId := util.Convert2ObjectId(payeePlainId)
+	if payeePlainId == "" || payeeObjectId == primitive.NilObjectID {
+		util.Logger.Warnln("payee's id is not acceptable")
+		return 0
+	}
+
 	filter := bson.D{
+		primitive.E{Key: "payee_id", Value: payeeObjectId},
+	}
+
+	database.OpenMongoDbConnection(database.CashFlowTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+func (CashFlowMongoDbMapper) GetCashFlowsByExactDesc(ownerPlainId, description string) []model.CashFlowEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
 		primitive.E{Key: "description", Value: description},
 	}
 
@@ -148,9 +183,10 @@ func (CashFlowMongoDbMapper) GetCashFlowsByExactDesc(description string) []model
 	return targetEntityList
 }
 
-func (CashFlowMongoDbMapper) GetCashFlowsByFuzzyDesc(description string) []model.CashFlowEntity {
+func (CashFlowMongoDbMapper) GetCashFlowsByFuzzyDesc(ownerPlainId, description string) []model.CashFlowEntity {
 	// Options i for disable case sensitive.
 	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
 		primitive.E{Key: "description", Value: primitive.Regex{
 			Pattern: description,
 			Options: "i",
@@ -171,9 +207,7 @@ func (CashFlowMongoDbMapper) GetCashFlowsByFuzzyDesc(description string) []model
 }
 
 func (CashFlowMongoDbMapper) InsertCashFlowByEntity(newEntity model.CashFlowEntity) string {
-	operatingTime := time.Now()
-	newEntity.CreateTime = operatingTime
-	newEntity.ModifyTime = operatingTime
+	newEntity = fillOperatingTime(newEntity, time.Now())
 
 	database.OpenMongoDbConnection(database.CashFlowTableName)
 	defer database.CloseMongoDbConnection()
@@ -191,9 +225,7 @@ func (CashFlowMongoDbMapper) BulkInsertCashFlows(entities []model.CashFlowEntity
 	documents := make([]interface{}, len(entities))
 
 	for i, entity := range entities {
-		entity.CreateTime = operatingTime
-		entity.ModifyTime = operatingTime
-		documents[i] = convertCashFlowEntity2BsonD(entity)
+		documents[i] = convertCashFlowEntity2BsonD(fillOperatingTime(entity, operatingTime))
 	}
 
 	collection := database.GetMongoCollection(database.CashFlowTableName)
@@ -232,8 +264,10 @@ func (CashFlowMongoDbMapper) UpdateCashFlowByEntity(plainId string, updatedEntit
 		return model.CashFlowEntity{}
 	}
 
-	// Update fields from updatedEntity while preserving ID and CreateTime
+	// Update fields from updatedEntity while preserving ID, owner, creator and CreateTime
 	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreatedBy = targetEntity.CreatedBy
 	updatedEntity.CreateTime = targetEntity.CreateTime
 	updatedEntity.ModifyTime = time.Now()
 
@@ -274,12 +308,13 @@ func (CashFlowMongoDbMapper) DeleteCashFlowByObjectId(plainId string) model.Cash
 	return targetEntity
 }
 
-func (CashFlowMongoDbMapper) DeleteCashFlowByBelongsDate(belongsDate time.Time) []model.CashFlowEntity {
+func (CashFlowMongoDbMapper) DeleteCashFlowByBelongsDate(ownerPlainId string, belongsDate time.Time) []model.CashFlowEntity {
 	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
 		primitive.E{Key: "belongs_date", Value: belongsDate},
 	}
 
-	cashFlowList := INSTANCE.GetCashFlowsByBelongsDate(belongsDate)
+	cashFlowList := INSTANCE.GetCashFlowsByBelongsDate(ownerPlainId, belongsDate)
 	if cashFlowList == nil {
 		util.Logger.Infoln("no cash_flow(s) found")
 		return []model.CashFlowEntity{}
@@ -296,14 +331,495 @@ func (CashFlowMongoDbMapper) DeleteCashFlowByBelongsDate(belongsDate time.Time)
 	return cashFlowList
 }
 
+func (CashFlowMongoDbMapper) DeleteAllCashFlows() (int64, error) {
+	collection := database.GetMongoCollection(database.CashFlowTableName)
+	result, err := collection.DeleteMany(context.TODO(), bson.D{})
+	if err != nil {
+		util.Logger.Errorw("delete all failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all cash_flows deleted", "count", result.DeletedCount)
+	return result.DeletedCount, nil
+}
+
 func (CashFlowMongoDbMapper) GetAllCashFlows(limit, offset int) []model.CashFlowEntity {
+	// Empty filter to get all documents, with pagination
+	return findMongoDbCashFlows(bson.D{}, limit, offset)
+}
+
+func (CashFlowMongoDbMapper) GetCashFlowsByOwnerId(ownerPlainId string, limit, offset int) []model.CashFlowEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+	return findMongoDbCashFlows(filter, limit, offset)
+}
+
+func (CashFlowMongoDbMapper) CountAllCashFlows() int64 {
+	filter := bson.D{}
+
 	database.OpenMongoDbConnection(database.CashFlowTableName)
 	defer database.CloseMongoDbConnection()
 
+	return database.CountInMongoDB(filter)
+}
+
+func (CashFlowMongoDbMapper) AssignUnownedCashFlows(ownerPlainId string) (int64, error) {
 	collection := database.GetMongoCollection(database.CashFlowTableName)
+	result, err := collection.UpdateMany(context.TODO(),
+		bson.D{database.OwnerFilterInMongoDB("")},
+		bson.D{primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "owner_id", Value: util.ConvertOwner2ObjectId(ownerPlainId)},
+		}}})
+	if err != nil {
+		util.Logger.Errorw("assign unowned cash_flows failed", "error", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
 
-	// Empty filter to get all documents, with pagination
-	filter := bson.D{}
+func (CashFlowMongoDbMapper) GetCashFlowTypeStats(ownerPlainId string) []model.CashFlowTypeStat {
+	pipeline := mongo.Pipeline{
+		cashFlowOwnerStage(ownerPlainId),
+		bson.D{primitive.E{Key: "$group", Value: bson.D{
+			primitive.E{Key: "_id", Value: bson.D{
+				primitive.E{Key: "flow_type", Value: "$flow_type"},
+				primitive.E{Key: "currency", Value: bson.M{"$ifNull": bson.A{"$currency", ""}}},
+			}},
+			primitive.E{Key: "count", Value: bson.M{"$sum": 1}},
+			primitive.E{Key: "total_amount", Value: bson.M{"$sum": "$amount"}},
+		}}},
+		bson.D{primitive.E{Key: "$project", Value: bson.D{
+			primitive.E{Key: "_id", Value: 0},
+			primitive.E{Key: "flow_type", Value: "$_id.flow_type"},
+			primitive.E{Key: "currency", Value: "$_id.currency"},
+			primitive.E{Key: "count", Value: 1},
+			primitive.E{Key: "total_amount", Value: 1},
+		}}},
+		bson.D{primitive.E{Key: "$sort", Value: bson.D{
+			primitive.E{Key: "flow_type", Value: 1},
+			primitive.E{Key: "currency", Value: 1},
+		}}},
+	}
+
+	var typeStatList []model.CashFlowTypeStat
+	if err := aggregateCashFlows(pipeline, &typeStatList); err != nil {
+		util.Logger.Errorw("aggregate by flow_type failed", "error", err)
+		return []model.CashFlowTypeStat{}
+	}
+	return typeStatList
+}
+
+// GetCashFlowCategoryStats shares split cash flows among the categories of their lines
+func (CashFlowMongoDbMapper) GetCashFlowCategoryStats(ownerPlainId string) []model.CashFlowCategoryStat {
+	pipeline := mongo.Pipeline{cashFlowOwnerStage(ownerPlainId)}
+	pipeline = append(pipeline, cashFlowCategoryLineStages()...)
+	pipeline = append(pipeline,
+		bson.D{primitive.E{Key: "$group", Value: bson.D{
+			primitive.E{Key: "_id", Value: bson.D{
+				primitive.E{Key: "category_id", Value: "$line.category_id"},
+				primitive.E{Key: "currency", Value: bson.M{"$ifNull": bson.A{"$currency", ""}}},
+			}},
+			primitive.E{Key: "count", Value: cashFlowLineCount()},
+			primitive.E{Key: "total_amount", Value: bson.M{"$sum": "$line.amount"}},
+		}}},
+		bson.D{primitive.E{Key: "$project", Value: bson.D{
+			primitive.E{Key: "_id", Value: 0},
+			primitive.E{Key: "category_id", Value: "$_id.category_id"},
+			primitive.E{Key: "currency", Value: "$_id.currency"},
+			primitive.E{Key: "count", Value: 1},
+			primitive.E{Key: "total_amount", Value: 1},
+		}}},
+		bson.D{primitive.E{Key: "$sort", Value: bson.D{
+			primitive.E{Key: "count", Value: -1},
+			primitive.E{Key: "category_id", Value: 1},
+			primitive.E{Key: "currency", Value: 1},
+		}}},
+	)
+
+	var categoryStatList []model.CashFlowCategoryStat
+	if err := aggregateCashFlows(pipeline, &categoryStatList); err != nil {
+		util.Logger.Errorw("aggregate by category_id failed", "error", err)
+		return []model.CashFlowCategoryStat{}
+	}
+	return categoryStatList
+}
+
+// GetCashFlowAccountStats groups by account and flow type,
+// records saved before accounts existed have no account_id and fall into the nil account.
+func (CashFlowMongoDbMapper) GetCashFlowAccountStats(ownerPlainId string) []model.CashFlowAccountStat {
+	pipeline := mongo.Pipeline{
+		cashFlowOwnerStage(ownerPlainId),
+		bson.D{primitive.E{Key: "$group", Value: bson.D{
+			primitive.E{Key: "_id", Value: bson.D{
+				primitive.E{Key: "account_id", Value: bson.M{"$ifNull": bson.A{"$account_id", primitive.NilObjectID}}},
+				primitive.E{Key: "flow_type", Value: "$flow_type"},
+			}},
+			primitive.E{Key: "count", Value: bson.M{"$sum": 1}},
+			primitive.E{Key: "total_amount", Value: bson.M{"$sum": "$amount"}},
+		}}},
+		bson.D{primitive.E{Key: "$project", Value: bson.D{
+			primitive.E{Key: "_id", Value: 0},
+			primitive.E{Key: "account_id", Value: "$_id.account_id"},
+			primitive.E{Key: "flow_type", Value: "$_id.flow_type"},
+			primitive.E{Key: "count", Value: 1},
+			primitive.E{Key: "total_amount", Value: 1},
+		}}},
+		bson.D{primitive.E{Key: "$sort", Value: bson.D{
+			primitive.E{Key: "account_id", Value: 1},
+			primitive.E{Key: "flow_type", Value: 1},
+		}}},
+	}
+
+	var accountStatList []model.CashFlowAccountStat
+	if err := aggregateCashFlows(pipeline, &accountStatList); err != nil {
+		util.Logger.Errorw("aggregate by account_id failed", "error", err)
+		return []model.CashFlowAccountStat{}
+	}
+	return accountStatList
+}
+
+func (CashFlowMongoDbMapper) GetCashFlowDateSpan(ownerPlainId string) (earliest, latest time.Time) {
+	pipeline := mongo.Pipeline{
+		cashFlowOwnerStage(ownerPlainId),
+		bson.D{primitive.E{Key: "$group", Value: bson.D{
+			primitive.E{Key: "_id", Value: nil},
+			primitive.E{Key: "earliest", Value: bson.M{"$min": "$belongs_date"}},
+			primitive.E{Key: "latest", Value: bson.M{"$max": "$belongs_date"}},
+		}}},
+	}
+
+	var dateSpanList []struct {
+		Earliest time.Time `bson:"earliest"`
+		Latest   time.Time `bson:"latest"`
+	}
+	if err := aggregateCashFlows(pipeline, &dateSpanList); err != nil {
+		util.Logger.Errorw("aggregate date span failed", "error", err)
+		return time.Time{}, time.Time{}
+	}
+
+	// An empty ledger yields no group at all
+	if len(dateSpanList) == 0 {
+		return time.Time{}, time.Time{}
+	}
+	return dateSpanList[0].Earliest, dateSpanList[0].Latest
+}
+
+// GetCashFlowSummaryByDateRange groups the range by flow type, currency and category, split cash flows by their lines,
+// and joins the category name in the same pipeline.
+func (CashFlowMongoDbMapper) GetCashFlowSummaryByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowSummaryStat {
+	pipeline := mongo.Pipeline{
+		bson.D{primitive.E{Key: "$match", Value: bson.D{
+			database.OwnerFilterInMongoDB(ownerPlainId),
+			primitive.E{Key: "belongs_date", Value: bson.M{
+				"$gte": from,
+				"$lte": to,
+			}},
+		}}},
+	}
+	pipeline = append(pipeline, cashFlowCategoryLineStages()...)
+	pipeline = append(pipeline,
+		bson.D{primitive.E{Key: "$group", Value: bson.D{
+			primitive.E{Key: "_id", Value: bson.D{
+				primitive.E{Key: "flow_type", Value: "$flow_type"},
+				primitive.E{Key: "currency", Value: bson.M{"$ifNull": bson.A{"$currency", ""}}},
+				primitive.E{Key: "category_id", Value: "$line.category_id"},
+			}},
+			primitive.E{Key: "count", Value: cashFlowLineCount()},
+			primitive.E{Key: "total_amount", Value: bson.M{"$sum": "$line.amount"}},
+		}}},
+		bson.D{primitive.E{Key: "$lookup", Value: bson.D{
+			primitive.E{Key: "from", Value: database.CategoryTableName},
+			primitive.E{Key: "localField", Value: "_id.category_id"},
+			primitive.E{Key: "foreignField", Value: "_id"},
+			primitive.E{Key: "as", Value: "category"},
+		}}},
+		bson.D{primitive.E{Key: "$project", Value: bson.D{
+			primitive.E{Key: "_id", Value: 0},
+			primitive.E{Key: "flow_type", Value: "$_id.flow_type"},
+			primitive.E{Key: "currency", Value: "$_id.currency"},
+			primitive.E{Key: "category_id", Value: "$_id.category_id"},
+			primitive.E{Key: "category_name", Value: bson.M{
+				"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$category.name", 0}}, ""},
+			}},
+			primitive.E{Key: "count", Value: 1},
+			primitive.E{Key: "total_amount", Value: 1},
+		}}},
+	)
+
+	var summaryStatList []model.CashFlowSummaryStat
+	if err := aggregateCashFlows(pipeline, &summaryStatList); err != nil {
+		util.Logger.Errorw("aggregate summary failed", "error", err)
+		return []model.CashFlowSummaryStat{}
+	}
+	return summaryStatList
+}
+
+func (CashFlowMongoDbMapper) GetCashFlowsByTag(ownerPlainId, tag string, limit, offset int) []model.CashFlowEntity {
+	// Matching a scalar against the tags array matches any element
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+		primitive.E{Key: "tags", Value: tag},
+	}
+	return findMongoDbCashFlows(filter, limit, offset)
+}
+
+func (CashFlowMongoDbMapper) CountCashFlowsByTag(ownerPlainId, tag string) int64 {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+		primitive.E{Key: "tags", Value: tag},
+	}
+
+	database.OpenMongoDbConnection(database.CashFlowTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+// QueryCashFlows matches the text against the text index on description (see "manage indexes"),
+// every word as a phrase so that all of them have to appear
+func (CashFlowMongoDbMapper) QueryCashFlows(ownerPlainId string, querySpec model.CashFlowQuerySpec) []model.CashFlowEntity {
+	database.OpenMongoDbConnection(database.CashFlowTableName)
+	defer database.CloseMongoDbConnection()
+
+	collection := database.GetMongoCollection(database.CashFlowTableName)
+	filter := cashFlowQueryFilter(ownerPlainId, querySpec)
+
+	ctx := context.TODO()
+	findOptions := database.GetFindOptions()
+	if querySpec.Limit > 0 {
+		findOptions.SetLimit(int64(querySpec.Limit))
+	}
+	if querySpec.Offset > 0 {
+		findOptions.SetSkip(int64(querySpec.Offset))
+	}
+
+	direction := 1
+	if querySpec.SortDesc {
+		direction = -1
+	}
+	switch {
+	case querySpec.CursorApplies():
+		// Closest to the cursor first, a backward page is turned around after reading
+		operator, scanDirection := "$lt", -1
+		if querySpec.ScansAscending() {
+			operator, scanDirection = "$gt", 1
+		}
+		filter = append(filter, primitive.E{Key: "$and", Value: bson.A{
+			bson.M{"$or": bson.A{
+				bson.M{"belongs_date": bson.M{operator: querySpec.Cursor.BelongsDate}},
+				bson.M{"belongs_date": querySpec.Cursor.BelongsDate, "_id": bson.M{operator: querySpec.Cursor.Id}},
+			}},
+		}})
+		findOptions.SetSort(bson.D{
+			primitive.E{Key: "belongs_date", Value: scanDirection},
+			primitive.E{Key: "_id", Value: scanDirection},
+		})
+	case querySpec.SortBy == model.CashFlowSortByAmount:
+		findOptions.SetSort(bson.D{
+			primitive.E{Key: "amount", Value: direction},
+			primitive.E{Key: "belongs_date", Value: direction},
+			primitive.E{Key: "_id", Value: direction},
+		})
+	case querySpec.SortBy == model.CashFlowSortByRelevance && len(querySpec.TextTerms()) > 0:
+		textScore := bson.M{"$meta": "textScore"}
+		findOptions.SetProjection(bson.M{"score": textScore})
+		findOptions.SetSort(bson.D{
+			primitive.E{Key: "score", Value: textScore},
+			primitive.E{Key: "belongs_date", Value: -1},
+			primitive.E{Key: "_id", Value: -1},
+		})
+	case querySpec.SortBy == model.CashFlowSortByRelevance:
+		findOptions.SetSort(bson.D{
+			primitive.E{Key: "belongs_date", Value: -1},
+			primitive.E{Key: "_id", Value: -1},
+		})
+	default:
+		findOptions.SetSort(bson.D{
+			primitive.E{Key: "belongs_date", Value: direction},
+			primitive.E{Key: "_id", Value: direction},
+		})
+	}
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		util.Logger.Errorw("query by spec failed", "error", err)
+		return []model.CashFlowEntity{}
+	}
+	defer cursor.Close(ctx)
+
+	var targetEntityList []model.CashFlowEntity
+	for cursor.Next(ctx) {
+		var bsonM bson.M
+		if err := cursor.Decode(&bsonM); err != nil {
+			util.Logger.Errorw("decode failed", "error", err)
+			continue
+		}
+		delete(bsonM, "score")
+		targetEntityList = append(targetEntityList, convertBsonM2CashFlowEntity(bsonM))
+	}
+	if querySpec.CursorApplies() && querySpec.Cursor.Backward {
+		reverseCashFlows(targetEntityList)
+	}
+	return targetEntityList
+}
+
+func (CashFlowMongoDbMapper) CountCashFlowsByQuery(ownerPlainId string, querySpec model.CashFlowQuerySpec) int64 {
+	database.OpenMongoDbConnection(database.CashFlowTableName)
+	defer database.CloseMongoDbConnection()
+
+	collection := database.GetMongoCollection(database.CashFlowTableName)
+	count, err := collection.CountDocuments(context.TODO(), cashFlowQueryFilter(ownerPlainId, querySpec))
+	if err != nil {
+		util.Logger.Errorw("count by spec failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+// GetCashFlowTagSummaryByDateRange groups the tagged cash flows of the range by flow type, currency and tag,
+// a cash flow with several tags counts once toward each of them.
+func (CashFlowMongoDbMapper) GetCashFlowTagSummaryByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowTagSummaryStat {
+	pipeline := mongo.Pipeline{
+		bson.D{primitive.E{Key: "$match", Value: bson.D{
+			database.OwnerFilterInMongoDB(ownerPlainId),
+			primitive.E{Key: "belongs_date", Value: bson.M{
+				"$gte": from,
+				"$lte": to,
+			}},
+		}}},
+		bson.D{primitive.E{Key: "$unwind", Value: "$tags"}},
+		bson.D{primitive.E{Key: "$group", Value: bson.D{
+			primitive.E{Key: "_id", Value: bson.D{
+				primitive.E{Key: "flow_type", Value: "$flow_type"},
+				primitive.E{Key: "currency", Value: bson.M{"$ifNull": bson.A{"$currency", ""}}},
+				primitive.E{Key: "tag", Value: "$tags"},
+			}},
+			primitive.E{Key: "count", Value: bson.M{"$sum": 1}},
+			primitive.E{Key: "total_amount", Value: bson.M{"$sum": "$amount"}},
+		}}},
+		bson.D{primitive.E{Key: "$project", Value: bson.D{
+			primitive.E{Key: "_id", Value: 0},
+			primitive.E{Key: "flow_type", Value: "$_id.flow_type"},
+			primitive.E{Key: "currency", Value: "$_id.currency"},
+			primitive.E{Key: "tag", Value: "$_id.tag"},
+			primitive.E{Key: "count", Value: 1},
+			primitive.E{Key: "total_amount", Value: 1},
+		}}},
+		bson.D{primitive.E{Key: "$sort", Value: bson.D{
+			primitive.E{Key: "tag", Value: 1},
+			primitive.E{Key: "flow_type", Value: 1},
+			primitive.E{Key: "currency", Value: 1},
+		}}},
+	}
+
+	var tagStatList []model.CashFlowTagSummaryStat
+	if err := aggregateCashFlows(pipeline, &tagStatList); err != nil {
+		util.Logger.Errorw("aggregate tag summary failed", "error", err)
+		return []model.CashFlowTagSummaryStat{}
+	}
+	return tagStatList
+}
+
+// cashFlowCategoryFilter matches the cash flows booked to the category, directly or on one of their split lines
+func cashFlowCategoryFilter(categoryObjectId primitive.ObjectID) bson.D {
+	return bson.D{
+		primitive.E{Key: "$or", Value: bson.A{
+			bson.M{"category_id": categoryObjectId},
+			bson.M{"splits.category_id": categoryObjectId},
+		}},
+	}
+}
+
+// cashFlowQueryFilter combines the filters of a query spec within the owner's ledger,
+// a $text query needs the text index on description
+func cashFlowQueryFilter(ownerPlainId string, querySpec model.CashFlowQuerySpec) bson.D {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+	if textTerms := querySpec.TextTerms(); len(textTerms) > 0 {
+		filter = append(filter, primitive.E{Key: "$text", Value: bson.M{
+			"$search": "\"" + strings.Join(textTerms, "\" \"") + "\"",
+		}})
+	}
+
+	dateRange := bson.M{}
+	if !querySpec.FromDate.IsZero() {
+		dateRange["$gte"] = querySpec.FromDate
+	}
+	if !querySpec.ToDate.IsZero() {
+		dateRange["$lte"] = querySpec.ToDate
+	}
+	if len(dateRange) > 0 {
+		filter = append(filter, primitive.E{Key: "belongs_date", Value: dateRange})
+	}
+
+	amountRange := bson.M{}
+	if !querySpec.MinAmount.IsZero() {
+		amountRange["$gte"] = querySpec.MinAmount
+	}
+	if !querySpec.MaxAmount.IsZero() {
+		amountRange["$lte"] = querySpec.MaxAmount
+	}
+	if len(amountRange) > 0 {
+		filter = append(filter, primitive.E{Key: "amount", Value: amountRange})
+	}
+
+	if querySpec.FlowType != "" {
+		filter = append(filter, primitive.E{Key: "flow_type", Value: querySpec.FlowType})
+	}
+	if !querySpec.AccountId.IsZero() {
+		filter = append(filter, primitive.E{Key: "account_id", Value: querySpec.AccountId})
+	}
+	if len(querySpec.CategoryIds) > 0 {
+		filter = append(filter, primitive.E{Key: "$or", Value: bson.A{
+			bson.M{"category_id": bson.M{"$in": querySpec.CategoryIds}},
+			bson.M{"splits.category_id": bson.M{"$in": querySpec.CategoryIds}},
+		}})
+	}
+	if len(querySpec.Tags) > 0 {
+		filter = append(filter, primitive.E{Key: "tags", Value: bson.M{"$all": querySpec.Tags}})
+	}
+	return filter
+}
+
+// cashFlowOwnerStage keeps the cash flows of the owner's ledger for the following stages
+func cashFlowOwnerStage(ownerPlainId string) bson.D {
+	return bson.D{primitive.E{Key: "$match", Value: bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}}}
+}
+
+// cashFlowCategoryLineStages turns every cash flow into its category lines under "line",
+// a cash flow that is not split becomes a single line of its own category and amount.
+func cashFlowCategoryLineStages() mongo.Pipeline {
+	return mongo.Pipeline{
+		bson.D{primitive.E{Key: "$addFields", Value: bson.D{
+			primitive.E{Key: "line", Value: bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$splits", bson.A{}}}}, 0}},
+				"$splits",
+				bson.A{bson.M{"category_id": "$category_id", "amount": "$amount"}},
+			}}},
+		}}},
+		bson.D{primitive.E{Key: "$unwind", Value: bson.D{
+			primitive.E{Key: "path", Value: "$line"},
+			primitive.E{Key: "includeArrayIndex", Value: "line_no"},
+		}}},
+	}
+}
+
+// cashFlowLineCount counts each cash flow once, on its first line, so split cash flows are not counted twice
+func cashFlowLineCount() bson.M {
+	return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$line_no", 0}}, 1, 0}}}
+}
+
+// findMongoDbCashFlows returns a page of the matching cash flows, newest first;
+// _id keeps the order stable between pages
+func findMongoDbCashFlows(filter bson.D, limit, offset int) []model.CashFlowEntity {
+	database.OpenMongoDbConnection(database.CashFlowTableName)
+	defer database.CloseMongoDbConnection()
+
+	collection := database.GetMongoCollection(database.CashFlowTableName)
 
 	ctx := context.TODO()
 	findOptions := database.GetFindOptions()
@@ -313,12 +829,14 @@ func (CashFlowMongoDbMapper) GetAllCashFlows(limit, offset int) []model.CashFlow
 	if offset > 0 {
 		findOptions.SetSkip(int64(offset))
 	}
-	// Sort by belongs_date descending (newest first)
-	findOptions.SetSort(bson.D{primitive.E{Key: "belongs_date", Value: -1}})
+	findOptions.SetSort(bson.D{
+		primitive.E{Key: "belongs_date", Value: -1},
+		primitive.E{Key: "_id", Value: -1},
+	})
 
 	cursor, err := collection.Find(ctx, filter, findOptions)
 	if err != nil {
-		util.Logger.Errorw("query all failed", "error", err)
+		util.Logger.Errorw("query cash_flows failed", "error", err)
 		return []model.CashFlowEntity{}
 	}
 	defer cursor.Close(ctx)
@@ -332,17 +850,21 @@ func (CashFlowMongoDbMapper) GetAllCashFlows(limit, offset int) []model.CashFlow
 		}
 		targetEntityList = append(targetEntityList, convertBsonM2CashFlowEntity(bsonM))
 	}
-
 	return targetEntityList
 }
 
-func (CashFlowMongoDbMapper) CountAllCashFlows() int64 {
-	filter := bson.D{}
+// aggregateCashFlows runs the pipeline on cash_flow and decodes every result into resultList
+func aggregateCashFlows(pipeline mongo.Pipeline, resultList interface{}) error {
+	collection := database.GetMongoCollection(database.CashFlowTableName)
 
-	database.OpenMongoDbConnection(database.CashFlowTableName)
-	defer database.CloseMongoDbConnection()
+	ctx := context.TODO()
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
 
-	return database.CountInMongoDB(filter)
+	return cursor.All(ctx, resultList)
 }
 
 func convertCashFlowEntity2BsonD(entity model.CashFlowEntity) bson.D {
@@ -353,12 +875,21 @@ func convertCashFlowEntity2BsonD(entity model.CashFlowEntity) bson.D {
 
 	return bson.D{
 		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "owner_id", Value: entity.OwnerId},
 		primitive.E{Key: "category_id", Value: entity.CategoryId},
+		primitive.E{Key: "account_id", Value: entity.AccountId},
+		primitive.E{Key: "linked_id", Value: entity.LinkedId},
+		primitive.E{Key: "payee_id", Value: entity.PayeeId},
 		primitive.E{Key: "belongs_date", Value: entity.BelongsDate},
 		primitive.E{Key: "flow_type", Value: entity.FlowType},
 		primitive.E{Key: "amount", Value: entity.Amount},
+		primitive.E{Key: "currency", Value: entity.Currency},
 		primitive.E{Key: "description", Value: entity.Description},
+		primitive.E{Key: "tags", Value: entity.Tags},
+		primitive.E{Key: "splits", Value: entity.Splits},
 		primitive.E{Key: "remark", Value: entity.Remark},
+		primitive.E{Key: "created_by", Value: entity.CreatedBy},
+		primitive.E{Key: "modified_by", Value: entity.ModifiedBy},
 		primitive.E{Key: "create_time", Value: entity.CreateTime},
 		primitive.E{Key: "modify_time", Value: entity.ModifyTime},
 	}
@@ -366,12 +897,7 @@ func convertCashFlowEntity2BsonD(entity model.CashFlowEntity) bson.D {
 
 func convertBsonM2CashFlowEntity(bsonM bson.M) model.CashFlowEntity {
 	var newEntity model.CashFlowEntity
-	bsonBytes, err := bson.Marshal(bsonM)
-	if err != nil {
-		util.Logger.Errorln(err)
-		panic(err)
-	}
-	if err = bson.Unmarshal(bsonBytes, &newEntity); err != nil {
+	if err := database.DecodeBsonM(bsonM, &newEntity); err != nil {
 		util.Logger.Errorln(err)
 		panic(err)
 	}
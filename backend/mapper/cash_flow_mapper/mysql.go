@@ -3,11 +3,14 @@ package cash_flow_mapper
 import (
 	"bytes"
 	"database/sql"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/macar-x/cashlens/model"
 	"github.com/macar-x/cashlens/util"
 	"github.com/macar-x/cashlens/util/database"
+	"github.com/shopspring/decimal"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -15,7 +18,7 @@ type CashFlowMySqlMapper struct{}
 
 func (CashFlowMySqlMapper) GetCashFlowByObjectId(plainId string) model.CashFlowEntity {
 	var sqlString bytes.Buffer
-	sqlString.WriteString("SELECT ID, CATEGORY_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, DESCRIPTION FROM ")
+	sqlString.WriteString("SELECT ID, OWNER_ID, CATEGORY_ID, ACCOUNT_ID, LINKED_ID, PAYEE_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, CURRENCY, DESCRIPTION, REMARK, CREATED_BY, MODIFIED_BY, CREATE_TIME, MODIFY_TIME, " + cashFlowTagsColumn + " FROM ")
 	sqlString.WriteString(database.CashFlowTableName)
 	sqlString.WriteString(" WHERE ID = ? ")
 
@@ -32,12 +35,12 @@ func (CashFlowMySqlMapper) GetCashFlowByObjectId(plainId string) model.CashFlowE
 		cashFlowEntity = convertRow2CashFlowEntity(rows)
 		break
 	}
-	return cashFlowEntity
+	return attachCashFlowSplits(connection, []model.CashFlowEntity{cashFlowEntity})[0]
 }
 
 func (CashFlowMySqlMapper) GetCashFlowsByObjectIdArray(plainIdList []string) []model.CashFlowEntity {
 	var sqlString bytes.Buffer
-	sqlString.WriteString("SELECT ID, CATEGORY_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, DESCRIPTION FROM ")
+	sqlString.WriteString("SELECT ID, OWNER_ID, CATEGORY_ID, ACCOUNT_ID, LINKED_ID, PAYEE_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, CURRENCY, DESCRIPTION, REMARK, CREATED_BY, MODIFIED_BY, CREATE_TIME, MODIFY_TIME, " + cashFlowTagsColumn + " FROM ")
 	sqlString.WriteString(database.CashFlowTableName)
 	sqlString.WriteString(" WHERE ID in ")
 	// fixme: pass the params by ? instead to avoid SQL inject.
@@ -55,19 +58,19 @@ func (CashFlowMySqlMapper) GetCashFlowsByObjectIdArray(plainIdList []string) []m
 	for rows.Next() {
 		targetEntityList = append(targetEntityList, convertRow2CashFlowEntity(rows))
 	}
-	return targetEntityList
+	return attachCashFlowSplits(connection, targetEntityList)
 }
 
-func (CashFlowMySqlMapper) GetCashFlowsByBelongsDate(belongsDate time.Time) []model.CashFlowEntity {
+func (CashFlowMySqlMapper) GetCashFlowsByBelongsDate(ownerPlainId string, belongsDate time.Time) []model.CashFlowEntity {
 	var sqlString bytes.Buffer
-	sqlString.WriteString("SELECT ID, CATEGORY_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, DESCRIPTION FROM ")
+	sqlString.WriteString("SELECT ID, OWNER_ID, CATEGORY_ID, ACCOUNT_ID, LINKED_ID, PAYEE_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, CURRENCY, DESCRIPTION, REMARK, CREATED_BY, MODIFIED_BY, CREATE_TIME, MODIFY_TIME, " + cashFlowTagsColumn + " FROM ")
 	sqlString.WriteString(database.CashFlowTableName)
-	sqlString.WriteString(" WHERE BELONGS_DATE = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND BELONGS_DATE = ? ")
 
 	connection := database.GetMySqlConnection()
 	defer database.CloseMySqlConnection()
 
-	rows, err := connection.Query(sqlString.String(), util.FormatDateToStringWithDash(belongsDate))
+	rows, err := connection.Query(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), util.FormatDateToStringWithDash(belongsDate))
 	if err != nil {
 		util.Logger.Errorw("query failed", "error", err)
 	}
@@ -76,19 +79,19 @@ func (CashFlowMySqlMapper) GetCashFlowsByBelongsDate(belongsDate time.Time) []mo
 	for rows.Next() {
 		targetEntityList = append(targetEntityList, convertRow2CashFlowEntity(rows))
 	}
-	return targetEntityList
+	return attachCashFlowSplits(connection, targetEntityList)
 }
 
-func (CashFlowMySqlMapper) GetCashFlowsByDateRange(from, to time.Time) []model.CashFlowEntity {
+func (CashFlowMySqlMapper) GetCashFlowsByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowEntity {
 	var sqlString bytes.Buffer
-	sqlString.WriteString("SELECT ID, CATEGORY_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, DESCRIPTION FROM ")
+	sqlString.WriteString("SELECT ID, OWNER_ID, CATEGORY_ID, ACCOUNT_ID, LINKED_ID, PAYEE_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, CURRENCY, DESCRIPTION, REMARK, CREATED_BY, MODIFIED_BY, CREATE_TIME, MODIFY_TIME, " + cashFlowTagsColumn + " FROM ")
 	sqlString.WriteString(database.CashFlowTableName)
-	sqlString.WriteString(" WHERE BELONGS_DATE BETWEEN ? AND ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND BELONGS_DATE BETWEEN ? AND ? ")
 
 	connection := database.GetMySqlConnection()
 	defer database.CloseMySqlConnection()
 
-	rows, err := connection.Query(sqlString.String(),
+	rows, err := connection.Query(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(),
 		util.FormatDateToStringWithDash(from),
 		util.FormatDateToStringWithDash(to))
 	if err != nil {
@@ -99,19 +102,21 @@ func (CashFlowMySqlMapper) GetCashFlowsByDateRange(from, to time.Time) []model.C
 	for rows.Next() {
 		targetEntityList = append(targetEntityList, convertRow2CashFlowEntity(rows))
 	}
-	return targetEntityList
+	return attachCashFlowSplits(connection, targetEntityList)
 }
 
 func (CashFlowMySqlMapper) GetCashFlowsByCategoryId(categoryPlainId string) []model.CashFlowEntity {
 	var sqlString bytes.Buffer
-	sqlString.WriteString("SELECT ID, CATEGORY_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, DESCRIPTION FROM ")
+	sqlString.WriteString("SELECT ID, OWNER_ID, CATEGORY_ID, ACCOUNT_ID, LINKED_ID, PAYEE_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, CURRENCY, DESCRIPTION, REMARK, CREATED_BY, MODIFIED_BY, CREATE_TIME, MODIFY_TIME, " + cashFlowTagsColumn + " FROM ")
 	sqlString.WriteString(database.CashFlowTableName)
-	sqlString.WriteString(" WHERE CATEGORY_ID = ? ")
+	sqlString.WriteString(" WHERE CATEGORY_ID = ? OR ID IN (SELECT CASH_FLOW_ID FROM ")
+	sqlString.WriteString(database.CashFlowSplitTableName)
+	sqlString.WriteString(" WHERE CATEGORY_ID = ?) ")
 
 	connection := database.GetMySqlConnection()
 	defer database.CloseMySqlConnection()
 
-	rows, err := connection.Query(sqlString.String(), categoryPlainId)
+	rows, err := connection.Query(sqlString.String(), categoryPlainId, categoryPlainId)
 	if err != nil {
 		util.Logger.Errorw("query failed", "error", err)
 	}
@@ -120,19 +125,19 @@ func (CashFlowMySqlMapper) GetCashFlowsByCategoryId(categoryPlainId string) []mo
 	for rows.Next() {
 		targetEntityList = append(targetEntityList, convertRow2CashFlowEntity(rows))
 	}
-	return targetEntityList
+	return attachCashFlowSplits(connection, targetEntityList)
 }
 
-func (CashFlowMySqlMapper) GetCashFlowsByExactDesc(description string) []model.CashFlowEntity {
+func (CashFlowMySqlMapper) GetCashFlowsByExactDesc(ownerPlainId, description string) []model.CashFlowEntity {
 	var sqlString bytes.Buffer
-	sqlString.WriteString("SELECT ID, CATEGORY_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, DESCRIPTION FROM ")
+	sqlString.WriteString("SELECT ID, OWNER_ID, CATEGORY_ID, ACCOUNT_ID, LINKED_ID, PAYEE_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, CURRENCY, DESCRIPTION, REMARK, CREATED_BY, MODIFIED_BY, CREATE_TIME, MODIFY_TIME, " + cashFlowTagsColumn + " FROM ")
 	sqlString.WriteString(database.CashFlowTableName)
-	sqlString.WriteString(" WHERE DESCRIPTION = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND DESCRIPTION = ? ")
 
 	connection := database.GetMySqlConnection()
 	defer database.CloseMySqlConnection()
 
-	rows, err := connection.Query(sqlString.String(), description)
+	rows, err := connection.Query(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), description)
 	if err != nil {
 		util.Logger.Errorw("query failed", "error", err)
 	}
@@ -141,19 +146,19 @@ func (CashFlowMySqlMapper) GetCashFlowsByExactDesc(description string) []model.C
 	for rows.Next() {
 		targetEntityList = append(targetEntityList, convertRow2CashFlowEntity(rows))
 	}
-	return targetEntityList
+	return attachCashFlowSplits(connection, targetEntityList)
 }
 
-func (CashFlowMySqlMapper) GetCashFlowsByFuzzyDesc(description string) []model.CashFlowEntity {
+func (CashFlowMySqlMapper) GetCashFlowsByFuzzyDesc(ownerPlainId, description string) []model.CashFlowEntity {
 	var sqlString bytes.Buffer
-	sqlString.WriteString("SELECT ID, CATEGORY_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, DESCRIPTION FROM ")
+	sqlString.WriteString("SELECT ID, OWNER_ID, CATEGORY_ID, ACCOUNT_ID, LINKED_ID, PAYEE_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, CURRENCY, DESCRIPTION, REMARK, CREATED_BY, MODIFIED_BY, CREATE_TIME, MODIFY_TIME, " + cashFlowTagsColumn + " FROM ")
 	sqlString.WriteString(database.CashFlowTableName)
-	sqlString.WriteString(" WHERE DESCRIPTION LIKE ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND DESCRIPTION LIKE ? ")
 
 	connection := database.GetMySqlConnection()
 	defer database.CloseMySqlConnection()
 
-	rows, err := connection.Query(sqlString.String(), "%"+description+"%")
+	rows, err := connection.Query(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), "%"+description+"%")
 	if err != nil {
 		util.Logger.Errorw("query failed", "error", err)
 	}
@@ -162,19 +167,21 @@ func (CashFlowMySqlMapper) GetCashFlowsByFuzzyDesc(description string) []model.C
 	for rows.Next() {
 		targetEntityList = append(targetEntityList, convertRow2CashFlowEntity(rows))
 	}
-	return targetEntityList
+	return attachCashFlowSplits(connection, targetEntityList)
 }
 
 func (CashFlowMySqlMapper) CountCashFLowsByCategoryId(categoryPlainId string) int64 {
 	var sqlString bytes.Buffer
 	sqlString.WriteString("SELECT COUNT(1) FROM ")
 	sqlString.WriteString(database.CashFlowTableName)
-	sqlString.WriteString(" WHERE CATEGORY_ID = ? ")
+	sqlString.WriteString(" WHERE CATEGORY_ID = ? OR ID IN (SELECT CASH_FLOW_ID FROM ")
+	sqlString.WriteString(database.CashFlowSplitTableName)
+	sqlString.WriteString(" WHERE CATEGORY_ID = ?) ")
 
 	connection := database.GetMySqlConnection()
 	defer database.CloseMySqlConnection()
 
-	rows, err := connection.Query(sqlString.String(), categoryPlainId)
+	rows, err := connection.Query(sqlString.String(), categoryPlainId, categoryPlainId)
 	if err != nil {
 		util.Logger.Errorw("query failed", "error", err)
 	}
@@ -188,21 +195,62 @@ func (CashFlowMySqlMapper) CountCashFLowsByCategoryId(categoryPlainId string) in
 	return rowsAffected
 }
 
+func (CashFlowMySqlMapper) CountCashFlowsByAccountId(accountPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE ACCOUNT_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	err := connection.QueryRow(sqlString.String(), accountPlainId).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count failed", "error", err)
+		return -1
+	}
+	return count
+}
+
+func (CashFlowMySqlMapper) CountCashFlowsByPayeeId(payeePlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE PAYEE_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	err := connection.QueryRow(sqlString.String(), payeePlainId).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count failed", "error", err)
+		return -1
+	}
+	return count
+}
+
 func (CashFlowMySqlMapper) InsertCashFlowByEntity(newEntity model.CashFlowEntity) string {
-	operatingTime := time.Now()
-	newEntity.CreateTime = operatingTime
-	newEntity.ModifyTime = operatingTime
+	newEntity = fillOperatingTime(newEntity, time.Now())
 
 	var sqlString bytes.Buffer
 	sqlString.WriteString("INSERT ")
 	sqlString.WriteString(database.CashFlowTableName)
 	sqlString.WriteString(" SET ID = ?, ")
+	sqlString.WriteString(" OWNER_ID = ?, ")
 	sqlString.WriteString(" CATEGORY_ID = ?, ")
+	sqlString.WriteString(" ACCOUNT_ID = ?, ")
+	sqlString.WriteString(" LINKED_ID = ?, ")
+	sqlString.WriteString(" PAYEE_ID = ?, ")
 	sqlString.WriteString(" BELONGS_DATE = ?, ")
 	sqlString.WriteString(" FLOW_TYPE = ?, ")
 	sqlString.WriteString(" AMOUNT = ?, ")
+	sqlString.WriteString(" CURRENCY = ?, ")
 	sqlString.WriteString(" DESCRIPTION = ?, ")
 	sqlString.WriteString(" REMARK = ?, ")
+	sqlString.WriteString(" CREATED_BY = ?, ")
+	sqlString.WriteString(" MODIFIED_BY = ?, ")
 	sqlString.WriteString(" CREATE_TIME = ?, ")
 	sqlString.WriteString(" MODIFY_TIME = ? ")
 
@@ -214,9 +262,10 @@ func (CashFlowMySqlMapper) InsertCashFlowByEntity(newEntity model.CashFlowEntity
 		util.Logger.Errorw("insert failed", "error", err)
 	}
 
-	newPlainId := primitive.NewObjectID().Hex()
-	result, err := statement.Exec(newPlainId, newEntity.CategoryId.Hex(), newEntity.BelongsDate, newEntity.FlowType,
-		newEntity.Amount, newEntity.Description, newEntity.Remark, operatingTime, operatingTime)
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := statement.Exec(newPlainId, newEntity.OwnerId.Hex(), newEntity.CategoryId.Hex(), newEntity.AccountId.Hex(), newEntity.LinkedId.Hex(),
+		newEntity.PayeeId.Hex(), newEntity.BelongsDate, newEntity.FlowType, newEntity.Amount, newEntity.Currency, newEntity.Description, newEntity.Remark,
+		newEntity.CreatedBy.Hex(), newEntity.ModifiedBy.Hex(), newEntity.CreateTime, newEntity.ModifyTime)
 	if err != nil {
 		util.Logger.Errorw("insert failed", "error", err)
 	}
@@ -226,6 +275,15 @@ func (CashFlowMySqlMapper) InsertCashFlowByEntity(newEntity model.CashFlowEntity
 		// fixme: maybe we should have a rollback here.
 		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
 	}
+
+	err = insertCashFlowTags(connection, []string{newPlainId}, []model.CashFlowEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert tags failed", "error", err)
+	}
+	err = insertCashFlowSplits(connection, []string{newPlainId}, []model.CashFlowEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert splits failed", "error", err)
+	}
 	return newPlainId
 }
 
@@ -238,20 +296,22 @@ func (CashFlowMySqlMapper) BulkInsertCashFlows(entities []model.CashFlowEntity)
 	var sqlString bytes.Buffer
 	sqlString.WriteString("INSERT INTO ")
 	sqlString.WriteString(database.CashFlowTableName)
-	sqlString.WriteString(" (ID, CATEGORY_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, DESCRIPTION, REMARK, CREATE_TIME, MODIFY_TIME) VALUES ")
+	sqlString.WriteString(" (ID, OWNER_ID, CATEGORY_ID, ACCOUNT_ID, LINKED_ID, PAYEE_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, CURRENCY, DESCRIPTION, REMARK, CREATED_BY, MODIFIED_BY, CREATE_TIME, MODIFY_TIME) VALUES ")
 
 	ids := make([]string, len(entities))
-	values := make([]interface{}, 0, len(entities)*9)
+	values := make([]interface{}, 0, len(entities)*16)
 
 	for i, entity := range entities {
 		if i > 0 {
 			sqlString.WriteString(", ")
 		}
-		sqlString.WriteString("(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		sqlString.WriteString("(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 
-		ids[i] = primitive.NewObjectID().Hex()
-		values = append(values, ids[i], entity.CategoryId.Hex(), entity.BelongsDate, entity.FlowType,
-			entity.Amount, entity.Description, entity.Remark, operatingTime, operatingTime)
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		values = append(values, ids[i], entity.OwnerId.Hex(), entity.CategoryId.Hex(), entity.AccountId.Hex(), entity.LinkedId.Hex(),
+			entity.PayeeId.Hex(), entity.BelongsDate, entity.FlowType, entity.Amount, entity.Currency, entity.Description, entity.Remark,
+			entity.CreatedBy.Hex(), entity.ModifiedBy.Hex(), entity.CreateTime, entity.ModifyTime)
 	}
 
 	connection := database.GetMySqlConnection()
@@ -274,6 +334,15 @@ func (CashFlowMySqlMapper) BulkInsertCashFlows(entities []model.CashFlowEntity)
 		util.Logger.Errorw("bulk insert incomplete", "error", err, "expected", len(entities), "actual", rowsAffected)
 	}
 
+	if err = insertCashFlowTags(connection, ids, entities); err != nil {
+		util.Logger.Errorw("bulk insert tags failed", "error", err)
+		return nil, err
+	}
+	if err = insertCashFlowSplits(connection, ids, entities); err != nil {
+		util.Logger.Errorw("bulk insert splits failed", "error", err)
+		return nil, err
+	}
+
 	util.Logger.Infow("bulk insert successful", "count", len(ids))
 	return ids, nil
 }
@@ -291,8 +360,10 @@ func (CashFlowMySqlMapper) UpdateCashFlowByEntity(plainId string, updatedEntity
 		return model.CashFlowEntity{}
 	}
 
-	// Update fields from updatedEntity while preserving ID and CreateTime
+	// Update fields from updatedEntity while preserving ID, owner, creator and CreateTime
 	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreatedBy = targetEntity.CreatedBy
 	updatedEntity.CreateTime = targetEntity.CreateTime
 	updatedEntity.ModifyTime = time.Now()
 
@@ -300,11 +371,16 @@ func (CashFlowMySqlMapper) UpdateCashFlowByEntity(plainId string, updatedEntity
 	sqlString.WriteString("UPDATE ")
 	sqlString.WriteString(database.CashFlowTableName)
 	sqlString.WriteString(" SET CATEGORY_ID = ?, ")
+	sqlString.WriteString(" ACCOUNT_ID = ?, ")
+	sqlString.WriteString(" LINKED_ID = ?, ")
+	sqlString.WriteString(" PAYEE_ID = ?, ")
 	sqlString.WriteString(" BELONGS_DATE = ?, ")
 	sqlString.WriteString(" FLOW_TYPE = ?, ")
 	sqlString.WriteString(" AMOUNT = ?, ")
+	sqlString.WriteString(" CURRENCY = ?, ")
 	sqlString.WriteString(" DESCRIPTION = ?, ")
 	sqlString.WriteString(" REMARK = ?, ")
+	sqlString.WriteString(" MODIFIED_BY = ?, ")
 	sqlString.WriteString(" MODIFY_TIME = ? ")
 	sqlString.WriteString(" WHERE ID = ? ")
 
@@ -316,8 +392,9 @@ func (CashFlowMySqlMapper) UpdateCashFlowByEntity(plainId string, updatedEntity
 		util.Logger.Errorw("update failed", "error", err)
 	}
 
-	result, err := statement.Exec(updatedEntity.CategoryId.Hex(), updatedEntity.BelongsDate, updatedEntity.FlowType,
-		updatedEntity.Amount, updatedEntity.Description, updatedEntity.Remark, updatedEntity.ModifyTime, plainId)
+	result, err := statement.Exec(updatedEntity.CategoryId.Hex(), updatedEntity.AccountId.Hex(), updatedEntity.LinkedId.Hex(),
+		updatedEntity.PayeeId.Hex(), updatedEntity.BelongsDate, updatedEntity.FlowType, updatedEntity.Amount, updatedEntity.Currency, updatedEntity.Description,
+		updatedEntity.Remark, updatedEntity.ModifiedBy.Hex(), updatedEntity.ModifyTime, plainId)
 	if err != nil {
 		util.Logger.Errorw("update failed", "error", err)
 	}
@@ -327,6 +404,13 @@ func (CashFlowMySqlMapper) UpdateCashFlowByEntity(plainId string, updatedEntity
 		// fixme: maybe we should have a rollback here.
 		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
 	}
+
+	if err = replaceCashFlowTags(connection, plainId, updatedEntity.Tags); err != nil {
+		util.Logger.Errorw("update tags failed", "error", err)
+	}
+	if err = replaceCashFlowSplits(connection, plainId, updatedEntity.Splits); err != nil {
+		util.Logger.Errorw("update splits failed", "error", err)
+	}
 	return updatedEntity
 }
 
@@ -345,6 +429,13 @@ func (CashFlowMySqlMapper) DeleteCashFlowByObjectId(plainId string) model.CashFl
 	connection := database.GetMySqlConnection()
 	defer database.CloseMySqlConnection()
 
+	if err := replaceCashFlowTags(connection, plainId, nil); err != nil {
+		util.Logger.Errorw("delete tags failed", "error", err)
+	}
+	if err := replaceCashFlowSplits(connection, plainId, nil); err != nil {
+		util.Logger.Errorw("delete splits failed", "error", err)
+	}
+
 	statement, err := connection.Prepare(sqlString.String())
 	if err != nil {
 		util.Logger.Errorw("delete failed", "error", err)
@@ -363,8 +454,8 @@ func (CashFlowMySqlMapper) DeleteCashFlowByObjectId(plainId string) model.CashFl
 	return targetEntity
 }
 
-func (CashFlowMySqlMapper) DeleteCashFlowByBelongsDate(belongsDate time.Time) []model.CashFlowEntity {
-	cashFlowList := INSTANCE.GetCashFlowsByBelongsDate(belongsDate)
+func (CashFlowMySqlMapper) DeleteCashFlowByBelongsDate(ownerPlainId string, belongsDate time.Time) []model.CashFlowEntity {
+	cashFlowList := INSTANCE.GetCashFlowsByBelongsDate(ownerPlainId, belongsDate)
 	if cashFlowList == nil {
 		util.Logger.Infoln("no cash_flow(s) found")
 		return cashFlowList
@@ -373,17 +464,24 @@ func (CashFlowMySqlMapper) DeleteCashFlowByBelongsDate(belongsDate time.Time) []
 	var sqlString bytes.Buffer
 	sqlString.WriteString("DELETE FROM ")
 	sqlString.WriteString(database.CashFlowTableName)
-	sqlString.WriteString(" WHERE BELONGS_DATE = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND BELONGS_DATE = ? ")
 
 	connection := database.GetMySqlConnection()
 	defer database.CloseMySqlConnection()
 
+	if err := deleteCashFlowDetailsByBelongsDate(connection, database.CashFlowTagTableName, ownerPlainId, belongsDate); err != nil {
+		util.Logger.Errorw("delete tags failed", "error", err)
+	}
+	if err := deleteCashFlowDetailsByBelongsDate(connection, database.CashFlowSplitTableName, ownerPlainId, belongsDate); err != nil {
+		util.Logger.Errorw("delete splits failed", "error", err)
+	}
+
 	statement, err := connection.Prepare(sqlString.String())
 	if err != nil {
 		util.Logger.Errorw("delete failed", "error", err)
 	}
 
-	result, err := statement.Exec(util.FormatDateToStringWithDash(belongsDate))
+	result, err := statement.Exec(util.ConvertOwner2ObjectId(ownerPlainId).Hex(), util.FormatDateToStringWithDash(belongsDate))
 	if err != nil {
 		util.Logger.Errorw("delete failed", "error", err)
 	}
@@ -396,38 +494,114 @@ func (CashFlowMySqlMapper) DeleteCashFlowByBelongsDate(belongsDate time.Time) []
 	return cashFlowList
 }
 
+func (CashFlowMySqlMapper) DeleteAllCashFlows() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	if _, err := connection.Exec("DELETE FROM " + database.CashFlowTagTableName); err != nil {
+		util.Logger.Errorw("delete all tags failed", "error", err)
+		return 0, err
+	}
+	if _, err := connection.Exec("DELETE FROM " + database.CashFlowSplitTableName); err != nil {
+		util.Logger.Errorw("delete all splits failed", "error", err)
+		return 0, err
+	}
+
+	result, err := connection.Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all cash_flows deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (CashFlowMySqlMapper) AssignUnownedCashFlows(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned cash_flows failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func (CashFlowMySqlMapper) GetAllCashFlows(limit, offset int) []model.CashFlowEntity {
 	var sqlString bytes.Buffer
-	sqlString.WriteString("SELECT ID, CATEGORY_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, DESCRIPTION FROM ")
+	sqlString.WriteString("SELECT ID, OWNER_ID, CATEGORY_ID, ACCOUNT_ID, LINKED_ID, PAYEE_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, CURRENCY, DESCRIPTION, REMARK, CREATED_BY, MODIFIED_BY, CREATE_TIME, MODIFY_TIME, " + cashFlowTagsColumn + " FROM ")
 	sqlString.WriteString(database.CashFlowTableName)
-	sqlString.WriteString(" ORDER BY BELONGS_DATE DESC ")
+	sqlString.WriteString(" ORDER BY BELONGS_DATE DESC, ID DESC ")
 
+	var args []interface{}
 	if limit > 0 {
 		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		args = append(args, limit, offset)
 	}
 
 	connection := database.GetMySqlConnection()
 	defer database.CloseMySqlConnection()
 
-	var rows *sql.Rows
-	var err error
+	rows, err := connection.Query(sqlString.String(), args...)
+	if err != nil {
+		util.Logger.Errorw("query all failed", "error", err)
+		return []model.CashFlowEntity{}
+	}
+
+	var targetEntityList []model.CashFlowEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2CashFlowEntity(rows))
+	}
+	return attachCashFlowSplits(connection, targetEntityList)
+}
+
+func (CashFlowMySqlMapper) GetCashFlowsByOwnerId(ownerPlainId string, limit, offset int) []model.CashFlowEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT ID, OWNER_ID, CATEGORY_ID, ACCOUNT_ID, LINKED_ID, PAYEE_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, CURRENCY, DESCRIPTION, REMARK, CREATED_BY, MODIFIED_BY, CREATE_TIME, MODIFY_TIME, " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY BELONGS_DATE DESC, ID DESC ")
 
+	args := []interface{}{util.ConvertOwner2ObjectId(ownerPlainId).Hex()}
 	if limit > 0 {
-		rows, err = connection.Query(sqlString.String(), limit, offset)
-	} else {
-		rows, err = connection.Query(sqlString.String())
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		args = append(args, limit, offset)
 	}
 
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString.String(), args...)
 	if err != nil {
-		util.Logger.Errorw("query all failed", "error", err)
+		util.Logger.Errorw("query by owner failed", "error", err)
 		return []model.CashFlowEntity{}
 	}
+	defer rows.Close()
 
 	var targetEntityList []model.CashFlowEntity
 	for rows.Next() {
 		targetEntityList = append(targetEntityList, convertRow2CashFlowEntity(rows))
 	}
-	return targetEntityList
+	return attachCashFlowSplits(connection, targetEntityList)
 }
 
 func (CashFlowMySqlMapper) CountAllCashFlows() int64 {
@@ -453,25 +627,670 @@ func (CashFlowMySqlMapper) CountAllCashFlows() int64 {
 	return count
 }
 
+func (CashFlowMySqlMapper) GetCashFlowTypeStats(ownerPlainId string) []model.CashFlowTypeStat {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT FLOW_TYPE, CURRENCY, COUNT(1), COALESCE(SUM(AMOUNT), 0) FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" GROUP BY FLOW_TYPE, CURRENCY ORDER BY FLOW_TYPE, CURRENCY ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex())
+	if err != nil {
+		util.Logger.Errorw("aggregate by flow_type failed", "error", err)
+		return []model.CashFlowTypeStat{}
+	}
+	defer rows.Close()
+
+	var typeStatList []model.CashFlowTypeStat
+	for rows.Next() {
+		var typeStat model.CashFlowTypeStat
+		if err = rows.Scan(&typeStat.FlowType, &typeStat.Currency, &typeStat.Count, &typeStat.TotalAmount); err != nil {
+			util.Logger.Errorw("parse flow_type stat failed", "error", err)
+			continue
+		}
+		typeStatList = append(typeStatList, typeStat)
+	}
+	return typeStatList
+}
+
+// GetCashFlowCategoryStats shares split cash flows among the categories of their lines
+func (CashFlowMySqlMapper) GetCashFlowCategoryStats(ownerPlainId string) []model.CashFlowCategoryStat {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT L.CATEGORY_ID, L.CURRENCY, SUM(L.IS_COUNTED), COALESCE(SUM(L.AMOUNT), 0) FROM ")
+	sqlString.WriteString(cashFlowCategoryLinesTable("CF.OWNER_ID = ?"))
+	sqlString.WriteString(" GROUP BY L.CATEGORY_ID, L.CURRENCY ORDER BY SUM(L.IS_COUNTED) DESC, L.CATEGORY_ID, L.CURRENCY ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	rows, err := connection.Query(sqlString.String(), ownerId, ownerId)
+	if err != nil {
+		util.Logger.Errorw("aggregate by category_id failed", "error", err)
+		return []model.CashFlowCategoryStat{}
+	}
+	defer rows.Close()
+
+	var categoryStatList []model.CashFlowCategoryStat
+	for rows.Next() {
+		var categoryId string
+		var categoryStat model.CashFlowCategoryStat
+		if err = rows.Scan(&categoryId, &categoryStat.Currency, &categoryStat.Count, &categoryStat.TotalAmount); err != nil {
+			util.Logger.Errorw("parse category stat failed", "error", err)
+			continue
+		}
+		categoryStat.CategoryId = util.Convert2ObjectId(categoryId)
+		categoryStatList = append(categoryStatList, categoryStat)
+	}
+	return categoryStatList
+}
+
+func (CashFlowMySqlMapper) GetCashFlowAccountStats(ownerPlainId string) []model.CashFlowAccountStat {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT ACCOUNT_ID, FLOW_TYPE, COUNT(1), COALESCE(SUM(AMOUNT), 0) FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" GROUP BY ACCOUNT_ID, FLOW_TYPE ORDER BY ACCOUNT_ID, FLOW_TYPE ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex())
+	if err != nil {
+		util.Logger.Errorw("aggregate by account_id failed", "error", err)
+		return []model.CashFlowAccountStat{}
+	}
+	defer rows.Close()
+
+	var accountStatList []model.CashFlowAccountStat
+	for rows.Next() {
+		var accountId sql.NullString
+		var accountStat model.CashFlowAccountStat
+		if err = rows.Scan(&accountId, &accountStat.FlowType, &accountStat.Count, &accountStat.TotalAmount); err != nil {
+			util.Logger.Errorw("parse account stat failed", "error", err)
+			continue
+		}
+		accountStat.AccountId = convertNullString2ObjectId(accountId)
+		accountStatList = append(accountStatList, accountStat)
+	}
+	return accountStatList
+}
+
+func (CashFlowMySqlMapper) GetCashFlowDateSpan(ownerPlainId string) (earliest, latest time.Time) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT MIN(BELONGS_DATE), MAX(BELONGS_DATE) FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	// MIN/MAX of an empty ledger are NULL
+	var earliestDate, latestDate sql.NullString
+	err := connection.QueryRow(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&earliestDate, &latestDate)
+	if err != nil {
+		util.Logger.Errorw("query date span failed", "error", err)
+		return time.Time{}, time.Time{}
+	}
+	if !earliestDate.Valid || !latestDate.Valid {
+		return time.Time{}, time.Time{}
+	}
+	return util.FormatDateTimeFromString(earliestDate.String), util.FormatDateTimeFromString(latestDate.String)
+}
+
+// GetCashFlowSummaryByDateRange groups the range by flow type, currency and category, split cash flows by their lines,
+// and joins the category name in the same query.
+func (CashFlowMySqlMapper) GetCashFlowSummaryByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowSummaryStat {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT L.FLOW_TYPE, L.CURRENCY, L.CATEGORY_ID, COALESCE(C.NAME, ''), SUM(L.IS_COUNTED), COALESCE(SUM(L.AMOUNT), 0) FROM ")
+	sqlString.WriteString(cashFlowCategoryLinesTable("CF.OWNER_ID = ? AND CF.BELONGS_DATE BETWEEN ? AND ?"))
+	sqlString.WriteString(" LEFT JOIN ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" C ON C.ID = L.CATEGORY_ID ")
+	sqlString.WriteString(" GROUP BY L.FLOW_TYPE, L.CURRENCY, L.CATEGORY_ID, C.NAME ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	fromDate := util.FormatDateToStringWithDash(from)
+	toDate := util.FormatDateToStringWithDash(to)
+	rows, err := connection.Query(sqlString.String(), ownerId, fromDate, toDate, ownerId, fromDate, toDate)
+	if err != nil {
+		util.Logger.Errorw("aggregate summary failed", "error", err)
+		return []model.CashFlowSummaryStat{}
+	}
+	defer rows.Close()
+
+	var summaryStatList []model.CashFlowSummaryStat
+	for rows.Next() {
+		var categoryId string
+		var summaryStat model.CashFlowSummaryStat
+		err = rows.Scan(&summaryStat.FlowType, &summaryStat.Currency, &categoryId, &summaryStat.CategoryName,
+			&summaryStat.Count, &summaryStat.TotalAmount)
+		if err != nil {
+			util.Logger.Errorw("parse summary stat failed", "error", err)
+			continue
+		}
+		summaryStat.CategoryId = util.Convert2ObjectId(categoryId)
+		summaryStatList = append(summaryStatList, summaryStat)
+	}
+	return summaryStatList
+}
+
+func (CashFlowMySqlMapper) GetCashFlowsByTag(ownerPlainId, tag string, limit, offset int) []model.CashFlowEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT ID, OWNER_ID, CATEGORY_ID, ACCOUNT_ID, LINKED_ID, PAYEE_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, CURRENCY, DESCRIPTION, REMARK, CREATED_BY, MODIFIED_BY, CREATE_TIME, MODIFY_TIME, " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND ID IN (SELECT CASH_FLOW_ID FROM ")
+	sqlString.WriteString(database.CashFlowTagTableName)
+	sqlString.WriteString(" WHERE TAG = ?) ")
+	sqlString.WriteString(" ORDER BY BELONGS_DATE DESC, ID DESC ")
+
+	args := []interface{}{util.ConvertOwner2ObjectId(ownerPlainId).Hex(), tag}
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		args = append(args, limit, offset)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString.String(), args...)
+	if err != nil {
+		util.Logger.Errorw("query by tag failed", "error", err)
+		return []model.CashFlowEntity{}
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.CashFlowEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2CashFlowEntity(rows))
+	}
+	return attachCashFlowSplits(connection, targetEntityList)
+}
+
+func (CashFlowMySqlMapper) CountCashFlowsByTag(ownerPlainId, tag string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CashFlowTagTableName)
+	sqlString.WriteString(" T JOIN ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" CF ON CF.ID = T.CASH_FLOW_ID ")
+	sqlString.WriteString(" WHERE CF.OWNER_ID = ? AND T.TAG = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), tag).Scan(&count); err != nil {
+		util.Logger.Errorw("count by tag failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+// QueryCashFlows matches the text against the FULLTEXT index on DESCRIPTION, every word as a required prefix
+func (CashFlowMySqlMapper) QueryCashFlows(ownerPlainId string, querySpec model.CashFlowQuerySpec) []model.CashFlowEntity {
+	conditionList, args := cashFlowQueryConditions(ownerPlainId, querySpec)
+	conditionList, args = appendCursorCondition(querySpec, conditionList, args)
+	relevanceExpression := ""
+	if booleanQuery := mySqlBooleanQuery(querySpec.TextTerms()); booleanQuery != "" {
+		relevanceExpression = "MATCH(DESCRIPTION) AGAINST(? IN BOOLEAN MODE)"
+		conditionList = append(conditionList, relevanceExpression)
+		args = append(args, booleanQuery)
+		if querySpec.SortBy == model.CashFlowSortByRelevance {
+			args = append(args, booleanQuery)
+		}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT ID, OWNER_ID, CATEGORY_ID, ACCOUNT_ID, LINKED_ID, PAYEE_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, CURRENCY, DESCRIPTION, REMARK, CREATED_BY, MODIFIED_BY, CREATE_TIME, MODIFY_TIME, " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(cashFlowQueryWhere(conditionList))
+	sqlString.WriteString(cashFlowQueryOrder(querySpec, relevanceExpression))
+	if querySpec.Limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		args = append(args, querySpec.Limit, querySpec.Offset)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString.String(), args...)
+	if err != nil {
+		util.Logger.Errorw("query by spec failed", "error", err)
+		return []model.CashFlowEntity{}
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.CashFlowEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2CashFlowEntity(rows))
+	}
+	if querySpec.CursorApplies() && querySpec.Cursor.Backward {
+		reverseCashFlows(targetEntityList)
+	}
+	return attachCashFlowSplits(connection, targetEntityList)
+}
+
+func (CashFlowMySqlMapper) CountCashFlowsByQuery(ownerPlainId string, querySpec model.CashFlowQuerySpec) int64 {
+	conditionList, args := cashFlowQueryConditions(ownerPlainId, querySpec)
+	if booleanQuery := mySqlBooleanQuery(querySpec.TextTerms()); booleanQuery != "" {
+		conditionList = append(conditionList, "MATCH(DESCRIPTION) AGAINST(? IN BOOLEAN MODE)")
+		args = append(args, booleanQuery)
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(cashFlowQueryWhere(conditionList))
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow(sqlString.String(), args...).Scan(&count); err != nil {
+		util.Logger.Errorw("count by spec failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+// GetCashFlowTagSummaryByDateRange groups the tagged cash flows of the range by flow type, currency and tag
+func (CashFlowMySqlMapper) GetCashFlowTagSummaryByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowTagSummaryStat {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT CF.FLOW_TYPE, CF.CURRENCY, T.TAG, COUNT(1), COALESCE(SUM(CF.AMOUNT), 0) FROM ")
+	sqlString.WriteString(database.CashFlowTagTableName)
+	sqlString.WriteString(" T JOIN ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" CF ON CF.ID = T.CASH_FLOW_ID ")
+	sqlString.WriteString(" WHERE CF.OWNER_ID = ? AND CF.BELONGS_DATE BETWEEN ? AND ? ")
+	sqlString.WriteString(" GROUP BY CF.FLOW_TYPE, CF.CURRENCY, T.TAG ORDER BY T.TAG, CF.FLOW_TYPE, CF.CURRENCY ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(),
+		util.FormatDateToStringWithDash(from),
+		util.FormatDateToStringWithDash(to))
+	if err != nil {
+		util.Logger.Errorw("aggregate tag summary failed", "error", err)
+		return []model.CashFlowTagSummaryStat{}
+	}
+	defer rows.Close()
+
+	var tagStatList []model.CashFlowTagSummaryStat
+	for rows.Next() {
+		var tagStat model.CashFlowTagSummaryStat
+		if err = rows.Scan(&tagStat.FlowType, &tagStat.Currency, &tagStat.Tag, &tagStat.Count, &tagStat.TotalAmount); err != nil {
+			util.Logger.Errorw("parse tag summary stat failed", "error", err)
+			continue
+		}
+		tagStatList = append(tagStatList, tagStat)
+	}
+	return tagStatList
+}
+
+// cashFlowTagsColumn folds the tags of each cash flow into one column, MySQL and SQLite
+// both join GROUP_CONCAT values with a comma, which a tag never contains.
+var cashFlowTagsColumn = "(SELECT GROUP_CONCAT(TAG) FROM " + database.CashFlowTagTableName +
+	" WHERE CASH_FLOW_ID = " + database.CashFlowTableName + ".ID) AS TAGS"
+
+// sqlExecutor is satisfied by both a connection and a transaction
+type sqlExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertCashFlowTags writes the tag rows of the entities in one statement, plainIdList is in entities order
+func insertCashFlowTags(executor sqlExecutor, plainIdList []string, entities []model.CashFlowEntity) error {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.CashFlowTagTableName)
+	sqlString.WriteString(" (CASH_FLOW_ID, TAG) VALUES ")
+
+	var values []interface{}
+	for i, entity := range entities {
+		for _, tag := range entity.Tags {
+			if len(values) > 0 {
+				sqlString.WriteString(", ")
+			}
+			sqlString.WriteString("(?, ?)")
+			values = append(values, plainIdList[i], tag)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	_, err := executor.Exec(sqlString.String(), values...)
+	return err
+}
+
+// replaceCashFlowTags drops the stored tags of one cash flow and writes the given ones instead
+func replaceCashFlowTags(executor sqlExecutor, plainId string, tags []string) error {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.CashFlowTagTableName)
+	sqlString.WriteString(" WHERE CASH_FLOW_ID = ? ")
+
+	if _, err := executor.Exec(sqlString.String(), plainId); err != nil {
+		return err
+	}
+	return insertCashFlowTags(executor, []string{plainId}, []model.CashFlowEntity{{Tags: tags}})
+}
+
+// deleteCashFlowDetailsByBelongsDate drops the tag or split rows of every cash flow the owner has on the date
+func deleteCashFlowDetailsByBelongsDate(executor sqlExecutor, detailTableName, ownerPlainId string, belongsDate time.Time) error {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(detailTableName)
+	sqlString.WriteString(" WHERE CASH_FLOW_ID IN (SELECT ID FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND BELONGS_DATE = ?) ")
+
+	_, err := executor.Exec(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), util.FormatDateToStringWithDash(belongsDate))
+	return err
+}
+
+// splitQueryBatchSize keeps the bound parameters of one split lookup well below the SQLite limit
+const splitQueryBatchSize = 500
+
+// sqlQueryer is satisfied by both a connection and a transaction
+type sqlQueryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// attachCashFlowSplits loads the split lines of the listed cash flows, entities without an id are skipped
+func attachCashFlowSplits(queryer sqlQueryer, entities []model.CashFlowEntity) []model.CashFlowEntity {
+	entityIndexMap := make(map[string]int, len(entities))
+	var plainIdList []interface{}
+	for i, entity := range entities {
+		if entity.Id == primitive.NilObjectID {
+			continue
+		}
+		entityIndexMap[entity.Id.Hex()] = i
+		plainIdList = append(plainIdList, entity.Id.Hex())
+	}
+
+	for start := 0; start < len(plainIdList); start += splitQueryBatchSize {
+		end := start + splitQueryBatchSize
+		if end > len(plainIdList) {
+			end = len(plainIdList)
+		}
+
+		var sqlString bytes.Buffer
+		sqlString.WriteString("SELECT CASH_FLOW_ID, CATEGORY_ID, AMOUNT, DESCRIPTION FROM ")
+		sqlString.WriteString(database.CashFlowSplitTableName)
+		sqlString.WriteString(" WHERE CASH_FLOW_ID IN (")
+		sqlString.WriteString(strings.TrimSuffix(strings.Repeat("?, ", end-start), ", "))
+		sqlString.WriteString(") ORDER BY CASH_FLOW_ID, LINE_NO ")
+
+		rows, err := queryer.Query(sqlString.String(), plainIdList[start:end]...)
+		if err != nil {
+			util.Logger.Errorw("query splits failed", "error", err)
+			return entities
+		}
+		for rows.Next() {
+			var cashFlowId string
+			var categoryId string
+			var split model.CashFlowSplit
+			if err = rows.Scan(&cashFlowId, &categoryId, &split.Amount, &split.Description); err != nil {
+				util.Logger.Errorw("parse split failed", "error", err)
+				continue
+			}
+			split.CategoryId = util.Convert2ObjectId(categoryId)
+			entityIndex := entityIndexMap[cashFlowId]
+			entities[entityIndex].Splits = append(entities[entityIndex].Splits, split)
+		}
+		rows.Close()
+	}
+	return entities
+}
+
+// insertCashFlowSplits writes the split lines of the entities in one statement, plainIdList is in entities order
+func insertCashFlowSplits(executor sqlExecutor, plainIdList []string, entities []model.CashFlowEntity) error {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.CashFlowSplitTableName)
+	sqlString.WriteString(" (CASH_FLOW_ID, LINE_NO, CATEGORY_ID, AMOUNT, DESCRIPTION) VALUES ")
+
+	var values []interface{}
+	for i, entity := range entities {
+		for lineNo, split := range entity.Splits {
+			if len(values) > 0 {
+				sqlString.WriteString(", ")
+			}
+			sqlString.WriteString("(?, ?, ?, ?, ?)")
+			values = append(values, plainIdList[i], lineNo, split.CategoryId.Hex(), split.Amount, split.Description)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	_, err := executor.Exec(sqlString.String(), values...)
+	return err
+}
+
+// replaceCashFlowSplits drops the stored split lines of one cash flow and writes the given ones instead
+func replaceCashFlowSplits(executor sqlExecutor, plainId string, splits []model.CashFlowSplit) error {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.CashFlowSplitTableName)
+	sqlString.WriteString(" WHERE CASH_FLOW_ID = ? ")
+
+	if _, err := executor.Exec(sqlString.String(), plainId); err != nil {
+		return err
+	}
+	return insertCashFlowSplits(executor, []string{plainId}, []model.CashFlowEntity{{Splits: splits}})
+}
+
+// cashFlowCategoryLinesTable lists FLOW_TYPE, CATEGORY_ID, AMOUNT and IS_COUNTED per category line as table L.
+// A cash flow that is not split is one line, a split one brings its split lines and is counted on line 0 only,
+// so the line counts still add up to the number of cash flows. condition filters the cash flows (alias CF)
+// in both halves of the union, its arguments have to be bound twice.
+func cashFlowCategoryLinesTable(condition string) string {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("(SELECT CF.FLOW_TYPE, CF.CURRENCY, CF.CATEGORY_ID, CF.AMOUNT, 1 AS IS_COUNTED FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" CF WHERE NOT EXISTS (SELECT 1 FROM ")
+	sqlString.WriteString(database.CashFlowSplitTableName)
+	sqlString.WriteString(" S WHERE S.CASH_FLOW_ID = CF.ID) ")
+	if condition != "" {
+		sqlString.WriteString(" AND " + condition)
+	}
+	sqlString.WriteString(" UNION ALL SELECT CF.FLOW_TYPE, CF.CURRENCY, S.CATEGORY_ID, S.AMOUNT, CASE WHEN S.LINE_NO = 0 THEN 1 ELSE 0 END FROM ")
+	sqlString.WriteString(database.CashFlowSplitTableName)
+	sqlString.WriteString(" S JOIN ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" CF ON CF.ID = S.CASH_FLOW_ID ")
+	if condition != "" {
+		sqlString.WriteString(" WHERE " + condition)
+	}
+	sqlString.WriteString(") L ")
+	return sqlString.String()
+}
+
+// cashFlowQueryConditions lists the MySQL and SQLite conditions of a query spec within the owner's ledger,
+// all but the text which each database matches its own way. Dates compare as YYYY-MM-DD text,
+// like GetCashFlowsByDateRange.
+func cashFlowQueryConditions(ownerPlainId string, querySpec model.CashFlowQuerySpec) ([]string, []interface{}) {
+	conditionList := []string{"OWNER_ID = ?"}
+	args := []interface{}{util.ConvertOwner2ObjectId(ownerPlainId).Hex()}
+	if !querySpec.FromDate.IsZero() {
+		conditionList = append(conditionList, "BELONGS_DATE >= ?")
+		args = append(args, util.FormatDateToStringWithDash(querySpec.FromDate))
+	}
+	if !querySpec.ToDate.IsZero() {
+		conditionList = append(conditionList, "BELONGS_DATE <= ?")
+		args = append(args, util.FormatDateToStringWithDash(querySpec.ToDate))
+	}
+	if !querySpec.MinAmount.IsZero() {
+		conditionList = append(conditionList, "AMOUNT >= ?")
+		args = append(args, querySpec.MinAmount.String())
+	}
+	if !querySpec.MaxAmount.IsZero() {
+		conditionList = append(conditionList, "AMOUNT <= ?")
+		args = append(args, querySpec.MaxAmount.String())
+	}
+	if querySpec.FlowType != "" {
+		conditionList = append(conditionList, "FLOW_TYPE = ?")
+		args = append(args, querySpec.FlowType)
+	}
+	if !querySpec.AccountId.IsZero() {
+		conditionList = append(conditionList, "ACCOUNT_ID = ?")
+		args = append(args, querySpec.AccountId.Hex())
+	}
+	if len(querySpec.CategoryIds) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(querySpec.CategoryIds)), ", ")
+		conditionList = append(conditionList, "(CATEGORY_ID IN ("+placeholders+") OR ID IN (SELECT CASH_FLOW_ID FROM "+
+			database.CashFlowSplitTableName+" WHERE CATEGORY_ID IN ("+placeholders+")))")
+		var categoryArgs []interface{}
+		for _, categoryId := range querySpec.CategoryIds {
+			categoryArgs = append(categoryArgs, categoryId.Hex())
+		}
+		args = append(append(args, categoryArgs...), categoryArgs...)
+	}
+	for _, tag := range querySpec.Tags {
+		conditionList = append(conditionList, "ID IN (SELECT CASH_FLOW_ID FROM "+database.CashFlowTagTableName+" WHERE TAG = ?)")
+		args = append(args, tag)
+	}
+	return conditionList, args
+}
+
+// appendCursorCondition keeps the records past the cursor in the order they are read,
+// comparing (BELONGS_DATE, ID) as a pair. Nothing is added when the cursor does not apply.
+func appendCursorCondition(querySpec model.CashFlowQuerySpec, conditionList []string, args []interface{}) ([]string, []interface{}) {
+	if !querySpec.CursorApplies() {
+		return conditionList, args
+	}
+	operator := "<"
+	if querySpec.ScansAscending() {
+		operator = ">"
+	}
+	belongsDate := util.FormatDateToStringWithDash(querySpec.Cursor.BelongsDate)
+	conditionList = append(conditionList,
+		"(BELONGS_DATE "+operator+" ? OR (BELONGS_DATE = ? AND ID "+operator+" ?))")
+	return conditionList, append(args, belongsDate, belongsDate, querySpec.Cursor.Id.Hex())
+}
+
+func cashFlowQueryWhere(conditionList []string) string {
+	if len(conditionList) == 0 {
+		return " "
+	}
+	return " WHERE " + strings.Join(conditionList, " AND ") + " "
+}
+
+// cashFlowQueryOrder sorts a query, id last to keep pages stable. Relevance is always the most relevant
+// first and falls back to the newest first when the database has no relevanceExpression to sort by.
+func cashFlowQueryOrder(querySpec model.CashFlowQuerySpec, relevanceExpression string) string {
+	// Next to a cursor the closest records come first, a backward page is turned around after reading
+	if querySpec.CursorApplies() {
+		direction := " DESC"
+		if querySpec.ScansAscending() {
+			direction = " ASC"
+		}
+		return " ORDER BY BELONGS_DATE" + direction + ", ID" + direction + " "
+	}
+
+	direction := " ASC"
+	if querySpec.SortDesc {
+		direction = " DESC"
+	}
+	switch querySpec.SortBy {
+	case model.CashFlowSortByAmount:
+		return " ORDER BY AMOUNT" + direction + ", BELONGS_DATE" + direction + ", ID" + direction + " "
+	case model.CashFlowSortByRelevance:
+		if relevanceExpression == "" {
+			return " ORDER BY BELONGS_DATE DESC, ID DESC "
+		}
+		return " ORDER BY " + relevanceExpression + " DESC, BELONGS_DATE DESC, ID DESC "
+	}
+	return " ORDER BY BELONGS_DATE" + direction + ", ID" + direction + " "
+}
+
+// mySqlBooleanQuery requires every word as a prefix, e.g. "+coffee* +bean*". The words hold letters
+// and digits only, so none of them can carry a boolean mode operator.
+func mySqlBooleanQuery(textTerms []string) string {
+	var termList []string
+	for _, term := range textTerms {
+		termList = append(termList, "+"+term+"*")
+	}
+	return strings.Join(termList, " ")
+}
+
+// generatePlainId keeps a preset id (e.g. restored from backup) and generates one otherwise
+func generatePlainId(objectId primitive.ObjectID) string {
+	if objectId == primitive.NilObjectID {
+		return primitive.NewObjectID().Hex()
+	}
+	return objectId.Hex()
+}
+
 func convertRow2CashFlowEntity(rows *sql.Rows) model.CashFlowEntity {
 	var id string
+	var ownerId string
 	var categoryId string
+	var accountId sql.NullString
+	var linkedId sql.NullString
+	var payeeId sql.NullString
 	var belongsDate string
 	var flowType string
-	var amount float64
+	var amount decimal.Decimal
+	var currency sql.NullString
 	var description string
-
-	err := rows.Scan(&id, &categoryId, &belongsDate, &flowType, &amount, &description)
+	var remark sql.NullString
+	var createdBy sql.NullString
+	var modifiedBy sql.NullString
+	var createTime string
+	var modifyTime string
+	var tags sql.NullString
+
+	err := rows.Scan(&id, &ownerId, &categoryId, &accountId, &linkedId, &payeeId, &belongsDate, &flowType, &amount, &currency, &description,
+		&remark, &createdBy, &modifiedBy, &createTime, &modifyTime, &tags)
 	if err != nil {
 		util.Logger.Errorw("covert into entity failed", "error", err)
 	}
 
 	return model.CashFlowEntity{
 		Id:          util.Convert2ObjectId(id),
+		OwnerId:     util.ConvertOwner2ObjectId(ownerId),
 		CategoryId:  util.Convert2ObjectId(categoryId),
-		BelongsDate: util.FormatDateFromStringWithDash(belongsDate),
+		AccountId:   convertNullString2ObjectId(accountId),
+		LinkedId:    convertNullString2ObjectId(linkedId),
+		PayeeId:     convertNullString2ObjectId(payeeId),
+		BelongsDate: util.FormatDateTimeFromString(belongsDate),
 		FlowType:    flowType,
 		Amount:      amount,
+		Currency:    currency.String,
 		Description: description,
+		Tags:        convertNullString2Tags(tags),
+		Remark:      remark.String,
+		CreatedBy:   convertNullString2ObjectId(createdBy),
+		ModifiedBy:  convertNullString2ObjectId(modifiedBy),
+		CreateTime:  util.FormatDateTimeFromString(createTime),
+		ModifyTime:  util.FormatDateTimeFromString(modifyTime),
+	}
+}
+
+// convertNullString2ObjectId treats a missing reference as the nil id
+func convertNullString2ObjectId(plainId sql.NullString) primitive.ObjectID {
+	if !plainId.Valid || plainId.String == "" {
+		return primitive.NilObjectID
+	}
+	return util.Convert2ObjectId(plainId.String)
+}
+
+// convertNullString2Tags splits the folded tags column, an untagged cash flow gets nil
+func convertNullString2Tags(tags sql.NullString) []string {
+	if !tags.Valid || tags.String == "" {
+		return nil
 	}
+	tagList := strings.Split(tags.String, ",")
+	sort.Strings(tagList)
+	return tagList
 }
@@ -0,0 +1,754 @@
+package cash_flow_mapper
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CashFlowSqliteMapper struct{}
+
+const sqliteCashFlowColumns = "ID, OWNER_ID, CATEGORY_ID, ACCOUNT_ID, LINKED_ID, PAYEE_ID, BELONGS_DATE, FLOW_TYPE, AMOUNT, CURRENCY, DESCRIPTION, REMARK, CREATED_BY, MODIFIED_BY, CREATE_TIME, MODIFY_TIME"
+
+func (CashFlowSqliteMapper) GetCashFlowByObjectId(plainId string) model.CashFlowEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCashFlowColumns + ", " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := querySqliteCashFlows(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.CashFlowEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (CashFlowSqliteMapper) GetCashFlowsByObjectIdArray(plainIdList []string) []model.CashFlowEntity {
+	if len(plainIdList) == 0 {
+		return nil
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCashFlowColumns + ", " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE ID IN (")
+	sqlString.WriteString(strings.TrimSuffix(strings.Repeat("?, ", len(plainIdList)), ", "))
+	sqlString.WriteString(") ")
+
+	args := make([]interface{}, len(plainIdList))
+	for i, plainId := range plainIdList {
+		args[i] = plainId
+	}
+	return querySqliteCashFlows(sqlString.String(), args...)
+}
+
+func (CashFlowSqliteMapper) GetCashFlowsByBelongsDate(ownerPlainId string, belongsDate time.Time) []model.CashFlowEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCashFlowColumns + ", " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND BELONGS_DATE = ? ")
+
+	return querySqliteCashFlows(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), util.FormatDateToStringWithDash(belongsDate))
+}
+
+func (CashFlowSqliteMapper) GetCashFlowsByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCashFlowColumns + ", " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND BELONGS_DATE BETWEEN ? AND ? ")
+
+	return querySqliteCashFlows(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(),
+		util.FormatDateToStringWithDash(from),
+		util.FormatDateToStringWithDash(to))
+}
+
+func (CashFlowSqliteMapper) GetCashFlowsByCategoryId(categoryPlainId string) []model.CashFlowEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCashFlowColumns + ", " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE CATEGORY_ID = ? OR ID IN (SELECT CASH_FLOW_ID FROM ")
+	sqlString.WriteString(database.CashFlowSplitTableName)
+	sqlString.WriteString(" WHERE CATEGORY_ID = ?) ")
+
+	return querySqliteCashFlows(sqlString.String(), categoryPlainId, categoryPlainId)
+}
+
+func (CashFlowSqliteMapper) GetCashFlowsByExactDesc(ownerPlainId, description string) []model.CashFlowEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCashFlowColumns + ", " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND DESCRIPTION = ? ")
+
+	return querySqliteCashFlows(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), description)
+}
+
+func (CashFlowSqliteMapper) GetCashFlowsByFuzzyDesc(ownerPlainId, description string) []model.CashFlowEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCashFlowColumns + ", " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND DESCRIPTION LIKE ? ")
+
+	return querySqliteCashFlows(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), "%"+description+"%")
+}
+
+func (CashFlowSqliteMapper) CountCashFLowsByCategoryId(categoryPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE CATEGORY_ID = ? OR ID IN (SELECT CASH_FLOW_ID FROM ")
+	sqlString.WriteString(database.CashFlowSplitTableName)
+	sqlString.WriteString(" WHERE CATEGORY_ID = ?) ")
+
+	var count int64
+	err := database.GetSqliteConnection().QueryRow(sqlString.String(), categoryPlainId, categoryPlainId).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count failed", "error", err)
+		return -1
+	}
+	return count
+}
+
+func (CashFlowSqliteMapper) CountCashFlowsByAccountId(accountPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE ACCOUNT_ID = ? ")
+
+	var count int64
+	err := database.GetSqliteConnection().QueryRow(sqlString.String(), accountPlainId).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count failed", "error", err)
+		return -1
+	}
+	return count
+}
+
+func (CashFlowSqliteMapper) CountCashFlowsByPayeeId(payeePlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE PAYEE_ID = ? ")
+
+	var count int64
+	err := database.GetSqliteConnection().QueryRow(sqlString.String(), payeePlainId).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count failed", "error", err)
+		return -1
+	}
+	return count
+}
+
+func (CashFlowSqliteMapper) InsertCashFlowByEntity(newEntity model.CashFlowEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" (" + sqliteCashFlowColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ")
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		convertCashFlowEntity2SqliteValues(newPlainId, newEntity)...)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+
+	err = insertCashFlowTags(database.GetSqliteConnection(), []string{newPlainId}, []model.CashFlowEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert tags failed", "error", err)
+	}
+	err = insertCashFlowSplits(database.GetSqliteConnection(), []string{newPlainId}, []model.CashFlowEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert splits failed", "error", err)
+	}
+	return newPlainId
+}
+
+func (CashFlowSqliteMapper) BulkInsertCashFlows(entities []model.CashFlowEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" (" + sqliteCashFlowColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ")
+
+	// One transaction keeps the batch all-or-nothing and avoids a disk sync per row
+	transaction, err := database.GetSqliteConnection().Begin()
+	if err != nil {
+		util.Logger.Errorw("bulk insert begin failed", "error", err)
+		return nil, err
+	}
+
+	statement, err := transaction.Prepare(sqlString.String())
+	if err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert prepare failed", "error", err)
+		return nil, err
+	}
+	defer statement.Close()
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		if _, err := statement.Exec(convertCashFlowEntity2SqliteValues(ids[i], entity)...); err != nil {
+			transaction.Rollback()
+			util.Logger.Errorw("bulk insert failed", "error", err)
+			return nil, err
+		}
+	}
+
+	if err := insertCashFlowTags(transaction, ids, entities); err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert tags failed", "error", err)
+		return nil, err
+	}
+	if err := insertCashFlowSplits(transaction, ids, entities); err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert splits failed", "error", err)
+		return nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		util.Logger.Errorw("bulk insert commit failed", "error", err)
+		return nil, err
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (CashFlowSqliteMapper) UpdateCashFlowByEntity(plainId string, updatedEntity model.CashFlowEntity) model.CashFlowEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("cash_flow's id is not acceptable")
+		return model.CashFlowEntity{}
+	}
+
+	targetEntity := INSTANCE.GetCashFlowByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("cash_flow is not exist")
+		return model.CashFlowEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner, creator and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreatedBy = targetEntity.CreatedBy
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" SET CATEGORY_ID = ?, ")
+	sqlString.WriteString(" ACCOUNT_ID = ?, ")
+	sqlString.WriteString(" LINKED_ID = ?, ")
+	sqlString.WriteString(" PAYEE_ID = ?, ")
+	sqlString.WriteString(" BELONGS_DATE = ?, ")
+	sqlString.WriteString(" FLOW_TYPE = ?, ")
+	sqlString.WriteString(" AMOUNT = ?, ")
+	sqlString.WriteString(" CURRENCY = ?, ")
+	sqlString.WriteString(" DESCRIPTION = ?, ")
+	sqlString.WriteString(" REMARK = ?, ")
+	sqlString.WriteString(" MODIFIED_BY = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		updatedEntity.CategoryId.Hex(), updatedEntity.AccountId.Hex(), updatedEntity.LinkedId.Hex(), updatedEntity.PayeeId.Hex(),
+		util.FormatDateToStringWithDash(updatedEntity.BelongsDate),
+		updatedEntity.FlowType, updatedEntity.Amount, updatedEntity.Currency, updatedEntity.Description, updatedEntity.Remark,
+		updatedEntity.ModifiedBy.Hex(), util.FormatDateTimeToString(updatedEntity.ModifyTime), plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.CashFlowEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+
+	if err = replaceCashFlowTags(database.GetSqliteConnection(), plainId, updatedEntity.Tags); err != nil {
+		util.Logger.Errorw("update tags failed", "error", err)
+	}
+	if err = replaceCashFlowSplits(database.GetSqliteConnection(), plainId, updatedEntity.Splits); err != nil {
+		util.Logger.Errorw("update splits failed", "error", err)
+	}
+	return updatedEntity
+}
+
+func (CashFlowSqliteMapper) DeleteCashFlowByObjectId(plainId string) model.CashFlowEntity {
+	targetEntity := INSTANCE.GetCashFlowByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("cash_flow is not exist")
+		return model.CashFlowEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	if err := replaceCashFlowTags(database.GetSqliteConnection(), plainId, nil); err != nil {
+		util.Logger.Errorw("delete tags failed", "error", err)
+	}
+	if err := replaceCashFlowSplits(database.GetSqliteConnection(), plainId, nil); err != nil {
+		util.Logger.Errorw("delete splits failed", "error", err)
+	}
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.CashFlowEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (CashFlowSqliteMapper) DeleteCashFlowByBelongsDate(ownerPlainId string, belongsDate time.Time) []model.CashFlowEntity {
+	cashFlowList := INSTANCE.GetCashFlowsByBelongsDate(ownerPlainId, belongsDate)
+	if cashFlowList == nil {
+		util.Logger.Infoln("no cash_flow(s) found")
+		return cashFlowList
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND BELONGS_DATE = ? ")
+
+	err := deleteCashFlowDetailsByBelongsDate(database.GetSqliteConnection(), database.CashFlowTagTableName, ownerPlainId, belongsDate)
+	if err != nil {
+		util.Logger.Errorw("delete tags failed", "error", err)
+	}
+	err = deleteCashFlowDetailsByBelongsDate(database.GetSqliteConnection(), database.CashFlowSplitTableName, ownerPlainId, belongsDate)
+	if err != nil {
+		util.Logger.Errorw("delete splits failed", "error", err)
+	}
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), util.FormatDateToStringWithDash(belongsDate))
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return nil
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != int64(len(cashFlowList)) {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return cashFlowList
+}
+
+func (CashFlowSqliteMapper) DeleteAllCashFlows() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+
+	if _, err := database.GetSqliteConnection().Exec("DELETE FROM " + database.CashFlowTagTableName); err != nil {
+		util.Logger.Errorw("delete all tags failed", "error", err)
+		return 0, err
+	}
+	if _, err := database.GetSqliteConnection().Exec("DELETE FROM " + database.CashFlowSplitTableName); err != nil {
+		util.Logger.Errorw("delete all splits failed", "error", err)
+		return 0, err
+	}
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all cash_flows deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (CashFlowSqliteMapper) AssignUnownedCashFlows(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned cash_flows failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (CashFlowSqliteMapper) GetAllCashFlows(limit, offset int) []model.CashFlowEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCashFlowColumns + ", " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" ORDER BY BELONGS_DATE DESC, ID DESC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteCashFlows(sqlString.String(), limit, offset)
+	}
+	return querySqliteCashFlows(sqlString.String())
+}
+
+func (CashFlowSqliteMapper) GetCashFlowsByOwnerId(ownerPlainId string, limit, offset int) []model.CashFlowEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCashFlowColumns + ", " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY BELONGS_DATE DESC, ID DESC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteCashFlows(sqlString.String(), ownerId, limit, offset)
+	}
+	return querySqliteCashFlows(sqlString.String(), ownerId)
+}
+
+func (CashFlowSqliteMapper) CountAllCashFlows() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (CashFlowSqliteMapper) GetCashFlowTypeStats(ownerPlainId string) []model.CashFlowTypeStat {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT FLOW_TYPE, CURRENCY, COUNT(1), " + sqliteSumInCents("AMOUNT") + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" GROUP BY FLOW_TYPE, CURRENCY ORDER BY FLOW_TYPE, CURRENCY ")
+
+	rows, err := database.GetSqliteConnection().Query(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex())
+	if err != nil {
+		util.Logger.Errorw("aggregate by flow_type failed", "error", err)
+		return []model.CashFlowTypeStat{}
+	}
+	defer rows.Close()
+
+	var typeStatList []model.CashFlowTypeStat
+	for rows.Next() {
+		var typeStat model.CashFlowTypeStat
+		if err = rows.Scan(&typeStat.FlowType, &typeStat.Currency, &typeStat.Count, &typeStat.TotalAmount); err != nil {
+			util.Logger.Errorw("parse flow_type stat failed", "error", err)
+			continue
+		}
+		typeStat.TotalAmount = typeStat.TotalAmount.Shift(-2)
+		typeStatList = append(typeStatList, typeStat)
+	}
+	return typeStatList
+}
+
+// GetCashFlowCategoryStats shares split cash flows among the categories of their lines
+func (CashFlowSqliteMapper) GetCashFlowCategoryStats(ownerPlainId string) []model.CashFlowCategoryStat {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT L.CATEGORY_ID, L.CURRENCY, SUM(L.IS_COUNTED), " + sqliteSumInCents("L.AMOUNT") + " FROM ")
+	sqlString.WriteString(cashFlowCategoryLinesTable("CF.OWNER_ID = ?"))
+	sqlString.WriteString(" GROUP BY L.CATEGORY_ID, L.CURRENCY ORDER BY SUM(L.IS_COUNTED) DESC, L.CATEGORY_ID, L.CURRENCY ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	rows, err := database.GetSqliteConnection().Query(sqlString.String(), ownerId, ownerId)
+	if err != nil {
+		util.Logger.Errorw("aggregate by category_id failed", "error", err)
+		return []model.CashFlowCategoryStat{}
+	}
+	defer rows.Close()
+
+	var categoryStatList []model.CashFlowCategoryStat
+	for rows.Next() {
+		var categoryId string
+		var categoryStat model.CashFlowCategoryStat
+		if err = rows.Scan(&categoryId, &categoryStat.Currency, &categoryStat.Count, &categoryStat.TotalAmount); err != nil {
+			util.Logger.Errorw("parse category stat failed", "error", err)
+			continue
+		}
+		categoryStat.CategoryId = util.Convert2ObjectId(categoryId)
+		categoryStat.TotalAmount = categoryStat.TotalAmount.Shift(-2)
+		categoryStatList = append(categoryStatList, categoryStat)
+	}
+	return categoryStatList
+}
+
+func (CashFlowSqliteMapper) GetCashFlowAccountStats(ownerPlainId string) []model.CashFlowAccountStat {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT ACCOUNT_ID, FLOW_TYPE, COUNT(1), " + sqliteSumInCents("AMOUNT") + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" GROUP BY ACCOUNT_ID, FLOW_TYPE ORDER BY ACCOUNT_ID, FLOW_TYPE ")
+
+	rows, err := database.GetSqliteConnection().Query(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex())
+	if err != nil {
+		util.Logger.Errorw("aggregate by account_id failed", "error", err)
+		return []model.CashFlowAccountStat{}
+	}
+	defer rows.Close()
+
+	var accountStatList []model.CashFlowAccountStat
+	for rows.Next() {
+		var accountId sql.NullString
+		var accountStat model.CashFlowAccountStat
+		if err = rows.Scan(&accountId, &accountStat.FlowType, &accountStat.Count, &accountStat.TotalAmount); err != nil {
+			util.Logger.Errorw("parse account stat failed", "error", err)
+			continue
+		}
+		accountStat.AccountId = convertNullString2ObjectId(accountId)
+		accountStat.TotalAmount = accountStat.TotalAmount.Shift(-2)
+		accountStatList = append(accountStatList, accountStat)
+	}
+	return accountStatList
+}
+
+func (CashFlowSqliteMapper) GetCashFlowDateSpan(ownerPlainId string) (earliest, latest time.Time) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT MIN(BELONGS_DATE), MAX(BELONGS_DATE) FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	// MIN/MAX of an empty ledger are NULL
+	var earliestDate, latestDate sql.NullString
+	err := database.GetSqliteConnection().QueryRow(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&earliestDate, &latestDate)
+	if err != nil {
+		util.Logger.Errorw("query date span failed", "error", err)
+		return time.Time{}, time.Time{}
+	}
+	if !earliestDate.Valid || !latestDate.Valid {
+		return time.Time{}, time.Time{}
+	}
+	return util.FormatDateTimeFromString(earliestDate.String), util.FormatDateTimeFromString(latestDate.String)
+}
+
+// GetCashFlowSummaryByDateRange groups the range by flow type, currency and category, split cash flows by their lines,
+// and joins the category name in the same query.
+func (CashFlowSqliteMapper) GetCashFlowSummaryByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowSummaryStat {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT L.FLOW_TYPE, L.CURRENCY, L.CATEGORY_ID, COALESCE(C.NAME, ''), SUM(L.IS_COUNTED), " + sqliteSumInCents("L.AMOUNT") + " FROM ")
+	sqlString.WriteString(cashFlowCategoryLinesTable("CF.OWNER_ID = ? AND CF.BELONGS_DATE BETWEEN ? AND ?"))
+	sqlString.WriteString(" LEFT JOIN ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" C ON C.ID = L.CATEGORY_ID ")
+	sqlString.WriteString(" GROUP BY L.FLOW_TYPE, L.CURRENCY, L.CATEGORY_ID, C.NAME ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	fromDate := util.FormatDateToStringWithDash(from)
+	toDate := util.FormatDateToStringWithDash(to)
+	rows, err := database.GetSqliteConnection().Query(sqlString.String(), ownerId, fromDate, toDate, ownerId, fromDate, toDate)
+	if err != nil {
+		util.Logger.Errorw("aggregate summary failed", "error", err)
+		return []model.CashFlowSummaryStat{}
+	}
+	defer rows.Close()
+
+	var summaryStatList []model.CashFlowSummaryStat
+	for rows.Next() {
+		var categoryId string
+		var summaryStat model.CashFlowSummaryStat
+		err = rows.Scan(&summaryStat.FlowType, &summaryStat.Currency, &categoryId, &summaryStat.CategoryName,
+			&summaryStat.Count, &summaryStat.TotalAmount)
+		if err != nil {
+			util.Logger.Errorw("parse summary stat failed", "error", err)
+			continue
+		}
+		summaryStat.CategoryId = util.Convert2ObjectId(categoryId)
+		summaryStat.TotalAmount = summaryStat.TotalAmount.Shift(-2)
+		summaryStatList = append(summaryStatList, summaryStat)
+	}
+	return summaryStatList
+}
+
+func (CashFlowSqliteMapper) GetCashFlowsByTag(ownerPlainId, tag string, limit, offset int) []model.CashFlowEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCashFlowColumns + ", " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND ID IN (SELECT CASH_FLOW_ID FROM ")
+	sqlString.WriteString(database.CashFlowTagTableName)
+	sqlString.WriteString(" WHERE TAG = ?) ")
+	sqlString.WriteString(" ORDER BY BELONGS_DATE DESC, ID DESC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteCashFlows(sqlString.String(), ownerId, tag, limit, offset)
+	}
+	return querySqliteCashFlows(sqlString.String(), ownerId, tag)
+}
+
+func (CashFlowSqliteMapper) CountCashFlowsByTag(ownerPlainId, tag string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CashFlowTagTableName)
+	sqlString.WriteString(" T JOIN ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" CF ON CF.ID = T.CASH_FLOW_ID ")
+	sqlString.WriteString(" WHERE CF.OWNER_ID = ? AND T.TAG = ? ")
+
+	var count int64
+	err := database.GetSqliteConnection().QueryRow(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), tag).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count by tag failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+// QueryCashFlows has no full-text index to use, every word is matched with LIKE and
+// sorting by relevance keeps the newest first
+func (CashFlowSqliteMapper) QueryCashFlows(ownerPlainId string, querySpec model.CashFlowQuerySpec) []model.CashFlowEntity {
+	conditionList, args := sqliteCashFlowQueryConditions(ownerPlainId, querySpec)
+	conditionList, args = appendCursorCondition(querySpec, conditionList, args)
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCashFlowColumns + ", " + cashFlowTagsColumn + " FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(cashFlowQueryWhere(conditionList))
+	sqlString.WriteString(cashFlowQueryOrder(querySpec, ""))
+	if querySpec.Limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		args = append(args, querySpec.Limit, querySpec.Offset)
+	}
+
+	targetEntityList := querySqliteCashFlows(sqlString.String(), args...)
+	if querySpec.CursorApplies() && querySpec.Cursor.Backward {
+		reverseCashFlows(targetEntityList)
+	}
+	return targetEntityList
+}
+
+func (CashFlowSqliteMapper) CountCashFlowsByQuery(ownerPlainId string, querySpec model.CashFlowQuerySpec) int64 {
+	conditionList, args := sqliteCashFlowQueryConditions(ownerPlainId, querySpec)
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(cashFlowQueryWhere(conditionList))
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String(), args...).Scan(&count); err != nil {
+		util.Logger.Errorw("count by spec failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+// GetCashFlowTagSummaryByDateRange groups the tagged cash flows of the range by flow type, currency and tag
+func (CashFlowSqliteMapper) GetCashFlowTagSummaryByDateRange(ownerPlainId string, from, to time.Time) []model.CashFlowTagSummaryStat {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT CF.FLOW_TYPE, CF.CURRENCY, T.TAG, COUNT(1), " + sqliteSumInCents("CF.AMOUNT") + " FROM ")
+	sqlString.WriteString(database.CashFlowTagTableName)
+	sqlString.WriteString(" T JOIN ")
+	sqlString.WriteString(database.CashFlowTableName)
+	sqlString.WriteString(" CF ON CF.ID = T.CASH_FLOW_ID ")
+	sqlString.WriteString(" WHERE CF.OWNER_ID = ? AND CF.BELONGS_DATE BETWEEN ? AND ? ")
+	sqlString.WriteString(" GROUP BY CF.FLOW_TYPE, CF.CURRENCY, T.TAG ORDER BY T.TAG, CF.FLOW_TYPE, CF.CURRENCY ")
+
+	rows, err := database.GetSqliteConnection().Query(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(),
+		util.FormatDateToStringWithDash(from),
+		util.FormatDateToStringWithDash(to))
+	if err != nil {
+		util.Logger.Errorw("aggregate tag summary failed", "error", err)
+		return []model.CashFlowTagSummaryStat{}
+	}
+	defer rows.Close()
+
+	var tagStatList []model.CashFlowTagSummaryStat
+	for rows.Next() {
+		var tagStat model.CashFlowTagSummaryStat
+		if err = rows.Scan(&tagStat.FlowType, &tagStat.Currency, &tagStat.Tag, &tagStat.Count, &tagStat.TotalAmount); err != nil {
+			util.Logger.Errorw("parse tag summary stat failed", "error", err)
+			continue
+		}
+		tagStat.TotalAmount = tagStat.TotalAmount.Shift(-2)
+		tagStatList = append(tagStatList, tagStat)
+	}
+	return tagStatList
+}
+
+// sqliteSumInCents adds the amounts up as whole cents, summing the REAL column directly would drift.
+// The result has to be shifted back by two places after scanning.
+func sqliteSumInCents(column string) string {
+	return "COALESCE(SUM(CAST(ROUND(" + column + " * 100) AS INTEGER)), 0)"
+}
+
+// sqliteCashFlowQueryConditions adds a LIKE per search word, case-insensitive for ASCII letters.
+// The words hold letters and digits only, so none of them can carry a wildcard.
+func sqliteCashFlowQueryConditions(ownerPlainId string, querySpec model.CashFlowQuerySpec) ([]string, []interface{}) {
+	conditionList, args := cashFlowQueryConditions(ownerPlainId, querySpec)
+	for _, term := range querySpec.TextTerms() {
+		conditionList = append(conditionList, "DESCRIPTION LIKE ?")
+		args = append(args, "%"+term+"%")
+	}
+	return conditionList, args
+}
+
+func querySqliteCashFlows(sqlString string, args ...interface{}) []model.CashFlowEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+
+	var targetEntityList []model.CashFlowEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2CashFlowEntity(rows))
+	}
+	// The single connection stays busy until the rows are closed, and the split lines need it next
+	rows.Close()
+	return attachCashFlowSplits(database.GetSqliteConnection(), targetEntityList)
+}
+
+// convertCashFlowEntity2SqliteValues lists the column values in sqliteCashFlowColumns order,
+// dates are written as text so that BETWEEN and ORDER BY compare them correctly.
+func convertCashFlowEntity2SqliteValues(plainId string, entity model.CashFlowEntity) []interface{} {
+	return []interface{}{
+		plainId,
+		entity.OwnerId.Hex(),
+		entity.CategoryId.Hex(),
+		entity.AccountId.Hex(),
+		entity.LinkedId.Hex(),
+		entity.PayeeId.Hex(),
+		util.FormatDateToStringWithDash(entity.BelongsDate),
+		entity.FlowType,
+		entity.Amount,
+		entity.Currency,
+		entity.Description,
+		entity.Remark,
+		entity.CreatedBy.Hex(),
+		entity.ModifiedBy.Hex(),
+		util.FormatDateTimeToString(entity.CreateTime),
+		util.FormatDateTimeToString(entity.ModifyTime),
+	}
+}
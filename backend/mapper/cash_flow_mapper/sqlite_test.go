@@ -0,0 +1,567 @@
+package cash_flow_mapper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMain(m *testing.M) {
+	tempDir, err := os.MkdirTemp("", "cashlens-sqlite-")
+	if err != nil {
+		panic(err)
+	}
+	util.SetConfigByKey("db.sqlite.path", filepath.Join(tempDir, "cash_flow_test.db"))
+	INSTANCE = CashFlowSqliteMapper{}
+
+	code := m.Run()
+	os.RemoveAll(tempDir)
+	os.Exit(code)
+}
+
+func TestSqliteCashFlowLifecycle(t *testing.T) {
+	mapper := CashFlowSqliteMapper{}
+	if _, err := mapper.DeleteAllCashFlows(); err != nil {
+		t.Fatalf("DeleteAllCashFlows() error = %v", err)
+	}
+
+	categoryId := primitive.NewObjectID()
+	payeeId := primitive.NewObjectID()
+	aliceId, bobId := primitive.NewObjectID(), primitive.NewObjectID()
+	belongsDate := util.FormatDateFromStringWithDash("2024-12-01")
+	plainId := mapper.InsertCashFlowByEntity(model.CashFlowEntity{
+		CategoryId:  categoryId,
+		PayeeId:     payeeId,
+		CreatedBy:   aliceId,
+		ModifiedBy:  aliceId,
+		BelongsDate: belongsDate,
+		FlowType:    model.FlowTypeOutcome,
+		Amount:      decimal.NewFromFloat(12.5),
+		Description: "lunch",
+	})
+
+	entity := mapper.GetCashFlowByObjectId(plainId)
+	if entity.Id.Hex() != plainId || entity.CategoryId != categoryId {
+		t.Fatalf("GetCashFlowByObjectId() = %+v, want id %s and category %s", entity, plainId, categoryId.Hex())
+	}
+	if !entity.BelongsDate.Equal(belongsDate) || !entity.Amount.Equal(decimal.NewFromFloat(12.5)) || entity.Description != "lunch" {
+		t.Errorf("GetCashFlowByObjectId() = %+v, fields not stored as inserted", entity)
+	}
+	if entity.CreateTime.IsZero() || entity.ModifyTime.IsZero() {
+		t.Errorf("GetCashFlowByObjectId() create/modify time not set")
+	}
+	if entity.PayeeId != payeeId {
+		t.Errorf("GetCashFlowByObjectId() payee = %s, want %s", entity.PayeeId.Hex(), payeeId.Hex())
+	}
+	if count := mapper.CountCashFlowsByPayeeId(payeeId.Hex()); count != 1 {
+		t.Errorf("CountCashFlowsByPayeeId() = %d, want 1", count)
+	}
+
+	entity.Amount = decimal.NewFromInt(20)
+	entity.CreatedBy = bobId
+	entity.ModifiedBy = bobId
+	mapper.UpdateCashFlowByEntity(plainId, entity)
+	if updated := mapper.GetCashFlowByObjectId(plainId); !updated.Amount.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("UpdateCashFlowByEntity() amount = %s, want 20.00", updated.Amount)
+	} else if updated.CreatedBy != aliceId || updated.ModifiedBy != bobId {
+		t.Errorf("UpdateCashFlowByEntity() created by %s and modified by %s, want alice then bob",
+			updated.CreatedBy.Hex(), updated.ModifiedBy.Hex())
+	}
+
+	if deleted := mapper.DeleteCashFlowByObjectId(plainId); deleted.Id.Hex() != plainId {
+		t.Errorf("DeleteCashFlowByObjectId() returned %s, want %s", deleted.Id.Hex(), plainId)
+	}
+	if count := mapper.CountAllCashFlows(); count != 0 {
+		t.Errorf("CountAllCashFlows() = %d after delete, want 0", count)
+	}
+}
+
+func TestSqliteCashFlowQueriesAndAggregations(t *testing.T) {
+	mapper := CashFlowSqliteMapper{}
+	if _, err := mapper.DeleteAllCashFlows(); err != nil {
+		t.Fatalf("DeleteAllCashFlows() error = %v", err)
+	}
+
+	foodId := primitive.NewObjectID()
+	salaryId := primitive.NewObjectID()
+	ids, err := mapper.BulkInsertCashFlows([]model.CashFlowEntity{
+		{CategoryId: foodId, BelongsDate: util.FormatDateFromStringWithDash("2024-12-01"),
+			FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromFloat(12.5), Description: "lunch"},
+		{CategoryId: foodId, BelongsDate: util.FormatDateFromStringWithDash("2024-12-03"),
+			FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromInt(7), Description: "coffee"},
+		{CategoryId: salaryId, BelongsDate: util.FormatDateFromStringWithDash("2024-12-31"),
+			FlowType: model.FlowTypeIncome, Amount: decimal.NewFromInt(3000), Description: "pay"},
+	})
+	if err != nil || len(ids) != 3 {
+		t.Fatalf("BulkInsertCashFlows() = %v, %v", ids, err)
+	}
+
+	from := util.FormatDateFromStringWithDash("2024-12-02")
+	to := util.FormatDateFromStringWithDash("2024-12-31")
+	if rangeList := mapper.GetCashFlowsByDateRange("", from, to); len(rangeList) != 2 {
+		t.Errorf("GetCashFlowsByDateRange() returned %d records, want 2", len(rangeList))
+	}
+	if fuzzyList := mapper.GetCashFlowsByFuzzyDesc("", "offe"); len(fuzzyList) != 1 {
+		t.Errorf("GetCashFlowsByFuzzyDesc() returned %d records, want 1", len(fuzzyList))
+	}
+	if count := mapper.CountCashFLowsByCategoryId(foodId.Hex()); count != 2 {
+		t.Errorf("CountCashFLowsByCategoryId() = %d, want 2", count)
+	}
+
+	pageList := mapper.GetAllCashFlows(2, 0)
+	if len(pageList) != 2 || pageList[0].Description != "pay" {
+		t.Errorf("GetAllCashFlows(2, 0) should start with the newest record, got %+v", pageList)
+	}
+
+	earliest, latest := mapper.GetCashFlowDateSpan("")
+	if util.FormatDateToStringWithDash(earliest) != "2024-12-01" || util.FormatDateToStringWithDash(latest) != "2024-12-31" {
+		t.Errorf("GetCashFlowDateSpan() = %v, %v", earliest, latest)
+	}
+
+	typeStats := map[string]model.CashFlowTypeStat{}
+	for _, typeStat := range mapper.GetCashFlowTypeStats("") {
+		typeStats[typeStat.FlowType] = typeStat
+	}
+	if typeStats[model.FlowTypeOutcome].Count != 2 || !typeStats[model.FlowTypeOutcome].TotalAmount.Equal(decimal.NewFromFloat(19.5)) {
+		t.Errorf("GetCashFlowTypeStats() outcome = %+v, want 2 records totalling 19.50", typeStats[model.FlowTypeOutcome])
+	}
+
+	categoryStats := mapper.GetCashFlowCategoryStats("")
+	if len(categoryStats) != 2 || categoryStats[0].CategoryId != foodId {
+		t.Errorf("GetCashFlowCategoryStats() = %+v, want food first", categoryStats)
+	}
+
+	summaryStats := mapper.GetCashFlowSummaryByDateRange("", from, to)
+	if len(summaryStats) != 2 {
+		t.Errorf("GetCashFlowSummaryByDateRange() returned %d groups, want 2", len(summaryStats))
+	}
+
+	deletedList := mapper.DeleteCashFlowByBelongsDate("", util.FormatDateFromStringWithDash("2024-12-01"))
+	if len(deletedList) != 1 {
+		t.Errorf("DeleteCashFlowByBelongsDate() returned %d records, want 1", len(deletedList))
+	}
+
+	deletedCount, err := mapper.DeleteAllCashFlows()
+	if err != nil || deletedCount != 2 {
+		t.Errorf("DeleteAllCashFlows() = %d, %v, want 2, nil", deletedCount, err)
+	}
+}
+
+func TestSqliteCashFlowEmptyTable(t *testing.T) {
+	mapper := CashFlowSqliteMapper{}
+	if _, err := mapper.DeleteAllCashFlows(); err != nil {
+		t.Fatalf("DeleteAllCashFlows() error = %v", err)
+	}
+
+	earliest, latest := mapper.GetCashFlowDateSpan("")
+	if !earliest.IsZero() || !latest.IsZero() {
+		t.Errorf("GetCashFlowDateSpan() on empty table = %v, %v, want zero times", earliest, latest)
+	}
+	if entity := mapper.GetCashFlowByObjectId(primitive.NewObjectID().Hex()); !entity.IsEmpty() {
+		t.Errorf("GetCashFlowByObjectId() of unknown id = %+v, want empty", entity)
+	}
+	if typeStats := mapper.GetCashFlowTypeStats(""); len(typeStats) != 0 {
+		t.Errorf("GetCashFlowTypeStats() on empty table = %+v", typeStats)
+	}
+}
+
+func TestSqliteCashFlowStatsByCurrency(t *testing.T) {
+	mapper := CashFlowSqliteMapper{}
+	if _, err := mapper.DeleteAllCashFlows(); err != nil {
+		t.Fatalf("DeleteAllCashFlows() error = %v", err)
+	}
+
+	foodId := primitive.NewObjectID()
+	belongsDate := util.FormatDateFromStringWithDash("2024-12-01")
+	_, err := mapper.BulkInsertCashFlows([]model.CashFlowEntity{
+		{CategoryId: foodId, BelongsDate: belongsDate, FlowType: model.FlowTypeOutcome,
+			Amount: decimal.NewFromInt(10), Currency: "EUR", Description: "lunch"},
+		{CategoryId: foodId, BelongsDate: belongsDate, FlowType: model.FlowTypeOutcome,
+			Amount: decimal.NewFromInt(1200), Currency: "JPY", Description: "ramen", Tags: []string{"trip-japan"}},
+		{CategoryId: foodId, BelongsDate: belongsDate, FlowType: model.FlowTypeOutcome,
+			Amount: decimal.NewFromInt(5), Currency: "EUR", Description: "coffee"},
+	})
+	if err != nil {
+		t.Fatalf("BulkInsertCashFlows() error = %v", err)
+	}
+
+	typeStats := mapper.GetCashFlowTypeStats("")
+	if len(typeStats) != 2 || typeStats[0].Currency != "EUR" || !typeStats[0].TotalAmount.Equal(decimal.NewFromInt(15)) ||
+		typeStats[1].Currency != "JPY" || !typeStats[1].TotalAmount.Equal(decimal.NewFromInt(1200)) {
+		t.Errorf("GetCashFlowTypeStats() = %+v, want 15 EUR and 1200 JPY apart", typeStats)
+	}
+
+	categoryStats := mapper.GetCashFlowCategoryStats("")
+	if len(categoryStats) != 2 || categoryStats[0].Currency != "EUR" || categoryStats[0].Count != 2 ||
+		categoryStats[1].Currency != "JPY" || !categoryStats[1].TotalAmount.Equal(decimal.NewFromInt(1200)) {
+		t.Errorf("GetCashFlowCategoryStats() = %+v, want food in EUR and JPY apart", categoryStats)
+	}
+
+	summaryStats := mapper.GetCashFlowSummaryByDateRange("", belongsDate, belongsDate)
+	summaryAmountMap := make(map[string]decimal.Decimal)
+	for _, summaryStat := range summaryStats {
+		summaryAmountMap[summaryStat.Currency] = summaryStat.TotalAmount
+	}
+	if len(summaryStats) != 2 || !summaryAmountMap["EUR"].Equal(decimal.NewFromInt(15)) || !summaryAmountMap["JPY"].Equal(decimal.NewFromInt(1200)) {
+		t.Errorf("GetCashFlowSummaryByDateRange() = %+v, want food in EUR and JPY apart", summaryStats)
+	}
+
+	tagStats := mapper.GetCashFlowTagSummaryByDateRange("", belongsDate, belongsDate)
+	if len(tagStats) != 1 || tagStats[0].Currency != "JPY" || !tagStats[0].TotalAmount.Equal(decimal.NewFromInt(1200)) {
+		t.Errorf("GetCashFlowTagSummaryByDateRange() = %+v, want trip-japan 1200 JPY", tagStats)
+	}
+}
+
+func TestSqliteCashFlowTransferLegs(t *testing.T) {
+	mapper := CashFlowSqliteMapper{}
+	if _, err := mapper.DeleteAllCashFlows(); err != nil {
+		t.Fatalf("DeleteAllCashFlows() error = %v", err)
+	}
+
+	bankId := primitive.NewObjectID()
+	savingsId := primitive.NewObjectID()
+	outgoingId := primitive.NewObjectID()
+	incomingId := primitive.NewObjectID()
+	belongsDate := util.FormatDateFromStringWithDash("2024-12-01")
+	if _, err := mapper.BulkInsertCashFlows([]model.CashFlowEntity{
+		{Id: outgoingId, AccountId: bankId, LinkedId: incomingId, BelongsDate: belongsDate,
+			FlowType: model.FlowTypeTransfer, Amount: decimal.NewFromInt(-100)},
+		{Id: incomingId, AccountId: savingsId, LinkedId: outgoingId, BelongsDate: belongsDate,
+			FlowType: model.FlowTypeTransfer, Amount: decimal.NewFromInt(100)},
+	}); err != nil {
+		t.Fatalf("BulkInsertCashFlows() error = %v", err)
+	}
+
+	outgoing := mapper.GetCashFlowByObjectId(outgoingId.Hex())
+	if outgoing.AccountId != bankId || outgoing.LinkedId != incomingId || outgoing.CategoryId != primitive.NilObjectID {
+		t.Errorf("GetCashFlowByObjectId() = %+v, account and linked leg not stored", outgoing)
+	}
+	if count := mapper.CountCashFlowsByAccountId(savingsId.Hex()); count != 1 {
+		t.Errorf("CountCashFlowsByAccountId() = %d, want 1", count)
+	}
+
+	accountStatList := mapper.GetCashFlowAccountStats("")
+	if len(accountStatList) != 2 {
+		t.Fatalf("GetCashFlowAccountStats() returned %d groups, want 2", len(accountStatList))
+	}
+	for _, accountStat := range accountStatList {
+		if accountStat.AccountId == bankId && !accountStat.TotalAmount.Equal(decimal.NewFromInt(-100)) {
+			t.Errorf("GetCashFlowAccountStats() bank total = %s, want -100.00", accountStat.TotalAmount)
+		}
+	}
+}
+
+func TestSqliteCashFlowSumsAreExact(t *testing.T) {
+	mapper := CashFlowSqliteMapper{}
+	if _, err := mapper.DeleteAllCashFlows(); err != nil {
+		t.Fatalf("DeleteAllCashFlows() error = %v", err)
+	}
+
+	// A year of ten cent records, summing the REAL column as is would drift away from 36.50
+	entities := make([]model.CashFlowEntity, 0, 365)
+	for day := 0; day < 365; day++ {
+		entities = append(entities, model.CashFlowEntity{
+			BelongsDate: util.FormatDateFromStringWithDash("2024-01-01").AddDate(0, 0, day),
+			FlowType:    model.FlowTypeOutcome,
+			Amount:      decimal.RequireFromString("0.10"),
+		})
+	}
+	if _, err := mapper.BulkInsertCashFlows(entities); err != nil {
+		t.Fatalf("BulkInsertCashFlows() error = %v", err)
+	}
+
+	want := decimal.RequireFromString("36.50")
+	typeStats := mapper.GetCashFlowTypeStats("")
+	if len(typeStats) != 1 || !typeStats[0].TotalAmount.Equal(want) {
+		t.Errorf("GetCashFlowTypeStats() = %+v, want a total of %s", typeStats, want)
+	}
+	summaryStats := mapper.GetCashFlowSummaryByDateRange("",
+		util.FormatDateFromStringWithDash("2024-01-01"), util.FormatDateFromStringWithDash("2024-12-31"))
+	if len(summaryStats) != 1 || !summaryStats[0].TotalAmount.Equal(want) {
+		t.Errorf("GetCashFlowSummaryByDateRange() = %+v, want a total of %s", summaryStats, want)
+	}
+}
+
+func TestSqliteCashFlowTags(t *testing.T) {
+	mapper := CashFlowSqliteMapper{}
+	if _, err := mapper.DeleteAllCashFlows(); err != nil {
+		t.Fatalf("DeleteAllCashFlows() error = %v", err)
+	}
+
+	categoryId := primitive.NewObjectID()
+	ids, err := mapper.BulkInsertCashFlows([]model.CashFlowEntity{
+		{CategoryId: categoryId, BelongsDate: util.FormatDateFromStringWithDash("2024-04-01"),
+			FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromFloat(30.1), Description: "dinner",
+			Tags: []string{"reimbursable", "trip-japan"}},
+		{CategoryId: categoryId, BelongsDate: util.FormatDateFromStringWithDash("2024-04-02"),
+			FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromFloat(120.2), Description: "ryokan",
+			Tags: []string{"trip-japan"}},
+		{CategoryId: categoryId, BelongsDate: util.FormatDateFromStringWithDash("2024-04-02"),
+			FlowType: model.FlowTypeIncome, Amount: decimal.NewFromInt(50), Description: "refund"},
+	})
+	if err != nil {
+		t.Fatalf("BulkInsertCashFlows() error = %v", err)
+	}
+
+	dinner := mapper.GetCashFlowByObjectId(ids[0])
+	if len(dinner.Tags) != 2 || dinner.Tags[0] != "reimbursable" || dinner.Tags[1] != "trip-japan" {
+		t.Errorf("GetCashFlowByObjectId() tags = %v, want [reimbursable trip-japan]", dinner.Tags)
+	}
+	if refund := mapper.GetCashFlowByObjectId(ids[2]); refund.Tags != nil {
+		t.Errorf("GetCashFlowByObjectId() untagged tags = %v, want nil", refund.Tags)
+	}
+
+	if count := mapper.CountCashFlowsByTag("", "trip-japan"); count != 2 {
+		t.Errorf("CountCashFlowsByTag() = %d, want 2", count)
+	}
+	tripList := mapper.GetCashFlowsByTag("", "trip-japan", 1, 1)
+	if len(tripList) != 1 || tripList[0].Id.Hex() != ids[0] {
+		t.Errorf("GetCashFlowsByTag() second page = %+v, want the dinner", tripList)
+	}
+
+	tagStatList := mapper.GetCashFlowTagSummaryByDateRange("",
+		util.FormatDateFromStringWithDash("2024-04-01"), util.FormatDateFromStringWithDash("2024-04-30"))
+	if len(tagStatList) != 2 || tagStatList[1].Tag != "trip-japan" || tagStatList[1].Count != 2 ||
+		!tagStatList[1].TotalAmount.Equal(decimal.NewFromFloat(150.3)) {
+		t.Errorf("GetCashFlowTagSummaryByDateRange() = %+v, want reimbursable then trip-japan 150.30", tagStatList)
+	}
+
+	dinner.Tags = []string{"work"}
+	mapper.UpdateCashFlowByEntity(ids[0], dinner)
+	if updated := mapper.GetCashFlowByObjectId(ids[0]); len(updated.Tags) != 1 || updated.Tags[0] != "work" {
+		t.Errorf("UpdateCashFlowByEntity() tags = %v, want [work]", updated.Tags)
+	}
+	if count := mapper.CountCashFlowsByTag("", "reimbursable"); count != 0 {
+		t.Errorf("CountCashFlowsByTag() = %d after the tag was replaced, want 0", count)
+	}
+
+	mapper.DeleteCashFlowByBelongsDate("", util.FormatDateFromStringWithDash("2024-04-02"))
+	if count := mapper.CountCashFlowsByTag("", "trip-japan"); count != 0 {
+		t.Errorf("CountCashFlowsByTag() = %d after delete, want 0", count)
+	}
+}
+
+func TestSqliteCashFlowSplits(t *testing.T) {
+	mapper := CashFlowSqliteMapper{}
+	if _, err := mapper.DeleteAllCashFlows(); err != nil {
+		t.Fatalf("DeleteAllCashFlows() error = %v", err)
+	}
+
+	groceryId := primitive.NewObjectID()
+	householdId := primitive.NewObjectID()
+	giftId := primitive.NewObjectID()
+	ids, err := mapper.BulkInsertCashFlows([]model.CashFlowEntity{
+		{CategoryId: groceryId, BelongsDate: util.FormatDateFromStringWithDash("2024-05-01"),
+			FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromFloat(42.5), Description: "supermarket",
+			Splits: []model.CashFlowSplit{
+				{CategoryId: groceryId, Amount: decimal.NewFromFloat(30.1), Description: "food"},
+				{CategoryId: householdId, Amount: decimal.NewFromFloat(7.4)},
+				{CategoryId: giftId, Amount: decimal.NewFromInt(5), Description: "card"},
+			}},
+		{CategoryId: groceryId, BelongsDate: util.FormatDateFromStringWithDash("2024-05-02"),
+			FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromInt(10), Description: "bakery"},
+	})
+	if err != nil {
+		t.Fatalf("BulkInsertCashFlows() error = %v", err)
+	}
+
+	receipt := mapper.GetCashFlowByObjectId(ids[0])
+	if len(receipt.Splits) != 3 || receipt.Splits[1].CategoryId != householdId ||
+		!receipt.Splits[1].Amount.Equal(decimal.NewFromFloat(7.4)) || receipt.Splits[2].Description != "card" {
+		t.Fatalf("GetCashFlowByObjectId() splits = %+v, want the three lines in order", receipt.Splits)
+	}
+	if bakery := mapper.GetCashFlowByObjectId(ids[1]); bakery.Splits != nil {
+		t.Errorf("GetCashFlowByObjectId() splits of a plain cash flow = %+v, want nil", bakery.Splits)
+	}
+
+	if count := mapper.CountCashFLowsByCategoryId(householdId.Hex()); count != 1 {
+		t.Errorf("CountCashFLowsByCategoryId() of a line category = %d, want 1", count)
+	}
+	if count := mapper.CountCashFLowsByCategoryId(groceryId.Hex()); count != 2 {
+		t.Errorf("CountCashFLowsByCategoryId() = %d, want 2", count)
+	}
+
+	summaryStatList := mapper.GetCashFlowSummaryByDateRange("",
+		util.FormatDateFromStringWithDash("2024-05-01"), util.FormatDateFromStringWithDash("2024-05-31"))
+	totalByCategory := make(map[primitive.ObjectID]decimal.Decimal)
+	var totalCount int64
+	for _, summaryStat := range summaryStatList {
+		totalByCategory[summaryStat.CategoryId] = summaryStat.TotalAmount
+		totalCount += summaryStat.Count
+	}
+	if !totalByCategory[groceryId].Equal(decimal.NewFromFloat(40.1)) ||
+		!totalByCategory[householdId].Equal(decimal.NewFromFloat(7.4)) || !totalByCategory[giftId].Equal(decimal.NewFromInt(5)) {
+		t.Errorf("GetCashFlowSummaryByDateRange() = %+v, want 40.10 groceries, 7.40 household and 5.00 gifts", summaryStatList)
+	}
+	if totalCount != 2 {
+		t.Errorf("GetCashFlowSummaryByDateRange() counts add up to %d, want 2", totalCount)
+	}
+
+	receipt.Splits = nil
+	mapper.UpdateCashFlowByEntity(ids[0], receipt)
+	if updated := mapper.GetCashFlowByObjectId(ids[0]); updated.Splits != nil {
+		t.Errorf("UpdateCashFlowByEntity() splits = %+v after clearing, want nil", updated.Splits)
+	}
+	if count := mapper.CountCashFLowsByCategoryId(householdId.Hex()); count != 0 {
+		t.Errorf("CountCashFLowsByCategoryId() = %d after the split was cleared, want 0", count)
+	}
+}
+
+func TestSqliteCashFlowSearch(t *testing.T) {
+	mapper := CashFlowSqliteMapper{}
+	if _, err := mapper.DeleteAllCashFlows(); err != nil {
+		t.Fatalf("DeleteAllCashFlows() error = %v", err)
+	}
+
+	coffeeId := primitive.NewObjectID()
+	groceryId := primitive.NewObjectID()
+	bankId := primitive.NewObjectID()
+	ids, err := mapper.BulkInsertCashFlows([]model.CashFlowEntity{
+		{CategoryId: coffeeId, AccountId: bankId, BelongsDate: util.FormatDateFromStringWithDash("2024-06-01"),
+			FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromFloat(4.5), Description: "Starbucks latte",
+			Tags: []string{"work"}},
+		{CategoryId: groceryId, BelongsDate: util.FormatDateFromStringWithDash("2024-06-02"),
+			FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromFloat(42.5), Description: "supermarket",
+			Splits: []model.CashFlowSplit{
+				{CategoryId: groceryId, Amount: decimal.NewFromFloat(37.5)},
+				{CategoryId: coffeeId, Amount: decimal.NewFromInt(5), Description: "coffee beans"},
+			}},
+		{CategoryId: coffeeId, BelongsDate: util.FormatDateFromStringWithDash("2024-06-03"),
+			FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromFloat(3.9), Description: "starbucks espresso",
+			Tags: []string{"morning", "work"}},
+		{CategoryId: groceryId, BelongsDate: util.FormatDateFromStringWithDash("2024-06-03"),
+			FlowType: model.FlowTypeIncome, Amount: decimal.NewFromInt(10), Description: "supermarket refund"},
+	})
+	if err != nil {
+		t.Fatalf("BulkInsertCashFlows() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		querySpec model.CashFlowQuerySpec
+		want      []string
+	}{
+		{"everything newest first", model.CashFlowQuerySpec{SortDesc: true}, []string{ids[3], ids[2], ids[1], ids[0]}},
+		{"text ignores case", model.CashFlowQuerySpec{Text: "STARBUCKS"}, []string{ids[0], ids[2]}},
+		{"every word", model.CashFlowQuerySpec{Text: "starbucks, latte"}, []string{ids[0]}},
+		{"category or split line", model.CashFlowQuerySpec{CategoryIds: []primitive.ObjectID{coffeeId}}, []string{ids[0], ids[1], ids[2]}},
+		{"amount range", model.CashFlowQuerySpec{MinAmount: decimal.NewFromFloat(4.5), MaxAmount: decimal.NewFromInt(10)}, []string{ids[0], ids[3]}},
+		{"all tags", model.CashFlowQuerySpec{Tags: []string{"morning", "work"}}, []string{ids[2]}},
+		{"account", model.CashFlowQuerySpec{AccountId: bankId}, []string{ids[0]}},
+		{"date and flow type", model.CashFlowQuerySpec{
+			FromDate: util.FormatDateFromStringWithDash("2024-06-02"), ToDate: util.FormatDateFromStringWithDash("2024-06-03"),
+			FlowType: model.FlowTypeOutcome}, []string{ids[1], ids[2]}},
+		{"amount descending page", model.CashFlowQuerySpec{SortBy: model.CashFlowSortByAmount, SortDesc: true, Limit: 2, Offset: 1},
+			[]string{ids[3], ids[0]}},
+		{"relevance keeps the newest first", model.CashFlowQuerySpec{Text: "supermarket", SortBy: model.CashFlowSortByRelevance},
+			[]string{ids[3], ids[1]}},
+		{"after a cursor on a shared date", model.CashFlowQuerySpec{SortDesc: true,
+			Cursor: model.CashFlowCursor{BelongsDate: util.FormatDateFromStringWithDash("2024-06-03"), Id: mustObjectId(t, ids[3])}},
+			[]string{ids[2], ids[1], ids[0]}},
+		{"before a cursor keeps the listing order", model.CashFlowQuerySpec{SortDesc: true, Limit: 2,
+			Cursor: model.CashFlowCursor{BelongsDate: util.FormatDateFromStringWithDash("2024-06-01"), Id: mustObjectId(t, ids[0]), Backward: true}},
+			[]string{ids[2], ids[1]}},
+		{"cursor ignored by other orders", model.CashFlowQuerySpec{SortBy: model.CashFlowSortByAmount, Limit: 1,
+			Cursor: model.CashFlowCursor{BelongsDate: util.FormatDateFromStringWithDash("2024-06-03"), Id: mustObjectId(t, ids[3])}},
+			[]string{ids[2]}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, cashFlow := range mapper.QueryCashFlows("", tt.querySpec) {
+				got = append(got, cashFlow.Id.Hex())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("QueryCashFlows() = %v, want %v", got, tt.want)
+			}
+
+			countQuerySpec := tt.querySpec
+			// The count covers every page, wherever the cursor stands
+			countQuerySpec.Limit, countQuerySpec.Offset, countQuerySpec.Cursor = 0, 0, model.CashFlowCursor{}
+			if count := mapper.CountCashFlowsByQuery("", countQuerySpec); count != int64(len(mapper.QueryCashFlows("", countQuerySpec))) {
+				t.Errorf("CountCashFlowsByQuery() = %d, does not match the search", count)
+			}
+		})
+	}
+
+	if supermarket := mapper.QueryCashFlows("", model.CashFlowQuerySpec{Text: "supermarket", FlowType: model.FlowTypeOutcome}); len(supermarket) != 1 ||
+		len(supermarket[0].Splits) != 2 {
+		t.Errorf("QueryCashFlows() = %+v, want the supermarket with its split lines", supermarket)
+	}
+}
+
+func mustObjectId(t *testing.T, id string) primitive.ObjectID {
+	t.Helper()
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		t.Fatalf("ObjectIDFromHex(%q) error = %v", id, err)
+	}
+	return objectId
+}
+
+func TestSqliteCashFlowOwners(t *testing.T) {
+	mapper := CashFlowSqliteMapper{}
+	if _, err := mapper.DeleteAllCashFlows(); err != nil {
+		t.Fatalf("DeleteAllCashFlows() error = %v", err)
+	}
+
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	belongsDate := util.FormatDateFromStringWithDash("2024-05-01")
+	ids, err := mapper.BulkInsertCashFlows([]model.CashFlowEntity{
+		{CategoryId: primitive.NewObjectID(), BelongsDate: belongsDate, FlowType: model.FlowTypeOutcome,
+			Amount: decimal.NewFromInt(10), Description: "lunch", Tags: []string{"work"}},
+		{OwnerId: bob, CategoryId: primitive.NewObjectID(), BelongsDate: belongsDate, FlowType: model.FlowTypeOutcome,
+			Amount: decimal.NewFromInt(30), Description: "lunch", Tags: []string{"work"}},
+	})
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("BulkInsertCashFlows() = %v, %v", ids, err)
+	}
+
+	// Every lookup stays within the owner's ledger
+	if bobList := mapper.GetCashFlowsByExactDesc(bob.Hex(), "lunch"); len(bobList) != 1 || bobList[0].Id.Hex() != ids[1] {
+		t.Errorf("GetCashFlowsByExactDesc(bob) = %+v, want bob's lunch", bobList)
+	}
+	if count := mapper.CountCashFlowsByTag(bob.Hex(), "work"); count != 1 {
+		t.Errorf("CountCashFlowsByTag(bob) = %d, want 1", count)
+	}
+	if count := mapper.CountCashFlowsByQuery(alice.Hex(), model.CashFlowQuerySpec{}); count != 0 {
+		t.Errorf("CountCashFlowsByQuery(alice) = %d, want 0", count)
+	}
+	typeStats := mapper.GetCashFlowTypeStats(bob.Hex())
+	if len(typeStats) != 1 || !typeStats[0].TotalAmount.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("GetCashFlowTypeStats(bob) = %+v, want a total of 30", typeStats)
+	}
+
+	// An update keeps the owner
+	bobLunch := mapper.GetCashFlowByObjectId(ids[1])
+	bobLunch.OwnerId = primitive.NilObjectID
+	mapper.UpdateCashFlowByEntity(ids[1], bobLunch)
+	if owner := mapper.GetCashFlowByObjectId(ids[1]).OwnerId; owner != bob {
+		t.Errorf("UpdateCashFlowByEntity() changed the owner to %s", owner.Hex())
+	}
+
+	// Only the cash flows without owner are handed over
+	assignedCount, err := mapper.AssignUnownedCashFlows(alice.Hex())
+	if err != nil || assignedCount != 1 {
+		t.Fatalf("AssignUnownedCashFlows() = %d, %v, want 1, nil", assignedCount, err)
+	}
+	aliceList := mapper.GetCashFlowsByOwnerId(alice.Hex(), 0, 0)
+	if len(aliceList) != 1 || aliceList[0].Id.Hex() != ids[0] {
+		t.Errorf("GetCashFlowsByOwnerId(alice) = %+v, want the unowned lunch", aliceList)
+	}
+
+	// Deleting a day only touches the owner's records
+	if deletedList := mapper.DeleteCashFlowByBelongsDate(alice.Hex(), belongsDate); len(deletedList) != 1 {
+		t.Errorf("DeleteCashFlowByBelongsDate(alice) returned %d records, want 1", len(deletedList))
+	}
+	if count := mapper.CountAllCashFlows(); count != 1 {
+		t.Errorf("CountAllCashFlows() = %d, want bob's record left", count)
+	}
+}
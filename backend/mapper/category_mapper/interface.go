@@ -1,21 +1,30 @@
 package category_mapper
 
 import (
+	"time"
+
 	"github.com/macar-x/cashlens/model"
 	"github.com/macar-x/cashlens/util"
 )
 
 var INSTANCE CategoryMapper
 
+// CategoryMapper stores categories. Every owner has categories of their own, a blank owner stands
+// for the categories no user owns; lookups by id and the GetAll/DeleteAll methods span all owners.
 type CategoryMapper interface {
 	GetCategoryByObjectId(plainId string) model.CategoryEntity
-	GetCategoryByName(categoryName string) model.CategoryEntity
+	GetCategoryByName(ownerPlainId, categoryName string) model.CategoryEntity
 	GetCategoryByParentId(parentPlainId string) []model.CategoryEntity
+	GetCategoriesByOwnerId(ownerPlainId string, limit, offset int) []model.CategoryEntity
+	CountCategoriesByOwnerId(ownerPlainId string) int64
 	InsertCategoryByEntity(newEntity model.CategoryEntity) string
+	BulkInsertCategories(entities []model.CategoryEntity) ([]string, error)
 	UpdateCategoryByEntity(plainId string, updatedEntity model.CategoryEntity) model.CategoryEntity
 	GetAllCategories(limit, offset int) []model.CategoryEntity
 	CountAllCategories() int64
 	DeleteCategoryByObjectId(plainId string) model.CategoryEntity
+	DeleteAllCategories() (int64, error)
+	AssignUnownedCategories(ownerPlainId string) (int64, error)
 }
 
 func init() {
@@ -24,7 +33,23 @@ func init() {
 		INSTANCE = CategoryMongoDbMapper{}
 	case "mysql":
 		INSTANCE = CategoryMySqlMapper{}
+	case "sqlite":
+		INSTANCE = CategorySqliteMapper{}
+	case "memory":
+		INSTANCE = NewCategoryMemoryMapper()
 	default:
 		panic("database type not supported")
 	}
 }
+
+// fillOperatingTime keeps create/modify times carried over from a backup,
+// and stamps the operating time on brand-new records.
+func fillOperatingTime(entity model.CategoryEntity, operatingTime time.Time) model.CategoryEntity {
+	if entity.CreateTime.IsZero() {
+		entity.CreateTime = operatingTime
+	}
+	if entity.ModifyTime.IsZero() {
+		entity.ModifyTime = operatingTime
+	}
+	return entity
+}
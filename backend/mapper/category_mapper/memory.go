@@ -0,0 +1,228 @@
+package category_mapper
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CategoryMemoryMapper keeps categories in process memory, nothing is persisted.
+// It is safe for concurrent use; copies of the mapper share the same data.
+type CategoryMemoryMapper struct {
+	store *categoryMemoryStore
+}
+
+type categoryMemoryStore struct {
+	mutex   sync.RWMutex
+	records map[primitive.ObjectID]model.CategoryEntity
+}
+
+// NewCategoryMemoryMapper returns an empty in-memory mapper
+func NewCategoryMemoryMapper() CategoryMemoryMapper {
+	return CategoryMemoryMapper{
+		store: &categoryMemoryStore{
+			records: make(map[primitive.ObjectID]model.CategoryEntity),
+		},
+	}
+}
+
+func (mapper CategoryMemoryMapper) GetCategoryByObjectId(plainId string) model.CategoryEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("category's id is not acceptable")
+		return model.CategoryEntity{}
+	}
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return mapper.store.records[objectId]
+}
+
+func (mapper CategoryMemoryMapper) GetCategoryByName(ownerPlainId, categoryName string) model.CategoryEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.CategoryEntity) bool {
+		return entity.OwnerId == ownerId && entity.Name == categoryName
+	})
+	if len(targetEntityList) == 0 {
+		return model.CategoryEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (mapper CategoryMemoryMapper) GetCategoryByParentId(parentPlainId string) []model.CategoryEntity {
+	return mapper.filter(func(entity model.CategoryEntity) bool {
+		return entity.ParentId.Hex() == parentPlainId
+	})
+}
+
+func (mapper CategoryMemoryMapper) GetCategoriesByOwnerId(ownerPlainId string, limit, offset int) []model.CategoryEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.CategoryEntity) bool {
+		return entity.OwnerId == ownerId
+	})
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper CategoryMemoryMapper) CountCategoriesByOwnerId(ownerPlainId string) int64 {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	return int64(len(mapper.filter(func(entity model.CategoryEntity) bool {
+		return entity.OwnerId == ownerId
+	})))
+}
+
+func (mapper CategoryMemoryMapper) InsertCategoryByEntity(newEntity model.CategoryEntity) string {
+	newPlainIdList, err := mapper.BulkInsertCategories([]model.CategoryEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+	return newPlainIdList[0]
+}
+
+func (mapper CategoryMemoryMapper) BulkInsertCategories(entities []model.CategoryEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	operatingTime := time.Now()
+	newEntityList := make([]model.CategoryEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.records[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate category id: " + entity.Id.Hex())
+		}
+		batchIds[entity.Id] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.records[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper CategoryMemoryMapper) UpdateCategoryByEntity(plainId string, updatedEntity model.CategoryEntity) model.CategoryEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("category is not exist")
+		return model.CategoryEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+	mapper.store.records[objectId] = updatedEntity
+	return updatedEntity
+}
+
+func (mapper CategoryMemoryMapper) GetAllCategories(limit, offset int) []model.CategoryEntity {
+	targetEntityList := mapper.filter(func(model.CategoryEntity) bool { return true })
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper CategoryMemoryMapper) CountAllCategories() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.records))
+}
+
+func (mapper CategoryMemoryMapper) DeleteCategoryByObjectId(plainId string) model.CategoryEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("category is not exist")
+		return model.CategoryEntity{}
+	}
+
+	// can not delete a category that has referred child-categories.
+	for _, entity := range mapper.store.records {
+		if entity.ParentId == objectId {
+			util.Logger.Infoln("can not delete a category which has child-categories refer to")
+			return model.CategoryEntity{}
+		}
+	}
+
+	delete(mapper.store.records, objectId)
+	return targetEntity
+}
+
+func (mapper CategoryMemoryMapper) DeleteAllCategories() (int64, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	deletedCount := int64(len(mapper.store.records))
+	mapper.store.records = make(map[primitive.ObjectID]model.CategoryEntity)
+	return deletedCount, nil
+}
+
+func (mapper CategoryMemoryMapper) AssignUnownedCategories(ownerPlainId string) (int64, error) {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	var assignedCount int64
+	for id, entity := range mapper.store.records {
+		if entity.OwnerId == primitive.NilObjectID {
+			entity.OwnerId = ownerId
+			mapper.store.records[id] = entity
+			assignedCount++
+		}
+	}
+	return assignedCount, nil
+}
+
+// paginate cuts one page out of the ordered categories, a zero limit keeps all of them
+func paginate(targetEntityList []model.CategoryEntity, limit, offset int) []model.CategoryEntity {
+	if offset >= len(targetEntityList) {
+		return []model.CategoryEntity{}
+	}
+	targetEntityList = targetEntityList[offset:]
+	if limit > 0 && limit < len(targetEntityList) {
+		targetEntityList = targetEntityList[:limit]
+	}
+	return targetEntityList
+}
+
+// filter returns the matching categories ordered by name, like the database mappers
+func (mapper CategoryMemoryMapper) filter(isMatched func(entity model.CategoryEntity) bool) []model.CategoryEntity {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	var targetEntityList []model.CategoryEntity
+	for _, entity := range mapper.store.records {
+		if isMatched(entity) {
+			targetEntityList = append(targetEntityList, entity)
+		}
+	}
+
+	sort.Slice(targetEntityList, func(i, j int) bool {
+		if targetEntityList[i].Name != targetEntityList[j].Name {
+			return targetEntityList[i].Name < targetEntityList[j].Name
+		}
+		return targetEntityList[i].Id.Hex() < targetEntityList[j].Id.Hex()
+	})
+	return targetEntityList
+}
@@ -30,15 +30,16 @@ func (CategoryMongoDbMapper) GetCategoryByObjectId(plainId string) model.Categor
 	return convertBsonM2CategoryEntity(database.GetOneInMongoDB(filter))
 }
 
-func (CategoryMongoDbMapper) GetCategoryByName(categoryName string) model.CategoryEntity {
+func (CategoryMongoDbMapper) GetCategoryByName(ownerPlainId, categoryName string) model.CategoryEntity {
 	// Check cache first
 	categoryCache := cache.GetCategoryCache()
-	if cached, ok := categoryCache.GetByName(categoryName); ok {
+	if cached, ok := categoryCache.GetByName(ownerPlainId, categoryName); ok {
 		return *cached
 	}
 
 	// Cache miss - query database
 	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
 		primitive.E{Key: "name", Value: categoryName},
 	}
 
@@ -71,10 +72,26 @@ func (CategoryMongoDbMapper) GetCategoryByParentId(parentPlainId string) []model
 	return targetEntityList
 }
 
+func (CategoryMongoDbMapper) GetCategoriesByOwnerId(ownerPlainId string, limit, offset int) []model.CategoryEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+	return findMongoDbCategories(filter, limit, offset)
+}
+
+func (CategoryMongoDbMapper) CountCategoriesByOwnerId(ownerPlainId string) int64 {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+
+	database.OpenMongoDbConnection(database.CategoryTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
 func (CategoryMongoDbMapper) InsertCategoryByEntity(newEntity model.CategoryEntity) string {
-	operatingTime := time.Now()
-	newEntity.CreateTime = operatingTime
-	newEntity.ModifyTime = operatingTime
+	newEntity = fillOperatingTime(newEntity, time.Now())
 
 	database.OpenMongoDbConnection(database.CategoryTableName)
 	defer database.CloseMongoDbConnection()
@@ -87,6 +104,37 @@ func (CategoryMongoDbMapper) InsertCategoryByEntity(newEntity model.CategoryEnti
 	return newCategoryId.Hex()
 }
 
+func (CategoryMongoDbMapper) BulkInsertCategories(entities []model.CategoryEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		documents[i] = convertCategoryEntity2BsonD(fillOperatingTime(entity, operatingTime))
+	}
+
+	collection := database.GetMongoCollection(database.CategoryTableName)
+	result, err := collection.InsertMany(context.TODO(), documents)
+
+	// Invalidate cache on insert, even a failed batch may be partially written
+	cache.GetCategoryCache().Clear()
+
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	ids := make([]string, len(result.InsertedIDs))
+	for i, id := range result.InsertedIDs {
+		ids[i] = id.(primitive.ObjectID).Hex()
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
 func (CategoryMongoDbMapper) UpdateCategoryByEntity(plainId string, updatedEntity model.CategoryEntity) model.CategoryEntity {
 	objectId := util.Convert2ObjectId(plainId)
 	if plainId == "" || objectId == primitive.NilObjectID {
@@ -107,8 +155,9 @@ func (CategoryMongoDbMapper) UpdateCategoryByEntity(plainId string, updatedEntit
 		return model.CategoryEntity{}
 	}
 
-	// Update fields from updatedEntity while preserving ID and CreateTime
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
 	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
 	updatedEntity.CreateTime = targetEntity.CreateTime
 	updatedEntity.ModifyTime = time.Now()
 
@@ -164,14 +213,57 @@ func (CategoryMongoDbMapper) DeleteCategoryByObjectId(plainId string) model.Cate
 	return targetEntity
 }
 
+func (CategoryMongoDbMapper) DeleteAllCategories() (int64, error) {
+	collection := database.GetMongoCollection(database.CategoryTableName)
+	result, err := collection.DeleteMany(context.TODO(), bson.D{})
+
+	// Invalidate cache on delete
+	cache.GetCategoryCache().Clear()
+
+	if err != nil {
+		util.Logger.Errorw("delete all categories failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all categories deleted", "count", result.DeletedCount)
+	return result.DeletedCount, nil
+}
+
 func (CategoryMongoDbMapper) GetAllCategories(limit, offset int) []model.CategoryEntity {
+	// Empty filter to get all documents, with pagination
+	return findMongoDbCategories(bson.D{}, limit, offset)
+}
+
+func (CategoryMongoDbMapper) CountAllCategories() int64 {
+	filter := bson.D{}
+
 	database.OpenMongoDbConnection(database.CategoryTableName)
 	defer database.CloseMongoDbConnection()
 
+	return database.CountInMongoDB(filter)
+}
+
+func (CategoryMongoDbMapper) AssignUnownedCategories(ownerPlainId string) (int64, error) {
 	collection := database.GetMongoCollection(database.CategoryTableName)
+	result, err := collection.UpdateMany(context.TODO(),
+		bson.D{database.OwnerFilterInMongoDB("")},
+		bson.D{primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "owner_id", Value: util.ConvertOwner2ObjectId(ownerPlainId)},
+		}}})
 
-	// Empty filter to get all documents, with pagination
-	filter := bson.D{}
+	// Invalidate cache, the cached categories carry their owner
+	cache.GetCategoryCache().Clear()
+
+	if err != nil {
+		util.Logger.Errorw("assign unowned categories failed", "error", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// findMongoDbCategories returns a page of the matching categories ordered by name
+func findMongoDbCategories(filter bson.D, limit, offset int) []model.CategoryEntity {
+	collection := database.GetMongoCollection(database.CategoryTableName)
 
 	ctx := context.TODO()
 	findOptions := database.GetFindOptions()
@@ -186,7 +278,7 @@ func (CategoryMongoDbMapper) GetAllCategories(limit, offset int) []model.Categor
 
 	cursor, err := collection.Find(ctx, filter, findOptions)
 	if err != nil {
-		util.Logger.Errorw("query all categories failed", "error", err)
+		util.Logger.Errorw("query categories failed", "error", err)
 		return []model.CategoryEntity{}
 	}
 	defer cursor.Close(ctx)
@@ -204,15 +296,6 @@ func (CategoryMongoDbMapper) GetAllCategories(limit, offset int) []model.Categor
 	return targetEntityList
 }
 
-func (CategoryMongoDbMapper) CountAllCategories() int64 {
-	filter := bson.D{}
-
-	database.OpenMongoDbConnection(database.CategoryTableName)
-	defer database.CloseMongoDbConnection()
-
-	return database.CountInMongoDB(filter)
-}
-
 func convertCategoryEntity2BsonD(entity model.CategoryEntity) bson.D {
 	// 为空时自动生成新Id
 	if entity.Id == primitive.NilObjectID {
@@ -221,6 +304,7 @@ func convertCategoryEntity2BsonD(entity model.CategoryEntity) bson.D {
 
 	return bson.D{
 		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "owner_id", Value: entity.OwnerId},
 		primitive.E{Key: "parent_id", Value: entity.ParentId},
 		primitive.E{Key: "name", Value: entity.Name},
 		primitive.E{Key: "remark", Value: entity.Remark},
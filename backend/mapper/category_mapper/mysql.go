@@ -16,7 +16,7 @@ type CategoryMySqlMapper struct{}
 
 func (CategoryMySqlMapper) GetCategoryByObjectId(plainId string) model.CategoryEntity {
 	var sqlString bytes.Buffer
-	sqlString.WriteString("SELECT ID, PARENT_ID, NAME FROM ")
+	sqlString.WriteString("SELECT ID, OWNER_ID, PARENT_ID, NAME, REMARK, CREATE_TIME, MODIFY_TIME FROM ")
 	sqlString.WriteString(database.CategoryTableName)
 	sqlString.WriteString(" WHERE ID = ? ")
 
@@ -36,23 +36,23 @@ func (CategoryMySqlMapper) GetCategoryByObjectId(plainId string) model.CategoryE
 	return categoryEntity
 }
 
-func (CategoryMySqlMapper) GetCategoryByName(categoryName string) model.CategoryEntity {
+func (CategoryMySqlMapper) GetCategoryByName(ownerPlainId, categoryName string) model.CategoryEntity {
 	// Check cache first
 	categoryCache := cache.GetCategoryCache()
-	if cached, ok := categoryCache.GetByName(categoryName); ok {
+	if cached, ok := categoryCache.GetByName(ownerPlainId, categoryName); ok {
 		return *cached
 	}
 
 	// Cache miss - query database
 	var sqlString bytes.Buffer
-	sqlString.WriteString("SELECT ID, PARENT_ID, NAME FROM ")
+	sqlString.WriteString("SELECT ID, OWNER_ID, PARENT_ID, NAME, REMARK, CREATE_TIME, MODIFY_TIME FROM ")
 	sqlString.WriteString(database.CategoryTableName)
-	sqlString.WriteString(" WHERE NAME = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND NAME = ? ")
 
 	connection := database.GetMySqlConnection()
 	defer database.CloseMySqlConnection()
 
-	rows, err := connection.Query(sqlString.String(), categoryName)
+	rows, err := connection.Query(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), categoryName)
 	if err != nil {
 		util.Logger.Errorw("query failed", "error", err)
 	}
@@ -73,7 +73,7 @@ func (CategoryMySqlMapper) GetCategoryByName(categoryName string) model.Category
 
 func (CategoryMySqlMapper) GetCategoryByParentId(parentPlainId string) []model.CategoryEntity {
 	var sqlString bytes.Buffer
-	sqlString.WriteString("SELECT ID, PARENT_ID, NAME FROM ")
+	sqlString.WriteString("SELECT ID, OWNER_ID, PARENT_ID, NAME, REMARK, CREATE_TIME, MODIFY_TIME FROM ")
 	sqlString.WriteString(database.CategoryTableName)
 	sqlString.WriteString(" WHERE PARENT_ID = ? ")
 
@@ -92,15 +92,61 @@ func (CategoryMySqlMapper) GetCategoryByParentId(parentPlainId string) []model.C
 	return targetEntityList
 }
 
+func (CategoryMySqlMapper) GetCategoriesByOwnerId(ownerPlainId string, limit, offset int) []model.CategoryEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT ID, OWNER_ID, PARENT_ID, NAME, REMARK, CREATE_TIME, MODIFY_TIME FROM ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	args := []interface{}{util.ConvertOwner2ObjectId(ownerPlainId).Hex()}
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		args = append(args, limit, offset)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString.String(), args...)
+	if err != nil {
+		util.Logger.Errorw("query categories by owner failed", "error", err)
+		return []model.CategoryEntity{}
+	}
+
+	var targetEntityList []model.CategoryEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2CategoryEntity(rows))
+	}
+	return targetEntityList
+}
+
+func (CategoryMySqlMapper) CountCategoriesByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	err := connection.QueryRow(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count categories by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
 func (CategoryMySqlMapper) InsertCategoryByEntity(newEntity model.CategoryEntity) string {
-	operatingTime := time.Now()
-	newEntity.CreateTime = operatingTime
-	newEntity.ModifyTime = operatingTime
+	newEntity = fillOperatingTime(newEntity, time.Now())
 
 	var sqlString bytes.Buffer
 	sqlString.WriteString("INSERT ")
 	sqlString.WriteString(database.CategoryTableName)
 	sqlString.WriteString(" SET ID = ?, ")
+	sqlString.WriteString(" OWNER_ID = ?, ")
 	sqlString.WriteString(" PARENT_ID = ?, ")
 	sqlString.WriteString(" NAME = ?, ")
 	sqlString.WriteString(" REMARK = ?, ")
@@ -115,9 +161,9 @@ func (CategoryMySqlMapper) InsertCategoryByEntity(newEntity model.CategoryEntity
 		util.Logger.Errorw("insert failed", "error", err)
 	}
 
-	newPlainId := primitive.NewObjectID().Hex()
-	result, err := statement.Exec(newPlainId, newEntity.ParentId.Hex(), newEntity.Name,
-		newEntity.Remark, operatingTime, operatingTime)
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := statement.Exec(newPlainId, newEntity.OwnerId.Hex(), newEntity.ParentId.Hex(), newEntity.Name,
+		newEntity.Remark, newEntity.CreateTime, newEntity.ModifyTime)
 	if err != nil {
 		util.Logger.Errorw("insert failed", "error", err)
 	}
@@ -134,6 +180,59 @@ func (CategoryMySqlMapper) InsertCategoryByEntity(newEntity model.CategoryEntity
 	return newPlainId
 }
 
+func (CategoryMySqlMapper) BulkInsertCategories(entities []model.CategoryEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" (ID, OWNER_ID, PARENT_ID, NAME, REMARK, CREATE_TIME, MODIFY_TIME) VALUES ")
+
+	ids := make([]string, len(entities))
+	values := make([]interface{}, 0, len(entities)*7)
+
+	for i, entity := range entities {
+		if i > 0 {
+			sqlString.WriteString(", ")
+		}
+		sqlString.WriteString("(?, ?, ?, ?, ?, ?, ?)")
+
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		values = append(values, ids[i], entity.OwnerId.Hex(), entity.ParentId.Hex(), entity.Name,
+			entity.Remark, entity.CreateTime, entity.ModifyTime)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	statement, err := connection.Prepare(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("bulk insert prepare failed", "error", err)
+		return nil, err
+	}
+
+	result, err := statement.Exec(values...)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != int64(len(entities)) {
+		util.Logger.Errorw("bulk insert incomplete", "error", err, "expected", len(entities), "actual", rowsAffected)
+	}
+
+	// Invalidate cache on insert
+	cache.GetCategoryCache().Clear()
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
 func (CategoryMySqlMapper) UpdateCategoryByEntity(plainId string, updatedEntity model.CategoryEntity) model.CategoryEntity {
 	targetEntity := INSTANCE.GetCategoryByObjectId(plainId)
 	if targetEntity.IsEmpty() {
@@ -141,8 +240,9 @@ func (CategoryMySqlMapper) UpdateCategoryByEntity(plainId string, updatedEntity
 		return model.CategoryEntity{}
 	}
 
-	// Update fields from updatedEntity while preserving ID and CreateTime
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
 	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
 	updatedEntity.CreateTime = targetEntity.CreateTime
 	updatedEntity.ModifyTime = time.Now()
 
@@ -224,9 +324,37 @@ func (CategoryMySqlMapper) DeleteCategoryByObjectId(plainId string) model.Catego
 	return targetEntity
 }
 
+func (CategoryMySqlMapper) DeleteAllCategories() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.CategoryTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String())
+
+	// Invalidate cache on delete
+	cache.GetCategoryCache().Clear()
+
+	if err != nil {
+		util.Logger.Errorw("delete all categories failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all categories failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all categories deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
 func (CategoryMySqlMapper) GetAllCategories(limit, offset int) []model.CategoryEntity {
 	var sqlString bytes.Buffer
-	sqlString.WriteString("SELECT ID, PARENT_ID, NAME FROM ")
+	sqlString.WriteString("SELECT ID, OWNER_ID, PARENT_ID, NAME, REMARK, CREATE_TIME, MODIFY_TIME FROM ")
 	sqlString.WriteString(database.CategoryTableName)
 	sqlString.WriteString(" ORDER BY NAME ASC ")
 
@@ -281,19 +409,58 @@ func (CategoryMySqlMapper) CountAllCategories() int64 {
 	return count
 }
 
+func (CategoryMySqlMapper) AssignUnownedCategories(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+
+	// Invalidate cache, the cached categories carry their owner
+	cache.GetCategoryCache().Clear()
+
+	if err != nil {
+		util.Logger.Errorw("assign unowned categories failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// generatePlainId keeps a preset id (e.g. restored from backup) and generates one otherwise
+func generatePlainId(objectId primitive.ObjectID) string {
+	if objectId == primitive.NilObjectID {
+		return primitive.NewObjectID().Hex()
+	}
+	return objectId.Hex()
+}
+
 func convertRow2CategoryEntity(rows *sql.Rows) model.CategoryEntity {
 	var id string
-	var parentId string
+	var ownerId string
+	var parentId sql.NullString
 	var name string
+	var remark sql.NullString
+	var createTime string
+	var modifyTime string
 
-	err := rows.Scan(&id, &parentId, &name)
+	err := rows.Scan(&id, &ownerId, &parentId, &name, &remark, &createTime, &modifyTime)
 	if err != nil {
 		util.Logger.Errorw("covert into entity failed", "error", err)
 	}
 
 	return model.CategoryEntity{
-		Id:       util.Convert2ObjectId(id),
-		ParentId: util.Convert2ObjectId(parentId),
-		Name:     name,
+		Id:         util.Convert2ObjectId(id),
+		OwnerId:    util.Convert2ObjectId(ownerId),
+		ParentId:   util.Convert2ObjectId(parentId.String),
+		Name:       name,
+		Remark:     remark.String,
+		CreateTime: util.FormatDateTimeFromString(createTime),
+		ModifyTime: util.FormatDateTimeFromString(modifyTime),
 	}
 }
@@ -0,0 +1,345 @@
+package category_mapper
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/macar-x/cashlens/cache"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CategorySqliteMapper struct{}
+
+const sqliteCategoryColumns = "ID, OWNER_ID, PARENT_ID, NAME, REMARK, CREATE_TIME, MODIFY_TIME"
+
+func (CategorySqliteMapper) GetCategoryByObjectId(plainId string) model.CategoryEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCategoryColumns + " FROM ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := querySqliteCategories(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.CategoryEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (CategorySqliteMapper) GetCategoryByName(ownerPlainId, categoryName string) model.CategoryEntity {
+	// Check cache first
+	categoryCache := cache.GetCategoryCache()
+	if cached, ok := categoryCache.GetByName(ownerPlainId, categoryName); ok {
+		return *cached
+	}
+
+	// Cache miss - query database
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCategoryColumns + " FROM ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND NAME = ? ")
+
+	targetEntityList := querySqliteCategories(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), categoryName)
+	if len(targetEntityList) == 0 {
+		return model.CategoryEntity{}
+	}
+
+	// Store in cache if found
+	categoryEntity := targetEntityList[0]
+	categoryCache.Set(&categoryEntity)
+	return categoryEntity
+}
+
+func (CategorySqliteMapper) GetCategoryByParentId(parentPlainId string) []model.CategoryEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCategoryColumns + " FROM ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" WHERE PARENT_ID = ? ")
+
+	return querySqliteCategories(sqlString.String(), parentPlainId)
+}
+
+func (CategorySqliteMapper) GetCategoriesByOwnerId(ownerPlainId string, limit, offset int) []model.CategoryEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCategoryColumns + " FROM ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteCategories(sqlString.String(), ownerId, limit, offset)
+	}
+	return querySqliteCategories(sqlString.String(), ownerId)
+}
+
+func (CategorySqliteMapper) CountCategoriesByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	var count int64
+	err := database.GetSqliteConnection().QueryRow(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count categories by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (CategorySqliteMapper) InsertCategoryByEntity(newEntity model.CategoryEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" (" + sqliteCategoryColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?) ")
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		convertCategoryEntity2SqliteValues(newPlainId, newEntity)...)
+
+	// Invalidate cache on insert
+	cache.GetCategoryCache().Clear()
+
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (CategorySqliteMapper) BulkInsertCategories(entities []model.CategoryEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" (" + sqliteCategoryColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?) ")
+
+	// One transaction keeps the batch all-or-nothing and avoids a disk sync per row
+	transaction, err := database.GetSqliteConnection().Begin()
+	if err != nil {
+		util.Logger.Errorw("bulk insert begin failed", "error", err)
+		return nil, err
+	}
+
+	statement, err := transaction.Prepare(sqlString.String())
+	if err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert prepare failed", "error", err)
+		return nil, err
+	}
+	defer statement.Close()
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		if _, err := statement.Exec(convertCategoryEntity2SqliteValues(ids[i], entity)...); err != nil {
+			transaction.Rollback()
+			util.Logger.Errorw("bulk insert failed", "error", err)
+			return nil, err
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		util.Logger.Errorw("bulk insert commit failed", "error", err)
+		return nil, err
+	}
+
+	// Invalidate cache on insert
+	cache.GetCategoryCache().Clear()
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (CategorySqliteMapper) UpdateCategoryByEntity(plainId string, updatedEntity model.CategoryEntity) model.CategoryEntity {
+	targetEntity := INSTANCE.GetCategoryByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("category is not exist")
+		return model.CategoryEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" SET PARENT_ID = ?, ")
+	sqlString.WriteString(" NAME = ?, ")
+	sqlString.WriteString(" REMARK = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		updatedEntity.ParentId.Hex(), updatedEntity.Name, updatedEntity.Remark,
+		util.FormatDateTimeToString(updatedEntity.ModifyTime), plainId)
+
+	// Invalidate cache on update
+	cache.GetCategoryCache().Clear()
+
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.CategoryEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (CategorySqliteMapper) DeleteCategoryByObjectId(plainId string) model.CategoryEntity {
+	targetEntity := INSTANCE.GetCategoryByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("category is not exist")
+		return model.CategoryEntity{}
+	}
+
+	// can not delete a category that has referred child-categories.
+	if len(INSTANCE.GetCategoryByParentId(plainId)) != 0 {
+		util.Logger.Infoln("can not delete a category which has child-categories refer to")
+		return model.CategoryEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), plainId)
+
+	// Invalidate cache on delete
+	cache.GetCategoryCache().Clear()
+
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.CategoryEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (CategorySqliteMapper) DeleteAllCategories() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.CategoryTableName)
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String())
+
+	// Invalidate cache on delete
+	cache.GetCategoryCache().Clear()
+
+	if err != nil {
+		util.Logger.Errorw("delete all categories failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all categories failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all categories deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (CategorySqliteMapper) GetAllCategories(limit, offset int) []model.CategoryEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCategoryColumns + " FROM ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteCategories(sqlString.String(), limit, offset)
+	}
+	return querySqliteCategories(sqlString.String())
+}
+
+func (CategorySqliteMapper) CountAllCategories() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CategoryTableName)
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all categories failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (CategorySqliteMapper) AssignUnownedCategories(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.CategoryTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+
+	// Invalidate cache, the cached categories carry their owner
+	cache.GetCategoryCache().Clear()
+
+	if err != nil {
+		util.Logger.Errorw("assign unowned categories failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func querySqliteCategories(sqlString string, args ...interface{}) []model.CategoryEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.CategoryEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2CategoryEntity(rows))
+	}
+	return targetEntityList
+}
+
+// convertCategoryEntity2SqliteValues lists the column values in sqliteCategoryColumns order
+func convertCategoryEntity2SqliteValues(plainId string, entity model.CategoryEntity) []interface{} {
+	return []interface{}{
+		plainId,
+		entity.OwnerId.Hex(),
+		entity.ParentId.Hex(),
+		entity.Name,
+		entity.Remark,
+		util.FormatDateTimeToString(entity.CreateTime),
+		util.FormatDateTimeToString(entity.ModifyTime),
+	}
+}
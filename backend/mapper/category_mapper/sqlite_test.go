@@ -0,0 +1,114 @@
+package category_mapper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMain(m *testing.M) {
+	tempDir, err := os.MkdirTemp("", "cashlens-sqlite-")
+	if err != nil {
+		panic(err)
+	}
+	util.SetConfigByKey("db.sqlite.path", filepath.Join(tempDir, "category_test.db"))
+	INSTANCE = CategorySqliteMapper{}
+
+	code := m.Run()
+	os.RemoveAll(tempDir)
+	os.Exit(code)
+}
+
+func TestSqliteCategoryLifecycle(t *testing.T) {
+	mapper := CategorySqliteMapper{}
+	if _, err := mapper.DeleteAllCategories(); err != nil {
+		t.Fatalf("DeleteAllCategories() error = %v", err)
+	}
+
+	parentId := mapper.InsertCategoryByEntity(model.CategoryEntity{Name: "Food"})
+	childIds, err := mapper.BulkInsertCategories([]model.CategoryEntity{
+		{ParentId: util.Convert2ObjectId(parentId), Name: "Groceries"},
+		{ParentId: util.Convert2ObjectId(parentId), Name: "Restaurants"},
+	})
+	if err != nil || len(childIds) != 2 {
+		t.Fatalf("BulkInsertCategories() = %v, %v", childIds, err)
+	}
+
+	if category := mapper.GetCategoryByName("", "Groceries"); category.Id.Hex() != childIds[0] {
+		t.Errorf("GetCategoryByName() = %+v, want id %s", category, childIds[0])
+	}
+	if children := mapper.GetCategoryByParentId(parentId); len(children) != 2 {
+		t.Errorf("GetCategoryByParentId() returned %d categories, want 2", len(children))
+	}
+	if count := mapper.CountAllCategories(); count != 3 {
+		t.Errorf("CountAllCategories() = %d, want 3", count)
+	}
+
+	pageList := mapper.GetAllCategories(2, 1)
+	if len(pageList) != 2 || pageList[0].Name != "Groceries" {
+		t.Errorf("GetAllCategories(2, 1) = %+v, want page sorted by name", pageList)
+	}
+
+	// A parent with children is kept
+	if deleted := mapper.DeleteCategoryByObjectId(parentId); !deleted.IsEmpty() {
+		t.Errorf("DeleteCategoryByObjectId() deleted a category with children")
+	}
+
+	updated := mapper.UpdateCategoryByEntity(childIds[1], model.CategoryEntity{
+		ParentId: util.Convert2ObjectId(parentId),
+		Name:     "Dining Out",
+	})
+	if updated.Name != "Dining Out" || mapper.GetCategoryByObjectId(childIds[1]).Name != "Dining Out" {
+		t.Errorf("UpdateCategoryByEntity() did not rename the category")
+	}
+
+	deletedCount, err := mapper.DeleteAllCategories()
+	if err != nil || deletedCount != 3 {
+		t.Errorf("DeleteAllCategories() = %d, %v, want 3, nil", deletedCount, err)
+	}
+}
+
+func TestSqliteCategoryOwners(t *testing.T) {
+	mapper := CategorySqliteMapper{}
+	if _, err := mapper.DeleteAllCategories(); err != nil {
+		t.Fatalf("DeleteAllCategories() error = %v", err)
+	}
+
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	unownedId := mapper.InsertCategoryByEntity(model.CategoryEntity{Name: "Food"})
+	bobId := mapper.InsertCategoryByEntity(model.CategoryEntity{OwnerId: bob, Name: "Food"})
+
+	// The same name belongs to each owner separately
+	if category := mapper.GetCategoryByName(bob.Hex(), "Food"); category.Id.Hex() != bobId {
+		t.Errorf("GetCategoryByName(bob) = %+v, want id %s", category, bobId)
+	}
+	if category := mapper.GetCategoryByName(alice.Hex(), "Food"); !category.IsEmpty() {
+		t.Errorf("GetCategoryByName(alice) = %+v, want none", category)
+	}
+	if count := mapper.CountCategoriesByOwnerId(bob.Hex()); count != 1 {
+		t.Errorf("CountCategoriesByOwnerId(bob) = %d, want 1", count)
+	}
+
+	// An update keeps the owner
+	updated := mapper.UpdateCategoryByEntity(bobId, model.CategoryEntity{Name: "Groceries"})
+	if updated.OwnerId != bob || mapper.GetCategoryByObjectId(bobId).OwnerId != bob {
+		t.Errorf("UpdateCategoryByEntity() changed the owner to %s", updated.OwnerId.Hex())
+	}
+
+	// Only the categories without owner are handed over
+	assignedCount, err := mapper.AssignUnownedCategories(alice.Hex())
+	if err != nil || assignedCount != 1 {
+		t.Fatalf("AssignUnownedCategories() = %d, %v, want 1, nil", assignedCount, err)
+	}
+	aliceList := mapper.GetCategoriesByOwnerId(alice.Hex(), 0, 0)
+	if len(aliceList) != 1 || aliceList[0].Id.Hex() != unownedId {
+		t.Errorf("GetCategoriesByOwnerId(alice) = %+v, want the unowned category", aliceList)
+	}
+	if count := mapper.CountCategoriesByOwnerId(""); count != 0 {
+		t.Errorf("CountCategoriesByOwnerId(\"\") = %d, want 0", count)
+	}
+}
@@ -0,0 +1,58 @@
+package category_rule_mapper
+
+import (
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+)
+
+var INSTANCE CategoryRuleMapper
+
+// CategoryRuleMapper stores category rules. Every owner has category rules of their own, a blank owner stands
+// for the category rules no user owns; lookups by id and the GetAll/DeleteAll methods span all owners.
+type CategoryRuleMapper interface {
+	GetCategoryRuleByObjectId(plainId string) model.CategoryRuleEntity
+	GetCategoryRuleByName(ownerPlainId, ruleName string) model.CategoryRuleEntity
+	GetCategoryRulesByOwnerId(ownerPlainId string, limit, offset int) []model.CategoryRuleEntity
+	CountCategoryRulesByOwnerId(ownerPlainId string) int64
+	InsertCategoryRuleByEntity(newEntity model.CategoryRuleEntity) string
+	BulkInsertCategoryRules(entities []model.CategoryRuleEntity) ([]string, error)
+	UpdateCategoryRuleByEntity(plainId string, updatedEntity model.CategoryRuleEntity) model.CategoryRuleEntity
+	// GetAllCategoryRules lists the rules in the order they are tried: priority descending, then name
+	GetAllCategoryRules(limit, offset int) []model.CategoryRuleEntity
+	CountAllCategoryRules() int64
+	CountCategoryRulesByCategoryId(categoryPlainId string) int64
+	CountCategoryRulesByPayeeId(payeePlainId string) int64
+	CountCategoryRulesByAccountId(accountPlainId string) int64
+	DeleteCategoryRuleByObjectId(plainId string) model.CategoryRuleEntity
+	DeleteAllCategoryRules() (int64, error)
+	AssignUnownedCategoryRules(ownerPlainId string) (int64, error)
+}
+
+func init() {
+	switch util.GetConfigByKey("db.type") {
+	case "mongodb":
+		INSTANCE = CategoryRuleMongoDbMapper{}
+	case "mysql":
+		INSTANCE = CategoryRuleMySqlMapper{}
+	case "sqlite":
+		INSTANCE = CategoryRuleSqliteMapper{}
+	case "memory":
+		INSTANCE = NewCategoryRuleMemoryMapper()
+	default:
+		panic("database type not supported")
+	}
+}
+
+// fillOperatingTime keeps create/modify times carried over from a backup,
+// and stamps the operating time on brand-new records.
+func fillOperatingTime(entity model.CategoryRuleEntity, operatingTime time.Time) model.CategoryRuleEntity {
+	if entity.CreateTime.IsZero() {
+		entity.CreateTime = operatingTime
+	}
+	if entity.ModifyTime.IsZero() {
+		entity.ModifyTime = operatingTime
+	}
+	return entity
+}
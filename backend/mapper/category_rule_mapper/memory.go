@@ -0,0 +1,238 @@
+package category_rule_mapper
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CategoryRuleMemoryMapper keeps categorization rules in process memory, nothing is persisted.
+// It is safe for concurrent use; copies of the mapper share the same data.
+type CategoryRuleMemoryMapper struct {
+	store *categoryRuleMemoryStore
+}
+
+type categoryRuleMemoryStore struct {
+	mutex   sync.RWMutex
+	records map[primitive.ObjectID]model.CategoryRuleEntity
+}
+
+// NewCategoryRuleMemoryMapper returns an empty in-memory mapper
+func NewCategoryRuleMemoryMapper() CategoryRuleMemoryMapper {
+	return CategoryRuleMemoryMapper{
+		store: &categoryRuleMemoryStore{
+			records: make(map[primitive.ObjectID]model.CategoryRuleEntity),
+		},
+	}
+}
+
+func (mapper CategoryRuleMemoryMapper) GetCategoryRuleByObjectId(plainId string) model.CategoryRuleEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("category rule's id is not acceptable")
+		return model.CategoryRuleEntity{}
+	}
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return mapper.store.records[objectId]
+}
+
+func (mapper CategoryRuleMemoryMapper) GetCategoryRuleByName(ownerPlainId, ruleName string) model.CategoryRuleEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.CategoryRuleEntity) bool {
+		return entity.OwnerId == ownerId && entity.Name == ruleName
+	})
+	if len(targetEntityList) == 0 {
+		return model.CategoryRuleEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (mapper CategoryRuleMemoryMapper) InsertCategoryRuleByEntity(newEntity model.CategoryRuleEntity) string {
+	newPlainIdList, err := mapper.BulkInsertCategoryRules([]model.CategoryRuleEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+	return newPlainIdList[0]
+}
+
+func (mapper CategoryRuleMemoryMapper) BulkInsertCategoryRules(entities []model.CategoryRuleEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	operatingTime := time.Now()
+	newEntityList := make([]model.CategoryRuleEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.records[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate category rule id: " + entity.Id.Hex())
+		}
+		batchIds[entity.Id] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.records[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper CategoryRuleMemoryMapper) UpdateCategoryRuleByEntity(plainId string, updatedEntity model.CategoryRuleEntity) model.CategoryRuleEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("category rule is not exist")
+		return model.CategoryRuleEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+	mapper.store.records[objectId] = updatedEntity
+	return updatedEntity
+}
+
+func (mapper CategoryRuleMemoryMapper) GetAllCategoryRules(limit, offset int) []model.CategoryRuleEntity {
+	targetEntityList := mapper.filter(func(model.CategoryRuleEntity) bool { return true })
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper CategoryRuleMemoryMapper) GetCategoryRulesByOwnerId(ownerPlainId string, limit, offset int) []model.CategoryRuleEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.CategoryRuleEntity) bool {
+		return entity.OwnerId == ownerId
+	})
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper CategoryRuleMemoryMapper) CountAllCategoryRules() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.records))
+}
+
+func (mapper CategoryRuleMemoryMapper) CountCategoryRulesByOwnerId(ownerPlainId string) int64 {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	return int64(len(mapper.filter(func(entity model.CategoryRuleEntity) bool {
+		return entity.OwnerId == ownerId
+	})))
+}
+
+func (mapper CategoryRuleMemoryMapper) CountCategoryRulesByCategoryId(categoryPlainId string) int64 {
+	categoryId := util.Convert2ObjectId(categoryPlainId)
+	return int64(len(mapper.filter(func(entity model.CategoryRuleEntity) bool {
+		return entity.CategoryId == categoryId
+	})))
+}
+
+func (mapper CategoryRuleMemoryMapper) CountCategoryRulesByPayeeId(payeePlainId string) int64 {
+	payeeId := util.Convert2ObjectId(payeePlainId)
+	return int64(len(mapper.filter(func(entity model.CategoryRuleEntity) bool {
+		return entity.PayeeId == payeeId
+	})))
+}
+
+func (mapper CategoryRuleMemoryMapper) CountCategoryRulesByAccountId(accountPlainId string) int64 {
+	accountId := util.Convert2ObjectId(accountPlainId)
+	return int64(len(mapper.filter(func(entity model.CategoryRuleEntity) bool {
+		return entity.AccountId == accountId
+	})))
+}
+
+func (mapper CategoryRuleMemoryMapper) DeleteCategoryRuleByObjectId(plainId string) model.CategoryRuleEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("category rule is not exist")
+		return model.CategoryRuleEntity{}
+	}
+
+	delete(mapper.store.records, objectId)
+	return targetEntity
+}
+
+func (mapper CategoryRuleMemoryMapper) DeleteAllCategoryRules() (int64, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	deletedCount := int64(len(mapper.store.records))
+	mapper.store.records = make(map[primitive.ObjectID]model.CategoryRuleEntity)
+	return deletedCount, nil
+}
+
+func (mapper CategoryRuleMemoryMapper) AssignUnownedCategoryRules(ownerPlainId string) (int64, error) {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	var assignedCount int64
+	for id, entity := range mapper.store.records {
+		if entity.OwnerId == primitive.NilObjectID {
+			entity.OwnerId = ownerId
+			mapper.store.records[id] = entity
+			assignedCount++
+		}
+	}
+	return assignedCount, nil
+}
+
+// paginate cuts one page out of the ordered category rules, a zero limit keeps all of them
+func paginate(targetEntityList []model.CategoryRuleEntity, limit, offset int) []model.CategoryRuleEntity {
+	if offset >= len(targetEntityList) {
+		return []model.CategoryRuleEntity{}
+	}
+	targetEntityList = targetEntityList[offset:]
+	if limit > 0 && limit < len(targetEntityList) {
+		targetEntityList = targetEntityList[:limit]
+	}
+	return targetEntityList
+}
+
+// filter returns the matching rules in the order they are tried, like the database mappers
+func (mapper CategoryRuleMemoryMapper) filter(isMatched func(entity model.CategoryRuleEntity) bool) []model.CategoryRuleEntity {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	var targetEntityList []model.CategoryRuleEntity
+	for _, entity := range mapper.store.records {
+		if isMatched(entity) {
+			targetEntityList = append(targetEntityList, entity)
+		}
+	}
+
+	sort.Slice(targetEntityList, func(i, j int) bool {
+		if targetEntityList[i].Priority != targetEntityList[j].Priority {
+			return targetEntityList[i].Priority > targetEntityList[j].Priority
+		}
+		if targetEntityList[i].Name != targetEntityList[j].Name {
+			return targetEntityList[i].Name < targetEntityList[j].Name
+		}
+		return targetEntityList[i].Id.Hex() < targetEntityList[j].Id.Hex()
+	})
+	return targetEntityList
+}
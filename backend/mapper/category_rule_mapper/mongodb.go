@@ -0,0 +1,303 @@
+package category_rule_mapper
+
+import (
+	"context"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CategoryRuleMongoDbMapper struct{}
+
+func (CategoryRuleMongoDbMapper) GetCategoryRuleByObjectId(plainId string) model.CategoryRuleEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("category rule's id is not acceptable")
+		return model.CategoryRuleEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.CategoryRuleTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2CategoryRuleEntity(database.GetOneInMongoDB(filter))
+}
+
+func (CategoryRuleMongoDbMapper) GetCategoryRuleByName(ownerPlainId, ruleName string) model.CategoryRuleEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+		primitive.E{Key: "name", Value: ruleName},
+	}
+
+	database.OpenMongoDbConnection(database.CategoryRuleTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2CategoryRuleEntity(database.GetOneInMongoDB(filter))
+}
+
+func (CategoryRuleMongoDbMapper) InsertCategoryRuleByEntity(newEntity model.CategoryRuleEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	database.OpenMongoDbConnection(database.CategoryRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	newCategoryRuleId := database.InsertOneInMongoDB(convertCategoryRuleEntity2BsonD(newEntity))
+	return newCategoryRuleId.Hex()
+}
+
+func (CategoryRuleMongoDbMapper) BulkInsertCategoryRules(entities []model.CategoryRuleEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		documents[i] = convertCategoryRuleEntity2BsonD(fillOperatingTime(entity, operatingTime))
+	}
+
+	collection := database.GetMongoCollection(database.CategoryRuleTableName)
+	result, err := collection.InsertMany(context.TODO(), documents)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	ids := make([]string, len(result.InsertedIDs))
+	for i, id := range result.InsertedIDs {
+		ids[i] = id.(primitive.ObjectID).Hex()
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (CategoryRuleMongoDbMapper) UpdateCategoryRuleByEntity(plainId string, updatedEntity model.CategoryRuleEntity) model.CategoryRuleEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("category rule's id is not acceptable")
+		return model.CategoryRuleEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.CategoryRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2CategoryRuleEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("category rule is not exist")
+		return model.CategoryRuleEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	rowsAffected := database.UpdateManyInMongoDB(filter, convertCategoryRuleEntity2BsonD(updatedEntity))
+	if rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "rows_affected", rowsAffected)
+		return model.CategoryRuleEntity{}
+	}
+	return updatedEntity
+}
+
+func (CategoryRuleMongoDbMapper) GetAllCategoryRules(limit, offset int) []model.CategoryRuleEntity {
+	return findMongoCategoryRulesPage(bson.D{}, limit, offset)
+}
+
+func (CategoryRuleMongoDbMapper) GetCategoryRulesByOwnerId(ownerPlainId string, limit, offset int) []model.CategoryRuleEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+	return findMongoCategoryRulesPage(filter, limit, offset)
+}
+
+func (CategoryRuleMongoDbMapper) CountAllCategoryRules() int64 {
+	database.OpenMongoDbConnection(database.CategoryRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(bson.D{})
+}
+
+func (CategoryRuleMongoDbMapper) CountCategoryRulesByOwnerId(ownerPlainId string) int64 {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+
+	database.OpenMongoDbConnection(database.CategoryRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+func (CategoryRuleMongoDbMapper) CountCategoryRulesByCategoryId(categoryPlainId string) int64 {
+	filter := bson.D{
+		primitive.E{Key: "category_id", Value: util.Convert2ObjectId(categoryPlainId)},
+	}
+
+	database.OpenMongoDbConnection(database.CategoryRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+func (CategoryRuleMongoDbMapper) CountCategoryRulesByPayeeId(payeePlainId string) int64 {
+	filter := bson.D{
+		primitive.E{Key: "payee_id", Value: util.Convert2ObjectId(payeePlainId)},
+	}
+
+	database.OpenMongoDbConnection(database.CategoryRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+func (CategoryRuleMongoDbMapper) CountCategoryRulesByAccountId(accountPlainId string) int64 {
+	filter := bson.D{
+		primitive.E{Key: "account_id", Value: util.Convert2ObjectId(accountPlainId)},
+	}
+
+	database.OpenMongoDbConnection(database.CategoryRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+func (CategoryRuleMongoDbMapper) DeleteCategoryRuleByObjectId(plainId string) model.CategoryRuleEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("category rule's id is not acceptable")
+		return model.CategoryRuleEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.CategoryRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2CategoryRuleEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("category rule is not exist")
+		return model.CategoryRuleEntity{}
+	}
+
+	rowsAffected := database.DeleteManyInMongoDB(filter)
+	if rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "rows_affected", rowsAffected)
+		return model.CategoryRuleEntity{}
+	}
+	return targetEntity
+}
+
+func (CategoryRuleMongoDbMapper) DeleteAllCategoryRules() (int64, error) {
+	collection := database.GetMongoCollection(database.CategoryRuleTableName)
+	result, err := collection.DeleteMany(context.TODO(), bson.D{})
+	if err != nil {
+		util.Logger.Errorw("delete all category rules failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all category rules deleted", "count", result.DeletedCount)
+	return result.DeletedCount, nil
+}
+
+func (CategoryRuleMongoDbMapper) AssignUnownedCategoryRules(ownerPlainId string) (int64, error) {
+	collection := database.GetMongoCollection(database.CategoryRuleTableName)
+	result, err := collection.UpdateMany(context.TODO(),
+		bson.D{database.OwnerFilterInMongoDB("")},
+		bson.D{primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "owner_id", Value: util.ConvertOwner2ObjectId(ownerPlainId)},
+		}}})
+	if err != nil {
+		util.Logger.Errorw("assign unowned category rules failed", "error", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// findMongoCategoryRulesPage returns one page of the matching category rules in listing order
+func findMongoCategoryRulesPage(filter bson.D, limit, offset int) []model.CategoryRuleEntity {
+	findOptions := database.GetFindOptions()
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		findOptions.SetSkip(int64(offset))
+	}
+	// Sort by priority descending, then name ascending
+	findOptions.SetSort(bson.D{
+		primitive.E{Key: "priority", Value: -1},
+		primitive.E{Key: "name", Value: 1},
+	})
+
+	return findMongoCategoryRules(filter, findOptions)
+}
+
+func findMongoCategoryRules(filter bson.D, findOptions *options.FindOptions) []model.CategoryRuleEntity {
+	collection := database.GetMongoCollection(database.CategoryRuleTableName)
+
+	ctx := context.TODO()
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		util.Logger.Errorw("query category rules failed", "error", err)
+		return []model.CategoryRuleEntity{}
+	}
+	defer cursor.Close(ctx)
+
+	var targetEntityList []model.CategoryRuleEntity
+	for cursor.Next(ctx) {
+		var bsonM bson.M
+		if err := cursor.Decode(&bsonM); err != nil {
+			util.Logger.Errorw("decode failed", "error", err)
+			continue
+		}
+		targetEntityList = append(targetEntityList, convertBsonM2CategoryRuleEntity(bsonM))
+	}
+	return targetEntityList
+}
+
+func convertCategoryRuleEntity2BsonD(entity model.CategoryRuleEntity) bson.D {
+	// 为空时自动生成新Id
+	if entity.Id == primitive.NilObjectID {
+		entity.Id = primitive.NewObjectID()
+	}
+
+	return bson.D{
+		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "owner_id", Value: entity.OwnerId},
+		primitive.E{Key: "name", Value: entity.Name},
+		primitive.E{Key: "priority", Value: entity.Priority},
+		primitive.E{Key: "flow_type", Value: entity.FlowType},
+		primitive.E{Key: "match_type", Value: entity.MatchType},
+		primitive.E{Key: "pattern", Value: entity.Pattern},
+		primitive.E{Key: "min_amount", Value: entity.MinAmount},
+		primitive.E{Key: "max_amount", Value: entity.MaxAmount},
+		primitive.E{Key: "payee_id", Value: entity.PayeeId},
+		primitive.E{Key: "account_id", Value: entity.AccountId},
+		primitive.E{Key: "category_id", Value: entity.CategoryId},
+		primitive.E{Key: "tags", Value: entity.Tags},
+		primitive.E{Key: "create_time", Value: entity.CreateTime},
+		primitive.E{Key: "modify_time", Value: entity.ModifyTime},
+	}
+}
+
+func convertBsonM2CategoryRuleEntity(bsonM bson.M) model.CategoryRuleEntity {
+	var newEntity model.CategoryRuleEntity
+	if err := database.DecodeBsonM(bsonM, &newEntity); err != nil {
+		panic(err)
+	}
+	return newEntity
+}
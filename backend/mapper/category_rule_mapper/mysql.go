@@ -0,0 +1,409 @@
+package category_rule_mapper
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CategoryRuleMySqlMapper struct{}
+
+const mySqlCategoryRuleColumns = "ID, OWNER_ID, NAME, PRIORITY, FLOW_TYPE, MATCH_TYPE, PATTERN, MIN_AMOUNT, MAX_AMOUNT, " +
+	"PAYEE_ID, ACCOUNT_ID, CATEGORY_ID, TAGS, CREATE_TIME, MODIFY_TIME"
+
+const mySqlCategoryRulePlaceholders = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+func (CategoryRuleMySqlMapper) GetCategoryRuleByObjectId(plainId string) model.CategoryRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlCategoryRuleColumns + " FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := queryMySqlCategoryRules(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.CategoryRuleEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (CategoryRuleMySqlMapper) GetCategoryRuleByName(ownerPlainId, ruleName string) model.CategoryRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlCategoryRuleColumns + " FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND NAME = ? ")
+
+	targetEntityList := queryMySqlCategoryRules(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), ruleName)
+	if len(targetEntityList) == 0 {
+		return model.CategoryRuleEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (CategoryRuleMySqlMapper) InsertCategoryRuleByEntity(newEntity model.CategoryRuleEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" (" + mySqlCategoryRuleColumns + ") VALUES " + mySqlCategoryRulePlaceholders)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := connection.Exec(sqlString.String(), convertCategoryRuleEntity2MySqlValues(newPlainId, newEntity)...)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (CategoryRuleMySqlMapper) BulkInsertCategoryRules(entities []model.CategoryRuleEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" (" + mySqlCategoryRuleColumns + ") VALUES ")
+
+	ids := make([]string, len(entities))
+	values := make([]interface{}, 0, len(entities)*15)
+
+	for i, entity := range entities {
+		if i > 0 {
+			sqlString.WriteString(", ")
+		}
+		sqlString.WriteString(mySqlCategoryRulePlaceholders)
+
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		values = append(values, convertCategoryRuleEntity2MySqlValues(ids[i], entity)...)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), values...)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != int64(len(entities)) {
+		util.Logger.Errorw("bulk insert incomplete", "error", err, "expected", len(entities), "actual", rowsAffected)
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (CategoryRuleMySqlMapper) UpdateCategoryRuleByEntity(plainId string, updatedEntity model.CategoryRuleEntity) model.CategoryRuleEntity {
+	targetEntity := INSTANCE.GetCategoryRuleByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("category rule is not exist")
+		return model.CategoryRuleEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" SET NAME = ?, ")
+	sqlString.WriteString(" PRIORITY = ?, ")
+	sqlString.WriteString(" FLOW_TYPE = ?, ")
+	sqlString.WriteString(" MATCH_TYPE = ?, ")
+	sqlString.WriteString(" PATTERN = ?, ")
+	sqlString.WriteString(" MIN_AMOUNT = ?, ")
+	sqlString.WriteString(" MAX_AMOUNT = ?, ")
+	sqlString.WriteString(" PAYEE_ID = ?, ")
+	sqlString.WriteString(" ACCOUNT_ID = ?, ")
+	sqlString.WriteString(" CATEGORY_ID = ?, ")
+	sqlString.WriteString(" TAGS = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	// Same values as on insert, without the id, owner and create time
+	values := convertCategoryRuleEntity2MySqlValues(plainId, updatedEntity)
+	values = append(values[2:len(values)-2], updatedEntity.ModifyTime, plainId)
+	result, err := connection.Exec(sqlString.String(), values...)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.CategoryRuleEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (CategoryRuleMySqlMapper) GetAllCategoryRules(limit, offset int) []model.CategoryRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlCategoryRuleColumns + " FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" ORDER BY PRIORITY DESC, NAME ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlCategoryRules(sqlString.String(), limit, offset)
+	}
+	return queryMySqlCategoryRules(sqlString.String())
+}
+
+func (CategoryRuleMySqlMapper) GetCategoryRulesByOwnerId(ownerPlainId string, limit, offset int) []model.CategoryRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlCategoryRuleColumns + " FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY PRIORITY DESC, NAME ASC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlCategoryRules(sqlString.String(), ownerId, limit, offset)
+	}
+	return queryMySqlCategoryRules(sqlString.String(), ownerId)
+}
+
+func (CategoryRuleMySqlMapper) CountCategoryRulesByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	err := connection.QueryRow(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count category rules by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (CategoryRuleMySqlMapper) CountAllCategoryRules() int64 {
+	return countMySqlCategoryRules("")
+}
+
+func (CategoryRuleMySqlMapper) CountCategoryRulesByCategoryId(categoryPlainId string) int64 {
+	return countMySqlCategoryRules(" WHERE CATEGORY_ID = ? ", categoryPlainId)
+}
+
+func (CategoryRuleMySqlMapper) CountCategoryRulesByPayeeId(payeePlainId string) int64 {
+	return countMySqlCategoryRules(" WHERE PAYEE_ID = ? ", payeePlainId)
+}
+
+func (CategoryRuleMySqlMapper) CountCategoryRulesByAccountId(accountPlainId string) int64 {
+	return countMySqlCategoryRules(" WHERE ACCOUNT_ID = ? ", accountPlainId)
+}
+
+func (CategoryRuleMySqlMapper) DeleteCategoryRuleByObjectId(plainId string) model.CategoryRuleEntity {
+	targetEntity := INSTANCE.GetCategoryRuleByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("category rule is not exist")
+		return model.CategoryRuleEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.CategoryRuleEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (CategoryRuleMySqlMapper) DeleteAllCategoryRules() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all category rules failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all category rules failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all category rules deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (CategoryRuleMySqlMapper) AssignUnownedCategoryRules(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned category rules failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func queryMySqlCategoryRules(sqlString string, args ...interface{}) []model.CategoryRuleEntity {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.CategoryRuleEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2CategoryRuleEntity(rows))
+	}
+	return targetEntityList
+}
+
+func countMySqlCategoryRules(whereString string, args ...interface{}) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(whereString)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow(sqlString.String(), args...).Scan(&count); err != nil {
+		util.Logger.Errorw("count category rules failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+// generatePlainId keeps a preset id (e.g. restored from backup) and generates one otherwise
+func generatePlainId(objectId primitive.ObjectID) string {
+	if objectId == primitive.NilObjectID {
+		return primitive.NewObjectID().Hex()
+	}
+	return objectId.Hex()
+}
+
+// convertCategoryRuleEntity2MySqlValues lists the column values in mySqlCategoryRuleColumns order,
+// the tags are folded into one comma separated column since they never contain a comma.
+func convertCategoryRuleEntity2MySqlValues(plainId string, entity model.CategoryRuleEntity) []interface{} {
+	return []interface{}{
+		plainId,
+		entity.OwnerId.Hex(),
+		entity.Name,
+		entity.Priority,
+		entity.FlowType,
+		entity.MatchType,
+		entity.Pattern,
+		entity.MinAmount,
+		entity.MaxAmount,
+		entity.PayeeId.Hex(),
+		entity.AccountId.Hex(),
+		entity.CategoryId.Hex(),
+		strings.Join(entity.Tags, ","),
+		entity.CreateTime,
+		entity.ModifyTime,
+	}
+}
+
+func convertRow2CategoryRuleEntity(rows *sql.Rows) model.CategoryRuleEntity {
+	var id string
+	var ownerId string
+	var name string
+	var priority int
+	var flowType string
+	var matchType string
+	var pattern string
+	var minAmount decimal.Decimal
+	var maxAmount decimal.Decimal
+	var payeeId string
+	var accountId string
+	var categoryId string
+	var tags string
+	var createTime string
+	var modifyTime string
+
+	err := rows.Scan(&id, &ownerId, &name, &priority, &flowType, &matchType, &pattern, &minAmount, &maxAmount,
+		&payeeId, &accountId, &categoryId, &tags, &createTime, &modifyTime)
+	if err != nil {
+		util.Logger.Errorw("covert into entity failed", "error", err)
+	}
+
+	var tagList []string
+	if tags != "" {
+		tagList = strings.Split(tags, ",")
+	}
+
+	return model.CategoryRuleEntity{
+		Id:         util.Convert2ObjectId(id),
+		OwnerId:    util.Convert2ObjectId(ownerId),
+		Name:       name,
+		Priority:   priority,
+		FlowType:   flowType,
+		MatchType:  matchType,
+		Pattern:    pattern,
+		MinAmount:  minAmount,
+		MaxAmount:  maxAmount,
+		PayeeId:    util.Convert2ObjectId(payeeId),
+		AccountId:  util.Convert2ObjectId(accountId),
+		CategoryId: util.Convert2ObjectId(categoryId),
+		Tags:       tagList,
+		CreateTime: util.FormatDateTimeFromString(createTime),
+		ModifyTime: util.FormatDateTimeFromString(modifyTime),
+	}
+}
@@ -0,0 +1,316 @@
+package category_rule_mapper
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CategoryRuleSqliteMapper struct{}
+
+const sqliteCategoryRuleColumns = mySqlCategoryRuleColumns
+
+func (CategoryRuleSqliteMapper) GetCategoryRuleByObjectId(plainId string) model.CategoryRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCategoryRuleColumns + " FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := querySqliteCategoryRules(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.CategoryRuleEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (CategoryRuleSqliteMapper) GetCategoryRuleByName(ownerPlainId, ruleName string) model.CategoryRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCategoryRuleColumns + " FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND NAME = ? ")
+
+	targetEntityList := querySqliteCategoryRules(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), ruleName)
+	if len(targetEntityList) == 0 {
+		return model.CategoryRuleEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (mapper CategoryRuleSqliteMapper) InsertCategoryRuleByEntity(newEntity model.CategoryRuleEntity) string {
+	newPlainIdList, err := mapper.BulkInsertCategoryRules([]model.CategoryRuleEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+	return newPlainIdList[0]
+}
+
+func (CategoryRuleSqliteMapper) BulkInsertCategoryRules(entities []model.CategoryRuleEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" (" + sqliteCategoryRuleColumns + ") VALUES " + mySqlCategoryRulePlaceholders)
+
+	// One transaction keeps the batch all-or-nothing and avoids a disk sync per row
+	transaction, err := database.GetSqliteConnection().Begin()
+	if err != nil {
+		util.Logger.Errorw("bulk insert begin failed", "error", err)
+		return nil, err
+	}
+
+	statement, err := transaction.Prepare(sqlString.String())
+	if err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert prepare failed", "error", err)
+		return nil, err
+	}
+	defer statement.Close()
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		if _, err := statement.Exec(convertCategoryRuleEntity2SqliteValues(ids[i], entity)...); err != nil {
+			transaction.Rollback()
+			util.Logger.Errorw("bulk insert failed", "error", err)
+			return nil, err
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		util.Logger.Errorw("bulk insert commit failed", "error", err)
+		return nil, err
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (CategoryRuleSqliteMapper) UpdateCategoryRuleByEntity(plainId string, updatedEntity model.CategoryRuleEntity) model.CategoryRuleEntity {
+	targetEntity := INSTANCE.GetCategoryRuleByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("category rule is not exist")
+		return model.CategoryRuleEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" SET NAME = ?, ")
+	sqlString.WriteString(" PRIORITY = ?, ")
+	sqlString.WriteString(" FLOW_TYPE = ?, ")
+	sqlString.WriteString(" MATCH_TYPE = ?, ")
+	sqlString.WriteString(" PATTERN = ?, ")
+	sqlString.WriteString(" MIN_AMOUNT = ?, ")
+	sqlString.WriteString(" MAX_AMOUNT = ?, ")
+	sqlString.WriteString(" PAYEE_ID = ?, ")
+	sqlString.WriteString(" ACCOUNT_ID = ?, ")
+	sqlString.WriteString(" CATEGORY_ID = ?, ")
+	sqlString.WriteString(" TAGS = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	// Same values as on insert, without the id, owner and create time
+	values := convertCategoryRuleEntity2SqliteValues(plainId, updatedEntity)
+	values = append(values[2:len(values)-2], util.FormatDateTimeToString(updatedEntity.ModifyTime), plainId)
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), values...)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.CategoryRuleEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (CategoryRuleSqliteMapper) GetAllCategoryRules(limit, offset int) []model.CategoryRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCategoryRuleColumns + " FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" ORDER BY PRIORITY DESC, NAME ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteCategoryRules(sqlString.String(), limit, offset)
+	}
+	return querySqliteCategoryRules(sqlString.String())
+}
+
+func (CategoryRuleSqliteMapper) GetCategoryRulesByOwnerId(ownerPlainId string, limit, offset int) []model.CategoryRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteCategoryRuleColumns + " FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY PRIORITY DESC, NAME ASC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteCategoryRules(sqlString.String(), ownerId, limit, offset)
+	}
+	return querySqliteCategoryRules(sqlString.String(), ownerId)
+}
+
+func (CategoryRuleSqliteMapper) CountCategoryRulesByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	var count int64
+	err := database.GetSqliteConnection().QueryRow(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count category rules by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (CategoryRuleSqliteMapper) CountAllCategoryRules() int64 {
+	return countSqliteCategoryRules("")
+}
+
+func (CategoryRuleSqliteMapper) CountCategoryRulesByCategoryId(categoryPlainId string) int64 {
+	return countSqliteCategoryRules(" WHERE CATEGORY_ID = ? ", categoryPlainId)
+}
+
+func (CategoryRuleSqliteMapper) CountCategoryRulesByPayeeId(payeePlainId string) int64 {
+	return countSqliteCategoryRules(" WHERE PAYEE_ID = ? ", payeePlainId)
+}
+
+func (CategoryRuleSqliteMapper) CountCategoryRulesByAccountId(accountPlainId string) int64 {
+	return countSqliteCategoryRules(" WHERE ACCOUNT_ID = ? ", accountPlainId)
+}
+
+func (CategoryRuleSqliteMapper) DeleteCategoryRuleByObjectId(plainId string) model.CategoryRuleEntity {
+	targetEntity := INSTANCE.GetCategoryRuleByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("category rule is not exist")
+		return model.CategoryRuleEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.CategoryRuleEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (CategoryRuleSqliteMapper) DeleteAllCategoryRules() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all category rules failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all category rules failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all category rules deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (CategoryRuleSqliteMapper) AssignUnownedCategoryRules(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned category rules failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func querySqliteCategoryRules(sqlString string, args ...interface{}) []model.CategoryRuleEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.CategoryRuleEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2CategoryRuleEntity(rows))
+	}
+	return targetEntityList
+}
+
+func countSqliteCategoryRules(whereString string, args ...interface{}) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.CategoryRuleTableName)
+	sqlString.WriteString(whereString)
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String(), args...).Scan(&count); err != nil {
+		util.Logger.Errorw("count category rules failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+// convertCategoryRuleEntity2SqliteValues lists the column values in sqliteCategoryRuleColumns order,
+// times are written as text like every other sqlite table.
+func convertCategoryRuleEntity2SqliteValues(plainId string, entity model.CategoryRuleEntity) []interface{} {
+	return []interface{}{
+		plainId,
+		entity.OwnerId.Hex(),
+		entity.Name,
+		entity.Priority,
+		entity.FlowType,
+		entity.MatchType,
+		entity.Pattern,
+		entity.MinAmount,
+		entity.MaxAmount,
+		entity.PayeeId.Hex(),
+		entity.AccountId.Hex(),
+		entity.CategoryId.Hex(),
+		strings.Join(entity.Tags, ","),
+		util.FormatDateTimeToString(entity.CreateTime),
+		util.FormatDateTimeToString(entity.ModifyTime),
+	}
+}
@@ -0,0 +1,134 @@
+package category_rule_mapper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMain(m *testing.M) {
+	tempDir, err := os.MkdirTemp("", "cashlens-sqlite-")
+	if err != nil {
+		panic(err)
+	}
+	util.SetConfigByKey("db.sqlite.path", filepath.Join(tempDir, "category_rule_test.db"))
+	INSTANCE = CategoryRuleSqliteMapper{}
+
+	code := m.Run()
+	os.RemoveAll(tempDir)
+	os.Exit(code)
+}
+
+func TestSqliteCategoryRuleLifecycle(t *testing.T) {
+	mapper := CategoryRuleSqliteMapper{}
+	if _, err := mapper.DeleteAllCategoryRules(); err != nil {
+		t.Fatalf("DeleteAllCategoryRules() error = %v", err)
+	}
+
+	categoryId := primitive.NewObjectID()
+	payeeId := primitive.NewObjectID()
+	coffeeRule := model.CategoryRuleEntity{
+		Name:       "Coffee",
+		Priority:   10,
+		FlowType:   model.FlowTypeOutcome,
+		MatchType:  model.PayeeRuleContains,
+		Pattern:    "coffee",
+		MaxAmount:  decimal.NewFromInt(20),
+		PayeeId:    payeeId,
+		CategoryId: categoryId,
+		Tags:       []string{"caffeine", "daily"},
+	}
+	coffeeId := mapper.InsertCategoryRuleByEntity(coffeeRule)
+	otherIds, err := mapper.BulkInsertCategoryRules([]model.CategoryRuleEntity{
+		{Name: "Fallback", Priority: -1, CategoryId: categoryId},
+		{Name: "Big Spend", Priority: 10, MinAmount: decimal.NewFromInt(500), CategoryId: primitive.NewObjectID()},
+	})
+	if err != nil || len(otherIds) != 2 {
+		t.Fatalf("BulkInsertCategoryRules() = %v, %v", otherIds, err)
+	}
+
+	coffee := mapper.GetCategoryRuleByObjectId(coffeeId)
+	if coffee.Pattern != "coffee" || coffee.PayeeId != payeeId || !coffee.MaxAmount.Equal(decimal.NewFromInt(20)) ||
+		!reflect.DeepEqual(coffee.Tags, coffeeRule.Tags) || !coffee.AccountId.IsZero() {
+		t.Errorf("GetCategoryRuleByObjectId() = %+v", coffee)
+	}
+	if fallback := mapper.GetCategoryRuleByName("", "Fallback"); fallback.Id.Hex() != otherIds[0] || fallback.Tags != nil {
+		t.Errorf("GetCategoryRuleByName() = %+v, want no tags", fallback)
+	}
+
+	allList := mapper.GetAllCategoryRules(0, 0)
+	if len(allList) != 3 || allList[0].Name != "Big Spend" || allList[1].Name != "Coffee" || allList[2].Name != "Fallback" {
+		t.Errorf("GetAllCategoryRules() = %+v, want priority descending then name", allList)
+	}
+	if count := mapper.CountCategoryRulesByCategoryId(categoryId.Hex()); count != 2 {
+		t.Errorf("CountCategoryRulesByCategoryId() = %d, want 2", count)
+	}
+	if count := mapper.CountCategoryRulesByPayeeId(payeeId.Hex()); count != 1 {
+		t.Errorf("CountCategoryRulesByPayeeId() = %d, want 1", count)
+	}
+
+	coffee.Priority = 20
+	coffee.Tags = nil
+	mapper.UpdateCategoryRuleByEntity(coffeeId, coffee)
+	if updated := mapper.GetCategoryRuleByObjectId(coffeeId); updated.Priority != 20 || updated.Tags != nil {
+		t.Errorf("UpdateCategoryRuleByEntity() did not store the changes, got %+v", updated)
+	}
+
+	if deleted := mapper.DeleteCategoryRuleByObjectId(otherIds[1]); deleted.Name != "Big Spend" {
+		t.Errorf("DeleteCategoryRuleByObjectId() = %+v", deleted)
+	}
+
+	deletedCount, err := mapper.DeleteAllCategoryRules()
+	if err != nil || deletedCount != 2 {
+		t.Errorf("DeleteAllCategoryRules() = %d, %v, want 2, nil", deletedCount, err)
+	}
+}
+
+func TestSqliteCategoryRuleOwners(t *testing.T) {
+	mapper := CategoryRuleSqliteMapper{}
+	if _, err := mapper.DeleteAllCategoryRules(); err != nil {
+		t.Fatalf("DeleteAllCategoryRules() error = %v", err)
+	}
+
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	unownedId := mapper.InsertCategoryRuleByEntity(model.CategoryRuleEntity{Name: "Coffee", CategoryId: primitive.NewObjectID()})
+	bobId := mapper.InsertCategoryRuleByEntity(model.CategoryRuleEntity{OwnerId: bob, Name: "Coffee", CategoryId: primitive.NewObjectID()})
+
+	// Owners look names up in their own rules only
+	if rule := mapper.GetCategoryRuleByName(bob.Hex(), "Coffee"); rule.Id.Hex() != bobId {
+		t.Errorf("GetCategoryRuleByName(bob) = %+v, want id %s", rule, bobId)
+	}
+	if rule := mapper.GetCategoryRuleByName(alice.Hex(), "Coffee"); !rule.IsEmpty() {
+		t.Errorf("GetCategoryRuleByName(alice) = %+v, want none", rule)
+	}
+
+	// An update keeps the owner
+	bobCategoryRule := mapper.GetCategoryRuleByObjectId(bobId)
+	bobCategoryRule.OwnerId = primitive.NilObjectID
+	mapper.UpdateCategoryRuleByEntity(bobId, bobCategoryRule)
+	if owner := mapper.GetCategoryRuleByObjectId(bobId).OwnerId; owner != bob {
+		t.Errorf("UpdateCategoryRuleByEntity() changed the owner to %s", owner.Hex())
+	}
+
+	// Only the rules without owner are handed over
+	assignedCount, err := mapper.AssignUnownedCategoryRules(alice.Hex())
+	if err != nil || assignedCount != 1 {
+		t.Fatalf("AssignUnownedCategoryRules() = %d, %v, want 1, nil", assignedCount, err)
+	}
+	aliceList := mapper.GetCategoryRulesByOwnerId(alice.Hex(), 0, 0)
+	if len(aliceList) != 1 || aliceList[0].Id.Hex() != unownedId {
+		t.Errorf("GetCategoryRulesByOwnerId(alice) = %+v, want the unowned rule", aliceList)
+	}
+	if bobList := mapper.GetCategoryRulesByOwnerId(bob.Hex(), 0, 0); len(bobList) != 1 || bobList[0].Id.Hex() != bobId {
+		t.Errorf("GetCategoryRulesByOwnerId(bob) = %+v, want bob's rule", bobList)
+	}
+	if count := mapper.CountCategoryRulesByOwnerId(bob.Hex()); count != 1 {
+		t.Errorf("CountCategoryRulesByOwnerId(bob) = %d, want 1", count)
+	}
+}
@@ -0,0 +1,56 @@
+package exchange_rate_mapper
+
+import (
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+)
+
+var INSTANCE ExchangeRateMapper
+
+// ExchangeRateMapper stores exchange rates. Every owner has exchange rates of their own, a blank owner stands
+// for the exchange rates no user owns; lookups by id and the GetAll/DeleteAll methods span all owners.
+type ExchangeRateMapper interface {
+	GetExchangeRateByObjectId(plainId string) model.ExchangeRateEntity
+	// GetExchangeRateInEffect returns the owner's latest rate of the pair whose effective date is not after date
+	GetExchangeRateInEffect(ownerPlainId, fromCurrency, toCurrency string, date time.Time) model.ExchangeRateEntity
+	GetExchangeRatesByCurrencyPair(ownerPlainId, fromCurrency, toCurrency string) []model.ExchangeRateEntity
+	GetExchangeRatesByOwnerId(ownerPlainId string, limit, offset int) []model.ExchangeRateEntity
+	CountExchangeRatesByOwnerId(ownerPlainId string) int64
+	InsertExchangeRateByEntity(newEntity model.ExchangeRateEntity) string
+	BulkInsertExchangeRates(entities []model.ExchangeRateEntity) ([]string, error)
+	UpdateExchangeRateByEntity(plainId string, updatedEntity model.ExchangeRateEntity) model.ExchangeRateEntity
+	GetAllExchangeRates(limit, offset int) []model.ExchangeRateEntity
+	CountAllExchangeRates() int64
+	DeleteExchangeRateByObjectId(plainId string) model.ExchangeRateEntity
+	DeleteAllExchangeRates() (int64, error)
+	AssignUnownedExchangeRates(ownerPlainId string) (int64, error)
+}
+
+func init() {
+	switch util.GetConfigByKey("db.type") {
+	case "mongodb":
+		INSTANCE = ExchangeRateMongoDbMapper{}
+	case "mysql":
+		INSTANCE = ExchangeRateMySqlMapper{}
+	case "sqlite":
+		INSTANCE = ExchangeRateSqliteMapper{}
+	case "memory":
+		INSTANCE = NewExchangeRateMemoryMapper()
+	default:
+		panic("database type not supported")
+	}
+}
+
+// fillOperatingTime keeps create/modify times carried over from a backup,
+// and stamps the operating time on brand-new records.
+func fillOperatingTime(entity model.ExchangeRateEntity, operatingTime time.Time) model.ExchangeRateEntity {
+	if entity.CreateTime.IsZero() {
+		entity.CreateTime = operatingTime
+	}
+	if entity.ModifyTime.IsZero() {
+		entity.ModifyTime = operatingTime
+	}
+	return entity
+}
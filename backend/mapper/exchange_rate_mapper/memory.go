@@ -0,0 +1,229 @@
+package exchange_rate_mapper
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExchangeRateMemoryMapper keeps exchange rates in process memory, nothing is persisted.
+// It is safe for concurrent use; copies of the mapper share the same data.
+type ExchangeRateMemoryMapper struct {
+	store *exchangeRateMemoryStore
+}
+
+type exchangeRateMemoryStore struct {
+	mutex   sync.RWMutex
+	records map[primitive.ObjectID]model.ExchangeRateEntity
+}
+
+// NewExchangeRateMemoryMapper returns an empty in-memory mapper
+func NewExchangeRateMemoryMapper() ExchangeRateMemoryMapper {
+	return ExchangeRateMemoryMapper{
+		store: &exchangeRateMemoryStore{
+			records: make(map[primitive.ObjectID]model.ExchangeRateEntity),
+		},
+	}
+}
+
+func (mapper ExchangeRateMemoryMapper) GetExchangeRateByObjectId(plainId string) model.ExchangeRateEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("exchange rate's id is not acceptable")
+		return model.ExchangeRateEntity{}
+	}
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return mapper.store.records[objectId]
+}
+
+func (mapper ExchangeRateMemoryMapper) GetExchangeRateInEffect(ownerPlainId, fromCurrency, toCurrency string, date time.Time) model.ExchangeRateEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.ExchangeRateEntity) bool {
+		return entity.OwnerId == ownerId && entity.FromCurrency == fromCurrency && entity.ToCurrency == toCurrency && !entity.EffectiveDate.After(date)
+	})
+	if len(targetEntityList) == 0 {
+		return model.ExchangeRateEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (mapper ExchangeRateMemoryMapper) GetExchangeRatesByCurrencyPair(ownerPlainId, fromCurrency, toCurrency string) []model.ExchangeRateEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	return mapper.filter(func(entity model.ExchangeRateEntity) bool {
+		return entity.OwnerId == ownerId && entity.FromCurrency == fromCurrency && entity.ToCurrency == toCurrency
+	})
+}
+
+func (mapper ExchangeRateMemoryMapper) InsertExchangeRateByEntity(newEntity model.ExchangeRateEntity) string {
+	newPlainIdList, err := mapper.BulkInsertExchangeRates([]model.ExchangeRateEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+	return newPlainIdList[0]
+}
+
+func (mapper ExchangeRateMemoryMapper) BulkInsertExchangeRates(entities []model.ExchangeRateEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	operatingTime := time.Now()
+	newEntityList := make([]model.ExchangeRateEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.records[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate exchange rate id: " + entity.Id.Hex())
+		}
+		batchIds[entity.Id] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.records[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper ExchangeRateMemoryMapper) UpdateExchangeRateByEntity(plainId string, updatedEntity model.ExchangeRateEntity) model.ExchangeRateEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("exchange rate is not exist")
+		return model.ExchangeRateEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+	mapper.store.records[objectId] = updatedEntity
+	return updatedEntity
+}
+
+func (mapper ExchangeRateMemoryMapper) GetAllExchangeRates(limit, offset int) []model.ExchangeRateEntity {
+	targetEntityList := mapper.filter(func(model.ExchangeRateEntity) bool { return true })
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper ExchangeRateMemoryMapper) GetExchangeRatesByOwnerId(ownerPlainId string, limit, offset int) []model.ExchangeRateEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.ExchangeRateEntity) bool {
+		return entity.OwnerId == ownerId
+	})
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper ExchangeRateMemoryMapper) CountAllExchangeRates() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.records))
+}
+
+func (mapper ExchangeRateMemoryMapper) CountExchangeRatesByOwnerId(ownerPlainId string) int64 {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	return int64(len(mapper.filter(func(entity model.ExchangeRateEntity) bool {
+		return entity.OwnerId == ownerId
+	})))
+}
+
+func (mapper ExchangeRateMemoryMapper) DeleteExchangeRateByObjectId(plainId string) model.ExchangeRateEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("exchange rate is not exist")
+		return model.ExchangeRateEntity{}
+	}
+
+	delete(mapper.store.records, objectId)
+	return targetEntity
+}
+
+func (mapper ExchangeRateMemoryMapper) DeleteAllExchangeRates() (int64, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	deletedCount := int64(len(mapper.store.records))
+	mapper.store.records = make(map[primitive.ObjectID]model.ExchangeRateEntity)
+	return deletedCount, nil
+}
+
+func (mapper ExchangeRateMemoryMapper) AssignUnownedExchangeRates(ownerPlainId string) (int64, error) {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	var assignedCount int64
+	for id, entity := range mapper.store.records {
+		if entity.OwnerId == primitive.NilObjectID {
+			entity.OwnerId = ownerId
+			mapper.store.records[id] = entity
+			assignedCount++
+		}
+	}
+	return assignedCount, nil
+}
+
+// paginate cuts one page out of the ordered exchange rates, a zero limit keeps all of them
+func paginate(targetEntityList []model.ExchangeRateEntity, limit, offset int) []model.ExchangeRateEntity {
+	if offset >= len(targetEntityList) {
+		return []model.ExchangeRateEntity{}
+	}
+	targetEntityList = targetEntityList[offset:]
+	if limit > 0 && limit < len(targetEntityList) {
+		targetEntityList = targetEntityList[:limit]
+	}
+	return targetEntityList
+}
+
+// filter returns the matching rates ordered by currency pair and then latest effective date first,
+// like the database mappers
+func (mapper ExchangeRateMemoryMapper) filter(isMatched func(entity model.ExchangeRateEntity) bool) []model.ExchangeRateEntity {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	var targetEntityList []model.ExchangeRateEntity
+	for _, entity := range mapper.store.records {
+		if isMatched(entity) {
+			targetEntityList = append(targetEntityList, entity)
+		}
+	}
+
+	sort.Slice(targetEntityList, func(i, j int) bool {
+		left, right := targetEntityList[i], targetEntityList[j]
+		if left.FromCurrency != right.FromCurrency {
+			return left.FromCurrency < right.FromCurrency
+		}
+		if left.ToCurrency != right.ToCurrency {
+			return left.ToCurrency < right.ToCurrency
+		}
+		if !left.EffectiveDate.Equal(right.EffectiveDate) {
+			return left.EffectiveDate.After(right.EffectiveDate)
+		}
+		return left.Id.Hex() < right.Id.Hex()
+	})
+	return targetEntityList
+}
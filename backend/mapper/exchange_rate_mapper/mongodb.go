@@ -0,0 +1,288 @@
+package exchange_rate_mapper
+
+import (
+	"context"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ExchangeRateMongoDbMapper struct{}
+
+func (ExchangeRateMongoDbMapper) GetExchangeRateByObjectId(plainId string) model.ExchangeRateEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("exchange rate's id is not acceptable")
+		return model.ExchangeRateEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.ExchangeRateTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2ExchangeRateEntity(database.GetOneInMongoDB(filter))
+}
+
+func (ExchangeRateMongoDbMapper) GetExchangeRateInEffect(ownerPlainId, fromCurrency, toCurrency string, date time.Time) model.ExchangeRateEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+		primitive.E{Key: "from_currency", Value: fromCurrency},
+		primitive.E{Key: "to_currency", Value: toCurrency},
+		primitive.E{Key: "effective_date", Value: bson.D{
+			primitive.E{Key: "$lte", Value: date},
+		}},
+	}
+
+	findOptions := database.GetFindOptions()
+	findOptions.SetSort(bson.D{primitive.E{Key: "effective_date", Value: -1}})
+	findOptions.SetLimit(1)
+
+	targetEntityList := findMongoExchangeRates(filter, findOptions)
+	if len(targetEntityList) == 0 {
+		return model.ExchangeRateEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (ExchangeRateMongoDbMapper) GetExchangeRatesByCurrencyPair(ownerPlainId, fromCurrency, toCurrency string) []model.ExchangeRateEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+		primitive.E{Key: "from_currency", Value: fromCurrency},
+		primitive.E{Key: "to_currency", Value: toCurrency},
+	}
+
+	findOptions := database.GetFindOptions()
+	findOptions.SetSort(bson.D{primitive.E{Key: "effective_date", Value: -1}})
+	return findMongoExchangeRates(filter, findOptions)
+}
+
+func (ExchangeRateMongoDbMapper) InsertExchangeRateByEntity(newEntity model.ExchangeRateEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	database.OpenMongoDbConnection(database.ExchangeRateTableName)
+	defer database.CloseMongoDbConnection()
+
+	newExchangeRateId := database.InsertOneInMongoDB(convertExchangeRateEntity2BsonD(newEntity))
+	return newExchangeRateId.Hex()
+}
+
+func (ExchangeRateMongoDbMapper) BulkInsertExchangeRates(entities []model.ExchangeRateEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		documents[i] = convertExchangeRateEntity2BsonD(fillOperatingTime(entity, operatingTime))
+	}
+
+	collection := database.GetMongoCollection(database.ExchangeRateTableName)
+	result, err := collection.InsertMany(context.TODO(), documents)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	ids := make([]string, len(result.InsertedIDs))
+	for i, id := range result.InsertedIDs {
+		ids[i] = id.(primitive.ObjectID).Hex()
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (ExchangeRateMongoDbMapper) UpdateExchangeRateByEntity(plainId string, updatedEntity model.ExchangeRateEntity) model.ExchangeRateEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("exchange rate's id is not acceptable")
+		return model.ExchangeRateEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.ExchangeRateTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2ExchangeRateEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("exchange rate is not exist")
+		return model.ExchangeRateEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	rowsAffected := database.UpdateManyInMongoDB(filter, convertExchangeRateEntity2BsonD(updatedEntity))
+	if rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "rows_affected", rowsAffected)
+		return model.ExchangeRateEntity{}
+	}
+	return updatedEntity
+}
+
+func (ExchangeRateMongoDbMapper) GetAllExchangeRates(limit, offset int) []model.ExchangeRateEntity {
+	return findMongoExchangeRatesPage(bson.D{}, limit, offset)
+}
+
+func (ExchangeRateMongoDbMapper) GetExchangeRatesByOwnerId(ownerPlainId string, limit, offset int) []model.ExchangeRateEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+	return findMongoExchangeRatesPage(filter, limit, offset)
+}
+
+func (ExchangeRateMongoDbMapper) CountAllExchangeRates() int64 {
+	database.OpenMongoDbConnection(database.ExchangeRateTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(bson.D{})
+}
+
+func (ExchangeRateMongoDbMapper) CountExchangeRatesByOwnerId(ownerPlainId string) int64 {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+
+	database.OpenMongoDbConnection(database.ExchangeRateTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+func (ExchangeRateMongoDbMapper) DeleteExchangeRateByObjectId(plainId string) model.ExchangeRateEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("exchange rate's id is not acceptable")
+		return model.ExchangeRateEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.ExchangeRateTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2ExchangeRateEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("exchange rate is not exist")
+		return model.ExchangeRateEntity{}
+	}
+
+	rowsAffected := database.DeleteManyInMongoDB(filter)
+	if rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "rows_affected", rowsAffected)
+		return model.ExchangeRateEntity{}
+	}
+	return targetEntity
+}
+
+func (ExchangeRateMongoDbMapper) DeleteAllExchangeRates() (int64, error) {
+	collection := database.GetMongoCollection(database.ExchangeRateTableName)
+	result, err := collection.DeleteMany(context.TODO(), bson.D{})
+	if err != nil {
+		util.Logger.Errorw("delete all exchange rates failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all exchange rates deleted", "count", result.DeletedCount)
+	return result.DeletedCount, nil
+}
+
+func (ExchangeRateMongoDbMapper) AssignUnownedExchangeRates(ownerPlainId string) (int64, error) {
+	collection := database.GetMongoCollection(database.ExchangeRateTableName)
+	result, err := collection.UpdateMany(context.TODO(),
+		bson.D{database.OwnerFilterInMongoDB("")},
+		bson.D{primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "owner_id", Value: util.ConvertOwner2ObjectId(ownerPlainId)},
+		}}})
+	if err != nil {
+		util.Logger.Errorw("assign unowned exchange rates failed", "error", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// findMongoExchangeRatesPage returns one page of the matching exchange rates in listing order
+func findMongoExchangeRatesPage(filter bson.D, limit, offset int) []model.ExchangeRateEntity {
+	findOptions := database.GetFindOptions()
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		findOptions.SetSkip(int64(offset))
+	}
+	// Group by pair, newest rate first
+	findOptions.SetSort(bson.D{
+		primitive.E{Key: "from_currency", Value: 1},
+		primitive.E{Key: "to_currency", Value: 1},
+		primitive.E{Key: "effective_date", Value: -1},
+	})
+
+	return findMongoExchangeRates(filter, findOptions)
+}
+
+func findMongoExchangeRates(filter bson.D, findOptions *options.FindOptions) []model.ExchangeRateEntity {
+	collection := database.GetMongoCollection(database.ExchangeRateTableName)
+
+	ctx := context.TODO()
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		util.Logger.Errorw("query exchange rates failed", "error", err)
+		return []model.ExchangeRateEntity{}
+	}
+	defer cursor.Close(ctx)
+
+	var targetEntityList []model.ExchangeRateEntity
+	for cursor.Next(ctx) {
+		var bsonM bson.M
+		if err := cursor.Decode(&bsonM); err != nil {
+			util.Logger.Errorw("decode failed", "error", err)
+			continue
+		}
+		targetEntityList = append(targetEntityList, convertBsonM2ExchangeRateEntity(bsonM))
+	}
+	return targetEntityList
+}
+
+func convertExchangeRateEntity2BsonD(entity model.ExchangeRateEntity) bson.D {
+	// 为空时自动生成新Id
+	if entity.Id == primitive.NilObjectID {
+		entity.Id = primitive.NewObjectID()
+	}
+
+	return bson.D{
+		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "owner_id", Value: entity.OwnerId},
+		primitive.E{Key: "from_currency", Value: entity.FromCurrency},
+		primitive.E{Key: "to_currency", Value: entity.ToCurrency},
+		primitive.E{Key: "rate", Value: entity.Rate},
+		primitive.E{Key: "effective_date", Value: entity.EffectiveDate},
+		primitive.E{Key: "create_time", Value: entity.CreateTime},
+		primitive.E{Key: "modify_time", Value: entity.ModifyTime},
+	}
+}
+
+func convertBsonM2ExchangeRateEntity(bsonM bson.M) model.ExchangeRateEntity {
+	var newEntity model.ExchangeRateEntity
+	bsonBytes, _ := bson.Marshal(bsonM)
+	err := bson.Unmarshal(bsonBytes, &newEntity)
+	if err != nil {
+		panic(err)
+	}
+	return newEntity
+}
@@ -0,0 +1,351 @@
+package exchange_rate_mapper
+
+import (
+	"bytes"
+	"database/sql"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ExchangeRateMySqlMapper struct{}
+
+const mySqlExchangeRateColumns = "ID, OWNER_ID, FROM_CURRENCY, TO_CURRENCY, RATE, EFFECTIVE_DATE, CREATE_TIME, MODIFY_TIME"
+
+func (ExchangeRateMySqlMapper) GetExchangeRateByObjectId(plainId string) model.ExchangeRateEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlExchangeRateColumns + " FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := queryMySqlExchangeRates(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.ExchangeRateEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (ExchangeRateMySqlMapper) GetExchangeRateInEffect(ownerPlainId, fromCurrency, toCurrency string, date time.Time) model.ExchangeRateEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlExchangeRateColumns + " FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND FROM_CURRENCY = ? AND TO_CURRENCY = ? AND EFFECTIVE_DATE <= ? ")
+	sqlString.WriteString(" ORDER BY EFFECTIVE_DATE DESC LIMIT 1 ")
+
+	targetEntityList := queryMySqlExchangeRates(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), fromCurrency, toCurrency, util.FormatDateToStringWithDash(date))
+	if len(targetEntityList) == 0 {
+		return model.ExchangeRateEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (ExchangeRateMySqlMapper) GetExchangeRatesByCurrencyPair(ownerPlainId, fromCurrency, toCurrency string) []model.ExchangeRateEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlExchangeRateColumns + " FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND FROM_CURRENCY = ? AND TO_CURRENCY = ? ")
+	sqlString.WriteString(" ORDER BY EFFECTIVE_DATE DESC ")
+
+	return queryMySqlExchangeRates(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), fromCurrency, toCurrency)
+}
+
+func (ExchangeRateMySqlMapper) InsertExchangeRateByEntity(newEntity model.ExchangeRateEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" (" + mySqlExchangeRateColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?) ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := connection.Exec(sqlString.String(), newPlainId, newEntity.OwnerId.Hex(), newEntity.FromCurrency, newEntity.ToCurrency,
+		newEntity.Rate, newEntity.EffectiveDate, newEntity.CreateTime, newEntity.ModifyTime)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (ExchangeRateMySqlMapper) BulkInsertExchangeRates(entities []model.ExchangeRateEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" (" + mySqlExchangeRateColumns + ") VALUES ")
+
+	ids := make([]string, len(entities))
+	values := make([]interface{}, 0, len(entities)*8)
+
+	for i, entity := range entities {
+		if i > 0 {
+			sqlString.WriteString(", ")
+		}
+		sqlString.WriteString("(?, ?, ?, ?, ?, ?, ?, ?)")
+
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		values = append(values, ids[i], entity.OwnerId.Hex(), entity.FromCurrency, entity.ToCurrency, entity.Rate,
+			entity.EffectiveDate, entity.CreateTime, entity.ModifyTime)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), values...)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != int64(len(entities)) {
+		util.Logger.Errorw("bulk insert incomplete", "error", err, "expected", len(entities), "actual", rowsAffected)
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (ExchangeRateMySqlMapper) UpdateExchangeRateByEntity(plainId string, updatedEntity model.ExchangeRateEntity) model.ExchangeRateEntity {
+	targetEntity := INSTANCE.GetExchangeRateByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("exchange rate is not exist")
+		return model.ExchangeRateEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" SET FROM_CURRENCY = ?, ")
+	sqlString.WriteString(" TO_CURRENCY = ?, ")
+	sqlString.WriteString(" RATE = ?, ")
+	sqlString.WriteString(" EFFECTIVE_DATE = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), updatedEntity.FromCurrency, updatedEntity.ToCurrency,
+		updatedEntity.Rate, updatedEntity.EffectiveDate, updatedEntity.ModifyTime, plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.ExchangeRateEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (ExchangeRateMySqlMapper) GetAllExchangeRates(limit, offset int) []model.ExchangeRateEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlExchangeRateColumns + " FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" ORDER BY FROM_CURRENCY ASC, TO_CURRENCY ASC, EFFECTIVE_DATE DESC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlExchangeRates(sqlString.String(), limit, offset)
+	}
+	return queryMySqlExchangeRates(sqlString.String())
+}
+
+func (ExchangeRateMySqlMapper) GetExchangeRatesByOwnerId(ownerPlainId string, limit, offset int) []model.ExchangeRateEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlExchangeRateColumns + " FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY FROM_CURRENCY ASC, TO_CURRENCY ASC, EFFECTIVE_DATE DESC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlExchangeRates(sqlString.String(), ownerId, limit, offset)
+	}
+	return queryMySqlExchangeRates(sqlString.String(), ownerId)
+}
+
+func (ExchangeRateMySqlMapper) CountExchangeRatesByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	err := connection.QueryRow(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count exchange rates by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (ExchangeRateMySqlMapper) CountAllExchangeRates() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all exchange rates failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (ExchangeRateMySqlMapper) DeleteExchangeRateByObjectId(plainId string) model.ExchangeRateEntity {
+	targetEntity := INSTANCE.GetExchangeRateByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("exchange rate is not exist")
+		return model.ExchangeRateEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.ExchangeRateEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (ExchangeRateMySqlMapper) DeleteAllExchangeRates() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all exchange rates failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all exchange rates failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all exchange rates deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (ExchangeRateMySqlMapper) AssignUnownedExchangeRates(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned exchange rates failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func queryMySqlExchangeRates(sqlString string, args ...interface{}) []model.ExchangeRateEntity {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.ExchangeRateEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2ExchangeRateEntity(rows))
+	}
+	return targetEntityList
+}
+
+// generatePlainId keeps a preset id (e.g. restored from backup) and generates one otherwise
+func generatePlainId(objectId primitive.ObjectID) string {
+	if objectId == primitive.NilObjectID {
+		return primitive.NewObjectID().Hex()
+	}
+	return objectId.Hex()
+}
+
+func convertRow2ExchangeRateEntity(rows *sql.Rows) model.ExchangeRateEntity {
+	var id string
+	var ownerId string
+	var fromCurrency string
+	var toCurrency string
+	var rate decimal.Decimal
+	var effectiveDate string
+	var createTime string
+	var modifyTime string
+
+	err := rows.Scan(&id, &ownerId, &fromCurrency, &toCurrency, &rate, &effectiveDate, &createTime, &modifyTime)
+	if err != nil {
+		util.Logger.Errorw("covert into entity failed", "error", err)
+	}
+
+	return model.ExchangeRateEntity{
+		Id:            util.Convert2ObjectId(id),
+		OwnerId:       util.Convert2ObjectId(ownerId),
+		FromCurrency:  fromCurrency,
+		ToCurrency:    toCurrency,
+		Rate:          rate,
+		EffectiveDate: util.FormatDateTimeFromString(effectiveDate),
+		CreateTime:    util.FormatDateTimeFromString(createTime),
+		ModifyTime:    util.FormatDateTimeFromString(modifyTime),
+	}
+}
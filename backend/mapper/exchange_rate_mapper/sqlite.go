@@ -0,0 +1,310 @@
+package exchange_rate_mapper
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ExchangeRateSqliteMapper struct{}
+
+const sqliteExchangeRateColumns = "ID, OWNER_ID, FROM_CURRENCY, TO_CURRENCY, RATE, EFFECTIVE_DATE, CREATE_TIME, MODIFY_TIME"
+
+func (ExchangeRateSqliteMapper) GetExchangeRateByObjectId(plainId string) model.ExchangeRateEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteExchangeRateColumns + " FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := querySqliteExchangeRates(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.ExchangeRateEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (ExchangeRateSqliteMapper) GetExchangeRateInEffect(ownerPlainId, fromCurrency, toCurrency string, date time.Time) model.ExchangeRateEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteExchangeRateColumns + " FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND FROM_CURRENCY = ? AND TO_CURRENCY = ? AND EFFECTIVE_DATE <= ? ")
+	sqlString.WriteString(" ORDER BY EFFECTIVE_DATE DESC LIMIT 1 ")
+
+	targetEntityList := querySqliteExchangeRates(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), fromCurrency, toCurrency, util.FormatDateToStringWithDash(date))
+	if len(targetEntityList) == 0 {
+		return model.ExchangeRateEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (ExchangeRateSqliteMapper) GetExchangeRatesByCurrencyPair(ownerPlainId, fromCurrency, toCurrency string) []model.ExchangeRateEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteExchangeRateColumns + " FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND FROM_CURRENCY = ? AND TO_CURRENCY = ? ")
+	sqlString.WriteString(" ORDER BY EFFECTIVE_DATE DESC ")
+
+	return querySqliteExchangeRates(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), fromCurrency, toCurrency)
+}
+
+func (ExchangeRateSqliteMapper) InsertExchangeRateByEntity(newEntity model.ExchangeRateEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" (" + sqliteExchangeRateColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?) ")
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		convertExchangeRateEntity2SqliteValues(newPlainId, newEntity)...)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (ExchangeRateSqliteMapper) BulkInsertExchangeRates(entities []model.ExchangeRateEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" (" + sqliteExchangeRateColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?) ")
+
+	// One transaction keeps the batch all-or-nothing and avoids a disk sync per row
+	transaction, err := database.GetSqliteConnection().Begin()
+	if err != nil {
+		util.Logger.Errorw("bulk insert begin failed", "error", err)
+		return nil, err
+	}
+
+	statement, err := transaction.Prepare(sqlString.String())
+	if err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert prepare failed", "error", err)
+		return nil, err
+	}
+	defer statement.Close()
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		if _, err := statement.Exec(convertExchangeRateEntity2SqliteValues(ids[i], entity)...); err != nil {
+			transaction.Rollback()
+			util.Logger.Errorw("bulk insert failed", "error", err)
+			return nil, err
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		util.Logger.Errorw("bulk insert commit failed", "error", err)
+		return nil, err
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (ExchangeRateSqliteMapper) UpdateExchangeRateByEntity(plainId string, updatedEntity model.ExchangeRateEntity) model.ExchangeRateEntity {
+	targetEntity := INSTANCE.GetExchangeRateByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("exchange rate is not exist")
+		return model.ExchangeRateEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" SET FROM_CURRENCY = ?, ")
+	sqlString.WriteString(" TO_CURRENCY = ?, ")
+	sqlString.WriteString(" RATE = ?, ")
+	sqlString.WriteString(" EFFECTIVE_DATE = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		updatedEntity.FromCurrency, updatedEntity.ToCurrency, updatedEntity.Rate,
+		util.FormatDateToStringWithDash(updatedEntity.EffectiveDate),
+		util.FormatDateTimeToString(updatedEntity.ModifyTime), plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.ExchangeRateEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (ExchangeRateSqliteMapper) GetAllExchangeRates(limit, offset int) []model.ExchangeRateEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteExchangeRateColumns + " FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" ORDER BY FROM_CURRENCY ASC, TO_CURRENCY ASC, EFFECTIVE_DATE DESC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteExchangeRates(sqlString.String(), limit, offset)
+	}
+	return querySqliteExchangeRates(sqlString.String())
+}
+
+func (ExchangeRateSqliteMapper) GetExchangeRatesByOwnerId(ownerPlainId string, limit, offset int) []model.ExchangeRateEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteExchangeRateColumns + " FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY FROM_CURRENCY ASC, TO_CURRENCY ASC, EFFECTIVE_DATE DESC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteExchangeRates(sqlString.String(), ownerId, limit, offset)
+	}
+	return querySqliteExchangeRates(sqlString.String(), ownerId)
+}
+
+func (ExchangeRateSqliteMapper) CountExchangeRatesByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	var count int64
+	err := database.GetSqliteConnection().QueryRow(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count exchange rates by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (ExchangeRateSqliteMapper) CountAllExchangeRates() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all exchange rates failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (ExchangeRateSqliteMapper) DeleteExchangeRateByObjectId(plainId string) model.ExchangeRateEntity {
+	targetEntity := INSTANCE.GetExchangeRateByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("exchange rate is not exist")
+		return model.ExchangeRateEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.ExchangeRateEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (ExchangeRateSqliteMapper) DeleteAllExchangeRates() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all exchange rates failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all exchange rates failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all exchange rates deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (ExchangeRateSqliteMapper) AssignUnownedExchangeRates(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.ExchangeRateTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned exchange rates failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func querySqliteExchangeRates(sqlString string, args ...interface{}) []model.ExchangeRateEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.ExchangeRateEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2ExchangeRateEntity(rows))
+	}
+	return targetEntityList
+}
+
+// convertExchangeRateEntity2SqliteValues lists the column values in sqliteExchangeRateColumns order,
+// the effective date is written as text so that comparisons and ORDER BY work on it.
+func convertExchangeRateEntity2SqliteValues(plainId string, entity model.ExchangeRateEntity) []interface{} {
+	return []interface{}{
+		plainId,
+		entity.OwnerId.Hex(),
+		entity.FromCurrency,
+		entity.ToCurrency,
+		entity.Rate,
+		util.FormatDateToStringWithDash(entity.EffectiveDate),
+		util.FormatDateTimeToString(entity.CreateTime),
+		util.FormatDateTimeToString(entity.ModifyTime),
+	}
+}
@@ -0,0 +1,140 @@
+package exchange_rate_mapper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMain(m *testing.M) {
+	tempDir, err := os.MkdirTemp("", "cashlens-sqlite-")
+	if err != nil {
+		panic(err)
+	}
+	util.SetConfigByKey("db.sqlite.path", filepath.Join(tempDir, "exchange_rate_test.db"))
+	INSTANCE = ExchangeRateSqliteMapper{}
+
+	code := m.Run()
+	os.RemoveAll(tempDir)
+	os.Exit(code)
+}
+
+func TestSqliteExchangeRateLifecycle(t *testing.T) {
+	mapper := ExchangeRateSqliteMapper{}
+	if _, err := mapper.DeleteAllExchangeRates(); err != nil {
+		t.Fatalf("DeleteAllExchangeRates() error = %v", err)
+	}
+
+	januaryId := mapper.InsertExchangeRateByEntity(model.ExchangeRateEntity{
+		FromCurrency:  "EUR",
+		ToCurrency:    "USD",
+		Rate:          decimal.RequireFromString("1.1"),
+		EffectiveDate: util.FormatDateFromStringWithDash("2024-01-01"),
+	})
+	otherIds, err := mapper.BulkInsertExchangeRates([]model.ExchangeRateEntity{
+		{FromCurrency: "EUR", ToCurrency: "USD", Rate: decimal.RequireFromString("1.2"), EffectiveDate: util.FormatDateFromStringWithDash("2024-03-01")},
+		{FromCurrency: "CNY", ToCurrency: "USD", Rate: decimal.RequireFromString("0.14"), EffectiveDate: util.FormatDateFromStringWithDash("2024-01-01")},
+	})
+	if err != nil || len(otherIds) != 2 {
+		t.Fatalf("BulkInsertExchangeRates() = %v, %v", otherIds, err)
+	}
+
+	january := mapper.GetExchangeRateByObjectId(januaryId)
+	if !january.Rate.Equal(decimal.RequireFromString("1.1")) || util.FormatDateToStringWithDash(january.EffectiveDate) != "2024-01-01" {
+		t.Errorf("GetExchangeRateByObjectId() = %+v", january)
+	}
+	if count := mapper.CountAllExchangeRates(); count != 3 {
+		t.Errorf("CountAllExchangeRates() = %d, want 3", count)
+	}
+
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"2023-12-31", "0"},
+		{"2024-01-01", "1.1"},
+		{"2024-02-15", "1.1"},
+		{"2024-03-01", "1.2"},
+		{"2025-01-01", "1.2"},
+	}
+	for _, tt := range tests {
+		got := mapper.GetExchangeRateInEffect("", "EUR", "USD", util.FormatDateFromStringWithDash(tt.date))
+		if !got.Rate.Equal(decimal.RequireFromString(tt.want)) {
+			t.Errorf("GetExchangeRateInEffect(%s) rate = %v, want %v", tt.date, got.Rate, tt.want)
+		}
+	}
+
+	if pairList := mapper.GetExchangeRatesByCurrencyPair("", "EUR", "USD"); len(pairList) != 2 || !pairList[0].Rate.Equal(decimal.RequireFromString("1.2")) {
+		t.Errorf("GetExchangeRatesByCurrencyPair() = %+v, want newest first", pairList)
+	}
+	if allList := mapper.GetAllExchangeRates(0, 0); len(allList) != 3 || allList[0].FromCurrency != "CNY" {
+		t.Errorf("GetAllExchangeRates() = %+v, want sorted by pair", allList)
+	}
+
+	updated := mapper.UpdateExchangeRateByEntity(januaryId, model.ExchangeRateEntity{
+		FromCurrency:  "EUR",
+		ToCurrency:    "USD",
+		Rate:          decimal.RequireFromString("1.05"),
+		EffectiveDate: january.EffectiveDate,
+	})
+	if updated.Rate.String() != "1.05" || mapper.GetExchangeRateByObjectId(januaryId).Rate.String() != "1.05" {
+		t.Errorf("UpdateExchangeRateByEntity() did not update the rate")
+	}
+
+	if deleted := mapper.DeleteExchangeRateByObjectId(otherIds[1]); deleted.FromCurrency != "CNY" {
+		t.Errorf("DeleteExchangeRateByObjectId() = %+v", deleted)
+	}
+
+	deletedCount, err := mapper.DeleteAllExchangeRates()
+	if err != nil || deletedCount != 2 {
+		t.Errorf("DeleteAllExchangeRates() = %d, %v, want 2, nil", deletedCount, err)
+	}
+}
+
+func TestSqliteExchangeRateOwners(t *testing.T) {
+	mapper := ExchangeRateSqliteMapper{}
+	if _, err := mapper.DeleteAllExchangeRates(); err != nil {
+		t.Fatalf("DeleteAllExchangeRates() error = %v", err)
+	}
+
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	effectiveDate := util.FormatDateFromStringWithDash("2024-01-01")
+	unownedId := mapper.InsertExchangeRateByEntity(model.ExchangeRateEntity{FromCurrency: "EUR", ToCurrency: "USD",
+		Rate: decimal.RequireFromString("1.1"), EffectiveDate: effectiveDate})
+	bobId := mapper.InsertExchangeRateByEntity(model.ExchangeRateEntity{OwnerId: bob, FromCurrency: "EUR", ToCurrency: "USD",
+		Rate: decimal.RequireFromString("1.2"), EffectiveDate: effectiveDate})
+
+	// Owners only see the rates of their own ledger
+	if rate := mapper.GetExchangeRateInEffect(bob.Hex(), "EUR", "USD", effectiveDate); rate.Id.Hex() != bobId {
+		t.Errorf("GetExchangeRateInEffect(bob) = %+v, want id %s", rate, bobId)
+	}
+	if pairList := mapper.GetExchangeRatesByCurrencyPair(alice.Hex(), "EUR", "USD"); len(pairList) != 0 {
+		t.Errorf("GetExchangeRatesByCurrencyPair(alice) = %+v, want none", pairList)
+	}
+
+	// An update keeps the owner
+	bobRate := mapper.GetExchangeRateByObjectId(bobId)
+	bobRate.OwnerId = primitive.NilObjectID
+	mapper.UpdateExchangeRateByEntity(bobId, bobRate)
+	if owner := mapper.GetExchangeRateByObjectId(bobId).OwnerId; owner != bob {
+		t.Errorf("UpdateExchangeRateByEntity() changed the owner to %s", owner.Hex())
+	}
+
+	// Only the rates without owner are handed over
+	assignedCount, err := mapper.AssignUnownedExchangeRates(alice.Hex())
+	if err != nil || assignedCount != 1 {
+		t.Fatalf("AssignUnownedExchangeRates() = %d, %v, want 1, nil", assignedCount, err)
+	}
+	aliceList := mapper.GetExchangeRatesByOwnerId(alice.Hex(), 0, 0)
+	if len(aliceList) != 1 || aliceList[0].Id.Hex() != unownedId {
+		t.Errorf("GetExchangeRatesByOwnerId(alice) = %+v, want the unowned rate", aliceList)
+	}
+	if count := mapper.CountExchangeRatesByOwnerId(bob.Hex()); count != 1 {
+		t.Errorf("CountExchangeRatesByOwnerId(bob) = %d, want 1", count)
+	}
+}
@@ -0,0 +1,55 @@
+package goal_mapper
+
+import (
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+)
+
+var INSTANCE GoalMapper
+
+// GoalMapper stores goals. Every owner has goals of their own, a blank owner stands
+// for the goals no user owns; lookups by id and the GetAll/DeleteAll methods span all owners.
+type GoalMapper interface {
+	GetGoalByObjectId(plainId string) model.GoalEntity
+	GetGoalByName(ownerPlainId, goalName string) model.GoalEntity
+	GetGoalsByOwnerId(ownerPlainId string, limit, offset int) []model.GoalEntity
+	CountGoalsByOwnerId(ownerPlainId string) int64
+	InsertGoalByEntity(newEntity model.GoalEntity) string
+	BulkInsertGoals(entities []model.GoalEntity) ([]string, error)
+	UpdateGoalByEntity(plainId string, updatedEntity model.GoalEntity) model.GoalEntity
+	GetAllGoals(limit, offset int) []model.GoalEntity
+	CountAllGoals() int64
+	CountGoalsByAccountId(accountPlainId string) int64
+	DeleteGoalByObjectId(plainId string) model.GoalEntity
+	DeleteAllGoals() (int64, error)
+	AssignUnownedGoals(ownerPlainId string) (int64, error)
+}
+
+func init() {
+	switch util.GetConfigByKey("db.type") {
+	case "mongodb":
+		INSTANCE = GoalMongoDbMapper{}
+	case "mysql":
+		INSTANCE = GoalMySqlMapper{}
+	case "sqlite":
+		INSTANCE = GoalSqliteMapper{}
+	case "memory":
+		INSTANCE = NewGoalMemoryMapper()
+	default:
+		panic("database type not supported")
+	}
+}
+
+// fillOperatingTime keeps create/modify times carried over from a backup,
+// and stamps the operating time on brand-new records.
+func fillOperatingTime(entity model.GoalEntity, operatingTime time.Time) model.GoalEntity {
+	if entity.CreateTime.IsZero() {
+		entity.CreateTime = operatingTime
+	}
+	if entity.ModifyTime.IsZero() {
+		entity.ModifyTime = operatingTime
+	}
+	return entity
+}
@@ -0,0 +1,221 @@
+package goal_mapper
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GoalMemoryMapper keeps goals in process memory, nothing is persisted.
+// It is safe for concurrent use; copies of the mapper share the same data.
+type GoalMemoryMapper struct {
+	store *goalMemoryStore
+}
+
+type goalMemoryStore struct {
+	mutex   sync.RWMutex
+	records map[primitive.ObjectID]model.GoalEntity
+}
+
+// NewGoalMemoryMapper returns an empty in-memory mapper
+func NewGoalMemoryMapper() GoalMemoryMapper {
+	return GoalMemoryMapper{
+		store: &goalMemoryStore{
+			records: make(map[primitive.ObjectID]model.GoalEntity),
+		},
+	}
+}
+
+func (mapper GoalMemoryMapper) GetGoalByObjectId(plainId string) model.GoalEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("goal's id is not acceptable")
+		return model.GoalEntity{}
+	}
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return mapper.store.records[objectId]
+}
+
+func (mapper GoalMemoryMapper) GetGoalByName(ownerPlainId, goalName string) model.GoalEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.GoalEntity) bool {
+		return entity.OwnerId == ownerId && entity.Name == goalName
+	})
+	if len(targetEntityList) == 0 {
+		return model.GoalEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (mapper GoalMemoryMapper) InsertGoalByEntity(newEntity model.GoalEntity) string {
+	newPlainIdList, err := mapper.BulkInsertGoals([]model.GoalEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+	return newPlainIdList[0]
+}
+
+func (mapper GoalMemoryMapper) BulkInsertGoals(entities []model.GoalEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	operatingTime := time.Now()
+	newEntityList := make([]model.GoalEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.records[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate goal id: " + entity.Id.Hex())
+		}
+		batchIds[entity.Id] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.records[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper GoalMemoryMapper) UpdateGoalByEntity(plainId string, updatedEntity model.GoalEntity) model.GoalEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("goal is not exist")
+		return model.GoalEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+	mapper.store.records[objectId] = updatedEntity
+	return updatedEntity
+}
+
+func (mapper GoalMemoryMapper) GetAllGoals(limit, offset int) []model.GoalEntity {
+	targetEntityList := mapper.filter(func(model.GoalEntity) bool { return true })
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper GoalMemoryMapper) GetGoalsByOwnerId(ownerPlainId string, limit, offset int) []model.GoalEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.GoalEntity) bool {
+		return entity.OwnerId == ownerId
+	})
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper GoalMemoryMapper) CountAllGoals() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.records))
+}
+
+func (mapper GoalMemoryMapper) CountGoalsByOwnerId(ownerPlainId string) int64 {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	return int64(len(mapper.filter(func(entity model.GoalEntity) bool {
+		return entity.OwnerId == ownerId
+	})))
+}
+
+func (mapper GoalMemoryMapper) CountGoalsByAccountId(accountPlainId string) int64 {
+	accountId := util.Convert2ObjectId(accountPlainId)
+	return int64(len(mapper.filter(func(entity model.GoalEntity) bool {
+		return entity.AccountId == accountId
+	})))
+}
+
+func (mapper GoalMemoryMapper) DeleteGoalByObjectId(plainId string) model.GoalEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("goal is not exist")
+		return model.GoalEntity{}
+	}
+
+	delete(mapper.store.records, objectId)
+	return targetEntity
+}
+
+func (mapper GoalMemoryMapper) DeleteAllGoals() (int64, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	deletedCount := int64(len(mapper.store.records))
+	mapper.store.records = make(map[primitive.ObjectID]model.GoalEntity)
+	return deletedCount, nil
+}
+
+func (mapper GoalMemoryMapper) AssignUnownedGoals(ownerPlainId string) (int64, error) {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	var assignedCount int64
+	for id, entity := range mapper.store.records {
+		if entity.OwnerId == primitive.NilObjectID {
+			entity.OwnerId = ownerId
+			mapper.store.records[id] = entity
+			assignedCount++
+		}
+	}
+	return assignedCount, nil
+}
+
+// paginate cuts one page out of the ordered goals, a zero limit keeps all of them
+func paginate(targetEntityList []model.GoalEntity, limit, offset int) []model.GoalEntity {
+	if offset >= len(targetEntityList) {
+		return []model.GoalEntity{}
+	}
+	targetEntityList = targetEntityList[offset:]
+	if limit > 0 && limit < len(targetEntityList) {
+		targetEntityList = targetEntityList[:limit]
+	}
+	return targetEntityList
+}
+
+// filter returns the matching goals ordered by name, like the database mappers
+func (mapper GoalMemoryMapper) filter(isMatched func(entity model.GoalEntity) bool) []model.GoalEntity {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	var targetEntityList []model.GoalEntity
+	for _, entity := range mapper.store.records {
+		if isMatched(entity) {
+			targetEntityList = append(targetEntityList, entity)
+		}
+	}
+
+	sort.Slice(targetEntityList, func(i, j int) bool {
+		if targetEntityList[i].Name != targetEntityList[j].Name {
+			return targetEntityList[i].Name < targetEntityList[j].Name
+		}
+		return targetEntityList[i].Id.Hex() < targetEntityList[j].Id.Hex()
+	})
+	return targetEntityList
+}
@@ -0,0 +1,273 @@
+package goal_mapper
+
+import (
+	"context"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type GoalMongoDbMapper struct{}
+
+func (GoalMongoDbMapper) GetGoalByObjectId(plainId string) model.GoalEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("goal's id is not acceptable")
+		return model.GoalEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.GoalTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2GoalEntity(database.GetOneInMongoDB(filter))
+}
+
+func (GoalMongoDbMapper) GetGoalByName(ownerPlainId, goalName string) model.GoalEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+		primitive.E{Key: "name", Value: goalName},
+	}
+
+	database.OpenMongoDbConnection(database.GoalTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2GoalEntity(database.GetOneInMongoDB(filter))
+}
+
+func (GoalMongoDbMapper) InsertGoalByEntity(newEntity model.GoalEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	database.OpenMongoDbConnection(database.GoalTableName)
+	defer database.CloseMongoDbConnection()
+
+	newGoalId := database.InsertOneInMongoDB(convertGoalEntity2BsonD(newEntity))
+	return newGoalId.Hex()
+}
+
+func (GoalMongoDbMapper) BulkInsertGoals(entities []model.GoalEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		documents[i] = convertGoalEntity2BsonD(fillOperatingTime(entity, operatingTime))
+	}
+
+	collection := database.GetMongoCollection(database.GoalTableName)
+	result, err := collection.InsertMany(context.TODO(), documents)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	ids := make([]string, len(result.InsertedIDs))
+	for i, id := range result.InsertedIDs {
+		ids[i] = id.(primitive.ObjectID).Hex()
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (GoalMongoDbMapper) UpdateGoalByEntity(plainId string, updatedEntity model.GoalEntity) model.GoalEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("goal's id is not acceptable")
+		return model.GoalEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.GoalTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2GoalEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("goal is not exist")
+		return model.GoalEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	rowsAffected := database.UpdateManyInMongoDB(filter, convertGoalEntity2BsonD(updatedEntity))
+	if rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "rows_affected", rowsAffected)
+		return model.GoalEntity{}
+	}
+	return updatedEntity
+}
+
+func (GoalMongoDbMapper) GetAllGoals(limit, offset int) []model.GoalEntity {
+	return findMongoGoalsPage(bson.D{}, limit, offset)
+}
+
+func (GoalMongoDbMapper) GetGoalsByOwnerId(ownerPlainId string, limit, offset int) []model.GoalEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+	return findMongoGoalsPage(filter, limit, offset)
+}
+
+func (GoalMongoDbMapper) CountAllGoals() int64 {
+	database.OpenMongoDbConnection(database.GoalTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(bson.D{})
+}
+
+func (GoalMongoDbMapper) CountGoalsByOwnerId(ownerPlainId string) int64 {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+
+	database.OpenMongoDbConnection(database.GoalTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+func (GoalMongoDbMapper) CountGoalsByAccountId(accountPlainId string) int64 {
+	filter := bson.D{
+		primitive.E{Key: "account_id", Value: util.Convert2ObjectId(accountPlainId)},
+	}
+
+	database.OpenMongoDbConnection(database.GoalTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+func (GoalMongoDbMapper) DeleteGoalByObjectId(plainId string) model.GoalEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("goal's id is not acceptable")
+		return model.GoalEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.GoalTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2GoalEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("goal is not exist")
+		return model.GoalEntity{}
+	}
+
+	rowsAffected := database.DeleteManyInMongoDB(filter)
+	if rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "rows_affected", rowsAffected)
+		return model.GoalEntity{}
+	}
+	return targetEntity
+}
+
+func (GoalMongoDbMapper) DeleteAllGoals() (int64, error) {
+	collection := database.GetMongoCollection(database.GoalTableName)
+	result, err := collection.DeleteMany(context.TODO(), bson.D{})
+	if err != nil {
+		util.Logger.Errorw("delete all goals failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all goals deleted", "count", result.DeletedCount)
+	return result.DeletedCount, nil
+}
+
+func (GoalMongoDbMapper) AssignUnownedGoals(ownerPlainId string) (int64, error) {
+	collection := database.GetMongoCollection(database.GoalTableName)
+	result, err := collection.UpdateMany(context.TODO(),
+		bson.D{database.OwnerFilterInMongoDB("")},
+		bson.D{primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "owner_id", Value: util.ConvertOwner2ObjectId(ownerPlainId)},
+		}}})
+	if err != nil {
+		util.Logger.Errorw("assign unowned goals failed", "error", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// findMongoGoalsPage returns one page of the matching goals in listing order
+func findMongoGoalsPage(filter bson.D, limit, offset int) []model.GoalEntity {
+	findOptions := database.GetFindOptions()
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		findOptions.SetSkip(int64(offset))
+	}
+	// Sort by name ascending
+	findOptions.SetSort(bson.D{primitive.E{Key: "name", Value: 1}})
+
+	return findMongoGoals(filter, findOptions)
+}
+
+func findMongoGoals(filter bson.D, findOptions *options.FindOptions) []model.GoalEntity {
+	collection := database.GetMongoCollection(database.GoalTableName)
+
+	ctx := context.TODO()
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		util.Logger.Errorw("query goals failed", "error", err)
+		return []model.GoalEntity{}
+	}
+	defer cursor.Close(ctx)
+
+	var targetEntityList []model.GoalEntity
+	for cursor.Next(ctx) {
+		var bsonM bson.M
+		if err := cursor.Decode(&bsonM); err != nil {
+			util.Logger.Errorw("decode failed", "error", err)
+			continue
+		}
+		targetEntityList = append(targetEntityList, convertBsonM2GoalEntity(bsonM))
+	}
+	return targetEntityList
+}
+
+func convertGoalEntity2BsonD(entity model.GoalEntity) bson.D {
+	// 为空时自动生成新Id
+	if entity.Id == primitive.NilObjectID {
+		entity.Id = primitive.NewObjectID()
+	}
+
+	return bson.D{
+		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "owner_id", Value: entity.OwnerId},
+		primitive.E{Key: "name", Value: entity.Name},
+		primitive.E{Key: "account_id", Value: entity.AccountId},
+		primitive.E{Key: "target_amount", Value: entity.TargetAmount},
+		primitive.E{Key: "currency", Value: entity.Currency},
+		primitive.E{Key: "start_date", Value: entity.StartDate},
+		primitive.E{Key: "deadline", Value: entity.Deadline},
+		primitive.E{Key: "create_time", Value: entity.CreateTime},
+		primitive.E{Key: "modify_time", Value: entity.ModifyTime},
+	}
+}
+
+func convertBsonM2GoalEntity(bsonM bson.M) model.GoalEntity {
+	var newEntity model.GoalEntity
+	if err := database.DecodeBsonM(bsonM, &newEntity); err != nil {
+		panic(err)
+	}
+	return newEntity
+}
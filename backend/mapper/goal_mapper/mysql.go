@@ -0,0 +1,379 @@
+package goal_mapper
+
+import (
+	"bytes"
+	"database/sql"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type GoalMySqlMapper struct{}
+
+const mySqlGoalColumns = "ID, OWNER_ID, NAME, ACCOUNT_ID, TARGET_AMOUNT, CURRENCY, START_DATE, DEADLINE, CREATE_TIME, MODIFY_TIME"
+
+const mySqlGoalPlaceholders = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+func (GoalMySqlMapper) GetGoalByObjectId(plainId string) model.GoalEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlGoalColumns + " FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := queryMySqlGoals(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.GoalEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (GoalMySqlMapper) GetGoalByName(ownerPlainId, goalName string) model.GoalEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlGoalColumns + " FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND NAME = ? ")
+
+	targetEntityList := queryMySqlGoals(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), goalName)
+	if len(targetEntityList) == 0 {
+		return model.GoalEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (GoalMySqlMapper) InsertGoalByEntity(newEntity model.GoalEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" (" + mySqlGoalColumns + ") VALUES " + mySqlGoalPlaceholders)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := connection.Exec(sqlString.String(), convertGoalEntity2MySqlValues(newPlainId, newEntity)...)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (GoalMySqlMapper) BulkInsertGoals(entities []model.GoalEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" (" + mySqlGoalColumns + ") VALUES ")
+
+	ids := make([]string, len(entities))
+	values := make([]interface{}, 0, len(entities)*10)
+
+	for i, entity := range entities {
+		if i > 0 {
+			sqlString.WriteString(", ")
+		}
+		sqlString.WriteString(mySqlGoalPlaceholders)
+
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		values = append(values, convertGoalEntity2MySqlValues(ids[i], entity)...)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), values...)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != int64(len(entities)) {
+		util.Logger.Errorw("bulk insert incomplete", "error", err, "expected", len(entities), "actual", rowsAffected)
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (GoalMySqlMapper) UpdateGoalByEntity(plainId string, updatedEntity model.GoalEntity) model.GoalEntity {
+	targetEntity := INSTANCE.GetGoalByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("goal is not exist")
+		return model.GoalEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" SET NAME = ?, ")
+	sqlString.WriteString(" ACCOUNT_ID = ?, ")
+	sqlString.WriteString(" TARGET_AMOUNT = ?, ")
+	sqlString.WriteString(" CURRENCY = ?, ")
+	sqlString.WriteString(" START_DATE = ?, ")
+	sqlString.WriteString(" DEADLINE = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(),
+		updatedEntity.Name, updatedEntity.AccountId.Hex(), updatedEntity.TargetAmount, updatedEntity.Currency,
+		updatedEntity.StartDate, updatedEntity.Deadline, updatedEntity.ModifyTime, plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.GoalEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (GoalMySqlMapper) GetAllGoals(limit, offset int) []model.GoalEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlGoalColumns + " FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlGoals(sqlString.String(), limit, offset)
+	}
+	return queryMySqlGoals(sqlString.String())
+}
+
+func (GoalMySqlMapper) GetGoalsByOwnerId(ownerPlainId string, limit, offset int) []model.GoalEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlGoalColumns + " FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlGoals(sqlString.String(), ownerId, limit, offset)
+	}
+	return queryMySqlGoals(sqlString.String(), ownerId)
+}
+
+func (GoalMySqlMapper) CountGoalsByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	err := connection.QueryRow(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count goals by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (GoalMySqlMapper) CountAllGoals() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.GoalTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all goals failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (GoalMySqlMapper) CountGoalsByAccountId(accountPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" WHERE ACCOUNT_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow(sqlString.String(), accountPlainId).Scan(&count); err != nil {
+		util.Logger.Errorw("count goals failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (GoalMySqlMapper) DeleteGoalByObjectId(plainId string) model.GoalEntity {
+	targetEntity := INSTANCE.GetGoalByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("goal is not exist")
+		return model.GoalEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.GoalEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (GoalMySqlMapper) DeleteAllGoals() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.GoalTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all goals failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all goals failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all goals deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (GoalMySqlMapper) AssignUnownedGoals(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned goals failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func queryMySqlGoals(sqlString string, args ...interface{}) []model.GoalEntity {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.GoalEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2GoalEntity(rows))
+	}
+	return targetEntityList
+}
+
+// generatePlainId keeps a preset id (e.g. restored from backup) and generates one otherwise
+func generatePlainId(objectId primitive.ObjectID) string {
+	if objectId == primitive.NilObjectID {
+		return primitive.NewObjectID().Hex()
+	}
+	return objectId.Hex()
+}
+
+// convertGoalEntity2MySqlValues lists the column values in mySqlGoalColumns order
+func convertGoalEntity2MySqlValues(plainId string, entity model.GoalEntity) []interface{} {
+	return []interface{}{
+		plainId,
+		entity.OwnerId.Hex(),
+		entity.Name,
+		entity.AccountId.Hex(),
+		entity.TargetAmount,
+		entity.Currency,
+		entity.StartDate,
+		entity.Deadline,
+		entity.CreateTime,
+		entity.ModifyTime,
+	}
+}
+
+func convertRow2GoalEntity(rows *sql.Rows) model.GoalEntity {
+	var id string
+	var ownerId string
+	var name string
+	var accountId string
+	var targetAmount decimal.Decimal
+	var currency string
+	var startDate string
+	var deadline string
+	var createTime string
+	var modifyTime string
+
+	err := rows.Scan(&id, &ownerId, &name, &accountId, &targetAmount, &currency, &startDate, &deadline, &createTime, &modifyTime)
+	if err != nil {
+		util.Logger.Errorw("covert into entity failed", "error", err)
+	}
+
+	return model.GoalEntity{
+		Id:           util.Convert2ObjectId(id),
+		OwnerId:      util.Convert2ObjectId(ownerId),
+		Name:         name,
+		AccountId:    util.Convert2ObjectId(accountId),
+		TargetAmount: targetAmount,
+		Currency:     currency,
+		StartDate:    util.FormatDateTimeFromString(startDate),
+		Deadline:     util.FormatDateTimeFromString(deadline),
+		CreateTime:   util.FormatDateTimeFromString(createTime),
+		ModifyTime:   util.FormatDateTimeFromString(modifyTime),
+	}
+}
@@ -0,0 +1,318 @@
+package goal_mapper
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type GoalSqliteMapper struct{}
+
+const sqliteGoalColumns = "ID, OWNER_ID, NAME, ACCOUNT_ID, TARGET_AMOUNT, CURRENCY, START_DATE, DEADLINE, CREATE_TIME, MODIFY_TIME"
+
+const sqliteGoalPlaceholders = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+func (GoalSqliteMapper) GetGoalByObjectId(plainId string) model.GoalEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteGoalColumns + " FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := querySqliteGoals(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.GoalEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (GoalSqliteMapper) GetGoalByName(ownerPlainId, goalName string) model.GoalEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteGoalColumns + " FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND NAME = ? ")
+
+	targetEntityList := querySqliteGoals(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), goalName)
+	if len(targetEntityList) == 0 {
+		return model.GoalEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (GoalSqliteMapper) InsertGoalByEntity(newEntity model.GoalEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" (" + sqliteGoalColumns + ") VALUES " + sqliteGoalPlaceholders)
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		convertGoalEntity2SqliteValues(newPlainId, newEntity)...)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (GoalSqliteMapper) BulkInsertGoals(entities []model.GoalEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" (" + sqliteGoalColumns + ") VALUES " + sqliteGoalPlaceholders)
+
+	// One transaction keeps the batch all-or-nothing and avoids a disk sync per row
+	transaction, err := database.GetSqliteConnection().Begin()
+	if err != nil {
+		util.Logger.Errorw("bulk insert begin failed", "error", err)
+		return nil, err
+	}
+
+	statement, err := transaction.Prepare(sqlString.String())
+	if err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert prepare failed", "error", err)
+		return nil, err
+	}
+	defer statement.Close()
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		if _, err := statement.Exec(convertGoalEntity2SqliteValues(ids[i], entity)...); err != nil {
+			transaction.Rollback()
+			util.Logger.Errorw("bulk insert failed", "error", err)
+			return nil, err
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		util.Logger.Errorw("bulk insert commit failed", "error", err)
+		return nil, err
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (GoalSqliteMapper) UpdateGoalByEntity(plainId string, updatedEntity model.GoalEntity) model.GoalEntity {
+	targetEntity := INSTANCE.GetGoalByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("goal is not exist")
+		return model.GoalEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" SET NAME = ?, ")
+	sqlString.WriteString(" ACCOUNT_ID = ?, ")
+	sqlString.WriteString(" TARGET_AMOUNT = ?, ")
+	sqlString.WriteString(" CURRENCY = ?, ")
+	sqlString.WriteString(" START_DATE = ?, ")
+	sqlString.WriteString(" DEADLINE = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		updatedEntity.Name, updatedEntity.AccountId.Hex(), updatedEntity.TargetAmount, updatedEntity.Currency,
+		util.FormatDateToStringWithDash(updatedEntity.StartDate), util.FormatDateToStringWithDash(updatedEntity.Deadline),
+		util.FormatDateTimeToString(updatedEntity.ModifyTime), plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.GoalEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (GoalSqliteMapper) GetAllGoals(limit, offset int) []model.GoalEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteGoalColumns + " FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteGoals(sqlString.String(), limit, offset)
+	}
+	return querySqliteGoals(sqlString.String())
+}
+
+func (GoalSqliteMapper) GetGoalsByOwnerId(ownerPlainId string, limit, offset int) []model.GoalEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteGoalColumns + " FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteGoals(sqlString.String(), ownerId, limit, offset)
+	}
+	return querySqliteGoals(sqlString.String(), ownerId)
+}
+
+func (GoalSqliteMapper) CountGoalsByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	var count int64
+	err := database.GetSqliteConnection().QueryRow(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count goals by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (GoalSqliteMapper) CountAllGoals() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.GoalTableName)
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all goals failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (GoalSqliteMapper) CountGoalsByAccountId(accountPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" WHERE ACCOUNT_ID = ? ")
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String(), accountPlainId).Scan(&count); err != nil {
+		util.Logger.Errorw("count goals failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (GoalSqliteMapper) DeleteGoalByObjectId(plainId string) model.GoalEntity {
+	targetEntity := INSTANCE.GetGoalByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("goal is not exist")
+		return model.GoalEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.GoalEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (GoalSqliteMapper) DeleteAllGoals() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.GoalTableName)
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all goals failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all goals failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all goals deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (GoalSqliteMapper) AssignUnownedGoals(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.GoalTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned goals failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func querySqliteGoals(sqlString string, args ...interface{}) []model.GoalEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.GoalEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2GoalEntity(rows))
+	}
+	return targetEntityList
+}
+
+// convertGoalEntity2SqliteValues lists the column values in sqliteGoalColumns order,
+// dates are written as text so that comparisons and ORDER BY work on them.
+func convertGoalEntity2SqliteValues(plainId string, entity model.GoalEntity) []interface{} {
+	return []interface{}{
+		plainId,
+		entity.OwnerId.Hex(),
+		entity.Name,
+		entity.AccountId.Hex(),
+		entity.TargetAmount,
+		entity.Currency,
+		util.FormatDateToStringWithDash(entity.StartDate),
+		util.FormatDateToStringWithDash(entity.Deadline),
+		util.FormatDateTimeToString(entity.CreateTime),
+		util.FormatDateTimeToString(entity.ModifyTime),
+	}
+}
@@ -0,0 +1,124 @@
+package goal_mapper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMain(m *testing.M) {
+	tempDir, err := os.MkdirTemp("", "cashlens-sqlite-")
+	if err != nil {
+		panic(err)
+	}
+	util.SetConfigByKey("db.sqlite.path", filepath.Join(tempDir, "goal_test.db"))
+	INSTANCE = GoalSqliteMapper{}
+
+	code := m.Run()
+	os.RemoveAll(tempDir)
+	os.Exit(code)
+}
+
+func TestSqliteGoalLifecycle(t *testing.T) {
+	mapper := GoalSqliteMapper{}
+	if _, err := mapper.DeleteAllGoals(); err != nil {
+		t.Fatalf("DeleteAllGoals() error = %v", err)
+	}
+
+	savingsId := primitive.NewObjectID()
+	laptopId := mapper.InsertGoalByEntity(model.GoalEntity{
+		Name:         "Laptop",
+		AccountId:    savingsId,
+		TargetAmount: decimal.RequireFromString("2000.50"),
+		Currency:     "USD",
+		StartDate:    util.FormatDateFromStringWithDash("2024-01-01"),
+		Deadline:     util.FormatDateFromStringWithDash("2024-06-30"),
+	})
+	otherIds, err := mapper.BulkInsertGoals([]model.GoalEntity{
+		{Name: "Trip", AccountId: savingsId, TargetAmount: decimal.NewFromInt(3000), Currency: "USD",
+			StartDate: util.FormatDateFromStringWithDash("2024-01-01"), Deadline: util.FormatDateFromStringWithDash("2024-12-31")},
+		{Name: "Bike", AccountId: primitive.NewObjectID(), TargetAmount: decimal.NewFromInt(500), Currency: "EUR",
+			StartDate: util.FormatDateFromStringWithDash("2024-02-01"), Deadline: util.FormatDateFromStringWithDash("2024-04-30")},
+	})
+	if err != nil || len(otherIds) != 2 {
+		t.Fatalf("BulkInsertGoals() = %v, %v", otherIds, err)
+	}
+
+	laptop := mapper.GetGoalByObjectId(laptopId)
+	if !laptop.TargetAmount.Equal(decimal.RequireFromString("2000.5")) || laptop.AccountId != savingsId ||
+		util.FormatDateToStringWithDash(laptop.Deadline) != "2024-06-30" {
+		t.Errorf("GetGoalByObjectId() = %+v", laptop)
+	}
+	if trip := mapper.GetGoalByName("", "Trip"); trip.Id.Hex() != otherIds[0] {
+		t.Errorf("GetGoalByName() = %+v", trip)
+	}
+	if count := mapper.CountGoalsByAccountId(savingsId.Hex()); count != 2 {
+		t.Errorf("CountGoalsByAccountId() = %d, want 2", count)
+	}
+	if allList := mapper.GetAllGoals(2, 0); len(allList) != 2 || allList[0].Name != "Bike" {
+		t.Errorf("GetAllGoals() = %+v, want the first two by name", allList)
+	}
+
+	laptop.Deadline = util.FormatDateFromStringWithDash("2024-09-30")
+	mapper.UpdateGoalByEntity(laptopId, laptop)
+	if updated := mapper.GetGoalByObjectId(laptopId); util.FormatDateToStringWithDash(updated.Deadline) != "2024-09-30" {
+		t.Errorf("UpdateGoalByEntity() did not save the deadline, got %+v", updated)
+	}
+
+	if deleted := mapper.DeleteGoalByObjectId(otherIds[1]); deleted.Name != "Bike" {
+		t.Errorf("DeleteGoalByObjectId() = %+v", deleted)
+	}
+
+	deletedCount, err := mapper.DeleteAllGoals()
+	if err != nil || deletedCount != 2 {
+		t.Errorf("DeleteAllGoals() = %d, %v, want 2, nil", deletedCount, err)
+	}
+}
+
+func TestSqliteGoalOwners(t *testing.T) {
+	mapper := GoalSqliteMapper{}
+	if _, err := mapper.DeleteAllGoals(); err != nil {
+		t.Fatalf("DeleteAllGoals() error = %v", err)
+	}
+
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	unownedId := mapper.InsertGoalByEntity(model.GoalEntity{Name: "Trip", TargetAmount: decimal.NewFromInt(100), Currency: "USD"})
+	bobId := mapper.InsertGoalByEntity(model.GoalEntity{OwnerId: bob, Name: "Trip", TargetAmount: decimal.NewFromInt(100), Currency: "USD"})
+
+	// Owners look names up in their own goals only
+	if goal := mapper.GetGoalByName(bob.Hex(), "Trip"); goal.Id.Hex() != bobId {
+		t.Errorf("GetGoalByName(bob) = %+v, want id %s", goal, bobId)
+	}
+	if goal := mapper.GetGoalByName(alice.Hex(), "Trip"); !goal.IsEmpty() {
+		t.Errorf("GetGoalByName(alice) = %+v, want none", goal)
+	}
+
+	// An update keeps the owner
+	bobGoal := mapper.GetGoalByObjectId(bobId)
+	bobGoal.OwnerId = primitive.NilObjectID
+	mapper.UpdateGoalByEntity(bobId, bobGoal)
+	if owner := mapper.GetGoalByObjectId(bobId).OwnerId; owner != bob {
+		t.Errorf("UpdateGoalByEntity() changed the owner to %s", owner.Hex())
+	}
+
+	// Only the goals without owner are handed over
+	assignedCount, err := mapper.AssignUnownedGoals(alice.Hex())
+	if err != nil || assignedCount != 1 {
+		t.Fatalf("AssignUnownedGoals() = %d, %v, want 1, nil", assignedCount, err)
+	}
+	aliceList := mapper.GetGoalsByOwnerId(alice.Hex(), 0, 0)
+	if len(aliceList) != 1 || aliceList[0].Id.Hex() != unownedId {
+		t.Errorf("GetGoalsByOwnerId(alice) = %+v, want the unowned goal", aliceList)
+	}
+	if bobList := mapper.GetGoalsByOwnerId(bob.Hex(), 0, 0); len(bobList) != 1 || bobList[0].Id.Hex() != bobId {
+		t.Errorf("GetGoalsByOwnerId(bob) = %+v, want bob's goal", bobList)
+	}
+	if count := mapper.CountGoalsByOwnerId(bob.Hex()); count != 1 {
+		t.Errorf("CountGoalsByOwnerId(bob) = %d, want 1", count)
+	}
+}
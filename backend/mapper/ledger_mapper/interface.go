@@ -0,0 +1,67 @@
+package ledger_mapper
+
+import (
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+)
+
+var INSTANCE LedgerMapper
+
+// LedgerMapper keeps shared ledgers together with their members and invitations
+type LedgerMapper interface {
+	GetLedgerByObjectId(plainId string) model.LedgerEntity
+	InsertLedgerByEntity(newEntity model.LedgerEntity) string
+	BulkInsertLedgers(entities []model.LedgerEntity) ([]string, error)
+	GetAllLedgers(limit, offset int) []model.LedgerEntity
+	CountAllLedgers() int64
+	// DeleteLedgerByObjectId removes the ledger alone, its members and invitations stay
+	DeleteLedgerByObjectId(plainId string) model.LedgerEntity
+
+	GetLedgerMember(ledgerPlainId, userPlainId string) model.LedgerMemberEntity
+	GetLedgerMembersByLedgerId(ledgerPlainId string) []model.LedgerMemberEntity
+	GetLedgerMembersByUserId(userPlainId string) []model.LedgerMemberEntity
+	InsertLedgerMemberByEntity(newEntity model.LedgerMemberEntity) string
+	BulkInsertLedgerMembers(entities []model.LedgerMemberEntity) ([]string, error)
+	GetAllLedgerMembers(limit, offset int) []model.LedgerMemberEntity
+	CountAllLedgerMembers() int64
+	UpdateLedgerMemberRole(plainId, role string) model.LedgerMemberEntity
+	DeleteLedgerMemberByObjectId(plainId string) model.LedgerMemberEntity
+
+	GetLedgerInvitationByHash(tokenHash string) model.LedgerInvitationEntity
+	InsertLedgerInvitationByEntity(newEntity model.LedgerInvitationEntity) string
+	BulkInsertLedgerInvitations(entities []model.LedgerInvitationEntity) ([]string, error)
+	GetAllLedgerInvitations(limit, offset int) []model.LedgerInvitationEntity
+	CountAllLedgerInvitations() int64
+	DeleteLedgerInvitationByObjectId(plainId string) model.LedgerInvitationEntity
+
+	// DeleteAllLedgers removes every ledger with its members and invitations, returning the ledger count
+	DeleteAllLedgers() (int64, error)
+}
+
+func init() {
+	switch util.GetConfigByKey("db.type") {
+	case "mongodb":
+		INSTANCE = LedgerMongoDbMapper{}
+	case "mysql":
+		INSTANCE = LedgerMySqlMapper{}
+	case "sqlite":
+		INSTANCE = LedgerSqliteMapper{}
+	case "memory":
+		INSTANCE = NewLedgerMemoryMapper()
+	default:
+		panic("database type not supported")
+	}
+}
+
+// fillOperatingTime keeps create/modify times already set, and stamps the operating time on brand-new records.
+func fillOperatingTime(createTime, modifyTime time.Time, operatingTime time.Time) (time.Time, time.Time) {
+	if createTime.IsZero() {
+		createTime = operatingTime
+	}
+	if modifyTime.IsZero() {
+		modifyTime = operatingTime
+	}
+	return createTime, modifyTime
+}
@@ -0,0 +1,410 @@
+package ledger_mapper
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LedgerMemoryMapper keeps ledgers, members and invitations in process memory, nothing is persisted.
+// It is safe for concurrent use; copies of the mapper share the same data.
+type LedgerMemoryMapper struct {
+	store *ledgerMemoryStore
+}
+
+type ledgerMemoryStore struct {
+	mutex       sync.RWMutex
+	ledgers     map[primitive.ObjectID]model.LedgerEntity
+	members     map[primitive.ObjectID]model.LedgerMemberEntity
+	invitations map[primitive.ObjectID]model.LedgerInvitationEntity
+}
+
+// NewLedgerMemoryMapper returns an empty in-memory mapper
+func NewLedgerMemoryMapper() LedgerMemoryMapper {
+	return LedgerMemoryMapper{
+		store: &ledgerMemoryStore{
+			ledgers:     make(map[primitive.ObjectID]model.LedgerEntity),
+			members:     make(map[primitive.ObjectID]model.LedgerMemberEntity),
+			invitations: make(map[primitive.ObjectID]model.LedgerInvitationEntity),
+		},
+	}
+}
+
+func (mapper LedgerMemoryMapper) GetLedgerByObjectId(plainId string) model.LedgerEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("ledger's id is not acceptable")
+		return model.LedgerEntity{}
+	}
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return mapper.store.ledgers[objectId]
+}
+
+func (mapper LedgerMemoryMapper) InsertLedgerByEntity(newEntity model.LedgerEntity) string {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	newEntity.CreateTime, newEntity.ModifyTime = fillOperatingTime(newEntity.CreateTime, newEntity.ModifyTime, time.Now())
+	if newEntity.Id == primitive.NilObjectID {
+		newEntity.Id = primitive.NewObjectID()
+	}
+	if _, isExist := mapper.store.ledgers[newEntity.Id]; isExist {
+		util.Logger.Errorw("insert failed", "error", "duplicate ledger id: "+newEntity.Id.Hex())
+		return ""
+	}
+	mapper.store.ledgers[newEntity.Id] = newEntity
+	return newEntity.Id.Hex()
+}
+
+func (mapper LedgerMemoryMapper) BulkInsertLedgers(entities []model.LedgerEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	operatingTime := time.Now()
+	newEntityList := make([]model.LedgerEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity.CreateTime, entity.ModifyTime = fillOperatingTime(entity.CreateTime, entity.ModifyTime, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.ledgers[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate ledger id: " + entity.Id.Hex())
+		}
+		batchIds[entity.Id] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.ledgers[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper LedgerMemoryMapper) GetAllLedgers(limit, offset int) []model.LedgerEntity {
+	mapper.store.mutex.RLock()
+	targetEntityList := make([]model.LedgerEntity, 0, len(mapper.store.ledgers))
+	for _, entity := range mapper.store.ledgers {
+		targetEntityList = append(targetEntityList, entity)
+	}
+	mapper.store.mutex.RUnlock()
+
+	sort.Slice(targetEntityList, func(i, j int) bool {
+		return isCreatedBefore(targetEntityList[i].CreateTime, targetEntityList[i].Id,
+			targetEntityList[j].CreateTime, targetEntityList[j].Id)
+	})
+	start, end := pageBounds(len(targetEntityList), limit, offset)
+	return targetEntityList[start:end]
+}
+
+func (mapper LedgerMemoryMapper) CountAllLedgers() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.ledgers))
+}
+
+func (mapper LedgerMemoryMapper) DeleteLedgerByObjectId(plainId string) model.LedgerEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.ledgers[objectId]
+	if !isExist {
+		util.Logger.Infoln("ledger is not exist")
+		return model.LedgerEntity{}
+	}
+	delete(mapper.store.ledgers, objectId)
+	return targetEntity
+}
+
+func (mapper LedgerMemoryMapper) GetLedgerMember(ledgerPlainId, userPlainId string) model.LedgerMemberEntity {
+	ledgerId := util.Convert2ObjectId(ledgerPlainId)
+	userId := util.Convert2ObjectId(userPlainId)
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	for _, entity := range mapper.store.members {
+		if entity.LedgerId == ledgerId && entity.UserId == userId {
+			return entity
+		}
+	}
+	return model.LedgerMemberEntity{}
+}
+
+func (mapper LedgerMemoryMapper) GetLedgerMembersByLedgerId(ledgerPlainId string) []model.LedgerMemberEntity {
+	ledgerId := util.Convert2ObjectId(ledgerPlainId)
+	return mapper.filterMembers(func(entity model.LedgerMemberEntity) bool {
+		return entity.LedgerId == ledgerId
+	})
+}
+
+func (mapper LedgerMemoryMapper) GetLedgerMembersByUserId(userPlainId string) []model.LedgerMemberEntity {
+	userId := util.Convert2ObjectId(userPlainId)
+	return mapper.filterMembers(func(entity model.LedgerMemberEntity) bool {
+		return entity.UserId == userId
+	})
+}
+
+func (mapper LedgerMemoryMapper) InsertLedgerMemberByEntity(newEntity model.LedgerMemberEntity) string {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	newEntity.CreateTime, newEntity.ModifyTime = fillOperatingTime(newEntity.CreateTime, newEntity.ModifyTime, time.Now())
+	if newEntity.Id == primitive.NilObjectID {
+		newEntity.Id = primitive.NewObjectID()
+	}
+	// A user joins a ledger only once, like the unique index of the database mappers
+	for _, entity := range mapper.store.members {
+		if entity.Id == newEntity.Id || (entity.LedgerId == newEntity.LedgerId && entity.UserId == newEntity.UserId) {
+			util.Logger.Errorw("insert failed", "error", "duplicate ledger member: "+newEntity.ToString())
+			return ""
+		}
+	}
+	mapper.store.members[newEntity.Id] = newEntity
+	return newEntity.Id.Hex()
+}
+
+func (mapper LedgerMemoryMapper) BulkInsertLedgerMembers(entities []model.LedgerMemberEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	type membership struct{ ledgerId, userId primitive.ObjectID }
+	memberships := make(map[membership]bool, len(mapper.store.members)+len(entities))
+	for _, entity := range mapper.store.members {
+		memberships[membership{entity.LedgerId, entity.UserId}] = true
+	}
+	operatingTime := time.Now()
+	newEntityList := make([]model.LedgerMemberEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity.CreateTime, entity.ModifyTime = fillOperatingTime(entity.CreateTime, entity.ModifyTime, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.members[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate ledger member id: " + entity.Id.Hex())
+		}
+		if memberships[membership{entity.LedgerId, entity.UserId}] {
+			return nil, errors.New("duplicate ledger member: " + entity.ToString())
+		}
+		batchIds[entity.Id] = true
+		memberships[membership{entity.LedgerId, entity.UserId}] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.members[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper LedgerMemoryMapper) GetAllLedgerMembers(limit, offset int) []model.LedgerMemberEntity {
+	targetEntityList := mapper.filterMembers(func(model.LedgerMemberEntity) bool {
+		return true
+	})
+	start, end := pageBounds(len(targetEntityList), limit, offset)
+	return targetEntityList[start:end]
+}
+
+func (mapper LedgerMemoryMapper) CountAllLedgerMembers() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.members))
+}
+
+func (mapper LedgerMemoryMapper) UpdateLedgerMemberRole(plainId, role string) model.LedgerMemberEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.members[objectId]
+	if !isExist {
+		util.Logger.Infoln("ledger member is not exist")
+		return model.LedgerMemberEntity{}
+	}
+	targetEntity.Role = role
+	targetEntity.ModifyTime = time.Now()
+	mapper.store.members[objectId] = targetEntity
+	return targetEntity
+}
+
+func (mapper LedgerMemoryMapper) DeleteLedgerMemberByObjectId(plainId string) model.LedgerMemberEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.members[objectId]
+	if !isExist {
+		util.Logger.Infoln("ledger member is not exist")
+		return model.LedgerMemberEntity{}
+	}
+	delete(mapper.store.members, objectId)
+	return targetEntity
+}
+
+func (mapper LedgerMemoryMapper) GetLedgerInvitationByHash(tokenHash string) model.LedgerInvitationEntity {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	for _, entity := range mapper.store.invitations {
+		if entity.TokenHash == tokenHash {
+			return entity
+		}
+	}
+	return model.LedgerInvitationEntity{}
+}
+
+func (mapper LedgerMemoryMapper) InsertLedgerInvitationByEntity(newEntity model.LedgerInvitationEntity) string {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	newEntity.CreateTime, newEntity.ModifyTime = fillOperatingTime(newEntity.CreateTime, newEntity.ModifyTime, time.Now())
+	if newEntity.Id == primitive.NilObjectID {
+		newEntity.Id = primitive.NewObjectID()
+	}
+	if _, isExist := mapper.store.invitations[newEntity.Id]; isExist {
+		util.Logger.Errorw("insert failed", "error", "duplicate ledger invitation id: "+newEntity.Id.Hex())
+		return ""
+	}
+	mapper.store.invitations[newEntity.Id] = newEntity
+	return newEntity.Id.Hex()
+}
+
+func (mapper LedgerMemoryMapper) BulkInsertLedgerInvitations(entities []model.LedgerInvitationEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	tokenHashes := make(map[string]bool, len(mapper.store.invitations)+len(entities))
+	for _, entity := range mapper.store.invitations {
+		tokenHashes[entity.TokenHash] = true
+	}
+	operatingTime := time.Now()
+	newEntityList := make([]model.LedgerInvitationEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity.CreateTime, entity.ModifyTime = fillOperatingTime(entity.CreateTime, entity.ModifyTime, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.invitations[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate ledger invitation id: " + entity.Id.Hex())
+		}
+		if tokenHashes[entity.TokenHash] {
+			return nil, errors.New("duplicate ledger invitation token of id: " + entity.Id.Hex())
+		}
+		batchIds[entity.Id] = true
+		tokenHashes[entity.TokenHash] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.invitations[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper LedgerMemoryMapper) GetAllLedgerInvitations(limit, offset int) []model.LedgerInvitationEntity {
+	mapper.store.mutex.RLock()
+	targetEntityList := make([]model.LedgerInvitationEntity, 0, len(mapper.store.invitations))
+	for _, entity := range mapper.store.invitations {
+		targetEntityList = append(targetEntityList, entity)
+	}
+	mapper.store.mutex.RUnlock()
+
+	sort.Slice(targetEntityList, func(i, j int) bool {
+		return isCreatedBefore(targetEntityList[i].CreateTime, targetEntityList[i].Id,
+			targetEntityList[j].CreateTime, targetEntityList[j].Id)
+	})
+	start, end := pageBounds(len(targetEntityList), limit, offset)
+	return targetEntityList[start:end]
+}
+
+func (mapper LedgerMemoryMapper) CountAllLedgerInvitations() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.invitations))
+}
+
+func (mapper LedgerMemoryMapper) DeleteLedgerInvitationByObjectId(plainId string) model.LedgerInvitationEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.invitations[objectId]
+	if !isExist {
+		util.Logger.Infoln("ledger invitation is not exist")
+		return model.LedgerInvitationEntity{}
+	}
+	delete(mapper.store.invitations, objectId)
+	return targetEntity
+}
+
+func (mapper LedgerMemoryMapper) DeleteAllLedgers() (int64, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	deletedCount := int64(len(mapper.store.ledgers))
+	mapper.store.ledgers = make(map[primitive.ObjectID]model.LedgerEntity)
+	mapper.store.members = make(map[primitive.ObjectID]model.LedgerMemberEntity)
+	mapper.store.invitations = make(map[primitive.ObjectID]model.LedgerInvitationEntity)
+	return deletedCount, nil
+}
+
+// filterMembers returns the matching members oldest first, like the database mappers
+func (mapper LedgerMemoryMapper) filterMembers(isMatched func(model.LedgerMemberEntity) bool) []model.LedgerMemberEntity {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	var targetEntityList []model.LedgerMemberEntity
+	for _, entity := range mapper.store.members {
+		if isMatched(entity) {
+			targetEntityList = append(targetEntityList, entity)
+		}
+	}
+
+	sort.Slice(targetEntityList, func(i, j int) bool {
+		return isCreatedBefore(targetEntityList[i].CreateTime, targetEntityList[i].Id,
+			targetEntityList[j].CreateTime, targetEntityList[j].Id)
+	})
+	return targetEntityList
+}
+
+// isCreatedBefore orders records oldest first and breaks ties by id
+func isCreatedBefore(createTime time.Time, id primitive.ObjectID, otherCreateTime time.Time, otherId primitive.ObjectID) bool {
+	if !createTime.Equal(otherCreateTime) {
+		return createTime.Before(otherCreateTime)
+	}
+	return id.Hex() < otherId.Hex()
+}
+
+// pageBounds turns limit and offset into slice bounds, a limit of zero means no limit
+func pageBounds(total, limit, offset int) (int, int) {
+	if offset > total {
+		offset = total
+	}
+	if limit <= 0 || offset+limit > total {
+		return offset, total
+	}
+	return offset, offset + limit
+}
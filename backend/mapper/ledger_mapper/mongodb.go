@@ -0,0 +1,435 @@
+package ledger_mapper
+
+import (
+	"context"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LedgerMongoDbMapper struct{}
+
+func (LedgerMongoDbMapper) GetLedgerByObjectId(plainId string) model.LedgerEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("ledger's id is not acceptable")
+		return model.LedgerEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.LedgerTableName)
+	defer database.CloseMongoDbConnection()
+
+	var targetEntity model.LedgerEntity
+	decodeBsonM(database.GetOneInMongoDB(filter), &targetEntity)
+	return targetEntity
+}
+
+func (LedgerMongoDbMapper) InsertLedgerByEntity(newEntity model.LedgerEntity) string {
+	newEntity.CreateTime, newEntity.ModifyTime = fillOperatingTime(newEntity.CreateTime, newEntity.ModifyTime, time.Now())
+
+	database.OpenMongoDbConnection(database.LedgerTableName)
+	defer database.CloseMongoDbConnection()
+
+	newLedgerId := database.InsertOneInMongoDB(convertLedgerEntity2BsonD(newEntity))
+	return newLedgerId.Hex()
+}
+
+func (LedgerMongoDbMapper) BulkInsertLedgers(entities []model.LedgerEntity) ([]string, error) {
+	operatingTime := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		entity.CreateTime, entity.ModifyTime = fillOperatingTime(entity.CreateTime, entity.ModifyTime, operatingTime)
+		documents[i] = convertLedgerEntity2BsonD(entity)
+	}
+	return insertMongoDbDocuments(database.LedgerTableName, documents)
+}
+
+func (LedgerMongoDbMapper) GetAllLedgers(limit, offset int) []model.LedgerEntity {
+	var targetEntityList []model.LedgerEntity
+	for _, bsonM := range findMongoDbDocuments(database.LedgerTableName, bson.D{}, limit, offset) {
+		var targetEntity model.LedgerEntity
+		decodeBsonM(bsonM, &targetEntity)
+		targetEntityList = append(targetEntityList, targetEntity)
+	}
+	return targetEntityList
+}
+
+func (LedgerMongoDbMapper) CountAllLedgers() int64 {
+	database.OpenMongoDbConnection(database.LedgerTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(bson.D{})
+}
+
+func (LedgerMongoDbMapper) DeleteLedgerByObjectId(plainId string) model.LedgerEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("ledger's id is not acceptable")
+		return model.LedgerEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.LedgerTableName)
+	defer database.CloseMongoDbConnection()
+
+	var targetEntity model.LedgerEntity
+	decodeBsonM(database.GetOneInMongoDB(filter), &targetEntity)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("ledger is not exist")
+		return model.LedgerEntity{}
+	}
+
+	rowsAffected := database.DeleteManyInMongoDB(filter)
+	if rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "rows_affected", rowsAffected)
+		return model.LedgerEntity{}
+	}
+	return targetEntity
+}
+
+func (LedgerMongoDbMapper) GetLedgerMember(ledgerPlainId, userPlainId string) model.LedgerMemberEntity {
+	filter := bson.D{
+		primitive.E{Key: "ledger_id", Value: util.Convert2ObjectId(ledgerPlainId)},
+		primitive.E{Key: "user_id", Value: util.Convert2ObjectId(userPlainId)},
+	}
+
+	database.OpenMongoDbConnection(database.LedgerMemberTableName)
+	defer database.CloseMongoDbConnection()
+
+	var targetEntity model.LedgerMemberEntity
+	decodeBsonM(database.GetOneInMongoDB(filter), &targetEntity)
+	return targetEntity
+}
+
+func (LedgerMongoDbMapper) GetLedgerMembersByLedgerId(ledgerPlainId string) []model.LedgerMemberEntity {
+	return queryMongoDbLedgerMembers(bson.D{
+		primitive.E{Key: "ledger_id", Value: util.Convert2ObjectId(ledgerPlainId)},
+	})
+}
+
+func (LedgerMongoDbMapper) GetLedgerMembersByUserId(userPlainId string) []model.LedgerMemberEntity {
+	return queryMongoDbLedgerMembers(bson.D{
+		primitive.E{Key: "user_id", Value: util.Convert2ObjectId(userPlainId)},
+	})
+}
+
+func (LedgerMongoDbMapper) InsertLedgerMemberByEntity(newEntity model.LedgerMemberEntity) string {
+	newEntity.CreateTime, newEntity.ModifyTime = fillOperatingTime(newEntity.CreateTime, newEntity.ModifyTime, time.Now())
+
+	database.OpenMongoDbConnection(database.LedgerMemberTableName)
+	defer database.CloseMongoDbConnection()
+
+	newMemberId := database.InsertOneInMongoDB(convertLedgerMemberEntity2BsonD(newEntity))
+	return newMemberId.Hex()
+}
+
+func (LedgerMongoDbMapper) BulkInsertLedgerMembers(entities []model.LedgerMemberEntity) ([]string, error) {
+	operatingTime := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		entity.CreateTime, entity.ModifyTime = fillOperatingTime(entity.CreateTime, entity.ModifyTime, operatingTime)
+		documents[i] = convertLedgerMemberEntity2BsonD(entity)
+	}
+	return insertMongoDbDocuments(database.LedgerMemberTableName, documents)
+}
+
+func (LedgerMongoDbMapper) GetAllLedgerMembers(limit, offset int) []model.LedgerMemberEntity {
+	return decodeLedgerMembers(findMongoDbDocuments(database.LedgerMemberTableName, bson.D{}, limit, offset))
+}
+
+func (LedgerMongoDbMapper) CountAllLedgerMembers() int64 {
+	database.OpenMongoDbConnection(database.LedgerMemberTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(bson.D{})
+}
+
+func (LedgerMongoDbMapper) UpdateLedgerMemberRole(plainId, role string) model.LedgerMemberEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("ledger member's id is not acceptable")
+		return model.LedgerMemberEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.LedgerMemberTableName)
+	defer database.CloseMongoDbConnection()
+
+	var targetEntity model.LedgerMemberEntity
+	decodeBsonM(database.GetOneInMongoDB(filter), &targetEntity)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("ledger member is not exist")
+		return model.LedgerMemberEntity{}
+	}
+
+	targetEntity.Role = role
+	targetEntity.ModifyTime = time.Now()
+	rowsAffected := database.UpdateManyInMongoDB(filter, bson.D{
+		primitive.E{Key: "role", Value: targetEntity.Role},
+		primitive.E{Key: "modify_time", Value: targetEntity.ModifyTime},
+	})
+	if rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "rows_affected", rowsAffected)
+		return model.LedgerMemberEntity{}
+	}
+	return targetEntity
+}
+
+func (LedgerMongoDbMapper) DeleteLedgerMemberByObjectId(plainId string) model.LedgerMemberEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("ledger member's id is not acceptable")
+		return model.LedgerMemberEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.LedgerMemberTableName)
+	defer database.CloseMongoDbConnection()
+
+	var targetEntity model.LedgerMemberEntity
+	decodeBsonM(database.GetOneInMongoDB(filter), &targetEntity)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("ledger member is not exist")
+		return model.LedgerMemberEntity{}
+	}
+
+	rowsAffected := database.DeleteManyInMongoDB(filter)
+	if rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "rows_affected", rowsAffected)
+		return model.LedgerMemberEntity{}
+	}
+	return targetEntity
+}
+
+func (LedgerMongoDbMapper) GetLedgerInvitationByHash(tokenHash string) model.LedgerInvitationEntity {
+	filter := bson.D{
+		primitive.E{Key: "token_hash", Value: tokenHash},
+	}
+
+	database.OpenMongoDbConnection(database.LedgerInvitationTableName)
+	defer database.CloseMongoDbConnection()
+
+	var targetEntity model.LedgerInvitationEntity
+	decodeBsonM(database.GetOneInMongoDB(filter), &targetEntity)
+	return targetEntity
+}
+
+func (LedgerMongoDbMapper) InsertLedgerInvitationByEntity(newEntity model.LedgerInvitationEntity) string {
+	newEntity.CreateTime, newEntity.ModifyTime = fillOperatingTime(newEntity.CreateTime, newEntity.ModifyTime, time.Now())
+
+	database.OpenMongoDbConnection(database.LedgerInvitationTableName)
+	defer database.CloseMongoDbConnection()
+
+	newInvitationId := database.InsertOneInMongoDB(convertLedgerInvitationEntity2BsonD(newEntity))
+	return newInvitationId.Hex()
+}
+
+func (LedgerMongoDbMapper) BulkInsertLedgerInvitations(entities []model.LedgerInvitationEntity) ([]string, error) {
+	operatingTime := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		entity.CreateTime, entity.ModifyTime = fillOperatingTime(entity.CreateTime, entity.ModifyTime, operatingTime)
+		documents[i] = convertLedgerInvitationEntity2BsonD(entity)
+	}
+	return insertMongoDbDocuments(database.LedgerInvitationTableName, documents)
+}
+
+func (LedgerMongoDbMapper) GetAllLedgerInvitations(limit, offset int) []model.LedgerInvitationEntity {
+	var targetEntityList []model.LedgerInvitationEntity
+	for _, bsonM := range findMongoDbDocuments(database.LedgerInvitationTableName, bson.D{}, limit, offset) {
+		var targetEntity model.LedgerInvitationEntity
+		decodeBsonM(bsonM, &targetEntity)
+		targetEntityList = append(targetEntityList, targetEntity)
+	}
+	return targetEntityList
+}
+
+func (LedgerMongoDbMapper) CountAllLedgerInvitations() int64 {
+	database.OpenMongoDbConnection(database.LedgerInvitationTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(bson.D{})
+}
+
+func (LedgerMongoDbMapper) DeleteLedgerInvitationByObjectId(plainId string) model.LedgerInvitationEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("ledger invitation's id is not acceptable")
+		return model.LedgerInvitationEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.LedgerInvitationTableName)
+	defer database.CloseMongoDbConnection()
+
+	var targetEntity model.LedgerInvitationEntity
+	decodeBsonM(database.GetOneInMongoDB(filter), &targetEntity)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("ledger invitation is not exist")
+		return model.LedgerInvitationEntity{}
+	}
+
+	// Only the caller whose delete went through gets the invitation, so a token is used once
+	rowsAffected := database.DeleteManyInMongoDB(filter)
+	if rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "rows_affected", rowsAffected)
+		return model.LedgerInvitationEntity{}
+	}
+	return targetEntity
+}
+
+func (LedgerMongoDbMapper) DeleteAllLedgers() (int64, error) {
+	var deletedCount int64
+	for _, tableName := range []string{database.LedgerInvitationTableName, database.LedgerMemberTableName, database.LedgerTableName} {
+		result, err := database.GetMongoCollection(tableName).DeleteMany(context.TODO(), bson.D{})
+		if err != nil {
+			util.Logger.Errorw("delete all ledgers failed", "collection", tableName, "error", err)
+			return 0, err
+		}
+		deletedCount = result.DeletedCount
+	}
+
+	util.Logger.Infow("all ledgers deleted", "count", deletedCount)
+	return deletedCount, nil
+}
+
+func queryMongoDbLedgerMembers(filter bson.D) []model.LedgerMemberEntity {
+	return decodeLedgerMembers(findMongoDbDocuments(database.LedgerMemberTableName, filter, 0, 0))
+}
+
+func decodeLedgerMembers(bsonMList []bson.M) []model.LedgerMemberEntity {
+	var targetEntityList []model.LedgerMemberEntity
+	for _, bsonM := range bsonMList {
+		var targetEntity model.LedgerMemberEntity
+		decodeBsonM(bsonM, &targetEntity)
+		targetEntityList = append(targetEntityList, targetEntity)
+	}
+	return targetEntityList
+}
+
+// findMongoDbDocuments returns the matching documents oldest first, a limit of zero means no limit
+func findMongoDbDocuments(tableName string, filter bson.D, limit, offset int) []bson.M {
+	findOptions := database.GetFindOptions()
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		findOptions.SetSkip(int64(offset))
+	}
+	findOptions.SetSort(bson.D{
+		primitive.E{Key: "create_time", Value: 1},
+		primitive.E{Key: "_id", Value: 1},
+	})
+
+	collection := database.GetMongoCollection(tableName)
+
+	ctx := context.TODO()
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		util.Logger.Errorw("query failed", "collection", tableName, "error", err)
+		return []bson.M{}
+	}
+	defer cursor.Close(ctx)
+
+	var bsonMList []bson.M
+	for cursor.Next(ctx) {
+		var bsonM bson.M
+		if err := cursor.Decode(&bsonM); err != nil {
+			util.Logger.Errorw("decode failed", "error", err)
+			continue
+		}
+		bsonMList = append(bsonMList, bsonM)
+	}
+	return bsonMList
+}
+
+func insertMongoDbDocuments(tableName string, documents []interface{}) ([]string, error) {
+	if len(documents) == 0 {
+		return []string{}, nil
+	}
+
+	result, err := database.GetMongoCollection(tableName).InsertMany(context.TODO(), documents)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "collection", tableName, "error", err)
+		return nil, err
+	}
+
+	ids := make([]string, len(result.InsertedIDs))
+	for i, id := range result.InsertedIDs {
+		ids[i] = id.(primitive.ObjectID).Hex()
+	}
+
+	util.Logger.Infow("bulk insert successful", "collection", tableName, "count", len(ids))
+	return ids, nil
+}
+
+func convertLedgerEntity2BsonD(entity model.LedgerEntity) bson.D {
+	// 为空时自动生成新Id
+	if entity.Id == primitive.NilObjectID {
+		entity.Id = primitive.NewObjectID()
+	}
+	return bson.D{
+		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "name", Value: entity.Name},
+		primitive.E{Key: "create_time", Value: entity.CreateTime},
+		primitive.E{Key: "modify_time", Value: entity.ModifyTime},
+	}
+}
+
+func convertLedgerMemberEntity2BsonD(entity model.LedgerMemberEntity) bson.D {
+	// 为空时自动生成新Id
+	if entity.Id == primitive.NilObjectID {
+		entity.Id = primitive.NewObjectID()
+	}
+	return bson.D{
+		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "ledger_id", Value: entity.LedgerId},
+		primitive.E{Key: "user_id", Value: entity.UserId},
+		primitive.E{Key: "role", Value: entity.Role},
+		primitive.E{Key: "create_time", Value: entity.CreateTime},
+		primitive.E{Key: "modify_time", Value: entity.ModifyTime},
+	}
+}
+
+func convertLedgerInvitationEntity2BsonD(entity model.LedgerInvitationEntity) bson.D {
+	// 为空时自动生成新Id
+	if entity.Id == primitive.NilObjectID {
+		entity.Id = primitive.NewObjectID()
+	}
+	return bson.D{
+		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "ledger_id", Value: entity.LedgerId},
+		primitive.E{Key: "role", Value: entity.Role},
+		primitive.E{Key: "token_hash", Value: entity.TokenHash},
+		primitive.E{Key: "invited_by", Value: entity.InvitedBy},
+		primitive.E{Key: "expire_time", Value: entity.ExpireTime},
+		primitive.E{Key: "create_time", Value: entity.CreateTime},
+		primitive.E{Key: "modify_time", Value: entity.ModifyTime},
+	}
+}
+
+func decodeBsonM(bsonM bson.M, targetEntity interface{}) {
+	if err := database.DecodeBsonM(bsonM, targetEntity); err != nil {
+		panic(err)
+	}
+}
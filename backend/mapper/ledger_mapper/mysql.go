@@ -0,0 +1,565 @@
+package ledger_mapper
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LedgerMySqlMapper struct{}
+
+const (
+	mySqlLedgerColumns           = "ID, NAME, CREATE_TIME, MODIFY_TIME"
+	mySqlLedgerMemberColumns     = "ID, LEDGER_ID, USER_ID, ROLE, CREATE_TIME, MODIFY_TIME"
+	mySqlLedgerInvitationColumns = "ID, LEDGER_ID, ROLE, TOKEN_HASH, INVITED_BY, EXPIRE_TIME, CREATE_TIME, MODIFY_TIME"
+)
+
+func (LedgerMySqlMapper) GetLedgerByObjectId(plainId string) model.LedgerEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlLedgerColumns + " FROM ")
+	sqlString.WriteString(database.LedgerTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return model.LedgerEntity{}
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return model.LedgerEntity{}
+	}
+	return convertRow2LedgerEntity(rows)
+}
+
+func (LedgerMySqlMapper) InsertLedgerByEntity(newEntity model.LedgerEntity) string {
+	newEntity.CreateTime, newEntity.ModifyTime = fillOperatingTime(newEntity.CreateTime, newEntity.ModifyTime, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.LedgerTableName)
+	sqlString.WriteString(" (" + mySqlLedgerColumns + ") VALUES (?, ?, ?, ?)")
+
+	newPlainId := generatePlainId(newEntity.Id)
+	return execMySqlInsert(sqlString.String(), newPlainId, newPlainId, newEntity.Name, newEntity.CreateTime, newEntity.ModifyTime)
+}
+
+func (LedgerMySqlMapper) BulkInsertLedgers(entities []model.LedgerEntity) ([]string, error) {
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	rowList := make([][]interface{}, len(entities))
+	for i, entity := range entities {
+		entity.CreateTime, entity.ModifyTime = fillOperatingTime(entity.CreateTime, entity.ModifyTime, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		rowList[i] = []interface{}{ids[i], entity.Name, entity.CreateTime, entity.ModifyTime}
+	}
+
+	if err := execMySqlBulkInsert(database.LedgerTableName, mySqlLedgerColumns, rowList); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (LedgerMySqlMapper) GetAllLedgers(limit, offset int) []model.LedgerEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlLedgerColumns + " FROM ")
+	sqlString.WriteString(database.LedgerTableName)
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlLedgers(sqlString.String(), limit, offset)
+	}
+	return queryMySqlLedgers(sqlString.String())
+}
+
+func (LedgerMySqlMapper) CountAllLedgers() int64 {
+	return countMySqlRows(database.LedgerTableName)
+}
+
+func (LedgerMySqlMapper) DeleteLedgerByObjectId(plainId string) model.LedgerEntity {
+	targetEntity := INSTANCE.GetLedgerByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("ledger is not exist")
+		return model.LedgerEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.LedgerTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	if !execMySqlUpdate(sqlString.String(), plainId) {
+		return model.LedgerEntity{}
+	}
+	return targetEntity
+}
+
+func (LedgerMySqlMapper) GetLedgerMember(ledgerPlainId, userPlainId string) model.LedgerMemberEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlLedgerMemberColumns + " FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" WHERE LEDGER_ID = ? AND USER_ID = ? ")
+
+	targetEntityList := queryMySqlLedgerMembers(sqlString.String(), ledgerPlainId, userPlainId)
+	if len(targetEntityList) == 0 {
+		return model.LedgerMemberEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (LedgerMySqlMapper) GetLedgerMembersByLedgerId(ledgerPlainId string) []model.LedgerMemberEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlLedgerMemberColumns + " FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" WHERE LEDGER_ID = ? ")
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	return queryMySqlLedgerMembers(sqlString.String(), ledgerPlainId)
+}
+
+func (LedgerMySqlMapper) GetLedgerMembersByUserId(userPlainId string) []model.LedgerMemberEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlLedgerMemberColumns + " FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" WHERE USER_ID = ? ")
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	return queryMySqlLedgerMembers(sqlString.String(), userPlainId)
+}
+
+func (LedgerMySqlMapper) InsertLedgerMemberByEntity(newEntity model.LedgerMemberEntity) string {
+	newEntity.CreateTime, newEntity.ModifyTime = fillOperatingTime(newEntity.CreateTime, newEntity.ModifyTime, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" (" + mySqlLedgerMemberColumns + ") VALUES (?, ?, ?, ?, ?, ?)")
+
+	newPlainId := generatePlainId(newEntity.Id)
+	return execMySqlInsert(sqlString.String(), newPlainId, newPlainId, newEntity.LedgerId.Hex(), newEntity.UserId.Hex(),
+		newEntity.Role, newEntity.CreateTime, newEntity.ModifyTime)
+}
+
+func (LedgerMySqlMapper) BulkInsertLedgerMembers(entities []model.LedgerMemberEntity) ([]string, error) {
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	rowList := make([][]interface{}, len(entities))
+	for i, entity := range entities {
+		entity.CreateTime, entity.ModifyTime = fillOperatingTime(entity.CreateTime, entity.ModifyTime, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		rowList[i] = []interface{}{ids[i], entity.LedgerId.Hex(), entity.UserId.Hex(), entity.Role,
+			entity.CreateTime, entity.ModifyTime}
+	}
+
+	if err := execMySqlBulkInsert(database.LedgerMemberTableName, mySqlLedgerMemberColumns, rowList); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (LedgerMySqlMapper) GetAllLedgerMembers(limit, offset int) []model.LedgerMemberEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlLedgerMemberColumns + " FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlLedgerMembers(sqlString.String(), limit, offset)
+	}
+	return queryMySqlLedgerMembers(sqlString.String())
+}
+
+func (LedgerMySqlMapper) CountAllLedgerMembers() int64 {
+	return countMySqlRows(database.LedgerMemberTableName)
+}
+
+func (LedgerMySqlMapper) UpdateLedgerMemberRole(plainId, role string) model.LedgerMemberEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlLedgerMemberColumns + " FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := queryMySqlLedgerMembers(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		util.Logger.Infoln("ledger member is not exist")
+		return model.LedgerMemberEntity{}
+	}
+	targetEntity := targetEntityList[0]
+	targetEntity.Role = role
+	targetEntity.ModifyTime = time.Now()
+
+	sqlString.Reset()
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" SET ROLE = ?, MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	if !execMySqlUpdate(sqlString.String(), targetEntity.Role, targetEntity.ModifyTime, plainId) {
+		return model.LedgerMemberEntity{}
+	}
+	return targetEntity
+}
+
+func (LedgerMySqlMapper) DeleteLedgerMemberByObjectId(plainId string) model.LedgerMemberEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlLedgerMemberColumns + " FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := queryMySqlLedgerMembers(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		util.Logger.Infoln("ledger member is not exist")
+		return model.LedgerMemberEntity{}
+	}
+
+	sqlString.Reset()
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	if !execMySqlUpdate(sqlString.String(), plainId) {
+		return model.LedgerMemberEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (LedgerMySqlMapper) GetLedgerInvitationByHash(tokenHash string) model.LedgerInvitationEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlLedgerInvitationColumns + " FROM ")
+	sqlString.WriteString(database.LedgerInvitationTableName)
+	sqlString.WriteString(" WHERE TOKEN_HASH = ? ")
+
+	return queryMySqlLedgerInvitation(sqlString.String(), tokenHash)
+}
+
+func (LedgerMySqlMapper) InsertLedgerInvitationByEntity(newEntity model.LedgerInvitationEntity) string {
+	newEntity.CreateTime, newEntity.ModifyTime = fillOperatingTime(newEntity.CreateTime, newEntity.ModifyTime, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.LedgerInvitationTableName)
+	sqlString.WriteString(" (" + mySqlLedgerInvitationColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
+
+	newPlainId := generatePlainId(newEntity.Id)
+	return execMySqlInsert(sqlString.String(), newPlainId, newPlainId, newEntity.LedgerId.Hex(), newEntity.Role,
+		newEntity.TokenHash, newEntity.InvitedBy.Hex(), newEntity.ExpireTime, newEntity.CreateTime, newEntity.ModifyTime)
+}
+
+func (LedgerMySqlMapper) BulkInsertLedgerInvitations(entities []model.LedgerInvitationEntity) ([]string, error) {
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	rowList := make([][]interface{}, len(entities))
+	for i, entity := range entities {
+		entity.CreateTime, entity.ModifyTime = fillOperatingTime(entity.CreateTime, entity.ModifyTime, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		rowList[i] = []interface{}{ids[i], entity.LedgerId.Hex(), entity.Role, entity.TokenHash, entity.InvitedBy.Hex(),
+			entity.ExpireTime, entity.CreateTime, entity.ModifyTime}
+	}
+
+	if err := execMySqlBulkInsert(database.LedgerInvitationTableName, mySqlLedgerInvitationColumns, rowList); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (LedgerMySqlMapper) GetAllLedgerInvitations(limit, offset int) []model.LedgerInvitationEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlLedgerInvitationColumns + " FROM ")
+	sqlString.WriteString(database.LedgerInvitationTableName)
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlLedgerInvitations(sqlString.String(), limit, offset)
+	}
+	return queryMySqlLedgerInvitations(sqlString.String())
+}
+
+func (LedgerMySqlMapper) CountAllLedgerInvitations() int64 {
+	return countMySqlRows(database.LedgerInvitationTableName)
+}
+
+func (LedgerMySqlMapper) DeleteLedgerInvitationByObjectId(plainId string) model.LedgerInvitationEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlLedgerInvitationColumns + " FROM ")
+	sqlString.WriteString(database.LedgerInvitationTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntity := queryMySqlLedgerInvitation(sqlString.String(), plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("ledger invitation is not exist")
+		return model.LedgerInvitationEntity{}
+	}
+
+	sqlString.Reset()
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.LedgerInvitationTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	// Only the caller whose delete went through gets the invitation, so a token is used once
+	if !execMySqlUpdate(sqlString.String(), plainId) {
+		return model.LedgerInvitationEntity{}
+	}
+	return targetEntity
+}
+
+func (LedgerMySqlMapper) DeleteAllLedgers() (int64, error) {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var deletedCount int64
+	for _, tableName := range []string{database.LedgerInvitationTableName, database.LedgerMemberTableName, database.LedgerTableName} {
+		result, err := connection.Exec("DELETE FROM " + tableName)
+		if err != nil {
+			util.Logger.Errorw("delete all ledgers failed", "table", tableName, "error", err)
+			return 0, err
+		}
+		deletedCount, err = result.RowsAffected()
+		if err != nil {
+			util.Logger.Errorw("delete all ledgers failed", "table", tableName, "error", err)
+			return 0, err
+		}
+	}
+
+	util.Logger.Infow("all ledgers deleted", "count", deletedCount)
+	return deletedCount, nil
+}
+
+// execMySqlInsert runs the insert and returns the new id, blank when nothing was inserted
+func execMySqlInsert(sqlString, newPlainId string, args ...interface{}) string {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+		return ""
+	}
+	return newPlainId
+}
+
+// execMySqlUpdate runs an update or delete of a single row and tells whether it went through
+func execMySqlUpdate(sqlString string, args ...interface{}) bool {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return false
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+		return false
+	}
+	return true
+}
+
+// execMySqlBulkInsert writes every row with a single multi-row insert
+func execMySqlBulkInsert(tableName, columns string, rowList [][]interface{}) error {
+	if len(rowList) == 0 {
+		return nil
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(tableName)
+	sqlString.WriteString(" (" + columns + ") VALUES ")
+
+	values := make([]interface{}, 0, len(rowList)*len(rowList[0]))
+	for i, args := range rowList {
+		if i > 0 {
+			sqlString.WriteString(", ")
+		}
+		sqlString.WriteString("(?" + strings.Repeat(", ?", len(args)-1) + ")")
+		values = append(values, args...)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), values...)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "table", tableName, "error", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != int64(len(rowList)) {
+		util.Logger.Errorw("bulk insert incomplete", "table", tableName, "error", err, "expected", len(rowList), "actual", rowsAffected)
+	}
+
+	util.Logger.Infow("bulk insert successful", "table", tableName, "count", len(rowList))
+	return nil
+}
+
+func countMySqlRows(tableName string) int64 {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow("SELECT COUNT(1) FROM " + tableName).Scan(&count); err != nil {
+		util.Logger.Errorw("count failed", "table", tableName, "error", err)
+		return 0
+	}
+	return count
+}
+
+func queryMySqlLedgers(sqlString string, args ...interface{}) []model.LedgerEntity {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.LedgerEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2LedgerEntity(rows))
+	}
+	return targetEntityList
+}
+
+func queryMySqlLedgerMembers(sqlString string, args ...interface{}) []model.LedgerMemberEntity {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.LedgerMemberEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2LedgerMemberEntity(rows))
+	}
+	return targetEntityList
+}
+
+func queryMySqlLedgerInvitation(sqlString string, args ...interface{}) model.LedgerInvitationEntity {
+	targetEntityList := queryMySqlLedgerInvitations(sqlString, args...)
+	if len(targetEntityList) == 0 {
+		return model.LedgerInvitationEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func queryMySqlLedgerInvitations(sqlString string, args ...interface{}) []model.LedgerInvitationEntity {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.LedgerInvitationEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2LedgerInvitationEntity(rows))
+	}
+	return targetEntityList
+}
+
+// generatePlainId keeps a preset id and generates one otherwise
+func generatePlainId(objectId primitive.ObjectID) string {
+	if objectId == primitive.NilObjectID {
+		return primitive.NewObjectID().Hex()
+	}
+	return objectId.Hex()
+}
+
+func convertRow2LedgerEntity(rows *sql.Rows) model.LedgerEntity {
+	var id string
+	var name string
+	var createTime string
+	var modifyTime string
+
+	err := rows.Scan(&id, &name, &createTime, &modifyTime)
+	if err != nil {
+		util.Logger.Errorw("covert into entity failed", "error", err)
+	}
+
+	return model.LedgerEntity{
+		Id:         util.Convert2ObjectId(id),
+		Name:       name,
+		CreateTime: util.FormatDateTimeFromString(createTime),
+		ModifyTime: util.FormatDateTimeFromString(modifyTime),
+	}
+}
+
+func convertRow2LedgerMemberEntity(rows *sql.Rows) model.LedgerMemberEntity {
+	var id string
+	var ledgerId string
+	var userId string
+	var role string
+	var createTime string
+	var modifyTime string
+
+	err := rows.Scan(&id, &ledgerId, &userId, &role, &createTime, &modifyTime)
+	if err != nil {
+		util.Logger.Errorw("covert into entity failed", "error", err)
+	}
+
+	return model.LedgerMemberEntity{
+		Id:         util.Convert2ObjectId(id),
+		LedgerId:   util.Convert2ObjectId(ledgerId),
+		UserId:     util.Convert2ObjectId(userId),
+		Role:       role,
+		CreateTime: util.FormatDateTimeFromString(createTime),
+		ModifyTime: util.FormatDateTimeFromString(modifyTime),
+	}
+}
+
+func convertRow2LedgerInvitationEntity(rows *sql.Rows) model.LedgerInvitationEntity {
+	var id string
+	var ledgerId string
+	var role string
+	var tokenHash string
+	var invitedBy string
+	var expireTime string
+	var createTime string
+	var modifyTime string
+
+	err := rows.Scan(&id, &ledgerId, &role, &tokenHash, &invitedBy, &expireTime, &createTime, &modifyTime)
+	if err != nil {
+		util.Logger.Errorw("covert into entity failed", "error", err)
+	}
+
+	return model.LedgerInvitationEntity{
+		Id:         util.Convert2ObjectId(id),
+		LedgerId:   util.Convert2ObjectId(ledgerId),
+		Role:       role,
+		TokenHash:  tokenHash,
+		InvitedBy:  util.Convert2ObjectId(invitedBy),
+		ExpireTime: util.FormatDateTimeFromString(expireTime),
+		CreateTime: util.FormatDateTimeFromString(createTime),
+		ModifyTime: util.FormatDateTimeFromString(modifyTime),
+	}
+}
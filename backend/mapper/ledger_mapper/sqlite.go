@@ -0,0 +1,480 @@
+package ledger_mapper
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+)
+
+type LedgerSqliteMapper struct{}
+
+const (
+	sqliteLedgerColumns           = "ID, NAME, CREATE_TIME, MODIFY_TIME"
+	sqliteLedgerMemberColumns     = "ID, LEDGER_ID, USER_ID, ROLE, CREATE_TIME, MODIFY_TIME"
+	sqliteLedgerInvitationColumns = "ID, LEDGER_ID, ROLE, TOKEN_HASH, INVITED_BY, EXPIRE_TIME, CREATE_TIME, MODIFY_TIME"
+)
+
+func (LedgerSqliteMapper) GetLedgerByObjectId(plainId string) model.LedgerEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteLedgerColumns + " FROM ")
+	sqlString.WriteString(database.LedgerTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	rows, err := database.GetSqliteConnection().Query(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return model.LedgerEntity{}
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return model.LedgerEntity{}
+	}
+	return convertRow2LedgerEntity(rows)
+}
+
+func (LedgerSqliteMapper) InsertLedgerByEntity(newEntity model.LedgerEntity) string {
+	newEntity.CreateTime, newEntity.ModifyTime = fillOperatingTime(newEntity.CreateTime, newEntity.ModifyTime, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.LedgerTableName)
+	sqlString.WriteString(" (" + sqliteLedgerColumns + ") VALUES (?, ?, ?, ?) ")
+
+	newPlainId := generatePlainId(newEntity.Id)
+	return execSqliteInsert(sqlString.String(), newPlainId, newPlainId, newEntity.Name,
+		util.FormatDateTimeToString(newEntity.CreateTime), util.FormatDateTimeToString(newEntity.ModifyTime))
+}
+
+func (LedgerSqliteMapper) BulkInsertLedgers(entities []model.LedgerEntity) ([]string, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.LedgerTableName)
+	sqlString.WriteString(" (" + sqliteLedgerColumns + ") VALUES (?, ?, ?, ?) ")
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	rowList := make([][]interface{}, len(entities))
+	for i, entity := range entities {
+		entity.CreateTime, entity.ModifyTime = fillOperatingTime(entity.CreateTime, entity.ModifyTime, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		rowList[i] = []interface{}{ids[i], entity.Name,
+			util.FormatDateTimeToString(entity.CreateTime), util.FormatDateTimeToString(entity.ModifyTime)}
+	}
+
+	if err := execSqliteBulkInsert(sqlString.String(), rowList); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (LedgerSqliteMapper) GetAllLedgers(limit, offset int) []model.LedgerEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteLedgerColumns + " FROM ")
+	sqlString.WriteString(database.LedgerTableName)
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteLedgers(sqlString.String(), limit, offset)
+	}
+	return querySqliteLedgers(sqlString.String())
+}
+
+func (LedgerSqliteMapper) CountAllLedgers() int64 {
+	return countSqliteRows(database.LedgerTableName)
+}
+
+func (LedgerSqliteMapper) DeleteLedgerByObjectId(plainId string) model.LedgerEntity {
+	targetEntity := INSTANCE.GetLedgerByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("ledger is not exist")
+		return model.LedgerEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.LedgerTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	if !execSqliteUpdate(sqlString.String(), plainId) {
+		return model.LedgerEntity{}
+	}
+	return targetEntity
+}
+
+func (LedgerSqliteMapper) GetLedgerMember(ledgerPlainId, userPlainId string) model.LedgerMemberEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteLedgerMemberColumns + " FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" WHERE LEDGER_ID = ? AND USER_ID = ? ")
+
+	targetEntityList := querySqliteLedgerMembers(sqlString.String(), ledgerPlainId, userPlainId)
+	if len(targetEntityList) == 0 {
+		return model.LedgerMemberEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (LedgerSqliteMapper) GetLedgerMembersByLedgerId(ledgerPlainId string) []model.LedgerMemberEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteLedgerMemberColumns + " FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" WHERE LEDGER_ID = ? ")
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	return querySqliteLedgerMembers(sqlString.String(), ledgerPlainId)
+}
+
+func (LedgerSqliteMapper) GetLedgerMembersByUserId(userPlainId string) []model.LedgerMemberEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteLedgerMemberColumns + " FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" WHERE USER_ID = ? ")
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	return querySqliteLedgerMembers(sqlString.String(), userPlainId)
+}
+
+func (LedgerSqliteMapper) InsertLedgerMemberByEntity(newEntity model.LedgerMemberEntity) string {
+	newEntity.CreateTime, newEntity.ModifyTime = fillOperatingTime(newEntity.CreateTime, newEntity.ModifyTime, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" (" + sqliteLedgerMemberColumns + ") VALUES (?, ?, ?, ?, ?, ?) ")
+
+	newPlainId := generatePlainId(newEntity.Id)
+	return execSqliteInsert(sqlString.String(), newPlainId, newPlainId, newEntity.LedgerId.Hex(), newEntity.UserId.Hex(),
+		newEntity.Role, util.FormatDateTimeToString(newEntity.CreateTime), util.FormatDateTimeToString(newEntity.ModifyTime))
+}
+
+func (LedgerSqliteMapper) BulkInsertLedgerMembers(entities []model.LedgerMemberEntity) ([]string, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" (" + sqliteLedgerMemberColumns + ") VALUES (?, ?, ?, ?, ?, ?) ")
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	rowList := make([][]interface{}, len(entities))
+	for i, entity := range entities {
+		entity.CreateTime, entity.ModifyTime = fillOperatingTime(entity.CreateTime, entity.ModifyTime, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		rowList[i] = []interface{}{ids[i], entity.LedgerId.Hex(), entity.UserId.Hex(), entity.Role,
+			util.FormatDateTimeToString(entity.CreateTime), util.FormatDateTimeToString(entity.ModifyTime)}
+	}
+
+	if err := execSqliteBulkInsert(sqlString.String(), rowList); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (LedgerSqliteMapper) GetAllLedgerMembers(limit, offset int) []model.LedgerMemberEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteLedgerMemberColumns + " FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteLedgerMembers(sqlString.String(), limit, offset)
+	}
+	return querySqliteLedgerMembers(sqlString.String())
+}
+
+func (LedgerSqliteMapper) CountAllLedgerMembers() int64 {
+	return countSqliteRows(database.LedgerMemberTableName)
+}
+
+func (LedgerSqliteMapper) UpdateLedgerMemberRole(plainId, role string) model.LedgerMemberEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteLedgerMemberColumns + " FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := querySqliteLedgerMembers(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		util.Logger.Infoln("ledger member is not exist")
+		return model.LedgerMemberEntity{}
+	}
+	targetEntity := targetEntityList[0]
+	targetEntity.Role = role
+	targetEntity.ModifyTime = time.Now()
+
+	sqlString.Reset()
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" SET ROLE = ?, MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	if !execSqliteUpdate(sqlString.String(), targetEntity.Role, util.FormatDateTimeToString(targetEntity.ModifyTime), plainId) {
+		return model.LedgerMemberEntity{}
+	}
+	return targetEntity
+}
+
+func (LedgerSqliteMapper) DeleteLedgerMemberByObjectId(plainId string) model.LedgerMemberEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteLedgerMemberColumns + " FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := querySqliteLedgerMembers(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		util.Logger.Infoln("ledger member is not exist")
+		return model.LedgerMemberEntity{}
+	}
+
+	sqlString.Reset()
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.LedgerMemberTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	if !execSqliteUpdate(sqlString.String(), plainId) {
+		return model.LedgerMemberEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (LedgerSqliteMapper) GetLedgerInvitationByHash(tokenHash string) model.LedgerInvitationEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteLedgerInvitationColumns + " FROM ")
+	sqlString.WriteString(database.LedgerInvitationTableName)
+	sqlString.WriteString(" WHERE TOKEN_HASH = ? ")
+
+	return querySqliteLedgerInvitation(sqlString.String(), tokenHash)
+}
+
+func (LedgerSqliteMapper) InsertLedgerInvitationByEntity(newEntity model.LedgerInvitationEntity) string {
+	newEntity.CreateTime, newEntity.ModifyTime = fillOperatingTime(newEntity.CreateTime, newEntity.ModifyTime, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.LedgerInvitationTableName)
+	sqlString.WriteString(" (" + sqliteLedgerInvitationColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?) ")
+
+	newPlainId := generatePlainId(newEntity.Id)
+	return execSqliteInsert(sqlString.String(), newPlainId, newPlainId, newEntity.LedgerId.Hex(), newEntity.Role,
+		newEntity.TokenHash, newEntity.InvitedBy.Hex(), util.FormatDateTimeToString(newEntity.ExpireTime),
+		util.FormatDateTimeToString(newEntity.CreateTime), util.FormatDateTimeToString(newEntity.ModifyTime))
+}
+
+func (LedgerSqliteMapper) BulkInsertLedgerInvitations(entities []model.LedgerInvitationEntity) ([]string, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.LedgerInvitationTableName)
+	sqlString.WriteString(" (" + sqliteLedgerInvitationColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?) ")
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	rowList := make([][]interface{}, len(entities))
+	for i, entity := range entities {
+		entity.CreateTime, entity.ModifyTime = fillOperatingTime(entity.CreateTime, entity.ModifyTime, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		rowList[i] = []interface{}{ids[i], entity.LedgerId.Hex(), entity.Role, entity.TokenHash, entity.InvitedBy.Hex(),
+			util.FormatDateTimeToString(entity.ExpireTime),
+			util.FormatDateTimeToString(entity.CreateTime), util.FormatDateTimeToString(entity.ModifyTime)}
+	}
+
+	if err := execSqliteBulkInsert(sqlString.String(), rowList); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (LedgerSqliteMapper) GetAllLedgerInvitations(limit, offset int) []model.LedgerInvitationEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteLedgerInvitationColumns + " FROM ")
+	sqlString.WriteString(database.LedgerInvitationTableName)
+	sqlString.WriteString(" ORDER BY CREATE_TIME ASC, ID ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteLedgerInvitations(sqlString.String(), limit, offset)
+	}
+	return querySqliteLedgerInvitations(sqlString.String())
+}
+
+func (LedgerSqliteMapper) CountAllLedgerInvitations() int64 {
+	return countSqliteRows(database.LedgerInvitationTableName)
+}
+
+func (LedgerSqliteMapper) DeleteLedgerInvitationByObjectId(plainId string) model.LedgerInvitationEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteLedgerInvitationColumns + " FROM ")
+	sqlString.WriteString(database.LedgerInvitationTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntity := querySqliteLedgerInvitation(sqlString.String(), plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("ledger invitation is not exist")
+		return model.LedgerInvitationEntity{}
+	}
+
+	sqlString.Reset()
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.LedgerInvitationTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	// Only the caller whose delete went through gets the invitation, so a token is used once
+	if !execSqliteUpdate(sqlString.String(), plainId) {
+		return model.LedgerInvitationEntity{}
+	}
+	return targetEntity
+}
+
+func (LedgerSqliteMapper) DeleteAllLedgers() (int64, error) {
+	var deletedCount int64
+	for _, tableName := range []string{database.LedgerInvitationTableName, database.LedgerMemberTableName, database.LedgerTableName} {
+		result, err := database.GetSqliteConnection().Exec("DELETE FROM " + tableName)
+		if err != nil {
+			util.Logger.Errorw("delete all ledgers failed", "table", tableName, "error", err)
+			return 0, err
+		}
+		deletedCount, err = result.RowsAffected()
+		if err != nil {
+			util.Logger.Errorw("delete all ledgers failed", "table", tableName, "error", err)
+			return 0, err
+		}
+	}
+
+	util.Logger.Infow("all ledgers deleted", "count", deletedCount)
+	return deletedCount, nil
+}
+
+// execSqliteInsert runs the insert and returns the new id, blank when nothing was inserted
+func execSqliteInsert(sqlString, newPlainId string, args ...interface{}) string {
+	result, err := database.GetSqliteConnection().Exec(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+		return ""
+	}
+	return newPlainId
+}
+
+// execSqliteUpdate runs an update or delete of a single row and tells whether it went through
+func execSqliteUpdate(sqlString string, args ...interface{}) bool {
+	result, err := database.GetSqliteConnection().Exec(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return false
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+		return false
+	}
+	return true
+}
+
+// execSqliteBulkInsert runs the insert once per row, one transaction keeps the batch all-or-nothing
+func execSqliteBulkInsert(sqlString string, rowList [][]interface{}) error {
+	if len(rowList) == 0 {
+		return nil
+	}
+
+	transaction, err := database.GetSqliteConnection().Begin()
+	if err != nil {
+		util.Logger.Errorw("bulk insert begin failed", "error", err)
+		return err
+	}
+
+	statement, err := transaction.Prepare(sqlString)
+	if err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert prepare failed", "error", err)
+		return err
+	}
+	defer statement.Close()
+
+	for _, args := range rowList {
+		if _, err := statement.Exec(args...); err != nil {
+			transaction.Rollback()
+			util.Logger.Errorw("bulk insert failed", "error", err)
+			return err
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		util.Logger.Errorw("bulk insert commit failed", "error", err)
+		return err
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(rowList))
+	return nil
+}
+
+func countSqliteRows(tableName string) int64 {
+	var count int64
+	err := database.GetSqliteConnection().QueryRow("SELECT COUNT(1) FROM " + tableName).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count failed", "table", tableName, "error", err)
+		return 0
+	}
+	return count
+}
+
+func querySqliteLedgers(sqlString string, args ...interface{}) []model.LedgerEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.LedgerEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2LedgerEntity(rows))
+	}
+	return targetEntityList
+}
+
+func querySqliteLedgerMembers(sqlString string, args ...interface{}) []model.LedgerMemberEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.LedgerMemberEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2LedgerMemberEntity(rows))
+	}
+	return targetEntityList
+}
+
+func querySqliteLedgerInvitation(sqlString string, args ...interface{}) model.LedgerInvitationEntity {
+	targetEntityList := querySqliteLedgerInvitations(sqlString, args...)
+	if len(targetEntityList) == 0 {
+		return model.LedgerInvitationEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func querySqliteLedgerInvitations(sqlString string, args ...interface{}) []model.LedgerInvitationEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.LedgerInvitationEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2LedgerInvitationEntity(rows))
+	}
+	return targetEntityList
+}
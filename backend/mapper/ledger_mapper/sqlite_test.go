@@ -0,0 +1,156 @@
+package ledger_mapper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMain(m *testing.M) {
+	tempDir, err := os.MkdirTemp("", "cashlens-sqlite-")
+	if err != nil {
+		panic(err)
+	}
+	util.SetConfigByKey("db.sqlite.path", filepath.Join(tempDir, "ledger_test.db"))
+	INSTANCE = LedgerSqliteMapper{}
+
+	code := m.Run()
+	os.RemoveAll(tempDir)
+	os.Exit(code)
+}
+
+func TestSqliteLedgerMembers(t *testing.T) {
+	mapper := LedgerSqliteMapper{}
+	if _, err := mapper.DeleteAllLedgers(); err != nil {
+		t.Fatalf("DeleteAllLedgers() error = %v", err)
+	}
+
+	ledgerPlainId := mapper.InsertLedgerByEntity(model.LedgerEntity{Name: "Household"})
+	if ledger := mapper.GetLedgerByObjectId(ledgerPlainId); ledger.Name != "Household" || ledger.CreateTime.IsZero() {
+		t.Fatalf("GetLedgerByObjectId() = %+v", ledger)
+	}
+	ledgerId := util.Convert2ObjectId(ledgerPlainId)
+
+	aliceId, bobId := primitive.NewObjectID(), primitive.NewObjectID()
+	createTime := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	bobMemberId := mapper.InsertLedgerMemberByEntity(model.LedgerMemberEntity{LedgerId: ledgerId, UserId: bobId,
+		Role: model.LedgerRoleViewer, CreateTime: createTime.Add(time.Hour)})
+	aliceMemberId := mapper.InsertLedgerMemberByEntity(model.LedgerMemberEntity{LedgerId: ledgerId, UserId: aliceId,
+		Role: model.LedgerRoleOwner, CreateTime: createTime})
+	mapper.InsertLedgerMemberByEntity(model.LedgerMemberEntity{LedgerId: primitive.NewObjectID(), UserId: aliceId, Role: model.LedgerRoleEditor})
+	if duplicateId := mapper.InsertLedgerMemberByEntity(model.LedgerMemberEntity{LedgerId: ledgerId, UserId: bobId, Role: model.LedgerRoleEditor}); duplicateId != "" {
+		t.Errorf("InsertLedgerMemberByEntity() of a member again = %q, want no id", duplicateId)
+	}
+
+	if member := mapper.GetLedgerMember(ledgerPlainId, bobId.Hex()); member.Id.Hex() != bobMemberId || member.Role != model.LedgerRoleViewer {
+		t.Errorf("GetLedgerMember() = %+v, want bob as viewer", member)
+	}
+	memberList := mapper.GetLedgerMembersByLedgerId(ledgerPlainId)
+	if len(memberList) != 2 || memberList[0].Id.Hex() != aliceMemberId || memberList[1].Id.Hex() != bobMemberId {
+		t.Errorf("GetLedgerMembersByLedgerId() = %+v, want alice then bob", memberList)
+	}
+	if memberList := mapper.GetLedgerMembersByUserId(aliceId.Hex()); len(memberList) != 2 {
+		t.Errorf("GetLedgerMembersByUserId() returned %d memberships, want 2", len(memberList))
+	}
+
+	if updated := mapper.UpdateLedgerMemberRole(bobMemberId, model.LedgerRoleEditor); updated.Role != model.LedgerRoleEditor {
+		t.Errorf("UpdateLedgerMemberRole() = %+v", updated)
+	}
+	if member := mapper.GetLedgerMember(ledgerPlainId, bobId.Hex()); member.Role != model.LedgerRoleEditor {
+		t.Errorf("GetLedgerMember() role = %s after the update, want EDITOR", member.Role)
+	}
+	if deleted := mapper.DeleteLedgerMemberByObjectId(bobMemberId); deleted.UserId != bobId {
+		t.Errorf("DeleteLedgerMemberByObjectId() = %+v", deleted)
+	}
+	if member := mapper.GetLedgerMember(ledgerPlainId, bobId.Hex()); !member.IsEmpty() {
+		t.Errorf("GetLedgerMember() of a removed member = %+v, want empty", member)
+	}
+}
+
+func TestSqliteLedgerInvitations(t *testing.T) {
+	mapper := LedgerSqliteMapper{}
+	if _, err := mapper.DeleteAllLedgers(); err != nil {
+		t.Fatalf("DeleteAllLedgers() error = %v", err)
+	}
+
+	ledgerId, aliceId := primitive.NewObjectID(), primitive.NewObjectID()
+	expireTime := time.Date(2024, 6, 8, 8, 0, 0, 0, time.UTC)
+	invitationId := mapper.InsertLedgerInvitationByEntity(model.LedgerInvitationEntity{LedgerId: ledgerId,
+		Role: model.LedgerRoleEditor, TokenHash: "hash-1", InvitedBy: aliceId, ExpireTime: expireTime})
+	if duplicateId := mapper.InsertLedgerInvitationByEntity(model.LedgerInvitationEntity{LedgerId: ledgerId, TokenHash: "hash-1"}); duplicateId != "" {
+		t.Errorf("InsertLedgerInvitationByEntity() with a taken hash = %q, want no id", duplicateId)
+	}
+
+	invitation := mapper.GetLedgerInvitationByHash("hash-1")
+	if invitation.Id.Hex() != invitationId || invitation.LedgerId != ledgerId || invitation.InvitedBy != aliceId ||
+		invitation.Role != model.LedgerRoleEditor || !invitation.ExpireTime.Equal(expireTime) {
+		t.Errorf("GetLedgerInvitationByHash() = %+v", invitation)
+	}
+	if deleted := mapper.DeleteLedgerInvitationByObjectId(invitationId); deleted.Id.Hex() != invitationId {
+		t.Errorf("DeleteLedgerInvitationByObjectId() = %+v", deleted)
+	}
+	if deleted := mapper.DeleteLedgerInvitationByObjectId(invitationId); !deleted.IsEmpty() {
+		t.Errorf("DeleteLedgerInvitationByObjectId() of a used invitation = %+v, want empty", deleted)
+	}
+}
+
+func TestSqliteLedgerBulkInsert(t *testing.T) {
+	mapper := LedgerSqliteMapper{}
+	if _, err := mapper.DeleteAllLedgers(); err != nil {
+		t.Fatalf("DeleteAllLedgers() error = %v", err)
+	}
+
+	createTime := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	householdId, tripId := primitive.NewObjectID(), primitive.NewObjectID()
+	ledgerIds, err := mapper.BulkInsertLedgers([]model.LedgerEntity{
+		{Id: tripId, Name: "Trip", CreateTime: createTime.Add(time.Hour)},
+		{Id: householdId, Name: "Household", CreateTime: createTime},
+	})
+	if err != nil || len(ledgerIds) != 2 || ledgerIds[0] != tripId.Hex() {
+		t.Fatalf("BulkInsertLedgers() = %v, %v, want the trip's id kept", ledgerIds, err)
+	}
+	if ledgerList := mapper.GetAllLedgers(1, 1); len(ledgerList) != 1 || ledgerList[0].Id != tripId {
+		t.Errorf("GetAllLedgers(1, 1) = %+v, want the trip", ledgerList)
+	}
+
+	aliceId := primitive.NewObjectID()
+	memberIds, err := mapper.BulkInsertLedgerMembers([]model.LedgerMemberEntity{
+		{LedgerId: householdId, UserId: aliceId, Role: model.LedgerRoleOwner},
+		{LedgerId: tripId, UserId: aliceId, Role: model.LedgerRoleEditor},
+	})
+	if err != nil || len(memberIds) != 2 {
+		t.Fatalf("BulkInsertLedgerMembers() = %v, %v", memberIds, err)
+	}
+	if _, err := mapper.BulkInsertLedgerMembers([]model.LedgerMemberEntity{
+		{LedgerId: tripId, UserId: primitive.NewObjectID(), Role: model.LedgerRoleViewer},
+		{LedgerId: householdId, UserId: aliceId, Role: model.LedgerRoleViewer},
+	}); err == nil {
+		t.Errorf("BulkInsertLedgerMembers() of a member again should fail")
+	}
+	if count := mapper.CountAllLedgerMembers(); count != 2 {
+		t.Errorf("CountAllLedgerMembers() = %d after a failed batch, want 2", count)
+	}
+
+	expireTime := time.Date(2024, 6, 8, 8, 0, 0, 0, time.UTC)
+	if _, err := mapper.BulkInsertLedgerInvitations([]model.LedgerInvitationEntity{
+		{LedgerId: householdId, Role: model.LedgerRoleViewer, TokenHash: "hash-1", InvitedBy: aliceId, ExpireTime: expireTime},
+	}); err != nil {
+		t.Fatalf("BulkInsertLedgerInvitations() error = %v", err)
+	}
+	if invitationList := mapper.GetAllLedgerInvitations(0, 0); len(invitationList) != 1 ||
+		invitationList[0].TokenHash != "hash-1" || !invitationList[0].ExpireTime.Equal(expireTime) {
+		t.Errorf("GetAllLedgerInvitations() = %+v", invitationList)
+	}
+
+	if deleted := mapper.DeleteLedgerByObjectId(tripId.Hex()); deleted.Name != "Trip" {
+		t.Errorf("DeleteLedgerByObjectId() = %+v, want the trip", deleted)
+	}
+	if count := mapper.CountAllLedgers(); count != 1 {
+		t.Errorf("CountAllLedgers() = %d after delete, want 1", count)
+	}
+}
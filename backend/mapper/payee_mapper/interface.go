@@ -0,0 +1,54 @@
+package payee_mapper
+
+import (
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+)
+
+var INSTANCE PayeeMapper
+
+// PayeeMapper stores payees. Every owner has payees of their own, a blank owner stands
+// for the payees no user owns; lookups by id and the GetAll/DeleteAll methods span all owners.
+type PayeeMapper interface {
+	GetPayeeByObjectId(plainId string) model.PayeeEntity
+	GetPayeeByName(ownerPlainId, payeeName string) model.PayeeEntity
+	GetPayeesByOwnerId(ownerPlainId string, limit, offset int) []model.PayeeEntity
+	CountPayeesByOwnerId(ownerPlainId string) int64
+	InsertPayeeByEntity(newEntity model.PayeeEntity) string
+	BulkInsertPayees(entities []model.PayeeEntity) ([]string, error)
+	UpdatePayeeByEntity(plainId string, updatedEntity model.PayeeEntity) model.PayeeEntity
+	GetAllPayees(limit, offset int) []model.PayeeEntity
+	CountAllPayees() int64
+	DeletePayeeByObjectId(plainId string) model.PayeeEntity
+	DeleteAllPayees() (int64, error)
+	AssignUnownedPayees(ownerPlainId string) (int64, error)
+}
+
+func init() {
+	switch util.GetConfigByKey("db.type") {
+	case "mongodb":
+		INSTANCE = PayeeMongoDbMapper{}
+	case "mysql":
+		INSTANCE = PayeeMySqlMapper{}
+	case "sqlite":
+		INSTANCE = PayeeSqliteMapper{}
+	case "memory":
+		INSTANCE = NewPayeeMemoryMapper()
+	default:
+		panic("database type not supported")
+	}
+}
+
+// fillOperatingTime keeps create/modify times carried over from a backup,
+// and stamps the operating time on brand-new records.
+func fillOperatingTime(entity model.PayeeEntity, operatingTime time.Time) model.PayeeEntity {
+	if entity.CreateTime.IsZero() {
+		entity.CreateTime = operatingTime
+	}
+	if entity.ModifyTime.IsZero() {
+		entity.ModifyTime = operatingTime
+	}
+	return entity
+}
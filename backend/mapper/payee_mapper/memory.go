@@ -0,0 +1,214 @@
+package payee_mapper
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PayeeMemoryMapper keeps payees in process memory, nothing is persisted.
+// It is safe for concurrent use; copies of the mapper share the same data.
+type PayeeMemoryMapper struct {
+	store *payeeMemoryStore
+}
+
+type payeeMemoryStore struct {
+	mutex   sync.RWMutex
+	records map[primitive.ObjectID]model.PayeeEntity
+}
+
+// NewPayeeMemoryMapper returns an empty in-memory mapper
+func NewPayeeMemoryMapper() PayeeMemoryMapper {
+	return PayeeMemoryMapper{
+		store: &payeeMemoryStore{
+			records: make(map[primitive.ObjectID]model.PayeeEntity),
+		},
+	}
+}
+
+func (mapper PayeeMemoryMapper) GetPayeeByObjectId(plainId string) model.PayeeEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("payee's id is not acceptable")
+		return model.PayeeEntity{}
+	}
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return mapper.store.records[objectId]
+}
+
+func (mapper PayeeMemoryMapper) GetPayeeByName(ownerPlainId, payeeName string) model.PayeeEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.PayeeEntity) bool {
+		return entity.OwnerId == ownerId && entity.Name == payeeName
+	})
+	if len(targetEntityList) == 0 {
+		return model.PayeeEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (mapper PayeeMemoryMapper) InsertPayeeByEntity(newEntity model.PayeeEntity) string {
+	newPlainIdList, err := mapper.BulkInsertPayees([]model.PayeeEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+	return newPlainIdList[0]
+}
+
+func (mapper PayeeMemoryMapper) BulkInsertPayees(entities []model.PayeeEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	operatingTime := time.Now()
+	newEntityList := make([]model.PayeeEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.records[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate payee id: " + entity.Id.Hex())
+		}
+		batchIds[entity.Id] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.records[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper PayeeMemoryMapper) UpdatePayeeByEntity(plainId string, updatedEntity model.PayeeEntity) model.PayeeEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("payee is not exist")
+		return model.PayeeEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+	mapper.store.records[objectId] = updatedEntity
+	return updatedEntity
+}
+
+func (mapper PayeeMemoryMapper) GetAllPayees(limit, offset int) []model.PayeeEntity {
+	targetEntityList := mapper.filter(func(model.PayeeEntity) bool { return true })
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper PayeeMemoryMapper) GetPayeesByOwnerId(ownerPlainId string, limit, offset int) []model.PayeeEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.PayeeEntity) bool {
+		return entity.OwnerId == ownerId
+	})
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper PayeeMemoryMapper) CountAllPayees() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.records))
+}
+
+func (mapper PayeeMemoryMapper) CountPayeesByOwnerId(ownerPlainId string) int64 {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	return int64(len(mapper.filter(func(entity model.PayeeEntity) bool {
+		return entity.OwnerId == ownerId
+	})))
+}
+
+func (mapper PayeeMemoryMapper) DeletePayeeByObjectId(plainId string) model.PayeeEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("payee is not exist")
+		return model.PayeeEntity{}
+	}
+
+	delete(mapper.store.records, objectId)
+	return targetEntity
+}
+
+func (mapper PayeeMemoryMapper) DeleteAllPayees() (int64, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	deletedCount := int64(len(mapper.store.records))
+	mapper.store.records = make(map[primitive.ObjectID]model.PayeeEntity)
+	return deletedCount, nil
+}
+
+func (mapper PayeeMemoryMapper) AssignUnownedPayees(ownerPlainId string) (int64, error) {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	var assignedCount int64
+	for id, entity := range mapper.store.records {
+		if entity.OwnerId == primitive.NilObjectID {
+			entity.OwnerId = ownerId
+			mapper.store.records[id] = entity
+			assignedCount++
+		}
+	}
+	return assignedCount, nil
+}
+
+// paginate cuts one page out of the ordered payees, a zero limit keeps all of them
+func paginate(targetEntityList []model.PayeeEntity, limit, offset int) []model.PayeeEntity {
+	if offset >= len(targetEntityList) {
+		return []model.PayeeEntity{}
+	}
+	targetEntityList = targetEntityList[offset:]
+	if limit > 0 && limit < len(targetEntityList) {
+		targetEntityList = targetEntityList[:limit]
+	}
+	return targetEntityList
+}
+
+// filter returns the matching payees ordered by name, like the database mappers
+func (mapper PayeeMemoryMapper) filter(isMatched func(entity model.PayeeEntity) bool) []model.PayeeEntity {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	var targetEntityList []model.PayeeEntity
+	for _, entity := range mapper.store.records {
+		if isMatched(entity) {
+			targetEntityList = append(targetEntityList, entity)
+		}
+	}
+
+	sort.Slice(targetEntityList, func(i, j int) bool {
+		if targetEntityList[i].Name != targetEntityList[j].Name {
+			return targetEntityList[i].Name < targetEntityList[j].Name
+		}
+		return targetEntityList[i].Id.Hex() < targetEntityList[j].Id.Hex()
+	})
+	return targetEntityList
+}
@@ -0,0 +1,258 @@
+package payee_mapper
+
+import (
+	"context"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PayeeMongoDbMapper struct{}
+
+func (PayeeMongoDbMapper) GetPayeeByObjectId(plainId string) model.PayeeEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("payee's id is not acceptable")
+		return model.PayeeEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.PayeeTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2PayeeEntity(database.GetOneInMongoDB(filter))
+}
+
+func (PayeeMongoDbMapper) GetPayeeByName(ownerPlainId, payeeName string) model.PayeeEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+		primitive.E{Key: "name", Value: payeeName},
+	}
+
+	database.OpenMongoDbConnection(database.PayeeTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2PayeeEntity(database.GetOneInMongoDB(filter))
+}
+
+func (PayeeMongoDbMapper) InsertPayeeByEntity(newEntity model.PayeeEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	database.OpenMongoDbConnection(database.PayeeTableName)
+	defer database.CloseMongoDbConnection()
+
+	newPayeeId := database.InsertOneInMongoDB(convertPayeeEntity2BsonD(newEntity))
+	return newPayeeId.Hex()
+}
+
+func (PayeeMongoDbMapper) BulkInsertPayees(entities []model.PayeeEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		documents[i] = convertPayeeEntity2BsonD(fillOperatingTime(entity, operatingTime))
+	}
+
+	collection := database.GetMongoCollection(database.PayeeTableName)
+	result, err := collection.InsertMany(context.TODO(), documents)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	ids := make([]string, len(result.InsertedIDs))
+	for i, id := range result.InsertedIDs {
+		ids[i] = id.(primitive.ObjectID).Hex()
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (PayeeMongoDbMapper) UpdatePayeeByEntity(plainId string, updatedEntity model.PayeeEntity) model.PayeeEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("payee's id is not acceptable")
+		return model.PayeeEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.PayeeTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2PayeeEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("payee is not exist")
+		return model.PayeeEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	rowsAffected := database.UpdateManyInMongoDB(filter, convertPayeeEntity2BsonD(updatedEntity))
+	if rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "rows_affected", rowsAffected)
+		return model.PayeeEntity{}
+	}
+	return updatedEntity
+}
+
+func (PayeeMongoDbMapper) GetAllPayees(limit, offset int) []model.PayeeEntity {
+	return findMongoPayeesPage(bson.D{}, limit, offset)
+}
+
+func (PayeeMongoDbMapper) GetPayeesByOwnerId(ownerPlainId string, limit, offset int) []model.PayeeEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+	return findMongoPayeesPage(filter, limit, offset)
+}
+
+func (PayeeMongoDbMapper) CountAllPayees() int64 {
+	database.OpenMongoDbConnection(database.PayeeTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(bson.D{})
+}
+
+func (PayeeMongoDbMapper) CountPayeesByOwnerId(ownerPlainId string) int64 {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+
+	database.OpenMongoDbConnection(database.PayeeTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+func (PayeeMongoDbMapper) DeletePayeeByObjectId(plainId string) model.PayeeEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("payee's id is not acceptable")
+		return model.PayeeEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.PayeeTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2PayeeEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("payee is not exist")
+		return model.PayeeEntity{}
+	}
+
+	rowsAffected := database.DeleteManyInMongoDB(filter)
+	if rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "rows_affected", rowsAffected)
+		return model.PayeeEntity{}
+	}
+	return targetEntity
+}
+
+func (PayeeMongoDbMapper) DeleteAllPayees() (int64, error) {
+	collection := database.GetMongoCollection(database.PayeeTableName)
+	result, err := collection.DeleteMany(context.TODO(), bson.D{})
+	if err != nil {
+		util.Logger.Errorw("delete all payees failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all payees deleted", "count", result.DeletedCount)
+	return result.DeletedCount, nil
+}
+
+func (PayeeMongoDbMapper) AssignUnownedPayees(ownerPlainId string) (int64, error) {
+	collection := database.GetMongoCollection(database.PayeeTableName)
+	result, err := collection.UpdateMany(context.TODO(),
+		bson.D{database.OwnerFilterInMongoDB("")},
+		bson.D{primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "owner_id", Value: util.ConvertOwner2ObjectId(ownerPlainId)},
+		}}})
+	if err != nil {
+		util.Logger.Errorw("assign unowned payees failed", "error", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// findMongoPayeesPage returns one page of the matching payees in listing order
+func findMongoPayeesPage(filter bson.D, limit, offset int) []model.PayeeEntity {
+	findOptions := database.GetFindOptions()
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		findOptions.SetSkip(int64(offset))
+	}
+	// Sort by name ascending
+	findOptions.SetSort(bson.D{primitive.E{Key: "name", Value: 1}})
+
+	return findMongoPayees(filter, findOptions)
+}
+
+func findMongoPayees(filter bson.D, findOptions *options.FindOptions) []model.PayeeEntity {
+	collection := database.GetMongoCollection(database.PayeeTableName)
+
+	ctx := context.TODO()
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		util.Logger.Errorw("query payees failed", "error", err)
+		return []model.PayeeEntity{}
+	}
+	defer cursor.Close(ctx)
+
+	var targetEntityList []model.PayeeEntity
+	for cursor.Next(ctx) {
+		var bsonM bson.M
+		if err := cursor.Decode(&bsonM); err != nil {
+			util.Logger.Errorw("decode failed", "error", err)
+			continue
+		}
+		targetEntityList = append(targetEntityList, convertBsonM2PayeeEntity(bsonM))
+	}
+	return targetEntityList
+}
+
+func convertPayeeEntity2BsonD(entity model.PayeeEntity) bson.D {
+	// 为空时自动生成新Id
+	if entity.Id == primitive.NilObjectID {
+		entity.Id = primitive.NewObjectID()
+	}
+
+	return bson.D{
+		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "owner_id", Value: entity.OwnerId},
+		primitive.E{Key: "name", Value: entity.Name},
+		primitive.E{Key: "rules", Value: entity.Rules},
+		primitive.E{Key: "create_time", Value: entity.CreateTime},
+		primitive.E{Key: "modify_time", Value: entity.ModifyTime},
+	}
+}
+
+func convertBsonM2PayeeEntity(bsonM bson.M) model.PayeeEntity {
+	var newEntity model.PayeeEntity
+	if err := database.DecodeBsonM(bsonM, &newEntity); err != nil {
+		panic(err)
+	}
+	return newEntity
+}
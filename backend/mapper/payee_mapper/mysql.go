@@ -0,0 +1,440 @@
+package payee_mapper
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PayeeMySqlMapper struct{}
+
+const mySqlPayeeColumns = "ID, OWNER_ID, NAME, CREATE_TIME, MODIFY_TIME"
+
+const mySqlPayeePlaceholders = "(?, ?, ?, ?, ?)"
+
+func (PayeeMySqlMapper) GetPayeeByObjectId(plainId string) model.PayeeEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlPayeeColumns + " FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := queryMySqlPayees(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.PayeeEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (PayeeMySqlMapper) GetPayeeByName(ownerPlainId, payeeName string) model.PayeeEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlPayeeColumns + " FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND NAME = ? ")
+
+	targetEntityList := queryMySqlPayees(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), payeeName)
+	if len(targetEntityList) == 0 {
+		return model.PayeeEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (PayeeMySqlMapper) InsertPayeeByEntity(newEntity model.PayeeEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" (" + mySqlPayeeColumns + ") VALUES " + mySqlPayeePlaceholders)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := connection.Exec(sqlString.String(), newPlainId, newEntity.OwnerId.Hex(), newEntity.Name, newEntity.CreateTime, newEntity.ModifyTime)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+
+	if err = insertPayeeRules(connection, []string{newPlainId}, []model.PayeeEntity{newEntity}); err != nil {
+		util.Logger.Errorw("insert rules failed", "error", err)
+	}
+	return newPlainId
+}
+
+func (PayeeMySqlMapper) BulkInsertPayees(entities []model.PayeeEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" (" + mySqlPayeeColumns + ") VALUES ")
+
+	ids := make([]string, len(entities))
+	values := make([]interface{}, 0, len(entities)*5)
+
+	for i, entity := range entities {
+		if i > 0 {
+			sqlString.WriteString(", ")
+		}
+		sqlString.WriteString(mySqlPayeePlaceholders)
+
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		values = append(values, ids[i], entity.OwnerId.Hex(), entity.Name, entity.CreateTime, entity.ModifyTime)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), values...)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != int64(len(entities)) {
+		util.Logger.Errorw("bulk insert incomplete", "error", err, "expected", len(entities), "actual", rowsAffected)
+	}
+
+	if err = insertPayeeRules(connection, ids, entities); err != nil {
+		util.Logger.Errorw("bulk insert rules failed", "error", err)
+		return nil, err
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (PayeeMySqlMapper) UpdatePayeeByEntity(plainId string, updatedEntity model.PayeeEntity) model.PayeeEntity {
+	targetEntity := INSTANCE.GetPayeeByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("payee is not exist")
+		return model.PayeeEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" SET NAME = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), updatedEntity.Name, updatedEntity.ModifyTime, plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.PayeeEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+
+	if err = replacePayeeRules(connection, plainId, updatedEntity.Rules); err != nil {
+		util.Logger.Errorw("update rules failed", "error", err)
+	}
+	return updatedEntity
+}
+
+func (PayeeMySqlMapper) GetAllPayees(limit, offset int) []model.PayeeEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlPayeeColumns + " FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlPayees(sqlString.String(), limit, offset)
+	}
+	return queryMySqlPayees(sqlString.String())
+}
+
+func (PayeeMySqlMapper) GetPayeesByOwnerId(ownerPlainId string, limit, offset int) []model.PayeeEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlPayeeColumns + " FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlPayees(sqlString.String(), ownerId, limit, offset)
+	}
+	return queryMySqlPayees(sqlString.String(), ownerId)
+}
+
+func (PayeeMySqlMapper) CountPayeesByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	err := connection.QueryRow(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count payees by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (PayeeMySqlMapper) CountAllPayees() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all payees failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (PayeeMySqlMapper) DeletePayeeByObjectId(plainId string) model.PayeeEntity {
+	targetEntity := INSTANCE.GetPayeeByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("payee is not exist")
+		return model.PayeeEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	if err := replacePayeeRules(connection, plainId, nil); err != nil {
+		util.Logger.Errorw("delete rules failed", "error", err)
+		return model.PayeeEntity{}
+	}
+
+	result, err := connection.Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.PayeeEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (PayeeMySqlMapper) DeleteAllPayees() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	if _, err := connection.Exec("DELETE FROM " + database.PayeeRuleTableName); err != nil {
+		util.Logger.Errorw("delete all payee rules failed", "error", err)
+		return 0, err
+	}
+
+	result, err := connection.Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all payees failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all payees failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all payees deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (PayeeMySqlMapper) AssignUnownedPayees(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned payees failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func queryMySqlPayees(sqlString string, args ...interface{}) []model.PayeeEntity {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+
+	var targetEntityList []model.PayeeEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2PayeeEntity(rows))
+	}
+	rows.Close()
+	return attachPayeeRules(connection, targetEntityList)
+}
+
+// generatePlainId keeps a preset id (e.g. restored from backup) and generates one otherwise
+func generatePlainId(objectId primitive.ObjectID) string {
+	if objectId == primitive.NilObjectID {
+		return primitive.NewObjectID().Hex()
+	}
+	return objectId.Hex()
+}
+
+// sqlExecutor is satisfied by both a connection and a transaction
+type sqlExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// sqlQueryer is satisfied by both a connection and a transaction
+type sqlQueryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// attachPayeeRules loads the rules of the listed payees in their stored order
+func attachPayeeRules(queryer sqlQueryer, entities []model.PayeeEntity) []model.PayeeEntity {
+	if len(entities) == 0 {
+		return entities
+	}
+
+	entityIndexMap := make(map[string]int, len(entities))
+	plainIdList := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		entityIndexMap[entity.Id.Hex()] = i
+		plainIdList[i] = entity.Id.Hex()
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT PAYEE_ID, MATCH_TYPE, PATTERN FROM ")
+	sqlString.WriteString(database.PayeeRuleTableName)
+	sqlString.WriteString(" WHERE PAYEE_ID IN (")
+	sqlString.WriteString(strings.TrimSuffix(strings.Repeat("?, ", len(plainIdList)), ", "))
+	sqlString.WriteString(") ORDER BY PAYEE_ID, RULE_NO ")
+
+	rows, err := queryer.Query(sqlString.String(), plainIdList...)
+	if err != nil {
+		util.Logger.Errorw("query rules failed", "error", err)
+		return entities
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var payeeId string
+		var rule model.PayeeRule
+		if err = rows.Scan(&payeeId, &rule.MatchType, &rule.Pattern); err != nil {
+			util.Logger.Errorw("parse rule failed", "error", err)
+			continue
+		}
+		entityIndex := entityIndexMap[payeeId]
+		entities[entityIndex].Rules = append(entities[entityIndex].Rules, rule)
+	}
+	return entities
+}
+
+// insertPayeeRules writes the rules of the entities in one statement, plainIdList is in entities order
+func insertPayeeRules(executor sqlExecutor, plainIdList []string, entities []model.PayeeEntity) error {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.PayeeRuleTableName)
+	sqlString.WriteString(" (PAYEE_ID, RULE_NO, MATCH_TYPE, PATTERN) VALUES ")
+
+	var values []interface{}
+	for i, entity := range entities {
+		for ruleNo, rule := range entity.Rules {
+			if len(values) > 0 {
+				sqlString.WriteString(", ")
+			}
+			sqlString.WriteString("(?, ?, ?, ?)")
+			values = append(values, plainIdList[i], ruleNo, rule.MatchType, rule.Pattern)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	_, err := executor.Exec(sqlString.String(), values...)
+	return err
+}
+
+// replacePayeeRules drops the stored rules of one payee and writes the given ones instead
+func replacePayeeRules(executor sqlExecutor, plainId string, rules []model.PayeeRule) error {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.PayeeRuleTableName)
+	sqlString.WriteString(" WHERE PAYEE_ID = ? ")
+
+	if _, err := executor.Exec(sqlString.String(), plainId); err != nil {
+		return err
+	}
+	return insertPayeeRules(executor, []string{plainId}, []model.PayeeEntity{{Rules: rules}})
+}
+
+func convertRow2PayeeEntity(rows *sql.Rows) model.PayeeEntity {
+	var id string
+	var ownerId string
+	var name string
+	var createTime string
+	var modifyTime string
+
+	err := rows.Scan(&id, &ownerId, &name, &createTime, &modifyTime)
+	if err != nil {
+		util.Logger.Errorw("covert into entity failed", "error", err)
+	}
+
+	return model.PayeeEntity{
+		Id:         util.Convert2ObjectId(id),
+		OwnerId:    util.Convert2ObjectId(ownerId),
+		Name:       name,
+		CreateTime: util.FormatDateTimeFromString(createTime),
+		ModifyTime: util.FormatDateTimeFromString(modifyTime),
+	}
+}
@@ -0,0 +1,288 @@
+package payee_mapper
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PayeeSqliteMapper struct{}
+
+const sqlitePayeeColumns = "ID, OWNER_ID, NAME, CREATE_TIME, MODIFY_TIME"
+
+func (PayeeSqliteMapper) GetPayeeByObjectId(plainId string) model.PayeeEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqlitePayeeColumns + " FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := querySqlitePayees(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.PayeeEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (PayeeSqliteMapper) GetPayeeByName(ownerPlainId, payeeName string) model.PayeeEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqlitePayeeColumns + " FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND NAME = ? ")
+
+	targetEntityList := querySqlitePayees(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), payeeName)
+	if len(targetEntityList) == 0 {
+		return model.PayeeEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (mapper PayeeSqliteMapper) InsertPayeeByEntity(newEntity model.PayeeEntity) string {
+	newPlainIdList, err := mapper.BulkInsertPayees([]model.PayeeEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+	return newPlainIdList[0]
+}
+
+func (PayeeSqliteMapper) BulkInsertPayees(entities []model.PayeeEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" (" + sqlitePayeeColumns + ") VALUES (?, ?, ?, ?, ?) ")
+
+	// One transaction keeps the batch and its rules all-or-nothing
+	transaction, err := database.GetSqliteConnection().Begin()
+	if err != nil {
+		util.Logger.Errorw("bulk insert begin failed", "error", err)
+		return nil, err
+	}
+
+	statement, err := transaction.Prepare(sqlString.String())
+	if err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert prepare failed", "error", err)
+		return nil, err
+	}
+	defer statement.Close()
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		if _, err := statement.Exec(ids[i], entity.OwnerId.Hex(), entity.Name,
+			util.FormatDateTimeToString(entity.CreateTime), util.FormatDateTimeToString(entity.ModifyTime)); err != nil {
+			transaction.Rollback()
+			util.Logger.Errorw("bulk insert failed", "error", err)
+			return nil, err
+		}
+	}
+
+	if err := insertPayeeRules(transaction, ids, entities); err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert rules failed", "error", err)
+		return nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		util.Logger.Errorw("bulk insert commit failed", "error", err)
+		return nil, err
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (PayeeSqliteMapper) UpdatePayeeByEntity(plainId string, updatedEntity model.PayeeEntity) model.PayeeEntity {
+	targetEntity := INSTANCE.GetPayeeByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("payee is not exist")
+		return model.PayeeEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" SET NAME = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetSqliteConnection()
+	result, err := connection.Exec(sqlString.String(),
+		updatedEntity.Name, util.FormatDateTimeToString(updatedEntity.ModifyTime), plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.PayeeEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+
+	if err = replacePayeeRules(connection, plainId, updatedEntity.Rules); err != nil {
+		util.Logger.Errorw("update rules failed", "error", err)
+	}
+	return updatedEntity
+}
+
+func (PayeeSqliteMapper) GetAllPayees(limit, offset int) []model.PayeeEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqlitePayeeColumns + " FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqlitePayees(sqlString.String(), limit, offset)
+	}
+	return querySqlitePayees(sqlString.String())
+}
+
+func (PayeeSqliteMapper) GetPayeesByOwnerId(ownerPlainId string, limit, offset int) []model.PayeeEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqlitePayeeColumns + " FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqlitePayees(sqlString.String(), ownerId, limit, offset)
+	}
+	return querySqlitePayees(sqlString.String(), ownerId)
+}
+
+func (PayeeSqliteMapper) CountPayeesByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	var count int64
+	err := database.GetSqliteConnection().QueryRow(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count payees by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (PayeeSqliteMapper) CountAllPayees() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all payees failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (PayeeSqliteMapper) DeletePayeeByObjectId(plainId string) model.PayeeEntity {
+	targetEntity := INSTANCE.GetPayeeByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("payee is not exist")
+		return model.PayeeEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetSqliteConnection()
+	if err := replacePayeeRules(connection, plainId, nil); err != nil {
+		util.Logger.Errorw("delete rules failed", "error", err)
+		return model.PayeeEntity{}
+	}
+
+	result, err := connection.Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.PayeeEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (PayeeSqliteMapper) DeleteAllPayees() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.PayeeTableName)
+
+	if _, err := database.GetSqliteConnection().Exec("DELETE FROM " + database.PayeeRuleTableName); err != nil {
+		util.Logger.Errorw("delete all payee rules failed", "error", err)
+		return 0, err
+	}
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all payees failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all payees failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all payees deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (PayeeSqliteMapper) AssignUnownedPayees(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.PayeeTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned payees failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func querySqlitePayees(sqlString string, args ...interface{}) []model.PayeeEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+
+	var targetEntityList []model.PayeeEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2PayeeEntity(rows))
+	}
+	// The single connection stays busy until the rows are closed, and the rules need it next
+	rows.Close()
+	return attachPayeeRules(database.GetSqliteConnection(), targetEntityList)
+}
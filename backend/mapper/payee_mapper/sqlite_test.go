@@ -0,0 +1,117 @@
+package payee_mapper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMain(m *testing.M) {
+	tempDir, err := os.MkdirTemp("", "cashlens-sqlite-")
+	if err != nil {
+		panic(err)
+	}
+	util.SetConfigByKey("db.sqlite.path", filepath.Join(tempDir, "payee_test.db"))
+	INSTANCE = PayeeSqliteMapper{}
+
+	code := m.Run()
+	os.RemoveAll(tempDir)
+	os.Exit(code)
+}
+
+func TestSqlitePayeeLifecycle(t *testing.T) {
+	mapper := PayeeSqliteMapper{}
+	if _, err := mapper.DeleteAllPayees(); err != nil {
+		t.Fatalf("DeleteAllPayees() error = %v", err)
+	}
+
+	starbucksRules := []model.PayeeRule{
+		{MatchType: model.PayeeRuleContains, Pattern: "starbucks"},
+		{MatchType: model.PayeeRuleRegex, Pattern: `^SBX\d+`},
+	}
+	starbucksId := mapper.InsertPayeeByEntity(model.PayeeEntity{Name: "Starbucks", Rules: starbucksRules})
+	otherIds, err := mapper.BulkInsertPayees([]model.PayeeEntity{
+		{Name: "Landlord"},
+		{Name: "Amazon", Rules: []model.PayeeRule{{MatchType: model.PayeeRuleContains, Pattern: "amzn"}}},
+	})
+	if err != nil || len(otherIds) != 2 {
+		t.Fatalf("BulkInsertPayees() = %v, %v", otherIds, err)
+	}
+
+	starbucks := mapper.GetPayeeByObjectId(starbucksId)
+	if starbucks.Name != "Starbucks" || !reflect.DeepEqual(starbucks.Rules, starbucksRules) {
+		t.Errorf("GetPayeeByObjectId() = %+v, want the rules in order", starbucks)
+	}
+	if landlord := mapper.GetPayeeByName("", "Landlord"); landlord.Id.Hex() != otherIds[0] || landlord.Rules != nil {
+		t.Errorf("GetPayeeByName() = %+v, want no rules", landlord)
+	}
+	if allList := mapper.GetAllPayees(0, 0); len(allList) != 3 || allList[0].Name != "Amazon" || len(allList[0].Rules) != 1 {
+		t.Errorf("GetAllPayees() = %+v, want all three by name with their rules", allList)
+	}
+	if count := mapper.CountAllPayees(); count != 3 {
+		t.Errorf("CountAllPayees() = %d, want 3", count)
+	}
+
+	starbucks.Rules = starbucksRules[:1]
+	mapper.UpdatePayeeByEntity(starbucksId, starbucks)
+	if updated := mapper.GetPayeeByObjectId(starbucksId); len(updated.Rules) != 1 {
+		t.Errorf("UpdatePayeeByEntity() did not replace the rules, got %+v", updated)
+	}
+
+	if deleted := mapper.DeletePayeeByObjectId(otherIds[1]); deleted.Name != "Amazon" {
+		t.Errorf("DeletePayeeByObjectId() = %+v", deleted)
+	}
+
+	deletedCount, err := mapper.DeleteAllPayees()
+	if err != nil || deletedCount != 2 {
+		t.Errorf("DeleteAllPayees() = %d, %v, want 2, nil", deletedCount, err)
+	}
+}
+
+func TestSqlitePayeeOwners(t *testing.T) {
+	mapper := PayeeSqliteMapper{}
+	if _, err := mapper.DeleteAllPayees(); err != nil {
+		t.Fatalf("DeleteAllPayees() error = %v", err)
+	}
+
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	unownedId := mapper.InsertPayeeByEntity(model.PayeeEntity{Name: "Bakery"})
+	bobId := mapper.InsertPayeeByEntity(model.PayeeEntity{OwnerId: bob, Name: "Bakery"})
+
+	// Owners look names up in their own payees only
+	if payee := mapper.GetPayeeByName(bob.Hex(), "Bakery"); payee.Id.Hex() != bobId {
+		t.Errorf("GetPayeeByName(bob) = %+v, want id %s", payee, bobId)
+	}
+	if payee := mapper.GetPayeeByName(alice.Hex(), "Bakery"); !payee.IsEmpty() {
+		t.Errorf("GetPayeeByName(alice) = %+v, want none", payee)
+	}
+
+	// An update keeps the owner
+	bobPayee := mapper.GetPayeeByObjectId(bobId)
+	bobPayee.OwnerId = primitive.NilObjectID
+	mapper.UpdatePayeeByEntity(bobId, bobPayee)
+	if owner := mapper.GetPayeeByObjectId(bobId).OwnerId; owner != bob {
+		t.Errorf("UpdatePayeeByEntity() changed the owner to %s", owner.Hex())
+	}
+
+	// Only the payees without owner are handed over
+	assignedCount, err := mapper.AssignUnownedPayees(alice.Hex())
+	if err != nil || assignedCount != 1 {
+		t.Fatalf("AssignUnownedPayees() = %d, %v, want 1, nil", assignedCount, err)
+	}
+	aliceList := mapper.GetPayeesByOwnerId(alice.Hex(), 0, 0)
+	if len(aliceList) != 1 || aliceList[0].Id.Hex() != unownedId {
+		t.Errorf("GetPayeesByOwnerId(alice) = %+v, want the unowned payee", aliceList)
+	}
+	if bobList := mapper.GetPayeesByOwnerId(bob.Hex(), 0, 0); len(bobList) != 1 || bobList[0].Id.Hex() != bobId {
+		t.Errorf("GetPayeesByOwnerId(bob) = %+v, want bob's payee", bobList)
+	}
+	if count := mapper.CountPayeesByOwnerId(bob.Hex()); count != 1 {
+		t.Errorf("CountPayeesByOwnerId(bob) = %d, want 1", count)
+	}
+}
@@ -0,0 +1,54 @@
+package recurring_rule_mapper
+
+import (
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+)
+
+var INSTANCE RecurringRuleMapper
+
+// RecurringRuleMapper stores recurring rules. Every owner has recurring rules of their own, a blank owner stands
+// for the recurring rules no user owns; lookups by id and the GetAll/DeleteAll methods span all owners.
+type RecurringRuleMapper interface {
+	GetRecurringRuleByObjectId(plainId string) model.RecurringRuleEntity
+	GetRecurringRuleByName(ownerPlainId, ruleName string) model.RecurringRuleEntity
+	GetRecurringRulesByOwnerId(ownerPlainId string, limit, offset int) []model.RecurringRuleEntity
+	CountRecurringRulesByOwnerId(ownerPlainId string) int64
+	InsertRecurringRuleByEntity(newEntity model.RecurringRuleEntity) string
+	BulkInsertRecurringRules(entities []model.RecurringRuleEntity) ([]string, error)
+	UpdateRecurringRuleByEntity(plainId string, updatedEntity model.RecurringRuleEntity) model.RecurringRuleEntity
+	GetAllRecurringRules(limit, offset int) []model.RecurringRuleEntity
+	CountAllRecurringRules() int64
+	DeleteRecurringRuleByObjectId(plainId string) model.RecurringRuleEntity
+	DeleteAllRecurringRules() (int64, error)
+	AssignUnownedRecurringRules(ownerPlainId string) (int64, error)
+}
+
+func init() {
+	switch util.GetConfigByKey("db.type") {
+	case "mongodb":
+		INSTANCE = RecurringRuleMongoDbMapper{}
+	case "mysql":
+		INSTANCE = RecurringRuleMySqlMapper{}
+	case "sqlite":
+		INSTANCE = RecurringRuleSqliteMapper{}
+	case "memory":
+		INSTANCE = NewRecurringRuleMemoryMapper()
+	default:
+		panic("database type not supported")
+	}
+}
+
+// fillOperatingTime keeps create/modify times carried over from a backup,
+// and stamps the operating time on brand-new records.
+func fillOperatingTime(entity model.RecurringRuleEntity, operatingTime time.Time) model.RecurringRuleEntity {
+	if entity.CreateTime.IsZero() {
+		entity.CreateTime = operatingTime
+	}
+	if entity.ModifyTime.IsZero() {
+		entity.ModifyTime = operatingTime
+	}
+	return entity
+}
@@ -0,0 +1,214 @@
+package recurring_rule_mapper
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RecurringRuleMemoryMapper keeps recurring rules in process memory, nothing is persisted.
+// It is safe for concurrent use; copies of the mapper share the same data.
+type RecurringRuleMemoryMapper struct {
+	store *recurringRuleMemoryStore
+}
+
+type recurringRuleMemoryStore struct {
+	mutex   sync.RWMutex
+	records map[primitive.ObjectID]model.RecurringRuleEntity
+}
+
+// NewRecurringRuleMemoryMapper returns an empty in-memory mapper
+func NewRecurringRuleMemoryMapper() RecurringRuleMemoryMapper {
+	return RecurringRuleMemoryMapper{
+		store: &recurringRuleMemoryStore{
+			records: make(map[primitive.ObjectID]model.RecurringRuleEntity),
+		},
+	}
+}
+
+func (mapper RecurringRuleMemoryMapper) GetRecurringRuleByObjectId(plainId string) model.RecurringRuleEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("recurring rule's id is not acceptable")
+		return model.RecurringRuleEntity{}
+	}
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return mapper.store.records[objectId]
+}
+
+func (mapper RecurringRuleMemoryMapper) GetRecurringRuleByName(ownerPlainId, ruleName string) model.RecurringRuleEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.RecurringRuleEntity) bool {
+		return entity.OwnerId == ownerId && entity.Name == ruleName
+	})
+	if len(targetEntityList) == 0 {
+		return model.RecurringRuleEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (mapper RecurringRuleMemoryMapper) InsertRecurringRuleByEntity(newEntity model.RecurringRuleEntity) string {
+	newPlainIdList, err := mapper.BulkInsertRecurringRules([]model.RecurringRuleEntity{newEntity})
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+	return newPlainIdList[0]
+}
+
+func (mapper RecurringRuleMemoryMapper) BulkInsertRecurringRules(entities []model.RecurringRuleEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	operatingTime := time.Now()
+	newEntityList := make([]model.RecurringRuleEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.records[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate recurring rule id: " + entity.Id.Hex())
+		}
+		batchIds[entity.Id] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.records[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper RecurringRuleMemoryMapper) UpdateRecurringRuleByEntity(plainId string, updatedEntity model.RecurringRuleEntity) model.RecurringRuleEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("recurring rule is not exist")
+		return model.RecurringRuleEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+	mapper.store.records[objectId] = updatedEntity
+	return updatedEntity
+}
+
+func (mapper RecurringRuleMemoryMapper) GetAllRecurringRules(limit, offset int) []model.RecurringRuleEntity {
+	targetEntityList := mapper.filter(func(model.RecurringRuleEntity) bool { return true })
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper RecurringRuleMemoryMapper) GetRecurringRulesByOwnerId(ownerPlainId string, limit, offset int) []model.RecurringRuleEntity {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	targetEntityList := mapper.filter(func(entity model.RecurringRuleEntity) bool {
+		return entity.OwnerId == ownerId
+	})
+	return paginate(targetEntityList, limit, offset)
+}
+
+func (mapper RecurringRuleMemoryMapper) CountAllRecurringRules() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.records))
+}
+
+func (mapper RecurringRuleMemoryMapper) CountRecurringRulesByOwnerId(ownerPlainId string) int64 {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	return int64(len(mapper.filter(func(entity model.RecurringRuleEntity) bool {
+		return entity.OwnerId == ownerId
+	})))
+}
+
+func (mapper RecurringRuleMemoryMapper) DeleteRecurringRuleByObjectId(plainId string) model.RecurringRuleEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("recurring rule is not exist")
+		return model.RecurringRuleEntity{}
+	}
+
+	delete(mapper.store.records, objectId)
+	return targetEntity
+}
+
+func (mapper RecurringRuleMemoryMapper) DeleteAllRecurringRules() (int64, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	deletedCount := int64(len(mapper.store.records))
+	mapper.store.records = make(map[primitive.ObjectID]model.RecurringRuleEntity)
+	return deletedCount, nil
+}
+
+func (mapper RecurringRuleMemoryMapper) AssignUnownedRecurringRules(ownerPlainId string) (int64, error) {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	var assignedCount int64
+	for id, entity := range mapper.store.records {
+		if entity.OwnerId == primitive.NilObjectID {
+			entity.OwnerId = ownerId
+			mapper.store.records[id] = entity
+			assignedCount++
+		}
+	}
+	return assignedCount, nil
+}
+
+// paginate cuts one page out of the ordered recurring rules, a zero limit keeps all of them
+func paginate(targetEntityList []model.RecurringRuleEntity, limit, offset int) []model.RecurringRuleEntity {
+	if offset >= len(targetEntityList) {
+		return []model.RecurringRuleEntity{}
+	}
+	targetEntityList = targetEntityList[offset:]
+	if limit > 0 && limit < len(targetEntityList) {
+		targetEntityList = targetEntityList[:limit]
+	}
+	return targetEntityList
+}
+
+// filter returns the matching rules ordered by name, like the database mappers
+func (mapper RecurringRuleMemoryMapper) filter(isMatched func(entity model.RecurringRuleEntity) bool) []model.RecurringRuleEntity {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	var targetEntityList []model.RecurringRuleEntity
+	for _, entity := range mapper.store.records {
+		if isMatched(entity) {
+			targetEntityList = append(targetEntityList, entity)
+		}
+	}
+
+	sort.Slice(targetEntityList, func(i, j int) bool {
+		if targetEntityList[i].Name != targetEntityList[j].Name {
+			return targetEntityList[i].Name < targetEntityList[j].Name
+		}
+		return targetEntityList[i].Id.Hex() < targetEntityList[j].Id.Hex()
+	})
+	return targetEntityList
+}
@@ -0,0 +1,270 @@
+package recurring_rule_mapper
+
+import (
+	"context"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RecurringRuleMongoDbMapper struct{}
+
+func (RecurringRuleMongoDbMapper) GetRecurringRuleByObjectId(plainId string) model.RecurringRuleEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("recurring rule's id is not acceptable")
+		return model.RecurringRuleEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.RecurringRuleTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2RecurringRuleEntity(database.GetOneInMongoDB(filter))
+}
+
+func (RecurringRuleMongoDbMapper) GetRecurringRuleByName(ownerPlainId, ruleName string) model.RecurringRuleEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+		primitive.E{Key: "name", Value: ruleName},
+	}
+
+	database.OpenMongoDbConnection(database.RecurringRuleTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2RecurringRuleEntity(database.GetOneInMongoDB(filter))
+}
+
+func (RecurringRuleMongoDbMapper) InsertRecurringRuleByEntity(newEntity model.RecurringRuleEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	database.OpenMongoDbConnection(database.RecurringRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	newRecurringRuleId := database.InsertOneInMongoDB(convertRecurringRuleEntity2BsonD(newEntity))
+	return newRecurringRuleId.Hex()
+}
+
+func (RecurringRuleMongoDbMapper) BulkInsertRecurringRules(entities []model.RecurringRuleEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		documents[i] = convertRecurringRuleEntity2BsonD(fillOperatingTime(entity, operatingTime))
+	}
+
+	collection := database.GetMongoCollection(database.RecurringRuleTableName)
+	result, err := collection.InsertMany(context.TODO(), documents)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	ids := make([]string, len(result.InsertedIDs))
+	for i, id := range result.InsertedIDs {
+		ids[i] = id.(primitive.ObjectID).Hex()
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (RecurringRuleMongoDbMapper) UpdateRecurringRuleByEntity(plainId string, updatedEntity model.RecurringRuleEntity) model.RecurringRuleEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("recurring rule's id is not acceptable")
+		return model.RecurringRuleEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.RecurringRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2RecurringRuleEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("recurring rule is not exist")
+		return model.RecurringRuleEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	rowsAffected := database.UpdateManyInMongoDB(filter, convertRecurringRuleEntity2BsonD(updatedEntity))
+	if rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "rows_affected", rowsAffected)
+		return model.RecurringRuleEntity{}
+	}
+	return updatedEntity
+}
+
+func (RecurringRuleMongoDbMapper) GetAllRecurringRules(limit, offset int) []model.RecurringRuleEntity {
+	return findMongoRecurringRulesPage(bson.D{}, limit, offset)
+}
+
+func (RecurringRuleMongoDbMapper) GetRecurringRulesByOwnerId(ownerPlainId string, limit, offset int) []model.RecurringRuleEntity {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+	return findMongoRecurringRulesPage(filter, limit, offset)
+}
+
+func (RecurringRuleMongoDbMapper) CountAllRecurringRules() int64 {
+	database.OpenMongoDbConnection(database.RecurringRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(bson.D{})
+}
+
+func (RecurringRuleMongoDbMapper) CountRecurringRulesByOwnerId(ownerPlainId string) int64 {
+	filter := bson.D{
+		database.OwnerFilterInMongoDB(ownerPlainId),
+	}
+
+	database.OpenMongoDbConnection(database.RecurringRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(filter)
+}
+
+func (RecurringRuleMongoDbMapper) DeleteRecurringRuleByObjectId(plainId string) model.RecurringRuleEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("recurring rule's id is not acceptable")
+		return model.RecurringRuleEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.RecurringRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2RecurringRuleEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("recurring rule is not exist")
+		return model.RecurringRuleEntity{}
+	}
+
+	rowsAffected := database.DeleteManyInMongoDB(filter)
+	if rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "rows_affected", rowsAffected)
+		return model.RecurringRuleEntity{}
+	}
+	return targetEntity
+}
+
+func (RecurringRuleMongoDbMapper) DeleteAllRecurringRules() (int64, error) {
+	collection := database.GetMongoCollection(database.RecurringRuleTableName)
+	result, err := collection.DeleteMany(context.TODO(), bson.D{})
+	if err != nil {
+		util.Logger.Errorw("delete all recurring rules failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all recurring rules deleted", "count", result.DeletedCount)
+	return result.DeletedCount, nil
+}
+
+func (RecurringRuleMongoDbMapper) AssignUnownedRecurringRules(ownerPlainId string) (int64, error) {
+	collection := database.GetMongoCollection(database.RecurringRuleTableName)
+	result, err := collection.UpdateMany(context.TODO(),
+		bson.D{database.OwnerFilterInMongoDB("")},
+		bson.D{primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "owner_id", Value: util.ConvertOwner2ObjectId(ownerPlainId)},
+		}}})
+	if err != nil {
+		util.Logger.Errorw("assign unowned recurring rules failed", "error", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// findMongoRecurringRulesPage returns one page of the matching recurring rules in listing order
+func findMongoRecurringRulesPage(filter bson.D, limit, offset int) []model.RecurringRuleEntity {
+	findOptions := database.GetFindOptions()
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		findOptions.SetSkip(int64(offset))
+	}
+	// Sort by name ascending
+	findOptions.SetSort(bson.D{primitive.E{Key: "name", Value: 1}})
+
+	return findMongoRecurringRules(filter, findOptions)
+}
+
+func findMongoRecurringRules(filter bson.D, findOptions *options.FindOptions) []model.RecurringRuleEntity {
+	collection := database.GetMongoCollection(database.RecurringRuleTableName)
+
+	ctx := context.TODO()
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		util.Logger.Errorw("query recurring rules failed", "error", err)
+		return []model.RecurringRuleEntity{}
+	}
+	defer cursor.Close(ctx)
+
+	var targetEntityList []model.RecurringRuleEntity
+	for cursor.Next(ctx) {
+		var bsonM bson.M
+		if err := cursor.Decode(&bsonM); err != nil {
+			util.Logger.Errorw("decode failed", "error", err)
+			continue
+		}
+		targetEntityList = append(targetEntityList, convertBsonM2RecurringRuleEntity(bsonM))
+	}
+	return targetEntityList
+}
+
+func convertRecurringRuleEntity2BsonD(entity model.RecurringRuleEntity) bson.D {
+	// 为空时自动生成新Id
+	if entity.Id == primitive.NilObjectID {
+		entity.Id = primitive.NewObjectID()
+	}
+
+	return bson.D{
+		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "owner_id", Value: entity.OwnerId},
+		primitive.E{Key: "name", Value: entity.Name},
+		primitive.E{Key: "flow_type", Value: entity.FlowType},
+		primitive.E{Key: "category_id", Value: entity.CategoryId},
+		primitive.E{Key: "account_id", Value: entity.AccountId},
+		primitive.E{Key: "amount", Value: entity.Amount},
+		primitive.E{Key: "currency", Value: entity.Currency},
+		primitive.E{Key: "description", Value: entity.Description},
+		primitive.E{Key: "frequency", Value: entity.Frequency},
+		primitive.E{Key: "day_of_month", Value: entity.DayOfMonth},
+		primitive.E{Key: "start_date", Value: entity.StartDate},
+		primitive.E{Key: "end_date", Value: entity.EndDate},
+		primitive.E{Key: "occurrence_limit", Value: entity.OccurrenceLimit},
+		primitive.E{Key: "occurrence_count", Value: entity.OccurrenceCount},
+		primitive.E{Key: "last_run_date", Value: entity.LastRunDate},
+		primitive.E{Key: "create_time", Value: entity.CreateTime},
+		primitive.E{Key: "modify_time", Value: entity.ModifyTime},
+	}
+}
+
+func convertBsonM2RecurringRuleEntity(bsonM bson.M) model.RecurringRuleEntity {
+	var newEntity model.RecurringRuleEntity
+	if err := database.DecodeBsonM(bsonM, &newEntity); err != nil {
+		panic(err)
+	}
+	return newEntity
+}
@@ -0,0 +1,414 @@
+package recurring_rule_mapper
+
+import (
+	"bytes"
+	"database/sql"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RecurringRuleMySqlMapper struct{}
+
+const mySqlRecurringRuleColumns = "ID, OWNER_ID, NAME, FLOW_TYPE, CATEGORY_ID, ACCOUNT_ID, AMOUNT, CURRENCY, DESCRIPTION, " +
+	"FREQUENCY, DAY_OF_MONTH, START_DATE, END_DATE, OCCURRENCE_LIMIT, OCCURRENCE_COUNT, LAST_RUN_DATE, " +
+	"CREATE_TIME, MODIFY_TIME"
+
+const mySqlRecurringRulePlaceholders = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+func (RecurringRuleMySqlMapper) GetRecurringRuleByObjectId(plainId string) model.RecurringRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlRecurringRuleColumns + " FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := queryMySqlRecurringRules(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.RecurringRuleEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (RecurringRuleMySqlMapper) GetRecurringRuleByName(ownerPlainId, ruleName string) model.RecurringRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlRecurringRuleColumns + " FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND NAME = ? ")
+
+	targetEntityList := queryMySqlRecurringRules(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), ruleName)
+	if len(targetEntityList) == 0 {
+		return model.RecurringRuleEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (RecurringRuleMySqlMapper) InsertRecurringRuleByEntity(newEntity model.RecurringRuleEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" (" + mySqlRecurringRuleColumns + ") VALUES " + mySqlRecurringRulePlaceholders)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := connection.Exec(sqlString.String(), convertRecurringRuleEntity2MySqlValues(newPlainId, newEntity)...)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (RecurringRuleMySqlMapper) BulkInsertRecurringRules(entities []model.RecurringRuleEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" (" + mySqlRecurringRuleColumns + ") VALUES ")
+
+	ids := make([]string, len(entities))
+	values := make([]interface{}, 0, len(entities)*18)
+
+	for i, entity := range entities {
+		if i > 0 {
+			sqlString.WriteString(", ")
+		}
+		sqlString.WriteString(mySqlRecurringRulePlaceholders)
+
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		values = append(values, convertRecurringRuleEntity2MySqlValues(ids[i], entity)...)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), values...)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != int64(len(entities)) {
+		util.Logger.Errorw("bulk insert incomplete", "error", err, "expected", len(entities), "actual", rowsAffected)
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (RecurringRuleMySqlMapper) UpdateRecurringRuleByEntity(plainId string, updatedEntity model.RecurringRuleEntity) model.RecurringRuleEntity {
+	targetEntity := INSTANCE.GetRecurringRuleByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("recurring rule is not exist")
+		return model.RecurringRuleEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" SET NAME = ?, ")
+	sqlString.WriteString(" FLOW_TYPE = ?, ")
+	sqlString.WriteString(" CATEGORY_ID = ?, ")
+	sqlString.WriteString(" ACCOUNT_ID = ?, ")
+	sqlString.WriteString(" AMOUNT = ?, ")
+	sqlString.WriteString(" CURRENCY = ?, ")
+	sqlString.WriteString(" DESCRIPTION = ?, ")
+	sqlString.WriteString(" FREQUENCY = ?, ")
+	sqlString.WriteString(" DAY_OF_MONTH = ?, ")
+	sqlString.WriteString(" START_DATE = ?, ")
+	sqlString.WriteString(" END_DATE = ?, ")
+	sqlString.WriteString(" OCCURRENCE_LIMIT = ?, ")
+	sqlString.WriteString(" OCCURRENCE_COUNT = ?, ")
+	sqlString.WriteString(" LAST_RUN_DATE = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(),
+		updatedEntity.Name, updatedEntity.FlowType, updatedEntity.CategoryId.Hex(), updatedEntity.AccountId.Hex(),
+		updatedEntity.Amount, updatedEntity.Currency, updatedEntity.Description,
+		updatedEntity.Frequency, updatedEntity.DayOfMonth, updatedEntity.StartDate,
+		convertOptionalDate2SqlValue(updatedEntity.EndDate), updatedEntity.OccurrenceLimit, updatedEntity.OccurrenceCount,
+		convertOptionalDate2SqlValue(updatedEntity.LastRunDate), updatedEntity.ModifyTime, plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.RecurringRuleEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (RecurringRuleMySqlMapper) GetAllRecurringRules(limit, offset int) []model.RecurringRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlRecurringRuleColumns + " FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlRecurringRules(sqlString.String(), limit, offset)
+	}
+	return queryMySqlRecurringRules(sqlString.String())
+}
+
+func (RecurringRuleMySqlMapper) GetRecurringRulesByOwnerId(ownerPlainId string, limit, offset int) []model.RecurringRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlRecurringRuleColumns + " FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlRecurringRules(sqlString.String(), ownerId, limit, offset)
+	}
+	return queryMySqlRecurringRules(sqlString.String(), ownerId)
+}
+
+func (RecurringRuleMySqlMapper) CountRecurringRulesByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	err := connection.QueryRow(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count recurring rules by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (RecurringRuleMySqlMapper) CountAllRecurringRules() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all recurring rules failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (RecurringRuleMySqlMapper) DeleteRecurringRuleByObjectId(plainId string) model.RecurringRuleEntity {
+	targetEntity := INSTANCE.GetRecurringRuleByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("recurring rule is not exist")
+		return model.RecurringRuleEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.RecurringRuleEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (RecurringRuleMySqlMapper) DeleteAllRecurringRules() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all recurring rules failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all recurring rules failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all recurring rules deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (RecurringRuleMySqlMapper) AssignUnownedRecurringRules(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned recurring rules failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func queryMySqlRecurringRules(sqlString string, args ...interface{}) []model.RecurringRuleEntity {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.RecurringRuleEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2RecurringRuleEntity(rows))
+	}
+	return targetEntityList
+}
+
+// generatePlainId keeps a preset id (e.g. restored from backup) and generates one otherwise
+func generatePlainId(objectId primitive.ObjectID) string {
+	if objectId == primitive.NilObjectID {
+		return primitive.NewObjectID().Hex()
+	}
+	return objectId.Hex()
+}
+
+// convertRecurringRuleEntity2MySqlValues lists the column values in mySqlRecurringRuleColumns order
+func convertRecurringRuleEntity2MySqlValues(plainId string, entity model.RecurringRuleEntity) []interface{} {
+	return []interface{}{
+		plainId,
+		entity.OwnerId.Hex(),
+		entity.Name,
+		entity.FlowType,
+		entity.CategoryId.Hex(),
+		entity.AccountId.Hex(),
+		entity.Amount,
+		entity.Currency,
+		entity.Description,
+		entity.Frequency,
+		entity.DayOfMonth,
+		entity.StartDate,
+		convertOptionalDate2SqlValue(entity.EndDate),
+		entity.OccurrenceLimit,
+		entity.OccurrenceCount,
+		convertOptionalDate2SqlValue(entity.LastRunDate),
+		entity.CreateTime,
+		entity.ModifyTime,
+	}
+}
+
+// convertOptionalDate2SqlValue stores an unset end or last run date as NULL
+func convertOptionalDate2SqlValue(date time.Time) interface{} {
+	if date.IsZero() {
+		return nil
+	}
+	return util.FormatDateToStringWithDash(date)
+}
+
+func convertRow2RecurringRuleEntity(rows *sql.Rows) model.RecurringRuleEntity {
+	var id string
+	var ownerId string
+	var name string
+	var flowType string
+	var categoryId string
+	var accountId string
+	var amount decimal.Decimal
+	var currency string
+	var description sql.NullString
+	var frequency string
+	var dayOfMonth int
+	var startDate string
+	var endDate sql.NullString
+	var occurrenceLimit int
+	var occurrenceCount int
+	var lastRunDate sql.NullString
+	var createTime string
+	var modifyTime string
+
+	err := rows.Scan(&id, &ownerId, &name, &flowType, &categoryId, &accountId, &amount, &currency, &description,
+		&frequency, &dayOfMonth, &startDate, &endDate, &occurrenceLimit, &occurrenceCount, &lastRunDate,
+		&createTime, &modifyTime)
+	if err != nil {
+		util.Logger.Errorw("covert into entity failed", "error", err)
+	}
+
+	entity := model.RecurringRuleEntity{
+		Id:              util.Convert2ObjectId(id),
+		OwnerId:         util.Convert2ObjectId(ownerId),
+		Name:            name,
+		FlowType:        flowType,
+		CategoryId:      util.Convert2ObjectId(categoryId),
+		AccountId:       util.Convert2ObjectId(accountId),
+		Amount:          amount,
+		Currency:        currency,
+		Description:     description.String,
+		Frequency:       frequency,
+		DayOfMonth:      dayOfMonth,
+		StartDate:       util.FormatDateTimeFromString(startDate),
+		OccurrenceLimit: occurrenceLimit,
+		OccurrenceCount: occurrenceCount,
+		CreateTime:      util.FormatDateTimeFromString(createTime),
+		ModifyTime:      util.FormatDateTimeFromString(modifyTime),
+	}
+	if endDate.Valid {
+		entity.EndDate = util.FormatDateTimeFromString(endDate.String)
+	}
+	if lastRunDate.Valid {
+		entity.LastRunDate = util.FormatDateTimeFromString(lastRunDate.String)
+	}
+	return entity
+}
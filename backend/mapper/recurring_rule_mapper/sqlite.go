@@ -0,0 +1,324 @@
+package recurring_rule_mapper
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RecurringRuleSqliteMapper struct{}
+
+const sqliteRecurringRuleColumns = "ID, OWNER_ID, NAME, FLOW_TYPE, CATEGORY_ID, ACCOUNT_ID, AMOUNT, CURRENCY, DESCRIPTION, " +
+	"FREQUENCY, DAY_OF_MONTH, START_DATE, END_DATE, OCCURRENCE_LIMIT, OCCURRENCE_COUNT, LAST_RUN_DATE, " +
+	"CREATE_TIME, MODIFY_TIME"
+
+const sqliteRecurringRulePlaceholders = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+func (RecurringRuleSqliteMapper) GetRecurringRuleByObjectId(plainId string) model.RecurringRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteRecurringRuleColumns + " FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := querySqliteRecurringRules(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.RecurringRuleEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (RecurringRuleSqliteMapper) GetRecurringRuleByName(ownerPlainId, ruleName string) model.RecurringRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteRecurringRuleColumns + " FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? AND NAME = ? ")
+
+	targetEntityList := querySqliteRecurringRules(sqlString.String(), util.ConvertOwner2ObjectId(ownerPlainId).Hex(), ruleName)
+	if len(targetEntityList) == 0 {
+		return model.RecurringRuleEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (RecurringRuleSqliteMapper) InsertRecurringRuleByEntity(newEntity model.RecurringRuleEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" (" + sqliteRecurringRuleColumns + ") VALUES " + sqliteRecurringRulePlaceholders)
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		convertRecurringRuleEntity2SqliteValues(newPlainId, newEntity)...)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (RecurringRuleSqliteMapper) BulkInsertRecurringRules(entities []model.RecurringRuleEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" (" + sqliteRecurringRuleColumns + ") VALUES " + sqliteRecurringRulePlaceholders)
+
+	// One transaction keeps the batch all-or-nothing and avoids a disk sync per row
+	transaction, err := database.GetSqliteConnection().Begin()
+	if err != nil {
+		util.Logger.Errorw("bulk insert begin failed", "error", err)
+		return nil, err
+	}
+
+	statement, err := transaction.Prepare(sqlString.String())
+	if err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert prepare failed", "error", err)
+		return nil, err
+	}
+	defer statement.Close()
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		if _, err := statement.Exec(convertRecurringRuleEntity2SqliteValues(ids[i], entity)...); err != nil {
+			transaction.Rollback()
+			util.Logger.Errorw("bulk insert failed", "error", err)
+			return nil, err
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		util.Logger.Errorw("bulk insert commit failed", "error", err)
+		return nil, err
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (RecurringRuleSqliteMapper) UpdateRecurringRuleByEntity(plainId string, updatedEntity model.RecurringRuleEntity) model.RecurringRuleEntity {
+	targetEntity := INSTANCE.GetRecurringRuleByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("recurring rule is not exist")
+		return model.RecurringRuleEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID, owner and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.OwnerId = targetEntity.OwnerId
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" SET NAME = ?, ")
+	sqlString.WriteString(" FLOW_TYPE = ?, ")
+	sqlString.WriteString(" CATEGORY_ID = ?, ")
+	sqlString.WriteString(" ACCOUNT_ID = ?, ")
+	sqlString.WriteString(" AMOUNT = ?, ")
+	sqlString.WriteString(" CURRENCY = ?, ")
+	sqlString.WriteString(" DESCRIPTION = ?, ")
+	sqlString.WriteString(" FREQUENCY = ?, ")
+	sqlString.WriteString(" DAY_OF_MONTH = ?, ")
+	sqlString.WriteString(" START_DATE = ?, ")
+	sqlString.WriteString(" END_DATE = ?, ")
+	sqlString.WriteString(" OCCURRENCE_LIMIT = ?, ")
+	sqlString.WriteString(" OCCURRENCE_COUNT = ?, ")
+	sqlString.WriteString(" LAST_RUN_DATE = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		updatedEntity.Name, updatedEntity.FlowType, updatedEntity.CategoryId.Hex(), updatedEntity.AccountId.Hex(),
+		updatedEntity.Amount, updatedEntity.Currency, updatedEntity.Description,
+		updatedEntity.Frequency, updatedEntity.DayOfMonth, util.FormatDateToStringWithDash(updatedEntity.StartDate),
+		convertOptionalDate2SqlValue(updatedEntity.EndDate), updatedEntity.OccurrenceLimit, updatedEntity.OccurrenceCount,
+		convertOptionalDate2SqlValue(updatedEntity.LastRunDate), util.FormatDateTimeToString(updatedEntity.ModifyTime), plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.RecurringRuleEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (RecurringRuleSqliteMapper) GetAllRecurringRules(limit, offset int) []model.RecurringRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteRecurringRuleColumns + " FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteRecurringRules(sqlString.String(), limit, offset)
+	}
+	return querySqliteRecurringRules(sqlString.String())
+}
+
+func (RecurringRuleSqliteMapper) GetRecurringRulesByOwnerId(ownerPlainId string, limit, offset int) []model.RecurringRuleEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteRecurringRuleColumns + " FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+	sqlString.WriteString(" ORDER BY NAME ASC ")
+
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId).Hex()
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteRecurringRules(sqlString.String(), ownerId, limit, offset)
+	}
+	return querySqliteRecurringRules(sqlString.String(), ownerId)
+}
+
+func (RecurringRuleSqliteMapper) CountRecurringRulesByOwnerId(ownerPlainId string) int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	var count int64
+	err := database.GetSqliteConnection().QueryRow(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex()).Scan(&count)
+	if err != nil {
+		util.Logger.Errorw("count recurring rules by owner failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (RecurringRuleSqliteMapper) CountAllRecurringRules() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all recurring rules failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (RecurringRuleSqliteMapper) DeleteRecurringRuleByObjectId(plainId string) model.RecurringRuleEntity {
+	targetEntity := INSTANCE.GetRecurringRuleByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("recurring rule is not exist")
+		return model.RecurringRuleEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.RecurringRuleEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (RecurringRuleSqliteMapper) DeleteAllRecurringRules() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all recurring rules failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all recurring rules failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all recurring rules deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (RecurringRuleSqliteMapper) AssignUnownedRecurringRules(ownerPlainId string) (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.RecurringRuleTableName)
+	sqlString.WriteString(" SET OWNER_ID = ? ")
+	sqlString.WriteString(" WHERE OWNER_ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		util.ConvertOwner2ObjectId(ownerPlainId).Hex(), primitive.NilObjectID.Hex())
+	if err != nil {
+		util.Logger.Errorw("assign unowned recurring rules failed", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func querySqliteRecurringRules(sqlString string, args ...interface{}) []model.RecurringRuleEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.RecurringRuleEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2RecurringRuleEntity(rows))
+	}
+	return targetEntityList
+}
+
+// convertRecurringRuleEntity2SqliteValues lists the column values in sqliteRecurringRuleColumns order,
+// dates are written as text so that comparisons and ORDER BY work on them.
+func convertRecurringRuleEntity2SqliteValues(plainId string, entity model.RecurringRuleEntity) []interface{} {
+	return []interface{}{
+		plainId,
+		entity.OwnerId.Hex(),
+		entity.Name,
+		entity.FlowType,
+		entity.CategoryId.Hex(),
+		entity.AccountId.Hex(),
+		entity.Amount,
+		entity.Currency,
+		entity.Description,
+		entity.Frequency,
+		entity.DayOfMonth,
+		util.FormatDateToStringWithDash(entity.StartDate),
+		convertOptionalDate2SqlValue(entity.EndDate),
+		entity.OccurrenceLimit,
+		entity.OccurrenceCount,
+		convertOptionalDate2SqlValue(entity.LastRunDate),
+		util.FormatDateTimeToString(entity.CreateTime),
+		util.FormatDateTimeToString(entity.ModifyTime),
+	}
+}
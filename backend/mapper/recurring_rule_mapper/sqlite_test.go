@@ -0,0 +1,131 @@
+package recurring_rule_mapper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMain(m *testing.M) {
+	tempDir, err := os.MkdirTemp("", "cashlens-sqlite-")
+	if err != nil {
+		panic(err)
+	}
+	util.SetConfigByKey("db.sqlite.path", filepath.Join(tempDir, "recurring_rule_test.db"))
+	INSTANCE = RecurringRuleSqliteMapper{}
+
+	code := m.Run()
+	os.RemoveAll(tempDir)
+	os.Exit(code)
+}
+
+func TestSqliteRecurringRuleLifecycle(t *testing.T) {
+	mapper := RecurringRuleSqliteMapper{}
+	if _, err := mapper.DeleteAllRecurringRules(); err != nil {
+		t.Fatalf("DeleteAllRecurringRules() error = %v", err)
+	}
+
+	categoryId := primitive.NewObjectID()
+	rentId := mapper.InsertRecurringRuleByEntity(model.RecurringRuleEntity{
+		Name:        "Rent",
+		FlowType:    model.FlowTypeOutcome,
+		CategoryId:  categoryId,
+		Amount:      decimal.RequireFromString("1200.50"),
+		Currency:    "USD",
+		Frequency:   model.FrequencyMonthly,
+		DayOfMonth:  31,
+		StartDate:   util.FormatDateFromStringWithDash("2024-01-31"),
+		EndDate:     util.FormatDateFromStringWithDash("2024-12-31"),
+		Description: "Monthly rent",
+	})
+	otherIds, err := mapper.BulkInsertRecurringRules([]model.RecurringRuleEntity{
+		{Name: "Salary", FlowType: model.FlowTypeIncome, CategoryId: categoryId, Amount: decimal.NewFromInt(5000),
+			Currency: "USD", Frequency: model.FrequencyLastBusinessDay, StartDate: util.FormatDateFromStringWithDash("2024-01-01")},
+		{Name: "Coffee", FlowType: model.FlowTypeOutcome, CategoryId: categoryId, Amount: decimal.RequireFromString("3.20"),
+			Currency: "USD", Frequency: model.FrequencyDaily, StartDate: util.FormatDateFromStringWithDash("2024-01-01"),
+			OccurrenceLimit: 10},
+	})
+	if err != nil || len(otherIds) != 2 {
+		t.Fatalf("BulkInsertRecurringRules() = %v, %v", otherIds, err)
+	}
+
+	rent := mapper.GetRecurringRuleByObjectId(rentId)
+	if !rent.Amount.Equal(decimal.RequireFromString("1200.5")) || rent.CategoryId != categoryId || rent.DayOfMonth != 31 ||
+		util.FormatDateToStringWithDash(rent.EndDate) != "2024-12-31" || !rent.LastRunDate.IsZero() {
+		t.Errorf("GetRecurringRuleByObjectId() = %+v", rent)
+	}
+	if salary := mapper.GetRecurringRuleByName("", "Salary"); salary.Id.Hex() != otherIds[0] || !salary.EndDate.IsZero() {
+		t.Errorf("GetRecurringRuleByName() = %+v", salary)
+	}
+	if count := mapper.CountAllRecurringRules(); count != 3 {
+		t.Errorf("CountAllRecurringRules() = %d, want 3", count)
+	}
+	if allList := mapper.GetAllRecurringRules(2, 0); len(allList) != 2 || allList[0].Name != "Coffee" {
+		t.Errorf("GetAllRecurringRules() = %+v, want the first two by name", allList)
+	}
+
+	rent.OccurrenceCount = 2
+	rent.LastRunDate = util.FormatDateFromStringWithDash("2024-02-29")
+	mapper.UpdateRecurringRuleByEntity(rentId, rent)
+	updated := mapper.GetRecurringRuleByObjectId(rentId)
+	if updated.OccurrenceCount != 2 || util.FormatDateToStringWithDash(updated.LastRunDate) != "2024-02-29" {
+		t.Errorf("UpdateRecurringRuleByEntity() did not save the progress, got %+v", updated)
+	}
+
+	if deleted := mapper.DeleteRecurringRuleByObjectId(otherIds[1]); deleted.Name != "Coffee" {
+		t.Errorf("DeleteRecurringRuleByObjectId() = %+v", deleted)
+	}
+
+	deletedCount, err := mapper.DeleteAllRecurringRules()
+	if err != nil || deletedCount != 2 {
+		t.Errorf("DeleteAllRecurringRules() = %d, %v, want 2, nil", deletedCount, err)
+	}
+}
+
+func TestSqliteRecurringRuleOwners(t *testing.T) {
+	mapper := RecurringRuleSqliteMapper{}
+	if _, err := mapper.DeleteAllRecurringRules(); err != nil {
+		t.Fatalf("DeleteAllRecurringRules() error = %v", err)
+	}
+
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	unownedId := mapper.InsertRecurringRuleByEntity(model.RecurringRuleEntity{Name: "Rent", FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromInt(100), Currency: "USD", Frequency: model.FrequencyMonthly})
+	bobId := mapper.InsertRecurringRuleByEntity(model.RecurringRuleEntity{OwnerId: bob, Name: "Rent", FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromInt(100), Currency: "USD", Frequency: model.FrequencyMonthly})
+
+	// Owners look names up in their own rules only
+	if rule := mapper.GetRecurringRuleByName(bob.Hex(), "Rent"); rule.Id.Hex() != bobId {
+		t.Errorf("GetRecurringRuleByName(bob) = %+v, want id %s", rule, bobId)
+	}
+	if rule := mapper.GetRecurringRuleByName(alice.Hex(), "Rent"); !rule.IsEmpty() {
+		t.Errorf("GetRecurringRuleByName(alice) = %+v, want none", rule)
+	}
+
+	// An update keeps the owner
+	bobRecurringRule := mapper.GetRecurringRuleByObjectId(bobId)
+	bobRecurringRule.OwnerId = primitive.NilObjectID
+	mapper.UpdateRecurringRuleByEntity(bobId, bobRecurringRule)
+	if owner := mapper.GetRecurringRuleByObjectId(bobId).OwnerId; owner != bob {
+		t.Errorf("UpdateRecurringRuleByEntity() changed the owner to %s", owner.Hex())
+	}
+
+	// Only the rules without owner are handed over
+	assignedCount, err := mapper.AssignUnownedRecurringRules(alice.Hex())
+	if err != nil || assignedCount != 1 {
+		t.Fatalf("AssignUnownedRecurringRules() = %d, %v, want 1, nil", assignedCount, err)
+	}
+	aliceList := mapper.GetRecurringRulesByOwnerId(alice.Hex(), 0, 0)
+	if len(aliceList) != 1 || aliceList[0].Id.Hex() != unownedId {
+		t.Errorf("GetRecurringRulesByOwnerId(alice) = %+v, want the unowned rule", aliceList)
+	}
+	if bobList := mapper.GetRecurringRulesByOwnerId(bob.Hex(), 0, 0); len(bobList) != 1 || bobList[0].Id.Hex() != bobId {
+		t.Errorf("GetRecurringRulesByOwnerId(bob) = %+v, want bob's rule", bobList)
+	}
+	if count := mapper.CountRecurringRulesByOwnerId(bob.Hex()); count != 1 {
+		t.Errorf("CountRecurringRulesByOwnerId(bob) = %d, want 1", count)
+	}
+}
@@ -0,0 +1,48 @@
+package user_mapper
+
+import (
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+)
+
+var INSTANCE UserMapper
+
+type UserMapper interface {
+	GetUserByObjectId(plainId string) model.UserEntity
+	GetUserByUsername(username string) model.UserEntity
+	InsertUserByEntity(newEntity model.UserEntity) string
+	BulkInsertUsers(entities []model.UserEntity) ([]string, error)
+	UpdateUserByEntity(plainId string, updatedEntity model.UserEntity) model.UserEntity
+	GetAllUsers(limit, offset int) []model.UserEntity
+	CountAllUsers() int64
+	DeleteUserByObjectId(plainId string) model.UserEntity
+	DeleteAllUsers() (int64, error)
+}
+
+func init() {
+	switch util.GetConfigByKey("db.type") {
+	case "mongodb":
+		INSTANCE = UserMongoDbMapper{}
+	case "mysql":
+		INSTANCE = UserMySqlMapper{}
+	case "sqlite":
+		INSTANCE = UserSqliteMapper{}
+	case "memory":
+		INSTANCE = NewUserMemoryMapper()
+	default:
+		panic("database type not supported")
+	}
+}
+
+// fillOperatingTime keeps create/modify times already set, and stamps the operating time on brand-new records.
+func fillOperatingTime(entity model.UserEntity, operatingTime time.Time) model.UserEntity {
+	if entity.CreateTime.IsZero() {
+		entity.CreateTime = operatingTime
+	}
+	if entity.ModifyTime.IsZero() {
+		entity.ModifyTime = operatingTime
+	}
+	return entity
+}
@@ -0,0 +1,187 @@
+package user_mapper
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserMemoryMapper keeps users in process memory, nothing is persisted.
+// It is safe for concurrent use; copies of the mapper share the same data.
+type UserMemoryMapper struct {
+	store *userMemoryStore
+}
+
+type userMemoryStore struct {
+	mutex   sync.RWMutex
+	records map[primitive.ObjectID]model.UserEntity
+}
+
+// NewUserMemoryMapper returns an empty in-memory mapper
+func NewUserMemoryMapper() UserMemoryMapper {
+	return UserMemoryMapper{
+		store: &userMemoryStore{
+			records: make(map[primitive.ObjectID]model.UserEntity),
+		},
+	}
+}
+
+func (mapper UserMemoryMapper) GetUserByObjectId(plainId string) model.UserEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("user's id is not acceptable")
+		return model.UserEntity{}
+	}
+
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return mapper.store.records[objectId]
+}
+
+func (mapper UserMemoryMapper) GetUserByUsername(username string) model.UserEntity {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+
+	for _, entity := range mapper.store.records {
+		if entity.Username == username {
+			return entity
+		}
+	}
+	return model.UserEntity{}
+}
+
+func (mapper UserMemoryMapper) InsertUserByEntity(newEntity model.UserEntity) string {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Usernames are unique, like the unique index of the database mappers
+	for _, entity := range mapper.store.records {
+		if entity.Username == newEntity.Username {
+			util.Logger.Errorw("insert failed", "error", "duplicate username: "+newEntity.Username)
+			return ""
+		}
+	}
+
+	newEntity = fillOperatingTime(newEntity, time.Now())
+	if newEntity.Id == primitive.NilObjectID {
+		newEntity.Id = primitive.NewObjectID()
+	}
+	if _, isExist := mapper.store.records[newEntity.Id]; isExist {
+		util.Logger.Errorw("insert failed", "error", "duplicate user id: "+newEntity.Id.Hex())
+		return ""
+	}
+	mapper.store.records[newEntity.Id] = newEntity
+	return newEntity.Id.Hex()
+}
+
+func (mapper UserMemoryMapper) BulkInsertUsers(entities []model.UserEntity) ([]string, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	// Check every record first, so a failing batch leaves nothing behind
+	usernames := make(map[string]bool, len(mapper.store.records)+len(entities))
+	for _, entity := range mapper.store.records {
+		usernames[entity.Username] = true
+	}
+	operatingTime := time.Now()
+	newEntityList := make([]model.UserEntity, len(entities))
+	batchIds := make(map[primitive.ObjectID]bool, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		if entity.Id == primitive.NilObjectID {
+			entity.Id = primitive.NewObjectID()
+		}
+		if _, isExist := mapper.store.records[entity.Id]; isExist || batchIds[entity.Id] {
+			return nil, errors.New("duplicate user id: " + entity.Id.Hex())
+		}
+		if usernames[entity.Username] {
+			return nil, errors.New("duplicate username: " + entity.Username)
+		}
+		batchIds[entity.Id] = true
+		usernames[entity.Username] = true
+		newEntityList[i] = entity
+	}
+
+	ids := make([]string, len(newEntityList))
+	for i, entity := range newEntityList {
+		mapper.store.records[entity.Id] = entity
+		ids[i] = entity.Id.Hex()
+	}
+	return ids, nil
+}
+
+func (mapper UserMemoryMapper) UpdateUserByEntity(plainId string, updatedEntity model.UserEntity) model.UserEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("user is not exist")
+		return model.UserEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+	mapper.store.records[objectId] = updatedEntity
+	return updatedEntity
+}
+
+func (mapper UserMemoryMapper) GetAllUsers(limit, offset int) []model.UserEntity {
+	mapper.store.mutex.RLock()
+	targetEntityList := make([]model.UserEntity, 0, len(mapper.store.records))
+	for _, entity := range mapper.store.records {
+		targetEntityList = append(targetEntityList, entity)
+	}
+	mapper.store.mutex.RUnlock()
+
+	sort.Slice(targetEntityList, func(i, j int) bool {
+		return targetEntityList[i].Username < targetEntityList[j].Username
+	})
+	if offset >= len(targetEntityList) {
+		return []model.UserEntity{}
+	}
+	targetEntityList = targetEntityList[offset:]
+	if limit > 0 && limit < len(targetEntityList) {
+		targetEntityList = targetEntityList[:limit]
+	}
+	return targetEntityList
+}
+
+func (mapper UserMemoryMapper) CountAllUsers() int64 {
+	mapper.store.mutex.RLock()
+	defer mapper.store.mutex.RUnlock()
+	return int64(len(mapper.store.records))
+}
+
+func (mapper UserMemoryMapper) DeleteUserByObjectId(plainId string) model.UserEntity {
+	objectId := util.Convert2ObjectId(plainId)
+
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	targetEntity, isExist := mapper.store.records[objectId]
+	if !isExist {
+		util.Logger.Infoln("user is not exist")
+		return model.UserEntity{}
+	}
+	delete(mapper.store.records, objectId)
+	return targetEntity
+}
+
+func (mapper UserMemoryMapper) DeleteAllUsers() (int64, error) {
+	mapper.store.mutex.Lock()
+	defer mapper.store.mutex.Unlock()
+
+	deletedCount := int64(len(mapper.store.records))
+	mapper.store.records = make(map[primitive.ObjectID]model.UserEntity)
+	return deletedCount, nil
+}
@@ -0,0 +1,218 @@
+package user_mapper
+
+import (
+	"context"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type UserMongoDbMapper struct{}
+
+func (UserMongoDbMapper) GetUserByObjectId(plainId string) model.UserEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("user's id is not acceptable")
+		return model.UserEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.UserTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2UserEntity(database.GetOneInMongoDB(filter))
+}
+
+func (UserMongoDbMapper) GetUserByUsername(username string) model.UserEntity {
+	filter := bson.D{
+		primitive.E{Key: "username", Value: username},
+	}
+
+	database.OpenMongoDbConnection(database.UserTableName)
+	defer database.CloseMongoDbConnection()
+	return convertBsonM2UserEntity(database.GetOneInMongoDB(filter))
+}
+
+func (UserMongoDbMapper) InsertUserByEntity(newEntity model.UserEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	database.OpenMongoDbConnection(database.UserTableName)
+	defer database.CloseMongoDbConnection()
+
+	newUserId := database.InsertOneInMongoDB(convertUserEntity2BsonD(newEntity))
+	return newUserId.Hex()
+}
+
+func (UserMongoDbMapper) BulkInsertUsers(entities []model.UserEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		documents[i] = convertUserEntity2BsonD(fillOperatingTime(entity, operatingTime))
+	}
+
+	collection := database.GetMongoCollection(database.UserTableName)
+	result, err := collection.InsertMany(context.TODO(), documents)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	ids := make([]string, len(result.InsertedIDs))
+	for i, id := range result.InsertedIDs {
+		ids[i] = id.(primitive.ObjectID).Hex()
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (UserMongoDbMapper) UpdateUserByEntity(plainId string, updatedEntity model.UserEntity) model.UserEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("user's id is not acceptable")
+		return model.UserEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.UserTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2UserEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("user is not exist")
+		return model.UserEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	rowsAffected := database.UpdateManyInMongoDB(filter, convertUserEntity2BsonD(updatedEntity))
+	if rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "rows_affected", rowsAffected)
+		return model.UserEntity{}
+	}
+	return updatedEntity
+}
+
+func (UserMongoDbMapper) GetAllUsers(limit, offset int) []model.UserEntity {
+	findOptions := database.GetFindOptions()
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		findOptions.SetSkip(int64(offset))
+	}
+	// Sort by username ascending
+	findOptions.SetSort(bson.D{primitive.E{Key: "username", Value: 1}})
+
+	return findMongoUsers(bson.D{}, findOptions)
+}
+
+func (UserMongoDbMapper) CountAllUsers() int64 {
+	database.OpenMongoDbConnection(database.UserTableName)
+	defer database.CloseMongoDbConnection()
+
+	return database.CountInMongoDB(bson.D{})
+}
+
+func (UserMongoDbMapper) DeleteUserByObjectId(plainId string) model.UserEntity {
+	objectId := util.Convert2ObjectId(plainId)
+	if plainId == "" || objectId == primitive.NilObjectID {
+		util.Logger.Warnln("user's id is not acceptable")
+		return model.UserEntity{}
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: objectId},
+	}
+
+	database.OpenMongoDbConnection(database.UserTableName)
+	defer database.CloseMongoDbConnection()
+
+	targetEntity := convertBsonM2UserEntity(database.GetOneInMongoDB(filter))
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("user is not exist")
+		return model.UserEntity{}
+	}
+
+	rowsAffected := database.DeleteManyInMongoDB(filter)
+	if rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "rows_affected", rowsAffected)
+		return model.UserEntity{}
+	}
+	return targetEntity
+}
+
+func (UserMongoDbMapper) DeleteAllUsers() (int64, error) {
+	collection := database.GetMongoCollection(database.UserTableName)
+	result, err := collection.DeleteMany(context.TODO(), bson.D{})
+	if err != nil {
+		util.Logger.Errorw("delete all users failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all users deleted", "count", result.DeletedCount)
+	return result.DeletedCount, nil
+}
+
+func findMongoUsers(filter bson.D, findOptions *options.FindOptions) []model.UserEntity {
+	collection := database.GetMongoCollection(database.UserTableName)
+
+	ctx := context.TODO()
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		util.Logger.Errorw("query users failed", "error", err)
+		return []model.UserEntity{}
+	}
+	defer cursor.Close(ctx)
+
+	var targetEntityList []model.UserEntity
+	for cursor.Next(ctx) {
+		var bsonM bson.M
+		if err := cursor.Decode(&bsonM); err != nil {
+			util.Logger.Errorw("decode failed", "error", err)
+			continue
+		}
+		targetEntityList = append(targetEntityList, convertBsonM2UserEntity(bsonM))
+	}
+	return targetEntityList
+}
+
+func convertUserEntity2BsonD(entity model.UserEntity) bson.D {
+	// 为空时自动生成新Id
+	if entity.Id == primitive.NilObjectID {
+		entity.Id = primitive.NewObjectID()
+	}
+
+	return bson.D{
+		primitive.E{Key: "_id", Value: entity.Id},
+		primitive.E{Key: "username", Value: entity.Username},
+		primitive.E{Key: "password_hash", Value: entity.PasswordHash},
+		primitive.E{Key: "create_time", Value: entity.CreateTime},
+		primitive.E{Key: "modify_time", Value: entity.ModifyTime},
+	}
+}
+
+func convertBsonM2UserEntity(bsonM bson.M) model.UserEntity {
+	var newEntity model.UserEntity
+	if err := database.DecodeBsonM(bsonM, &newEntity); err != nil {
+		panic(err)
+	}
+	return newEntity
+}
@@ -0,0 +1,276 @@
+package user_mapper
+
+import (
+	"bytes"
+	"database/sql"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UserMySqlMapper struct{}
+
+const mySqlUserColumns = "ID, USERNAME, PASSWORD_HASH, CREATE_TIME, MODIFY_TIME"
+
+func (UserMySqlMapper) GetUserByObjectId(plainId string) model.UserEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlUserColumns + " FROM ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := queryMySqlUsers(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.UserEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (UserMySqlMapper) GetUserByUsername(username string) model.UserEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlUserColumns + " FROM ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" WHERE USERNAME = ? ")
+
+	targetEntityList := queryMySqlUsers(sqlString.String(), username)
+	if len(targetEntityList) == 0 {
+		return model.UserEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (UserMySqlMapper) InsertUserByEntity(newEntity model.UserEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" (" + mySqlUserColumns + ") VALUES (?, ?, ?, ?, ?)")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := connection.Exec(sqlString.String(),
+		newPlainId, newEntity.Username, newEntity.PasswordHash, newEntity.CreateTime, newEntity.ModifyTime)
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (UserMySqlMapper) BulkInsertUsers(entities []model.UserEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	operatingTime := time.Now()
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" (" + mySqlUserColumns + ") VALUES ")
+
+	ids := make([]string, len(entities))
+	values := make([]interface{}, 0, len(entities)*5)
+
+	for i, entity := range entities {
+		if i > 0 {
+			sqlString.WriteString(", ")
+		}
+		sqlString.WriteString("(?, ?, ?, ?, ?)")
+
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		values = append(values, ids[i], entity.Username, entity.PasswordHash, entity.CreateTime, entity.ModifyTime)
+	}
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), values...)
+	if err != nil {
+		util.Logger.Errorw("bulk insert failed", "error", err)
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != int64(len(entities)) {
+		util.Logger.Errorw("bulk insert incomplete", "error", err, "expected", len(entities), "actual", rowsAffected)
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (UserMySqlMapper) UpdateUserByEntity(plainId string, updatedEntity model.UserEntity) model.UserEntity {
+	targetEntity := INSTANCE.GetUserByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("user is not exist")
+		return model.UserEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" SET USERNAME = ?, ")
+	sqlString.WriteString(" PASSWORD_HASH = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(),
+		updatedEntity.Username, updatedEntity.PasswordHash, updatedEntity.ModifyTime, plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.UserEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (UserMySqlMapper) GetAllUsers(limit, offset int) []model.UserEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + mySqlUserColumns + " FROM ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" ORDER BY USERNAME ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return queryMySqlUsers(sqlString.String(), limit, offset)
+	}
+	return queryMySqlUsers(sqlString.String())
+}
+
+func (UserMySqlMapper) CountAllUsers() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.UserTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	var count int64
+	if err := connection.QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all users failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (UserMySqlMapper) DeleteUserByObjectId(plainId string) model.UserEntity {
+	targetEntity := INSTANCE.GetUserByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("user is not exist")
+		return model.UserEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.UserEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (UserMySqlMapper) DeleteAllUsers() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.UserTableName)
+
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	result, err := connection.Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all users failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all users failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all users deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func queryMySqlUsers(sqlString string, args ...interface{}) []model.UserEntity {
+	connection := database.GetMySqlConnection()
+	defer database.CloseMySqlConnection()
+
+	rows, err := connection.Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.UserEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2UserEntity(rows))
+	}
+	return targetEntityList
+}
+
+// generatePlainId keeps a preset id and generates one otherwise
+func generatePlainId(objectId primitive.ObjectID) string {
+	if objectId == primitive.NilObjectID {
+		return primitive.NewObjectID().Hex()
+	}
+	return objectId.Hex()
+}
+
+func convertRow2UserEntity(rows *sql.Rows) model.UserEntity {
+	var id string
+	var username string
+	var passwordHash string
+	var createTime string
+	var modifyTime string
+
+	err := rows.Scan(&id, &username, &passwordHash, &createTime, &modifyTime)
+	if err != nil {
+		util.Logger.Errorw("covert into entity failed", "error", err)
+	}
+
+	return model.UserEntity{
+		Id:           util.Convert2ObjectId(id),
+		Username:     username,
+		PasswordHash: passwordHash,
+		CreateTime:   util.FormatDateTimeFromString(createTime),
+		ModifyTime:   util.FormatDateTimeFromString(modifyTime),
+	}
+}
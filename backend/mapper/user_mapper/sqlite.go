@@ -0,0 +1,232 @@
+package user_mapper
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/util/database"
+)
+
+type UserSqliteMapper struct{}
+
+const sqliteUserColumns = "ID, USERNAME, PASSWORD_HASH, CREATE_TIME, MODIFY_TIME"
+
+func (UserSqliteMapper) GetUserByObjectId(plainId string) model.UserEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteUserColumns + " FROM ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	targetEntityList := querySqliteUsers(sqlString.String(), plainId)
+	if len(targetEntityList) == 0 {
+		return model.UserEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (UserSqliteMapper) GetUserByUsername(username string) model.UserEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteUserColumns + " FROM ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" WHERE USERNAME = ? ")
+
+	targetEntityList := querySqliteUsers(sqlString.String(), username)
+	if len(targetEntityList) == 0 {
+		return model.UserEntity{}
+	}
+	return targetEntityList[0]
+}
+
+func (UserSqliteMapper) InsertUserByEntity(newEntity model.UserEntity) string {
+	newEntity = fillOperatingTime(newEntity, time.Now())
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" (" + sqliteUserColumns + ") VALUES (?, ?, ?, ?, ?) ")
+
+	newPlainId := generatePlainId(newEntity.Id)
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(),
+		newPlainId, newEntity.Username, newEntity.PasswordHash,
+		util.FormatDateTimeToString(newEntity.CreateTime), util.FormatDateTimeToString(newEntity.ModifyTime))
+	if err != nil {
+		util.Logger.Errorw("insert failed", "error", err)
+		return ""
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("insert failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return newPlainId
+}
+
+func (UserSqliteMapper) BulkInsertUsers(entities []model.UserEntity) ([]string, error) {
+	if len(entities) == 0 {
+		return []string{}, nil
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("INSERT INTO ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" (" + sqliteUserColumns + ") VALUES (?, ?, ?, ?, ?) ")
+
+	// One transaction keeps the batch all-or-nothing
+	transaction, err := database.GetSqliteConnection().Begin()
+	if err != nil {
+		util.Logger.Errorw("bulk insert begin failed", "error", err)
+		return nil, err
+	}
+
+	statement, err := transaction.Prepare(sqlString.String())
+	if err != nil {
+		transaction.Rollback()
+		util.Logger.Errorw("bulk insert prepare failed", "error", err)
+		return nil, err
+	}
+	defer statement.Close()
+
+	operatingTime := time.Now()
+	ids := make([]string, len(entities))
+	for i, entity := range entities {
+		entity = fillOperatingTime(entity, operatingTime)
+		ids[i] = generatePlainId(entity.Id)
+		if _, err := statement.Exec(ids[i], entity.Username, entity.PasswordHash,
+			util.FormatDateTimeToString(entity.CreateTime), util.FormatDateTimeToString(entity.ModifyTime)); err != nil {
+			transaction.Rollback()
+			util.Logger.Errorw("bulk insert failed", "error", err)
+			return nil, err
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		util.Logger.Errorw("bulk insert commit failed", "error", err)
+		return nil, err
+	}
+
+	util.Logger.Infow("bulk insert successful", "count", len(ids))
+	return ids, nil
+}
+
+func (UserSqliteMapper) UpdateUserByEntity(plainId string, updatedEntity model.UserEntity) model.UserEntity {
+	targetEntity := INSTANCE.GetUserByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("user is not exist")
+		return model.UserEntity{}
+	}
+
+	// Update fields from updatedEntity while preserving ID and CreateTime
+	updatedEntity.Id = targetEntity.Id
+	updatedEntity.CreateTime = targetEntity.CreateTime
+	updatedEntity.ModifyTime = time.Now()
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("UPDATE ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" SET USERNAME = ?, ")
+	sqlString.WriteString(" PASSWORD_HASH = ?, ")
+	sqlString.WriteString(" MODIFY_TIME = ? ")
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), updatedEntity.Username,
+		updatedEntity.PasswordHash, util.FormatDateTimeToString(updatedEntity.ModifyTime), plainId)
+	if err != nil {
+		util.Logger.Errorw("update failed", "error", err)
+		return model.UserEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("update failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return updatedEntity
+}
+
+func (UserSqliteMapper) GetAllUsers(limit, offset int) []model.UserEntity {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT " + sqliteUserColumns + " FROM ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" ORDER BY USERNAME ASC ")
+
+	if limit > 0 {
+		sqlString.WriteString(" LIMIT ? OFFSET ? ")
+		return querySqliteUsers(sqlString.String(), limit, offset)
+	}
+	return querySqliteUsers(sqlString.String())
+}
+
+func (UserSqliteMapper) CountAllUsers() int64 {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("SELECT COUNT(1) FROM ")
+	sqlString.WriteString(database.UserTableName)
+
+	var count int64
+	if err := database.GetSqliteConnection().QueryRow(sqlString.String()).Scan(&count); err != nil {
+		util.Logger.Errorw("count all users failed", "error", err)
+		return 0
+	}
+	return count
+}
+
+func (UserSqliteMapper) DeleteUserByObjectId(plainId string) model.UserEntity {
+	targetEntity := INSTANCE.GetUserByObjectId(plainId)
+	if targetEntity.IsEmpty() {
+		util.Logger.Infoln("user is not exist")
+		return model.UserEntity{}
+	}
+
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.UserTableName)
+	sqlString.WriteString(" WHERE ID = ? ")
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String(), plainId)
+	if err != nil {
+		util.Logger.Errorw("delete failed", "error", err)
+		return model.UserEntity{}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected != 1 {
+		util.Logger.Errorw("delete failed", "error", err, "rows_affected", rowsAffected)
+	}
+	return targetEntity
+}
+
+func (UserSqliteMapper) DeleteAllUsers() (int64, error) {
+	var sqlString bytes.Buffer
+	sqlString.WriteString("DELETE FROM ")
+	sqlString.WriteString(database.UserTableName)
+
+	result, err := database.GetSqliteConnection().Exec(sqlString.String())
+	if err != nil {
+		util.Logger.Errorw("delete all users failed", "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		util.Logger.Errorw("delete all users failed", "error", err)
+		return 0, err
+	}
+
+	util.Logger.Infow("all users deleted", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+func querySqliteUsers(sqlString string, args ...interface{}) []model.UserEntity {
+	rows, err := database.GetSqliteConnection().Query(sqlString, args...)
+	if err != nil {
+		util.Logger.Errorw("query failed", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var targetEntityList []model.UserEntity
+	for rows.Next() {
+		targetEntityList = append(targetEntityList, convertRow2UserEntity(rows))
+	}
+	return targetEntityList
+}
@@ -0,0 +1,93 @@
+package user_mapper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMain(m *testing.M) {
+	tempDir, err := os.MkdirTemp("", "cashlens-sqlite-")
+	if err != nil {
+		panic(err)
+	}
+	util.SetConfigByKey("db.sqlite.path", filepath.Join(tempDir, "user_test.db"))
+	INSTANCE = UserSqliteMapper{}
+
+	code := m.Run()
+	os.RemoveAll(tempDir)
+	os.Exit(code)
+}
+
+func TestSqliteUserLifecycle(t *testing.T) {
+	mapper := UserSqliteMapper{}
+	if _, err := mapper.DeleteAllUsers(); err != nil {
+		t.Fatalf("DeleteAllUsers() error = %v", err)
+	}
+
+	aliceId := mapper.InsertUserByEntity(model.UserEntity{Username: "alice", PasswordHash: "hash-1"})
+	if aliceId == "" {
+		t.Fatalf("InsertUserByEntity() returned no id")
+	}
+	if duplicateId := mapper.InsertUserByEntity(model.UserEntity{Username: "alice", PasswordHash: "hash-2"}); duplicateId != "" {
+		t.Errorf("InsertUserByEntity() with a taken username = %q, want no id", duplicateId)
+	}
+
+	alice := mapper.GetUserByUsername("alice")
+	if alice.Id.Hex() != aliceId || alice.PasswordHash != "hash-1" || alice.CreateTime.IsZero() {
+		t.Errorf("GetUserByUsername() = %+v", alice)
+	}
+	if missing := mapper.GetUserByUsername("bob"); !missing.IsEmpty() {
+		t.Errorf("GetUserByUsername() of an unknown user = %+v, want empty", missing)
+	}
+
+	alice.PasswordHash = "hash-3"
+	mapper.UpdateUserByEntity(aliceId, alice)
+	if updated := mapper.GetUserByObjectId(aliceId); updated.PasswordHash != "hash-3" || updated.Username != "alice" {
+		t.Errorf("UpdateUserByEntity() did not replace the hash, got %+v", updated)
+	}
+
+	if count := mapper.CountAllUsers(); count != 1 {
+		t.Errorf("CountAllUsers() = %d, want 1", count)
+	}
+	deletedCount, err := mapper.DeleteAllUsers()
+	if err != nil || deletedCount != 1 {
+		t.Errorf("DeleteAllUsers() = %d, %v, want 1, nil", deletedCount, err)
+	}
+}
+
+func TestSqliteUserBulkInsert(t *testing.T) {
+	mapper := UserSqliteMapper{}
+	if _, err := mapper.DeleteAllUsers(); err != nil {
+		t.Fatalf("DeleteAllUsers() error = %v", err)
+	}
+
+	bobId := primitive.NewObjectID()
+	ids, err := mapper.BulkInsertUsers([]model.UserEntity{
+		{Id: bobId, Username: "bob", PasswordHash: "hash-b"},
+		{Username: "alice", PasswordHash: "hash-a"},
+	})
+	if err != nil || len(ids) != 2 || ids[0] != bobId.Hex() {
+		t.Fatalf("BulkInsertUsers() = %v, %v, want bob's id kept", ids, err)
+	}
+	if _, err := mapper.BulkInsertUsers([]model.UserEntity{{Username: "carol"}, {Username: "bob"}}); err == nil {
+		t.Errorf("BulkInsertUsers() with a taken username should fail")
+	}
+	if missing := mapper.GetUserByUsername("carol"); !missing.IsEmpty() {
+		t.Errorf("a failed BulkInsertUsers() left carol behind")
+	}
+
+	if pageList := mapper.GetAllUsers(1, 1); len(pageList) != 1 || pageList[0].Username != "bob" {
+		t.Errorf("GetAllUsers(1, 1) = %+v, want bob", pageList)
+	}
+	if deleted := mapper.DeleteUserByObjectId(bobId.Hex()); deleted.Username != "bob" {
+		t.Errorf("DeleteUserByObjectId() = %+v, want bob", deleted)
+	}
+	if count := mapper.CountAllUsers(); count != 1 {
+		t.Errorf("CountAllUsers() = %d after delete, want 1", count)
+	}
+}
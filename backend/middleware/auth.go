@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/user_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+type contextKey string
+
+const currentUserKey contextKey = "current_user"
+
+// publicPaths are served without a token
+var publicPaths = map[string]bool{
+	"/api/health":     true,
+	"/api/version":    true,
+	"/api/auth/login": true,
+}
+
+// optionalAuthPaths are served without a token, but still learn who is calling when one is sent
+var optionalAuthPaths = map[string]bool{
+	"/api/auth/register": true,
+}
+
+// Auth middleware turns away requests without a valid "Authorization: Bearer <token>" header.
+// The token is either a sign-in token or a personal API token, the user is kept in the request context.
+func Auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" || publicPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := readBearerToken(r)
+		if token == "" && optionalAuthPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userEntity, err := user_service.AuthenticateService(token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="cashlens"`)
+			util.ComposeJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), currentUserKey, userEntity)))
+	})
+}
+
+// CurrentUser returns the user who sent the request, empty when it came without a token
+func CurrentUser(r *http.Request) model.UserEntity {
+	userEntity, _ := r.Context().Value(currentUserKey).(model.UserEntity)
+	return userEntity
+}
+
+func readBearerToken(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(authorization[7:])
+}
@@ -29,10 +29,9 @@ func CORS(next http.Handler) http.Handler {
 			}
 		}
 
-		// Set CORS headers
+		// Set CORS headers, no credentials: the API reads its token from the Authorization header, not from cookies
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-Ledger-Id")
 		w.Header().Set("Access-Control-Max-Age", "3600")
 
 		// Handle preflight requests
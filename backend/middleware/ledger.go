@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/macar-x/cashlens/errors"
+	"github.com/macar-x/cashlens/service/ledger_service"
+	"github.com/macar-x/cashlens/util"
+)
+
+const (
+	currentLedgerKey     contextKey = "current_ledger"
+	currentLedgerRoleKey contextKey = "current_ledger_role"
+)
+
+// LedgerHeader picks the ledger a request works on, the user's personal ledger when left out
+const LedgerHeader = "X-Ledger-Id"
+
+// Ledger middleware resolves the ledger of the request and checks the user's role in it:
+// any member may read, only owners and editors may write. Ledger management and sign-in routes
+// check permissions on their own.
+func Ledger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userEntity := CurrentUser(r)
+		if r.Method == "OPTIONS" || userEntity.IsEmpty() ||
+			strings.HasPrefix(r.URL.Path, "/api/auth/") || strings.HasPrefix(r.URL.Path, "/api/ledgers") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userPlainId := userEntity.Id.Hex()
+		ledgerPlainId := strings.TrimSpace(r.Header.Get(LedgerHeader))
+		if ledgerPlainId == "" {
+			ledgerPlainId = userPlainId
+		}
+
+		role, err := ledger_service.RoleService(userPlainId, ledgerPlainId)
+		if err != nil {
+			statusCode := http.StatusBadRequest
+			if errors.IsNotFound(err) {
+				statusCode = http.StatusNotFound
+			} else if !errors.IsValidationError(err) {
+				statusCode = http.StatusInternalServerError
+			}
+			util.ComposeJSONResponse(w, statusCode, map[string]string{"error": err.Error()})
+			return
+		}
+		if r.Method != "GET" && r.Method != "HEAD" && !ledger_service.CanEdit(role) {
+			util.ComposeJSONResponse(w, http.StatusForbidden, map[string]string{"error": "a " + strings.ToLower(role) + " cannot change the ledger"})
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), currentLedgerKey, ledgerPlainId)
+		ctx = context.WithValue(ctx, currentLedgerRoleKey, role)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// CurrentLedger returns the id of the ledger the request works on, empty when it came without a user
+func CurrentLedger(r *http.Request) string {
+	ledgerPlainId, _ := r.Context().Value(currentLedgerKey).(string)
+	return ledgerPlainId
+}
+
+// CurrentLedgerRole returns the user's role in the ledger the request works on
+func CurrentLedgerRole(r *http.Request) string {
+	role, _ := r.Context().Value(currentLedgerRoleKey).(string)
+	return role
+}
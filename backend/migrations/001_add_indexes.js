@@ -24,9 +24,9 @@ print("✓ Created index: idx_category_id");
 
 print("\nCreating indexes for category collection...");
 
-// Unique index on category name
-db.category.createIndex({ "name": 1 }, { unique: true, name: "idx_category_name_unique" });
-print("✓ Created unique index: idx_category_name_unique");
+// Unique index on category owner and name, each owner keeps their own names
+db.category.createIndex({ "owner_id": 1, "name": 1 }, { unique: true, name: "idx_category_owner_name_unique" });
+print("✓ Created unique index: idx_category_owner_name_unique");
 
 print("\n=== Index Creation Complete ===");
 print("\nVerifying indexes:");
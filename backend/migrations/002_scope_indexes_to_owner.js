@@ -0,0 +1,41 @@
+// MongoDB Index Migration
+// Run with: mongosh <connection_string> < 002_scope_indexes_to_owner.js
+//
+// Accounts, goals, payees, rules and exchange rates are kept per ledger now,
+// so their unique indexes lead with owner_id instead of spanning all owners.
+
+// Switch to cashlens database
+use cashlens;
+
+// Drops an index replaced below, a database indexed by `cashlens manage indexes` may not have it
+function dropIndexIfExists(collection, indexName) {
+    if (collection.getIndexes().some(function(idx) { return idx.name === indexName; })) {
+        collection.dropIndex(indexName);
+        print("✓ Dropped index: " + indexName);
+    }
+}
+
+var ownerNameIndexes = [
+    { collection: db.account, dropped: "idx_account_name_unique", added: "idx_account_owner_name_unique" },
+    { collection: db.goal, dropped: "idx_goal_name_unique", added: "idx_goal_owner_name_unique" },
+    { collection: db.payee, dropped: "idx_payee_name_unique", added: "idx_payee_owner_name_unique" },
+    { collection: db.recurring_rule, dropped: "idx_recurring_rule_name_unique", added: "idx_recurring_rule_owner_name_unique" },
+    { collection: db.category_rule, dropped: "idx_category_rule_name_unique", added: "idx_category_rule_owner_name_unique" }
+];
+
+ownerNameIndexes.forEach(function(index) {
+    print("\nScoping names of " + index.collection.getName() + " collection to their owner...");
+    dropIndexIfExists(index.collection, index.dropped);
+    index.collection.createIndex({ "owner_id": 1, "name": 1 }, { unique: true, name: index.added });
+    print("✓ Created unique index: " + index.added);
+});
+
+print("\nScoping exchange rates to their owner...");
+dropIndexIfExists(db.exchange_rate, "idx_exchange_rate_pair_date_unique");
+db.exchange_rate.createIndex(
+    { "owner_id": 1, "from_currency": 1, "to_currency": 1, "effective_date": -1 },
+    { unique: true, name: "idx_exchange_rate_owner_pair_date_unique" }
+);
+print("✓ Created unique index: idx_exchange_rate_owner_pair_date_unique");
+
+print("\n=== Index Migration Complete ===");
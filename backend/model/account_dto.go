@@ -0,0 +1,11 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+type AccountDTO struct {
+	Name           string          `json:"name"`
+	Type           string          `json:"type"`
+	OpeningBalance decimal.Decimal `json:"opening_balance"`
+	Currency       string          `json:"currency"`
+	Remark         string          `json:"remark"`
+}
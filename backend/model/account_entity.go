@@ -0,0 +1,41 @@
+package model
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AccountEntity struct {
+	Id             primitive.ObjectID `bson:"_id,omitempty"`
+	OwnerId        primitive.ObjectID `json:"owner_id" bson:"owner_id"` // the ledger it is kept in, names are unique per ledger
+	Name           string             `json:"name" bson:"name"`
+	Type           string             `json:"type" bson:"type"`
+	OpeningBalance decimal.Decimal    `json:"opening_balance" bson:"opening_balance"`
+	Currency       string             `json:"currency" bson:"currency"`
+	Remark         string             `json:"remark" bson:"remark"`
+	CreateTime     time.Time          `json:"create_time" bson:"create_time"`
+	ModifyTime     time.Time          `json:"modify_time" bson:"modify_time"`
+}
+
+func (entity AccountEntity) IsEmpty() bool {
+	return reflect.DeepEqual(entity, AccountEntity{})
+}
+
+// IsOwnedBy tells whether the record belongs to the owner, a blank owner matches the records no user owns
+func (entity AccountEntity) IsOwnedBy(ownerPlainId string) bool {
+	return entity.OwnerId == util.ConvertOwner2ObjectId(ownerPlainId)
+}
+
+func (entity AccountEntity) ToString() string {
+	return "[ " +
+		"Id: " + entity.Id.Hex() +
+		", Name: " + entity.Name +
+		", Type: " + entity.Type +
+		", OpeningBalance: " + entity.OpeningBalance.StringFixed(2) +
+		", Currency: " + entity.Currency +
+		" ]"
+}
@@ -0,0 +1,8 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+func init() {
+	// Amounts stay JSON numbers as they were when they were float64
+	decimal.MarshalJSONWithoutQuotes = true
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ApiTokenEntity is a long-lived personal token a user hands to scripts instead of a password.
+// Only the SHA-256 hash of the token is kept, the token itself is shown once when it is created.
+type ApiTokenEntity struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty"`
+	UserId     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Name       string             `json:"name" bson:"name"`
+	TokenHash  string             `json:"-" bson:"token_hash"`
+	CreateTime time.Time          `json:"create_time" bson:"create_time"`
+	ModifyTime time.Time          `json:"modify_time" bson:"modify_time"`
+}
+
+func (entity ApiTokenEntity) IsEmpty() bool {
+	return reflect.DeepEqual(entity, ApiTokenEntity{})
+}
+
+func (entity ApiTokenEntity) ToString() string {
+	return "[ " +
+		"Id: " + entity.Id.Hex() +
+		", UserId: " + entity.UserId.Hex() +
+		", Name: " + entity.Name +
+		", CreateTime: " + entity.CreateTime.Format(time.RFC3339) +
+		" ]"
+}
@@ -0,0 +1,12 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+type BudgetDTO struct {
+	CategoryName string          `json:"category_name"`
+	Period       string          `json:"period"`
+	Limit        decimal.Decimal `json:"limit"`
+	Currency     string          `json:"currency"`
+	Rollover     bool            `json:"rollover"`
+	StartDate    string          `json:"start_date"`
+}
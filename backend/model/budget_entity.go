@@ -0,0 +1,51 @@
+package model
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BudgetEntity caps the outcome of a category, its subcategories included, per period.
+// There is at most one budget per category and period.
+type BudgetEntity struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty"`
+	OwnerId    primitive.ObjectID `json:"owner_id" bson:"owner_id"` // the ledger of its category, nil when no user owns it
+	CategoryId primitive.ObjectID `json:"category_id" bson:"category_id"`
+	Period     string             `json:"period" bson:"period"`
+	Limit      decimal.Decimal    `json:"limit" bson:"limit"`
+	Currency   string             `json:"currency" bson:"currency"`
+	Rollover   bool               `json:"rollover" bson:"rollover"`     // unspent amounts carry over into the next period
+	StartDate  time.Time          `json:"start_date" bson:"start_date"` // first day of the first period, rollover counts from here
+	CreateTime time.Time          `json:"create_time" bson:"create_time"`
+	ModifyTime time.Time          `json:"modify_time" bson:"modify_time"`
+}
+
+func (entity BudgetEntity) IsEmpty() bool {
+	return reflect.DeepEqual(entity, BudgetEntity{})
+}
+
+// IsOwnedBy tells whether the record belongs to the owner, a blank owner matches the records no user owns
+func (entity BudgetEntity) IsOwnedBy(ownerPlainId string) bool {
+	return entity.OwnerId == util.ConvertOwner2ObjectId(ownerPlainId)
+}
+
+func (entity BudgetEntity) ToString() string {
+	rollover := "false"
+	if entity.Rollover {
+		rollover = "true"
+	}
+
+	return "[ " +
+		"Id: " + entity.Id.Hex() +
+		", CategoryId: " + entity.CategoryId.Hex() +
+		", Period: " + entity.Period +
+		", Limit: " + entity.Limit.StringFixed(2) +
+		", Currency: " + entity.Currency +
+		", Rollover: " + rollover +
+		", StartDate: " + util.FormatDateToStringWithDash(entity.StartDate) +
+		" ]"
+}
@@ -1,8 +1,29 @@
 package model
 
+import "github.com/shopspring/decimal"
+
 type CashFlowDTO struct {
-	BelongsDate  string  `json:"belongs_date"`
-	CategoryName string  `json:"category_name"`
-	Amount       float64 `json:"amount"`
-	Description  string  `json:"description"`
+	BelongsDate  string          `json:"belongs_date"`
+	CategoryName string          `json:"category_name"`
+	AccountName  string          `json:"account_name"`
+	Amount       decimal.Decimal `json:"amount"`
+	Currency     string          `json:"currency"`
+	Description  string          `json:"description"`
+	PayeeName    string          `json:"payee_name"` // blank lets the payee rules recognise it in the description
+	Tags         []string        `json:"tags"`       // nil keeps the tags on update, empty clears them
+}
+
+// CashFlowSplitDTO is one line of a split request, the lines have to add up to the cash flow's amount
+type CashFlowSplitDTO struct {
+	CategoryName string          `json:"category_name"`
+	Amount       decimal.Decimal `json:"amount"`
+	Description  string          `json:"description"`
+}
+
+type TransferDTO struct {
+	BelongsDate     string          `json:"belongs_date"`
+	FromAccountName string          `json:"from_account_name"`
+	ToAccountName   string          `json:"to_account_name"`
+	Amount          decimal.Decimal `json:"amount"`
+	Description     string          `json:"description"`
 }
@@ -3,29 +3,60 @@ package model
 import (
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type CashFlowEntity struct {
 	Id          primitive.ObjectID `bson:"_id,omitempty"`
+	OwnerId     primitive.ObjectID `json:"owner_id" bson:"owner_id"` // the ledger it is kept in, nil when no user owns it
 	CategoryId  primitive.ObjectID `json:"category_id" bson:"category_id"`
+	AccountId   primitive.ObjectID `json:"account_id" bson:"account_id"`
+	LinkedId    primitive.ObjectID `json:"linked_id" bson:"linked_id"` // the other leg of a transfer
+	PayeeId     primitive.ObjectID `json:"payee_id" bson:"payee_id"`   // nil when no payee is known
 	BelongsDate time.Time          `json:"belongs_date" bson:"belongs_date"`
 	FlowType    string             `json:"flow_type" bson:"flow_type"`
-	Amount      float64            `json:"amount" bson:"amount"`
+	Amount      decimal.Decimal    `json:"amount" bson:"amount"`
+	Currency    string             `json:"currency" bson:"currency"`
 	Description string             `json:"description" bson:"description"`
+	Tags        []string           `json:"tags" bson:"tags"`     // lowercase and sorted, nil when untagged
+	Splits      []CashFlowSplit    `json:"splits" bson:"splits"` // category lines adding up to Amount, nil when not split
 	Remark      string             `json:"remark" bson:"remark"`
+	CreatedBy   primitive.ObjectID `json:"created_by" bson:"created_by"`   // the user who recorded it, nil when booked by the system
+	ModifiedBy  primitive.ObjectID `json:"modified_by" bson:"modified_by"` // the user who last changed it
 	CreateTime  time.Time          `json:"create_time" bson:"create_time"`
 	ModifyTime  time.Time          `json:"modify_time" bson:"modify_time"`
 }
 
+// CashFlowSplit is one line of a cash flow shared among several categories, in the cash flow's currency
+type CashFlowSplit struct {
+	CategoryId  primitive.ObjectID `json:"category_id" bson:"category_id"`
+	Amount      decimal.Decimal    `json:"amount" bson:"amount"`
+	Description string             `json:"description" bson:"description"`
+}
+
 func (entity CashFlowEntity) IsEmpty() bool {
 	return reflect.DeepEqual(entity, CashFlowEntity{})
 }
 
+// IsOwnedBy tells whether the record belongs to the owner, a blank owner matches the records no user owns
+func (entity CashFlowEntity) IsOwnedBy(ownerPlainId string) bool {
+	return entity.OwnerId == util.ConvertOwner2ObjectId(ownerPlainId)
+}
+
+// CategoryLines returns the split lines, or the cash flow's own category and amount as a single line
+func (entity CashFlowEntity) CategoryLines() []CashFlowSplit {
+	if len(entity.Splits) > 0 {
+		return entity.Splits
+	}
+	return []CashFlowSplit{{CategoryId: entity.CategoryId, Amount: entity.Amount, Description: entity.Description}}
+}
+
 func (entity CashFlowEntity) ToString() string {
 	// todo: category query from cache like redis would be better.
 	return "[ " +
@@ -34,8 +65,11 @@ func (entity CashFlowEntity) ToString() string {
 		// ", Category: " + mapper.GetCategoryMapper().GetCategoryByObjectId(entity.CategoryId.Hex()).Name +
 		", Date: " + util.FormatDateToStringWithoutDash(entity.BelongsDate) +
 		", FlowType: " + entity.FlowType +
-		", Amount: " + strconv.FormatFloat(entity.Amount, 'f', 2, 64) +
+		", Amount: " + entity.Amount.StringFixed(2) +
+		", Currency: " + entity.Currency +
 		", Description: " + entity.Description +
+		", Tags: " + strings.Join(entity.Tags, ",") +
+		", Splits: " + strconv.Itoa(len(entity.Splits)) +
 		" ]"
 }
 
@@ -51,19 +85,31 @@ func (entity CashFlowEntity) Build(fieldMap map[string]string) CashFlowEntity {
 			newEntity.Id = objectId
 		case "CategoryId":
 			newEntity.CategoryId = util.Convert2ObjectId(value)
+		case "AccountId":
+			newEntity.AccountId = util.Convert2ObjectId(value)
+		case "LinkedId":
+			newEntity.LinkedId = util.Convert2ObjectId(value)
+		case "PayeeId":
+			newEntity.PayeeId = util.Convert2ObjectId(value)
 		case "BelongsDate":
 			newEntity.BelongsDate = util.FormatDateFromStringWithoutDash(value)
 		case "FlowType":
 			// todo: use enum to check if value available
 			newEntity.FlowType = value
 		case "Amount":
-			amount, err := strconv.ParseFloat(value, 64)
+			amount, err := decimal.NewFromString(value)
 			if err != nil {
 				util.Logger.Warnln("build cash failed with err: " + err.Error())
 			}
 			newEntity.Amount = amount
+		case "Currency":
+			newEntity.Currency = value
 		case "Description":
 			newEntity.Description = value
+		case "Tags":
+			if value != "" {
+				newEntity.Tags = strings.Split(value, ",")
+			}
 		case "Remark":
 			newEntity.Remark = value
 		}
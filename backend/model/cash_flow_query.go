@@ -0,0 +1,139 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Sort orders of a cash flow query
+const (
+	CashFlowSortByDate      = "date"
+	CashFlowSortByAmount    = "amount"
+	CashFlowSortByRelevance = "relevance" // only with a search text, ties fall back to the date
+)
+
+// CashFlowQuerySpec combines the filters, sort and page of a cash flow query, every filter left at
+// its zero value is not applied and the filters that are set must all match
+type CashFlowQuerySpec struct {
+	Text        string               // every word has to appear in the description
+	FromDate    time.Time            // inclusive
+	ToDate      time.Time            // inclusive
+	MinAmount   decimal.Decimal      // inclusive
+	MaxAmount   decimal.Decimal      // inclusive
+	FlowType    string               // INCOME, OUTCOME or TRANSFER
+	CategoryIds []primitive.ObjectID // booked to any of them, directly or on a split line
+	Tags        []string             // carrying all of them
+	AccountId   primitive.ObjectID
+	SortBy      string // date by default
+	SortDesc    bool
+	Limit       int            // 0 returns every match
+	Offset      int            // not combined with a cursor
+	Cursor      CashFlowCursor // continues the date order next to a record, ignored by the other orders
+}
+
+// CursorApplies tells whether the query continues from a cursor, only the date order has one
+func (querySpec CashFlowQuerySpec) CursorApplies() bool {
+	return !querySpec.Cursor.IsZero() && (querySpec.SortBy == "" || querySpec.SortBy == CashFlowSortByDate)
+}
+
+// ScansAscending tells the order the records next to the cursor are read in, the listing order
+// turned around for a backward page so that the closest records come first
+func (querySpec CashFlowQuerySpec) ScansAscending() bool {
+	return querySpec.SortDesc == querySpec.Cursor.Backward
+}
+
+// CashFlowCursor is a position in the (belongs_date, id) order of a listing. It stays valid while
+// records are added or removed in front of it, unlike an offset.
+type CashFlowCursor struct {
+	BelongsDate time.Time
+	Id          primitive.ObjectID
+	Backward    bool // the page before the position instead of the one after it, still in listing order
+}
+
+// cursorDateLayout keeps the cursor short, belongs_date never carries a time of day
+const cursorDateLayout = "20060102"
+
+// IsZero tells whether no cursor is set
+func (cursor CashFlowCursor) IsZero() bool {
+	return cursor.Id.IsZero()
+}
+
+// IsAfter tells whether a record of this date and id comes after the position in the ascending order
+func (cursor CashFlowCursor) IsAfter(belongsDate time.Time, id primitive.ObjectID) bool {
+	if !belongsDate.Equal(cursor.BelongsDate) {
+		return belongsDate.After(cursor.BelongsDate)
+	}
+	return id.Hex() > cursor.Id.Hex()
+}
+
+// Encode turns the cursor into an opaque token that is safe in a URL
+func (cursor CashFlowCursor) Encode() string {
+	direction := "n"
+	if cursor.Backward {
+		direction = "p"
+	}
+	return base64.RawURLEncoding.EncodeToString(
+		[]byte(direction + "|" + cursor.BelongsDate.Format(cursorDateLayout) + "|" + cursor.Id.Hex()))
+}
+
+// DecodeCashFlowCursor reads back a token made by Encode
+func DecodeCashFlowCursor(token string) (CashFlowCursor, error) {
+	plainToken, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return CashFlowCursor{}, errors.New("invalid cursor")
+	}
+	partList := strings.Split(string(plainToken), "|")
+	if len(partList) != 3 || (partList[0] != "n" && partList[0] != "p") {
+		return CashFlowCursor{}, errors.New("invalid cursor")
+	}
+	belongsDate, err := time.Parse(cursorDateLayout, partList[1])
+	if err != nil {
+		return CashFlowCursor{}, errors.New("invalid cursor")
+	}
+	id, err := primitive.ObjectIDFromHex(partList[2])
+	if err != nil || id.IsZero() {
+		return CashFlowCursor{}, errors.New("invalid cursor")
+	}
+	return CashFlowCursor{BelongsDate: belongsDate, Id: id, Backward: partList[0] == "p"}, nil
+}
+
+// CashFlowPage is one page of a cash flow listing, a cursor is blank when no page follows in that direction
+type CashFlowPage struct {
+	Data       []CashFlowEntity `json:"data"`
+	TotalCount int64            `json:"total_count"`
+	Limit      int              `json:"limit"`
+	Offset     int              `json:"offset"`
+	NextCursor string           `json:"next_cursor"`
+	PrevCursor string           `json:"prev_cursor"`
+}
+
+// TextTerms splits the search text into lowercase words, punctuation separates words and is dropped
+func (querySpec CashFlowQuerySpec) TextTerms() []string {
+	return strings.FieldsFunc(strings.ToLower(querySpec.Text), func(character rune) bool {
+		return !unicode.IsLetter(character) && !unicode.IsDigit(character)
+	})
+}
+
+// CashFlowSearchDTO is a search request by names, blank fields and zero amounts are not applied
+type CashFlowSearchDTO struct {
+	Text         string          `json:"text"`
+	FromDate     string          `json:"from_date"`
+	ToDate       string          `json:"to_date"`
+	MinAmount    decimal.Decimal `json:"min_amount"`
+	MaxAmount    decimal.Decimal `json:"max_amount"`
+	FlowType     string          `json:"flow_type"`
+	CategoryName string          `json:"category_name"` // the category and everything below it
+	Tags         []string        `json:"tags"`
+	AccountName  string          `json:"account_name"`
+	SortBy       string          `json:"sort_by"`
+	Order        string          `json:"order"` // asc or desc, desc by default
+	Limit        int             `json:"limit"`
+	Offset       int             `json:"offset"`
+	Cursor       string          `json:"cursor"` // next_cursor or prev_cursor of an earlier page, instead of the offset
+}
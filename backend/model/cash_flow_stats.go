@@ -0,0 +1,50 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CashFlowTypeStat is the aggregated count and amount of one flow type in one currency,
+// Currency is blank for records saved before currencies existed
+type CashFlowTypeStat struct {
+	FlowType    string          `json:"flow_type" bson:"flow_type"`
+	Currency    string          `json:"currency" bson:"currency"`
+	Count       int64           `json:"count" bson:"count"`
+	TotalAmount decimal.Decimal `json:"total_amount" bson:"total_amount"`
+}
+
+// CashFlowCategoryStat is the aggregated count and amount of one category in one currency
+type CashFlowCategoryStat struct {
+	CategoryId  primitive.ObjectID `json:"category_id" bson:"category_id"`
+	Currency    string             `json:"currency" bson:"currency"`
+	Count       int64              `json:"count" bson:"count"`
+	TotalAmount decimal.Decimal    `json:"total_amount" bson:"total_amount"`
+}
+
+// CashFlowSummaryStat is the aggregated count and amount of one flow type within one category in one currency
+type CashFlowSummaryStat struct {
+	FlowType     string             `json:"flow_type" bson:"flow_type"`
+	Currency     string             `json:"currency" bson:"currency"`
+	CategoryId   primitive.ObjectID `json:"category_id" bson:"category_id"`
+	CategoryName string             `json:"category_name" bson:"category_name"`
+	Count        int64              `json:"count" bson:"count"`
+	TotalAmount  decimal.Decimal    `json:"total_amount" bson:"total_amount"`
+}
+
+// CashFlowAccountStat is the aggregated count and amount of one flow type within one account
+type CashFlowAccountStat struct {
+	AccountId   primitive.ObjectID `json:"account_id" bson:"account_id"`
+	FlowType    string             `json:"flow_type" bson:"flow_type"`
+	Count       int64              `json:"count" bson:"count"`
+	TotalAmount decimal.Decimal    `json:"total_amount" bson:"total_amount"`
+}
+
+// CashFlowTagSummaryStat is the aggregated count and amount of one flow type within one tag in one currency
+type CashFlowTagSummaryStat struct {
+	FlowType    string          `json:"flow_type" bson:"flow_type"`
+	Currency    string          `json:"currency" bson:"currency"`
+	Tag         string          `json:"tag" bson:"tag"`
+	Count       int64           `json:"count" bson:"count"`
+	TotalAmount decimal.Decimal `json:"total_amount" bson:"total_amount"`
+}
@@ -4,11 +4,13 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/macar-x/cashlens/util"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type CategoryEntity struct {
 	Id         primitive.ObjectID `bson:"_id,omitempty"`
+	OwnerId    primitive.ObjectID `json:"owner_id" bson:"owner_id"` // the ledger it is kept in, names are unique per ledger
 	ParentId   primitive.ObjectID `json:"parent_id" bson:"parent_id"`
 	Name       string             `json:"name" bson:"name"`
 	Remark     string             `json:"remark" bson:"remark"`
@@ -20,6 +22,11 @@ func (entity CategoryEntity) IsEmpty() bool {
 	return reflect.DeepEqual(entity, CategoryEntity{})
 }
 
+// IsOwnedBy tells whether the record belongs to the owner, a blank owner matches the records no user owns
+func (entity CategoryEntity) IsOwnedBy(ownerPlainId string) bool {
+	return entity.OwnerId == util.ConvertOwner2ObjectId(ownerPlainId)
+}
+
 func (entity CategoryEntity) ToString() string {
 	return "[ " +
 		"Id: " + entity.Id.Hex() +
@@ -0,0 +1,23 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+type CategoryRuleDTO struct {
+	Name         string `json:"name"`
+	CategoryName string `json:"category_name"`
+	// Priority is a pointer because zero is a valid priority, nil leaves it unchanged on update
+	Priority   *int                      `json:"priority"`
+	Conditions *CategoryRuleConditionDTO `json:"conditions"` // nil keeps the conditions on update
+	Tags       []string                  `json:"tags"`       // nil keeps the tags on update, empty clears them
+}
+
+// CategoryRuleConditionDTO holds the conditions of a rule, blank ones match anything
+type CategoryRuleConditionDTO struct {
+	FlowType    string          `json:"flow_type"`
+	MatchType   string          `json:"match_type"`
+	Pattern     string          `json:"pattern"`
+	MinAmount   decimal.Decimal `json:"min_amount"`
+	MaxAmount   decimal.Decimal `json:"max_amount"`
+	PayeeName   string          `json:"payee_name"`
+	AccountName string          `json:"account_name"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CategoryRuleEntity picks the category and tags of a cash_flow saved or imported without a category.
+// Every condition left blank matches anything, and all the set ones have to match.
+type CategoryRuleEntity struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty"`
+	OwnerId    primitive.ObjectID `json:"owner_id" bson:"owner_id"` // the ledger it is kept in, names are unique per ledger
+	Name       string             `json:"name" bson:"name"`
+	Priority   int                `json:"priority" bson:"priority"`       // higher is tried first, ties by name
+	FlowType   string             `json:"flow_type" bson:"flow_type"`     // INCOME or OUTCOME, blank for both
+	MatchType  string             `json:"match_type" bson:"match_type"`   // CONTAINS or REGEX on the description, blank when not checked
+	Pattern    string             `json:"pattern" bson:"pattern"`         // blank when the description is not checked
+	MinAmount  decimal.Decimal    `json:"min_amount" bson:"min_amount"`   // zero when there is no lower bound
+	MaxAmount  decimal.Decimal    `json:"max_amount" bson:"max_amount"`   // zero when there is no upper bound
+	PayeeId    primitive.ObjectID `json:"payee_id" bson:"payee_id"`       // nil matches any payee
+	AccountId  primitive.ObjectID `json:"account_id" bson:"account_id"`   // nil matches any account
+	CategoryId primitive.ObjectID `json:"category_id" bson:"category_id"` // category given to the matching cash_flows
+	Tags       []string           `json:"tags" bson:"tags"`               // added to the matching cash_flows, nil when none
+	CreateTime time.Time          `json:"create_time" bson:"create_time"`
+	ModifyTime time.Time          `json:"modify_time" bson:"modify_time"`
+}
+
+func (entity CategoryRuleEntity) IsEmpty() bool {
+	return reflect.DeepEqual(entity, CategoryRuleEntity{})
+}
+
+// IsOwnedBy tells whether the record belongs to the owner, a blank owner matches the records no user owns
+func (entity CategoryRuleEntity) IsOwnedBy(ownerPlainId string) bool {
+	return entity.OwnerId == util.ConvertOwner2ObjectId(ownerPlainId)
+}
+
+func (entity CategoryRuleEntity) ToString() string {
+	return "[ " +
+		"Id: " + entity.Id.Hex() +
+		", Name: " + entity.Name +
+		", Priority: " + strconv.Itoa(entity.Priority) +
+		", FlowType: " + entity.FlowType +
+		", Description: " + entity.MatchType + " " + entity.Pattern +
+		", MinAmount: " + entity.MinAmount.StringFixed(2) +
+		", MaxAmount: " + entity.MaxAmount.StringFixed(2) +
+		", PayeeId: " + entity.PayeeId.Hex() +
+		", AccountId: " + entity.AccountId.Hex() +
+		", CategoryId: " + entity.CategoryId.Hex() +
+		", Tags: " + strings.Join(entity.Tags, ",") +
+		" ]"
+}
@@ -2,8 +2,44 @@ package model
 
 // FlowType constants for cash flow types
 const (
-	FlowTypeIncome  = "INCOME"
-	FlowTypeOutcome = "OUTCOME"
+	FlowTypeIncome   = "INCOME"
+	FlowTypeOutcome  = "OUTCOME"
+	FlowTypeTransfer = "TRANSFER" // money moved between two accounts, signed by direction
+)
+
+// AccountType constants for where the money is kept
+const (
+	AccountTypeBank  = "BANK"
+	AccountTypeCard  = "CARD"
+	AccountTypeCash  = "CASH"
+	AccountTypeOther = "OTHER"
+)
+
+// Frequency constants for how often a recurring rule repeats
+const (
+	FrequencyDaily           = "DAILY"
+	FrequencyWeekly          = "WEEKLY"            // same weekday as the start date
+	FrequencyMonthly         = "MONTHLY"           // on the day of month, or the month's last day when shorter
+	FrequencyLastBusinessDay = "LAST_BUSINESS_DAY" // last Monday to Friday of the month
+)
+
+// BudgetPeriod constants for how long one budget limit lasts
+const (
+	BudgetPeriodMonthly = "MONTHLY"
+	BudgetPeriodYearly  = "YEARLY"
+)
+
+// PayeeRule match types for recognising a payee in a description
+const (
+	PayeeRuleContains = "CONTAINS" // case-insensitive substring
+	PayeeRuleRegex    = "REGEX"    // Go regular expression, add (?i) to ignore case
+)
+
+// LedgerRole constants for what a member may do in a shared ledger
+const (
+	LedgerRoleOwner  = "OWNER"  // edits records, and invites, changes and removes members
+	LedgerRoleEditor = "EDITOR" // reads and edits records
+	LedgerRoleViewer = "VIEWER" // only reads
 )
 
 // DateFormat constants
@@ -18,4 +54,12 @@ const (
 const (
 	TableCashFlow = "cash_flow"
 	TableCategory = "category"
+	TableAccount  = "account"
+
+	TableExchangeRate  = "exchange_rate"
+	TableRecurringRule = "recurring_rule"
+	TableBudget        = "budget"
+	TableGoal          = "goal"
+	TablePayee         = "payee"
+	TableCategoryRule  = "category_rule"
 )
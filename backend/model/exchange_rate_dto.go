@@ -0,0 +1,10 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+type ExchangeRateDTO struct {
+	FromCurrency  string          `json:"from_currency"`
+	ToCurrency    string          `json:"to_currency"`
+	Rate          decimal.Decimal `json:"rate"`
+	EffectiveDate string          `json:"effective_date"`
+}
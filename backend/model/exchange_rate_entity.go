@@ -0,0 +1,41 @@
+package model
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExchangeRateEntity says how many ToCurrency one FromCurrency buys,
+// from EffectiveDate until the next rate of the same pair takes over.
+type ExchangeRateEntity struct {
+	Id            primitive.ObjectID `bson:"_id,omitempty"`
+	OwnerId       primitive.ObjectID `json:"owner_id" bson:"owner_id"` // the ledger it is kept in, nil when no user owns it
+	FromCurrency  string             `json:"from_currency" bson:"from_currency"`
+	ToCurrency    string             `json:"to_currency" bson:"to_currency"`
+	Rate          decimal.Decimal    `json:"rate" bson:"rate"`
+	EffectiveDate time.Time          `json:"effective_date" bson:"effective_date"`
+	CreateTime    time.Time          `json:"create_time" bson:"create_time"`
+	ModifyTime    time.Time          `json:"modify_time" bson:"modify_time"`
+}
+
+func (entity ExchangeRateEntity) IsEmpty() bool {
+	return reflect.DeepEqual(entity, ExchangeRateEntity{})
+}
+
+// IsOwnedBy tells whether the record belongs to the owner, a blank owner matches the records no user owns
+func (entity ExchangeRateEntity) IsOwnedBy(ownerPlainId string) bool {
+	return entity.OwnerId == util.ConvertOwner2ObjectId(ownerPlainId)
+}
+
+func (entity ExchangeRateEntity) ToString() string {
+	return "[ " +
+		"Id: " + entity.Id.Hex() +
+		", Pair: " + entity.FromCurrency + "/" + entity.ToCurrency +
+		", Rate: " + entity.Rate.String() +
+		", EffectiveDate: " + util.FormatDateToStringWithDash(entity.EffectiveDate) +
+		" ]"
+}
@@ -0,0 +1,11 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+type GoalDTO struct {
+	Name         string          `json:"name"`
+	AccountName  string          `json:"account_name"`
+	TargetAmount decimal.Decimal `json:"target_amount"`
+	StartDate    string          `json:"start_date"`
+	Deadline     string          `json:"deadline"`
+}
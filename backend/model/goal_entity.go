@@ -0,0 +1,46 @@
+package model
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GoalEntity is an amount to save by a deadline. Every cash_flow booked to its account
+// from the start date on is a contribution, outcomes and transfers out count against it.
+type GoalEntity struct {
+	Id           primitive.ObjectID `bson:"_id,omitempty"`
+	OwnerId      primitive.ObjectID `json:"owner_id" bson:"owner_id"` // the ledger it is kept in, names are unique per ledger
+	Name         string             `json:"name" bson:"name"`
+	AccountId    primitive.ObjectID `json:"account_id" bson:"account_id"`
+	TargetAmount decimal.Decimal    `json:"target_amount" bson:"target_amount"`
+	Currency     string             `json:"currency" bson:"currency"`
+	StartDate    time.Time          `json:"start_date" bson:"start_date"`
+	Deadline     time.Time          `json:"deadline" bson:"deadline"`
+	CreateTime   time.Time          `json:"create_time" bson:"create_time"`
+	ModifyTime   time.Time          `json:"modify_time" bson:"modify_time"`
+}
+
+func (entity GoalEntity) IsEmpty() bool {
+	return reflect.DeepEqual(entity, GoalEntity{})
+}
+
+// IsOwnedBy tells whether the record belongs to the owner, a blank owner matches the records no user owns
+func (entity GoalEntity) IsOwnedBy(ownerPlainId string) bool {
+	return entity.OwnerId == util.ConvertOwner2ObjectId(ownerPlainId)
+}
+
+func (entity GoalEntity) ToString() string {
+	return "[ " +
+		"Id: " + entity.Id.Hex() +
+		", Name: " + entity.Name +
+		", AccountId: " + entity.AccountId.Hex() +
+		", TargetAmount: " + entity.TargetAmount.StringFixed(2) +
+		", Currency: " + entity.Currency +
+		", StartDate: " + util.FormatDateToStringWithDash(entity.StartDate) +
+		", Deadline: " + util.FormatDateToStringWithDash(entity.Deadline) +
+		" ]"
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+type LedgerDTO struct {
+	Name string `json:"name"`
+}
+
+// LedgerRoleDTO carries the role of an invitation or of a member being changed
+type LedgerRoleDTO struct {
+	Role string `json:"role"`
+}
+
+type LedgerInvitationAcceptDTO struct {
+	Token string `json:"token"`
+}
+
+// LedgerView is a ledger as one of its members sees it
+type LedgerView struct {
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	Role       string `json:"role"`        // the member's role
+	IsPersonal bool   `json:"is_personal"` // the member's own ledger, which cannot be shared
+}
+
+// LedgerMemberView is a member of a ledger with their username
+type LedgerMemberView struct {
+	UserId   string `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// LedgerInvitationView is what a new invitation hands back, the token is shown only this once
+type LedgerInvitationView struct {
+	Id         string    `json:"id"`
+	LedgerId   string    `json:"ledger_id"`
+	Role       string    `json:"role"`
+	Token      string    `json:"token"`
+	ExpireTime time.Time `json:"expire_time"`
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LedgerEntity is a set of cash flows, categories and budgets that several users keep together.
+// Every user also has a personal ledger that is not stored: its id is the user's own id.
+type LedgerEntity struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty"`
+	Name       string             `json:"name" bson:"name"`
+	CreateTime time.Time          `json:"create_time" bson:"create_time"`
+	ModifyTime time.Time          `json:"modify_time" bson:"modify_time"`
+}
+
+// LedgerMemberEntity gives a user a role in a shared ledger
+type LedgerMemberEntity struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty"`
+	LedgerId   primitive.ObjectID `json:"ledger_id" bson:"ledger_id"`
+	UserId     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Role       string             `json:"role" bson:"role"` // OWNER, EDITOR or VIEWER
+	CreateTime time.Time          `json:"create_time" bson:"create_time"`
+	ModifyTime time.Time          `json:"modify_time" bson:"modify_time"`
+}
+
+// LedgerInvitationEntity lets whoever holds its token join the ledger once, before it expires.
+// Only the SHA-256 hash of the token is kept, the token itself is shown once when it is created.
+type LedgerInvitationEntity struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty"`
+	LedgerId   primitive.ObjectID `json:"ledger_id" bson:"ledger_id"`
+	Role       string             `json:"role" bson:"role"` // the role the invited user joins with
+	TokenHash  string             `json:"-" bson:"token_hash"`
+	InvitedBy  primitive.ObjectID `json:"invited_by" bson:"invited_by"`
+	ExpireTime time.Time          `json:"expire_time" bson:"expire_time"`
+	CreateTime time.Time          `json:"create_time" bson:"create_time"`
+	ModifyTime time.Time          `json:"modify_time" bson:"modify_time"`
+}
+
+func (entity LedgerEntity) IsEmpty() bool {
+	return reflect.DeepEqual(entity, LedgerEntity{})
+}
+
+func (entity LedgerEntity) ToString() string {
+	return "[ " +
+		"Id: " + entity.Id.Hex() +
+		", Name: " + entity.Name +
+		" ]"
+}
+
+func (entity LedgerMemberEntity) IsEmpty() bool {
+	return reflect.DeepEqual(entity, LedgerMemberEntity{})
+}
+
+func (entity LedgerMemberEntity) ToString() string {
+	return "[ " +
+		"LedgerId: " + entity.LedgerId.Hex() +
+		", UserId: " + entity.UserId.Hex() +
+		", Role: " + entity.Role +
+		" ]"
+}
+
+func (entity LedgerInvitationEntity) IsEmpty() bool {
+	return reflect.DeepEqual(entity, LedgerInvitationEntity{})
+}
+
+func (entity LedgerInvitationEntity) ToString() string {
+	return "[ " +
+		"Id: " + entity.Id.Hex() +
+		", LedgerId: " + entity.LedgerId.Hex() +
+		", Role: " + entity.Role +
+		", ExpireTime: " + entity.ExpireTime.Format(time.RFC3339) +
+		" ]"
+}
@@ -0,0 +1,6 @@
+package model
+
+type PayeeDTO struct {
+	Name  string      `json:"name"`
+	Rules []PayeeRule `json:"rules"` // nil keeps the rules on update, empty clears them
+}
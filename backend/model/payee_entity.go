@@ -0,0 +1,48 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PayeeEntity is a merchant or person a cash_flow is paid to or received from.
+// Its rules recognise the payee in the raw description of a cash_flow, such as "STARBUCKS #1234".
+type PayeeEntity struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty"`
+	OwnerId    primitive.ObjectID `json:"owner_id" bson:"owner_id"` // the ledger it is kept in, names are unique per ledger
+	Name       string             `json:"name" bson:"name"`
+	Rules      []PayeeRule        `json:"rules" bson:"rules"` // nil when the payee is only assigned by hand
+	CreateTime time.Time          `json:"create_time" bson:"create_time"`
+	ModifyTime time.Time          `json:"modify_time" bson:"modify_time"`
+}
+
+// PayeeRule maps the descriptions it matches to its payee
+type PayeeRule struct {
+	MatchType string `json:"match_type" bson:"match_type"` // CONTAINS (case-insensitive) or REGEX
+	Pattern   string `json:"pattern" bson:"pattern"`
+}
+
+func (entity PayeeEntity) IsEmpty() bool {
+	return reflect.DeepEqual(entity, PayeeEntity{})
+}
+
+// IsOwnedBy tells whether the record belongs to the owner, a blank owner matches the records no user owns
+func (entity PayeeEntity) IsOwnedBy(ownerPlainId string) bool {
+	return entity.OwnerId == util.ConvertOwner2ObjectId(ownerPlainId)
+}
+
+func (entity PayeeEntity) ToString() string {
+	ruleList := make([]string, len(entity.Rules))
+	for i, rule := range entity.Rules {
+		ruleList[i] = rule.MatchType + " " + rule.Pattern
+	}
+	return "[ " +
+		"Id: " + entity.Id.Hex() +
+		", Name: " + entity.Name +
+		", Rules: " + strings.Join(ruleList, "; ") +
+		" ]"
+}
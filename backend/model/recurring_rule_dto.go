@@ -0,0 +1,19 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+type RecurringRuleDTO struct {
+	Name         string          `json:"name"`
+	FlowType     string          `json:"flow_type"`
+	CategoryName string          `json:"category_name"`
+	AccountName  string          `json:"account_name"`
+	Amount       decimal.Decimal `json:"amount"`
+	Currency     string          `json:"currency"`
+	Description  string          `json:"description"`
+	Frequency    string          `json:"frequency"`
+	DayOfMonth   int             `json:"day_of_month"`
+	StartDate    string          `json:"start_date"`
+	EndDate      string          `json:"end_date"`
+	// OccurrenceLimit is a pointer because zero means unlimited, nil leaves it unchanged on update
+	OccurrenceLimit *int `json:"occurrence_limit"`
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RecurringRuleEntity is an income or outcome that repeats on a schedule,
+// each occurrence up to today is booked as one cash_flow.
+type RecurringRuleEntity struct {
+	Id              primitive.ObjectID `bson:"_id,omitempty"`
+	OwnerId         primitive.ObjectID `json:"owner_id" bson:"owner_id"` // the ledger it is kept in, names are unique per ledger
+	Name            string             `json:"name" bson:"name"`
+	FlowType        string             `json:"flow_type" bson:"flow_type"`
+	CategoryId      primitive.ObjectID `json:"category_id" bson:"category_id"`
+	AccountId       primitive.ObjectID `json:"account_id" bson:"account_id"`
+	Amount          decimal.Decimal    `json:"amount" bson:"amount"`
+	Currency        string             `json:"currency" bson:"currency"`
+	Description     string             `json:"description" bson:"description"`
+	Frequency       string             `json:"frequency" bson:"frequency"`
+	DayOfMonth      int                `json:"day_of_month" bson:"day_of_month"` // MONTHLY only
+	StartDate       time.Time          `json:"start_date" bson:"start_date"`
+	EndDate         time.Time          `json:"end_date" bson:"end_date"`                 // zero when open-ended
+	OccurrenceLimit int                `json:"occurrence_limit" bson:"occurrence_limit"` // zero when unlimited
+	OccurrenceCount int                `json:"occurrence_count" bson:"occurrence_count"`
+	LastRunDate     time.Time          `json:"last_run_date" bson:"last_run_date"` // latest occurrence booked, zero before the first
+	CreateTime      time.Time          `json:"create_time" bson:"create_time"`
+	ModifyTime      time.Time          `json:"modify_time" bson:"modify_time"`
+}
+
+func (entity RecurringRuleEntity) IsEmpty() bool {
+	return reflect.DeepEqual(entity, RecurringRuleEntity{})
+}
+
+// IsOwnedBy tells whether the record belongs to the owner, a blank owner matches the records no user owns
+func (entity RecurringRuleEntity) IsOwnedBy(ownerPlainId string) bool {
+	return entity.OwnerId == util.ConvertOwner2ObjectId(ownerPlainId)
+}
+
+func (entity RecurringRuleEntity) ToString() string {
+	schedule := entity.Frequency
+	if entity.Frequency == FrequencyMonthly {
+		schedule += " on day " + strconv.Itoa(entity.DayOfMonth)
+	}
+	schedule += " from " + util.FormatDateToStringWithDash(entity.StartDate)
+	if !entity.EndDate.IsZero() {
+		schedule += " until " + util.FormatDateToStringWithDash(entity.EndDate)
+	}
+	if entity.OccurrenceLimit > 0 {
+		schedule += " for " + strconv.Itoa(entity.OccurrenceLimit) + " times"
+	}
+
+	lastRunDate := "never"
+	if !entity.LastRunDate.IsZero() {
+		lastRunDate = util.FormatDateToStringWithDash(entity.LastRunDate)
+	}
+
+	return "[ " +
+		"Id: " + entity.Id.Hex() +
+		", Name: " + entity.Name +
+		", FlowType: " + entity.FlowType +
+		", Amount: " + entity.Amount.StringFixed(2) +
+		", Currency: " + entity.Currency +
+		", Schedule: " + schedule +
+		", Booked: " + strconv.Itoa(entity.OccurrenceCount) +
+		", LastRunDate: " + lastRunDate +
+		" ]"
+}
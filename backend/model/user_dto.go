@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type UserDTO struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type ApiTokenDTO struct {
+	Name string `json:"name"`
+}
+
+// AccessTokenDTO is what a sign-in or a new API token hands back, send it as "Authorization: Bearer <token>"
+type AccessTokenDTO struct {
+	Token      string     `json:"token"`
+	TokenType  string     `json:"token_type"`            // always Bearer
+	ExpireTime *time.Time `json:"expire_time,omitempty"` // nil for API tokens, they last until revoked
+}
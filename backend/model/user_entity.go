@@ -0,0 +1,28 @@
+package model
+
+import (
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserEntity is someone who can sign in to the API. Only the bcrypt hash of the password is kept.
+type UserEntity struct {
+	Id           primitive.ObjectID `bson:"_id,omitempty"`
+	Username     string             `json:"username" bson:"username"`
+	PasswordHash string             `json:"-" bson:"password_hash"`
+	CreateTime   time.Time          `json:"create_time" bson:"create_time"`
+	ModifyTime   time.Time          `json:"modify_time" bson:"modify_time"`
+}
+
+func (entity UserEntity) IsEmpty() bool {
+	return reflect.DeepEqual(entity, UserEntity{})
+}
+
+func (entity UserEntity) ToString() string {
+	return "[ " +
+		"Id: " + entity.Id.Hex() +
+		", Username: " + entity.Username +
+		" ]"
+}
@@ -0,0 +1,22 @@
+USE
+    `emm_moneybox`;
+
+-- -------------------
+-- Create table `account`
+-- -------------------
+DROP TABLE IF EXISTS account;
+CREATE TABLE `account`
+(
+    `id`              VARCHAR(24)    NOT NULL,
+    `name`            VARCHAR(200)   NOT NULL,
+    `type`            VARCHAR(10)    NOT NULL COMMENT 'BANK/CARD/CASH/OTHER',
+    `opening_balance` DECIMAL(19, 2) NOT NULL DEFAULT 0,
+    `remark`          VARCHAR(200)            DEFAULT NULL,
+    `create_time`     TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP(),
+    `modify_time`     TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP() ON UPDATE CURRENT_TIMESTAMP(),
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='Account Table';
+
+CREATE UNIQUE INDEX account_name_unique_index ON account (name);
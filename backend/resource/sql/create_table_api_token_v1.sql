@@ -0,0 +1,22 @@
+USE
+    `emm_moneybox`;
+
+-- ------------------------
+-- Create table `api_token`
+-- ------------------------
+DROP TABLE IF EXISTS api_token;
+CREATE TABLE `api_token`
+(
+    `id`          VARCHAR(24)  NOT NULL,
+    `user_id`     VARCHAR(24)  NOT NULL,
+    `name`        VARCHAR(100) NOT NULL,
+    `token_hash`  VARCHAR(64)  NOT NULL COMMENT 'SHA-256 OF THE TOKEN, HEX',
+    `create_time` TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP(),
+    `modify_time` TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP() ON UPDATE CURRENT_TIMESTAMP(),
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='API Token Table';
+
+CREATE UNIQUE INDEX api_token_token_hash_unique_index ON api_token (token_hash);
+CREATE INDEX api_token_user_id_index ON api_token (user_id);
@@ -0,0 +1,24 @@
+USE
+    `emm_moneybox`;
+
+-- -------------------
+-- Create table `budget`
+-- -------------------
+DROP TABLE IF EXISTS budget;
+CREATE TABLE `budget`
+(
+    `id`           VARCHAR(24)    NOT NULL,
+    `category_id`  VARCHAR(24)    NOT NULL,
+    `period`       VARCHAR(10)    NOT NULL COMMENT 'MONTHLY/YEARLY',
+    `limit_amount` DECIMAL(15, 2) NOT NULL,
+    `currency`     CHAR(3)        NOT NULL DEFAULT '',
+    `rollover`     TINYINT(1)     NOT NULL DEFAULT 0 COMMENT 'CARRY UNSPENT AMOUNTS INTO THE NEXT PERIOD',
+    `start_date`   DATE           NOT NULL COMMENT 'FIRST DAY OF THE FIRST PERIOD',
+    `create_time`  TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP(),
+    `modify_time`  TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP() ON UPDATE CURRENT_TIMESTAMP(),
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='Budget Table';
+
+CREATE UNIQUE INDEX budget_category_period_unique_index ON budget (category_id, period);
@@ -0,0 +1,20 @@
+USE
+    `emm_moneybox`;
+
+-- -----------------------------
+-- Create table `cash_flow_split`
+-- -----------------------------
+DROP TABLE IF EXISTS cash_flow_split;
+CREATE TABLE `cash_flow_split`
+(
+    `cash_flow_id` VARCHAR(24)    NOT NULL,
+    `line_no`      INT            NOT NULL COMMENT 'STARTS FROM 0, THE CASH FLOW IS COUNTED UNDER LINE 0',
+    `category_id`  VARCHAR(24)    NOT NULL,
+    `amount`       DECIMAL(15, 2) NOT NULL COMMENT 'LINES ADD UP TO THE CASH FLOW AMOUNT',
+    `description`  VARCHAR(200)   NOT NULL DEFAULT '',
+    PRIMARY KEY (`cash_flow_id`, `line_no`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='Cash Flow Split Table';
+
+CREATE INDEX cash_flow_split_category_id_index ON cash_flow_split (category_id);
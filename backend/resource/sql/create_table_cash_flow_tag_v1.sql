@@ -0,0 +1,17 @@
+USE
+    `emm_moneybox`;
+
+-- ---------------------------
+-- Create table `cash_flow_tag`
+-- ---------------------------
+DROP TABLE IF EXISTS cash_flow_tag;
+CREATE TABLE `cash_flow_tag`
+(
+    `cash_flow_id` VARCHAR(24) NOT NULL,
+    `tag`          VARCHAR(32) NOT NULL COMMENT 'LOWERCASE, LETTERS, DIGITS, - OR _',
+    PRIMARY KEY (`cash_flow_id`, `tag`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='Cash Flow Tag Table';
+
+CREATE INDEX cash_flow_tag_tag_index ON cash_flow_tag (tag);
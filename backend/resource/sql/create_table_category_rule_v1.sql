@@ -0,0 +1,29 @@
+USE
+    `emm_moneybox`;
+
+-- ----------------------------
+-- Create table `category_rule`
+-- ----------------------------
+DROP TABLE IF EXISTS category_rule;
+CREATE TABLE `category_rule`
+(
+    `id`          VARCHAR(24)    NOT NULL,
+    `name`        VARCHAR(100)   NOT NULL,
+    `priority`    INT            NOT NULL DEFAULT 0 COMMENT 'HIGHER IS TRIED FIRST',
+    `flow_type`   VARCHAR(10)    NOT NULL DEFAULT '' COMMENT 'INCOME/OUTCOME, BLANK FOR BOTH',
+    `match_type`  VARCHAR(10)    NOT NULL DEFAULT '' COMMENT 'CONTAINS/REGEX, BLANK WHEN THE DESCRIPTION IS NOT CHECKED',
+    `pattern`     VARCHAR(200)   NOT NULL DEFAULT '',
+    `min_amount`  DECIMAL(15, 2) NOT NULL DEFAULT 0 COMMENT '0 FOR NO LOWER BOUND',
+    `max_amount`  DECIMAL(15, 2) NOT NULL DEFAULT 0 COMMENT '0 FOR NO UPPER BOUND',
+    `payee_id`    VARCHAR(24)    NOT NULL DEFAULT '000000000000000000000000',
+    `account_id`  VARCHAR(24)    NOT NULL DEFAULT '000000000000000000000000',
+    `category_id` VARCHAR(24)    NOT NULL,
+    `tags`        VARCHAR(700)   NOT NULL DEFAULT '' COMMENT 'COMMA SEPARATED',
+    `create_time` TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP(),
+    `modify_time` TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP() ON UPDATE CURRENT_TIMESTAMP(),
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='Category Rule Table';
+
+CREATE UNIQUE INDEX category_rule_name_unique_index ON category_rule (name);
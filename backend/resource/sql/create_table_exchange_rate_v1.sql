@@ -0,0 +1,22 @@
+USE
+    `emm_moneybox`;
+
+-- -------------------
+-- Create table `exchange_rate`
+-- -------------------
+DROP TABLE IF EXISTS exchange_rate;
+CREATE TABLE `exchange_rate`
+(
+    `id`             VARCHAR(24)    NOT NULL,
+    `from_currency`  CHAR(3)        NOT NULL,
+    `to_currency`    CHAR(3)        NOT NULL,
+    `rate`           DECIMAL(19, 6) NOT NULL COMMENT 'TO_CURRENCY BOUGHT BY ONE FROM_CURRENCY',
+    `effective_date` DATE           NOT NULL,
+    `create_time`    TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP(),
+    `modify_time`    TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP() ON UPDATE CURRENT_TIMESTAMP(),
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='Exchange Rate Table';
+
+CREATE UNIQUE INDEX exchange_rate_pair_date_unique_index ON exchange_rate (from_currency, to_currency, effective_date);
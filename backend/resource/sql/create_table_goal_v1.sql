@@ -0,0 +1,24 @@
+USE
+    `emm_moneybox`;
+
+-- -------------------
+-- Create table `goal`
+-- -------------------
+DROP TABLE IF EXISTS goal;
+CREATE TABLE `goal`
+(
+    `id`            VARCHAR(24)    NOT NULL,
+    `name`          VARCHAR(100)   NOT NULL,
+    `account_id`    VARCHAR(24)    NOT NULL COMMENT 'ACCOUNT THE CONTRIBUTIONS ARE BOOKED TO',
+    `target_amount` DECIMAL(15, 2) NOT NULL,
+    `currency`      CHAR(3)        NOT NULL DEFAULT '',
+    `start_date`    DATE           NOT NULL COMMENT 'CONTRIBUTIONS COUNT FROM THIS DATE',
+    `deadline`      DATE           NOT NULL,
+    `create_time`   TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP(),
+    `modify_time`   TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP() ON UPDATE CURRENT_TIMESTAMP(),
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='Savings Goal Table';
+
+CREATE UNIQUE INDEX goal_name_unique_index ON goal (name);
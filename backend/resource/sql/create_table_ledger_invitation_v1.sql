@@ -0,0 +1,23 @@
+USE
+    `emm_moneybox`;
+
+-- --------------------------------
+-- Create table `ledger_invitation`
+-- --------------------------------
+DROP TABLE IF EXISTS ledger_invitation;
+CREATE TABLE `ledger_invitation`
+(
+    `id`          VARCHAR(24) NOT NULL,
+    `ledger_id`   VARCHAR(24) NOT NULL,
+    `role`        VARCHAR(10) NOT NULL COMMENT 'ROLE THE INVITED USER JOINS WITH',
+    `token_hash`  VARCHAR(64) NOT NULL COMMENT 'SHA-256 OF THE TOKEN, HEX',
+    `invited_by`  VARCHAR(24) NOT NULL,
+    `expire_time` TIMESTAMP   NOT NULL,
+    `create_time` TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP(),
+    `modify_time` TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP() ON UPDATE CURRENT_TIMESTAMP(),
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='Shared Ledger Invitation Table';
+
+CREATE UNIQUE INDEX ledger_invitation_token_hash_unique_index ON ledger_invitation (token_hash);
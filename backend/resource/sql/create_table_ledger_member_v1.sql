@@ -0,0 +1,22 @@
+USE
+    `emm_moneybox`;
+
+-- ----------------------------
+-- Create table `ledger_member`
+-- ----------------------------
+DROP TABLE IF EXISTS ledger_member;
+CREATE TABLE `ledger_member`
+(
+    `id`          VARCHAR(24) NOT NULL,
+    `ledger_id`   VARCHAR(24) NOT NULL,
+    `user_id`     VARCHAR(24) NOT NULL,
+    `role`        VARCHAR(10) NOT NULL COMMENT 'OWNER/EDITOR/VIEWER',
+    `create_time` TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP(),
+    `modify_time` TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP() ON UPDATE CURRENT_TIMESTAMP(),
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='Shared Ledger Member Table';
+
+CREATE UNIQUE INDEX ledger_member_ledger_user_unique_index ON ledger_member (ledger_id, user_id);
+CREATE INDEX ledger_member_user_id_index ON ledger_member (user_id);
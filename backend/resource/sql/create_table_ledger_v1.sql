@@ -0,0 +1,17 @@
+USE
+    `emm_moneybox`;
+
+-- ---------------------
+-- Create table `ledger`
+-- ---------------------
+DROP TABLE IF EXISTS ledger;
+CREATE TABLE `ledger`
+(
+    `id`          VARCHAR(24)  NOT NULL,
+    `name`        VARCHAR(100) NOT NULL,
+    `create_time` TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP(),
+    `modify_time` TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP() ON UPDATE CURRENT_TIMESTAMP(),
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='Shared Ledger Table';
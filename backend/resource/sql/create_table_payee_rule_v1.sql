@@ -0,0 +1,17 @@
+USE
+    `emm_moneybox`;
+
+-- -------------------------
+-- Create table `payee_rule`
+-- -------------------------
+DROP TABLE IF EXISTS payee_rule;
+CREATE TABLE `payee_rule`
+(
+    `payee_id`   VARCHAR(24)  NOT NULL,
+    `rule_no`    INT          NOT NULL COMMENT 'STARTS FROM 0, KEEPS THE ORDER OF THE RULES',
+    `match_type` VARCHAR(10)  NOT NULL COMMENT 'CONTAINS/REGEX',
+    `pattern`    VARCHAR(200) NOT NULL,
+    PRIMARY KEY (`payee_id`, `rule_no`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='Payee Rule Table';
@@ -0,0 +1,19 @@
+USE
+    `emm_moneybox`;
+
+-- --------------------
+-- Create table `payee`
+-- --------------------
+DROP TABLE IF EXISTS payee;
+CREATE TABLE `payee`
+(
+    `id`          VARCHAR(24)  NOT NULL,
+    `name`        VARCHAR(100) NOT NULL,
+    `create_time` TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP(),
+    `modify_time` TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP() ON UPDATE CURRENT_TIMESTAMP(),
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='Payee Table';
+
+CREATE UNIQUE INDEX payee_name_unique_index ON payee (name);
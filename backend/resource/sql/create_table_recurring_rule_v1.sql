@@ -0,0 +1,32 @@
+USE
+    `emm_moneybox`;
+
+-- -------------------
+-- Create table `recurring_rule`
+-- -------------------
+DROP TABLE IF EXISTS recurring_rule;
+CREATE TABLE `recurring_rule`
+(
+    `id`               VARCHAR(24)    NOT NULL,
+    `name`             VARCHAR(200)   NOT NULL,
+    `flow_type`        VARCHAR(10)    NOT NULL COMMENT 'INCOME/OUTCOME',
+    `category_id`      VARCHAR(24)    NOT NULL,
+    `account_id`       VARCHAR(24)    NOT NULL DEFAULT '000000000000000000000000',
+    `amount`           DECIMAL(15, 2) NOT NULL,
+    `currency`         CHAR(3)        NOT NULL DEFAULT '',
+    `description`      VARCHAR(500)            DEFAULT NULL,
+    `frequency`        VARCHAR(20)    NOT NULL COMMENT 'DAILY/WEEKLY/MONTHLY/LAST_BUSINESS_DAY',
+    `day_of_month`     TINYINT        NOT NULL DEFAULT 0 COMMENT 'MONTHLY ONLY, CLAMPED TO THE LAST DAY OF SHORTER MONTHS',
+    `start_date`       DATE           NOT NULL,
+    `end_date`         DATE                    DEFAULT NULL COMMENT 'NULL WHEN OPEN-ENDED',
+    `occurrence_limit` INT            NOT NULL DEFAULT 0 COMMENT '0 FOR UNLIMITED',
+    `occurrence_count` INT            NOT NULL DEFAULT 0,
+    `last_run_date`    DATE                    DEFAULT NULL COMMENT 'LATEST OCCURRENCE BOOKED',
+    `create_time`      TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP(),
+    `modify_time`      TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP() ON UPDATE CURRENT_TIMESTAMP(),
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='Recurring Rule Table';
+
+CREATE UNIQUE INDEX recurring_rule_name_unique_index ON recurring_rule (name);
@@ -0,0 +1,20 @@
+USE
+    `emm_moneybox`;
+
+-- -------------------
+-- Create table `user`
+-- -------------------
+DROP TABLE IF EXISTS user;
+CREATE TABLE `user`
+(
+    `id`            VARCHAR(24) NOT NULL,
+    `username`      VARCHAR(50) NOT NULL,
+    `password_hash` VARCHAR(60) NOT NULL COMMENT 'BCRYPT',
+    `create_time`   TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP(),
+    `modify_time`   TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP() ON UPDATE CURRENT_TIMESTAMP(),
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = UTF8MB4
+    COMMENT ='User Table';
+
+CREATE UNIQUE INDEX user_username_unique_index ON user (username);
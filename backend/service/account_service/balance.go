@@ -0,0 +1,91 @@
+package account_service
+
+import (
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccountBalance is what an account holds: its opening balance plus every income minus every outcome,
+// plus whatever was transferred in and minus whatever was transferred out
+type AccountBalance struct {
+	AccountId      string          `json:"account_id"`
+	AccountName    string          `json:"account_name"`
+	AccountType    string          `json:"account_type"`
+	Currency       string          `json:"currency"`
+	OpeningBalance decimal.Decimal `json:"opening_balance"`
+	TotalIncome    decimal.Decimal `json:"total_income"`
+	TotalExpense   decimal.Decimal `json:"total_expense"`
+	NetTransfer    decimal.Decimal `json:"net_transfer"`
+	CashFlowCount  int64           `json:"cash_flow_count"`
+	Balance        decimal.Decimal `json:"balance"`
+}
+
+// GetBalancesService returns the balance of every account in the owner's ledger, ordered by account name.
+// Totals are aggregated by the database, no cash_flow is loaded into memory.
+func GetBalancesService(ownerPlainId string) ([]AccountBalance, error) {
+	accountStatMap := groupAccountStats(cash_flow_mapper.INSTANCE.GetCashFlowAccountStats(ownerPlainId))
+
+	accountEntityList := account_mapper.INSTANCE.GetAccountsByOwnerId(ownerPlainId, 0, 0)
+	balanceList := make([]AccountBalance, 0, len(accountEntityList))
+	for _, accountEntity := range accountEntityList {
+		balanceList = append(balanceList, buildAccountBalance(accountEntity, accountStatMap[accountEntity.Id]))
+	}
+	return balanceList, nil
+}
+
+// GetBalanceService returns the balance in the owner's ledger of the account found by either its id or its name
+func GetBalanceService(ownerPlainId, plainId, accountName string) (AccountBalance, error) {
+	accountEntity, err := QueryService(ownerPlainId, plainId, accountName)
+	if err != nil {
+		return AccountBalance{}, err
+	}
+
+	accountStatMap := groupAccountStats(cash_flow_mapper.INSTANCE.GetCashFlowAccountStats(ownerPlainId))
+	return buildAccountBalance(accountEntity, accountStatMap[accountEntity.Id]), nil
+}
+
+func groupAccountStats(accountStatList []model.CashFlowAccountStat) map[primitive.ObjectID][]model.CashFlowAccountStat {
+	accountStatMap := make(map[primitive.ObjectID][]model.CashFlowAccountStat)
+	for _, accountStat := range accountStatList {
+		accountStatMap[accountStat.AccountId] = append(accountStatMap[accountStat.AccountId], accountStat)
+	}
+	return accountStatMap
+}
+
+func buildAccountBalance(accountEntity model.AccountEntity, accountStatList []model.CashFlowAccountStat) AccountBalance {
+	totalIncome := decimal.Zero
+	totalExpense := decimal.Zero
+	netTransfer := decimal.Zero
+	var cashFlowCount int64
+	for _, accountStat := range accountStatList {
+		cashFlowCount += accountStat.Count
+		switch accountStat.FlowType {
+		case model.FlowTypeIncome:
+			totalIncome = totalIncome.Add(accountStat.TotalAmount)
+		case model.FlowTypeOutcome:
+			totalExpense = totalExpense.Add(accountStat.TotalAmount)
+		case model.FlowTypeTransfer:
+			// Transfer legs are signed, so their sum is the net amount moved in
+			netTransfer = netTransfer.Add(accountStat.TotalAmount)
+		}
+	}
+
+	balance := accountEntity.OpeningBalance.Add(totalIncome).Sub(totalExpense).Add(netTransfer)
+
+	return AccountBalance{
+		AccountId:      accountEntity.Id.Hex(),
+		AccountName:    accountEntity.Name,
+		AccountType:    accountEntity.Type,
+		Currency:       exchange_rate_service.NormalizeCurrency(accountEntity.Currency),
+		OpeningBalance: accountEntity.OpeningBalance,
+		TotalIncome:    totalIncome,
+		TotalExpense:   totalExpense,
+		NetTransfer:    netTransfer,
+		CashFlowCount:  cashFlowCount,
+		Balance:        balance,
+	}
+}
@@ -0,0 +1,58 @@
+package account_service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"github.com/shopspring/decimal"
+)
+
+// CreateService creates a new account in the owner's ledger, the type defaults to OTHER and the currency to the default one when blank
+func CreateService(ownerPlainId, accountName, accountType, currency string, openingBalance decimal.Decimal, remark string) (model.AccountEntity, error) {
+	if err := validation.ValidateAccountName(accountName); err != nil {
+		return model.AccountEntity{}, err
+	}
+
+	accountType = normalizeAccountType(accountType)
+	if err := validation.ValidateAccountType(accountType); err != nil {
+		return model.AccountEntity{}, err
+	}
+
+	currency = exchange_rate_service.NormalizeCurrency(currency)
+	if err := validation.ValidateCurrency(currency); err != nil {
+		return model.AccountEntity{}, err
+	}
+
+	if !account_mapper.INSTANCE.GetAccountByName(ownerPlainId, accountName).IsEmpty() {
+		return model.AccountEntity{}, errors.New("account already exists")
+	}
+
+	// 取小數點後兩位
+	openingBalance = openingBalance.Round(2)
+
+	newAccountPlainId := account_mapper.INSTANCE.InsertAccountByEntity(model.AccountEntity{
+		OwnerId:        util.ConvertOwner2ObjectId(ownerPlainId),
+		Name:           accountName,
+		Type:           accountType,
+		OpeningBalance: openingBalance,
+		Currency:       currency,
+		Remark:         remark,
+	})
+	if newAccountPlainId == "" {
+		return model.AccountEntity{}, errors.New("account create failed")
+	}
+	return account_mapper.INSTANCE.GetAccountByObjectId(newAccountPlainId), nil
+}
+
+func normalizeAccountType(accountType string) string {
+	accountType = strings.ToUpper(strings.TrimSpace(accountType))
+	if accountType == "" {
+		return model.AccountTypeOther
+	}
+	return accountType
+}
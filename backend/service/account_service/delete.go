@@ -0,0 +1,40 @@
+package account_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// DeleteService deletes one of the owner's accounts which no cash_flow, goal or category rule refers to
+func DeleteService(ownerPlainId, plainId string) (model.AccountEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.AccountEntity{}, err
+	}
+
+	existingAccount := account_mapper.INSTANCE.GetAccountByObjectId(plainId)
+	if existingAccount.IsEmpty() || !existingAccount.IsOwnedBy(ownerPlainId) {
+		return model.AccountEntity{}, errors.New("account not found")
+	}
+
+	if cash_flow_mapper.INSTANCE.CountCashFlowsByAccountId(plainId) != 0 {
+		return model.AccountEntity{}, errors.New("can not delete an account which has cash_flows refer to")
+	}
+	if goal_mapper.INSTANCE.CountGoalsByAccountId(plainId) != 0 {
+		return model.AccountEntity{}, errors.New("can not delete an account which has goals refer to")
+	}
+	if category_rule_mapper.INSTANCE.CountCategoryRulesByAccountId(plainId) != 0 {
+		return model.AccountEntity{}, errors.New("can not delete an account which has category rules refer to")
+	}
+
+	deletedAccount := account_mapper.INSTANCE.DeleteAccountByObjectId(plainId)
+	if deletedAccount.IsEmpty() {
+		return model.AccountEntity{}, errors.New("account delete failed")
+	}
+	return deletedAccount, nil
+}
@@ -0,0 +1,17 @@
+package account_service
+
+import (
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/model"
+)
+
+// ListAllService lists the owner's accounts with pagination
+func ListAllService(ownerPlainId string, limit, offset int) ([]model.AccountEntity, int64, error) {
+	totalCount := account_mapper.INSTANCE.CountAccountsByOwnerId(ownerPlainId)
+
+	accountEntityList := account_mapper.INSTANCE.GetAccountsByOwnerId(ownerPlainId, limit, offset)
+	if accountEntityList == nil {
+		accountEntityList = []model.AccountEntity{}
+	}
+	return accountEntityList, totalCount, nil
+}
@@ -0,0 +1,31 @@
+package account_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// QueryService finds one of the owner's accounts by either its id or its name
+func QueryService(ownerPlainId, plainId, accountName string) (model.AccountEntity, error) {
+	if (plainId == "") == (accountName == "") {
+		return model.AccountEntity{}, errors.New("should have one and only one query type")
+	}
+
+	var accountEntity model.AccountEntity
+	if plainId != "" {
+		if err := validation.ValidateID(plainId); err != nil {
+			return model.AccountEntity{}, err
+		}
+		accountEntity = account_mapper.INSTANCE.GetAccountByObjectId(plainId)
+	} else {
+		accountEntity = account_mapper.INSTANCE.GetAccountByName(ownerPlainId, accountName)
+	}
+
+	if accountEntity.IsEmpty() || !accountEntity.IsOwnedBy(ownerPlainId) {
+		return model.AccountEntity{}, errors.New("account not found")
+	}
+	return accountEntity, nil
+}
@@ -0,0 +1,165 @@
+package account_service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// resetMappers gives each test empty in-memory storage, so no database is needed
+func resetMappers() {
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	category_rule_mapper.INSTANCE = category_rule_mapper.NewCategoryRuleMemoryMapper()
+	account_mapper.INSTANCE = account_mapper.NewAccountMemoryMapper()
+	goal_mapper.INSTANCE = goal_mapper.NewGoalMemoryMapper()
+}
+
+func insertCashFlow(t *testing.T, account model.AccountEntity, flowType string, amount float64) {
+	t.Helper()
+	if cash_flow_mapper.INSTANCE.InsertCashFlowByEntity(model.CashFlowEntity{
+		AccountId:   account.Id,
+		BelongsDate: time.Date(2024, 12, 1, 0, 0, 0, 0, time.Local),
+		FlowType:    flowType,
+		Amount:      decimal.NewFromFloat(amount),
+	}) == "" {
+		t.Fatalf("insert cash_flow failed")
+	}
+}
+
+func TestAccountLifecycle(t *testing.T) {
+	resetMappers()
+
+	account, err := CreateService("", "Bank", "bank", "", decimal.NewFromInt(100), "")
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+	if account.Type != model.AccountTypeBank || account.Currency != "USD" {
+		t.Errorf("CreateService() = %+v, want type %s in the default currency", account, model.AccountTypeBank)
+	}
+	if _, err := CreateService("", "Bank", "", "", decimal.Zero, ""); err == nil {
+		t.Errorf("CreateService() with duplicated name expected error, got nil")
+	}
+	if _, err := CreateService("", "Vault", "SAFE", "", decimal.Zero, ""); err == nil {
+		t.Errorf("CreateService() with unknown type expected error, got nil")
+	}
+	if _, err := CreateService("", "Vault", "", "EURO", decimal.Zero, ""); err == nil {
+		t.Errorf("CreateService() with bad currency expected error, got nil")
+	}
+
+	updated, err := UpdateService("", account.Id.Hex(), "Savings", "", "", nil, "")
+	if err != nil || updated.Name != "Savings" || !updated.OpeningBalance.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("UpdateService() = %+v, %v, want renamed account keeping its opening balance", updated, err)
+	}
+
+	if _, err := QueryService("", account.Id.Hex(), "Savings"); err == nil {
+		t.Errorf("QueryService() with both id and name expected error, got nil")
+	}
+	if queried, err := QueryService("", "", "Savings"); err != nil || queried.Id != account.Id {
+		t.Errorf("QueryService() = %+v, %v", queried, err)
+	}
+
+	// An account in use is kept
+	insertCashFlow(t, account, model.FlowTypeOutcome, 10)
+	if _, err := DeleteService("", account.Id.Hex()); err == nil {
+		t.Errorf("DeleteService() on an account in use expected error, got nil")
+	}
+	if _, err := UpdateService("", account.Id.Hex(), "", "", "EUR", nil, ""); err == nil {
+		t.Errorf("UpdateService() changing the currency of an account in use expected error, got nil")
+	}
+
+	unused, _ := CreateService("", "Wallet", model.AccountTypeCash, "", decimal.Zero, "")
+	if updated, err := UpdateService("", unused.Id.Hex(), "", "", "eur", nil, ""); err != nil || updated.Currency != "EUR" {
+		t.Errorf("UpdateService() = %+v, %v, want the currency of an unused account changed", updated, err)
+	}
+	// An account a goal saves into is kept until the goal is gone
+	goalId := goal_mapper.INSTANCE.InsertGoalByEntity(model.GoalEntity{Name: "Trip", AccountId: unused.Id,
+		TargetAmount: decimal.NewFromInt(500), Currency: "EUR"})
+	if _, err := DeleteService("", unused.Id.Hex()); err == nil {
+		t.Errorf("DeleteService() on an account with goals expected error, got nil")
+	}
+	goal_mapper.INSTANCE.DeleteGoalByObjectId(goalId)
+	if _, err := DeleteService("", unused.Id.Hex()); err != nil {
+		t.Errorf("DeleteService() error = %v", err)
+	}
+}
+
+func TestGetBalances(t *testing.T) {
+	resetMappers()
+
+	bank, _ := CreateService("", "Bank", model.AccountTypeBank, "", decimal.NewFromInt(1000), "")
+	wallet, _ := CreateService("", "Wallet", model.AccountTypeCash, "", decimal.NewFromFloat(20.1), "")
+	insertCashFlow(t, bank, model.FlowTypeIncome, 500.25)
+	insertCashFlow(t, bank, model.FlowTypeOutcome, 100.1)
+	insertCashFlow(t, wallet, model.FlowTypeOutcome, 0.2)
+	insertCashFlow(t, bank, model.FlowTypeTransfer, -50)
+	insertCashFlow(t, wallet, model.FlowTypeTransfer, 50)
+
+	balanceList, err := GetBalancesService("")
+	if err != nil || len(balanceList) != 2 {
+		t.Fatalf("GetBalancesService() = %+v, %v", balanceList, err)
+	}
+
+	tests := []struct {
+		name          string
+		balance       AccountBalance
+		wantBalance   string
+		wantCashFlows int64
+	}{
+		{"Bank", balanceList[0], "1350.15", 3},
+		{"Wallet", balanceList[1], "69.9", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.balance.AccountName != tt.name {
+				t.Errorf("AccountName = %s, want %s", tt.balance.AccountName, tt.name)
+			}
+			if !tt.balance.Balance.Equal(decimal.RequireFromString(tt.wantBalance)) {
+				t.Errorf("Balance = %v, want %v", tt.balance.Balance, tt.wantBalance)
+			}
+			if tt.balance.CashFlowCount != tt.wantCashFlows {
+				t.Errorf("CashFlowCount = %d, want %d", tt.balance.CashFlowCount, tt.wantCashFlows)
+			}
+		})
+	}
+
+	if _, err := GetBalanceService("", "", "Unknown"); err == nil {
+		t.Errorf("GetBalanceService() with unknown account expected error, got nil")
+	}
+}
+
+func TestAccountOwners(t *testing.T) {
+	resetMappers()
+	alice, bob := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+
+	aliceAccount, err := CreateService(alice, "Wallet", model.AccountTypeCash, "", decimal.Zero, "")
+	if err != nil {
+		t.Fatalf("CreateService(alice) error = %v", err)
+	}
+	// Names are unique per owner only
+	if _, err := CreateService(bob, "Wallet", model.AccountTypeCash, "", decimal.Zero, ""); err != nil {
+		t.Errorf("CreateService(bob) with alice's account name error = %v", err)
+	}
+
+	if _, err := QueryService(bob, aliceAccount.Id.Hex(), ""); err == nil {
+		t.Errorf("QueryService(bob) found alice's account")
+	}
+	if _, err := UpdateService(bob, aliceAccount.Id.Hex(), "Stolen", "", "", nil, ""); err == nil {
+		t.Errorf("UpdateService(bob) changed alice's account")
+	}
+	if _, err := DeleteService(bob, aliceAccount.Id.Hex()); err == nil {
+		t.Errorf("DeleteService(bob) deleted alice's account")
+	}
+	if _, totalCount, _ := ListAllService(bob, 0, 0); totalCount != 1 {
+		t.Errorf("ListAllService(bob) counted %d accounts, want only his own", totalCount)
+	}
+	if balanceList, _ := GetBalancesService(alice); len(balanceList) != 1 || balanceList[0].AccountId != aliceAccount.Id.Hex() {
+		t.Errorf("GetBalancesService(alice) = %+v, want only her account", balanceList)
+	}
+}
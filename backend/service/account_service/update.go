@@ -0,0 +1,70 @@
+package account_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/validation"
+	"github.com/shopspring/decimal"
+)
+
+// UpdateService updates one of the owner's accounts by ID, blank fields are kept.
+// openingBalance is a pointer because zero is a valid new balance.
+func UpdateService(ownerPlainId, plainId, accountName, accountType, currency string, openingBalance *decimal.Decimal, remark string) (model.AccountEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.AccountEntity{}, err
+	}
+
+	existingAccount := account_mapper.INSTANCE.GetAccountByObjectId(plainId)
+	if existingAccount.IsEmpty() || !existingAccount.IsOwnedBy(ownerPlainId) {
+		return model.AccountEntity{}, errors.New("account not found")
+	}
+
+	if accountName != "" && accountName != existingAccount.Name {
+		if err := validation.ValidateAccountName(accountName); err != nil {
+			return model.AccountEntity{}, err
+		}
+		if !account_mapper.INSTANCE.GetAccountByName(ownerPlainId, accountName).IsEmpty() {
+			return model.AccountEntity{}, errors.New("account already exists")
+		}
+		existingAccount.Name = accountName
+	}
+
+	if accountType != "" {
+		accountType = normalizeAccountType(accountType)
+		if err := validation.ValidateAccountType(accountType); err != nil {
+			return model.AccountEntity{}, err
+		}
+		existingAccount.Type = accountType
+	}
+
+	// The records of an account are in its currency, so it is fixed once any record refers to it
+	if currency != "" {
+		currency = exchange_rate_service.NormalizeCurrency(currency)
+		if err := validation.ValidateCurrency(currency); err != nil {
+			return model.AccountEntity{}, err
+		}
+		if currency != exchange_rate_service.NormalizeCurrency(existingAccount.Currency) &&
+			cash_flow_mapper.INSTANCE.CountCashFlowsByAccountId(plainId) != 0 {
+			return model.AccountEntity{}, errors.New("can not change the currency of an account which has cash_flows refer to")
+		}
+		existingAccount.Currency = currency
+	}
+
+	if openingBalance != nil {
+		existingAccount.OpeningBalance = openingBalance.Round(2)
+	}
+
+	if remark != "" {
+		existingAccount.Remark = remark
+	}
+
+	updatedEntity := account_mapper.INSTANCE.UpdateAccountByEntity(plainId, existingAccount)
+	if updatedEntity.IsEmpty() {
+		return model.AccountEntity{}, errors.New("failed to update account")
+	}
+	return updatedEntity, nil
+}
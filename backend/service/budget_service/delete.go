@@ -0,0 +1,27 @@
+package budget_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/budget_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// DeleteService deletes one of the owner's budgets, the cash flows it tracked are not touched
+func DeleteService(ownerPlainId, plainId string) (model.BudgetEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.BudgetEntity{}, err
+	}
+
+	existEntity := budget_mapper.INSTANCE.GetBudgetByObjectId(plainId)
+	if existEntity.IsEmpty() || !existEntity.IsOwnedBy(ownerPlainId) {
+		return model.BudgetEntity{}, errors.New("budget not found")
+	}
+
+	deletedEntity := budget_mapper.INSTANCE.DeleteBudgetByObjectId(plainId)
+	if deletedEntity.IsEmpty() {
+		return model.BudgetEntity{}, errors.New("budget delete failed")
+	}
+	return deletedEntity, nil
+}
@@ -0,0 +1,191 @@
+package budget_service
+
+import (
+	"testing"
+
+	"github.com/macar-x/cashlens/mapper/budget_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// resetMappers gives each test empty in-memory storage with Food > Groceries and Rent categories
+func resetMappers(t *testing.T) map[string]primitive.ObjectID {
+	t.Helper()
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	exchange_rate_mapper.INSTANCE = exchange_rate_mapper.NewExchangeRateMemoryMapper()
+	budget_mapper.INSTANCE = budget_mapper.NewBudgetMemoryMapper()
+
+	categoryIds := make(map[string]primitive.ObjectID)
+	for _, category := range []struct{ name, parent string }{{"Food", ""}, {"Groceries", "Food"}, {"Rent", ""}} {
+		plainId := category_mapper.INSTANCE.InsertCategoryByEntity(model.CategoryEntity{
+			Name:     category.name,
+			ParentId: categoryIds[category.parent],
+		})
+		if plainId == "" {
+			t.Fatalf("insert category %q failed", category.name)
+		}
+		categoryIds[category.name] = util.Convert2ObjectId(plainId)
+	}
+	return categoryIds
+}
+
+func bookOutcome(t *testing.T, categoryId primitive.ObjectID, belongsDate, amount, currency string) {
+	t.Helper()
+	plainId := cash_flow_mapper.INSTANCE.InsertCashFlowByEntity(model.CashFlowEntity{
+		CategoryId:  categoryId,
+		BelongsDate: util.FormatDateFromStringWithDash(belongsDate),
+		FlowType:    model.FlowTypeOutcome,
+		Amount:      decimal.RequireFromString(amount),
+		Currency:    currency,
+	})
+	if plainId == "" {
+		t.Fatalf("insert cash_flow on %s failed", belongsDate)
+	}
+}
+
+func TestSetService(t *testing.T) {
+	resetMappers(t)
+
+	created, err := SetService("", model.BudgetDTO{
+		CategoryName: "Food",
+		Limit:        decimal.RequireFromString("300"),
+		Currency:     "usd",
+		StartDate:    "2024-03-15",
+	})
+	if err != nil {
+		t.Fatalf("SetService() error = %v", err)
+	}
+	if created.Period != model.BudgetPeriodMonthly || created.Currency != "USD" ||
+		util.FormatDateToStringWithDash(created.StartDate) != "2024-03-01" {
+		t.Errorf("SetService() = %+v", created)
+	}
+
+	// Setting the same category and period again replaces the limit and keeps the start date
+	replaced, err := SetService("", model.BudgetDTO{CategoryName: "Food", Period: "monthly",
+		Limit: decimal.RequireFromString("350"), Currency: "USD", Rollover: true})
+	if err != nil || replaced.Id != created.Id || !replaced.Limit.Equal(decimal.NewFromInt(350)) ||
+		!replaced.Rollover || !replaced.StartDate.Equal(created.StartDate) {
+		t.Errorf("SetService() = %+v, %v, want the existing budget replaced", replaced, err)
+	}
+	if count := budget_mapper.INSTANCE.CountAllBudgets(); count != 1 {
+		t.Errorf("CountAllBudgets() = %d, want 1", count)
+	}
+
+	tests := []struct {
+		name      string
+		budgetDTO model.BudgetDTO
+	}{
+		{"unknown category", model.BudgetDTO{CategoryName: "Travel", Limit: decimal.NewFromInt(100)}},
+		{"unknown period", model.BudgetDTO{CategoryName: "Food", Period: "WEEKLY", Limit: decimal.NewFromInt(100)}},
+		{"zero limit", model.BudgetDTO{CategoryName: "Food"}},
+		{"invalid currency", model.BudgetDTO{CategoryName: "Food", Limit: decimal.NewFromInt(100), Currency: "DOLLAR"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := SetService("", tt.budgetDTO); err == nil {
+				t.Errorf("SetService() expected an error")
+			}
+		})
+	}
+}
+
+func TestStatusService(t *testing.T) {
+	categoryIds := resetMappers(t)
+
+	if _, err := SetService("", model.BudgetDTO{CategoryName: "Food", Limit: decimal.NewFromInt(300),
+		Currency: "USD", Rollover: true, StartDate: "2024-01-01"}); err != nil {
+		t.Fatalf("SetService() error = %v", err)
+	}
+	if _, err := SetService("", model.BudgetDTO{CategoryName: "Rent", Limit: decimal.NewFromInt(1000),
+		Currency: "USD", StartDate: "2024-01-01"}); err != nil {
+		t.Fatalf("SetService() error = %v", err)
+	}
+	if exchange_rate_mapper.INSTANCE.InsertExchangeRateByEntity(model.ExchangeRateEntity{FromCurrency: "EUR",
+		ToCurrency: "USD", Rate: decimal.RequireFromString("1.1"), EffectiveDate: util.FormatDateFromStringWithDash("2024-01-01")}) == "" {
+		t.Fatal("insert exchange rate failed")
+	}
+
+	// January leaves 100 unspent, February overspends by 50, so only January rolls into February
+	bookOutcome(t, categoryIds["Food"], "2024-01-10", "200", "USD")
+	bookOutcome(t, categoryIds["Groceries"], "2024-02-05", "400", "USD")
+	bookOutcome(t, categoryIds["Groceries"], "2024-02-20", "50", "USD")
+	// March: groceries roll up into Food, the EUR outcome is converted
+	bookOutcome(t, categoryIds["Groceries"], "2024-03-03", "100", "EUR")
+	bookOutcome(t, categoryIds["Rent"], "2024-03-01", "1200", "USD")
+
+	tests := []struct {
+		name           string
+		date           string
+		wantRolledOver string
+		wantSpent      string
+		wantRemaining  string
+		wantPercent    string
+		wantOverspent  bool
+	}{
+		{"first period has nothing to roll over", "2024-01-31", "0", "200", "100", "66.67", false},
+		{"unspent January rolls into February", "2024-02-15", "100", "450", "-50", "112.5", true},
+		{"overspent February carries nothing", "2024-03-15", "0", "110", "190", "36.67", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statusList, err := StatusService("", "Food", tt.date)
+			if err != nil || len(statusList) != 1 {
+				t.Fatalf("StatusService() = %+v, %v, want the Food budget", statusList, err)
+			}
+			status := statusList[0]
+			if !status.RolledOver.Equal(decimal.RequireFromString(tt.wantRolledOver)) ||
+				!status.Spent.Equal(decimal.RequireFromString(tt.wantSpent)) ||
+				!status.Remaining.Equal(decimal.RequireFromString(tt.wantRemaining)) ||
+				!status.PercentUsed.Equal(decimal.RequireFromString(tt.wantPercent)) ||
+				status.Overspent != tt.wantOverspent {
+				t.Errorf("StatusService() = %+v", status)
+			}
+		})
+	}
+
+	statusList, err := StatusService("", "", "2024-03-31")
+	if err != nil || len(statusList) != 2 || statusList[1].CategoryName != "Rent" ||
+		!statusList[1].Overspent || statusList[1].PeriodEnd != "2024-03-31" {
+		t.Errorf("StatusService() = %+v, %v, want Food and an overspent Rent", statusList, err)
+	}
+
+	// Budgets are left out before they start
+	if statusList, err := StatusService("", "", "2023-12-31"); err != nil || len(statusList) != 0 {
+		t.Errorf("StatusService() = %+v, %v, want no budgets", statusList, err)
+	}
+}
+
+func TestStatusServiceWithSplits(t *testing.T) {
+	categoryIds := resetMappers(t)
+	if _, err := SetService("", model.BudgetDTO{CategoryName: "Food", Limit: decimal.NewFromInt(300),
+		Currency: "USD", StartDate: "2024-04-01"}); err != nil {
+		t.Fatalf("SetService() error = %v", err)
+	}
+
+	// Only the groceries line of the receipt is food spending
+	plainId := cash_flow_mapper.INSTANCE.InsertCashFlowByEntity(model.CashFlowEntity{
+		CategoryId:  categoryIds["Rent"],
+		BelongsDate: util.FormatDateFromStringWithDash("2024-04-10"),
+		FlowType:    model.FlowTypeOutcome,
+		Amount:      decimal.NewFromInt(100),
+		Currency:    "USD",
+		Splits: []model.CashFlowSplit{
+			{CategoryId: categoryIds["Rent"], Amount: decimal.NewFromInt(40)},
+			{CategoryId: categoryIds["Groceries"], Amount: decimal.NewFromInt(60)},
+		},
+	})
+	if plainId == "" {
+		t.Fatal("insert split cash_flow failed")
+	}
+
+	statusList, err := StatusService("", "Food", "2024-04-30")
+	if err != nil || len(statusList) != 1 || !statusList[0].Spent.Equal(decimal.NewFromInt(60)) {
+		t.Errorf("StatusService() = %+v, %v, want 60 spent", statusList, err)
+	}
+}
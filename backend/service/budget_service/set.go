@@ -0,0 +1,100 @@
+package budget_service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/budget_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SetService creates the budget of one of the owner's categories and a period, or replaces the existing one.
+// The period defaults to MONTHLY; the start date defaults to the current period for a new budget
+// and is kept for an existing one.
+func SetService(ownerPlainId string, budgetDTO model.BudgetDTO) (model.BudgetEntity, error) {
+	// 必填參數: 類別
+	if err := validation.ValidateCategoryName(budgetDTO.CategoryName); err != nil {
+		return model.BudgetEntity{}, err
+	}
+	categoryEntity := category_mapper.INSTANCE.GetCategoryByName(ownerPlainId, budgetDTO.CategoryName)
+	if categoryEntity.IsEmpty() {
+		return model.BudgetEntity{}, errors.New("category does not exist")
+	}
+
+	period := strings.ToUpper(strings.TrimSpace(budgetDTO.Period))
+	if period == "" {
+		period = model.BudgetPeriodMonthly
+	}
+	if err := validation.ValidateBudgetPeriod(period); err != nil {
+		return model.BudgetEntity{}, err
+	}
+
+	if err := validation.ValidateAmount(budgetDTO.Limit); err != nil {
+		return model.BudgetEntity{}, err
+	}
+
+	// 選填參數: 幣別（默認 DEFAULT_CURRENCY）
+	currency, err := cash_flow_service.ResolveCurrency(primitive.NilObjectID, budgetDTO.Currency)
+	if err != nil {
+		return model.BudgetEntity{}, err
+	}
+
+	existEntity := budget_mapper.INSTANCE.GetBudgetByCategoryIdAndPeriod(categoryEntity.Id.Hex(), period)
+
+	startDate := existEntity.StartDate
+	if budgetDTO.StartDate != "" {
+		if err := validation.ValidateDate(budgetDTO.StartDate); err != nil {
+			return model.BudgetEntity{}, err
+		}
+		startDate = util.FormatDateFromStringWithOptionalDash(budgetDTO.StartDate)
+	}
+	if startDate.IsZero() {
+		startDate = util.FormatDateFromStringWithoutDash(util.FormatDateToStringWithoutDash(time.Now()))
+	}
+
+	budgetEntity := model.BudgetEntity{
+		OwnerId:    categoryEntity.OwnerId,
+		CategoryId: categoryEntity.Id,
+		Period:     period,
+		Limit:      budgetDTO.Limit.Round(2),
+		Currency:   currency,
+		Rollover:   budgetDTO.Rollover,
+		StartDate:  periodStart(period, startDate),
+	}
+
+	if existEntity.IsEmpty() {
+		newPlainId := budget_mapper.INSTANCE.InsertBudgetByEntity(budgetEntity)
+		if newPlainId == "" {
+			return model.BudgetEntity{}, errors.New("budget create failed")
+		}
+		return budget_mapper.INSTANCE.GetBudgetByObjectId(newPlainId), nil
+	}
+
+	updatedEntity := budget_mapper.INSTANCE.UpdateBudgetByEntity(existEntity.Id.Hex(), budgetEntity)
+	if updatedEntity.IsEmpty() {
+		return model.BudgetEntity{}, errors.New("budget update failed")
+	}
+	return updatedEntity, nil
+}
+
+// periodStart returns the first day of the month or year that date falls in
+func periodStart(period string, date time.Time) time.Time {
+	if period == model.BudgetPeriodYearly {
+		return time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, date.Location())
+	}
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+}
+
+// nextPeriodStart returns the first day of the period following the one starting at start
+func nextPeriodStart(period string, start time.Time) time.Time {
+	if period == model.BudgetPeriodYearly {
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 1, 0)
+}
@@ -0,0 +1,190 @@
+package budget_service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/budget_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BudgetStatus reports how much of a budget is used in the period containing the requested date.
+// Available is the limit plus what rolled over, Remaining turns negative once it is overspent.
+type BudgetStatus struct {
+	BudgetId     string          `json:"budget_id"`
+	CategoryName string          `json:"category_name"`
+	Period       string          `json:"period"`
+	PeriodStart  string          `json:"period_start"`
+	PeriodEnd    string          `json:"period_end"`
+	Currency     string          `json:"currency"`
+	Limit        decimal.Decimal `json:"limit"`
+	RolledOver   decimal.Decimal `json:"rolled_over"`
+	Available    decimal.Decimal `json:"available"`
+	Spent        decimal.Decimal `json:"spent"`
+	Remaining    decimal.Decimal `json:"remaining"`
+	PercentUsed  decimal.Decimal `json:"percent_used"`
+	Overspent    bool            `json:"overspent"`
+}
+
+// StatusService reports every budget of the owner, or only those of categoryName, for the period containing date.
+// A blank date means today; budgets starting after date are left out.
+func StatusService(ownerPlainId, categoryName, date string) ([]BudgetStatus, error) {
+	statusDate := util.FormatDateFromStringWithoutDash(util.FormatDateToStringWithoutDash(time.Now()))
+	if date != "" {
+		if err := validation.ValidateDate(date); err != nil {
+			return nil, err
+		}
+		statusDate = util.FormatDateFromStringWithOptionalDash(date)
+	}
+
+	var categoryId primitive.ObjectID
+	if categoryName != "" {
+		categoryEntity := category_mapper.INSTANCE.GetCategoryByName(ownerPlainId, categoryName)
+		if categoryEntity.IsEmpty() {
+			return nil, errors.New("category does not exist")
+		}
+		categoryId = categoryEntity.Id
+	}
+
+	tracker := newSpendingTracker(ownerPlainId)
+	statusList := []BudgetStatus{}
+	for _, budget := range budget_mapper.INSTANCE.GetBudgetsByOwnerId(ownerPlainId, 0, 0) {
+		if categoryName != "" && budget.CategoryId != categoryId {
+			continue
+		}
+		if budget.StartDate.After(statusDate) {
+			continue
+		}
+
+		status, err := tracker.buildStatus(budget, statusDate)
+		if err != nil {
+			return nil, err
+		}
+		statusList = append(statusList, status)
+	}
+
+	sort.SliceStable(statusList, func(i, j int) bool {
+		if statusList[i].CategoryName != statusList[j].CategoryName {
+			return statusList[i].CategoryName < statusList[j].CategoryName
+		}
+		return statusList[i].Period < statusList[j].Period
+	})
+	return statusList, nil
+}
+
+// spendingTracker adds up the outcome of a category and its subcategories per period in the owner's ledger,
+// the categories and the records of each period are read only once.
+type spendingTracker struct {
+	ownerPlainId      string
+	categoryList      []model.CategoryEntity
+	cashFlowsByPeriod map[string][]model.CashFlowEntity
+}
+
+func newSpendingTracker(ownerPlainId string) *spendingTracker {
+	return &spendingTracker{
+		ownerPlainId:      ownerPlainId,
+		categoryList:      category_mapper.INSTANCE.GetCategoriesByOwnerId(ownerPlainId, 0, 0),
+		cashFlowsByPeriod: make(map[string][]model.CashFlowEntity),
+	}
+}
+
+func (tracker *spendingTracker) buildStatus(budget model.BudgetEntity, statusDate time.Time) (BudgetStatus, error) {
+	categoryIds := tracker.categoryWithDescendants(budget.CategoryId)
+
+	// Unspent amounts of earlier periods carry over, an overspent period carries nothing
+	rolledOver := decimal.Zero
+	currentStart := periodStart(budget.Period, statusDate)
+	if budget.Rollover {
+		for start := periodStart(budget.Period, budget.StartDate); start.Before(currentStart); start = nextPeriodStart(budget.Period, start) {
+			spent, err := tracker.spent(budget, categoryIds, start)
+			if err != nil {
+				return BudgetStatus{}, err
+			}
+			rolledOver = decimal.Max(decimal.Zero, budget.Limit.Add(rolledOver).Sub(spent))
+		}
+	}
+
+	spent, err := tracker.spent(budget, categoryIds, currentStart)
+	if err != nil {
+		return BudgetStatus{}, err
+	}
+
+	available := budget.Limit.Add(rolledOver)
+	percentUsed := decimal.Zero
+	if available.IsPositive() {
+		percentUsed = spent.Mul(decimal.NewFromInt(100)).Div(available).Round(2)
+	}
+
+	return BudgetStatus{
+		BudgetId:     budget.Id.Hex(),
+		CategoryName: category_mapper.INSTANCE.GetCategoryByObjectId(budget.CategoryId.Hex()).Name,
+		Period:       budget.Period,
+		PeriodStart:  util.FormatDateToStringWithDash(currentStart),
+		PeriodEnd:    util.FormatDateToStringWithDash(nextPeriodStart(budget.Period, currentStart).AddDate(0, 0, -1)),
+		Currency:     budget.Currency,
+		Limit:        budget.Limit,
+		RolledOver:   rolledOver,
+		Available:    available,
+		Spent:        spent,
+		Remaining:    available.Sub(spent),
+		PercentUsed:  percentUsed,
+		Overspent:    spent.GreaterThan(available),
+	}, nil
+}
+
+// spent adds up the outcome booked to categoryIds in the period starting at start,
+// converted into the budget's currency at the rate in effect on each record's date
+func (tracker *spendingTracker) spent(budget model.BudgetEntity, categoryIds map[primitive.ObjectID]bool,
+	start time.Time) (decimal.Decimal, error) {
+
+	end := nextPeriodStart(budget.Period, start).AddDate(0, 0, -1)
+	periodKey := util.FormatDateToStringWithDash(start) + "/" + util.FormatDateToStringWithDash(end)
+	cashFlowList, found := tracker.cashFlowsByPeriod[periodKey]
+	if !found {
+		cashFlowList = cash_flow_mapper.INSTANCE.GetCashFlowsByDateRange(tracker.ownerPlainId, start, end)
+		tracker.cashFlowsByPeriod[periodKey] = cashFlowList
+	}
+
+	total := decimal.Zero
+	for _, cashFlow := range cashFlowList {
+		if cashFlow.FlowType != model.FlowTypeOutcome {
+			continue
+		}
+		// Only the lines of a split cash flow booked to the budget's categories count
+		for _, line := range cashFlow.CategoryLines() {
+			if !categoryIds[line.CategoryId] {
+				continue
+			}
+			convertedAmount, err := exchange_rate_service.ConvertAmount(
+				tracker.ownerPlainId, line.Amount, cashFlow.Currency, budget.Currency, cashFlow.BelongsDate)
+			if err != nil {
+				return decimal.Zero, err
+			}
+			total = total.Add(convertedAmount)
+		}
+	}
+	return total, nil
+}
+
+// categoryWithDescendants returns the category and every category below it, so child spending rolls up
+func (tracker *spendingTracker) categoryWithDescendants(categoryId primitive.ObjectID) map[primitive.ObjectID]bool {
+	categoryIds := map[primitive.ObjectID]bool{categoryId: true}
+	for addedCount := 1; addedCount > 0; {
+		addedCount = 0
+		for _, category := range tracker.categoryList {
+			if categoryIds[category.ParentId] && !categoryIds[category.Id] {
+				categoryIds[category.Id] = true
+				addedCount++
+			}
+		}
+	}
+	return categoryIds
+}
@@ -0,0 +1,53 @@
+package cash_flow_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetAccountIdByName resolves the owner's optional account, a blank name means no account
+func GetAccountIdByName(ownerPlainId, accountName string) (primitive.ObjectID, error) {
+	if accountName == "" {
+		return primitive.NilObjectID, nil
+	}
+
+	if err := validation.ValidateAccountName(accountName); err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	accountEntity := account_mapper.INSTANCE.GetAccountByName(ownerPlainId, accountName)
+	if accountEntity.IsEmpty() {
+		return primitive.NilObjectID, errors.New("account does not exist")
+	}
+	return accountEntity.Id, nil
+}
+
+// ResolveCurrency decides the currency of a record booked to the account.
+// The account's currency wins and a different explicit currency is refused,
+// a record without account takes the given currency or the default one.
+func ResolveCurrency(accountId primitive.ObjectID, currency string) (string, error) {
+	if currency != "" {
+		currency = exchange_rate_service.NormalizeCurrency(currency)
+		if err := validation.ValidateCurrency(currency); err != nil {
+			return "", err
+		}
+	}
+
+	if accountId == primitive.NilObjectID {
+		return exchange_rate_service.NormalizeCurrency(currency), nil
+	}
+
+	accountEntity := account_mapper.INSTANCE.GetAccountByObjectId(accountId.Hex())
+	if accountEntity.IsEmpty() {
+		return "", errors.New("account does not exist")
+	}
+	accountCurrency := exchange_rate_service.NormalizeCurrency(accountEntity.Currency)
+	if currency != "" && currency != accountCurrency {
+		return "", errors.New("currency does not match the account's currency " + accountCurrency)
+	}
+	return accountCurrency, nil
+}
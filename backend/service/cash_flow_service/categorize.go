@@ -0,0 +1,111 @@
+package cash_flow_service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CategoryRuleMatcher picks the categorization rule of cash_flows without a category,
+// it is built once and reused across many records
+type CategoryRuleMatcher struct {
+	ruleList []compiledCategoryRule
+}
+
+type compiledCategoryRule struct {
+	rule     model.CategoryRuleEntity
+	contains string         // lowercase pattern of a CONTAINS description condition
+	regex    *regexp.Regexp // compiled pattern of a REGEX description condition
+}
+
+// NewCategoryRuleMatcher loads the owner's categorization rules in the order they are tried,
+// leaving out the rules whose category the owner does not own
+func NewCategoryRuleMatcher(ownerPlainId string) CategoryRuleMatcher {
+	isOwnedCategory := make(map[primitive.ObjectID]bool)
+	for _, category := range category_mapper.INSTANCE.GetCategoriesByOwnerId(ownerPlainId, 0, 0) {
+		isOwnedCategory[category.Id] = true
+	}
+
+	var matcher CategoryRuleMatcher
+	for _, rule := range category_rule_mapper.INSTANCE.GetCategoryRulesByOwnerId(ownerPlainId, 0, 0) {
+		if !isOwnedCategory[rule.CategoryId] {
+			continue
+		}
+		compiledRule := compiledCategoryRule{rule: rule}
+		switch rule.MatchType {
+		case model.PayeeRuleContains:
+			compiledRule.contains = strings.ToLower(rule.Pattern)
+		case model.PayeeRuleRegex:
+			regex, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				// Stored before validation or restored from a backup, it can not match anything
+				util.Logger.Warnw("category rule skipped, invalid regular expression",
+					"rule", rule.Name, "pattern", rule.Pattern)
+				continue
+			}
+			compiledRule.regex = regex
+		}
+		matcher.ruleList = append(matcher.ruleList, compiledRule)
+	}
+	return matcher
+}
+
+// Match gives the first rule whose conditions all hold for the cash_flow, or an empty entity when none does.
+// Transfers are never categorized.
+func (matcher CategoryRuleMatcher) Match(flowType, description string, amount decimal.Decimal,
+	payeeId, accountId primitive.ObjectID) model.CategoryRuleEntity {
+	if flowType == model.FlowTypeTransfer {
+		return model.CategoryRuleEntity{}
+	}
+
+	amount = amount.Abs()
+	lowerDescription := strings.ToLower(description)
+	for _, compiledRule := range matcher.ruleList {
+		rule := compiledRule.rule
+		if rule.FlowType != "" && rule.FlowType != flowType {
+			continue
+		}
+		if compiledRule.regex != nil && !compiledRule.regex.MatchString(description) {
+			continue
+		}
+		if compiledRule.contains != "" && !strings.Contains(lowerDescription, compiledRule.contains) {
+			continue
+		}
+		if !rule.MinAmount.IsZero() && amount.LessThan(rule.MinAmount) {
+			continue
+		}
+		if !rule.MaxAmount.IsZero() && amount.GreaterThan(rule.MaxAmount) {
+			continue
+		}
+		if !rule.PayeeId.IsZero() && rule.PayeeId != payeeId {
+			continue
+		}
+		if !rule.AccountId.IsZero() && rule.AccountId != accountId {
+			continue
+		}
+		return rule
+	}
+	return model.CategoryRuleEntity{}
+}
+
+// categorize gives the category of the owner's first matching rule and adds its tags to the given ones
+func categorize(ownerPlainId, flowType, description string, amount decimal.Decimal, payeeId, accountId primitive.ObjectID,
+	tags []string) (primitive.ObjectID, []string, error) {
+	rule := NewCategoryRuleMatcher(ownerPlainId).Match(flowType, description, amount, payeeId, accountId)
+	if rule.IsEmpty() {
+		return primitive.NilObjectID, nil, errors.New("category is empty and no categorization rule matches")
+	}
+
+	tags, err := NormalizeTags(append(tags, rule.Tags...))
+	if err != nil {
+		return primitive.NilObjectID, nil, err
+	}
+	return rule.CategoryId, tags, nil
+}
@@ -32,14 +32,14 @@ func IsDeleteFieldsConflicted(plainId, belongsDate string) bool {
 	return !semiOptionalFieldFilledFlag
 }
 
-func DeleteById(plainId string) (model.CashFlowEntity, error) {
+func DeleteById(ownerPlainId, plainId string) (model.CashFlowEntity, error) {
 	// Validate ID
 	if err := validation.ValidateID(plainId); err != nil {
 		return model.CashFlowEntity{}, err
 	}
 
 	existCashFlowEntity := cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(plainId)
-	if existCashFlowEntity.IsEmpty() {
+	if existCashFlowEntity.IsEmpty() || !existCashFlowEntity.IsOwnedBy(ownerPlainId) {
 		return model.CashFlowEntity{}, errors.New("cash_flow not found")
 	}
 
@@ -47,20 +47,28 @@ func DeleteById(plainId string) (model.CashFlowEntity, error) {
 	if existCashFlowEntity.IsEmpty() {
 		return model.CashFlowEntity{}, errors.New("cash_flow delete failed")
 	}
+
+	// Deleting one leg of a transfer deletes the other
+	deleteLinkedTransfer(existCashFlowEntity)
 	return existCashFlowEntity, nil
 }
 
-func DeleteByDate(belongsDate string) ([]model.CashFlowEntity, error) {
+func DeleteByDate(ownerPlainId, belongsDate string) ([]model.CashFlowEntity, error) {
 	// Validate date
 	if err := validation.ValidateDate(belongsDate); err != nil {
 		return []model.CashFlowEntity{}, err
 	}
 
-	deleteDate := util.FormatDateFromStringWithoutDash(belongsDate)
+	deleteDate := util.FormatDateFromStringWithOptionalDash(belongsDate)
 	if reflect.DeepEqual(deleteDate, time.Time{}) {
 		return []model.CashFlowEntity{}, errors.New("belongs_date error, try format like 19700101")
 	}
 
-	cashFlowList := cash_flow_mapper.INSTANCE.DeleteCashFlowByBelongsDate(deleteDate)
+	cashFlowList := cash_flow_mapper.INSTANCE.DeleteCashFlowByBelongsDate(ownerPlainId, deleteDate)
+	for _, cashFlowEntity := range cashFlowList {
+		if linkedEntity := deleteLinkedTransfer(cashFlowEntity); !linkedEntity.IsEmpty() {
+			cashFlowList = append(cashFlowList, linkedEntity)
+		}
+	}
 	return cashFlowList, nil
 }
@@ -1,32 +1,32 @@
 package cash_flow_service
 
 import (
-	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"strings"
+
 	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
 )
 
-// QueryAll queries all cash flows with optional filtering and pagination
-func QueryAll(cashType string, limit, offset int) ([]*model.CashFlowEntity, int64, error) {
-	// Get total count
-	totalCount := cash_flow_mapper.INSTANCE.CountAllCashFlows()
-
-	// Get paginated results
-	cashFlows := cash_flow_mapper.INSTANCE.GetAllCashFlows(limit, offset)
-
-	// Filter by cash type if specified
-	var filteredResults []*model.CashFlowEntity
+// QueryAll queries the owner's cash flows with optional filtering, sorting and pagination, newest first by default.
+// The flow type and tag are applied by the mapper, so pages come back full and the total count matches them.
+// A cursor from an earlier page of the date order takes the place of the offset.
+func QueryAll(ownerPlainId, cashType, tag, sortBy, order, cursor string, limit, offset int) (model.CashFlowPage, error) {
+	querySpec := model.CashFlowQuerySpec{Limit: limit, Offset: offset}
 	if cashType != "" {
-		for i := range cashFlows {
-			if cashFlows[i].FlowType == cashType {
-				filteredResults = append(filteredResults, &cashFlows[i])
-			}
+		querySpec.FlowType = strings.ToUpper(strings.TrimSpace(cashType))
+		if err := validation.ValidateFlowType(querySpec.FlowType); err != nil {
+			return model.CashFlowPage{}, err
 		}
-	} else {
-		// Convert to pointer slice
-		for i := range cashFlows {
-			filteredResults = append(filteredResults, &cashFlows[i])
+	}
+	if tag != "" {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if err := validation.ValidateTag(tag); err != nil {
+			return model.CashFlowPage{}, err
 		}
+		querySpec.Tags = []string{tag}
 	}
-
-	return filteredResults, totalCount, nil
+	if err := applyQuerySort(&querySpec, sortBy, order); err != nil {
+		return model.CashFlowPage{}, err
+	}
+	return queryPage(ownerPlainId, querySpec, cursor)
 }
@@ -0,0 +1,24 @@
+package cash_flow_service
+
+import (
+	"os"
+	"testing"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+)
+
+// TestMain runs the package against in-memory mappers, so no database is needed
+func TestMain(m *testing.M) {
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	account_mapper.INSTANCE = account_mapper.NewAccountMemoryMapper()
+	exchange_rate_mapper.INSTANCE = exchange_rate_mapper.NewExchangeRateMemoryMapper()
+	payee_mapper.INSTANCE = payee_mapper.NewPayeeMemoryMapper()
+	category_rule_mapper.INSTANCE = category_rule_mapper.NewCategoryRuleMemoryMapper()
+	os.Exit(m.Run())
+}
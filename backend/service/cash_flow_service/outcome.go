@@ -10,12 +10,15 @@ import (
 	"github.com/macar-x/cashlens/util"
 	"github.com/macar-x/cashlens/validation"
 	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func SaveOutcome(belongsDate, categoryName string, amount float64, description string) (model.CashFlowEntity, error) {
+func SaveOutcome(ownerPlainId, userPlainId, belongsDate, categoryName, accountName, currency string, amount decimal.Decimal, description, payeeName string, tags []string) (model.CashFlowEntity, error) {
 	// Validate inputs
-	if err := validation.ValidateCategoryName(categoryName); err != nil {
-		return model.CashFlowEntity{}, err
+	if categoryName != "" {
+		if err := validation.ValidateCategoryName(categoryName); err != nil {
+			return model.CashFlowEntity{}, err
+		}
 	}
 
 	if err := validation.ValidateAmount(amount); err != nil {
@@ -32,27 +35,67 @@ func SaveOutcome(belongsDate, categoryName string, amount float64, description s
 		return model.CashFlowEntity{}, err
 	}
 
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return model.CashFlowEntity{}, err
+	}
+
 	// 取小數點後兩位
-	amount, _ = decimal.NewFromFloat(amount).Round(2).Float64()
+	amount = amount.Round(2)
+
+	// 選填參數: 帳戶
+	accountId, err := GetAccountIdByName(ownerPlainId, accountName)
+	if err != nil {
+		return model.CashFlowEntity{}, err
+	}
+
+	// 選填參數: 幣別（有帳戶時跟隨帳戶）
+	currency, err = ResolveCurrency(accountId, currency)
+	if err != nil {
+		return model.CashFlowEntity{}, err
+	}
+
+	// 選填參數: 收付對象（未指定時按規則從描述識別）
+	payeeId, err := ResolvePayeeId(ownerPlainId, payeeName, description)
+	if err != nil {
+		return model.CashFlowEntity{}, err
+	}
 
-	// 必填參數: 類別
-	categoryEntity := category_mapper.INSTANCE.GetCategoryByName(categoryName)
-	if categoryEntity.IsEmpty() {
-		return model.CashFlowEntity{}, errors.New("category does not exist")
+	// 選填參數: 類別（未指定時按分類規則識別，並加上規則的標籤）
+	var categoryId primitive.ObjectID
+	if categoryName != "" {
+		categoryEntity := category_mapper.INSTANCE.GetCategoryByName(ownerPlainId, categoryName)
+		if categoryEntity.IsEmpty() {
+			return model.CashFlowEntity{}, errors.New("category does not exist")
+		}
+		categoryId = categoryEntity.Id
+	} else {
+		categoryId, tags, err = categorize(ownerPlainId, model.FlowTypeOutcome, description, amount, payeeId, accountId, tags)
+		if err != nil {
+			return model.CashFlowEntity{}, err
+		}
 	}
 
 	// 選填參數: 日期（默認當天）
 	date := util.FormatDateFromStringWithoutDash(util.FormatDateToStringWithoutDash(time.Now()))
 	if belongsDate != "" {
-		date = util.FormatDateFromStringWithoutDash(belongsDate)
+		date = util.FormatDateFromStringWithOptionalDash(belongsDate)
 	}
 
+	userId := util.ConvertOwner2ObjectId(userPlainId)
 	newCashFlowId := cash_flow_mapper.INSTANCE.InsertCashFlowByEntity(model.CashFlowEntity{
-		CategoryId:  categoryEntity.Id,
+		OwnerId:     util.ConvertOwner2ObjectId(ownerPlainId),
+		CategoryId:  categoryId,
+		AccountId:   accountId,
 		BelongsDate: date,
 		FlowType:    "OUTCOME",
 		Amount:      amount,
+		Currency:    currency,
 		Description: description,
+		Tags:        tags,
+		PayeeId:     payeeId,
+		CreatedBy:   userId,
+		ModifiedBy:  userId,
 	})
 	if newCashFlowId == "" {
 		return model.CashFlowEntity{}, errors.New("cash_flow create failed")
@@ -62,11 +105,9 @@ func SaveOutcome(belongsDate, categoryName string, amount float64, description s
 	return newCashFlow, nil
 }
 
-func IsOutcomeRequiredFiledSatisfied(categoryName string, amount float64) bool {
-	if categoryName == "" {
-		return false
-	}
-	if amount == 0 {
+// IsOutcomeRequiredFiledSatisfied checks the required fields, the category may be left to the categorization rules
+func IsOutcomeRequiredFiledSatisfied(amount decimal.Decimal) bool {
+	if amount.IsZero() {
 		return false
 	}
 
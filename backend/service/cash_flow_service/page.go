@@ -0,0 +1,70 @@
+package cash_flow_service
+
+import (
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// queryPage reads one page of the query spec within the owner's ledger, continuing from the cursor token when one is given.
+// The cursors of the page are filled in for the date order, which is the only one they can follow.
+func queryPage(ownerPlainId string, querySpec model.CashFlowQuerySpec, cursorToken string) (model.CashFlowPage, error) {
+	if querySpec.Limit < 0 || querySpec.Offset < 0 {
+		return model.CashFlowPage{}, validation.NewValidationError("pagination", "limit and offset must not be negative")
+	}
+	isDateOrder := querySpec.SortBy == "" || querySpec.SortBy == model.CashFlowSortByDate
+	if cursorToken != "" {
+		cursor, err := model.DecodeCashFlowCursor(cursorToken)
+		if err != nil {
+			return model.CashFlowPage{}, validation.NewValidationError("cursor", err.Error())
+		}
+		if !isDateOrder {
+			return model.CashFlowPage{}, validation.NewValidationError("cursor", "only follows the date order")
+		}
+		if querySpec.Offset > 0 {
+			return model.CashFlowPage{}, validation.NewValidationError("cursor", "cannot be combined with an offset")
+		}
+		querySpec.Cursor = cursor
+	}
+
+	page := model.CashFlowPage{
+		TotalCount: cash_flow_mapper.INSTANCE.CountCashFlowsByQuery(ownerPlainId, querySpec),
+		Limit:      querySpec.Limit,
+		Offset:     querySpec.Offset,
+	}
+
+	// One record more than the page tells whether another page follows in the reading direction
+	pageSize := querySpec.Limit
+	if pageSize > 0 {
+		querySpec.Limit++
+	}
+	cashFlowList := cash_flow_mapper.INSTANCE.QueryCashFlows(ownerPlainId, querySpec)
+	hasMore := pageSize > 0 && len(cashFlowList) > pageSize
+	if hasMore && querySpec.Cursor.Backward {
+		// A backward page is in listing order, the extra record is the farthest one from the cursor
+		cashFlowList = cashFlowList[1:]
+	} else if hasMore {
+		cashFlowList = cashFlowList[:pageSize]
+	}
+	if cashFlowList == nil {
+		cashFlowList = []model.CashFlowEntity{}
+	}
+	page.Data = cashFlowList
+
+	if !isDateOrder || len(cashFlowList) == 0 {
+		return page, nil
+	}
+	// Coming from a cursor, the records on its other side are still there
+	hasNext := hasMore || querySpec.Cursor.Backward
+	hasPrev := hasMore && querySpec.Cursor.Backward ||
+		!querySpec.Cursor.IsZero() && !querySpec.Cursor.Backward || querySpec.Offset > 0
+	if hasNext {
+		lastCashFlow := cashFlowList[len(cashFlowList)-1]
+		page.NextCursor = model.CashFlowCursor{BelongsDate: lastCashFlow.BelongsDate, Id: lastCashFlow.Id}.Encode()
+	}
+	if hasPrev {
+		firstCashFlow := cashFlowList[0]
+		page.PrevCursor = model.CashFlowCursor{BelongsDate: firstCashFlow.BelongsDate, Id: firstCashFlow.Id, Backward: true}.Encode()
+	}
+	return page, nil
+}
@@ -0,0 +1,27 @@
+package cash_flow_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/service/payee_service"
+	"github.com/macar-x/cashlens/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ResolvePayeeId resolves the owner's optional payee, a blank name lets the payee rules recognise it in the description
+func ResolvePayeeId(ownerPlainId, payeeName, description string) (primitive.ObjectID, error) {
+	if payeeName == "" {
+		return payee_service.MatchPayeeId(ownerPlainId, description), nil
+	}
+
+	if err := validation.ValidatePayeeName(payeeName); err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	payeeEntity := payee_mapper.INSTANCE.GetPayeeByName(ownerPlainId, payeeName)
+	if payeeEntity.IsEmpty() {
+		return primitive.NilObjectID, errors.New("payee does not exist")
+	}
+	return payeeEntity.Id, nil
+}
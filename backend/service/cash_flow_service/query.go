@@ -47,30 +47,30 @@ func IsQueryFieldsConflicted(plainId, belongsDate, exactDescription, fuzzyDescri
 	return !semiOptionalFieldFilledFlag
 }
 
-func QueryById(plainId string) (model.CashFlowEntity, error) {
+func QueryById(ownerPlainId, plainId string) (model.CashFlowEntity, error) {
 	cashFlowEntity := cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(plainId)
-	if cashFlowEntity.IsEmpty() {
+	if cashFlowEntity.IsEmpty() || !cashFlowEntity.IsOwnedBy(ownerPlainId) {
 		return model.CashFlowEntity{}, errors.New("cash_flow not found")
 	}
 	return cashFlowEntity, nil
 }
 
-func QueryByDate(belongsDate string) ([]model.CashFlowEntity, error) {
-	queryDate := util.FormatDateFromStringWithoutDash(belongsDate)
+func QueryByDate(ownerPlainId, belongsDate string) ([]model.CashFlowEntity, error) {
+	queryDate := util.FormatDateFromStringWithOptionalDash(belongsDate)
 	if reflect.DeepEqual(queryDate, time.Time{}) {
 		return []model.CashFlowEntity{}, errors.New("belongs_date error, try format like 19700101")
 	}
 
-	matchedCashFlowList := cash_flow_mapper.INSTANCE.GetCashFlowsByBelongsDate(queryDate)
+	matchedCashFlowList := cash_flow_mapper.INSTANCE.GetCashFlowsByBelongsDate(ownerPlainId, queryDate)
 	return matchedCashFlowList, nil
 }
 
-func QueryByExactDescription(exactDescription string) ([]model.CashFlowEntity, error) {
-	matchedCashFlowList := cash_flow_mapper.INSTANCE.GetCashFlowsByExactDesc(exactDescription)
+func QueryByExactDescription(ownerPlainId, exactDescription string) ([]model.CashFlowEntity, error) {
+	matchedCashFlowList := cash_flow_mapper.INSTANCE.GetCashFlowsByExactDesc(ownerPlainId, exactDescription)
 	return matchedCashFlowList, nil
 }
 
-func QueryByFuzzyDescription(fuzzyDescription string) ([]model.CashFlowEntity, error) {
-	matchedCashFlowList := cash_flow_mapper.INSTANCE.GetCashFlowsByFuzzyDesc(fuzzyDescription)
+func QueryByFuzzyDescription(ownerPlainId, fuzzyDescription string) ([]model.CashFlowEntity, error) {
+	matchedCashFlowList := cash_flow_mapper.INSTANCE.GetCashFlowsByFuzzyDesc(ownerPlainId, fuzzyDescription)
 	return matchedCashFlowList, nil
 }
@@ -7,19 +7,35 @@ import (
 	"github.com/macar-x/cashlens/validation"
 )
 
-// QueryByDateRange queries cash flows within a date range
-func QueryByDateRange(fromDate, toDate string) ([]*model.CashFlowEntity, error) {
+// QueryPageByDateRange reads one page of the owner's cash flows within a date range, oldest first.
+// A limit of 0 returns the whole range, a cursor from an earlier page continues from there.
+func QueryPageByDateRange(ownerPlainId, fromDate, toDate, cursor string, limit int) (model.CashFlowPage, error) {
+	if err := validation.ValidateDateRange(fromDate, toDate); err != nil {
+		return model.CashFlowPage{}, err
+	}
+
+	querySpec := model.CashFlowQuerySpec{
+		FromDate: util.FormatDateFromStringWithOptionalDash(fromDate),
+		ToDate:   util.FormatDateFromStringWithOptionalDash(toDate),
+		SortBy:   model.CashFlowSortByDate,
+		Limit:    limit,
+	}
+	return queryPage(ownerPlainId, querySpec, cursor)
+}
+
+// QueryByDateRange queries the owner's cash flows within a date range
+func QueryByDateRange(ownerPlainId, fromDate, toDate string) ([]*model.CashFlowEntity, error) {
 	// Validate date range
 	if err := validation.ValidateDateRange(fromDate, toDate); err != nil {
 		return nil, err
 	}
 
 	// Parse dates
-	from := util.FormatDateFromStringWithoutDash(fromDate)
-	to := util.FormatDateFromStringWithoutDash(toDate)
+	from := util.FormatDateFromStringWithOptionalDash(fromDate)
+	to := util.FormatDateFromStringWithOptionalDash(toDate)
 
 	// Single query for entire date range
-	results := cash_flow_mapper.INSTANCE.GetCashFlowsByDateRange(from, to)
+	results := cash_flow_mapper.INSTANCE.GetCashFlowsByDateRange(ownerPlainId, from, to)
 
 	// Convert to pointer slice
 	var resultPtrs []*model.CashFlowEntity
@@ -63,7 +63,7 @@ func TestQueryByDateRange_Validation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := QueryByDateRange(tt.fromDate, tt.toDate)
+			_, err := QueryByDateRange("", tt.fromDate, tt.toDate)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("QueryByDateRange() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -0,0 +1,143 @@
+package cash_flow_service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultSearchLimit is the page size of a search when no limit is given
+const defaultSearchLimit = 20
+
+// SearchService finds the owner's cash flows matching every filter of the request, one page of them
+// along with the total count of matches
+func SearchService(ownerPlainId string, searchDTO model.CashFlowSearchDTO) (model.CashFlowPage, error) {
+	querySpec, err := buildSearchQuerySpec(ownerPlainId, searchDTO)
+	if err != nil {
+		return model.CashFlowPage{}, err
+	}
+	return queryPage(ownerPlainId, querySpec, searchDTO.Cursor)
+}
+
+func buildSearchQuerySpec(ownerPlainId string, searchDTO model.CashFlowSearchDTO) (model.CashFlowQuerySpec, error) {
+	querySpec := model.CashFlowQuerySpec{
+		Text:      strings.TrimSpace(searchDTO.Text),
+		MinAmount: searchDTO.MinAmount.Round(2),
+		MaxAmount: searchDTO.MaxAmount.Round(2),
+		Limit:     searchDTO.Limit,
+		Offset:    searchDTO.Offset,
+	}
+	if err := validation.ValidateDescription(querySpec.Text); err != nil {
+		return querySpec, err
+	}
+
+	// 選填參數: 日期區間，可只給一端
+	if searchDTO.FromDate != "" {
+		if err := validation.ValidateDate(searchDTO.FromDate); err != nil {
+			return querySpec, err
+		}
+		querySpec.FromDate = util.FormatDateFromStringWithOptionalDash(searchDTO.FromDate)
+	}
+	if searchDTO.ToDate != "" {
+		if err := validation.ValidateDate(searchDTO.ToDate); err != nil {
+			return querySpec, err
+		}
+		querySpec.ToDate = util.FormatDateFromStringWithOptionalDash(searchDTO.ToDate)
+	}
+	if !querySpec.FromDate.IsZero() && !querySpec.ToDate.IsZero() && querySpec.FromDate.After(querySpec.ToDate) {
+		return querySpec, validation.NewValidationError("date_range", "from date must be before or equal to to date")
+	}
+
+	if err := validation.ValidateAmountRange(querySpec.MinAmount, querySpec.MaxAmount); err != nil {
+		return querySpec, err
+	}
+
+	if searchDTO.FlowType != "" {
+		querySpec.FlowType = strings.ToUpper(strings.TrimSpace(searchDTO.FlowType))
+		if err := validation.ValidateFlowType(querySpec.FlowType); err != nil {
+			return querySpec, err
+		}
+	}
+
+	// 選填參數: 分類，包含其下所有子分類
+	if searchDTO.CategoryName != "" {
+		if err := validation.ValidateCategoryName(searchDTO.CategoryName); err != nil {
+			return querySpec, err
+		}
+		categoryEntity := category_mapper.INSTANCE.GetCategoryByName(ownerPlainId, searchDTO.CategoryName)
+		if categoryEntity.IsEmpty() {
+			return querySpec, errors.New("category does not exist")
+		}
+		querySpec.CategoryIds = categoryWithDescendants(ownerPlainId, categoryEntity.Id)
+	}
+
+	tags, err := NormalizeTags(searchDTO.Tags)
+	if err != nil {
+		return querySpec, err
+	}
+	querySpec.Tags = tags
+
+	querySpec.AccountId, err = GetAccountIdByName(ownerPlainId, searchDTO.AccountName)
+	if err != nil {
+		return querySpec, err
+	}
+
+	if err := applyQuerySort(&querySpec, searchDTO.SortBy, searchDTO.Order); err != nil {
+		return querySpec, err
+	}
+
+	if querySpec.Limit == 0 {
+		querySpec.Limit = defaultSearchLimit
+	}
+	return querySpec, nil
+}
+
+// applyQuerySort sets the sort of the query spec, date and descending when left blank.
+// Relevance only makes sense once the spec has a text.
+func applyQuerySort(querySpec *model.CashFlowQuerySpec, sortBy, order string) error {
+	querySpec.SortBy = strings.ToLower(strings.TrimSpace(sortBy))
+	switch querySpec.SortBy {
+	case "":
+		querySpec.SortBy = model.CashFlowSortByDate
+	case model.CashFlowSortByDate, model.CashFlowSortByAmount:
+	case model.CashFlowSortByRelevance:
+		if len(querySpec.TextTerms()) == 0 {
+			return validation.NewValidationError("sort_by", "relevance needs a search text")
+		}
+	default:
+		return validation.NewValidationError("sort_by", "must be date, amount or relevance")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(order)) {
+	case "", "desc":
+		querySpec.SortDesc = true
+	case "asc":
+		querySpec.SortDesc = false
+	default:
+		return validation.NewValidationError("order", "must be asc or desc")
+	}
+	return nil
+}
+
+// categoryWithDescendants returns the owner's category followed by every category below it
+func categoryWithDescendants(ownerPlainId string, categoryId primitive.ObjectID) []primitive.ObjectID {
+	categoryList := category_mapper.INSTANCE.GetCategoriesByOwnerId(ownerPlainId, 0, 0)
+	categoryIds := []primitive.ObjectID{categoryId}
+	isIncluded := map[primitive.ObjectID]bool{categoryId: true}
+	for addedCount := 1; addedCount > 0; {
+		addedCount = 0
+		for _, category := range categoryList {
+			if isIncluded[category.ParentId] && !isIncluded[category.Id] {
+				isIncluded[category.Id] = true
+				categoryIds = append(categoryIds, category.Id)
+				addedCount++
+			}
+		}
+	}
+	return categoryIds
+}
@@ -0,0 +1,777 @@
+package cash_flow_service
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// resetMappers gives each test empty storage with the named categories in place
+func resetMappers(t *testing.T, categoryNames ...string) {
+	t.Helper()
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	account_mapper.INSTANCE = account_mapper.NewAccountMemoryMapper()
+	exchange_rate_mapper.INSTANCE = exchange_rate_mapper.NewExchangeRateMemoryMapper()
+	payee_mapper.INSTANCE = payee_mapper.NewPayeeMemoryMapper()
+	category_rule_mapper.INSTANCE = category_rule_mapper.NewCategoryRuleMemoryMapper()
+	for _, categoryName := range categoryNames {
+		if category_mapper.INSTANCE.InsertCategoryByEntity(model.CategoryEntity{Name: categoryName}) == "" {
+			t.Fatalf("insert category %q failed", categoryName)
+		}
+	}
+}
+
+func TestSaveAndQueryEndToEnd(t *testing.T) {
+	resetMappers(t, "Food", "Salary")
+
+	if _, err := SaveOutcome("", "", "2024-12-01", "Food", "", "", decimal.NewFromFloat(12.345), "lunch", "", nil); err != nil {
+		t.Fatalf("SaveOutcome() error = %v", err)
+	}
+	if _, err := SaveOutcome("", "", "20241203", "Food", "", "", decimal.NewFromInt(7), "coffee", "", nil); err != nil {
+		t.Fatalf("SaveOutcome() error = %v", err)
+	}
+	income, err := SaveIncome("", "", "20241231", "Salary", "", "", decimal.NewFromInt(3000), "pay", "", nil)
+	if err != nil {
+		t.Fatalf("SaveIncome() error = %v", err)
+	}
+	if _, err := SaveOutcome("", "", "20241201", "Unknown", "", "", decimal.NewFromInt(1), "", "", nil); err == nil {
+		t.Errorf("SaveOutcome() with unknown category expected error, got nil")
+	}
+
+	dayList, err := QueryByDate("", "20241201")
+	if err != nil || len(dayList) != 1 || !dayList[0].Amount.Equal(decimal.NewFromFloat(12.35)) {
+		t.Errorf("QueryByDate() = %+v, %v, want one record rounded to 12.35", dayList, err)
+	}
+
+	rangeList, err := QueryByDateRange("", "2024-12-02", "2024-12-31")
+	if err != nil || len(rangeList) != 2 {
+		t.Errorf("QueryByDateRange() returned %d records, %v, want 2", len(rangeList), err)
+	}
+
+	queried, err := QueryById("", income.Id.Hex())
+	if err != nil || queried.Description != "pay" {
+		t.Errorf("QueryById() = %+v, %v", queried, err)
+	}
+
+	summary, err := GetSummaryByMonth("", "202412", "")
+	if err != nil {
+		t.Fatalf("GetSummaryByMonth() error = %v", err)
+	}
+	totals := singleTotals(t, summary)
+	if summary.TransactionCount != 3 || !totals.TotalIncome.Equal(decimal.NewFromInt(3000)) ||
+		!totals.TotalExpense.Equal(decimal.NewFromFloat(19.35)) {
+		t.Errorf("GetSummaryByMonth() = %+v", summary)
+	}
+	if !totals.CategoryBreakdown["Food"].Equal(decimal.NewFromFloat(19.35)) {
+		t.Errorf("CategoryBreakdown[Food] = %s, want 19.35", totals.CategoryBreakdown["Food"])
+	}
+
+	deletedList, err := DeleteByDate("", "2024-12-03")
+	if err != nil || len(deletedList) != 1 {
+		t.Errorf("DeleteByDate() = %+v, %v, want one record", deletedList, err)
+	}
+	if count := cash_flow_mapper.INSTANCE.CountAllCashFlows(); count != 2 {
+		t.Errorf("CountAllCashFlows() = %d after delete, want 2", count)
+	}
+}
+
+func TestCreatedAndModifiedBy(t *testing.T) {
+	resetMappers(t, "Food")
+	alice := primitive.NewObjectID()
+	bob := primitive.NewObjectID()
+
+	outcome, err := SaveOutcome("", alice.Hex(), "20241201", "Food", "", "", decimal.NewFromInt(10), "lunch", "", nil)
+	if err != nil || outcome.CreatedBy != alice || outcome.ModifiedBy != alice {
+		t.Fatalf("SaveOutcome() = %+v, %v, want created and modified by alice", outcome, err)
+	}
+	updated, err := UpdateById("", bob.Hex(), outcome.Id.Hex(), "", "", "", "", decimal.Zero, "team lunch", "", nil)
+	if err != nil || updated.CreatedBy != alice || updated.ModifiedBy != bob {
+		t.Errorf("UpdateById() = %+v, %v, want created by alice and modified by bob", updated, err)
+	}
+	system, _ := SaveOutcome("", "", "20241201", "Food", "", "", decimal.NewFromInt(5), "", "", nil)
+	if !system.CreatedBy.IsZero() || !system.ModifiedBy.IsZero() {
+		t.Errorf("SaveOutcome() without a user = %+v, want no creator", system)
+	}
+}
+
+func TestSaveAndUpdateWithAccount(t *testing.T) {
+	resetMappers(t, "Food")
+	bankId := account_mapper.INSTANCE.InsertAccountByEntity(model.AccountEntity{Name: "Bank", Type: model.AccountTypeBank})
+	walletId := account_mapper.INSTANCE.InsertAccountByEntity(model.AccountEntity{Name: "Wallet", Type: model.AccountTypeCash})
+
+	outcome, err := SaveOutcome("", "", "20241201", "Food", "Bank", "", decimal.NewFromInt(10), "lunch", "", nil)
+	if err != nil {
+		t.Fatalf("SaveOutcome() error = %v", err)
+	}
+	if outcome.AccountId.Hex() != bankId {
+		t.Errorf("SaveOutcome() account = %s, want %s", outcome.AccountId.Hex(), bankId)
+	}
+	if _, err := SaveOutcome("", "", "20241201", "Food", "Unknown", "", decimal.NewFromInt(10), "", "", nil); err == nil {
+		t.Errorf("SaveOutcome() with unknown account expected error, got nil")
+	}
+
+	updated, err := UpdateById("", "", outcome.Id.Hex(), "", "", "Wallet", "", decimal.Zero, "", "", nil)
+	if err != nil {
+		t.Fatalf("UpdateById() error = %v", err)
+	}
+	if updated.AccountId.Hex() != walletId {
+		t.Errorf("UpdateById() account = %s, want %s", updated.AccountId.Hex(), walletId)
+	}
+}
+
+func TestPayeeOnSaveAndUpdate(t *testing.T) {
+	resetMappers(t, "Food")
+	starbucksId := payee_mapper.INSTANCE.InsertPayeeByEntity(model.PayeeEntity{Name: "Starbucks",
+		Rules: []model.PayeeRule{{MatchType: model.PayeeRuleContains, Pattern: "starbucks"}}})
+	cafeId := payee_mapper.INSTANCE.InsertPayeeByEntity(model.PayeeEntity{Name: "Corner Cafe"})
+
+	matched, err := SaveOutcome("", "", "20240401", "Food", "", "", decimal.NewFromInt(5), "STARBUCKS #1234", "", nil)
+	if err != nil || matched.PayeeId.Hex() != starbucksId {
+		t.Errorf("SaveOutcome() payee = %s, %v, want recognised as %s", matched.PayeeId.Hex(), err, starbucksId)
+	}
+	given, err := SaveOutcome("", "", "20240401", "Food", "", "", decimal.NewFromInt(5), "Starbucks Coffee", "Corner Cafe", nil)
+	if err != nil || given.PayeeId.Hex() != cafeId {
+		t.Errorf("SaveOutcome() payee = %s, %v, want the given %s", given.PayeeId.Hex(), err, cafeId)
+	}
+	if _, err := SaveOutcome("", "", "20240401", "Food", "", "", decimal.NewFromInt(5), "", "Unknown", nil); err == nil {
+		t.Errorf("SaveOutcome() with unknown payee expected error, got nil")
+	}
+
+	// A new description is recognised again only while the record has no payee
+	unknown, _ := SaveOutcome("", "", "20240402", "Food", "", "", decimal.NewFromInt(5), "card payment", "", nil)
+	if !unknown.PayeeId.IsZero() {
+		t.Errorf("SaveOutcome() payee = %s, want none", unknown.PayeeId.Hex())
+	}
+	updated, err := UpdateById("", "", unknown.Id.Hex(), "", "", "", "", decimal.Zero, "Starbucks reserve", "", nil)
+	if err != nil || updated.PayeeId.Hex() != starbucksId {
+		t.Errorf("UpdateById() payee = %s, %v, want recognised as %s", updated.PayeeId.Hex(), err, starbucksId)
+	}
+	updated, err = UpdateById("", "", given.Id.Hex(), "", "", "", "", decimal.Zero, "STARBUCKS #99", "", nil)
+	if err != nil || updated.PayeeId.Hex() != cafeId {
+		t.Errorf("UpdateById() payee = %s, %v, want %s kept", updated.PayeeId.Hex(), err, cafeId)
+	}
+	updated, err = UpdateById("", "", matched.Id.Hex(), "", "", "", "", decimal.Zero, "", "Corner Cafe", nil)
+	if err != nil || updated.PayeeId.Hex() != cafeId {
+		t.Errorf("UpdateById() payee = %s, %v, want %s", updated.PayeeId.Hex(), err, cafeId)
+	}
+}
+
+func TestCategorizeOnSave(t *testing.T) {
+	resetMappers(t, "Food", "Coffee", "Salary")
+	foodId := category_mapper.INSTANCE.GetCategoryByName("", "Food").Id
+	coffeeId := category_mapper.INSTANCE.GetCategoryByName("", "Coffee").Id
+	salaryId := category_mapper.INSTANCE.GetCategoryByName("", "Salary").Id
+	starbucksId := util.Convert2ObjectId(payee_mapper.INSTANCE.InsertPayeeByEntity(model.PayeeEntity{Name: "Starbucks",
+		Rules: []model.PayeeRule{{MatchType: model.PayeeRuleContains, Pattern: "starbucks"}}}))
+	if _, err := category_rule_mapper.INSTANCE.BulkInsertCategoryRules([]model.CategoryRuleEntity{
+		{Name: "Starbucks", Priority: 10, PayeeId: starbucksId, MaxAmount: decimal.NewFromInt(20),
+			CategoryId: coffeeId, Tags: []string{"caffeine"}},
+		{Name: "Groceries", MatchType: model.PayeeRuleRegex, Pattern: `(?i)^(lidl|aldi)\b`, CategoryId: foodId},
+		{Name: "Payroll", FlowType: model.FlowTypeIncome, MinAmount: decimal.NewFromInt(1000), CategoryId: salaryId},
+	}); err != nil {
+		t.Fatalf("BulkInsertCategoryRules() error = %v", err)
+	}
+
+	coffee, err := SaveOutcome("", "", "20240501", "", "", "", decimal.NewFromFloat(4.5), "STARBUCKS #1234", "", []string{"Work"})
+	if err != nil || coffee.CategoryId != coffeeId || len(coffee.Tags) != 2 || coffee.Tags[0] != "caffeine" {
+		t.Errorf("SaveOutcome() = %+v, %v, want Coffee tagged caffeine and work", coffee, err)
+	}
+	// The amount condition fails, so the next rule is tried
+	if _, err := SaveOutcome("", "", "20240501", "", "", "", decimal.NewFromInt(45), "STARBUCKS beans", "", nil); err == nil {
+		t.Errorf("SaveOutcome() above every rule's range expected error, got nil")
+	}
+	groceries, err := SaveOutcome("", "", "20240501", "", "", "", decimal.NewFromInt(45), "ALDI Berlin", "", nil)
+	if err != nil || groceries.CategoryId != foodId || groceries.Tags != nil {
+		t.Errorf("SaveOutcome() = %+v, %v, want Food without tags", groceries, err)
+	}
+	if _, err := SaveOutcome("", "", "20240501", "", "", "", decimal.NewFromInt(3000), "ACME", "", nil); err == nil {
+		t.Errorf("SaveOutcome() matching only an income rule expected error, got nil")
+	}
+	salary, err := SaveIncome("", "", "20240501", "", "", "", decimal.NewFromInt(3000), "ACME", "", nil)
+	if err != nil || salary.CategoryId != salaryId {
+		t.Errorf("SaveIncome() = %+v, %v, want Salary", salary, err)
+	}
+	// A given category always wins over the rules
+	given, err := SaveOutcome("", "", "20240501", "Food", "", "", decimal.NewFromInt(3), "STARBUCKS #1234", "", nil)
+	if err != nil || given.CategoryId != foodId || given.Tags != nil {
+		t.Errorf("SaveOutcome() = %+v, %v, want the given Food category", given, err)
+	}
+}
+
+func TestSuggestCategory(t *testing.T) {
+	resetMappers(t, "Food", "Coffee", "Rent", "Salary")
+	for _, outcome := range []struct {
+		category    string
+		amount      int64
+		description string
+	}{
+		{"Coffee", 5, "STARBUCKS #1234"},
+		{"Coffee", 4, "Starbucks latte"},
+		{"Coffee", 6, "blue bottle coffee"},
+		{"Food", 45, "ALDI Berlin"},
+		{"Food", 60, "LIDL market"},
+		{"Food", 12, "lunch at the market"},
+		{"Rent", 1200, "rent transfer"},
+	} {
+		if _, err := SaveOutcome("", "", "20240501", outcome.category, "", "", decimal.NewFromInt(outcome.amount),
+			outcome.description, "", nil); err != nil {
+			t.Fatalf("SaveOutcome() error = %v", err)
+		}
+	}
+	if _, err := SaveIncome("", "", "20240501", "Salary", "", "", decimal.NewFromInt(3000), "ACME coffee payroll", "", nil); err != nil {
+		t.Fatalf("SaveIncome() error = %v", err)
+	}
+
+	suggestionList, err := SuggestCategoryService("", "", "Starbucks #9876", decimal.NewFromInt(5), 0)
+	if err != nil || len(suggestionList) != 3 {
+		t.Fatalf("SuggestCategoryService() = %+v, %v, want 3 suggestions", suggestionList, err)
+	}
+	if suggestionList[0].CategoryName != "Coffee" || suggestionList[0].Confidence < 0.8 ||
+		suggestionList[0].Confidence < suggestionList[1].Confidence {
+		t.Errorf("SuggestCategoryService() = %+v, want Coffee first with high confidence", suggestionList)
+	}
+
+	// Both cash_flows at a market are Food, the limit keeps only the first suggestion
+	suggestionList, err = SuggestCategoryService("", model.FlowTypeOutcome, "market", decimal.NewFromInt(50), 1)
+	if err != nil || len(suggestionList) != 1 || suggestionList[0].CategoryName != "Food" {
+		t.Errorf("SuggestCategoryService() = %+v, %v, want only Food", suggestionList, err)
+	}
+
+	// Incomes learn apart from outcomes
+	suggestionList, err = SuggestCategoryService("", model.FlowTypeIncome, "coffee", decimal.Zero, 0)
+	if err != nil || len(suggestionList) != 1 || suggestionList[0].CategoryName != "Salary" || suggestionList[0].Confidence != 1 {
+		t.Errorf("SuggestCategoryService() = %+v, %v, want only Salary", suggestionList, err)
+	}
+
+	// Nothing is known about these words
+	suggestionList, err = SuggestCategoryService("", "", "#4711 unknown shop", decimal.NewFromInt(5), 0)
+	if err != nil || len(suggestionList) != 0 {
+		t.Errorf("SuggestCategoryService() = %+v, %v, want no suggestion", suggestionList, err)
+	}
+
+	if _, err := SuggestCategoryService("", "", " ", decimal.Zero, 0); err == nil {
+		t.Errorf("SuggestCategoryService() with a blank description expected error, got nil")
+	}
+	if _, err := SuggestCategoryService("", model.FlowTypeTransfer, "rent", decimal.Zero, 0); err == nil {
+		t.Errorf("SuggestCategoryService() for a transfer expected error, got nil")
+	}
+
+	// A deleted category is not suggested any more, even for its own words
+	coffee := category_mapper.INSTANCE.GetCategoryByName("", "Coffee")
+	category_mapper.INSTANCE.DeleteCategoryByObjectId(coffee.Id.Hex())
+	suggestionList, err = SuggestCategoryService("", "", "coffee at the market", decimal.NewFromInt(5), 0)
+	if err != nil || len(suggestionList) == 0 || suggestionList[0].CategoryName != "Food" {
+		t.Fatalf("SuggestCategoryService() after deleting Coffee = %+v, %v, want Food first", suggestionList, err)
+	}
+	for _, suggestion := range suggestionList {
+		if suggestion.CategoryId == coffee.Id || suggestion.CategoryName == "" {
+			t.Errorf("SuggestCategoryService() suggested %+v, want no deleted category", suggestion)
+		}
+	}
+}
+
+func TestTokenizeDescription(t *testing.T) {
+	tests := []struct {
+		description string
+		want        string
+	}{
+		{"STARBUCKS #1234", "starbucks"},
+		{"Amazon.de Mktp 2024-05-01 a", "amazon,de,mktp"},
+		{"全家 FamilyMart", "全,家,familymart"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			if got := strings.Join(tokenizeDescription(tt.description), ","); got != tt.want {
+				t.Errorf("tokenizeDescription() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchService(t *testing.T) {
+	resetMappers(t, "Food", "Rent")
+	foodId := category_mapper.INSTANCE.GetCategoryByName("", "Food").Id
+	category_mapper.INSTANCE.InsertCategoryByEntity(model.CategoryEntity{Name: "Coffee", ParentId: foodId})
+	account_mapper.INSTANCE.InsertAccountByEntity(model.AccountEntity{Name: "Bank", Type: model.AccountTypeBank})
+	for _, outcome := range []struct {
+		date        string
+		category    string
+		account     string
+		amount      int64
+		description string
+		tags        []string
+	}{
+		{"20240501", "Coffee", "Bank", 5, "Starbucks latte", []string{"work"}},
+		{"20240502", "Food", "", 45, "ALDI market", nil},
+		{"20240503", "Coffee", "", 4, "starbucks espresso, starbucks card", []string{"work", "morning"}},
+		{"20240510", "Rent", "", 1200, "Rent May", nil},
+	} {
+		if _, err := SaveOutcome("", "", outcome.date, outcome.category, outcome.account, "", decimal.NewFromInt(outcome.amount),
+			outcome.description, "", outcome.tags); err != nil {
+			t.Fatalf("SaveOutcome() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		searchDTO model.CashFlowSearchDTO
+		wantCount int64
+		want      string // descriptions of the page, in order
+	}{
+		{"text newest first", model.CashFlowSearchDTO{Text: "STARBUCKS"}, 2, "starbucks espresso, starbucks card|Starbucks latte"},
+		{"every word", model.CashFlowSearchDTO{Text: "starbucks latte"}, 1, "Starbucks latte"},
+		{"oldest first", model.CashFlowSearchDTO{Text: "starbucks", Order: "asc"}, 2, "Starbucks latte|starbucks espresso, starbucks card"},
+		{"relevance", model.CashFlowSearchDTO{Text: "starbucks", SortBy: "relevance", Order: "asc"}, 2, "starbucks espresso, starbucks card|Starbucks latte"},
+		{"category with children", model.CashFlowSearchDTO{CategoryName: "Food", Order: "asc"}, 3, "Starbucks latte|ALDI market|starbucks espresso, starbucks card"},
+		{"amount range", model.CashFlowSearchDTO{MinAmount: decimal.NewFromInt(10), MaxAmount: decimal.NewFromInt(100)}, 1, "ALDI market"},
+		{"all tags", model.CashFlowSearchDTO{Tags: []string{"Work", "morning"}}, 1, "starbucks espresso, starbucks card"},
+		{"account", model.CashFlowSearchDTO{AccountName: "Bank"}, 1, "Starbucks latte"},
+		{"date range", model.CashFlowSearchDTO{FromDate: "20240502", ToDate: "2024-05-03"}, 2, "starbucks espresso, starbucks card|ALDI market"},
+		{"flow type", model.CashFlowSearchDTO{FlowType: "income"}, 0, ""},
+		{"amount page", model.CashFlowSearchDTO{SortBy: "amount", Order: "asc", Limit: 2, Offset: 1}, 4, "Starbucks latte|ALDI market"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := SearchService("", tt.searchDTO)
+			if err != nil {
+				t.Fatalf("SearchService() error = %v", err)
+			}
+			var descriptionList []string
+			for _, cashFlow := range page.Data {
+				descriptionList = append(descriptionList, cashFlow.Description)
+			}
+			if page.TotalCount != tt.wantCount || strings.Join(descriptionList, "|") != tt.want {
+				t.Errorf("SearchService() = %q, %d, want %q, %d", descriptionList, page.TotalCount, tt.want, tt.wantCount)
+			}
+		})
+	}
+
+	for _, searchDTO := range []model.CashFlowSearchDTO{
+		{SortBy: "relevance"},
+		{SortBy: "payee"},
+		{Order: "up"},
+		{CategoryName: "Travel"},
+		{AccountName: "Cash"},
+		{FlowType: "GIFT"},
+		{MinAmount: decimal.NewFromInt(100), MaxAmount: decimal.NewFromInt(10)},
+		{FromDate: "20240510", ToDate: "20240501"},
+		{FromDate: "2024-13-01"},
+		{Limit: -1},
+		{Cursor: "not-a-cursor"},
+	} {
+		if _, err := SearchService("", searchDTO); err == nil {
+			t.Errorf("SearchService(%+v) expected error, got nil", searchDTO)
+		}
+	}
+}
+
+func TestQueryAll(t *testing.T) {
+	resetMappers(t, "Food", "Salary")
+	for day, amount := range []int64{30, 2000, 12, 45, 2100, 8} {
+		belongsDate := "2024060" + strconv.Itoa(day+1)
+		var err error
+		if amount > 1000 {
+			_, err = SaveIncome("", "", belongsDate, "Salary", "", "", decimal.NewFromInt(amount), "", "", nil)
+		} else {
+			_, err = SaveOutcome("", "", belongsDate, "Food", "", "", decimal.NewFromInt(amount), "", "", nil)
+		}
+		if err != nil {
+			t.Fatalf("save cash flow error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		cashType      string
+		sortBy, order string
+		limit, offset int
+		wantCount     int64
+		want          string // amounts of the page, in order
+	}{
+		{"newest first", "", "", "", 2, 0, 6, "8,2100"},
+		{"full page of one type", "outcome", "", "", 3, 0, 4, "8,45,12"},
+		{"last page of one type", "OUTCOME", "", "", 3, 3, 4, "30"},
+		{"smallest first", "outcome", "amount", "asc", 0, 0, 4, "8,12,30,45"},
+		{"oldest first", "income", "date", "asc", 0, 0, 2, "2000,2100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := QueryAll("", tt.cashType, "", tt.sortBy, tt.order, "", tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("QueryAll() error = %v", err)
+			}
+			var amountList []string
+			for _, cashFlow := range page.Data {
+				amountList = append(amountList, cashFlow.Amount.String())
+			}
+			if page.TotalCount != tt.wantCount || strings.Join(amountList, ",") != tt.want {
+				t.Errorf("QueryAll() = %v of %d, want %s of %d", amountList, page.TotalCount, tt.want, tt.wantCount)
+			}
+		})
+	}
+
+	for _, args := range [][2]string{{"gift", ""}, {"", "relevance"}, {"", "payee"}} {
+		if _, err := QueryAll("", args[0], "", args[1], "", "", 0, 0); err == nil {
+			t.Errorf("QueryAll(%q, sort %q) expected error, got nil", args[0], args[1])
+		}
+	}
+}
+
+func TestTagsLifecycle(t *testing.T) {
+	resetMappers(t, "Food", "Hotel")
+
+	dinner, err := SaveOutcome("", "", "20240401", "Food", "", "", decimal.NewFromInt(30), "dinner", "", []string{" Trip-Japan", "reimbursable", "trip-japan", ""})
+	if err != nil {
+		t.Fatalf("SaveOutcome() error = %v", err)
+	}
+	if len(dinner.Tags) != 2 || dinner.Tags[0] != "reimbursable" || dinner.Tags[1] != "trip-japan" {
+		t.Errorf("SaveOutcome() tags = %v, want [reimbursable trip-japan]", dinner.Tags)
+	}
+	if _, err := SaveOutcome("", "", "20240402", "Hotel", "", "", decimal.NewFromInt(120), "ryokan", "", []string{"trip-japan"}); err != nil {
+		t.Fatalf("SaveOutcome() error = %v", err)
+	}
+	if _, err := SaveOutcome("", "", "20240403", "Food", "", "", decimal.NewFromInt(8), "lunch", "", nil); err != nil {
+		t.Fatalf("SaveOutcome() error = %v", err)
+	}
+	if _, err := SaveOutcome("", "", "20240403", "Food", "", "", decimal.NewFromInt(8), "", "", []string{"trip japan"}); err == nil {
+		t.Errorf("SaveOutcome() with an invalid tag expected error, got nil")
+	}
+
+	tripPage, err := QueryAll("", "", "Trip-Japan", "", "", "", 0, 0)
+	if err != nil || tripPage.TotalCount != 2 || len(tripPage.Data) != 2 || tripPage.Data[0].Description != "ryokan" {
+		t.Errorf("QueryAll() by tag = %d records of %d, %v, want ryokan then dinner", len(tripPage.Data), tripPage.TotalCount, err)
+	}
+
+	summary, err := GetSummaryByMonth("", "202404", "")
+	if err != nil {
+		t.Fatalf("GetSummaryByMonth() error = %v", err)
+	}
+	tagBreakdown := singleTotals(t, summary).TagBreakdown
+	if !tagBreakdown["trip-japan"].Equal(decimal.NewFromInt(150)) ||
+		!tagBreakdown["reimbursable"].Equal(decimal.NewFromInt(30)) || len(tagBreakdown) != 2 {
+		t.Errorf("TagBreakdown = %v, want trip-japan 150 and reimbursable 30", tagBreakdown)
+	}
+
+	// nil keeps the tags, an empty list clears them
+	updated, err := UpdateById("", "", dinner.Id.Hex(), "", "", "", "", decimal.Zero, "team dinner", "", nil)
+	if err != nil || len(updated.Tags) != 2 {
+		t.Errorf("UpdateById() without tags = %v, %v, want the tags kept", updated.Tags, err)
+	}
+	updated, err = UpdateById("", "", dinner.Id.Hex(), "", "", "", "", decimal.Zero, "", "", []string{})
+	if err != nil || updated.Tags != nil {
+		t.Errorf("UpdateById() with empty tags = %v, %v, want them cleared", updated.Tags, err)
+	}
+	if page, _ := QueryAll("", "", "reimbursable", "", "", "", 0, 0); page.TotalCount != 0 {
+		t.Errorf("QueryAll() by a cleared tag counted %d, want 0", page.TotalCount)
+	}
+}
+
+func TestQueryAllByCursor(t *testing.T) {
+	resetMappers(t, "Food")
+	// Two records share each date so the id breaks the ties
+	for _, amount := range []int64{1, 2, 3, 4, 5, 6, 7} {
+		belongsDate := "2024070" + strconv.FormatInt((amount+1)/2, 10)
+		if _, err := SaveOutcome("", "", belongsDate, "Food", "", "", decimal.NewFromInt(amount), "", "", nil); err != nil {
+			t.Fatalf("SaveOutcome() error = %v", err)
+		}
+	}
+	readAmounts := func(page model.CashFlowPage) string {
+		var amountList []string
+		for _, cashFlow := range page.Data {
+			amountList = append(amountList, cashFlow.Amount.String())
+		}
+		return strings.Join(amountList, ",")
+	}
+
+	// Forward to the end
+	var pageList []model.CashFlowPage
+	cursor := ""
+	for {
+		page, err := QueryAll("", "", "", "", "", cursor, 3, 0)
+		if err != nil {
+			t.Fatalf("QueryAll() cursor %q error = %v", cursor, err)
+		}
+		pageList = append(pageList, page)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	var gotList []string
+	for _, page := range pageList {
+		gotList = append(gotList, readAmounts(page))
+	}
+	if strings.Join(gotList, "|") != "7,6,5|4,3,2|1" {
+		t.Errorf("forward pages = %q, want 7,6,5|4,3,2|1", gotList)
+	}
+	if pageList[0].PrevCursor != "" || pageList[2].PrevCursor == "" || pageList[2].TotalCount != 7 {
+		t.Errorf("first page prev %q, last page prev %q, want only the last one set", pageList[0].PrevCursor, pageList[2].PrevCursor)
+	}
+
+	// And back again
+	page, err := QueryAll("", "", "", "", "", pageList[2].PrevCursor, 3, 0)
+	if err != nil || readAmounts(page) != "4,3,2" || page.PrevCursor == "" || page.NextCursor == "" {
+		t.Errorf("QueryAll() back one page = %s, prev %q, next %q, %v, want 4,3,2 with both cursors", readAmounts(page), page.PrevCursor, page.NextCursor, err)
+	}
+	page, err = QueryAll("", "", "", "", "", page.PrevCursor, 3, 0)
+	if err != nil || readAmounts(page) != "7,6,5" || page.PrevCursor != "" {
+		t.Errorf("QueryAll() back to the start = %s, prev %q, %v, want 7,6,5 without prev", readAmounts(page), page.PrevCursor, err)
+	}
+
+	// Records saved while paging neither repeat nor shift the following page
+	if _, err := SaveOutcome("", "", "20240709", "Food", "", "", decimal.NewFromInt(99), "", "", nil); err != nil {
+		t.Fatalf("SaveOutcome() error = %v", err)
+	}
+	page, err = QueryAll("", "", "", "", "", pageList[0].NextCursor, 3, 0)
+	if err != nil || readAmounts(page) != "4,3,2" || page.TotalCount != 8 {
+		t.Errorf("QueryAll() after an insert = %s of %d, %v, want 4,3,2 of 8", readAmounts(page), page.TotalCount, err)
+	}
+
+	for _, args := range [][2]string{{"amount", ""}, {"", "5"}} {
+		offset, _ := strconv.Atoi(args[1])
+		if _, err := QueryAll("", "", "", args[0], "", pageList[0].NextCursor, 3, offset); err == nil {
+			t.Errorf("QueryAll() cursor with sort %q offset %d expected error, got nil", args[0], offset)
+		}
+	}
+}
+
+func TestSplitLifecycle(t *testing.T) {
+	resetMappers(t, "Groceries", "Household", "Gifts")
+
+	receipt, err := SaveOutcome("", "", "20240501", "Groceries", "", "", decimal.NewFromFloat(42.5), "supermarket", "", nil)
+	if err != nil {
+		t.Fatalf("SaveOutcome() error = %v", err)
+	}
+	if _, err := SaveOutcome("", "", "20240502", "Groceries", "", "", decimal.NewFromInt(10), "bakery", "", nil); err != nil {
+		t.Fatalf("SaveOutcome() error = %v", err)
+	}
+
+	lines := []model.CashFlowSplitDTO{
+		{CategoryName: "Household", Amount: decimal.NewFromFloat(7.4), Description: "detergent"},
+		{CategoryName: "Groceries", Amount: decimal.NewFromFloat(30.1)},
+		{CategoryName: "Gifts", Amount: decimal.NewFromInt(5), Description: "birthday card"},
+	}
+	if _, err := SplitById("", "", receipt.Id.Hex(), lines[:2]); err == nil {
+		t.Errorf("SplitById() with lines short of the amount expected error, got nil")
+	}
+	if _, err := SplitById("", "", receipt.Id.Hex(), append(lines[:2:2], model.CashFlowSplitDTO{CategoryName: "Toys", Amount: decimal.NewFromInt(5)})); err == nil {
+		t.Errorf("SplitById() with an unknown category expected error, got nil")
+	}
+	split, err := SplitById("", "", receipt.Id.Hex(), lines)
+	if err != nil {
+		t.Fatalf("SplitById() error = %v", err)
+	}
+	household := category_mapper.INSTANCE.GetCategoryByName("", "Household")
+	if len(split.Splits) != 3 || split.CategoryId != household.Id {
+		t.Errorf("SplitById() = %+v, want three lines and the first line's category", split)
+	}
+
+	summary, err := GetSummaryByMonth("", "202405", "")
+	if err != nil {
+		t.Fatalf("GetSummaryByMonth() error = %v", err)
+	}
+	totals := singleTotals(t, summary)
+	if !totals.CategoryBreakdown["Groceries"].Equal(decimal.NewFromFloat(40.1)) ||
+		!totals.CategoryBreakdown["Household"].Equal(decimal.NewFromFloat(7.4)) ||
+		!totals.CategoryBreakdown["Gifts"].Equal(decimal.NewFromInt(5)) {
+		t.Errorf("CategoryBreakdown = %v, want Groceries 40.10, Household 7.40 and Gifts 5", totals.CategoryBreakdown)
+	}
+	if summary.TransactionCount != 2 || !totals.TotalExpense.Equal(decimal.NewFromFloat(52.5)) {
+		t.Errorf("GetSummaryByMonth() = %d records and %s expense, want 2 and 52.50", summary.TransactionCount, totals.TotalExpense)
+	}
+	converted, err := GetSummaryByMonth("", "202405", "USD")
+	if err != nil || !singleTotals(t, converted).CategoryBreakdown["Gifts"].Equal(decimal.NewFromInt(5)) {
+		t.Errorf("GetSummaryByMonth() in USD = %v, %v, want Gifts 5", converted, err)
+	}
+
+	// Category and amount follow the lines, the rest can still be updated
+	if _, err := UpdateById("", "", receipt.Id.Hex(), "", "", "", "", decimal.NewFromInt(50), "", "", nil); err == nil {
+		t.Errorf("UpdateById() of a split amount expected error, got nil")
+	}
+	if _, err := UpdateById("", "", receipt.Id.Hex(), "", "Gifts", "", "", decimal.Zero, "", "", nil); err == nil {
+		t.Errorf("UpdateById() of a split category expected error, got nil")
+	}
+	if updated, err := UpdateById("", "", receipt.Id.Hex(), "", "", "", "", decimal.Zero, "weekly shop", "", nil); err != nil || len(updated.Splits) != 3 {
+		t.Errorf("UpdateById() of the description = %+v, %v, want the lines kept", updated, err)
+	}
+
+	unsplit, err := SplitById("", "", receipt.Id.Hex(), nil)
+	if err != nil || unsplit.Splits != nil || unsplit.CategoryId != household.Id {
+		t.Errorf("SplitById() without lines = %+v, %v, want a single Household cash flow", unsplit, err)
+	}
+}
+
+func TestTransferLifecycle(t *testing.T) {
+	resetMappers(t, "Salary")
+	bankId := account_mapper.INSTANCE.InsertAccountByEntity(model.AccountEntity{Name: "Bank", Type: model.AccountTypeBank})
+	savingsId := account_mapper.INSTANCE.InsertAccountByEntity(model.AccountEntity{Name: "Savings", Type: model.AccountTypeBank})
+	account_mapper.INSTANCE.InsertAccountByEntity(model.AccountEntity{Name: "Wallet", Type: model.AccountTypeCash})
+
+	if _, err := SaveTransfer("", "", "20241201", "Bank", "Bank", decimal.NewFromInt(10), ""); err == nil {
+		t.Errorf("SaveTransfer() to the same account expected error, got nil")
+	}
+	if _, err := SaveIncome("", "", "20241201", "Salary", "Bank", "", decimal.NewFromInt(3000), "pay", "", nil); err != nil {
+		t.Fatalf("SaveIncome() error = %v", err)
+	}
+
+	legList, err := SaveTransfer("", "", "20241201", "Bank", "Savings", decimal.NewFromFloat(500.255), "saving")
+	if err != nil || len(legList) != 2 {
+		t.Fatalf("SaveTransfer() = %+v, %v", legList, err)
+	}
+	outgoing, incoming := legList[0], legList[1]
+	if outgoing.AccountId.Hex() != bankId || !outgoing.Amount.Equal(decimal.NewFromFloat(-500.26)) || outgoing.LinkedId != incoming.Id {
+		t.Errorf("outgoing leg = %+v", outgoing)
+	}
+	if incoming.AccountId.Hex() != savingsId || !incoming.Amount.Equal(decimal.NewFromFloat(500.26)) || incoming.LinkedId != outgoing.Id {
+		t.Errorf("incoming leg = %+v", incoming)
+	}
+
+	// Transfers are neither income nor expense
+	summary, err := GetSummaryByMonth("", "202412", "")
+	if err != nil {
+		t.Fatalf("GetSummaryByMonth() error = %v", err)
+	}
+	if totals := singleTotals(t, summary); summary.TransactionCount != 1 ||
+		!totals.TotalIncome.Equal(decimal.NewFromInt(3000)) || !totals.TotalExpense.IsZero() {
+		t.Errorf("GetSummaryByMonth() = %+v, want only the income", summary)
+	}
+
+	// Updating one leg updates the other, keeping the direction
+	if _, err := UpdateById("", "", incoming.Id.Hex(), "20241202", "", "", "", decimal.NewFromInt(200), "moved", "", nil); err != nil {
+		t.Fatalf("UpdateById() error = %v", err)
+	}
+	outgoing, _ = QueryById("", outgoing.Id.Hex())
+	if !outgoing.Amount.Equal(decimal.NewFromInt(-200)) || outgoing.Description != "moved" ||
+		util.FormatDateToStringWithoutDash(outgoing.BelongsDate) != "20241202" {
+		t.Errorf("linked leg after update = %+v", outgoing)
+	}
+	if _, err := UpdateById("", "", incoming.Id.Hex(), "", "", "Bank", "", decimal.Zero, "", "", nil); err == nil {
+		t.Errorf("UpdateById() moving both legs into one account expected error, got nil")
+	}
+	if _, err := UpdateById("", "", incoming.Id.Hex(), "", "Salary", "", "", decimal.Zero, "", "", nil); err == nil {
+		t.Errorf("UpdateById() setting a category on a transfer expected error, got nil")
+	}
+	if _, err := UpdateById("", "", incoming.Id.Hex(), "", "", "", "", decimal.Zero, "", "Bank", nil); err == nil {
+		t.Errorf("UpdateById() setting a payee on a transfer expected error, got nil")
+	}
+	if _, err := UpdateById("", "", incoming.Id.Hex(), "", "", "Wallet", "", decimal.Zero, "", "", nil); err != nil {
+		t.Errorf("UpdateById() error = %v", err)
+	}
+
+	// Deleting one leg deletes the other
+	if _, err := DeleteById("", outgoing.Id.Hex()); err != nil {
+		t.Fatalf("DeleteById() error = %v", err)
+	}
+	if remaining, _ := QueryById("", incoming.Id.Hex()); !remaining.IsEmpty() {
+		t.Errorf("linked leg still exists after delete: %+v", remaining)
+	}
+}
+
+func TestMultiCurrency(t *testing.T) {
+	resetMappers(t, "Food", "Salary")
+	account_mapper.INSTANCE.InsertAccountByEntity(model.AccountEntity{Name: "Bank", Type: model.AccountTypeBank, Currency: "USD"})
+	account_mapper.INSTANCE.InsertAccountByEntity(model.AccountEntity{Name: "Paris", Type: model.AccountTypeCash, Currency: "EUR"})
+	for _, rate := range []struct {
+		rate          string
+		effectiveDate string
+	}{
+		{"1.1", "2024-01-01"},
+		{"1.2", "2024-06-01"},
+	} {
+		if _, err := exchange_rate_service.CreateService("", "EUR", "USD", decimal.RequireFromString(rate.rate), rate.effectiveDate); err != nil {
+			t.Fatalf("CreateService() error = %v", err)
+		}
+	}
+
+	if _, err := SaveOutcome("", "", "20240301", "Food", "Paris", "USD", decimal.NewFromInt(100), "", "", nil); err == nil {
+		t.Errorf("SaveOutcome() in a currency other than the account's expected error, got nil")
+	}
+	march, err := SaveOutcome("", "", "20240301", "Food", "Paris", "", decimal.NewFromInt(100), "", "", nil)
+	if err != nil || march.Currency != "EUR" {
+		t.Fatalf("SaveOutcome() = %+v, %v, want the account's currency", march, err)
+	}
+	if _, err := SaveOutcome("", "", "20240701", "Food", "Paris", "eur", decimal.NewFromInt(100), "", "", nil); err != nil {
+		t.Fatalf("SaveOutcome() error = %v", err)
+	}
+	if income, err := SaveIncome("", "", "20240301", "Salary", "", "", decimal.NewFromInt(1000), "", "", nil); err != nil || income.Currency != "USD" {
+		t.Fatalf("SaveIncome() = %+v, %v, want the default currency", income, err)
+	}
+
+	// Each record is converted at the rate in effect on its own date
+	tests := []struct {
+		baseCurrency string
+		wantIncome   string
+		wantExpense  string
+	}{
+		{"USD", "1000", "230"},
+		{"eur", "909.09", "200"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.baseCurrency, func(t *testing.T) {
+			summary, err := GetSummaryByYear("", "2024", tt.baseCurrency)
+			if err != nil {
+				t.Fatalf("GetSummaryByYear() error = %v", err)
+			}
+			totals := singleTotals(t, summary)
+			if !totals.TotalIncome.Equal(decimal.RequireFromString(tt.wantIncome)) ||
+				!totals.TotalExpense.Equal(decimal.RequireFromString(tt.wantExpense)) || summary.TransactionCount != 3 {
+				t.Errorf("GetSummaryByYear() = %+v, want income %v and expense %v", summary, tt.wantIncome, tt.wantExpense)
+			}
+			if !totals.CategoryBreakdown["Food"].Equal(decimal.RequireFromString(tt.wantExpense)) {
+				t.Errorf("CategoryBreakdown = %v, want Food %v", totals.CategoryBreakdown, tt.wantExpense)
+			}
+		})
+	}
+
+	// Without a base currency nothing is converted, and euros and dollars are reported apart
+	recorded, err := GetSummaryByYear("", "2024", "")
+	if err != nil || len(recorded.Totals) != 2 || recorded.Totals[0].Currency != "EUR" || recorded.Totals[1].Currency != "USD" ||
+		!recorded.Totals[0].TotalExpense.Equal(decimal.NewFromInt(200)) || !recorded.Totals[1].TotalIncome.Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("GetSummaryByYear() = %+v, %v, want 200 EUR expense and 1000 USD income", recorded, err)
+	}
+	if _, err := GetSummaryByYear("", "2024", "JPY"); err == nil {
+		t.Errorf("GetSummaryByYear() without a JPY rate expected error, got nil")
+	}
+
+	// The incoming leg of a cross-currency transfer is converted
+	legList, err := SaveTransfer("", "", "20240301", "Bank", "Paris", decimal.NewFromInt(110), "")
+	if err != nil {
+		t.Fatalf("SaveTransfer() error = %v", err)
+	}
+	if legList[0].Currency != "USD" || !legList[0].Amount.Equal(decimal.NewFromInt(-110)) ||
+		legList[1].Currency != "EUR" || !legList[1].Amount.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("SaveTransfer() legs = %+v", legList)
+	}
+	if _, err := UpdateById("", "", legList[0].Id.Hex(), "20240701", "", "", "", decimal.Zero, "", "", nil); err != nil {
+		t.Fatalf("UpdateById() error = %v", err)
+	}
+	if incoming, _ := QueryById("", legList[1].Id.Hex()); !incoming.Amount.Equal(decimal.NewFromFloat(91.67)) {
+		t.Errorf("incoming leg after moving the date = %+v, want 91.67 at the July rate", incoming)
+	}
+}
+
+// singleTotals returns the only currency the summary reports, failing the test otherwise
+func singleTotals(t *testing.T, summary *Summary) CurrencySummary {
+	t.Helper()
+	if len(summary.Totals) != 1 {
+		t.Fatalf("Totals = %+v, want a single currency", summary.Totals)
+	}
+	return summary.Totals[0]
+}
@@ -0,0 +1,85 @@
+package cash_flow_service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"github.com/shopspring/decimal"
+)
+
+// SplitById shares a cash flow among several categories, the line amounts have to add up to its amount.
+// The cash flow takes the category of the first line, no lines at all turn it back into a single category.
+func SplitById(ownerPlainId, userPlainId, plainId string, lines []model.CashFlowSplitDTO) (model.CashFlowEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.CashFlowEntity{}, err
+	}
+
+	existingEntity := cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(plainId)
+	if existingEntity.IsEmpty() || !existingEntity.IsOwnedBy(ownerPlainId) {
+		return model.CashFlowEntity{}, errors.New("cash_flow not found")
+	}
+	// A transfer has no category to share
+	if existingEntity.FlowType == model.FlowTypeTransfer {
+		return model.CashFlowEntity{}, errors.New("a transfer can not be split")
+	}
+
+	splits, err := buildSplits(ownerPlainId, existingEntity.Amount, lines)
+	if err != nil {
+		return model.CashFlowEntity{}, err
+	}
+	if len(splits) > 0 {
+		existingEntity.CategoryId = splits[0].CategoryId
+	}
+	existingEntity.Splits = splits
+	existingEntity.ModifiedBy = util.ConvertOwner2ObjectId(userPlainId)
+	existingEntity.ModifyTime = time.Now()
+
+	updatedEntity := cash_flow_mapper.INSTANCE.UpdateCashFlowByEntity(plainId, existingEntity)
+	if updatedEntity.IsEmpty() {
+		return model.CashFlowEntity{}, errors.New("failed to update cash_flow")
+	}
+	return updatedEntity, nil
+}
+
+// buildSplits checks the lines against the cash flow amount and looks their categories up
+// among the owner's, no lines gives nil
+func buildSplits(ownerPlainId string, amount decimal.Decimal, lines []model.CashFlowSplitDTO) ([]model.CashFlowSplit, error) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	splits := make([]model.CashFlowSplit, 0, len(lines))
+	lineAmounts := make([]decimal.Decimal, 0, len(lines))
+	for _, line := range lines {
+		if err := validation.ValidateCategoryName(line.CategoryName); err != nil {
+			return nil, err
+		}
+		if err := validation.ValidateDescription(line.Description); err != nil {
+			return nil, err
+		}
+
+		categoryEntity := category_mapper.INSTANCE.GetCategoryByName(ownerPlainId, line.CategoryName)
+		if categoryEntity.IsEmpty() {
+			return nil, errors.New("category does not exist: " + line.CategoryName)
+		}
+
+		// 取小數點後兩位
+		lineAmount := line.Amount.Round(2)
+		lineAmounts = append(lineAmounts, lineAmount)
+		splits = append(splits, model.CashFlowSplit{
+			CategoryId:  categoryEntity.Id,
+			Amount:      lineAmount,
+			Description: line.Description,
+		})
+	}
+
+	if err := validation.ValidateSplitLines(amount, lineAmounts); err != nil {
+		return nil, err
+	}
+	return splits, nil
+}
@@ -0,0 +1,235 @@
+package cash_flow_service
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultSuggestionLimit is how many categories are suggested when no limit is given
+const defaultSuggestionLimit = 3
+
+// CategorySuggestion is a category a cash_flow likely belongs to, Confidence is between 0 and 1
+// and the confidences of every known category add up to 1
+type CategorySuggestion struct {
+	CategoryId   primitive.ObjectID `json:"category_id"`
+	CategoryName string             `json:"category_name"`
+	Confidence   float64            `json:"confidence"`
+}
+
+// CategorySuggester ranks categories with a naive Bayes model trained on the saved cash_flows,
+// one per flow type. It is built once and reused across many records, nothing leaves the database.
+type CategorySuggester struct {
+	modelMap        map[string]*naiveBayesModel
+	categoryNameMap map[primitive.ObjectID]string // blank for categories deleted since
+}
+
+type naiveBayesModel struct {
+	documentCount  int
+	vocabulary     map[string]bool
+	categoryList   []primitive.ObjectID // in the order first seen, so that ties are stable
+	categoryCounts map[primitive.ObjectID]*categoryTokenCounts
+}
+
+type categoryTokenCounts struct {
+	documentCount int
+	tokenTotal    int
+	tokenCounts   map[string]int
+}
+
+// NewCategorySuggester trains on every income and outcome of the owner, a split cash_flow teaches each
+// of its lines. Transfers carry no category and are left out, so are the lines of deleted categories.
+func NewCategorySuggester(ownerPlainId string) CategorySuggester {
+	suggester := CategorySuggester{
+		modelMap:        map[string]*naiveBayesModel{},
+		categoryNameMap: map[primitive.ObjectID]string{},
+	}
+	for _, cashFlow := range cash_flow_mapper.INSTANCE.GetCashFlowsByOwnerId(ownerPlainId, 0, 0) {
+		if cashFlow.FlowType == model.FlowTypeTransfer {
+			continue
+		}
+		if len(cashFlow.Splits) == 0 {
+			suggester.learn(cashFlow.FlowType, cashFlow.CategoryId, cashFlow.Description, cashFlow.Amount)
+			continue
+		}
+		for _, split := range cashFlow.Splits {
+			suggester.learn(cashFlow.FlowType, split.CategoryId,
+				cashFlow.Description+" "+split.Description, split.Amount)
+		}
+	}
+	return suggester
+}
+
+func (suggester CategorySuggester) learn(flowType string, categoryId primitive.ObjectID,
+	description string, amount decimal.Decimal) {
+	if categoryId.IsZero() || suggester.categoryName(categoryId) == "" {
+		return
+	}
+
+	bayesModel, found := suggester.modelMap[flowType]
+	if !found {
+		bayesModel = &naiveBayesModel{
+			vocabulary:     map[string]bool{},
+			categoryCounts: map[primitive.ObjectID]*categoryTokenCounts{},
+		}
+		suggester.modelMap[flowType] = bayesModel
+	}
+
+	counts, found := bayesModel.categoryCounts[categoryId]
+	if !found {
+		counts = &categoryTokenCounts{tokenCounts: map[string]int{}}
+		bayesModel.categoryCounts[categoryId] = counts
+		bayesModel.categoryList = append(bayesModel.categoryList, categoryId)
+	}
+
+	bayesModel.documentCount++
+	counts.documentCount++
+	for _, token := range append(tokenizeDescription(description), amountBucketToken(amount)) {
+		bayesModel.vocabulary[token] = true
+		counts.tokenCounts[token]++
+		counts.tokenTotal++
+	}
+}
+
+// categoryName looks the category up once, blank when it has been deleted
+func (suggester CategorySuggester) categoryName(categoryId primitive.ObjectID) string {
+	categoryName, found := suggester.categoryNameMap[categoryId]
+	if !found {
+		categoryName = category_mapper.INSTANCE.GetCategoryByObjectId(categoryId.Hex()).Name
+		suggester.categoryNameMap[categoryId] = categoryName
+	}
+	return categoryName
+}
+
+// Suggest ranks the categories for a cash_flow, the most likely first. Nothing is suggested when
+// no word of the description has been seen on a cash_flow of this flow type before.
+func (suggester CategorySuggester) Suggest(flowType, description string, amount decimal.Decimal) []CategorySuggestion {
+	bayesModel, found := suggester.modelMap[flowType]
+	if !found {
+		return []CategorySuggestion{}
+	}
+
+	// Words never seen tell nothing about any category, they are left out
+	var knownTokenList []string
+	for _, token := range tokenizeDescription(description) {
+		if bayesModel.vocabulary[token] {
+			knownTokenList = append(knownTokenList, token)
+		}
+	}
+	if len(knownTokenList) == 0 {
+		return []CategorySuggestion{}
+	}
+	if bucketToken := amountBucketToken(amount); bayesModel.vocabulary[bucketToken] {
+		knownTokenList = append(knownTokenList, bucketToken)
+	}
+
+	// log P(category) + sum of log P(token | category), with add-one smoothing
+	vocabularySize := float64(len(bayesModel.vocabulary))
+	logScoreList := make([]float64, len(bayesModel.categoryList))
+	maxLogScore := math.Inf(-1)
+	for index, categoryId := range bayesModel.categoryList {
+		counts := bayesModel.categoryCounts[categoryId]
+		logScore := math.Log(float64(counts.documentCount) / float64(bayesModel.documentCount))
+		for _, token := range knownTokenList {
+			logScore += math.Log(float64(counts.tokenCounts[token]+1) / (float64(counts.tokenTotal) + vocabularySize))
+		}
+		logScoreList[index] = logScore
+		maxLogScore = math.Max(maxLogScore, logScore)
+	}
+
+	// Turn the scores into probabilities, shifted by the highest one to stay within float range
+	scoreTotal := 0.0
+	for index := range logScoreList {
+		logScoreList[index] = math.Exp(logScoreList[index] - maxLogScore)
+		scoreTotal += logScoreList[index]
+	}
+
+	suggestionList := make([]CategorySuggestion, 0, len(bayesModel.categoryList))
+	for index, categoryId := range bayesModel.categoryList {
+		suggestionList = append(suggestionList, CategorySuggestion{
+			CategoryId:   categoryId,
+			CategoryName: suggester.categoryNameMap[categoryId],
+			Confidence:   math.Round(logScoreList[index]/scoreTotal*10000) / 10000,
+		})
+	}
+	sort.SliceStable(suggestionList, func(i, j int) bool {
+		return suggestionList[i].Confidence > suggestionList[j].Confidence
+	})
+	return suggestionList
+}
+
+// SuggestCategoryService ranks the categories a cash_flow with this description and amount likely belongs to,
+// learning from the cash_flows the owner already saved. A blank flow type means OUTCOME, a limit of 0 the default.
+func SuggestCategoryService(ownerPlainId, flowType, description string, amount decimal.Decimal, limit int) ([]CategorySuggestion, error) {
+	flowType = strings.ToUpper(strings.TrimSpace(flowType))
+	if flowType == "" {
+		flowType = model.FlowTypeOutcome
+	}
+	if flowType != model.FlowTypeIncome && flowType != model.FlowTypeOutcome {
+		return nil, validation.NewValidationError("flow_type", "must be INCOME or OUTCOME")
+	}
+	if err := validation.ValidateRequired("description", strings.TrimSpace(description)); err != nil {
+		return nil, err
+	}
+	if err := validation.ValidateDescription(description); err != nil {
+		return nil, err
+	}
+	if limit < 0 {
+		return nil, validation.NewValidationError("limit", "must not be negative")
+	}
+	if limit == 0 {
+		limit = defaultSuggestionLimit
+	}
+
+	suggestionList := NewCategorySuggester(ownerPlainId).Suggest(flowType, description, amount.Round(2))
+	if len(suggestionList) > limit {
+		suggestionList = suggestionList[:limit]
+	}
+	return suggestionList, nil
+}
+
+// tokenizeDescription splits a description into lowercase words. Numbers, such as store or
+// receipt numbers, and single letters are dropped; Chinese and Japanese characters count one by one.
+func tokenizeDescription(description string) []string {
+	var tokenList []string
+	var word []rune
+	flushWord := func() {
+		if len(word) > 1 && strings.IndexFunc(string(word), unicode.IsLetter) >= 0 {
+			tokenList = append(tokenList, string(word))
+		}
+		word = word[:0]
+	}
+
+	for _, character := range strings.ToLower(description) {
+		switch {
+		case unicode.In(character, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			flushWord()
+			tokenList = append(tokenList, string(character))
+		case unicode.IsLetter(character) || unicode.IsDigit(character):
+			word = append(word, character)
+		default:
+			flushWord()
+		}
+	}
+	flushWord()
+	return tokenList
+}
+
+// amountBucketToken puts an amount into a bucket doubling in size, 8 to 16, 16 to 32 and so on,
+// so that a coffee and a rent payment look different even with the same description
+func amountBucketToken(amount decimal.Decimal) string {
+	value, _ := amount.Abs().Float64()
+	if value < 1 {
+		return "#amount:0"
+	}
+	return "#amount:" + strconv.Itoa(int(math.Log2(value))+1)
+}
@@ -2,26 +2,42 @@ package cash_flow_service
 
 import (
 	"errors"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
 	"github.com/macar-x/cashlens/mapper/category_mapper"
 	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
 	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// Summary represents financial summary data
+// Summary represents financial summary data. Amounts are never added across currencies: Totals holds
+// the base currency every record was converted into, or without one an entry per currency recorded
 type Summary struct {
-	TotalIncome       float64
-	TotalExpense      float64
-	Balance           float64
-	TransactionCount  int
-	CategoryBreakdown map[string]float64
+	Currency         string // the base currency, blank when amounts are reported as recorded
+	TransactionCount int
+	Totals           []CurrencySummary
 }
 
-// GetSummary returns financial summary for a given period
-func GetSummary(period, date string) (*Summary, error) {
+// CurrencySummary represents the income, expense and breakdowns of the period in one currency
+type CurrencySummary struct {
+	Currency          string
+	TotalIncome       decimal.Decimal
+	TotalExpense      decimal.Decimal
+	Balance           decimal.Decimal
+	CategoryBreakdown map[string]decimal.Decimal
+	TagBreakdown      map[string]decimal.Decimal // a cash flow with several tags counts toward each of them
+}
+
+// GetSummary returns the financial summary of the owner's ledger for a given period.
+// With a baseCurrency every record is converted at the rate in effect on its BelongsDate,
+// without one the amounts are summed as recorded, per currency.
+func GetSummary(ownerPlainId, period, date, baseCurrency string) (*Summary, error) {
 	validPeriods := map[string]bool{
 		"daily":   true,
 		"monthly": true,
@@ -39,7 +55,7 @@ func GetSummary(period, date string) (*Summary, error) {
 	switch period {
 	case "daily":
 		// Date format: YYYY-MM-DD
-		fromDate = util.FormatDateFromStringWithoutDash(date)
+		fromDate = util.FormatDateFromStringWithOptionalDash(date)
 		if fromDate.IsZero() {
 			return nil, errors.New("invalid date format for daily, use YYYY-MM-DD")
 		}
@@ -64,35 +80,145 @@ func GetSummary(period, date string) (*Summary, error) {
 		toDate = fromDate.AddDate(1, 0, -1) // Last day of year
 	}
 
-	// Query transactions for period
-	summary := &Summary{
-		CategoryBreakdown: make(map[string]float64),
+	if baseCurrency != "" {
+		return buildSummaryInCurrency(ownerPlainId, fromDate, toDate, baseCurrency)
+	}
+
+	// Totals and category breakdown come from one aggregation over the whole period, tags from another
+	summaryStatList := cash_flow_mapper.INSTANCE.GetCashFlowSummaryByDateRange(ownerPlainId, fromDate, toDate)
+	tagStatList := cash_flow_mapper.INSTANCE.GetCashFlowTagSummaryByDateRange(ownerPlainId, fromDate, toDate)
+	return buildSummary(summaryStatList, tagStatList), nil
+}
+
+// buildSummaryInCurrency converts each record of the period into baseCurrency before adding it up,
+// rates differ from day to day so this cannot be left to the database aggregation.
+// The owner's rates are loaded once and every record is converted in memory.
+func buildSummaryInCurrency(ownerPlainId string, fromDate, toDate time.Time, baseCurrency string) (*Summary, error) {
+	baseCurrency = exchange_rate_service.NormalizeCurrency(baseCurrency)
+	if err := validation.ValidateCurrency(baseCurrency); err != nil {
+		return nil, err
 	}
 
-	currentDate := fromDate
-	for !currentDate.After(toDate) {
-		dayResults := cash_flow_mapper.INSTANCE.GetCashFlowsByBelongsDate(currentDate)
+	summary := &Summary{Currency: baseCurrency}
+	totals := currencyTotals(summary, baseCurrency)
+	categoryNameMap := make(map[primitive.ObjectID]string)
+	rateConverter := exchange_rate_service.NewRateConverter(ownerPlainId)
+
+	for _, cashFlow := range cash_flow_mapper.INSTANCE.GetCashFlowsByDateRange(ownerPlainId, fromDate, toDate) {
+		// Transfers only move money between accounts, they are neither income nor expense
+		if cashFlow.FlowType == model.FlowTypeTransfer {
+			continue
+		}
 
-		for _, cashFlow := range dayResults {
-			summary.TransactionCount++
+		summary.TransactionCount++
 
-			if cashFlow.FlowType == model.FlowTypeIncome {
-				summary.TotalIncome += cashFlow.Amount
-			} else {
-				summary.TotalExpense += cashFlow.Amount
+		// Split lines are converted one by one, so the breakdown adds up to the totals
+		convertedTotal := decimal.Zero
+		for _, line := range cashFlow.CategoryLines() {
+			convertedAmount, err := rateConverter.Convert(line.Amount, cashFlow.Currency, baseCurrency, cashFlow.BelongsDate)
+			if err != nil {
+				return nil, err
 			}
+			convertedTotal = convertedTotal.Add(convertedAmount)
 
-			// Get category name for breakdown
-			category := category_mapper.INSTANCE.GetCategoryByObjectId(cashFlow.CategoryId.Hex())
-			if !category.IsEmpty() {
-				summary.CategoryBreakdown[category.Name] += cashFlow.Amount
+			categoryName, found := categoryNameMap[line.CategoryId]
+			if !found {
+				categoryName = category_mapper.INSTANCE.GetCategoryByObjectId(line.CategoryId.Hex()).Name
+				categoryNameMap[line.CategoryId] = categoryName
+			}
+			// Records whose category no longer exists are left out of the breakdown
+			if categoryName != "" {
+				totals.CategoryBreakdown[categoryName] = totals.CategoryBreakdown[categoryName].Add(convertedAmount)
 			}
 		}
 
-		currentDate = currentDate.AddDate(0, 0, 1)
+		if cashFlow.FlowType == model.FlowTypeIncome {
+			totals.TotalIncome = totals.TotalIncome.Add(convertedTotal)
+		} else {
+			totals.TotalExpense = totals.TotalExpense.Add(convertedTotal)
+		}
+		for _, tag := range cashFlow.Tags {
+			totals.TagBreakdown[tag] = totals.TagBreakdown[tag].Add(convertedTotal)
+		}
 	}
 
-	summary.Balance = summary.TotalIncome - summary.TotalExpense
-
+	finishSummary(summary)
 	return summary, nil
 }
+
+// buildSummary folds the per flow type, currency and category aggregation, and the per flow type, currency
+// and tag one, into a Summary. Records without a currency count towards the default currency.
+func buildSummary(summaryStatList []model.CashFlowSummaryStat, tagStatList []model.CashFlowTagSummaryStat) *Summary {
+	summary := &Summary{Totals: make([]CurrencySummary, 0)}
+
+	for _, summaryStat := range summaryStatList {
+		// Transfers only move money between accounts, they are neither income nor expense
+		if summaryStat.FlowType == model.FlowTypeTransfer {
+			continue
+		}
+		summary.TransactionCount += int(summaryStat.Count)
+
+		totals := currencyTotals(summary, exchange_rate_service.NormalizeCurrency(summaryStat.Currency))
+		if summaryStat.FlowType == model.FlowTypeIncome {
+			totals.TotalIncome = totals.TotalIncome.Add(summaryStat.TotalAmount)
+		} else {
+			totals.TotalExpense = totals.TotalExpense.Add(summaryStat.TotalAmount)
+		}
+
+		// Records whose category no longer exists are left out of the breakdown
+		if summaryStat.CategoryName != "" {
+			totals.CategoryBreakdown[summaryStat.CategoryName] =
+				totals.CategoryBreakdown[summaryStat.CategoryName].Add(summaryStat.TotalAmount)
+		}
+	}
+
+	for _, tagStat := range tagStatList {
+		if tagStat.FlowType == model.FlowTypeTransfer {
+			continue
+		}
+		totals := currencyTotals(summary, exchange_rate_service.NormalizeCurrency(tagStat.Currency))
+		totals.TagBreakdown[tagStat.Tag] = totals.TagBreakdown[tagStat.Tag].Add(tagStat.TotalAmount)
+	}
+
+	finishSummary(summary)
+	return summary
+}
+
+// currencyTotals returns the summary's entry of the currency, adding it when missing.
+// The pointer is only good until the next entry is added.
+func currencyTotals(summary *Summary, currency string) *CurrencySummary {
+	for index := range summary.Totals {
+		if summary.Totals[index].Currency == currency {
+			return &summary.Totals[index]
+		}
+	}
+	summary.Totals = append(summary.Totals, CurrencySummary{
+		Currency:          currency,
+		CategoryBreakdown: make(map[string]decimal.Decimal),
+		TagBreakdown:      make(map[string]decimal.Decimal),
+	})
+	return &summary.Totals[len(summary.Totals)-1]
+}
+
+// finishSummary works out the balance of every currency and orders the currencies by code
+func finishSummary(summary *Summary) {
+	for index := range summary.Totals {
+		summary.Totals[index].Balance = summary.Totals[index].TotalIncome.Sub(summary.Totals[index].TotalExpense)
+	}
+	sort.Slice(summary.Totals, func(i, j int) bool {
+		return summary.Totals[i].Currency < summary.Totals[j].Currency
+	})
+}
+
+// GetSummaryByMonth returns financial summary for a month given in YYYYMM format
+func GetSummaryByMonth(ownerPlainId, month, baseCurrency string) (*Summary, error) {
+	if len(month) != 6 {
+		return nil, errors.New("invalid month format, use YYYYMM")
+	}
+	return GetSummary(ownerPlainId, "monthly", month[0:4]+"-"+month[4:6], baseCurrency)
+}
+
+// GetSummaryByYear returns financial summary for a year given in YYYY format
+func GetSummaryByYear(ownerPlainId, year, baseCurrency string) (*Summary, error) {
+	return GetSummary(ownerPlainId, "yearly", year, baseCurrency)
+}
@@ -0,0 +1,87 @@
+package cash_flow_service
+
+import (
+	"testing"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+)
+
+func TestBuildSummary(t *testing.T) {
+	summary := buildSummary([]model.CashFlowSummaryStat{
+		{FlowType: model.FlowTypeIncome, Currency: "USD", CategoryName: "Salary", Count: 1, TotalAmount: decimal.NewFromInt(3000)},
+		{FlowType: model.FlowTypeOutcome, Currency: "USD", CategoryName: "Food", Count: 4, TotalAmount: decimal.NewFromFloat(120.5)},
+		{FlowType: model.FlowTypeOutcome, Currency: "USD", CategoryName: "Rent", Count: 1, TotalAmount: decimal.NewFromInt(1000)},
+		{FlowType: model.FlowTypeOutcome, Currency: "USD", CategoryName: "", Count: 2, TotalAmount: decimal.NewFromInt(30)},
+		{FlowType: model.FlowTypeOutcome, Currency: "JPY", CategoryName: "Food", Count: 2, TotalAmount: decimal.NewFromInt(4500)},
+		{FlowType: model.FlowTypeTransfer, Currency: "USD", CategoryName: "", Count: 2, TotalAmount: decimal.Zero},
+	}, []model.CashFlowTagSummaryStat{
+		{FlowType: model.FlowTypeOutcome, Currency: "USD", Tag: "trip-japan", Count: 3, TotalAmount: decimal.NewFromFloat(90.5)},
+		{FlowType: model.FlowTypeIncome, Currency: "USD", Tag: "trip-japan", Count: 1, TotalAmount: decimal.NewFromInt(40)},
+		{FlowType: model.FlowTypeOutcome, Currency: "JPY", Tag: "trip-japan", Count: 2, TotalAmount: decimal.NewFromInt(4500)},
+		{FlowType: model.FlowTypeTransfer, Currency: "USD", Tag: "trip-japan", Count: 1, TotalAmount: decimal.NewFromInt(-200)},
+	})
+
+	if summary.TransactionCount != 10 {
+		t.Errorf("TransactionCount = %d, want 10", summary.TransactionCount)
+	}
+	if summary.Currency != "" || len(summary.Totals) != 2 {
+		t.Fatalf("Totals = %+v, want JPY and USD apart", summary.Totals)
+	}
+
+	tests := []struct {
+		currency     string
+		totalIncome  string
+		totalExpense string
+		balance      string
+		food         string
+		tripJapan    string
+	}{
+		{"JPY", "0", "4500", "-4500", "4500", "4500"},
+		{"USD", "3000", "1150.5", "1849.5", "120.5", "130.5"},
+	}
+	for index, tt := range tests {
+		totals := summary.Totals[index]
+		if totals.Currency != tt.currency ||
+			!totals.TotalIncome.Equal(decimal.RequireFromString(tt.totalIncome)) ||
+			!totals.TotalExpense.Equal(decimal.RequireFromString(tt.totalExpense)) ||
+			!totals.Balance.Equal(decimal.RequireFromString(tt.balance)) {
+			t.Errorf("Totals[%d] = %+v, want %s %s/%s/%s", index, totals, tt.currency, tt.totalIncome, tt.totalExpense, tt.balance)
+		}
+		if !totals.CategoryBreakdown["Food"].Equal(decimal.RequireFromString(tt.food)) {
+			t.Errorf("%s CategoryBreakdown[Food] = %s, want %s", tt.currency, totals.CategoryBreakdown["Food"], tt.food)
+		}
+		if !totals.TagBreakdown["trip-japan"].Equal(decimal.RequireFromString(tt.tripJapan)) {
+			t.Errorf("%s TagBreakdown[trip-japan] = %s, want %s", tt.currency, totals.TagBreakdown["trip-japan"], tt.tripJapan)
+		}
+	}
+	if len(summary.Totals[1].CategoryBreakdown) != 3 {
+		t.Errorf("USD CategoryBreakdown has %d entries, want 3", len(summary.Totals[1].CategoryBreakdown))
+	}
+}
+
+func TestBuildSummaryBlankCurrency(t *testing.T) {
+	defaultCurrency := util.GetConfigByKey("currency.default")
+
+	summary := buildSummary([]model.CashFlowSummaryStat{
+		{FlowType: model.FlowTypeIncome, Currency: "", CategoryName: "Salary", Count: 1, TotalAmount: decimal.NewFromInt(100)},
+		{FlowType: model.FlowTypeIncome, Currency: defaultCurrency, CategoryName: "Salary", Count: 1, TotalAmount: decimal.NewFromInt(50)},
+	}, nil)
+
+	if len(summary.Totals) != 1 || summary.Totals[0].Currency != defaultCurrency ||
+		!summary.Totals[0].CategoryBreakdown["Salary"].Equal(decimal.NewFromInt(150)) {
+		t.Errorf("Totals = %+v, want 150 in %s", summary.Totals, defaultCurrency)
+	}
+}
+
+func TestBuildSummaryEmpty(t *testing.T) {
+	summary := buildSummary(nil, nil)
+
+	if summary.TransactionCount != 0 {
+		t.Errorf("expected empty summary, got %+v", summary)
+	}
+	if summary.Totals == nil || len(summary.Totals) != 0 {
+		t.Errorf("Totals = %v, want an empty list", summary.Totals)
+	}
+}
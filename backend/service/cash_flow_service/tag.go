@@ -0,0 +1,36 @@
+package cash_flow_service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/macar-x/cashlens/validation"
+)
+
+// maxTagsPerCashFlow keeps the tags of one cash flow within a single folded SQL column
+const maxTagsPerCashFlow = 20
+
+// NormalizeTags lowercases, dedupes and sorts the tags, blank entries are dropped.
+// No tags at all gives nil, so an untagged cash flow stays empty.
+func NormalizeTags(tags []string) ([]string, error) {
+	tagSet := make(map[string]bool, len(tags))
+	var normalizedTags []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tagSet[tag] {
+			continue
+		}
+		if err := validation.ValidateTag(tag); err != nil {
+			return nil, err
+		}
+		tagSet[tag] = true
+		normalizedTags = append(normalizedTags, tag)
+	}
+
+	if len(normalizedTags) > maxTagsPerCashFlow {
+		return nil, errors.New("too many tags (max 20 per cash flow)")
+	}
+	sort.Strings(normalizedTags)
+	return normalizedTags, nil
+}
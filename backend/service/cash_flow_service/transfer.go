@@ -0,0 +1,207 @@
+package cash_flow_service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SaveTransfer moves money between two accounts as a pair of linked TRANSFER records.
+// The leg leaving fromAccountName carries a negative amount, the leg entering
+// toAccountName a positive one; both are returned in that order.
+// Between accounts of different currencies the incoming amount is converted at the rate of belongsDate.
+func SaveTransfer(ownerPlainId, userPlainId, belongsDate, fromAccountName, toAccountName string, amount decimal.Decimal, description string) ([]model.CashFlowEntity, error) {
+	if err := validation.ValidateRequired("from_account", fromAccountName); err != nil {
+		return nil, err
+	}
+	if err := validation.ValidateRequired("to_account", toAccountName); err != nil {
+		return nil, err
+	}
+
+	if err := validation.ValidateAmount(amount); err != nil {
+		return nil, err
+	}
+
+	if belongsDate != "" {
+		if err := validation.ValidateDate(belongsDate); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validation.ValidateDescription(description); err != nil {
+		return nil, err
+	}
+
+	// 取小數點後兩位
+	amount = amount.Round(2)
+
+	fromAccountId, err := GetAccountIdByName(ownerPlainId, fromAccountName)
+	if err != nil {
+		return nil, err
+	}
+	toAccountId, err := GetAccountIdByName(ownerPlainId, toAccountName)
+	if err != nil {
+		return nil, err
+	}
+	if fromAccountId == toAccountId {
+		return nil, errors.New("cannot transfer to the same account")
+	}
+
+	// 選填參數: 日期（默認當天）
+	date := util.FormatDateFromStringWithoutDash(util.FormatDateToStringWithoutDash(time.Now()))
+	if belongsDate != "" {
+		date = util.FormatDateFromStringWithOptionalDash(belongsDate)
+	}
+
+	// Each leg is in its own account's currency, the incoming amount is converted when they differ
+	fromCurrency, err := ResolveCurrency(fromAccountId, "")
+	if err != nil {
+		return nil, err
+	}
+	toCurrency, err := ResolveCurrency(toAccountId, "")
+	if err != nil {
+		return nil, err
+	}
+	incomingAmount, err := exchange_rate_service.ConvertAmount(ownerPlainId, amount, fromCurrency, toCurrency, date)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ids are generated up front so that each leg can refer to the other
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	userId := util.ConvertOwner2ObjectId(userPlainId)
+	outgoingId := primitive.NewObjectID()
+	incomingId := primitive.NewObjectID()
+	_, err = cash_flow_mapper.INSTANCE.BulkInsertCashFlows([]model.CashFlowEntity{
+		{
+			Id:          outgoingId,
+			OwnerId:     ownerId,
+			AccountId:   fromAccountId,
+			LinkedId:    incomingId,
+			BelongsDate: date,
+			FlowType:    model.FlowTypeTransfer,
+			Amount:      amount.Neg(),
+			Currency:    fromCurrency,
+			Description: description,
+			CreatedBy:   userId,
+			ModifiedBy:  userId,
+		},
+		{
+			Id:          incomingId,
+			OwnerId:     ownerId,
+			AccountId:   toAccountId,
+			LinkedId:    outgoingId,
+			BelongsDate: date,
+			FlowType:    model.FlowTypeTransfer,
+			Amount:      incomingAmount,
+			Currency:    toCurrency,
+			Description: description,
+			CreatedBy:   userId,
+			ModifiedBy:  userId,
+		},
+	})
+	if err != nil {
+		return nil, errors.New("transfer create failed")
+	}
+	return []model.CashFlowEntity{
+		cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(outgoingId.Hex()),
+		cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(incomingId.Hex()),
+	}, nil
+}
+
+func IsTransferRequiredFiledSatisfied(fromAccountName, toAccountName string, amount decimal.Decimal) bool {
+	if fromAccountName == "" || toAccountName == "" {
+		return false
+	}
+	if amount.IsZero() {
+		return false
+	}
+
+	return true
+}
+
+// updateTransfer applies an update to one leg of a transfer and mirrors it onto the other leg,
+// the amount keeps the sign that tells which way the money moved and is converted
+// when the two legs are in different currencies.
+func updateTransfer(ownerPlainId string, entity model.CashFlowEntity, categoryName, accountName, currency string, amount decimal.Decimal) (model.CashFlowEntity, error) {
+	if categoryName != "" {
+		return model.CashFlowEntity{}, errors.New("transfer has no category")
+	}
+
+	linkedEntity := cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(entity.LinkedId.Hex())
+	if linkedEntity.IsEmpty() {
+		return model.CashFlowEntity{}, errors.New("linked transfer record not found")
+	}
+
+	if accountName != "" {
+		accountId, err := GetAccountIdByName(ownerPlainId, accountName)
+		if err != nil {
+			return model.CashFlowEntity{}, err
+		}
+		if accountId == primitive.NilObjectID || accountId == linkedEntity.AccountId {
+			return model.CashFlowEntity{}, errors.New("cannot transfer to the same account")
+		}
+		entity.AccountId = accountId
+	}
+
+	var err error
+	if entity.Currency, err = ResolveCurrency(entity.AccountId, currency); err != nil {
+		return model.CashFlowEntity{}, err
+	}
+	if linkedEntity.Currency, err = ResolveCurrency(linkedEntity.AccountId, ""); err != nil {
+		return model.CashFlowEntity{}, err
+	}
+
+	if !amount.IsZero() {
+		// Round to 2 decimal places
+		amount = amount.Round(2)
+		if entity.Amount.IsNegative() {
+			entity.Amount = amount.Neg()
+		} else {
+			entity.Amount = amount
+		}
+	}
+
+	// The date or either account may have changed, so the mirrored amount is worked out again
+	mirroredAmount, err := exchange_rate_service.ConvertAmount(
+		ownerPlainId, entity.Amount.Abs(), entity.Currency, linkedEntity.Currency, entity.BelongsDate)
+	if err != nil {
+		return model.CashFlowEntity{}, err
+	}
+	if entity.Amount.IsNegative() {
+		linkedEntity.Amount = mirroredAmount
+	} else {
+		linkedEntity.Amount = mirroredAmount.Neg()
+	}
+
+	linkedEntity.BelongsDate = entity.BelongsDate
+	linkedEntity.Description = entity.Description
+	linkedEntity.ModifiedBy = entity.ModifiedBy
+	if cash_flow_mapper.INSTANCE.UpdateCashFlowByEntity(linkedEntity.Id.Hex(), linkedEntity).IsEmpty() {
+		return model.CashFlowEntity{}, errors.New("failed to update linked transfer record")
+	}
+
+	updatedEntity := cash_flow_mapper.INSTANCE.UpdateCashFlowByEntity(entity.Id.Hex(), entity)
+	if updatedEntity.IsEmpty() {
+		return model.CashFlowEntity{}, errors.New("failed to update cash_flow")
+	}
+	return updatedEntity, nil
+}
+
+// deleteLinkedTransfer removes the other leg of a deleted transfer record, if it is still there
+func deleteLinkedTransfer(entity model.CashFlowEntity) model.CashFlowEntity {
+	if entity.FlowType != model.FlowTypeTransfer || entity.LinkedId == primitive.NilObjectID {
+		return model.CashFlowEntity{}
+	}
+	if cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(entity.LinkedId.Hex()).IsEmpty() {
+		return model.CashFlowEntity{}
+	}
+	return cash_flow_mapper.INSTANCE.DeleteCashFlowByObjectId(entity.LinkedId.Hex())
+}
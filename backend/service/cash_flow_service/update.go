@@ -7,13 +7,15 @@ import (
 	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
 	"github.com/macar-x/cashlens/mapper/category_mapper"
 	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/payee_service"
 	"github.com/macar-x/cashlens/util"
 	"github.com/macar-x/cashlens/validation"
 	"github.com/shopspring/decimal"
 )
 
-// UpdateById updates a cash flow record by ID
-func UpdateById(plainId, belongsDate, categoryName string, amount float64, description string) (model.CashFlowEntity, error) {
+// UpdateById updates a cash flow record by ID, nil tags keep the current ones and empty tags clear them.
+// A new description of a record without payee is run through the payee rules again.
+func UpdateById(ownerPlainId, userPlainId, plainId, belongsDate, categoryName, accountName, currency string, amount decimal.Decimal, description, payeeName string, tags []string) (model.CashFlowEntity, error) {
 	// Validate ID
 	if err := validation.ValidateID(plainId); err != nil {
 		return model.CashFlowEntity{}, err
@@ -32,7 +34,7 @@ func UpdateById(plainId, belongsDate, categoryName string, amount float64, descr
 		}
 	}
 
-	if amount != 0 {
+	if !amount.IsZero() {
 		if err := validation.ValidateAmount(amount); err != nil {
 			return model.CashFlowEntity{}, err
 		}
@@ -46,35 +48,88 @@ func UpdateById(plainId, belongsDate, categoryName string, amount float64, descr
 
 	// Query existing record
 	existingEntity := cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(plainId)
-	if existingEntity.IsEmpty() {
+	if existingEntity.IsEmpty() || !existingEntity.IsOwnedBy(ownerPlainId) {
 		return model.CashFlowEntity{}, errors.New("cash_flow not found")
 	}
+	existingEntity.ModifiedBy = util.ConvertOwner2ObjectId(userPlainId)
 
 	// Update fields that are provided
 	if belongsDate != "" {
-		date := util.FormatDateFromStringWithoutDash(belongsDate)
+		date := util.FormatDateFromStringWithOptionalDash(belongsDate)
 		if date.IsZero() {
 			return model.CashFlowEntity{}, errors.New("invalid date format")
 		}
 		existingEntity.BelongsDate = date
 	}
 
+	descriptionChanged := description != "" && description != existingEntity.Description
+	if description != "" {
+		existingEntity.Description = description
+	}
+
+	if payeeName != "" {
+		// A transfer moves money between own accounts, nobody is paid
+		if existingEntity.FlowType == model.FlowTypeTransfer {
+			return model.CashFlowEntity{}, errors.New("a transfer can not have a payee")
+		}
+		payeeId, err := ResolvePayeeId(ownerPlainId, payeeName, "")
+		if err != nil {
+			return model.CashFlowEntity{}, err
+		}
+		existingEntity.PayeeId = payeeId
+	} else if descriptionChanged && existingEntity.PayeeId.IsZero() && existingEntity.FlowType != model.FlowTypeTransfer {
+		existingEntity.PayeeId = payee_service.MatchPayeeId(ownerPlainId, existingEntity.Description)
+	}
+
+	// Tags belong to this record only, the other leg of a transfer keeps its own
+	if tags != nil {
+		normalizedTags, err := NormalizeTags(tags)
+		if err != nil {
+			return model.CashFlowEntity{}, err
+		}
+		existingEntity.Tags = normalizedTags
+	}
+
+	// Both legs of a transfer are kept in step
+	if existingEntity.FlowType == model.FlowTypeTransfer {
+		return updateTransfer(ownerPlainId, existingEntity, categoryName, accountName, currency, amount)
+	}
+
+	// The category and amount of a split cash flow follow its lines
+	if len(existingEntity.Splits) > 0 {
+		if categoryName != "" || (!amount.IsZero() && !amount.Round(2).Equal(existingEntity.Amount)) {
+			return model.CashFlowEntity{}, errors.New("cash_flow is split, change its lines instead of its category or amount")
+		}
+	}
+
 	if categoryName != "" {
-		categoryEntity := category_mapper.INSTANCE.GetCategoryByName(categoryName)
+		categoryEntity := category_mapper.INSTANCE.GetCategoryByName(ownerPlainId, categoryName)
 		if categoryEntity.IsEmpty() {
 			return model.CashFlowEntity{}, errors.New("category does not exist")
 		}
 		existingEntity.CategoryId = categoryEntity.Id
 	}
 
-	if amount != 0 {
-		// Round to 2 decimal places
-		amount, _ = decimal.NewFromFloat(amount).Round(2).Float64()
-		existingEntity.Amount = amount
+	if accountName != "" {
+		accountId, err := GetAccountIdByName(ownerPlainId, accountName)
+		if err != nil {
+			return model.CashFlowEntity{}, err
+		}
+		existingEntity.AccountId = accountId
 	}
 
-	if description != "" {
-		existingEntity.Description = description
+	// The currency follows the account, so it is settled again whenever either of them changes
+	if accountName != "" || currency != "" {
+		resolvedCurrency, err := ResolveCurrency(existingEntity.AccountId, currency)
+		if err != nil {
+			return model.CashFlowEntity{}, err
+		}
+		existingEntity.Currency = resolvedCurrency
+	}
+
+	if !amount.IsZero() {
+		// Round to 2 decimal places
+		existingEntity.Amount = amount.Round(2)
 	}
 
 	// Update modify time
@@ -0,0 +1,118 @@
+package category_rule_service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateService creates a categorization rule for one of the owner's categories,
+// a rule without conditions matches every cash_flow
+func CreateService(ownerPlainId string, ruleDTO model.CategoryRuleDTO) (model.CategoryRuleEntity, error) {
+	if err := validation.ValidateCategoryRuleName(ruleDTO.Name); err != nil {
+		return model.CategoryRuleEntity{}, err
+	}
+	if !category_rule_mapper.INSTANCE.GetCategoryRuleByName(ownerPlainId, ruleDTO.Name).IsEmpty() {
+		return model.CategoryRuleEntity{}, errors.New("category rule already exists")
+	}
+
+	newEntity := model.CategoryRuleEntity{OwnerId: util.ConvertOwner2ObjectId(ownerPlainId), Name: ruleDTO.Name}
+	if ruleDTO.Priority != nil {
+		newEntity.Priority = *ruleDTO.Priority
+	}
+
+	categoryId, err := getCategoryIdByName(ownerPlainId, ruleDTO.CategoryName)
+	if err != nil {
+		return model.CategoryRuleEntity{}, err
+	}
+	newEntity.CategoryId = categoryId
+
+	if ruleDTO.Conditions != nil {
+		if err := applyConditions(ownerPlainId, &newEntity, *ruleDTO.Conditions); err != nil {
+			return model.CategoryRuleEntity{}, err
+		}
+	}
+
+	newEntity.Tags, err = cash_flow_service.NormalizeTags(ruleDTO.Tags)
+	if err != nil {
+		return model.CategoryRuleEntity{}, err
+	}
+
+	newPlainId := category_rule_mapper.INSTANCE.InsertCategoryRuleByEntity(newEntity)
+	if newPlainId == "" {
+		return model.CategoryRuleEntity{}, errors.New("category rule create failed")
+	}
+	return category_rule_mapper.INSTANCE.GetCategoryRuleByObjectId(newPlainId), nil
+}
+
+func getCategoryIdByName(ownerPlainId, categoryName string) (primitive.ObjectID, error) {
+	if err := validation.ValidateCategoryName(categoryName); err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	categoryEntity := category_mapper.INSTANCE.GetCategoryByName(ownerPlainId, categoryName)
+	if categoryEntity.IsEmpty() {
+		return primitive.NilObjectID, errors.New("category does not exist")
+	}
+	return categoryEntity.Id, nil
+}
+
+// applyConditions replaces every condition of the rule, blank ones match anything.
+// The payee and account of a condition are looked up in the owner's ledger.
+func applyConditions(ownerPlainId string, entity *model.CategoryRuleEntity, conditionDTO model.CategoryRuleConditionDTO) error {
+	flowType := strings.ToUpper(strings.TrimSpace(conditionDTO.FlowType))
+	if flowType != "" && flowType != model.FlowTypeIncome && flowType != model.FlowTypeOutcome {
+		return validation.NewValidationError("flow_type", "must be INCOME or OUTCOME")
+	}
+
+	// The description is checked like a payee rule, when a pattern is given
+	matchType := strings.ToUpper(strings.TrimSpace(conditionDTO.MatchType))
+	if conditionDTO.Pattern != "" {
+		if matchType == "" {
+			matchType = model.PayeeRuleContains
+		}
+		if err := validation.ValidatePayeeRule(matchType, conditionDTO.Pattern); err != nil {
+			return err
+		}
+	} else {
+		matchType = ""
+	}
+
+	if err := validation.ValidateAmountRange(conditionDTO.MinAmount, conditionDTO.MaxAmount); err != nil {
+		return err
+	}
+
+	payeeId := primitive.NilObjectID
+	if conditionDTO.PayeeName != "" {
+		if err := validation.ValidatePayeeName(conditionDTO.PayeeName); err != nil {
+			return err
+		}
+		payeeEntity := payee_mapper.INSTANCE.GetPayeeByName(ownerPlainId, conditionDTO.PayeeName)
+		if payeeEntity.IsEmpty() {
+			return errors.New("payee does not exist")
+		}
+		payeeId = payeeEntity.Id
+	}
+
+	accountId, err := cash_flow_service.GetAccountIdByName(ownerPlainId, conditionDTO.AccountName)
+	if err != nil {
+		return err
+	}
+
+	entity.FlowType = flowType
+	entity.MatchType = matchType
+	entity.Pattern = conditionDTO.Pattern
+	entity.MinAmount = conditionDTO.MinAmount.Round(2)
+	entity.MaxAmount = conditionDTO.MaxAmount.Round(2)
+	entity.PayeeId = payeeId
+	entity.AccountId = accountId
+	return nil
+}
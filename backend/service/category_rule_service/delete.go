@@ -0,0 +1,27 @@
+package category_rule_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// DeleteService deletes one of the owner's categorization rules, the cash_flows it categorized are kept
+func DeleteService(ownerPlainId, plainId string) (model.CategoryRuleEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.CategoryRuleEntity{}, err
+	}
+
+	existingRule := category_rule_mapper.INSTANCE.GetCategoryRuleByObjectId(plainId)
+	if existingRule.IsEmpty() || !existingRule.IsOwnedBy(ownerPlainId) {
+		return model.CategoryRuleEntity{}, errors.New("category rule not found")
+	}
+
+	deletedRule := category_rule_mapper.INSTANCE.DeleteCategoryRuleByObjectId(plainId)
+	if deletedRule.IsEmpty() {
+		return model.CategoryRuleEntity{}, errors.New("category rule delete failed")
+	}
+	return deletedRule, nil
+}
@@ -0,0 +1,17 @@
+package category_rule_service
+
+import (
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/model"
+)
+
+// ListAllService lists the owner's categorization rules in the order they are tried, with pagination
+func ListAllService(ownerPlainId string, limit, offset int) ([]model.CategoryRuleEntity, int64, error) {
+	totalCount := category_rule_mapper.INSTANCE.CountCategoryRulesByOwnerId(ownerPlainId)
+
+	ruleEntityList := category_rule_mapper.INSTANCE.GetCategoryRulesByOwnerId(ownerPlainId, limit, offset)
+	if ruleEntityList == nil {
+		ruleEntityList = []model.CategoryRuleEntity{}
+	}
+	return ruleEntityList, totalCount, nil
+}
@@ -0,0 +1,57 @@
+package category_rule_service
+
+import (
+	"strings"
+
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/validation"
+	"github.com/shopspring/decimal"
+)
+
+// RuleMatch tells which rule a cash_flow saved without category would get
+type RuleMatch struct {
+	Rule         model.CategoryRuleEntity `json:"rule"`          // empty when no rule matches
+	CategoryName string                   `json:"category_name"` // blank when no rule matches
+	PayeeName    string                   `json:"payee_name"`    // payee the rules were tried with, blank when none
+}
+
+// MatchService tries the rules on a cash_flow that is not saved, the way SaveOutcome and SaveIncome would.
+// Only the owner's rules on the owner's categories are tried, a blank flow type means OUTCOME and a blank payee
+// is recognised from the description.
+func MatchService(ownerPlainId, flowType, description string, amount decimal.Decimal, payeeName, accountName string) (RuleMatch, error) {
+	flowType = strings.ToUpper(strings.TrimSpace(flowType))
+	if flowType == "" {
+		flowType = model.FlowTypeOutcome
+	}
+	if flowType != model.FlowTypeIncome && flowType != model.FlowTypeOutcome {
+		return RuleMatch{}, validation.NewValidationError("flow_type", "must be INCOME or OUTCOME")
+	}
+
+	if err := validation.ValidateDescription(description); err != nil {
+		return RuleMatch{}, err
+	}
+
+	accountId, err := cash_flow_service.GetAccountIdByName(ownerPlainId, accountName)
+	if err != nil {
+		return RuleMatch{}, err
+	}
+
+	payeeId, err := cash_flow_service.ResolvePayeeId(ownerPlainId, payeeName, description)
+	if err != nil {
+		return RuleMatch{}, err
+	}
+
+	var ruleMatch RuleMatch
+	if !payeeId.IsZero() {
+		ruleMatch.PayeeName = payee_mapper.INSTANCE.GetPayeeByObjectId(payeeId.Hex()).Name
+	}
+
+	ruleMatch.Rule = cash_flow_service.NewCategoryRuleMatcher(ownerPlainId).Match(flowType, description, amount.Round(2), payeeId, accountId)
+	if !ruleMatch.Rule.IsEmpty() {
+		ruleMatch.CategoryName = category_mapper.INSTANCE.GetCategoryByObjectId(ruleMatch.Rule.CategoryId.Hex()).Name
+	}
+	return ruleMatch, nil
+}
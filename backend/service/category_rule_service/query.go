@@ -0,0 +1,31 @@
+package category_rule_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// QueryService finds one of the owner's categorization rules by either its id or its name
+func QueryService(ownerPlainId, plainId, ruleName string) (model.CategoryRuleEntity, error) {
+	if (plainId == "") == (ruleName == "") {
+		return model.CategoryRuleEntity{}, errors.New("should have one and only one query type")
+	}
+
+	var ruleEntity model.CategoryRuleEntity
+	if plainId != "" {
+		if err := validation.ValidateID(plainId); err != nil {
+			return model.CategoryRuleEntity{}, err
+		}
+		ruleEntity = category_rule_mapper.INSTANCE.GetCategoryRuleByObjectId(plainId)
+	} else {
+		ruleEntity = category_rule_mapper.INSTANCE.GetCategoryRuleByName(ownerPlainId, ruleName)
+	}
+
+	if ruleEntity.IsEmpty() || !ruleEntity.IsOwnedBy(ownerPlainId) {
+		return model.CategoryRuleEntity{}, errors.New("category rule not found")
+	}
+	return ruleEntity, nil
+}
@@ -0,0 +1,161 @@
+package category_rule_service
+
+import (
+	"testing"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/shopspring/decimal"
+)
+
+// resetMappers gives each test empty in-memory storage with the named categories in place
+func resetMappers(t *testing.T, categoryNames ...string) {
+	t.Helper()
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	category_rule_mapper.INSTANCE = category_rule_mapper.NewCategoryRuleMemoryMapper()
+	account_mapper.INSTANCE = account_mapper.NewAccountMemoryMapper()
+	payee_mapper.INSTANCE = payee_mapper.NewPayeeMemoryMapper()
+	for _, categoryName := range categoryNames {
+		if category_mapper.INSTANCE.InsertCategoryByEntity(model.CategoryEntity{Name: categoryName}) == "" {
+			t.Fatalf("insert category %q failed", categoryName)
+		}
+	}
+}
+
+func TestCategoryRuleLifecycle(t *testing.T) {
+	resetMappers(t, "Food", "Coffee")
+	payee_mapper.INSTANCE.InsertPayeeByEntity(model.PayeeEntity{Name: "Starbucks"})
+	priority := 10
+
+	created, err := CreateService("", model.CategoryRuleDTO{
+		Name:         "Starbucks",
+		CategoryName: "Coffee",
+		Priority:     &priority,
+		Conditions: &model.CategoryRuleConditionDTO{
+			FlowType:  "outcome",
+			Pattern:   "starbucks",
+			MaxAmount: decimal.NewFromFloat(20.005),
+			PayeeName: "Starbucks",
+		},
+		Tags: []string{"Caffeine"},
+	})
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+	if created.FlowType != model.FlowTypeOutcome || created.MatchType != model.PayeeRuleContains ||
+		!created.MaxAmount.Equal(decimal.NewFromFloat(20.01)) || created.PayeeId.IsZero() ||
+		len(created.Tags) != 1 || created.Tags[0] != "caffeine" {
+		t.Errorf("CreateService() = %+v", created)
+	}
+
+	tests := []struct {
+		name    string
+		ruleDTO model.CategoryRuleDTO
+	}{
+		{"duplicate name", model.CategoryRuleDTO{Name: "Starbucks", CategoryName: "Food"}},
+		{"missing category", model.CategoryRuleDTO{Name: "Lunch"}},
+		{"unknown category", model.CategoryRuleDTO{Name: "Lunch", CategoryName: "Unknown"}},
+		{"transfer flow type", model.CategoryRuleDTO{Name: "Lunch", CategoryName: "Food",
+			Conditions: &model.CategoryRuleConditionDTO{FlowType: model.FlowTypeTransfer}}},
+		{"invalid regex", model.CategoryRuleDTO{Name: "Lunch", CategoryName: "Food",
+			Conditions: &model.CategoryRuleConditionDTO{MatchType: "regex", Pattern: "lunch("}}},
+		{"max below min", model.CategoryRuleDTO{Name: "Lunch", CategoryName: "Food",
+			Conditions: &model.CategoryRuleConditionDTO{MinAmount: decimal.NewFromInt(20), MaxAmount: decimal.NewFromInt(5)}}},
+		{"unknown payee", model.CategoryRuleDTO{Name: "Lunch", CategoryName: "Food",
+			Conditions: &model.CategoryRuleConditionDTO{PayeeName: "Unknown"}}},
+		{"unknown account", model.CategoryRuleDTO{Name: "Lunch", CategoryName: "Food",
+			Conditions: &model.CategoryRuleConditionDTO{AccountName: "Unknown"}}},
+		{"invalid tag", model.CategoryRuleDTO{Name: "Lunch", CategoryName: "Food", Tags: []string{"a,b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CreateService("", tt.ruleDTO); err == nil {
+				t.Errorf("CreateService() expected an error")
+			}
+		})
+	}
+
+	// Blank fields and nil conditions keep the current values
+	renamed, err := UpdateService("", created.Id.Hex(), model.CategoryRuleDTO{Name: "Coffee Shops"})
+	if err != nil {
+		t.Fatalf("UpdateService() error = %v", err)
+	}
+	if renamed.Name != "Coffee Shops" || renamed.Priority != 10 || renamed.Pattern != "starbucks" || len(renamed.Tags) != 1 {
+		t.Errorf("UpdateService() = %+v, want renamed with everything else kept", renamed)
+	}
+
+	// Given conditions replace all of them, empty tags clear them
+	replaced, err := UpdateService("", created.Id.Hex(), model.CategoryRuleDTO{
+		Conditions: &model.CategoryRuleConditionDTO{MinAmount: decimal.NewFromInt(100)},
+		Tags:       []string{},
+	})
+	if err != nil {
+		t.Fatalf("UpdateService() error = %v", err)
+	}
+	if replaced.Pattern != "" || replaced.MatchType != "" || replaced.FlowType != "" || !replaced.PayeeId.IsZero() ||
+		!replaced.MaxAmount.IsZero() || !replaced.MinAmount.Equal(decimal.NewFromInt(100)) || replaced.Tags != nil {
+		t.Errorf("UpdateService() = %+v, want only the min amount left", replaced)
+	}
+
+	if _, err := QueryService("", "", "Coffee Shops"); err != nil {
+		t.Errorf("QueryService() by name error = %v", err)
+	}
+	if _, err := DeleteService("", created.Id.Hex()); err != nil {
+		t.Errorf("DeleteService() error = %v", err)
+	}
+	if _, err := QueryService("", created.Id.Hex(), ""); err == nil {
+		t.Errorf("QueryService() after delete expected an error")
+	}
+}
+
+func TestMatchService(t *testing.T) {
+	resetMappers(t, "Food", "Coffee", "Salary")
+	payee_mapper.INSTANCE.InsertPayeeByEntity(model.PayeeEntity{Name: "Starbucks",
+		Rules: []model.PayeeRule{{MatchType: model.PayeeRuleContains, Pattern: "starbucks"}}})
+	high, low := 10, -1
+	for _, ruleDTO := range []model.CategoryRuleDTO{
+		{Name: "Starbucks", CategoryName: "Coffee", Priority: &high,
+			Conditions: &model.CategoryRuleConditionDTO{PayeeName: "Starbucks"}},
+		{Name: "Payroll", CategoryName: "Salary",
+			Conditions: &model.CategoryRuleConditionDTO{FlowType: "INCOME", Pattern: "payroll"}},
+		{Name: "Everything Else", CategoryName: "Food", Priority: &low},
+	} {
+		if _, err := CreateService("", ruleDTO); err != nil {
+			t.Fatalf("CreateService(%s) error = %v", ruleDTO.Name, err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		flowType     string
+		description  string
+		payeeName    string
+		wantRule     string
+		wantCategory string
+		wantPayee    string
+	}{
+		{"Payee from description", "", "STARBUCKS #1234", "", "Starbucks", "Coffee", "Starbucks"},
+		{"Payee given", "", "card payment", "Starbucks", "Starbucks", "Coffee", "Starbucks"},
+		{"Income only rule", "income", "ACME payroll", "", "Payroll", "Salary", ""},
+		{"Outcome skips income rule", "outcome", "ACME payroll", "", "Everything Else", "Food", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ruleMatch, err := MatchService("", tt.flowType, tt.description, decimal.NewFromInt(5), tt.payeeName, "")
+			if err != nil {
+				t.Fatalf("MatchService() error = %v", err)
+			}
+			if ruleMatch.Rule.Name != tt.wantRule || ruleMatch.CategoryName != tt.wantCategory || ruleMatch.PayeeName != tt.wantPayee {
+				t.Errorf("MatchService() = %s/%s/%s, want %s/%s/%s", ruleMatch.Rule.Name, ruleMatch.CategoryName,
+					ruleMatch.PayeeName, tt.wantRule, tt.wantCategory, tt.wantPayee)
+			}
+		})
+	}
+
+	if _, err := MatchService("", "transfer", "ATM", decimal.NewFromInt(5), "", ""); err == nil {
+		t.Errorf("MatchService() with a transfer expected an error")
+	}
+}
@@ -0,0 +1,67 @@
+package category_rule_service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// UpdateService updates one of the owner's categorization rules by ID, blank fields are kept and a new category is the owner's too.
+// Given conditions replace all the current ones, cash_flows already saved keep their category.
+func UpdateService(ownerPlainId, plainId string, ruleDTO model.CategoryRuleDTO) (model.CategoryRuleEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.CategoryRuleEntity{}, err
+	}
+
+	existingRule := category_rule_mapper.INSTANCE.GetCategoryRuleByObjectId(plainId)
+	if existingRule.IsEmpty() || !existingRule.IsOwnedBy(ownerPlainId) {
+		return model.CategoryRuleEntity{}, errors.New("category rule not found")
+	}
+
+	if ruleDTO.Name != "" && ruleDTO.Name != existingRule.Name {
+		if err := validation.ValidateCategoryRuleName(ruleDTO.Name); err != nil {
+			return model.CategoryRuleEntity{}, err
+		}
+		if !category_rule_mapper.INSTANCE.GetCategoryRuleByName(ownerPlainId, ruleDTO.Name).IsEmpty() {
+			return model.CategoryRuleEntity{}, errors.New("category rule already exists")
+		}
+		existingRule.Name = ruleDTO.Name
+	}
+
+	if ruleDTO.Priority != nil {
+		existingRule.Priority = *ruleDTO.Priority
+	}
+
+	if ruleDTO.CategoryName != "" {
+		categoryId, err := getCategoryIdByName(ownerPlainId, ruleDTO.CategoryName)
+		if err != nil {
+			return model.CategoryRuleEntity{}, err
+		}
+		existingRule.CategoryId = categoryId
+	}
+
+	if ruleDTO.Conditions != nil {
+		if err := applyConditions(ownerPlainId, &existingRule, *ruleDTO.Conditions); err != nil {
+			return model.CategoryRuleEntity{}, err
+		}
+	}
+
+	if ruleDTO.Tags != nil {
+		tags, err := cash_flow_service.NormalizeTags(ruleDTO.Tags)
+		if err != nil {
+			return model.CategoryRuleEntity{}, err
+		}
+		existingRule.Tags = tags
+	}
+	existingRule.ModifyTime = time.Now()
+
+	updatedEntity := category_rule_mapper.INSTANCE.UpdateCategoryRuleByEntity(plainId, existingRule)
+	if updatedEntity.IsEmpty() {
+		return model.CategoryRuleEntity{}, errors.New("failed to update category rule")
+	}
+	return updatedEntity, nil
+}
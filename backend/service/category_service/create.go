@@ -2,7 +2,6 @@ package category_service
 
 import (
 	"errors"
-	"fmt"
 
 	"github.com/macar-x/cashlens/mapper/category_mapper"
 	"github.com/macar-x/cashlens/model"
@@ -11,20 +10,26 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func CreateService(parentPlainId, categoryName string) error {
+func CreateService(ownerPlainId, parentPlainId, categoryName string) (string, error) {
 	// Validate category name
 	if err := validation.ValidateCategoryName(categoryName); err != nil {
-		return err
+		return "", err
 	}
 
 	// Validate parent ID if provided
 	if parentPlainId != "" {
 		if err := validation.ValidateID(parentPlainId); err != nil {
-			return err
+			return "", err
+		}
+		// Another user's category can not be the parent
+		parentCategory := category_mapper.INSTANCE.GetCategoryByObjectId(parentPlainId)
+		if !parentCategory.IsEmpty() && !parentCategory.IsOwnedBy(ownerPlainId) {
+			return "", errors.New("parent category does not exist")
 		}
 	}
 
 	categoryEntity := model.CategoryEntity{
+		OwnerId:  util.ConvertOwner2ObjectId(ownerPlainId),
 		ParentId: primitive.NilObjectID,
 		Name:     categoryName,
 	}
@@ -34,12 +39,9 @@ func CreateService(parentPlainId, categoryName string) error {
 
 	newCategoryPlainId := category_mapper.INSTANCE.InsertCategoryByEntity(categoryEntity)
 	if newCategoryPlainId == "" {
-		return errors.New("category create failed")
+		return "", errors.New("category create failed")
 	}
-
-	newCategoryEntity := category_mapper.INSTANCE.GetCategoryByObjectId(newCategoryPlainId)
-	fmt.Println("category ", 0, ": ", newCategoryEntity.ToString())
-	return nil
+	return newCategoryPlainId, nil
 }
 
 func isCreateRequiredFiledSatisfied(categoryName string) bool {
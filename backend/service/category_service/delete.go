@@ -4,22 +4,24 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/macar-x/cashlens/mapper/budget_mapper"
 	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
 	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
 	"github.com/macar-x/cashlens/validation"
 )
 
-func DeleteService(plainId, categoryName string) error {
+func DeleteService(ownerPlainId, plainId, categoryName string) error {
 	if isDeleteFieldsConflicted(plainId, categoryName) {
 		return errors.New("should have one and only one delete type")
 	}
 
 	if plainId != "" {
-		return deleteById(plainId)
+		return deleteById(ownerPlainId, plainId)
 	}
 
 	if categoryName != "" {
-		return deleteByName(categoryName)
+		return deleteByName(ownerPlainId, categoryName)
 	}
 
 	return errors.New("not supported delete type")
@@ -46,14 +48,14 @@ func isDeleteFieldsConflicted(plainId, categoryName string) bool {
 	return !semiOptionalFieldFilledFlag
 }
 
-func deleteById(plainId string) error {
+func deleteById(ownerPlainId, plainId string) error {
 	// Validate ID
 	if err := validation.ValidateID(plainId); err != nil {
 		return err
 	}
 
 	existCategoryEntity := category_mapper.INSTANCE.GetCategoryByObjectId(plainId)
-	if existCategoryEntity.IsEmpty() {
+	if existCategoryEntity.IsEmpty() || !existCategoryEntity.IsOwnedBy(ownerPlainId) {
 		fmt.Println("category not found")
 		return nil
 	}
@@ -61,6 +63,12 @@ func deleteById(plainId string) error {
 	if cash_flow_mapper.INSTANCE.CountCashFLowsByCategoryId(existCategoryEntity.Id.Hex()) != 0 {
 		return errors.New("can not delete a category which has cash_flows refer to")
 	}
+	if budget_mapper.INSTANCE.CountBudgetsByCategoryId(existCategoryEntity.Id.Hex()) != 0 {
+		return errors.New("can not delete a category which has budgets refer to")
+	}
+	if category_rule_mapper.INSTANCE.CountCategoryRulesByCategoryId(existCategoryEntity.Id.Hex()) != 0 {
+		return errors.New("can not delete a category which has category rules refer to")
+	}
 
 	existCategoryEntity = category_mapper.INSTANCE.DeleteCategoryByObjectId(plainId)
 	if existCategoryEntity.IsEmpty() {
@@ -70,13 +78,13 @@ func deleteById(plainId string) error {
 	return nil
 }
 
-func deleteByName(categoryName string) error {
+func deleteByName(ownerPlainId, categoryName string) error {
 	// Validate category name
 	if err := validation.ValidateCategoryName(categoryName); err != nil {
 		return err
 	}
 
-	existCategoryEntity := category_mapper.INSTANCE.GetCategoryByName(categoryName)
+	existCategoryEntity := category_mapper.INSTANCE.GetCategoryByName(ownerPlainId, categoryName)
 	if existCategoryEntity.IsEmpty() {
 		fmt.Println("category not found")
 		return nil
@@ -85,6 +93,12 @@ func deleteByName(categoryName string) error {
 	if cash_flow_mapper.INSTANCE.CountCashFLowsByCategoryId(existCategoryEntity.Id.Hex()) != 0 {
 		return errors.New("can not delete a category which has cash_flows refer to")
 	}
+	if budget_mapper.INSTANCE.CountBudgetsByCategoryId(existCategoryEntity.Id.Hex()) != 0 {
+		return errors.New("can not delete a category which has budgets refer to")
+	}
+	if category_rule_mapper.INSTANCE.CountCategoryRulesByCategoryId(existCategoryEntity.Id.Hex()) != 0 {
+		return errors.New("can not delete a category which has category rules refer to")
+	}
 
 	existCategoryEntity = category_mapper.INSTANCE.DeleteCategoryByObjectId(existCategoryEntity.Id.Hex())
 	if existCategoryEntity.IsEmpty() {
@@ -5,13 +5,13 @@ import (
 	"github.com/macar-x/cashlens/model"
 )
 
-// ListAllService lists all categories with pagination
-func ListAllService(limit, offset int) ([]model.CategoryEntity, int64, error) {
+// ListAllService lists the owner's categories with pagination
+func ListAllService(ownerPlainId string, limit, offset int) ([]model.CategoryEntity, int64, error) {
 	// Get total count
-	totalCount := category_mapper.INSTANCE.CountAllCategories()
+	totalCount := category_mapper.INSTANCE.CountCategoriesByOwnerId(ownerPlainId)
 
 	// Get paginated results
-	categories := category_mapper.INSTANCE.GetAllCategories(limit, offset)
+	categories := category_mapper.INSTANCE.GetCategoriesByOwnerId(ownerPlainId, limit, offset)
 
 	return categories, totalCount, nil
 }
@@ -2,32 +2,29 @@ package category_service
 
 import (
 	"errors"
-	"fmt"
 
 	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/model"
 )
 
-func QueryService(plainId, parentPlainId, categoryName string) error {
+func QueryService(ownerPlainId, plainId, parentPlainId, categoryName string) ([]model.CategoryEntity, error) {
 	if isQueryFieldsConflicted(plainId, parentPlainId, categoryName) {
-		return errors.New("should have one and only one query type")
+		return nil, errors.New("should have one and only one query type")
 	}
 
 	if plainId != "" {
-		queryById(plainId)
-		return nil
+		return queryById(ownerPlainId, plainId), nil
 	}
 
 	if parentPlainId != "" {
-		queryByParentId(parentPlainId)
-		return nil
+		return queryByParentId(ownerPlainId, parentPlainId), nil
 	}
 
 	if categoryName != "" {
-		queryByName(categoryName)
-		return nil
+		return queryByName(ownerPlainId, categoryName), nil
 	}
 
-	return errors.New("not supported query type")
+	return nil, errors.New("not supported query type")
 }
 
 func isQueryFieldsConflicted(plainId, parentPlainId, name string) bool {
@@ -59,32 +56,28 @@ func isQueryFieldsConflicted(plainId, parentPlainId, name string) bool {
 	return !semiOptionalFieldFilledFlag
 }
 
-func queryById(plainId string) {
+func queryById(ownerPlainId, plainId string) []model.CategoryEntity {
 	categoryEntity := category_mapper.INSTANCE.GetCategoryByObjectId(plainId)
-	if categoryEntity.IsEmpty() {
-		fmt.Println("category not found")
-		return
+	if categoryEntity.IsEmpty() || !categoryEntity.IsOwnedBy(ownerPlainId) {
+		return []model.CategoryEntity{}
 	}
-	fmt.Println("category ", 0, ": ", categoryEntity.ToString())
+	return []model.CategoryEntity{categoryEntity}
 }
 
-func queryByParentId(plainParentId string) {
-	matchedCategoryList := category_mapper.INSTANCE.GetCategoryByParentId(plainParentId)
-	if len(matchedCategoryList) == 0 {
-		fmt.Println("no matched categories")
-		return
-	}
-
-	for index, categoryEntity := range matchedCategoryList {
-		fmt.Println("category ", index, ": ", categoryEntity.ToString())
+func queryByParentId(ownerPlainId, plainParentId string) []model.CategoryEntity {
+	categoryList := []model.CategoryEntity{}
+	for _, categoryEntity := range category_mapper.INSTANCE.GetCategoryByParentId(plainParentId) {
+		if categoryEntity.IsOwnedBy(ownerPlainId) {
+			categoryList = append(categoryList, categoryEntity)
+		}
 	}
+	return categoryList
 }
 
-func queryByName(categoryName string) {
-	categoryEntity := category_mapper.INSTANCE.GetCategoryByName(categoryName)
+func queryByName(ownerPlainId, categoryName string) []model.CategoryEntity {
+	categoryEntity := category_mapper.INSTANCE.GetCategoryByName(ownerPlainId, categoryName)
 	if categoryEntity.IsEmpty() {
-		fmt.Println("category not found")
-		return
+		return []model.CategoryEntity{}
 	}
-	fmt.Println("category ", 0, ": ", categoryEntity.ToString())
+	return []model.CategoryEntity{categoryEntity}
 }
@@ -6,22 +6,22 @@ import (
 	"github.com/macar-x/cashlens/mapper/category_mapper"
 )
 
-// UpdateService updates a category by ID
-func UpdateService(plainId, parentPlainId, categoryName string) error {
+// UpdateService updates one of the owner's categories by ID
+func UpdateService(ownerPlainId, plainId, parentPlainId, categoryName string) error {
 	if plainId == "" {
 		return errors.New("id cannot be empty")
 	}
 
 	// Query existing category
 	existingCategory := category_mapper.INSTANCE.GetCategoryByObjectId(plainId)
-	if existingCategory.IsEmpty() {
+	if existingCategory.IsEmpty() || !existingCategory.IsOwnedBy(ownerPlainId) {
 		return errors.New("category not found")
 	}
 
 	// Update fields that are provided
 	if parentPlainId != "" {
 		parentCategory := category_mapper.INSTANCE.GetCategoryByObjectId(parentPlainId)
-		if parentCategory.IsEmpty() {
+		if parentCategory.IsEmpty() || !parentCategory.IsOwnedBy(ownerPlainId) {
 			return errors.New("parent category does not exist")
 		}
 		// Prevent circular reference
@@ -56,6 +56,20 @@ func TestConnection() (*ConnectionInfo, error) {
 		_ = database.GetMySqlConnection()
 		database.CloseMySqlConnection()
 		info.Status = "connected"
+
+	case "sqlite":
+		// The database file takes the place of a host
+		info.Host = util.GetConfigByKey("db.sqlite.path")
+
+		if err := database.GetSqliteConnection().Ping(); err != nil {
+			return info, err
+		}
+		info.Status = "connected"
+
+	case "memory":
+		// Data lives in the process, there is nothing to connect to
+		info.Host = "in-memory"
+		info.Status = "connected"
 	}
 
 	return info, nil
@@ -0,0 +1,82 @@
+package exchange_rate_service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+)
+
+// ConvertAmount converts amount from one currency into another with the owner's rate in effect on date.
+// The rate of the pair itself is preferred, the inverse of the opposite pair is used otherwise.
+// Blank currencies mean the default currency.
+func ConvertAmount(ownerPlainId string, amount decimal.Decimal, fromCurrency, toCurrency string, date time.Time) (decimal.Decimal, error) {
+	return convertWith(func(fromCurrency, toCurrency string, date time.Time) model.ExchangeRateEntity {
+		return exchange_rate_mapper.INSTANCE.GetExchangeRateInEffect(ownerPlainId, fromCurrency, toCurrency, date)
+	}, amount, fromCurrency, toCurrency, date)
+}
+
+// RateConverter converts amounts like ConvertAmount, with the owner's rates loaded once
+// so that a report converting many records does not look every rate up in the database
+type RateConverter struct {
+	pairRateMap map[string][]model.ExchangeRateEntity // by FROM/TO, earliest effective date first
+}
+
+// NewRateConverter loads the owner's rates
+func NewRateConverter(ownerPlainId string) RateConverter {
+	converter := RateConverter{pairRateMap: make(map[string][]model.ExchangeRateEntity)}
+	for _, rateEntity := range exchange_rate_mapper.INSTANCE.GetExchangeRatesByOwnerId(ownerPlainId, 0, 0) {
+		pairKey := rateEntity.FromCurrency + "/" + rateEntity.ToCurrency
+		converter.pairRateMap[pairKey] = append(converter.pairRateMap[pairKey], rateEntity)
+	}
+	for _, rateList := range converter.pairRateMap {
+		sort.Slice(rateList, func(i, j int) bool {
+			return rateList[i].EffectiveDate.Before(rateList[j].EffectiveDate)
+		})
+	}
+	return converter
+}
+
+// Convert converts amount from one currency into another with the rate in effect on date
+func (converter RateConverter) Convert(amount decimal.Decimal, fromCurrency, toCurrency string, date time.Time) (decimal.Decimal, error) {
+	return convertWith(converter.rateInEffect, amount, fromCurrency, toCurrency, date)
+}
+
+// rateInEffect returns the latest rate of the pair whose effective date is not after date
+func (converter RateConverter) rateInEffect(fromCurrency, toCurrency string, date time.Time) model.ExchangeRateEntity {
+	rateList := converter.pairRateMap[fromCurrency+"/"+toCurrency]
+	index := sort.Search(len(rateList), func(i int) bool {
+		return rateList[i].EffectiveDate.After(date)
+	})
+	if index == 0 {
+		return model.ExchangeRateEntity{}
+	}
+	return rateList[index-1]
+}
+
+// convertWith converts amount with the rate rateInEffect finds for the pair, or the inverse of the opposite pair
+func convertWith(rateInEffect func(fromCurrency, toCurrency string, date time.Time) model.ExchangeRateEntity,
+	amount decimal.Decimal, fromCurrency, toCurrency string, date time.Time) (decimal.Decimal, error) {
+	fromCurrency = NormalizeCurrency(fromCurrency)
+	toCurrency = NormalizeCurrency(toCurrency)
+	if fromCurrency == toCurrency {
+		return amount, nil
+	}
+
+	var converted decimal.Decimal
+	if directRate := rateInEffect(fromCurrency, toCurrency, date); !directRate.IsEmpty() {
+		converted = amount.Mul(directRate.Rate)
+	} else if inverseRate := rateInEffect(toCurrency, fromCurrency, date); !inverseRate.IsEmpty() {
+		converted = amount.Div(inverseRate.Rate)
+	} else {
+		return decimal.Zero, fmt.Errorf("no exchange rate from %s to %s in effect on %s",
+			fromCurrency, toCurrency, util.FormatDateToStringWithDash(date))
+	}
+
+	// 取小數點後兩位
+	return converted.Round(2), nil
+}
@@ -0,0 +1,98 @@
+package exchange_rate_service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"github.com/shopspring/decimal"
+)
+
+// CreateService saves the owner's rate of a currency pair from effectiveDate on,
+// a rate the owner already set for the same pair and date is replaced.
+func CreateService(ownerPlainId, fromCurrency, toCurrency string, rate decimal.Decimal, effectiveDate string) (model.ExchangeRateEntity, error) {
+	newEntity, err := buildExchangeRate(fromCurrency, toCurrency, rate, effectiveDate)
+	if err != nil {
+		return model.ExchangeRateEntity{}, err
+	}
+
+	savedEntity, _, err := saveExchangeRate(ownerPlainId, newEntity)
+	return savedEntity, err
+}
+
+// NormalizeCurrency upper-cases a currency code, a blank code means the configured default currency
+func NormalizeCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return util.GetConfigByKey("currency.default")
+	}
+	return currency
+}
+
+func buildExchangeRate(fromCurrency, toCurrency string, rate decimal.Decimal, effectiveDate string) (model.ExchangeRateEntity, error) {
+	fromCurrency = strings.ToUpper(strings.TrimSpace(fromCurrency))
+	if err := validation.ValidateCurrency(fromCurrency); err != nil {
+		return model.ExchangeRateEntity{}, err
+	}
+	toCurrency = strings.ToUpper(strings.TrimSpace(toCurrency))
+	if err := validation.ValidateCurrency(toCurrency); err != nil {
+		return model.ExchangeRateEntity{}, err
+	}
+	if fromCurrency == toCurrency {
+		return model.ExchangeRateEntity{}, errors.New("from_currency and to_currency should be different")
+	}
+
+	if err := validation.ValidateExchangeRate(rate); err != nil {
+		return model.ExchangeRateEntity{}, err
+	}
+
+	if effectiveDate != "" {
+		if err := validation.ValidateDate(effectiveDate); err != nil {
+			return model.ExchangeRateEntity{}, err
+		}
+	}
+
+	// 取小數點後六位
+	rate = rate.Round(6)
+
+	// 選填參數: 生效日期（默認當天）
+	date := util.FormatDateFromStringWithoutDash(util.FormatDateToStringWithoutDash(time.Now()))
+	if effectiveDate != "" {
+		date = util.FormatDateFromStringWithOptionalDash(effectiveDate)
+	}
+
+	return model.ExchangeRateEntity{
+		FromCurrency:  fromCurrency,
+		ToCurrency:    toCurrency,
+		Rate:          rate,
+		EffectiveDate: date,
+	}, nil
+}
+
+// saveExchangeRate inserts the owner's rate, or updates the one of the same pair and effective date,
+// the returned flag tells whether a new rate was created.
+func saveExchangeRate(ownerPlainId string, newEntity model.ExchangeRateEntity) (model.ExchangeRateEntity, bool, error) {
+	newEntity.OwnerId = util.ConvertOwner2ObjectId(ownerPlainId)
+	pairRateList := exchange_rate_mapper.INSTANCE.GetExchangeRatesByCurrencyPair(ownerPlainId, newEntity.FromCurrency, newEntity.ToCurrency)
+	for _, existingEntity := range pairRateList {
+		if !existingEntity.EffectiveDate.Equal(newEntity.EffectiveDate) {
+			continue
+		}
+
+		updatedEntity := exchange_rate_mapper.INSTANCE.UpdateExchangeRateByEntity(existingEntity.Id.Hex(), newEntity)
+		if updatedEntity.IsEmpty() {
+			return model.ExchangeRateEntity{}, false, errors.New("failed to update exchange rate")
+		}
+		return updatedEntity, false, nil
+	}
+
+	newPlainId := exchange_rate_mapper.INSTANCE.InsertExchangeRateByEntity(newEntity)
+	if newPlainId == "" {
+		return model.ExchangeRateEntity{}, false, errors.New("exchange rate create failed")
+	}
+	return exchange_rate_mapper.INSTANCE.GetExchangeRateByObjectId(newPlainId), true, nil
+}
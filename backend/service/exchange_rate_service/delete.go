@@ -0,0 +1,27 @@
+package exchange_rate_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// DeleteService deletes one of the owner's exchange rates by ID
+func DeleteService(ownerPlainId, plainId string) (model.ExchangeRateEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.ExchangeRateEntity{}, err
+	}
+
+	existingEntity := exchange_rate_mapper.INSTANCE.GetExchangeRateByObjectId(plainId)
+	if existingEntity.IsEmpty() || !existingEntity.IsOwnedBy(ownerPlainId) {
+		return model.ExchangeRateEntity{}, errors.New("exchange rate not found")
+	}
+
+	deletedEntity := exchange_rate_mapper.INSTANCE.DeleteExchangeRateByObjectId(plainId)
+	if deletedEntity.IsEmpty() {
+		return model.ExchangeRateEntity{}, errors.New("exchange rate delete failed")
+	}
+	return deletedEntity, nil
+}
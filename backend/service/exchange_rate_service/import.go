@@ -0,0 +1,65 @@
+package exchange_rate_service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/shopspring/decimal"
+)
+
+// ImportResult counts the rates created and replaced by an import
+type ImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// ImportService reads the owner's rates from CSV rows of from_currency,to_currency,rate,effective_date.
+// A header row is optional. Every row is checked before any is saved, so a bad file changes nothing.
+func ImportService(ownerPlainId string, reader io.Reader) (ImportResult, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = 4
+	csvReader.TrimLeadingSpace = true
+
+	recordList, err := csvReader.ReadAll()
+	if err != nil {
+		return ImportResult{}, err
+	}
+	if len(recordList) > 0 && strings.EqualFold(strings.TrimSpace(recordList[0][0]), "from_currency") {
+		recordList = recordList[1:]
+	}
+	if len(recordList) == 0 {
+		return ImportResult{}, errors.New("no exchange rate to import")
+	}
+
+	newEntityList := make([]model.ExchangeRateEntity, 0, len(recordList))
+	for index, record := range recordList {
+		rate, err := decimal.NewFromString(strings.TrimSpace(record[2]))
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("row %d: rate is not a number", index+1)
+		}
+
+		newEntity, err := buildExchangeRate(record[0], record[1], rate, strings.TrimSpace(record[3]))
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("row %d: %w", index+1, err)
+		}
+		newEntityList = append(newEntityList, newEntity)
+	}
+
+	var importResult ImportResult
+	for _, newEntity := range newEntityList {
+		_, created, err := saveExchangeRate(ownerPlainId, newEntity)
+		if err != nil {
+			return importResult, err
+		}
+		if created {
+			importResult.Created++
+		} else {
+			importResult.Updated++
+		}
+	}
+	return importResult, nil
+}
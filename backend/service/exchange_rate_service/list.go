@@ -0,0 +1,17 @@
+package exchange_rate_service
+
+import (
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/model"
+)
+
+// ListAllService lists the owner's exchange rates with pagination, grouped by pair with the newest rate first
+func ListAllService(ownerPlainId string, limit, offset int) ([]model.ExchangeRateEntity, int64, error) {
+	totalCount := exchange_rate_mapper.INSTANCE.CountExchangeRatesByOwnerId(ownerPlainId)
+
+	exchangeRateEntityList := exchange_rate_mapper.INSTANCE.GetExchangeRatesByOwnerId(ownerPlainId, limit, offset)
+	if exchangeRateEntityList == nil {
+		exchangeRateEntityList = []model.ExchangeRateEntity{}
+	}
+	return exchangeRateEntityList, totalCount, nil
+}
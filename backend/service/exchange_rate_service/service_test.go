@@ -0,0 +1,137 @@
+package exchange_rate_service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+)
+
+// resetMappers gives each test empty in-memory storage, so no database is needed
+func resetMappers() {
+	exchange_rate_mapper.INSTANCE = exchange_rate_mapper.NewExchangeRateMemoryMapper()
+}
+
+func TestCreateService(t *testing.T) {
+	resetMappers()
+
+	created, err := CreateService("", "eur", "usd", decimal.RequireFromString("1.123456789"), "20240101")
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+	if created.FromCurrency != "EUR" || created.ToCurrency != "USD" || created.Rate.String() != "1.123457" {
+		t.Errorf("CreateService() = %+v", created)
+	}
+
+	// The same pair and date replaces the rate instead of adding another
+	replaced, err := CreateService("", "EUR", "USD", decimal.RequireFromString("1.2"), "2024-01-01")
+	if err != nil || replaced.Id != created.Id || !replaced.Rate.Equal(decimal.RequireFromString("1.2")) {
+		t.Errorf("CreateService() = %+v, %v, want the existing rate replaced", replaced, err)
+	}
+
+	tests := []struct {
+		name         string
+		fromCurrency string
+		toCurrency   string
+		rate         decimal.Decimal
+	}{
+		{"same currency", "USD", "USD", decimal.NewFromInt(1)},
+		{"bad code", "EURO", "USD", decimal.NewFromInt(1)},
+		{"zero rate", "EUR", "USD", decimal.Zero},
+		{"negative rate", "EUR", "USD", decimal.RequireFromString("-1.1")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CreateService("", tt.fromCurrency, tt.toCurrency, tt.rate, ""); err == nil {
+				t.Errorf("CreateService() expected error, got nil")
+			}
+		})
+	}
+
+	if _, totalCount, _ := ListAllService("", 0, 0); totalCount != 1 {
+		t.Errorf("ListAllService() count = %d, want 1", totalCount)
+	}
+	if _, err := DeleteService("", created.Id.Hex()); err != nil {
+		t.Errorf("DeleteService() error = %v", err)
+	}
+}
+
+func TestImportService(t *testing.T) {
+	resetMappers()
+	if _, err := CreateService("", "EUR", "USD", decimal.NewFromInt(1), "2024-01-01"); err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+
+	importResult, err := ImportService("", strings.NewReader(
+		"from_currency,to_currency,rate,effective_date\n"+
+			"EUR,USD,1.1,2024-01-01\n"+
+			"EUR,USD,1.2,20240601\n"+
+			"gbp, usd, 1.25, 2024-01-01\n"))
+	if err != nil {
+		t.Fatalf("ImportService() error = %v", err)
+	}
+	if importResult.Created != 2 || importResult.Updated != 1 {
+		t.Errorf("ImportService() = %+v, want 2 created and 1 updated", importResult)
+	}
+
+	// A bad row fails the whole file before anything is saved
+	if _, err := ImportService("", strings.NewReader("JPY,USD,0.0067,2024-01-01\nJPY,USD,abc,2024-02-01\n")); err == nil {
+		t.Errorf("ImportService() with a bad rate expected error, got nil")
+	}
+	if _, totalCount, _ := ListAllService("", 0, 0); totalCount != 3 {
+		t.Errorf("ListAllService() count = %d, want 3", totalCount)
+	}
+}
+
+func TestConvertAmount(t *testing.T) {
+	resetMappers()
+	for _, rate := range []struct {
+		rate          string
+		effectiveDate string
+	}{
+		{"1.1", "2024-01-01"},
+		{"1.2", "2024-06-01"},
+	} {
+		if _, err := CreateService("", "EUR", "USD", decimal.RequireFromString(rate.rate), rate.effectiveDate); err != nil {
+			t.Fatalf("CreateService() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		amount       string
+		fromCurrency string
+		toCurrency   string
+		date         string
+		want         string
+		wantErr      bool
+	}{
+		{"same currency", "10.05", "usd", "USD", "2024-03-01", "10.05", false},
+		{"direct rate", "100", "EUR", "USD", "2024-03-01", "110", false},
+		{"later rate", "100", "EUR", "USD", "2024-06-01", "120", false},
+		{"inverse rate", "100", "USD", "EUR", "2024-03-01", "90.91", false},
+		{"blank is default", "120", "", "EUR", "2024-07-01", "100", false},
+		{"before any rate", "100", "EUR", "USD", "2023-12-31", "0", true},
+		{"unknown pair", "100", "EUR", "JPY", "2024-03-01", "0", true},
+	}
+	rateConverter := NewRateConverter("")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertAmount("", decimal.RequireFromString(tt.amount), tt.fromCurrency, tt.toCurrency, util.FormatDateFromStringWithDash(tt.date))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConvertAmount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("ConvertAmount() = %v, want %v", got, tt.want)
+			}
+
+			// The rates loaded once convert alike
+			got, err = rateConverter.Convert(decimal.RequireFromString(tt.amount), tt.fromCurrency, tt.toCurrency, util.FormatDateFromStringWithDash(tt.date))
+			if (err != nil) != tt.wantErr || !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("RateConverter.Convert() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package goal_service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// CreateService creates a savings goal on one of the owner's accounts, its target is in the account's currency.
+// The start date defaults to today, only cash_flows booked from then on count toward the goal.
+func CreateService(ownerPlainId string, goalDTO model.GoalDTO) (model.GoalEntity, error) {
+	if err := validation.ValidateGoalName(goalDTO.Name); err != nil {
+		return model.GoalEntity{}, err
+	}
+	if !goal_mapper.INSTANCE.GetGoalByName(ownerPlainId, goalDTO.Name).IsEmpty() {
+		return model.GoalEntity{}, errors.New("goal already exists")
+	}
+
+	if err := validation.ValidateAmount(goalDTO.TargetAmount); err != nil {
+		return model.GoalEntity{}, err
+	}
+
+	// 必填參數: 帳戶（幣別跟隨帳戶）
+	if err := validation.ValidateAccountName(goalDTO.AccountName); err != nil {
+		return model.GoalEntity{}, err
+	}
+	accountId, err := cash_flow_service.GetAccountIdByName(ownerPlainId, goalDTO.AccountName)
+	if err != nil {
+		return model.GoalEntity{}, err
+	}
+	currency, err := cash_flow_service.ResolveCurrency(accountId, "")
+	if err != nil {
+		return model.GoalEntity{}, err
+	}
+
+	newEntity := model.GoalEntity{
+		OwnerId:      util.ConvertOwner2ObjectId(ownerPlainId),
+		Name:         goalDTO.Name,
+		AccountId:    accountId,
+		TargetAmount: goalDTO.TargetAmount.Round(2),
+		Currency:     currency,
+		// 選填參數: 開始日期（默認當天）
+		StartDate: util.FormatDateFromStringWithoutDash(util.FormatDateToStringWithoutDash(time.Now())),
+	}
+	if goalDTO.Deadline == "" {
+		return model.GoalEntity{}, validation.NewValidationError("deadline", "is required")
+	}
+	if err := applyDates(&newEntity, goalDTO.StartDate, goalDTO.Deadline); err != nil {
+		return model.GoalEntity{}, err
+	}
+
+	newPlainId := goal_mapper.INSTANCE.InsertGoalByEntity(newEntity)
+	if newPlainId == "" {
+		return model.GoalEntity{}, errors.New("goal create failed")
+	}
+	return goal_mapper.INSTANCE.GetGoalByObjectId(newPlainId), nil
+}
+
+// applyDates sets the start date and deadline, blank values keep the current ones
+func applyDates(entity *model.GoalEntity, startDate, deadline string) error {
+	if startDate != "" {
+		if err := validation.ValidateDate(startDate); err != nil {
+			return err
+		}
+		entity.StartDate = util.FormatDateFromStringWithOptionalDash(startDate)
+	}
+	if deadline != "" {
+		if err := validation.ValidateDate(deadline); err != nil {
+			return err
+		}
+		entity.Deadline = util.FormatDateFromStringWithOptionalDash(deadline)
+	}
+
+	if !entity.Deadline.After(entity.StartDate) {
+		return validation.NewValidationError("deadline", "must be after start_date")
+	}
+	return nil
+}
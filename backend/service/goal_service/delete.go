@@ -0,0 +1,27 @@
+package goal_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// DeleteService deletes one of the owner's goals, the cash_flows that contributed to it are kept
+func DeleteService(ownerPlainId, plainId string) (model.GoalEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.GoalEntity{}, err
+	}
+
+	existingGoal := goal_mapper.INSTANCE.GetGoalByObjectId(plainId)
+	if existingGoal.IsEmpty() || !existingGoal.IsOwnedBy(ownerPlainId) {
+		return model.GoalEntity{}, errors.New("goal not found")
+	}
+
+	deletedGoal := goal_mapper.INSTANCE.DeleteGoalByObjectId(plainId)
+	if deletedGoal.IsEmpty() {
+		return model.GoalEntity{}, errors.New("goal delete failed")
+	}
+	return deletedGoal, nil
+}
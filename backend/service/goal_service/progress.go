@@ -0,0 +1,176 @@
+package goal_service
+
+import (
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+)
+
+// Goal statuses reported by the progress
+const (
+	GoalStatusAchieved = "ACHIEVED" // saved reached the target
+	GoalStatusOnTrack  = "ON_TRACK" // saved at least as much as an even pace would have by now
+	GoalStatusBehind   = "BEHIND"   // saved less than an even pace would have by now
+	GoalStatusMissed   = "MISSED"   // the deadline passed before the target was reached
+)
+
+// GoalProgress reports how far a goal is on the reporting date. Saved is what was contributed
+// minus what was withdrawn; ExpectedSaved is where an even pace from start date to deadline would be.
+type GoalProgress struct {
+	GoalId            string          `json:"goal_id"`
+	Name              string          `json:"name"`
+	AccountName       string          `json:"account_name"`
+	Currency          string          `json:"currency"`
+	TargetAmount      decimal.Decimal `json:"target_amount"`
+	StartDate         string          `json:"start_date"`
+	Deadline          string          `json:"deadline"`
+	ContributionCount int             `json:"contribution_count"`
+	Contributed       decimal.Decimal `json:"contributed"`
+	Withdrawn         decimal.Decimal `json:"withdrawn"`
+	Saved             decimal.Decimal `json:"saved"`
+	Remaining         decimal.Decimal `json:"remaining"`
+	PercentComplete   decimal.Decimal `json:"percent_complete"`
+	ExpectedSaved     decimal.Decimal `json:"expected_saved"`
+	MonthsLeft        int             `json:"months_left"`
+	RequiredMonthly   decimal.Decimal `json:"required_monthly"`
+	OnTrack           bool            `json:"on_track"`
+	Status            string          `json:"status"`
+}
+
+// ProgressService reports the progress of one goal in the owner's ledger, found by either its id or its name, as of today
+func ProgressService(ownerPlainId, plainId, goalName string) (GoalProgress, error) {
+	goalEntity, err := QueryService(ownerPlainId, plainId, goalName)
+	if err != nil {
+		return GoalProgress{}, err
+	}
+	return buildProgress(ownerPlainId, goalEntity, today())
+}
+
+// ListProgressService reports the progress of every goal in the owner's ledger as of today, ordered by goal name
+func ListProgressService(ownerPlainId string) ([]GoalProgress, error) {
+	progressList := []GoalProgress{}
+	for _, goalEntity := range goal_mapper.INSTANCE.GetGoalsByOwnerId(ownerPlainId, 0, 0) {
+		progress, err := buildProgress(ownerPlainId, goalEntity, today())
+		if err != nil {
+			return nil, err
+		}
+		progressList = append(progressList, progress)
+	}
+	return progressList, nil
+}
+
+// ContributionsService lists the owner's cash_flows that count toward a goal: everything booked to its
+// account from the start date up to today, including outcomes and transfers out that reduce it
+func ContributionsService(ownerPlainId, plainId, goalName string) ([]model.CashFlowEntity, error) {
+	goalEntity, err := QueryService(ownerPlainId, plainId, goalName)
+	if err != nil {
+		return nil, err
+	}
+	return contributions(ownerPlainId, goalEntity, today()), nil
+}
+
+func today() time.Time {
+	return util.FormatDateFromStringWithoutDash(util.FormatDateToStringWithoutDash(time.Now()))
+}
+
+func contributions(ownerPlainId string, goal model.GoalEntity, asOf time.Time) []model.CashFlowEntity {
+	contributionList := []model.CashFlowEntity{}
+	if asOf.Before(goal.StartDate) {
+		return contributionList
+	}
+	for _, cashFlow := range cash_flow_mapper.INSTANCE.GetCashFlowsByDateRange(ownerPlainId, goal.StartDate, asOf) {
+		if cashFlow.AccountId == goal.AccountId {
+			contributionList = append(contributionList, cashFlow)
+		}
+	}
+	return contributionList
+}
+
+func buildProgress(ownerPlainId string, goal model.GoalEntity, asOf time.Time) (GoalProgress, error) {
+	contributed := decimal.Zero
+	withdrawn := decimal.Zero
+	contributionCount := 0
+	for _, cashFlow := range contributions(ownerPlainId, goal, asOf) {
+		// Income adds and outcome takes away, transfer legs are already signed by direction
+		signedAmount := cashFlow.Amount
+		if cashFlow.FlowType == model.FlowTypeOutcome {
+			signedAmount = signedAmount.Neg()
+		}
+		convertedAmount, err := exchange_rate_service.ConvertAmount(
+			ownerPlainId, signedAmount, cashFlow.Currency, goal.Currency, cashFlow.BelongsDate)
+		if err != nil {
+			return GoalProgress{}, err
+		}
+		if convertedAmount.IsNegative() {
+			withdrawn = withdrawn.Sub(convertedAmount)
+		} else {
+			contributed = contributed.Add(convertedAmount)
+			contributionCount++
+		}
+	}
+
+	saved := contributed.Sub(withdrawn)
+	remaining := decimal.Max(decimal.Zero, goal.TargetAmount.Sub(saved))
+	hundred := decimal.NewFromInt(100)
+
+	// An even pace saves the same share of the target every day from start date to deadline
+	expectedSaved := decimal.Zero
+	totalDays := daysBetween(goal.StartDate, goal.Deadline) + 1
+	if elapsedDays := daysBetween(goal.StartDate, asOf) + 1; elapsedDays >= totalDays {
+		expectedSaved = goal.TargetAmount
+	} else if elapsedDays > 0 {
+		expectedSaved = goal.TargetAmount.Mul(decimal.NewFromInt(elapsedDays)).Div(decimal.NewFromInt(totalDays)).Round(2)
+	}
+
+	// The current month counts as one left, contributions booked later this month still help
+	monthsLeft := 0
+	if !asOf.After(goal.Deadline) {
+		monthsLeft = (goal.Deadline.Year()-asOf.Year())*12 + int(goal.Deadline.Month()-asOf.Month()) + 1
+	}
+	requiredMonthly := remaining
+	if monthsLeft > 0 {
+		requiredMonthly = remaining.Div(decimal.NewFromInt(int64(monthsLeft))).RoundCeil(2)
+	}
+
+	status := GoalStatusBehind
+	switch {
+	case !saved.LessThan(goal.TargetAmount):
+		status = GoalStatusAchieved
+	case asOf.After(goal.Deadline):
+		status = GoalStatusMissed
+	case !saved.LessThan(expectedSaved):
+		status = GoalStatusOnTrack
+	}
+
+	return GoalProgress{
+		GoalId:            goal.Id.Hex(),
+		Name:              goal.Name,
+		AccountName:       account_mapper.INSTANCE.GetAccountByObjectId(goal.AccountId.Hex()).Name,
+		Currency:          goal.Currency,
+		TargetAmount:      goal.TargetAmount,
+		StartDate:         util.FormatDateToStringWithDash(goal.StartDate),
+		Deadline:          util.FormatDateToStringWithDash(goal.Deadline),
+		ContributionCount: contributionCount,
+		Contributed:       contributed,
+		Withdrawn:         withdrawn,
+		Saved:             saved,
+		Remaining:         remaining,
+		PercentComplete:   saved.Mul(hundred).Div(goal.TargetAmount).Round(2),
+		ExpectedSaved:     expectedSaved,
+		MonthsLeft:        monthsLeft,
+		RequiredMonthly:   requiredMonthly,
+		OnTrack:           status == GoalStatusAchieved || status == GoalStatusOnTrack,
+		Status:            status,
+	}, nil
+}
+
+// daysBetween counts whole days from one date to another, negative when to is earlier
+func daysBetween(from, to time.Time) int64 {
+	return int64(to.Sub(from).Hours() / 24)
+}
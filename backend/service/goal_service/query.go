@@ -0,0 +1,31 @@
+package goal_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// QueryService finds one of the owner's goals by either its id or its name
+func QueryService(ownerPlainId, plainId, goalName string) (model.GoalEntity, error) {
+	if (plainId == "") == (goalName == "") {
+		return model.GoalEntity{}, errors.New("should have one and only one query type")
+	}
+
+	var goalEntity model.GoalEntity
+	if plainId != "" {
+		if err := validation.ValidateID(plainId); err != nil {
+			return model.GoalEntity{}, err
+		}
+		goalEntity = goal_mapper.INSTANCE.GetGoalByObjectId(plainId)
+	} else {
+		goalEntity = goal_mapper.INSTANCE.GetGoalByName(ownerPlainId, goalName)
+	}
+
+	if goalEntity.IsEmpty() || !goalEntity.IsOwnedBy(ownerPlainId) {
+		return model.GoalEntity{}, errors.New("goal not found")
+	}
+	return goalEntity, nil
+}
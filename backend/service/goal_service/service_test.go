@@ -0,0 +1,169 @@
+package goal_service
+
+import (
+	"testing"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// resetMappers gives each test empty in-memory storage with a USD Savings and a EUR Checking account
+func resetMappers(t *testing.T) map[string]primitive.ObjectID {
+	t.Helper()
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	account_mapper.INSTANCE = account_mapper.NewAccountMemoryMapper()
+	goal_mapper.INSTANCE = goal_mapper.NewGoalMemoryMapper()
+
+	accountIds := make(map[string]primitive.ObjectID)
+	for _, account := range []struct{ name, currency string }{{"Savings", "USD"}, {"Checking", "EUR"}} {
+		plainId := account_mapper.INSTANCE.InsertAccountByEntity(model.AccountEntity{
+			Name:     account.name,
+			Type:     model.AccountTypeBank,
+			Currency: account.currency,
+		})
+		if plainId == "" {
+			t.Fatalf("insert account %q failed", account.name)
+		}
+		accountIds[account.name] = util.Convert2ObjectId(plainId)
+	}
+	return accountIds
+}
+
+func book(t *testing.T, accountId primitive.ObjectID, belongsDate, flowType, amount, currency string) {
+	t.Helper()
+	plainId := cash_flow_mapper.INSTANCE.InsertCashFlowByEntity(model.CashFlowEntity{
+		AccountId:   accountId,
+		BelongsDate: util.FormatDateFromStringWithDash(belongsDate),
+		FlowType:    flowType,
+		Amount:      decimal.RequireFromString(amount),
+		Currency:    currency,
+	})
+	if plainId == "" {
+		t.Fatalf("insert cash_flow on %s failed", belongsDate)
+	}
+}
+
+func TestGoalLifecycle(t *testing.T) {
+	resetMappers(t)
+
+	created, err := CreateService("", model.GoalDTO{Name: "Vacation", AccountName: "Savings",
+		TargetAmount: decimal.RequireFromString("1200.005"), StartDate: "2024-01-01", Deadline: "2024-12-31"})
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+	if created.Currency != "USD" || !created.TargetAmount.Equal(decimal.RequireFromString("1200.01")) ||
+		util.FormatDateToStringWithDash(created.StartDate) != "2024-01-01" {
+		t.Errorf("CreateService() = %+v", created)
+	}
+
+	tests := []struct {
+		name    string
+		goalDTO model.GoalDTO
+	}{
+		{"duplicate name", model.GoalDTO{Name: "Vacation", AccountName: "Savings",
+			TargetAmount: decimal.NewFromInt(100), Deadline: "2099-01-01"}},
+		{"unknown account", model.GoalDTO{Name: "Car", AccountName: "Cash",
+			TargetAmount: decimal.NewFromInt(100), Deadline: "2099-01-01"}},
+		{"zero target", model.GoalDTO{Name: "Car", AccountName: "Savings", Deadline: "2099-01-01"}},
+		{"missing deadline", model.GoalDTO{Name: "Car", AccountName: "Savings", TargetAmount: decimal.NewFromInt(100)}},
+		{"deadline before start", model.GoalDTO{Name: "Car", AccountName: "Savings",
+			TargetAmount: decimal.NewFromInt(100), StartDate: "2024-06-01", Deadline: "2024-05-31"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CreateService("", tt.goalDTO); err == nil {
+				t.Errorf("CreateService() expected an error")
+			}
+		})
+	}
+
+	// Moving the goal to another account takes that account's currency, blank fields are kept
+	updated, err := UpdateService("", created.Id.Hex(), model.GoalDTO{AccountName: "Checking"})
+	if err != nil || updated.Currency != "EUR" || updated.Name != "Vacation" ||
+		!updated.Deadline.Equal(created.Deadline) {
+		t.Errorf("UpdateService() = %+v, %v", updated, err)
+	}
+	if _, err := UpdateService("", created.Id.Hex(), model.GoalDTO{Deadline: "2023-12-31"}); err == nil {
+		t.Errorf("UpdateService() expected an error for a deadline before the start date")
+	}
+
+	if found, err := QueryService("", "", "Vacation"); err != nil || found.Id != created.Id {
+		t.Errorf("QueryService() = %+v, %v", found, err)
+	}
+	if _, err := DeleteService("", created.Id.Hex()); err != nil {
+		t.Fatalf("DeleteService() error = %v", err)
+	}
+	if _, err := QueryService("", created.Id.Hex(), ""); err == nil {
+		t.Errorf("QueryService() expected an error after delete")
+	}
+}
+
+func TestBuildProgress(t *testing.T) {
+	accountIds := resetMappers(t)
+
+	vacation, err := CreateService("", model.GoalDTO{Name: "Vacation", AccountName: "Savings",
+		TargetAmount: decimal.NewFromInt(1200), StartDate: "2024-01-01", Deadline: "2024-12-31"})
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+	laptop, err := CreateService("", model.GoalDTO{Name: "Laptop", AccountName: "Checking",
+		TargetAmount: decimal.NewFromInt(1000), StartDate: "2024-01-01", Deadline: "2024-06-30"})
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+
+	// Only records of the goal's account booked from the start date on count
+	book(t, accountIds["Savings"], "2023-12-31", model.FlowTypeIncome, "1000", "USD")
+	book(t, accountIds["Savings"], "2024-01-15", model.FlowTypeIncome, "300", "USD")
+	book(t, accountIds["Savings"], "2024-02-10", model.FlowTypeTransfer, "200", "USD")
+	book(t, accountIds["Savings"], "2024-03-05", model.FlowTypeOutcome, "50", "USD")
+	book(t, accountIds["Savings"], "2024-03-20", model.FlowTypeTransfer, "-100", "USD")
+	book(t, accountIds["Savings"], "2024-07-01", model.FlowTypeIncome, "900", "USD")
+	book(t, accountIds["Checking"], "2024-02-01", model.FlowTypeIncome, "500", "EUR")
+
+	tests := []struct {
+		name                string
+		goal                model.GoalEntity
+		asOf                string
+		wantCount           int
+		wantSaved           string
+		wantExpected        string
+		wantMonthsLeft      int
+		wantRequiredMonthly string
+		wantStatus          string
+	}{
+		{"ahead of an even pace", vacation, "2024-03-31", 2, "350", "298.36", 10, "85", GoalStatusOnTrack},
+		{"behind an even pace", vacation, "2024-06-30", 2, "350", "596.72", 7, "121.43", GoalStatusBehind},
+		{"target reached", vacation, "2024-07-31", 3, "1250", "698.36", 6, "0", GoalStatusAchieved},
+		{"deadline passed", laptop, "2024-07-15", 1, "500", "1000", 0, "500", GoalStatusMissed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress, err := buildProgress("", tt.goal, util.FormatDateFromStringWithDash(tt.asOf))
+			if err != nil {
+				t.Fatalf("buildProgress() error = %v", err)
+			}
+			if progress.ContributionCount != tt.wantCount ||
+				!progress.Saved.Equal(decimal.RequireFromString(tt.wantSaved)) ||
+				!progress.ExpectedSaved.Equal(decimal.RequireFromString(tt.wantExpected)) ||
+				progress.MonthsLeft != tt.wantMonthsLeft ||
+				!progress.RequiredMonthly.Equal(decimal.RequireFromString(tt.wantRequiredMonthly)) ||
+				progress.Status != tt.wantStatus {
+				t.Errorf("buildProgress() = %+v", progress)
+			}
+		})
+	}
+
+	progress, err := buildProgress("", vacation, util.FormatDateFromStringWithDash("2024-03-31"))
+	if err != nil || !progress.Contributed.Equal(decimal.NewFromInt(500)) ||
+		!progress.Withdrawn.Equal(decimal.NewFromInt(150)) || !progress.Remaining.Equal(decimal.NewFromInt(850)) ||
+		!progress.PercentComplete.Equal(decimal.RequireFromString("29.17")) || !progress.OnTrack ||
+		progress.AccountName != "Savings" {
+		t.Errorf("buildProgress() = %+v, %v", progress, err)
+	}
+}
@@ -0,0 +1,66 @@
+package goal_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// UpdateService updates one of the owner's goals by ID, blank fields are kept.
+// Moving the goal to another account also takes that account's currency.
+func UpdateService(ownerPlainId, plainId string, goalDTO model.GoalDTO) (model.GoalEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.GoalEntity{}, err
+	}
+
+	existingGoal := goal_mapper.INSTANCE.GetGoalByObjectId(plainId)
+	if existingGoal.IsEmpty() || !existingGoal.IsOwnedBy(ownerPlainId) {
+		return model.GoalEntity{}, errors.New("goal not found")
+	}
+
+	if goalDTO.Name != "" && goalDTO.Name != existingGoal.Name {
+		if err := validation.ValidateGoalName(goalDTO.Name); err != nil {
+			return model.GoalEntity{}, err
+		}
+		if !goal_mapper.INSTANCE.GetGoalByName(ownerPlainId, goalDTO.Name).IsEmpty() {
+			return model.GoalEntity{}, errors.New("goal already exists")
+		}
+		existingGoal.Name = goalDTO.Name
+	}
+
+	if goalDTO.AccountName != "" {
+		if err := validation.ValidateAccountName(goalDTO.AccountName); err != nil {
+			return model.GoalEntity{}, err
+		}
+		accountId, err := cash_flow_service.GetAccountIdByName(ownerPlainId, goalDTO.AccountName)
+		if err != nil {
+			return model.GoalEntity{}, err
+		}
+		currency, err := cash_flow_service.ResolveCurrency(accountId, "")
+		if err != nil {
+			return model.GoalEntity{}, err
+		}
+		existingGoal.AccountId = accountId
+		existingGoal.Currency = currency
+	}
+
+	if !goalDTO.TargetAmount.IsZero() {
+		if err := validation.ValidateAmount(goalDTO.TargetAmount); err != nil {
+			return model.GoalEntity{}, err
+		}
+		existingGoal.TargetAmount = goalDTO.TargetAmount.Round(2)
+	}
+
+	if err := applyDates(&existingGoal, goalDTO.StartDate, goalDTO.Deadline); err != nil {
+		return model.GoalEntity{}, err
+	}
+
+	updatedEntity := goal_mapper.INSTANCE.UpdateGoalByEntity(plainId, existingGoal)
+	if updatedEntity.IsEmpty() {
+		return model.GoalEntity{}, errors.New("failed to update goal")
+	}
+	return updatedEntity, nil
+}
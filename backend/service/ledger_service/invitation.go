@@ -0,0 +1,106 @@
+package ledger_service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/macar-x/cashlens/errors"
+	"github.com/macar-x/cashlens/mapper/ledger_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+)
+
+const (
+	// invitationTokenPrefix tells invitation tokens apart from sign-in and API tokens
+	invitationTokenPrefix = "clinv_"
+	// invitationTTL is how long an invitation can be accepted
+	invitationTTL = 7 * 24 * time.Hour
+)
+
+// InviteService makes an invitation to a shared ledger, only an owner may invite. The token is returned
+// only here and is handed to the invited user by hand, what is stored is its hash.
+func InviteService(userPlainId, ledgerPlainId string, roleDTO model.LedgerRoleDTO) (model.LedgerInvitationView, error) {
+	role := strings.ToUpper(strings.TrimSpace(roleDTO.Role))
+	if role == "" {
+		role = model.LedgerRoleViewer
+	}
+	if err := validation.ValidateLedgerRole(role); err != nil {
+		return model.LedgerInvitationView{}, err
+	}
+	if err := requireSharedOwner(userPlainId, ledgerPlainId); err != nil {
+		return model.LedgerInvitationView{}, err
+	}
+
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return model.LedgerInvitationView{}, err
+	}
+	token := invitationTokenPrefix + base64.RawURLEncoding.EncodeToString(randomBytes)
+
+	invitationEntity := model.LedgerInvitationEntity{
+		LedgerId:   util.Convert2ObjectId(ledgerPlainId),
+		Role:       role,
+		TokenHash:  hashInvitationToken(token),
+		InvitedBy:  util.Convert2ObjectId(userPlainId),
+		ExpireTime: time.Now().Add(invitationTTL),
+	}
+	newPlainId := ledger_mapper.INSTANCE.InsertLedgerInvitationByEntity(invitationEntity)
+	if newPlainId == "" {
+		return model.LedgerInvitationView{}, errors.NewDatabaseError("ledger invitation create failed", nil)
+	}
+	return model.LedgerInvitationView{
+		Id:         newPlainId,
+		LedgerId:   ledgerPlainId,
+		Role:       role,
+		Token:      token,
+		ExpireTime: invitationEntity.ExpireTime,
+	}, nil
+}
+
+// AcceptInvitationService adds the user to the ledger of the invitation with its role.
+// An invitation is used up when accepted.
+func AcceptInvitationService(userPlainId string, acceptDTO model.LedgerInvitationAcceptDTO) (model.LedgerView, error) {
+	token := strings.TrimSpace(acceptDTO.Token)
+	if err := validation.ValidateRequired("token", token); err != nil {
+		return model.LedgerView{}, err
+	}
+
+	invitationEntity := ledger_mapper.INSTANCE.GetLedgerInvitationByHash(hashInvitationToken(token))
+	if invitationEntity.IsEmpty() {
+		return model.LedgerView{}, errors.NewNotFoundError("invitation does not exist or was used")
+	}
+	if time.Now().After(invitationEntity.ExpireTime) {
+		ledger_mapper.INSTANCE.DeleteLedgerInvitationByObjectId(invitationEntity.Id.Hex())
+		return model.LedgerView{}, validation.NewValidationError("token", "invitation expired")
+	}
+	ledgerPlainId := invitationEntity.LedgerId.Hex()
+	if !ledger_mapper.INSTANCE.GetLedgerMember(ledgerPlainId, userPlainId).IsEmpty() {
+		return model.LedgerView{}, errors.NewAlreadyExistsError("already a member of the ledger")
+	}
+
+	// Whoever deletes the invitation first gets it, so it is not accepted twice
+	if ledger_mapper.INSTANCE.DeleteLedgerInvitationByObjectId(invitationEntity.Id.Hex()).IsEmpty() {
+		return model.LedgerView{}, errors.NewNotFoundError("invitation does not exist or was used")
+	}
+	memberPlainId := ledger_mapper.INSTANCE.InsertLedgerMemberByEntity(model.LedgerMemberEntity{
+		LedgerId: invitationEntity.LedgerId,
+		UserId:   util.Convert2ObjectId(userPlainId),
+		Role:     invitationEntity.Role,
+	})
+	if memberPlainId == "" {
+		return model.LedgerView{}, errors.NewDatabaseError("ledger member create failed", nil)
+	}
+
+	ledgerEntity := ledger_mapper.INSTANCE.GetLedgerByObjectId(ledgerPlainId)
+	return model.LedgerView{Id: ledgerPlainId, Name: ledgerEntity.Name, Role: invitationEntity.Role}, nil
+}
+
+func hashInvitationToken(token string) string {
+	tokenHash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(tokenHash[:])
+}
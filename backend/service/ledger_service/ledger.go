@@ -0,0 +1,95 @@
+package ledger_service
+
+import (
+	"strings"
+
+	"github.com/macar-x/cashlens/errors"
+	"github.com/macar-x/cashlens/mapper/ledger_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// personalLedgerName is shown for the ledger every user has on their own
+const personalLedgerName = "Personal"
+
+// CreateService makes a shared ledger, the user creating it becomes its first owner
+func CreateService(userPlainId string, ledgerDTO model.LedgerDTO) (model.LedgerView, error) {
+	ledgerDTO.Name = strings.TrimSpace(ledgerDTO.Name)
+	if err := validation.ValidateLedgerName(ledgerDTO.Name); err != nil {
+		return model.LedgerView{}, err
+	}
+	if err := validation.ValidateID(userPlainId); err != nil {
+		return model.LedgerView{}, err
+	}
+
+	newPlainId := ledger_mapper.INSTANCE.InsertLedgerByEntity(model.LedgerEntity{Name: ledgerDTO.Name})
+	if newPlainId == "" {
+		return model.LedgerView{}, errors.NewDatabaseError("ledger create failed", nil)
+	}
+	memberPlainId := ledger_mapper.INSTANCE.InsertLedgerMemberByEntity(model.LedgerMemberEntity{
+		LedgerId: util.Convert2ObjectId(newPlainId),
+		UserId:   util.Convert2ObjectId(userPlainId),
+		Role:     model.LedgerRoleOwner,
+	})
+	if memberPlainId == "" {
+		return model.LedgerView{}, errors.NewDatabaseError("ledger owner create failed", nil)
+	}
+	return model.LedgerView{Id: newPlainId, Name: ledgerDTO.Name, Role: model.LedgerRoleOwner}, nil
+}
+
+// ListService returns the user's personal ledger followed by the shared ledgers they joined, oldest first
+func ListService(userPlainId string) []model.LedgerView {
+	ledgerList := []model.LedgerView{
+		{Id: userPlainId, Name: personalLedgerName, Role: model.LedgerRoleOwner, IsPersonal: true},
+	}
+	for _, memberEntity := range ledger_mapper.INSTANCE.GetLedgerMembersByUserId(userPlainId) {
+		ledgerEntity := ledger_mapper.INSTANCE.GetLedgerByObjectId(memberEntity.LedgerId.Hex())
+		if ledgerEntity.IsEmpty() {
+			continue
+		}
+		ledgerList = append(ledgerList, model.LedgerView{
+			Id:   ledgerEntity.Id.Hex(),
+			Name: ledgerEntity.Name,
+			Role: memberEntity.Role,
+		})
+	}
+	return ledgerList
+}
+
+// RoleService returns the user's role in the ledger. Blank stands for the user's personal ledger,
+// whose id is their own and which they always own; a ledger they have not joined is reported as not found.
+func RoleService(userPlainId, ledgerPlainId string) (string, error) {
+	if ledgerPlainId == "" || ledgerPlainId == userPlainId {
+		return model.LedgerRoleOwner, nil
+	}
+	if err := validation.ValidateID(ledgerPlainId); err != nil {
+		return "", err
+	}
+
+	memberEntity := ledger_mapper.INSTANCE.GetLedgerMember(ledgerPlainId, userPlainId)
+	if memberEntity.IsEmpty() {
+		return "", errors.NewNotFoundError("ledger does not exist")
+	}
+	return memberEntity.Role, nil
+}
+
+// CanEdit tells whether the role may add, change and delete records of the ledger
+func CanEdit(role string) bool {
+	return role == model.LedgerRoleOwner || role == model.LedgerRoleEditor
+}
+
+// requireSharedOwner turns away anyone but an owner of a shared ledger
+func requireSharedOwner(userPlainId, ledgerPlainId string) error {
+	if ledgerPlainId == userPlainId {
+		return validation.NewValidationError("ledger", "a personal ledger cannot be shared, create a ledger to share instead")
+	}
+	role, err := RoleService(userPlainId, ledgerPlainId)
+	if err != nil {
+		return err
+	}
+	if role != model.LedgerRoleOwner {
+		return errors.NewForbiddenError("only an owner of the ledger can manage its members")
+	}
+	return nil
+}
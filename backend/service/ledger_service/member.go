@@ -0,0 +1,95 @@
+package ledger_service
+
+import (
+	"strings"
+
+	"github.com/macar-x/cashlens/errors"
+	"github.com/macar-x/cashlens/mapper/ledger_mapper"
+	"github.com/macar-x/cashlens/mapper/user_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// ListMembersService returns the members of a ledger the user belongs to, oldest first
+func ListMembersService(userPlainId, ledgerPlainId string) ([]model.LedgerMemberView, error) {
+	if _, err := RoleService(userPlainId, ledgerPlainId); err != nil {
+		return nil, err
+	}
+	if ledgerPlainId == userPlainId {
+		return []model.LedgerMemberView{convertMember2View(userPlainId, model.LedgerRoleOwner)}, nil
+	}
+
+	memberList := []model.LedgerMemberView{}
+	for _, memberEntity := range ledger_mapper.INSTANCE.GetLedgerMembersByLedgerId(ledgerPlainId) {
+		memberList = append(memberList, convertMember2View(memberEntity.UserId.Hex(), memberEntity.Role))
+	}
+	return memberList, nil
+}
+
+// UpdateMemberService changes a member's role, only an owner may do so and the last owner stays one
+func UpdateMemberService(userPlainId, ledgerPlainId, memberUserPlainId string, roleDTO model.LedgerRoleDTO) (model.LedgerMemberView, error) {
+	role := strings.ToUpper(strings.TrimSpace(roleDTO.Role))
+	if err := validation.ValidateLedgerRole(role); err != nil {
+		return model.LedgerMemberView{}, err
+	}
+	if err := requireSharedOwner(userPlainId, ledgerPlainId); err != nil {
+		return model.LedgerMemberView{}, err
+	}
+
+	memberEntity := ledger_mapper.INSTANCE.GetLedgerMember(ledgerPlainId, memberUserPlainId)
+	if memberEntity.IsEmpty() {
+		return model.LedgerMemberView{}, errors.NewNotFoundError("member does not exist")
+	}
+	if memberEntity.Role == model.LedgerRoleOwner && role != model.LedgerRoleOwner && isLastOwner(ledgerPlainId) {
+		return model.LedgerMemberView{}, validation.NewValidationError("role", "a ledger needs at least one owner")
+	}
+
+	updatedEntity := ledger_mapper.INSTANCE.UpdateLedgerMemberRole(memberEntity.Id.Hex(), role)
+	if updatedEntity.IsEmpty() {
+		return model.LedgerMemberView{}, errors.NewDatabaseError("member update failed", nil)
+	}
+	return convertMember2View(memberUserPlainId, updatedEntity.Role), nil
+}
+
+// RemoveMemberService takes a member out of the ledger. Owners remove anyone, other members only themselves,
+// and the last owner cannot leave.
+func RemoveMemberService(userPlainId, ledgerPlainId, memberUserPlainId string) error {
+	if memberUserPlainId != userPlainId {
+		if err := requireSharedOwner(userPlainId, ledgerPlainId); err != nil {
+			return err
+		}
+	} else if ledgerPlainId == userPlainId {
+		return validation.NewValidationError("ledger", "a personal ledger cannot be left")
+	}
+
+	memberEntity := ledger_mapper.INSTANCE.GetLedgerMember(ledgerPlainId, memberUserPlainId)
+	if memberEntity.IsEmpty() {
+		return errors.NewNotFoundError("member does not exist")
+	}
+	if memberEntity.Role == model.LedgerRoleOwner && isLastOwner(ledgerPlainId) {
+		return validation.NewValidationError("member", "a ledger needs at least one owner")
+	}
+
+	if ledger_mapper.INSTANCE.DeleteLedgerMemberByObjectId(memberEntity.Id.Hex()).IsEmpty() {
+		return errors.NewDatabaseError("member delete failed", nil)
+	}
+	return nil
+}
+
+func isLastOwner(ledgerPlainId string) bool {
+	ownerCount := 0
+	for _, memberEntity := range ledger_mapper.INSTANCE.GetLedgerMembersByLedgerId(ledgerPlainId) {
+		if memberEntity.Role == model.LedgerRoleOwner {
+			ownerCount++
+		}
+	}
+	return ownerCount <= 1
+}
+
+func convertMember2View(userPlainId, role string) model.LedgerMemberView {
+	return model.LedgerMemberView{
+		UserId:   userPlainId,
+		Username: user_mapper.INSTANCE.GetUserByObjectId(userPlainId).Username,
+		Role:     role,
+	}
+}
@@ -0,0 +1,166 @@
+package ledger_service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/macar-x/cashlens/errors"
+	"github.com/macar-x/cashlens/mapper/ledger_mapper"
+	"github.com/macar-x/cashlens/mapper/user_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+)
+
+// resetMappers gives each test empty storage and returns the ids of alice and bob
+func resetMappers(t *testing.T) (string, string) {
+	t.Helper()
+	user_mapper.INSTANCE = user_mapper.NewUserMemoryMapper()
+	ledger_mapper.INSTANCE = ledger_mapper.NewLedgerMemoryMapper()
+	return user_mapper.INSTANCE.InsertUserByEntity(model.UserEntity{Username: "alice"}),
+		user_mapper.INSTANCE.InsertUserByEntity(model.UserEntity{Username: "bob"})
+}
+
+func TestCreateAndList(t *testing.T) {
+	alice, bob := resetMappers(t)
+	if _, err := CreateService(alice, model.LedgerDTO{Name: "  "}); err == nil {
+		t.Errorf("CreateService() with a blank name expected error, got nil")
+	}
+
+	household, err := CreateService(alice, model.LedgerDTO{Name: " Household "})
+	if err != nil || household.Name != "Household" || household.Role != model.LedgerRoleOwner {
+		t.Fatalf("CreateService() = %+v, %v", household, err)
+	}
+
+	ledgerList := ListService(alice)
+	if len(ledgerList) != 2 || !ledgerList[0].IsPersonal || ledgerList[0].Id != alice || ledgerList[1].Id != household.Id {
+		t.Errorf("ListService(alice) = %+v, want personal then Household", ledgerList)
+	}
+	if ledgerList := ListService(bob); len(ledgerList) != 1 {
+		t.Errorf("ListService(bob) = %+v, want only the personal ledger", ledgerList)
+	}
+}
+
+func TestRoleService(t *testing.T) {
+	alice, bob := resetMappers(t)
+	household, _ := CreateService(alice, model.LedgerDTO{Name: "Household"})
+
+	tests := []struct {
+		name          string
+		userPlainId   string
+		ledgerPlainId string
+		want          string
+		wantNotFound  bool
+		wantErr       bool
+	}{
+		{"blank is personal", bob, "", model.LedgerRoleOwner, false, false},
+		{"own id is personal", bob, bob, model.LedgerRoleOwner, false, false},
+		{"member", alice, household.Id, model.LedgerRoleOwner, false, false},
+		{"not a member", bob, household.Id, "", true, true},
+		{"someone else's personal ledger", bob, alice, "", true, true},
+		{"bad id", bob, "not-an-id", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RoleService(tt.userPlainId, tt.ledgerPlainId)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("RoleService() = %q, %v, want %q", got, err, tt.want)
+			}
+			if tt.wantNotFound && !errors.IsNotFound(err) {
+				t.Errorf("RoleService() error = %v, want not found", err)
+			}
+		})
+	}
+}
+
+func TestInvitationFlow(t *testing.T) {
+	alice, bob := resetMappers(t)
+	household, _ := CreateService(alice, model.LedgerDTO{Name: "Household"})
+
+	if _, err := InviteService(alice, alice, model.LedgerRoleDTO{}); err == nil {
+		t.Errorf("InviteService() to a personal ledger expected error, got nil")
+	}
+	if _, err := InviteService(bob, household.Id, model.LedgerRoleDTO{}); !errors.IsNotFound(err) {
+		t.Errorf("InviteService() by a non-member error = %v, want not found", err)
+	}
+	if _, err := InviteService(alice, household.Id, model.LedgerRoleDTO{Role: "admin"}); err == nil {
+		t.Errorf("InviteService() with an unknown role expected error, got nil")
+	}
+
+	invitation, err := InviteService(alice, household.Id, model.LedgerRoleDTO{})
+	if err != nil || invitation.Role != model.LedgerRoleViewer || !strings.HasPrefix(invitation.Token, invitationTokenPrefix) {
+		t.Fatalf("InviteService() = %+v, %v", invitation, err)
+	}
+	if stored := ledger_mapper.INSTANCE.GetLedgerInvitationByHash(hashInvitationToken(invitation.Token)); stored.TokenHash == invitation.Token {
+		t.Errorf("InviteService() stored the token itself, want its hash")
+	}
+
+	if _, err := AcceptInvitationService(alice, model.LedgerInvitationAcceptDTO{Token: invitation.Token}); err == nil {
+		t.Errorf("AcceptInvitationService() by a member expected error, got nil")
+	}
+	joined, err := AcceptInvitationService(bob, model.LedgerInvitationAcceptDTO{Token: invitation.Token})
+	if err != nil || joined.Id != household.Id || joined.Name != "Household" || joined.Role != model.LedgerRoleViewer {
+		t.Fatalf("AcceptInvitationService() = %+v, %v", joined, err)
+	}
+	if role, _ := RoleService(bob, household.Id); role != model.LedgerRoleViewer {
+		t.Errorf("RoleService(bob) = %q after accepting, want VIEWER", role)
+	}
+	if _, err := AcceptInvitationService(bob, model.LedgerInvitationAcceptDTO{Token: invitation.Token}); err == nil {
+		t.Errorf("AcceptInvitationService() with a used token expected error, got nil")
+	}
+	if _, err := InviteService(bob, household.Id, model.LedgerRoleDTO{}); !errors.IsForbidden(err) {
+		t.Errorf("InviteService() by a viewer error = %v, want forbidden", err)
+	}
+}
+
+func TestAcceptExpiredInvitation(t *testing.T) {
+	alice, bob := resetMappers(t)
+	household, _ := CreateService(alice, model.LedgerDTO{Name: "Household"})
+	ledger_mapper.INSTANCE.InsertLedgerInvitationByEntity(model.LedgerInvitationEntity{
+		LedgerId:   util.Convert2ObjectId(household.Id),
+		Role:       model.LedgerRoleEditor,
+		TokenHash:  hashInvitationToken("clinv_expired"),
+		InvitedBy:  util.Convert2ObjectId(alice),
+		ExpireTime: time.Now().Add(-time.Minute),
+	})
+
+	if _, err := AcceptInvitationService(bob, model.LedgerInvitationAcceptDTO{Token: "clinv_expired"}); err == nil {
+		t.Errorf("AcceptInvitationService() with an expired token expected error, got nil")
+	}
+	if !ledger_mapper.INSTANCE.GetLedgerInvitationByHash(hashInvitationToken("clinv_expired")).IsEmpty() {
+		t.Errorf("AcceptInvitationService() kept an expired invitation, want it deleted")
+	}
+}
+
+func TestManageMembers(t *testing.T) {
+	alice, bob := resetMappers(t)
+	household, _ := CreateService(alice, model.LedgerDTO{Name: "Household"})
+	invitation, _ := InviteService(alice, household.Id, model.LedgerRoleDTO{Role: "editor"})
+	_, _ = AcceptInvitationService(bob, model.LedgerInvitationAcceptDTO{Token: invitation.Token})
+
+	memberList, err := ListMembersService(bob, household.Id)
+	if err != nil || len(memberList) != 2 || memberList[0].Username != "alice" || memberList[1].Role != model.LedgerRoleEditor {
+		t.Fatalf("ListMembersService() = %+v, %v", memberList, err)
+	}
+
+	if _, err := UpdateMemberService(bob, household.Id, alice, model.LedgerRoleDTO{Role: "VIEWER"}); !errors.IsForbidden(err) {
+		t.Errorf("UpdateMemberService() by an editor error = %v, want forbidden", err)
+	}
+	if _, err := UpdateMemberService(alice, household.Id, alice, model.LedgerRoleDTO{Role: "VIEWER"}); err == nil {
+		t.Errorf("UpdateMemberService() demoting the last owner expected error, got nil")
+	}
+	if err := RemoveMemberService(alice, household.Id, alice); err == nil {
+		t.Errorf("RemoveMemberService() removing the last owner expected error, got nil")
+	}
+
+	promoted, err := UpdateMemberService(alice, household.Id, bob, model.LedgerRoleDTO{Role: "owner"})
+	if err != nil || promoted.Role != model.LedgerRoleOwner || promoted.Username != "bob" {
+		t.Fatalf("UpdateMemberService() = %+v, %v", promoted, err)
+	}
+	if err := RemoveMemberService(alice, household.Id, alice); err != nil {
+		t.Errorf("RemoveMemberService() leaving with another owner error = %v", err)
+	}
+	if _, err := RoleService(alice, household.Id); !errors.IsNotFound(err) {
+		t.Errorf("RoleService(alice) after leaving error = %v, want not found", err)
+	}
+}
@@ -3,50 +3,1000 @@ package manage_service
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/api_token_mapper"
+	"github.com/macar-x/cashlens/mapper/budget_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/mapper/ledger_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/user_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// BackupVersion is the format version written into every backup file, files of the same major version
+// up to this one can be restored
+const BackupVersion = "1.12.0"
+
+// backupPageSize is the number of records fetched from the mapper per round trip
+const backupPageSize = 500
+
 // BackupData represents the structure of backup data
 type BackupData struct {
-	Version    string                   `json:"version"`
-	Timestamp  string                   `json:"timestamp"`
-	CashFlows  []map[string]interface{} `json:"cash_flows"`
-	Categories []map[string]interface{} `json:"categories"`
+	Version        string                `json:"version"`
+	Timestamp      string                `json:"timestamp"`
+	DatabaseType   string                `json:"database_type"`
+	CashFlows      []BackupCashFlow      `json:"cash_flows"`
+	Categories     []BackupCategory      `json:"categories"`
+	Accounts       []BackupAccount       `json:"accounts"`
+	ExchangeRates  []BackupExchangeRate  `json:"exchange_rates"`
+	RecurringRules []BackupRecurringRule `json:"recurring_rules"`
+	Budgets        []BackupBudget        `json:"budgets"`
+	Goals          []BackupGoal          `json:"goals"`
+	Payees         []BackupPayee         `json:"payees"`
+	CategoryRules  []BackupCategoryRule  `json:"category_rules"`
+	// Access data, missing from backups older than 1.12.0
+	Users             []BackupUser             `json:"users"`
+	ApiTokens         []BackupApiToken         `json:"api_tokens"`
+	Ledgers           []BackupLedger           `json:"ledgers"`
+	LedgerMembers     []BackupLedgerMember     `json:"ledger_members"`
+	LedgerInvitations []BackupLedgerInvitation `json:"ledger_invitations"`
+}
+
+// BackupCashFlow is the serialized form of a cash_flow record
+type BackupCashFlow struct {
+	Id          string          `json:"id"`
+	OwnerId     string          `json:"owner_id"`
+	CategoryId  string          `json:"category_id"`
+	AccountId   string          `json:"account_id"`
+	LinkedId    string          `json:"linked_id"`
+	PayeeId     string          `json:"payee_id"`
+	BelongsDate string          `json:"belongs_date"`
+	FlowType    string          `json:"flow_type"`
+	Amount      decimal.Decimal `json:"amount"`
+	Currency    string          `json:"currency"`
+	Description string          `json:"description"`
+	Tags        []string        `json:"tags"`
+	Splits      []BackupSplit   `json:"splits"`
+	Remark      string          `json:"remark"`
+	CreatedBy   string          `json:"created_by"`
+	ModifiedBy  string          `json:"modified_by"`
+	CreateTime  time.Time       `json:"create_time"`
+	ModifyTime  time.Time       `json:"modify_time"`
+}
+
+// BackupSplit is the serialized form of one line of a split cash_flow
+type BackupSplit struct {
+	CategoryId  string          `json:"category_id"`
+	Amount      decimal.Decimal `json:"amount"`
+	Description string          `json:"description"`
+}
+
+// BackupCategory is the serialized form of a category record
+type BackupCategory struct {
+	Id         string    `json:"id"`
+	OwnerId    string    `json:"owner_id"`
+	ParentId   string    `json:"parent_id"`
+	Name       string    `json:"name"`
+	Remark     string    `json:"remark"`
+	CreateTime time.Time `json:"create_time"`
+	ModifyTime time.Time `json:"modify_time"`
+}
+
+// BackupAccount is the serialized form of an account record
+type BackupAccount struct {
+	Id             string          `json:"id"`
+	OwnerId        string          `json:"owner_id"`
+	Name           string          `json:"name"`
+	Type           string          `json:"type"`
+	OpeningBalance decimal.Decimal `json:"opening_balance"`
+	Currency       string          `json:"currency"`
+	Remark         string          `json:"remark"`
+	CreateTime     time.Time       `json:"create_time"`
+	ModifyTime     time.Time       `json:"modify_time"`
+}
+
+// BackupExchangeRate is the serialized form of an exchange rate record
+type BackupExchangeRate struct {
+	Id            string          `json:"id"`
+	OwnerId       string          `json:"owner_id"`
+	FromCurrency  string          `json:"from_currency"`
+	ToCurrency    string          `json:"to_currency"`
+	Rate          decimal.Decimal `json:"rate"`
+	EffectiveDate string          `json:"effective_date"`
+	CreateTime    time.Time       `json:"create_time"`
+	ModifyTime    time.Time       `json:"modify_time"`
+}
+
+// BackupRecurringRule is the serialized form of a recurring rule record,
+// its booking progress is kept so a restored rule does not book occurrences twice
+type BackupRecurringRule struct {
+	Id              string          `json:"id"`
+	OwnerId         string          `json:"owner_id"`
+	Name            string          `json:"name"`
+	FlowType        string          `json:"flow_type"`
+	CategoryId      string          `json:"category_id"`
+	AccountId       string          `json:"account_id"`
+	Amount          decimal.Decimal `json:"amount"`
+	Currency        string          `json:"currency"`
+	Description     string          `json:"description"`
+	Frequency       string          `json:"frequency"`
+	DayOfMonth      int             `json:"day_of_month"`
+	StartDate       string          `json:"start_date"`
+	EndDate         string          `json:"end_date"`
+	OccurrenceLimit int             `json:"occurrence_limit"`
+	OccurrenceCount int             `json:"occurrence_count"`
+	LastRunDate     string          `json:"last_run_date"`
+	CreateTime      time.Time       `json:"create_time"`
+	ModifyTime      time.Time       `json:"modify_time"`
+}
+
+// BackupBudget is the serialized form of a budget record
+type BackupBudget struct {
+	Id         string          `json:"id"`
+	OwnerId    string          `json:"owner_id"`
+	CategoryId string          `json:"category_id"`
+	Period     string          `json:"period"`
+	Limit      decimal.Decimal `json:"limit"`
+	Currency   string          `json:"currency"`
+	Rollover   bool            `json:"rollover"`
+	StartDate  string          `json:"start_date"`
+	CreateTime time.Time       `json:"create_time"`
+	ModifyTime time.Time       `json:"modify_time"`
+}
+
+// BackupGoal is the serialized form of a goal record
+type BackupGoal struct {
+	Id           string          `json:"id"`
+	OwnerId      string          `json:"owner_id"`
+	Name         string          `json:"name"`
+	AccountId    string          `json:"account_id"`
+	TargetAmount decimal.Decimal `json:"target_amount"`
+	Currency     string          `json:"currency"`
+	StartDate    string          `json:"start_date"`
+	Deadline     string          `json:"deadline"`
+	CreateTime   time.Time       `json:"create_time"`
+	ModifyTime   time.Time       `json:"modify_time"`
+}
+
+// BackupPayee is the serialized form of a payee record, its rules keep their order
+type BackupPayee struct {
+	Id         string            `json:"id"`
+	OwnerId    string            `json:"owner_id"`
+	Name       string            `json:"name"`
+	Rules      []BackupPayeeRule `json:"rules"`
+	CreateTime time.Time         `json:"create_time"`
+	ModifyTime time.Time         `json:"modify_time"`
+}
+
+// BackupPayeeRule is the serialized form of one normalization rule of a payee
+type BackupPayeeRule struct {
+	MatchType string `json:"match_type"`
+	Pattern   string `json:"pattern"`
+}
+
+// BackupCategoryRule is the serialized form of a categorization rule record
+type BackupCategoryRule struct {
+	Id         string          `json:"id"`
+	OwnerId    string          `json:"owner_id"`
+	Name       string          `json:"name"`
+	Priority   int             `json:"priority"`
+	FlowType   string          `json:"flow_type"`
+	MatchType  string          `json:"match_type"`
+	Pattern    string          `json:"pattern"`
+	MinAmount  decimal.Decimal `json:"min_amount"`
+	MaxAmount  decimal.Decimal `json:"max_amount"`
+	PayeeId    string          `json:"payee_id"`
+	AccountId  string          `json:"account_id"`
+	CategoryId string          `json:"category_id"`
+	Tags       []string        `json:"tags"`
+	CreateTime time.Time       `json:"create_time"`
+	ModifyTime time.Time       `json:"modify_time"`
+}
+
+// BackupUser is the serialized form of a user record, the password is kept as its hash
+type BackupUser struct {
+	Id           string    `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"password_hash"`
+	CreateTime   time.Time `json:"create_time"`
+	ModifyTime   time.Time `json:"modify_time"`
+}
+
+// BackupApiToken is the serialized form of an api token record, the token is kept as its hash
+type BackupApiToken struct {
+	Id         string    `json:"id"`
+	UserId     string    `json:"user_id"`
+	Name       string    `json:"name"`
+	TokenHash  string    `json:"token_hash"`
+	CreateTime time.Time `json:"create_time"`
+	ModifyTime time.Time `json:"modify_time"`
+}
+
+// BackupLedger is the serialized form of a shared ledger record
+type BackupLedger struct {
+	Id         string    `json:"id"`
+	Name       string    `json:"name"`
+	CreateTime time.Time `json:"create_time"`
+	ModifyTime time.Time `json:"modify_time"`
+}
+
+// BackupLedgerMember is the serialized form of a ledger membership record
+type BackupLedgerMember struct {
+	Id         string    `json:"id"`
+	LedgerId   string    `json:"ledger_id"`
+	UserId     string    `json:"user_id"`
+	Role       string    `json:"role"`
+	CreateTime time.Time `json:"create_time"`
+	ModifyTime time.Time `json:"modify_time"`
+}
+
+// BackupLedgerInvitation is the serialized form of a pending ledger invitation, the token is kept as its hash
+type BackupLedgerInvitation struct {
+	Id         string    `json:"id"`
+	LedgerId   string    `json:"ledger_id"`
+	Role       string    `json:"role"`
+	TokenHash  string    `json:"token_hash"`
+	InvitedBy  string    `json:"invited_by"`
+	ExpireTime time.Time `json:"expire_time"`
+	CreateTime time.Time `json:"create_time"`
+	ModifyTime time.Time `json:"modify_time"`
 }
 
 // CreateBackup creates a backup of all database data
-func CreateBackup(filePath string) error {
+func CreateBackup(filePath string) (*BackupData, error) {
 	if filePath == "" {
-		return errors.New("file path cannot be empty")
+		return nil, errors.New("file path cannot be empty")
+	}
+
+	categories, err := collectCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := collectAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	cashFlows, err := collectCashFlows()
+	if err != nil {
+		return nil, err
+	}
+
+	exchangeRates, err := collectExchangeRates()
+	if err != nil {
+		return nil, err
+	}
+
+	recurringRules, err := collectRecurringRules()
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := collectBudgets()
+	if err != nil {
+		return nil, err
+	}
+
+	goals, err := collectGoals()
+	if err != nil {
+		return nil, err
+	}
+
+	payees, err := collectPayees()
+	if err != nil {
+		return nil, err
+	}
+
+	categoryRules, err := collectCategoryRules()
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := collectUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	apiTokens, err := collectApiTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	ledgers, err := collectLedgers()
+	if err != nil {
+		return nil, err
+	}
+
+	ledgerMembers, err := collectLedgerMembers()
+	if err != nil {
+		return nil, err
+	}
+
+	ledgerInvitations, err := collectLedgerInvitations()
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &BackupData{
+		Version:        BackupVersion,
+		Timestamp:      time.Now().Format(time.RFC3339),
+		DatabaseType:   util.GetConfigByKey("db.type"),
+		CashFlows:      cashFlows,
+		Categories:     categories,
+		Accounts:       accounts,
+		ExchangeRates:  exchangeRates,
+		RecurringRules: recurringRules,
+		Budgets:        budgets,
+		Goals:          goals,
+		Payees:         payees,
+		CategoryRules:  categoryRules,
+
+		Users:             users,
+		ApiTokens:         apiTokens,
+		Ledgers:           ledgers,
+		LedgerMembers:     ledgerMembers,
+		LedgerInvitations: ledgerInvitations,
+	}
+
+	if err := writeBackupFile(filePath, backup); err != nil {
+		return nil, err
+	}
+
+	util.Logger.Infow("backup created",
+		"file_path", filePath,
+		"cash_flows", len(backup.CashFlows),
+		"categories", len(backup.Categories),
+		"accounts", len(backup.Accounts),
+		"exchange_rates", len(backup.ExchangeRates),
+		"recurring_rules", len(backup.RecurringRules),
+		"budgets", len(backup.Budgets),
+		"goals", len(backup.Goals),
+		"payees", len(backup.Payees),
+		"category_rules", len(backup.CategoryRules),
+		"users", len(backup.Users),
+		"api_tokens", len(backup.ApiTokens),
+		"ledgers", len(backup.Ledgers),
+		"ledger_members", len(backup.LedgerMembers),
+		"ledger_invitations", len(backup.LedgerInvitations))
+	return backup, nil
+}
+
+func collectCategories() ([]BackupCategory, error) {
+	expectedCount := category_mapper.INSTANCE.CountAllCategories()
+
+	seenIds := make(map[primitive.ObjectID]bool)
+	categories := []BackupCategory{}
+	for offset := 0; ; offset += backupPageSize {
+		page := category_mapper.INSTANCE.GetAllCategories(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			categories = append(categories, convertCategoryEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
+	}
+
+	if int64(len(categories)) != expectedCount {
+		return nil, fmt.Errorf("category count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(categories))
+	}
+	return categories, nil
+}
+
+func collectAccounts() ([]BackupAccount, error) {
+	expectedCount := account_mapper.INSTANCE.CountAllAccounts()
+
+	seenIds := make(map[primitive.ObjectID]bool)
+	accounts := []BackupAccount{}
+	for offset := 0; ; offset += backupPageSize {
+		page := account_mapper.INSTANCE.GetAllAccounts(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			accounts = append(accounts, convertAccountEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
+	}
+
+	if int64(len(accounts)) != expectedCount {
+		return nil, fmt.Errorf("account count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(accounts))
+	}
+	return accounts, nil
+}
+
+func collectCashFlows() ([]BackupCashFlow, error) {
+	expectedCount := cash_flow_mapper.INSTANCE.CountAllCashFlows()
+
+	seenIds := make(map[primitive.ObjectID]bool)
+	cashFlows := []BackupCashFlow{}
+	for offset := 0; ; offset += backupPageSize {
+		page := cash_flow_mapper.INSTANCE.GetAllCashFlows(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			cashFlows = append(cashFlows, convertCashFlowEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
+	}
+
+	if int64(len(cashFlows)) != expectedCount {
+		return nil, fmt.Errorf("cash_flow count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(cashFlows))
+	}
+	return cashFlows, nil
+}
+
+func collectExchangeRates() ([]BackupExchangeRate, error) {
+	expectedCount := exchange_rate_mapper.INSTANCE.CountAllExchangeRates()
+
+	seenIds := make(map[primitive.ObjectID]bool)
+	exchangeRates := []BackupExchangeRate{}
+	for offset := 0; ; offset += backupPageSize {
+		page := exchange_rate_mapper.INSTANCE.GetAllExchangeRates(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			exchangeRates = append(exchangeRates, convertExchangeRateEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
+	}
+
+	if int64(len(exchangeRates)) != expectedCount {
+		return nil, fmt.Errorf("exchange rate count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(exchangeRates))
+	}
+	return exchangeRates, nil
+}
+
+func collectRecurringRules() ([]BackupRecurringRule, error) {
+	expectedCount := recurring_rule_mapper.INSTANCE.CountAllRecurringRules()
+
+	seenIds := make(map[primitive.ObjectID]bool)
+	recurringRules := []BackupRecurringRule{}
+	for offset := 0; ; offset += backupPageSize {
+		page := recurring_rule_mapper.INSTANCE.GetAllRecurringRules(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			recurringRules = append(recurringRules, convertRecurringRuleEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
 	}
 
-	// Note: To properly implement this, we need:
-	// 1. Mapper method to get all cash flows: GetAllCashFlows()
-	// 2. Mapper method to get all categories: GetAllCategories()
-	// 3. Serialize to JSON
-	// 4. Write to file
+	if int64(len(recurringRules)) != expectedCount {
+		return nil, fmt.Errorf("recurring rule count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(recurringRules))
+	}
+	return recurringRules, nil
+}
+
+func collectBudgets() ([]BackupBudget, error) {
+	expectedCount := budget_mapper.INSTANCE.CountAllBudgets()
+
+	seenIds := make(map[primitive.ObjectID]bool)
+	budgets := []BackupBudget{}
+	for offset := 0; ; offset += backupPageSize {
+		page := budget_mapper.INSTANCE.GetAllBudgets(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			budgets = append(budgets, convertBudgetEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
+	}
+
+	if int64(len(budgets)) != expectedCount {
+		return nil, fmt.Errorf("budget count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(budgets))
+	}
+	return budgets, nil
+}
+
+func collectGoals() ([]BackupGoal, error) {
+	expectedCount := goal_mapper.INSTANCE.CountAllGoals()
+
+	seenIds := make(map[primitive.ObjectID]bool)
+	goals := []BackupGoal{}
+	for offset := 0; ; offset += backupPageSize {
+		page := goal_mapper.INSTANCE.GetAllGoals(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			goals = append(goals, convertGoalEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
+	}
+
+	if int64(len(goals)) != expectedCount {
+		return nil, fmt.Errorf("goal count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(goals))
+	}
+	return goals, nil
+}
+
+func collectPayees() ([]BackupPayee, error) {
+	expectedCount := payee_mapper.INSTANCE.CountAllPayees()
+
+	seenIds := make(map[primitive.ObjectID]bool)
+	payees := []BackupPayee{}
+	for offset := 0; ; offset += backupPageSize {
+		page := payee_mapper.INSTANCE.GetAllPayees(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			payees = append(payees, convertPayeeEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
+	}
+
+	if int64(len(payees)) != expectedCount {
+		return nil, fmt.Errorf("payee count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(payees))
+	}
+	return payees, nil
+}
+
+func collectCategoryRules() ([]BackupCategoryRule, error) {
+	expectedCount := category_rule_mapper.INSTANCE.CountAllCategoryRules()
+
+	seenIds := make(map[primitive.ObjectID]bool)
+	categoryRules := []BackupCategoryRule{}
+	for offset := 0; ; offset += backupPageSize {
+		page := category_rule_mapper.INSTANCE.GetAllCategoryRules(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			categoryRules = append(categoryRules, convertCategoryRuleEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
+	}
+
+	if int64(len(categoryRules)) != expectedCount {
+		return nil, fmt.Errorf("category rule count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(categoryRules))
+	}
+	return categoryRules, nil
+}
+
+func collectUsers() ([]BackupUser, error) {
+	expectedCount := user_mapper.INSTANCE.CountAllUsers()
+
+	seenIds := make(map[primitive.ObjectID]bool)
+	users := []BackupUser{}
+	for offset := 0; ; offset += backupPageSize {
+		page := user_mapper.INSTANCE.GetAllUsers(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			users = append(users, convertUserEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
+	}
+
+	if int64(len(users)) != expectedCount {
+		return nil, fmt.Errorf("user count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(users))
+	}
+	return users, nil
+}
+
+func collectApiTokens() ([]BackupApiToken, error) {
+	expectedCount := api_token_mapper.INSTANCE.CountAllApiTokens()
+
+	seenIds := make(map[primitive.ObjectID]bool)
+	apiTokens := []BackupApiToken{}
+	for offset := 0; ; offset += backupPageSize {
+		page := api_token_mapper.INSTANCE.GetAllApiTokens(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			apiTokens = append(apiTokens, convertApiTokenEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
+	}
+
+	if int64(len(apiTokens)) != expectedCount {
+		return nil, fmt.Errorf("api token count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(apiTokens))
+	}
+	return apiTokens, nil
+}
+
+func collectLedgers() ([]BackupLedger, error) {
+	expectedCount := ledger_mapper.INSTANCE.CountAllLedgers()
+
+	seenIds := make(map[primitive.ObjectID]bool)
+	ledgers := []BackupLedger{}
+	for offset := 0; ; offset += backupPageSize {
+		page := ledger_mapper.INSTANCE.GetAllLedgers(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			ledgers = append(ledgers, convertLedgerEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
+	}
+
+	if int64(len(ledgers)) != expectedCount {
+		return nil, fmt.Errorf("ledger count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(ledgers))
+	}
+	return ledgers, nil
+}
+
+func collectLedgerMembers() ([]BackupLedgerMember, error) {
+	expectedCount := ledger_mapper.INSTANCE.CountAllLedgerMembers()
+
+	seenIds := make(map[primitive.ObjectID]bool)
+	ledgerMembers := []BackupLedgerMember{}
+	for offset := 0; ; offset += backupPageSize {
+		page := ledger_mapper.INSTANCE.GetAllLedgerMembers(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			ledgerMembers = append(ledgerMembers, convertLedgerMemberEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
+	}
+
+	if int64(len(ledgerMembers)) != expectedCount {
+		return nil, fmt.Errorf("ledger member count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(ledgerMembers))
+	}
+	return ledgerMembers, nil
+}
+
+func collectLedgerInvitations() ([]BackupLedgerInvitation, error) {
+	expectedCount := ledger_mapper.INSTANCE.CountAllLedgerInvitations()
 
-	// For now, create empty backup structure
-	backup := BackupData{
-		Version:    "1.0.0",
-		Timestamp:  "",
-		CashFlows:  []map[string]interface{}{},
-		Categories: []map[string]interface{}{},
+	seenIds := make(map[primitive.ObjectID]bool)
+	ledgerInvitations := []BackupLedgerInvitation{}
+	for offset := 0; ; offset += backupPageSize {
+		page := ledger_mapper.INSTANCE.GetAllLedgerInvitations(backupPageSize, offset)
+		for _, entity := range page {
+			if seenIds[entity.Id] {
+				continue
+			}
+			seenIds[entity.Id] = true
+			ledgerInvitations = append(ledgerInvitations, convertLedgerInvitationEntity2Backup(entity))
+		}
+		if len(page) < backupPageSize {
+			break
+		}
 	}
 
-	// Write to file
-	file, err := os.Create(filePath)
+	if int64(len(ledgerInvitations)) != expectedCount {
+		return nil, fmt.Errorf("ledger invitation count changed during backup (expected %d, read %d), please retry",
+			expectedCount, len(ledgerInvitations))
+	}
+	return ledgerInvitations, nil
+}
+
+// writeBackupFile writes into a temporary file next to the target and renames it,
+// so an interrupted backup never leaves a truncated file behind.
+func writeBackupFile(filePath string, backup *BackupData) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".tmp-*")
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	tempFilePath := tempFile.Name()
+	defer os.Remove(tempFilePath)
 
-	encoder := json.NewEncoder(file)
+	encoder := json.NewEncoder(tempFile)
 	encoder.SetIndent("", "  ")
 	if err := encoder.Encode(backup); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Sync(); err != nil {
+		tempFile.Close()
 		return err
 	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFilePath, filePath)
+}
+
+func convertCashFlowEntity2Backup(entity model.CashFlowEntity) BackupCashFlow {
+	return BackupCashFlow{
+		Id:          entity.Id.Hex(),
+		OwnerId:     convertObjectId2Plain(entity.OwnerId),
+		CategoryId:  convertObjectId2Plain(entity.CategoryId),
+		AccountId:   convertObjectId2Plain(entity.AccountId),
+		LinkedId:    convertObjectId2Plain(entity.LinkedId),
+		PayeeId:     convertObjectId2Plain(entity.PayeeId),
+		BelongsDate: util.FormatDateToStringWithDash(entity.BelongsDate),
+		FlowType:    entity.FlowType,
+		Amount:      entity.Amount,
+		Currency:    entity.Currency,
+		Description: entity.Description,
+		Tags:        entity.Tags,
+		Splits:      convertSplits2Backup(entity.Splits),
+		Remark:      entity.Remark,
+		CreatedBy:   convertObjectId2Plain(entity.CreatedBy),
+		ModifiedBy:  convertObjectId2Plain(entity.ModifiedBy),
+		CreateTime:  entity.CreateTime,
+		ModifyTime:  entity.ModifyTime,
+	}
+}
+
+func convertSplits2Backup(splits []model.CashFlowSplit) []BackupSplit {
+	if len(splits) == 0 {
+		return nil
+	}
+	backupSplits := make([]BackupSplit, 0, len(splits))
+	for _, split := range splits {
+		backupSplits = append(backupSplits, BackupSplit{
+			CategoryId:  convertObjectId2Plain(split.CategoryId),
+			Amount:      split.Amount,
+			Description: split.Description,
+		})
+	}
+	return backupSplits
+}
+
+func convertCategoryEntity2Backup(entity model.CategoryEntity) BackupCategory {
+	return BackupCategory{
+		Id:         entity.Id.Hex(),
+		OwnerId:    convertObjectId2Plain(entity.OwnerId),
+		ParentId:   convertObjectId2Plain(entity.ParentId),
+		Name:       entity.Name,
+		Remark:     entity.Remark,
+		CreateTime: entity.CreateTime,
+		ModifyTime: entity.ModifyTime,
+	}
+}
 
-	// TODO: Implement actual data querying when mapper methods are available
-	return errors.New("backup functionality requires mapper enhancement - need GetAll methods")
+func convertAccountEntity2Backup(entity model.AccountEntity) BackupAccount {
+	return BackupAccount{
+		Id:             entity.Id.Hex(),
+		OwnerId:        convertObjectId2Plain(entity.OwnerId),
+		Name:           entity.Name,
+		Type:           entity.Type,
+		OpeningBalance: entity.OpeningBalance,
+		Currency:       entity.Currency,
+		Remark:         entity.Remark,
+		CreateTime:     entity.CreateTime,
+		ModifyTime:     entity.ModifyTime,
+	}
+}
+
+func convertExchangeRateEntity2Backup(entity model.ExchangeRateEntity) BackupExchangeRate {
+	return BackupExchangeRate{
+		Id:            entity.Id.Hex(),
+		OwnerId:       convertObjectId2Plain(entity.OwnerId),
+		FromCurrency:  entity.FromCurrency,
+		ToCurrency:    entity.ToCurrency,
+		Rate:          entity.Rate,
+		EffectiveDate: util.FormatDateToStringWithDash(entity.EffectiveDate),
+		CreateTime:    entity.CreateTime,
+		ModifyTime:    entity.ModifyTime,
+	}
+}
+
+func convertRecurringRuleEntity2Backup(entity model.RecurringRuleEntity) BackupRecurringRule {
+	return BackupRecurringRule{
+		Id:              entity.Id.Hex(),
+		OwnerId:         convertObjectId2Plain(entity.OwnerId),
+		Name:            entity.Name,
+		FlowType:        entity.FlowType,
+		CategoryId:      convertObjectId2Plain(entity.CategoryId),
+		AccountId:       convertObjectId2Plain(entity.AccountId),
+		Amount:          entity.Amount,
+		Currency:        entity.Currency,
+		Description:     entity.Description,
+		Frequency:       entity.Frequency,
+		DayOfMonth:      entity.DayOfMonth,
+		StartDate:       util.FormatDateToStringWithDash(entity.StartDate),
+		EndDate:         convertOptionalDate2Plain(entity.EndDate),
+		OccurrenceLimit: entity.OccurrenceLimit,
+		OccurrenceCount: entity.OccurrenceCount,
+		LastRunDate:     convertOptionalDate2Plain(entity.LastRunDate),
+		CreateTime:      entity.CreateTime,
+		ModifyTime:      entity.ModifyTime,
+	}
+}
+
+func convertBudgetEntity2Backup(entity model.BudgetEntity) BackupBudget {
+	return BackupBudget{
+		Id:         entity.Id.Hex(),
+		OwnerId:    convertObjectId2Plain(entity.OwnerId),
+		CategoryId: convertObjectId2Plain(entity.CategoryId),
+		Period:     entity.Period,
+		Limit:      entity.Limit,
+		Currency:   entity.Currency,
+		Rollover:   entity.Rollover,
+		StartDate:  util.FormatDateToStringWithDash(entity.StartDate),
+		CreateTime: entity.CreateTime,
+		ModifyTime: entity.ModifyTime,
+	}
+}
+
+func convertGoalEntity2Backup(entity model.GoalEntity) BackupGoal {
+	return BackupGoal{
+		Id:           entity.Id.Hex(),
+		OwnerId:      convertObjectId2Plain(entity.OwnerId),
+		Name:         entity.Name,
+		AccountId:    convertObjectId2Plain(entity.AccountId),
+		TargetAmount: entity.TargetAmount,
+		Currency:     entity.Currency,
+		StartDate:    util.FormatDateToStringWithDash(entity.StartDate),
+		Deadline:     util.FormatDateToStringWithDash(entity.Deadline),
+		CreateTime:   entity.CreateTime,
+		ModifyTime:   entity.ModifyTime,
+	}
+}
+
+func convertPayeeEntity2Backup(entity model.PayeeEntity) BackupPayee {
+	var rules []BackupPayeeRule
+	for _, rule := range entity.Rules {
+		rules = append(rules, BackupPayeeRule{MatchType: rule.MatchType, Pattern: rule.Pattern})
+	}
+	return BackupPayee{
+		Id:         entity.Id.Hex(),
+		OwnerId:    convertObjectId2Plain(entity.OwnerId),
+		Name:       entity.Name,
+		Rules:      rules,
+		CreateTime: entity.CreateTime,
+		ModifyTime: entity.ModifyTime,
+	}
+}
+
+func convertCategoryRuleEntity2Backup(entity model.CategoryRuleEntity) BackupCategoryRule {
+	return BackupCategoryRule{
+		Id:         entity.Id.Hex(),
+		OwnerId:    convertObjectId2Plain(entity.OwnerId),
+		Name:       entity.Name,
+		Priority:   entity.Priority,
+		FlowType:   entity.FlowType,
+		MatchType:  entity.MatchType,
+		Pattern:    entity.Pattern,
+		MinAmount:  entity.MinAmount,
+		MaxAmount:  entity.MaxAmount,
+		PayeeId:    convertObjectId2Plain(entity.PayeeId),
+		AccountId:  convertObjectId2Plain(entity.AccountId),
+		CategoryId: convertObjectId2Plain(entity.CategoryId),
+		Tags:       entity.Tags,
+		CreateTime: entity.CreateTime,
+		ModifyTime: entity.ModifyTime,
+	}
+}
+
+func convertUserEntity2Backup(entity model.UserEntity) BackupUser {
+	return BackupUser{
+		Id:           entity.Id.Hex(),
+		Username:     entity.Username,
+		PasswordHash: entity.PasswordHash,
+		CreateTime:   entity.CreateTime,
+		ModifyTime:   entity.ModifyTime,
+	}
+}
+
+func convertApiTokenEntity2Backup(entity model.ApiTokenEntity) BackupApiToken {
+	return BackupApiToken{
+		Id:         entity.Id.Hex(),
+		UserId:     convertObjectId2Plain(entity.UserId),
+		Name:       entity.Name,
+		TokenHash:  entity.TokenHash,
+		CreateTime: entity.CreateTime,
+		ModifyTime: entity.ModifyTime,
+	}
+}
+
+func convertLedgerEntity2Backup(entity model.LedgerEntity) BackupLedger {
+	return BackupLedger{
+		Id:         entity.Id.Hex(),
+		Name:       entity.Name,
+		CreateTime: entity.CreateTime,
+		ModifyTime: entity.ModifyTime,
+	}
+}
+
+func convertLedgerMemberEntity2Backup(entity model.LedgerMemberEntity) BackupLedgerMember {
+	return BackupLedgerMember{
+		Id:         entity.Id.Hex(),
+		LedgerId:   convertObjectId2Plain(entity.LedgerId),
+		UserId:     convertObjectId2Plain(entity.UserId),
+		Role:       entity.Role,
+		CreateTime: entity.CreateTime,
+		ModifyTime: entity.ModifyTime,
+	}
+}
+
+func convertLedgerInvitationEntity2Backup(entity model.LedgerInvitationEntity) BackupLedgerInvitation {
+	return BackupLedgerInvitation{
+		Id:         entity.Id.Hex(),
+		LedgerId:   convertObjectId2Plain(entity.LedgerId),
+		Role:       entity.Role,
+		TokenHash:  entity.TokenHash,
+		InvitedBy:  convertObjectId2Plain(entity.InvitedBy),
+		ExpireTime: entity.ExpireTime,
+		CreateTime: entity.CreateTime,
+		ModifyTime: entity.ModifyTime,
+	}
+}
+
+// convertOptionalDate2Plain keeps unset dates empty instead of writing 0001-01-01
+func convertOptionalDate2Plain(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return util.FormatDateToStringWithDash(date)
+}
+
+// convertObjectId2Plain keeps empty references empty instead of writing the all-zero id
+func convertObjectId2Plain(objectId primitive.ObjectID) string {
+	if objectId == primitive.NilObjectID {
+		return ""
+	}
+	return objectId.Hex()
 }
@@ -0,0 +1,74 @@
+package manage_service
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWriteBackupFile(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "backup.json")
+
+	backup := &BackupData{
+		Version:    BackupVersion,
+		Timestamp:  time.Now().Format(time.RFC3339),
+		CashFlows:  []BackupCashFlow{{Id: primitive.NewObjectID().Hex(), Amount: decimal.NewFromFloat(12.34)}},
+		Categories: []BackupCategory{{Id: primitive.NewObjectID().Hex(), Name: "Food"}},
+	}
+	if err := writeBackupFile(filePath, backup); err != nil {
+		t.Fatalf("writeBackupFile() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("read backup failed: %v", err)
+	}
+	var parsed BackupData
+	if err := json.Unmarshal(content, &parsed); err != nil {
+		t.Fatalf("backup is not valid json: %v", err)
+	}
+	if parsed.Version != BackupVersion || len(parsed.CashFlows) != 1 || len(parsed.Categories) != 1 {
+		t.Errorf("unexpected backup content: %+v", parsed)
+	}
+
+	// No temporary files should be left next to the backup
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the backup file in %s, got %d entries", dir, len(entries))
+	}
+}
+
+func TestConvertEntity2Backup(t *testing.T) {
+	parent := model.CategoryEntity{Id: primitive.NewObjectID(), Name: "Food"}
+	child := model.CategoryEntity{Id: primitive.NewObjectID(), ParentId: parent.Id, Name: "Lunch", Remark: "daily"}
+
+	if got := convertCategoryEntity2Backup(parent); got.ParentId != "" {
+		t.Errorf("Expected empty parent id for root category, got %q", got.ParentId)
+	}
+	got := convertCategoryEntity2Backup(child)
+	if got.ParentId != parent.Id.Hex() || got.Remark != "daily" {
+		t.Errorf("unexpected category backup: %+v", got)
+	}
+
+	cashFlow := model.CashFlowEntity{
+		Id:          primitive.NewObjectID(),
+		CategoryId:  child.Id,
+		BelongsDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		FlowType:    model.FlowTypeOutcome,
+		Amount:      decimal.NewFromFloat(45.5),
+		Remark:      "note",
+		CreatedBy:   primitive.NewObjectID(),
+	}
+	gotCashFlow := convertCashFlowEntity2Backup(cashFlow)
+	if gotCashFlow.BelongsDate != "2024-01-15" || gotCashFlow.CategoryId != child.Id.Hex() || gotCashFlow.Remark != "note" ||
+		gotCashFlow.CreatedBy != cashFlow.CreatedBy.Hex() || gotCashFlow.ModifiedBy != "" {
+		t.Errorf("unexpected cash_flow backup: %+v", gotCashFlow)
+	}
+}
@@ -0,0 +1,37 @@
+package manage_service
+
+import (
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/api_token_mapper"
+	"github.com/macar-x/cashlens/mapper/budget_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/mapper/ledger_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/user_mapper"
+	"github.com/macar-x/cashlens/util"
+)
+
+// StartDemoMode switches to fresh in-memory storage and seeds it with demo data no user owns,
+// the first user to register takes it over. Nothing written afterwards reaches the configured database.
+func StartDemoMode() error {
+	util.SetConfigByKey("db.type", "memory")
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	account_mapper.INSTANCE = account_mapper.NewAccountMemoryMapper()
+	exchange_rate_mapper.INSTANCE = exchange_rate_mapper.NewExchangeRateMemoryMapper()
+	recurring_rule_mapper.INSTANCE = recurring_rule_mapper.NewRecurringRuleMemoryMapper()
+	budget_mapper.INSTANCE = budget_mapper.NewBudgetMemoryMapper()
+	goal_mapper.INSTANCE = goal_mapper.NewGoalMemoryMapper()
+	payee_mapper.INSTANCE = payee_mapper.NewPayeeMemoryMapper()
+	category_rule_mapper.INSTANCE = category_rule_mapper.NewCategoryRuleMemoryMapper()
+	user_mapper.INSTANCE = user_mapper.NewUserMemoryMapper()
+	api_token_mapper.INSTANCE = api_token_mapper.NewApiTokenMemoryMapper()
+	ledger_mapper.INSTANCE = ledger_mapper.NewLedgerMemoryMapper()
+
+	return InitializeDemoData("")
+}
@@ -8,16 +8,23 @@ import (
 
 	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
 	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
 	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
 	"github.com/xuri/excelize/v2"
 )
 
 var (
 	defaultSheetName = "report"
-	defaultRowTitle  = []string{"Id", "CategoryId", "CategoryName", "BelongsDate", "FlowType", "Amount", "Description"}
+	defaultRowTitle  = []string{"Id", "CategoryId", "CategoryName", "BelongsDate", "FlowType", "Amount", "Description", "Currency", "PayeeName"}
+	// legacyRowTitleCount is how many titles a sheet exported before payees has, the later ones are optional on import
+	legacyRowTitleCount = 8
 )
 
-func ExportService(fromDateInString, toDateInString, filePath string) error {
+// ExportService writes the owner's cash_flows between the two dates into an Excel file, one sheet per month.
+// With a baseCurrency an extra column holds each amount converted at the rate of its BelongsDate.
+func ExportService(ownerPlainId, fromDateInString, toDateInString, filePath, baseCurrency string) error {
 	if filePath == "" {
 		filePath = "./export.xlsx"
 	}
@@ -26,9 +33,17 @@ func ExportService(fromDateInString, toDateInString, filePath string) error {
 	if err := isExportRequiredFiledSatisfied(fromDate, toDate, filePath); err != nil {
 		return err
 	}
+	if baseCurrency != "" {
+		baseCurrency = exchange_rate_service.NormalizeCurrency(baseCurrency)
+		if err := validation.ValidateCurrency(baseCurrency); err != nil {
+			return err
+		}
+	}
 
 	file := createExcelFile()
-	exportData(file, fromDateInString, toDateInString)
+	if err := exportData(file, ownerPlainId, fromDateInString, toDateInString, baseCurrency); err != nil {
+		return err
+	}
 	saveExcelFile(file, filePath)
 	return nil
 }
@@ -74,7 +89,7 @@ func saveExcelFile(file *excelize.File, filePath string) {
 	}
 }
 
-func exportData(file *excelize.File, fromDate, toDate string) {
+func exportData(file *excelize.File, ownerPlainId, fromDate, toDate, baseCurrency string) error {
 	cashFlowRowIndex := 1
 
 	queryDateCurrent := util.FormatDateFromStringWithoutDash(fromDate)
@@ -84,7 +99,7 @@ func exportData(file *excelize.File, fromDate, toDate string) {
 	currentYearAndMonth := "nil"
 
 	for queryDateEnded.After(queryDateCurrent) {
-		cashFlowArray := cash_flow_mapper.INSTANCE.GetCashFlowsByBelongsDate(queryDateCurrent)
+		cashFlowArray := cash_flow_mapper.INSTANCE.GetCashFlowsByBelongsDate(ownerPlainId, queryDateCurrent)
 		if len(cashFlowArray) == 0 {
 			util.Logger.Debugf("%s's flow is empty.\n", util.FormatDateToStringWithoutDash(queryDateCurrent))
 			queryDateCurrent = queryDateCurrent.AddDate(0, 0, 1)
@@ -110,24 +125,53 @@ func exportData(file *excelize.File, fromDate, toDate string) {
 			writeExcelRow(file, currentYearAndMonth, "E1", defaultRowTitle[4])
 			writeExcelRow(file, currentYearAndMonth, "F1", defaultRowTitle[5])
 			writeExcelRow(file, currentYearAndMonth, "G1", defaultRowTitle[6])
+			writeExcelRow(file, currentYearAndMonth, "H1", defaultRowTitle[7])
+			writeExcelRow(file, currentYearAndMonth, "I1", defaultRowTitle[8])
+			if baseCurrency != "" {
+				writeExcelRow(file, currentYearAndMonth, "J1", "Amount"+baseCurrency)
+			}
 		}
 
 		for _, cashFlow := range cashFlowArray {
-			cashFlowRowIndex++
-			cashFlowIndexInString := strconv.Itoa(cashFlowRowIndex)
-			// refer to hardcode defaultRowTitle, bad idea.
-			writeExcelRow(file, currentYearAndMonth, "A"+cashFlowIndexInString, cashFlow.Id.Hex())
-			writeExcelRow(file, currentYearAndMonth, "B"+cashFlowIndexInString, cashFlow.CategoryId.Hex())
-			writeExcelRow(file, currentYearAndMonth, "C"+cashFlowIndexInString,
-				category_mapper.INSTANCE.GetCategoryByObjectId(cashFlow.CategoryId.Hex()).Name)
-			writeExcelRow(file, currentYearAndMonth, "D"+cashFlowIndexInString, queryDateCurrentInString)
-			writeExcelRow(file, currentYearAndMonth, "E"+cashFlowIndexInString, cashFlow.FlowType)
-			writeExcelRow(file, currentYearAndMonth, "F"+cashFlowIndexInString, cashFlow.Amount)
-			writeExcelRow(file, currentYearAndMonth, "G"+cashFlowIndexInString, cashFlow.Description)
+			payeeName := ""
+			if !cashFlow.PayeeId.IsZero() {
+				payeeName = payee_mapper.INSTANCE.GetPayeeByObjectId(cashFlow.PayeeId.Hex()).Name
+			}
+			// A split cash flow takes one row per line under the same Id, so the sheet adds up by category
+			for _, line := range cashFlow.CategoryLines() {
+				cashFlowRowIndex++
+				cashFlowIndexInString := strconv.Itoa(cashFlowRowIndex)
+				lineDescription := line.Description
+				if lineDescription == "" {
+					lineDescription = cashFlow.Description
+				}
+				// refer to hardcode defaultRowTitle, bad idea.
+				writeExcelRow(file, currentYearAndMonth, "A"+cashFlowIndexInString, cashFlow.Id.Hex())
+				writeExcelRow(file, currentYearAndMonth, "B"+cashFlowIndexInString, line.CategoryId.Hex())
+				writeExcelRow(file, currentYearAndMonth, "C"+cashFlowIndexInString,
+					category_mapper.INSTANCE.GetCategoryByObjectId(line.CategoryId.Hex()).Name)
+				writeExcelRow(file, currentYearAndMonth, "D"+cashFlowIndexInString, queryDateCurrentInString)
+				writeExcelRow(file, currentYearAndMonth, "E"+cashFlowIndexInString, cashFlow.FlowType)
+				// Excel keeps numbers as doubles, the cell is only converted at this last step
+				writeExcelRow(file, currentYearAndMonth, "F"+cashFlowIndexInString, line.Amount.InexactFloat64())
+				writeExcelRow(file, currentYearAndMonth, "G"+cashFlowIndexInString, lineDescription)
+				writeExcelRow(file, currentYearAndMonth, "H"+cashFlowIndexInString,
+					exchange_rate_service.NormalizeCurrency(cashFlow.Currency))
+				writeExcelRow(file, currentYearAndMonth, "I"+cashFlowIndexInString, payeeName)
+				if baseCurrency != "" {
+					convertedAmount, err := exchange_rate_service.ConvertAmount(
+						ownerPlainId, line.Amount, cashFlow.Currency, baseCurrency, cashFlow.BelongsDate)
+					if err != nil {
+						return err
+					}
+					writeExcelRow(file, currentYearAndMonth, "J"+cashFlowIndexInString, convertedAmount.InexactFloat64())
+				}
+			}
 		}
 
 		queryDateCurrent = queryDateCurrent.AddDate(0, 0, 1)
 	}
+	return nil
 }
 
 func writeExcelRow(file *excelize.File, sheetName, cellPosition string, cellValue interface{}) {
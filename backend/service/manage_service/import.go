@@ -4,12 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
 	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
 	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/service/payee_service"
 	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"github.com/shopspring/decimal"
 	"github.com/xuri/excelize/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -22,7 +28,14 @@ var (
 	importSucceedRowNumberList []int
 )
 
-func ImportService(filePath string) error {
+// ImportService imports the cash_flows of an Excel file into the ledger, recorded as created by the user. A row without category gets the one of the first
+// matching categorization rule; failing that, when minConfidence is above 0, the suggested category
+// is applied if its confidence reaches minConfidence.
+func ImportService(ownerPlainId, userPlainId, filePath string, minConfidence float64) error {
+	if minConfidence < 0 || minConfidence > 1 {
+		return validation.NewValidationError("min_confidence", "must be between 0 and 1")
+	}
+
 	// 打開並讀取目標文件
 	file := readExcelFile(filePath)
 	if file == nil {
@@ -35,6 +48,15 @@ func ImportService(filePath string) error {
 		}
 	}()
 
+	// 沒有收付對象的行按規則從描述識別，沒有類別的行按分類規則識別，規則只讀取一次
+	payeeMatcher := payee_service.NewPayeeMatcher(ownerPlainId)
+	categoryRuleMatcher := cash_flow_service.NewCategoryRuleMatcher(ownerPlainId)
+	// 選填參數: 按歷史數據建議類別，只在設定了最低置信度時訓練
+	var categorySuggester cash_flow_service.CategorySuggester
+	if minConfidence > 0 {
+		categorySuggester = cash_flow_service.NewCategorySuggester(ownerPlainId)
+	}
+
 	// 獲取工作表列表，遍歷讀取數據
 	sheetNameList := file.GetSheetList()
 	for _, currentSheetName := range sheetNameList {
@@ -53,10 +75,10 @@ func ImportService(filePath string) error {
 			util.Logger.Errorw("read sheet rows failed", "error", err)
 		}
 		util.Logger.Infof("processing sheet %s", currentSheetName)
-		cashFlowMapByDate := readSheetData(rows)
+		cashFlowMapByDate := readSheetData(ownerPlainId, rows, payeeMatcher, categoryRuleMatcher, categorySuggester, minConfidence)
 		// fixme: 保存 cashFlowList 時，要考慮事務細粒度，考慮增加 batchInsert()
 		for date, cashFlowMapByColumnList := range cashFlowMapByDate {
-			saveIntoDB(cashFlowMapByColumnList)
+			saveIntoDB(ownerPlainId, userPlainId, cashFlowMapByColumnList)
 			util.Logger.Debugf("%s of %s's flows imported", util.FormatDateToStringWithoutDash(date), currentSheetName)
 		}
 		util.Logger.Infow("sheet has been imported",
@@ -79,7 +101,9 @@ func readExcelFile(fileName string) *excelize.File {
 /**
  * 讀取工作表的數據，以 date 爲 key 整理 cashFlows
  */
-func readSheetData(sheetRowCursor *excelize.Rows) map[time.Time][]map[string]string {
+func readSheetData(ownerPlainId string, sheetRowCursor *excelize.Rows, payeeMatcher payee_service.PayeeMatcher,
+	categoryRuleMatcher cash_flow_service.CategoryRuleMatcher, categorySuggester cash_flow_service.CategorySuggester,
+	minConfidence float64) map[time.Time][]map[string]string {
 	cashFlowMapByDate := make(map[time.Time][]map[string]string)
 
 	// 第一行爲標題行，校驗格式是否正確
@@ -89,7 +113,9 @@ func readSheetData(sheetRowCursor *excelize.Rows) map[time.Time][]map[string]str
 	if err != nil {
 		util.Logger.Error(err.Error())
 	}
-	if !isSheetTitleVerified(rowColumnList) {
+	titleCount := countSheetTitle(rowColumnList)
+	if titleCount < legacyRowTitleCount {
+		util.Logger.Warn("sheet title un-expected, parse failed.")
 		return cashFlowMapByDate
 	}
 
@@ -106,12 +132,25 @@ func readSheetData(sheetRowCursor *excelize.Rows) map[time.Time][]map[string]str
 		// 依序組裝每一行數據，形成 title-value Map
 		cashFlowMapByColumn := map[string]string{}
 		for index, colCell := range rowColumnList {
+			// columns past the known titles, such as a converted amount, are not imported
+			if index >= titleCount {
+				break
+			}
 			cashFlowMapByColumn[defaultRowTitle[index]] = colCell
 		}
 		cashFlowMapByColumn[sheetRowNumberLabel] = strconv.Itoa(currentRowNumber)
-		// check category info and get the correct id
-		newCategoryId := handleCategoryInfo(
+		if payeeId := handlePayeeInfo(ownerPlainId, cashFlowMapByColumn, payeeMatcher); payeeId != "" {
+			cashFlowMapByColumn["PayeeId"] = payeeId
+		}
+		// check category info and get the correct id, a row without category is left to the categorization rules
+		newCategoryId := handleCategoryInfo(ownerPlainId,
 			cashFlowMapByColumn["CategoryId"], cashFlowMapByColumn["CategoryName"])
+		if newCategoryId == "" {
+			newCategoryId = handleCategoryRule(cashFlowMapByColumn, categoryRuleMatcher)
+		}
+		if newCategoryId == "" && minConfidence > 0 {
+			newCategoryId = handleCategorySuggestion(cashFlowMapByColumn, categorySuggester, minConfidence)
+		}
 		if newCategoryId == "" {
 			fmt.Println("failed: row " + strconv.Itoa(currentRowNumber) + ": category not satisfied")
 			importFailedRowNumberList = append(importFailedRowNumberList, currentRowNumber)
@@ -137,14 +176,14 @@ func readSheetData(sheetRowCursor *excelize.Rows) map[time.Time][]map[string]str
 	return cashFlowMapByDate
 }
 
-func isSheetTitleVerified(titleColumnList []string) bool {
+// countSheetTitle counts the leading titles matching defaultRowTitle, the columns after them are not imported
+func countSheetTitle(titleColumnList []string) int {
 	for index, colCell := range titleColumnList {
-		if colCell != defaultRowTitle[index] {
-			util.Logger.Warn("sheet title un-expected, parse failed.")
-			return false
+		if index >= len(defaultRowTitle) || colCell != defaultRowTitle[index] {
+			return index
 		}
 	}
-	return true
+	return len(titleColumnList)
 }
 
 func isRequiredFieldSatisfied(currentRowNumber int, columnCellMap map[string]string) bool {
@@ -158,11 +197,11 @@ func isRequiredFieldSatisfied(currentRowNumber int, columnCellMap map[string]str
 	return true
 }
 
-func handleCategoryInfo(categoryId, categoryName string) string {
-	// use category id to fetch first
+func handleCategoryInfo(ownerPlainId, categoryId, categoryName string) string {
+	// use category id to fetch first, only the owner's categories count
 	if categoryId != "" {
 		categoryEntity := category_mapper.INSTANCE.GetCategoryByObjectId(categoryId)
-		if !categoryEntity.IsEmpty() {
+		if !categoryEntity.IsEmpty() && categoryEntity.IsOwnedBy(ownerPlainId) {
 			return categoryEntity.Id.Hex()
 		}
 		util.Logger.Warnw("category not existed", "category_id", categoryId)
@@ -174,7 +213,7 @@ func handleCategoryInfo(categoryId, categoryName string) string {
 	}
 
 	// use category name to fetch correct id
-	categoryEntity := category_mapper.INSTANCE.GetCategoryByName(categoryName)
+	categoryEntity := category_mapper.INSTANCE.GetCategoryByName(ownerPlainId, categoryName)
 	if !categoryEntity.IsEmpty() {
 		return categoryEntity.Id.Hex()
 	}
@@ -182,32 +221,176 @@ func handleCategoryInfo(categoryId, categoryName string) string {
 
 	// create new category for this flow
 	plainId := category_mapper.INSTANCE.InsertCategoryByEntity(model.CategoryEntity{
-		Name:   categoryName,
-		Remark: "create by import",
+		OwnerId: util.ConvertOwner2ObjectId(ownerPlainId),
+		Name:    categoryName,
+		Remark:  "create by import",
 	})
 	return plainId
 }
 
-func saveIntoDB(cashFlowMapByColumnList []map[string]string) {
-	for _, cashFlowMapByColumn := range cashFlowMapByColumnList {
-		cashFlowEntity := model.CashFlowEntity{}.Build(cashFlowMapByColumn)
+// handleCategoryRule gives the category of the first categorization rule matching a row without category,
+// the rule's tags are added to the row. Rows carry no account, so rules on an account never match them.
+func handleCategoryRule(cashFlowMapByColumn map[string]string, categoryRuleMatcher cash_flow_service.CategoryRuleMatcher) string {
+	amount, err := decimal.NewFromString(cashFlowMapByColumn["Amount"])
+	if err != nil {
+		return ""
+	}
+
+	payeeId := primitive.NilObjectID
+	if payeePlainId := cashFlowMapByColumn["PayeeId"]; payeePlainId != "" {
+		payeeId = util.Convert2ObjectId(payeePlainId)
+	}
+
+	ruleEntity := categoryRuleMatcher.Match(cashFlowMapByColumn["FlowType"], cashFlowMapByColumn["Description"],
+		amount, payeeId, primitive.NilObjectID)
+	if ruleEntity.IsEmpty() {
+		return ""
+	}
+	if len(ruleEntity.Tags) != 0 {
+		cashFlowMapByColumn["Tags"] = strings.Join(ruleEntity.Tags, ",")
+	}
+	return ruleEntity.CategoryId.Hex()
+}
+
+// handleCategorySuggestion gives the most likely category of a row without category,
+// only when its confidence reaches minConfidence
+func handleCategorySuggestion(cashFlowMapByColumn map[string]string, categorySuggester cash_flow_service.CategorySuggester,
+	minConfidence float64) string {
+	amount, err := decimal.NewFromString(cashFlowMapByColumn["Amount"])
+	if err != nil {
+		return ""
+	}
+
+	suggestionList := categorySuggester.Suggest(cashFlowMapByColumn["FlowType"], cashFlowMapByColumn["Description"], amount)
+	if len(suggestionList) == 0 || suggestionList[0].Confidence < minConfidence {
+		return ""
+	}
+	util.Logger.Debugw("category suggested",
+		sheetRowNumberLabel, cashFlowMapByColumn[sheetRowNumberLabel],
+		"category_id", suggestionList[0].CategoryId.Hex(),
+		"confidence", suggestionList[0].Confidence)
+	return suggestionList[0].CategoryId.Hex()
+}
+
+// handlePayeeInfo gives the id of the owner's payee of the row, a payee unknown by name is created without rules.
+// A row without payee name is matched against the payee rules by its description.
+func handlePayeeInfo(ownerPlainId string, cashFlowMapByColumn map[string]string, payeeMatcher payee_service.PayeeMatcher) string {
+	if cashFlowMapByColumn["FlowType"] == model.FlowTypeTransfer {
+		return ""
+	}
+
+	payeeName := cashFlowMapByColumn["PayeeName"]
+	if payeeName == "" {
+		if payeeId := payeeMatcher.Match(cashFlowMapByColumn["Description"]); !payeeId.IsZero() {
+			return payeeId.Hex()
+		}
+		return ""
+	}
+
+	payeeEntity := payee_mapper.INSTANCE.GetPayeeByName(ownerPlainId, payeeName)
+	if !payeeEntity.IsEmpty() {
+		return payeeEntity.Id.Hex()
+	}
+	if err := validation.ValidatePayeeName(payeeName); err != nil {
+		util.Logger.Warnw("payee name invalid, row imported without payee", "payee_name", payeeName, "error", err)
+		return ""
+	}
+	util.Logger.Warnw("payee not existed", "payee_name", payeeName)
+
+	// create new payee for this flow
+	return payee_mapper.INSTANCE.InsertPayeeByEntity(model.PayeeEntity{
+		OwnerId: util.ConvertOwner2ObjectId(ownerPlainId),
+		Name:    payeeName,
+	})
+}
+
+func saveIntoDB(ownerPlainId, userPlainId string, cashFlowMapByColumnList []map[string]string) {
+	for _, cashFlowRowList := range groupSplitRows(cashFlowMapByColumnList) {
+		cashFlowEntity := model.CashFlowEntity{}.Build(cashFlowRowList[0])
+		cashFlowEntity.OwnerId = util.ConvertOwner2ObjectId(ownerPlainId)
+		cashFlowEntity.CreatedBy = util.ConvertOwner2ObjectId(userPlainId)
+		cashFlowEntity.ModifiedBy = cashFlowEntity.CreatedBy
+		if len(cashFlowRowList) > 1 {
+			var err error
+			cashFlowEntity, err = mergeSplitRows(cashFlowEntity, cashFlowRowList)
+			if err != nil {
+				for _, cashFlowMapByColumn := range cashFlowRowList {
+					fmt.Println("failed: row " + cashFlowMapByColumn[sheetRowNumberLabel] + ": " + err.Error())
+					importFailedRowNumberList = append(importFailedRowNumberList,
+						util.ToInteger(cashFlowMapByColumn[sheetRowNumberLabel]))
+				}
+				continue
+			}
+		}
+
 		if cashFlowEntity.Id != primitive.NilObjectID {
 			existedCashFlow := cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(cashFlowEntity.Id.Hex())
 			if !existedCashFlow.IsEmpty() {
-				util.Logger.Warnw("cash_flow existed, ignored import.",
-					sheetRowNumberLabel, cashFlowMapByColumn[sheetRowNumberLabel],
-					"objectId", cashFlowEntity.Id.Hex())
-				fmt.Println("ignored: row " + cashFlowMapByColumn[sheetRowNumberLabel] + ": cash_flow existed")
-				importIgnoredRowNumberList = append(importIgnoredRowNumberList,
-					util.ToInteger(cashFlowMapByColumn[sheetRowNumberLabel]))
+				for _, cashFlowMapByColumn := range cashFlowRowList {
+					util.Logger.Warnw("cash_flow existed, ignored import.",
+						sheetRowNumberLabel, cashFlowMapByColumn[sheetRowNumberLabel],
+						"objectId", cashFlowEntity.Id.Hex())
+					fmt.Println("ignored: row " + cashFlowMapByColumn[sheetRowNumberLabel] + ": cash_flow existed")
+					importIgnoredRowNumberList = append(importIgnoredRowNumberList,
+						util.ToInteger(cashFlowMapByColumn[sheetRowNumberLabel]))
+				}
 				continue
 			}
 		}
 		newPlainId := cash_flow_mapper.INSTANCE.InsertCashFlowByEntity(cashFlowEntity)
 		cashFlowEntity.Id = util.Convert2ObjectId(newPlainId)
 		util.Logger.Debug("cash_flow inserted: " + cashFlowEntity.ToString())
-		fmt.Println("succeed: row " + cashFlowMapByColumn[sheetRowNumberLabel] + ": cash_flow saved")
-		importSucceedRowNumberList = append(importSucceedRowNumberList,
-			util.ToInteger(cashFlowMapByColumn[sheetRowNumberLabel]))
+		for _, cashFlowMapByColumn := range cashFlowRowList {
+			fmt.Println("succeed: row " + cashFlowMapByColumn[sheetRowNumberLabel] + ": cash_flow saved")
+			importSucceedRowNumberList = append(importSucceedRowNumberList,
+				util.ToInteger(cashFlowMapByColumn[sheetRowNumberLabel]))
+		}
+	}
+}
+
+// groupSplitRows keeps the rows in order and gathers the ones sharing an Id,
+// export writes such rows for the lines of a split cash flow. Rows without an Id stay on their own.
+func groupSplitRows(cashFlowMapByColumnList []map[string]string) [][]map[string]string {
+	var cashFlowRowGroupList [][]map[string]string
+	groupIndexMap := make(map[string]int)
+	for _, cashFlowMapByColumn := range cashFlowMapByColumnList {
+		plainId := cashFlowMapByColumn["Id"]
+		if groupIndex, found := groupIndexMap[plainId]; found {
+			cashFlowRowGroupList[groupIndex] = append(cashFlowRowGroupList[groupIndex], cashFlowMapByColumn)
+			continue
+		}
+		if plainId != "" {
+			groupIndexMap[plainId] = len(cashFlowRowGroupList)
+		}
+		cashFlowRowGroupList = append(cashFlowRowGroupList, []map[string]string{cashFlowMapByColumn})
+	}
+	return cashFlowRowGroupList
+}
+
+// mergeSplitRows turns the line rows back into one split cash flow, the amount is their sum
+// and the category and description come from the first row.
+func mergeSplitRows(cashFlowEntity model.CashFlowEntity, cashFlowRowList []map[string]string) (model.CashFlowEntity, error) {
+	if cashFlowEntity.FlowType == model.FlowTypeTransfer {
+		return model.CashFlowEntity{}, errors.New("a transfer can not be split")
+	}
+
+	cashFlowEntity.Amount = decimal.Zero
+	cashFlowEntity.Splits = make([]model.CashFlowSplit, 0, len(cashFlowRowList))
+	lineAmounts := make([]decimal.Decimal, 0, len(cashFlowRowList))
+	for _, cashFlowMapByColumn := range cashFlowRowList {
+		lineEntity := model.CashFlowEntity{}.Build(cashFlowMapByColumn)
+		lineAmount := lineEntity.Amount.Round(2)
+		cashFlowEntity.Amount = cashFlowEntity.Amount.Add(lineAmount)
+		lineAmounts = append(lineAmounts, lineAmount)
+		cashFlowEntity.Splits = append(cashFlowEntity.Splits, model.CashFlowSplit{
+			CategoryId:  lineEntity.CategoryId,
+			Amount:      lineAmount,
+			Description: lineEntity.Description,
+		})
+	}
+
+	if err := validation.ValidateSplitLines(cashFlowEntity.Amount, lineAmounts); err != nil {
+		return model.CashFlowEntity{}, err
 	}
+	return cashFlowEntity, nil
 }
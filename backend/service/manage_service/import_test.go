@@ -0,0 +1,216 @@
+package manage_service
+
+import (
+	"testing"
+
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/service/payee_service"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCountSheetTitle(t *testing.T) {
+	legacyTitle := append([]string{}, defaultRowTitle[:legacyRowTitleCount]...)
+	tests := []struct {
+		name  string
+		title []string
+		want  int
+	}{
+		{"Current title", defaultRowTitle, len(defaultRowTitle)},
+		{"Without payee", legacyTitle, legacyRowTitleCount},
+		{"Converted amount without payee", append(legacyTitle, "AmountUSD"), legacyRowTitleCount},
+		{"Converted amount", append(append([]string{}, defaultRowTitle...), "AmountUSD"), len(defaultRowTitle)},
+		{"Unknown title", []string{"Id", "Category"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countSheetTitle(tt.title); got != tt.want {
+				t.Errorf("countSheetTitle() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlePayeeInfo(t *testing.T) {
+	payee_mapper.INSTANCE = payee_mapper.NewPayeeMemoryMapper()
+	starbucksId := payee_mapper.INSTANCE.InsertPayeeByEntity(model.PayeeEntity{Name: "Starbucks",
+		Rules: []model.PayeeRule{{MatchType: model.PayeeRuleContains, Pattern: "starbucks"}}})
+	payeeMatcher := payee_service.NewPayeeMatcher("")
+
+	if got := handlePayeeInfo("", map[string]string{"Description": "STARBUCKS #1234"}, payeeMatcher); got != starbucksId {
+		t.Errorf("handlePayeeInfo() by rule = %q, want %q", got, starbucksId)
+	}
+	if got := handlePayeeInfo("", map[string]string{"Description": "parking"}, payeeMatcher); got != "" {
+		t.Errorf("handlePayeeInfo() without match = %q, want none", got)
+	}
+	if got := handlePayeeInfo("", map[string]string{"PayeeName": "Starbucks", "Description": "beans"}, payeeMatcher); got != starbucksId {
+		t.Errorf("handlePayeeInfo() by name = %q, want %q", got, starbucksId)
+	}
+
+	created := handlePayeeInfo("", map[string]string{"PayeeName": "Corner Cafe"}, payeeMatcher)
+	if created == "" || payee_mapper.INSTANCE.GetPayeeByName("", "Corner Cafe").Id.Hex() != created {
+		t.Errorf("handlePayeeInfo() of an unknown name = %q, want the payee created", created)
+	}
+
+	// Another owner's payee of the same name is not used
+	bobPayeeId := payee_mapper.INSTANCE.InsertPayeeByEntity(model.PayeeEntity{OwnerId: primitive.NewObjectID(), Name: "Bakery"})
+	if got := handlePayeeInfo("", map[string]string{"PayeeName": "Bakery"}, payeeMatcher); got == "" || got == bobPayeeId {
+		t.Errorf("handlePayeeInfo() of another owner's payee = %q, want a payee of its own", got)
+	}
+}
+
+func TestHandleCategoryRule(t *testing.T) {
+	category_rule_mapper.INSTANCE = category_rule_mapper.NewCategoryRuleMemoryMapper()
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	coffeeId := primitive.NewObjectID()
+	groceriesId := primitive.NewObjectID()
+	starbucksId := primitive.NewObjectID()
+	if _, err := category_mapper.INSTANCE.BulkInsertCategories([]model.CategoryEntity{
+		{Id: coffeeId, Name: "Coffee"},
+		{Id: groceriesId, Name: "Groceries"},
+	}); err != nil {
+		t.Fatalf("BulkInsertCategories() error = %v", err)
+	}
+	if _, err := category_rule_mapper.INSTANCE.BulkInsertCategoryRules([]model.CategoryRuleEntity{
+		{Name: "Starbucks", PayeeId: starbucksId, CategoryId: coffeeId, Tags: []string{"caffeine", "daily"}},
+		{Name: "Card", Priority: 5, AccountId: primitive.NewObjectID(), CategoryId: groceriesId},
+	}); err != nil {
+		t.Fatalf("BulkInsertCategoryRules() error = %v", err)
+	}
+	categoryRuleMatcher := cash_flow_service.NewCategoryRuleMatcher("")
+
+	row := map[string]string{"FlowType": "OUTCOME", "Amount": "4.50", "PayeeId": starbucksId.Hex()}
+	if got := handleCategoryRule(row, categoryRuleMatcher); got != coffeeId.Hex() || row["Tags"] != "caffeine,daily" {
+		t.Errorf("handleCategoryRule() = %q with tags %q, want %q with the rule's tags", got, row["Tags"], coffeeId.Hex())
+	}
+	if got := handleCategoryRule(map[string]string{"FlowType": "OUTCOME", "Amount": "4.50"}, categoryRuleMatcher); got != "" {
+		t.Errorf("handleCategoryRule() without payee = %q, want none since rows carry no account", got)
+	}
+	if got := handleCategoryRule(map[string]string{"FlowType": "OUTCOME", "Amount": "n/a",
+		"PayeeId": starbucksId.Hex()}, categoryRuleMatcher); got != "" {
+		t.Errorf("handleCategoryRule() with an invalid amount = %q, want none", got)
+	}
+	if tags := (model.CashFlowEntity{}).Build(row).Tags; len(tags) != 2 || tags[1] != "daily" {
+		t.Errorf("Build() tags = %v, want the rule's tags", tags)
+	}
+}
+
+func TestHandleCategorySuggestion(t *testing.T) {
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	coffeeId := primitive.NewObjectID()
+	foodId := primitive.NewObjectID()
+	if _, err := category_mapper.INSTANCE.BulkInsertCategories([]model.CategoryEntity{
+		{Id: coffeeId, Name: "Coffee"},
+		{Id: foodId, Name: "Food"},
+	}); err != nil {
+		t.Fatalf("BulkInsertCategories() error = %v", err)
+	}
+	if _, err := cash_flow_mapper.INSTANCE.BulkInsertCashFlows([]model.CashFlowEntity{
+		{FlowType: model.FlowTypeOutcome, CategoryId: coffeeId, Amount: decimal.NewFromInt(5), Description: "STARBUCKS #1"},
+		{FlowType: model.FlowTypeOutcome, CategoryId: coffeeId, Amount: decimal.NewFromInt(4), Description: "Starbucks latte"},
+		{FlowType: model.FlowTypeOutcome, CategoryId: foodId, Amount: decimal.NewFromInt(40), Description: "ALDI market"},
+		{FlowType: model.FlowTypeOutcome, CategoryId: coffeeId, Amount: decimal.NewFromInt(6), Description: "market coffee"},
+	}); err != nil {
+		t.Fatalf("BulkInsertCashFlows() error = %v", err)
+	}
+	categorySuggester := cash_flow_service.NewCategorySuggester("")
+
+	row := map[string]string{"FlowType": "OUTCOME", "Amount": "4.50", "Description": "STARBUCKS #2"}
+	if got := handleCategorySuggestion(row, categorySuggester, 0.8); got != coffeeId.Hex() {
+		t.Errorf("handleCategorySuggestion() = %q, want %q", got, coffeeId.Hex())
+	}
+	// Both categories were seen at the market, the suggestion is not confident enough
+	row = map[string]string{"FlowType": "OUTCOME", "Amount": "20", "Description": "market"}
+	if got := handleCategorySuggestion(row, categorySuggester, 0.8); got != "" {
+		t.Errorf("handleCategorySuggestion() = %q, want none below the confidence", got)
+	}
+	row = map[string]string{"FlowType": "INCOME", "Amount": "4.50", "Description": "STARBUCKS #2"}
+	if got := handleCategorySuggestion(row, categorySuggester, 0.1); got != "" {
+		t.Errorf("handleCategorySuggestion() of an income = %q, want none learned from outcomes", got)
+	}
+	if err := ImportService("", "", "missing.xlsx", 1.5); err == nil {
+		t.Errorf("ImportService() with a confidence above 1 expected error, got nil")
+	}
+}
+
+func TestGroupSplitRows(t *testing.T) {
+	rowList := []map[string]string{
+		{"Id": "65f000000000000000000001", sheetRowNumberLabel: "2"},
+		{"Id": "", sheetRowNumberLabel: "3"},
+		{"Id": "65f000000000000000000001", sheetRowNumberLabel: "4"},
+		{"Id": "", sheetRowNumberLabel: "5"},
+		{"Id": "65f000000000000000000002", sheetRowNumberLabel: "6"},
+	}
+
+	groupList := groupSplitRows(rowList)
+	want := [][]string{{"2", "4"}, {"3"}, {"5"}, {"6"}}
+	if len(groupList) != len(want) {
+		t.Fatalf("groupSplitRows() = %d groups, want %d", len(groupList), len(want))
+	}
+	for i, group := range groupList {
+		if len(group) != len(want[i]) {
+			t.Errorf("group %d = %v, want rows %v", i, group, want[i])
+			continue
+		}
+		for j, row := range group {
+			if row[sheetRowNumberLabel] != want[i][j] {
+				t.Errorf("group %d = %v, want rows %v", i, group, want[i])
+			}
+		}
+	}
+}
+
+func TestMergeSplitRows(t *testing.T) {
+	splitRow := func(categoryId, amount, description string) map[string]string {
+		return map[string]string{
+			"Id":          "65f000000000000000000001",
+			"CategoryId":  categoryId,
+			"BelongsDate": "20240501",
+			"FlowType":    model.FlowTypeOutcome,
+			"Amount":      amount,
+			"Description": description,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		rowList    []map[string]string
+		wantErr    bool
+		wantAmount string
+	}{
+		{"Lines add up to the amount", []map[string]string{
+			splitRow("65f0000000000000000000a1", "30.1", "food"),
+			splitRow("65f0000000000000000000a2", "12.4", "detergent"),
+		}, false, "42.5"},
+		{"Negative line", []map[string]string{
+			splitRow("65f0000000000000000000a1", "30.1", "food"),
+			splitRow("65f0000000000000000000a2", "-12.4", "refund"),
+		}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cashFlowEntity := model.CashFlowEntity{}.Build(tt.rowList[0])
+			merged, err := mergeSplitRows(cashFlowEntity, tt.rowList)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mergeSplitRows() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !merged.Amount.Equal(decimal.RequireFromString(tt.wantAmount)) || len(merged.Splits) != len(tt.rowList) {
+				t.Errorf("mergeSplitRows() = %+v, want amount %s over %d lines", merged, tt.wantAmount, len(tt.rowList))
+			}
+			if merged.CategoryId.Hex() != tt.rowList[0]["CategoryId"] || merged.Description != tt.rowList[0]["Description"] {
+				t.Errorf("mergeSplitRows() = %+v, want the first row's category and description", merged)
+			}
+		})
+	}
+}
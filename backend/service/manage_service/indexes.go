@@ -2,6 +2,8 @@ package manage_service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/macar-x/cashlens/util"
@@ -11,6 +13,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// mongoIndexNotFoundCode is the error code MongoDB answers with when dropping an index that does not exist
+const mongoIndexNotFoundCode = 27
+
 // CreateIndexes creates database indexes for performance optimization
 func CreateIndexes() error {
 	dbType := util.GetConfigByKey("db.type")
@@ -20,6 +25,11 @@ func CreateIndexes() error {
 		return createMongoDBIndexes()
 	case "mysql":
 		return createMySQLIndexes()
+	case "sqlite":
+		return createSQLiteIndexes()
+	case "memory":
+		util.Logger.Info("In-memory storage needs no indexes")
+		return nil
 	default:
 		util.Logger.Errorw("unsupported database type", "type", dbType)
 		return nil
@@ -83,26 +93,376 @@ func createMongoDBIndexes() error {
 	}
 	util.Logger.Info("✓ Created index: idx_category_id")
 
-	// Category collection - unique index on name
+	// Index on account_id
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "account_id", Value: 1}},
+		Options: options.Index().SetName("idx_account_id"),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create account_id index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_account_id")
+
+	// Index on payee_id
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "payee_id", Value: 1}},
+		Options: options.Index().SetName("idx_payee_id"),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create payee_id index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_payee_id")
+
+	// Multikey index on tags, one entry per tag of each cash flow
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "tags", Value: 1}},
+		Options: options.Index().SetName("idx_tags"),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create tags index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_tags")
+
+	// Multikey index on the categories of split lines
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "splits.category_id", Value: 1}},
+		Options: options.Index().SetName("idx_splits_category_id"),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create splits.category_id index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_splits_category_id")
+
+	// Text index on description, what a search matches its words against
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "description", Value: "text"}},
+		Options: options.Index().SetName("idx_description_text"),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create description text index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_description_text")
+
+	// Compound index on owner and belongs_date, every ledger query filters by owner first
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "owner_id", Value: 1},
+			{Key: "belongs_date", Value: 1},
+		},
+		Options: options.Index().SetName("idx_owner_id_belongs_date"),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create owner_id belongs_date index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_owner_id_belongs_date")
+
+	// Category collection - unique index on owner and name, names were unique across owners before
 	database.CloseMongoDbConnection()
 	database.OpenMongoDbConnection(database.CategoryTableName)
 	defer database.CloseMongoDbConnection()
 
 	categoryCollection := database.GetMongoDbCollection()
+	err = dropMongoDBIndexIfExists(ctx, categoryCollection, "idx_category_name_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop category name index", "error", err)
+		return err
+	}
 	_, err = categoryCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
-		Keys:    bson.D{{Key: "name", Value: 1}},
-		Options: options.Index().SetName("idx_category_name_unique").SetUnique(true),
+		Keys: bson.D{
+			{Key: "owner_id", Value: 1},
+			{Key: "name", Value: 1},
+		},
+		Options: options.Index().SetName("idx_category_owner_name_unique").SetUnique(true),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create category owner name index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_category_owner_name_unique")
+
+	// Account collection - unique index on owner and name, names were unique across owners before
+	database.CloseMongoDbConnection()
+	database.OpenMongoDbConnection(database.AccountTableName)
+	defer database.CloseMongoDbConnection()
+
+	accountCollection := database.GetMongoDbCollection()
+	err = dropMongoDBIndexIfExists(ctx, accountCollection, "idx_account_name_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop account name index", "error", err)
+		return err
+	}
+	_, err = accountCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "owner_id", Value: 1},
+			{Key: "name", Value: 1},
+		},
+		Options: options.Index().SetName("idx_account_owner_name_unique").SetUnique(true),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create account owner name index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_account_owner_name_unique")
+
+	// Exchange rate collection - one rate per owner, pair and effective date, also serves the rate lookup
+	database.CloseMongoDbConnection()
+	database.OpenMongoDbConnection(database.ExchangeRateTableName)
+	defer database.CloseMongoDbConnection()
+
+	exchangeRateCollection := database.GetMongoDbCollection()
+	err = dropMongoDBIndexIfExists(ctx, exchangeRateCollection, "idx_exchange_rate_pair_date_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop exchange rate pair index", "error", err)
+		return err
+	}
+	_, err = exchangeRateCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "owner_id", Value: 1},
+			{Key: "from_currency", Value: 1},
+			{Key: "to_currency", Value: 1},
+			{Key: "effective_date", Value: -1},
+		},
+		Options: options.Index().SetName("idx_exchange_rate_owner_pair_date_unique").SetUnique(true),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create exchange rate pair index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_exchange_rate_owner_pair_date_unique")
+
+	// Recurring rule collection - unique index on owner and name, names were unique across owners before
+	database.CloseMongoDbConnection()
+	database.OpenMongoDbConnection(database.RecurringRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	recurringRuleCollection := database.GetMongoDbCollection()
+	err = dropMongoDBIndexIfExists(ctx, recurringRuleCollection, "idx_recurring_rule_name_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop recurring rule name index", "error", err)
+		return err
+	}
+	_, err = recurringRuleCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "owner_id", Value: 1},
+			{Key: "name", Value: 1},
+		},
+		Options: options.Index().SetName("idx_recurring_rule_owner_name_unique").SetUnique(true),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create recurring rule owner name index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_recurring_rule_owner_name_unique")
+
+	// Budget collection - unique index on category and period
+	database.CloseMongoDbConnection()
+	database.OpenMongoDbConnection(database.BudgetTableName)
+	defer database.CloseMongoDbConnection()
+
+	budgetCollection := database.GetMongoDbCollection()
+	_, err = budgetCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "category_id", Value: 1},
+			{Key: "period", Value: 1},
+		},
+		Options: options.Index().SetName("idx_budget_category_period_unique").SetUnique(true),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create budget category period index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_budget_category_period_unique")
+
+	_, err = budgetCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "owner_id", Value: 1}},
+		Options: options.Index().SetName("idx_budget_owner_id"),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create budget owner_id index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_budget_owner_id")
+
+	// Goal collection - unique index on owner and name, names were unique across owners before
+	database.CloseMongoDbConnection()
+	database.OpenMongoDbConnection(database.GoalTableName)
+	defer database.CloseMongoDbConnection()
+
+	goalCollection := database.GetMongoDbCollection()
+	err = dropMongoDBIndexIfExists(ctx, goalCollection, "idx_goal_name_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop goal name index", "error", err)
+		return err
+	}
+	_, err = goalCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "owner_id", Value: 1},
+			{Key: "name", Value: 1},
+		},
+		Options: options.Index().SetName("idx_goal_owner_name_unique").SetUnique(true),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create goal owner name index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_goal_owner_name_unique")
+
+	// Payee collection - unique index on owner and name, names were unique across owners before
+	database.CloseMongoDbConnection()
+	database.OpenMongoDbConnection(database.PayeeTableName)
+	defer database.CloseMongoDbConnection()
+
+	payeeCollection := database.GetMongoDbCollection()
+	err = dropMongoDBIndexIfExists(ctx, payeeCollection, "idx_payee_name_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop payee name index", "error", err)
+		return err
+	}
+	_, err = payeeCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "owner_id", Value: 1},
+			{Key: "name", Value: 1},
+		},
+		Options: options.Index().SetName("idx_payee_owner_name_unique").SetUnique(true),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create payee owner name index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_payee_owner_name_unique")
+
+	// Category rule collection - unique index on owner and name, names were unique across owners before
+	database.CloseMongoDbConnection()
+	database.OpenMongoDbConnection(database.CategoryRuleTableName)
+	defer database.CloseMongoDbConnection()
+
+	categoryRuleCollection := database.GetMongoDbCollection()
+	err = dropMongoDBIndexIfExists(ctx, categoryRuleCollection, "idx_category_rule_name_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop category rule name index", "error", err)
+		return err
+	}
+	_, err = categoryRuleCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "owner_id", Value: 1},
+			{Key: "name", Value: 1},
+		},
+		Options: options.Index().SetName("idx_category_rule_owner_name_unique").SetUnique(true),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create category rule owner name index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_category_rule_owner_name_unique")
+
+	// User collection - unique index on username
+	database.CloseMongoDbConnection()
+	database.OpenMongoDbConnection(database.UserTableName)
+	defer database.CloseMongoDbConnection()
+
+	userCollection := database.GetMongoDbCollection()
+	_, err = userCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "username", Value: 1}},
+		Options: options.Index().SetName("idx_user_username_unique").SetUnique(true),
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create user username index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_user_username_unique")
+
+	// API token collection - unique index on the token hash, looked up on every request, and one on the user
+	database.CloseMongoDbConnection()
+	database.OpenMongoDbConnection(database.ApiTokenTableName)
+	defer database.CloseMongoDbConnection()
+
+	apiTokenCollection := database.GetMongoDbCollection()
+	_, err = apiTokenCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetName("idx_api_token_hash_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("idx_api_token_user_id"),
+		},
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create api token indexes", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created indexes: idx_api_token_hash_unique, idx_api_token_user_id")
+
+	// Ledger member collection - a user joins a ledger once, and their ledgers are listed by user
+	database.CloseMongoDbConnection()
+	database.OpenMongoDbConnection(database.LedgerMemberTableName)
+	defer database.CloseMongoDbConnection()
+
+	ledgerMemberCollection := database.GetMongoDbCollection()
+	_, err = ledgerMemberCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "ledger_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("idx_ledger_member_ledger_user_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("idx_ledger_member_user_id"),
+		},
+	})
+	if err != nil {
+		util.Logger.Errorw("failed to create ledger member indexes", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created indexes: idx_ledger_member_ledger_user_unique, idx_ledger_member_user_id")
+
+	// Ledger invitation collection - unique index on the token hash
+	database.CloseMongoDbConnection()
+	database.OpenMongoDbConnection(database.LedgerInvitationTableName)
+	defer database.CloseMongoDbConnection()
+
+	ledgerInvitationCollection := database.GetMongoDbCollection()
+	_, err = ledgerInvitationCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "token_hash", Value: 1}},
+		Options: options.Index().SetName("idx_ledger_invitation_hash_unique").SetUnique(true),
 	})
 	if err != nil {
-		util.Logger.Errorw("failed to create category name index", "error", err)
+		util.Logger.Errorw("failed to create ledger invitation hash index", "error", err)
 		return err
 	}
-	util.Logger.Info("✓ Created unique index: idx_category_name_unique")
+	util.Logger.Info("✓ Created unique index: idx_ledger_invitation_hash_unique")
 
 	util.Logger.Info("All indexes created successfully")
 	return nil
 }
 
+// dropMongoDBIndexIfExists drops an index only when it is there, an index replaced by a newer one may be gone already
+func dropMongoDBIndexIfExists(ctx context.Context, collection *mongo.Collection, indexName string) error {
+	_, err := collection.Indexes().DropOne(ctx, indexName)
+	var commandErr mongo.CommandError
+	if err != nil && !(errors.As(err, &commandErr) && commandErr.Code == mongoIndexNotFoundCode) {
+		return err
+	}
+	return nil
+}
+
+// dropMySQLIndexIfExists drops an index only when it is there, MySQL has no DROP INDEX IF EXISTS
+func dropMySQLIndexIfExists(connection *sql.DB, tableName, indexName string) error {
+	var indexCount int
+	err := connection.QueryRow("SELECT COUNT(*) FROM information_schema.statistics "+
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ?", tableName, indexName).Scan(&indexCount)
+	if err != nil || indexCount == 0 {
+		return err
+	}
+	_, err = connection.Exec("DROP INDEX " + indexName + " ON " + tableName)
+	return err
+}
+
 func createMySQLIndexes() error {
 	util.Logger.Info("Creating MySQL indexes...")
 
@@ -141,13 +501,268 @@ func createMySQLIndexes() error {
 	}
 	util.Logger.Info("✓ Created index: idx_category_id")
 
-	// Unique index on category name
-	_, err = connection.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_category_name_unique ON category(NAME)")
+	// Index on account_id
+	_, err = connection.Exec("CREATE INDEX IF NOT EXISTS idx_account_id ON cash_flow(ACCOUNT_ID)")
 	if err != nil {
-		util.Logger.Errorw("failed to create category name index", "error", err)
+		util.Logger.Errorw("failed to create account_id index", "error", err)
 		return err
 	}
-	util.Logger.Info("✓ Created unique index: idx_category_name_unique")
+	util.Logger.Info("✓ Created index: idx_account_id")
+
+	// Index on payee_id
+	_, err = connection.Exec("CREATE INDEX IF NOT EXISTS idx_payee_id ON cash_flow(PAYEE_ID)")
+	if err != nil {
+		util.Logger.Errorw("failed to create payee_id index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_payee_id")
+
+	// Index on tag, the primary key already covers lookups by cash flow
+	_, err = connection.Exec("CREATE INDEX IF NOT EXISTS idx_cash_flow_tag ON cash_flow_tag(TAG)")
+	if err != nil {
+		util.Logger.Errorw("failed to create cash_flow_tag index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_cash_flow_tag")
+
+	// Index on the category of split lines, the primary key already covers lookups by cash flow
+	_, err = connection.Exec("CREATE INDEX IF NOT EXISTS idx_cash_flow_split_category ON cash_flow_split(CATEGORY_ID)")
+	if err != nil {
+		util.Logger.Errorw("failed to create cash_flow_split index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_cash_flow_split_category")
+
+	// Full-text index on description, what a search matches its words against
+	_, err = connection.Exec("CREATE FULLTEXT INDEX IF NOT EXISTS idx_description_fulltext ON cash_flow(DESCRIPTION)")
+	if err != nil {
+		util.Logger.Errorw("failed to create description full-text index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_description_fulltext")
+
+	// Compound index on owner and belongs_date, every ledger query filters by owner first
+	_, err = connection.Exec("CREATE INDEX IF NOT EXISTS idx_owner_id_belongs_date ON cash_flow(OWNER_ID, BELONGS_DATE)")
+	if err != nil {
+		util.Logger.Errorw("failed to create owner_id belongs_date index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_owner_id_belongs_date")
+
+	// Unique index on category owner and name, names were unique across owners before
+	err = dropMySQLIndexIfExists(connection, "category", "idx_category_name_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop category name index", "error", err)
+		return err
+	}
+	_, err = connection.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_category_owner_name_unique ON category(OWNER_ID, NAME)")
+	if err != nil {
+		util.Logger.Errorw("failed to create category owner name index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_category_owner_name_unique")
+
+	// Unique index on account owner and name, names were unique across owners before
+	err = dropMySQLIndexIfExists(connection, "account", "idx_account_name_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop account name index", "error", err)
+		return err
+	}
+	_, err = connection.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_account_owner_name_unique ON account(OWNER_ID, NAME)")
+	if err != nil {
+		util.Logger.Errorw("failed to create account owner name index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_account_owner_name_unique")
+
+	// Unique index on exchange rate owner, pair and effective date, rates were unique across owners before
+	err = dropMySQLIndexIfExists(connection, "exchange_rate", "idx_exchange_rate_pair_date_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop exchange rate pair index", "error", err)
+		return err
+	}
+	_, err = connection.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_exchange_rate_owner_pair_date_unique ON exchange_rate(OWNER_ID, FROM_CURRENCY, TO_CURRENCY, EFFECTIVE_DATE)")
+	if err != nil {
+		util.Logger.Errorw("failed to create exchange rate pair index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_exchange_rate_owner_pair_date_unique")
+
+	// Unique index on recurring rule owner and name, names were unique across owners before
+	err = dropMySQLIndexIfExists(connection, "recurring_rule", "idx_recurring_rule_name_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop recurring rule name index", "error", err)
+		return err
+	}
+	_, err = connection.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_recurring_rule_owner_name_unique ON recurring_rule(OWNER_ID, NAME)")
+	if err != nil {
+		util.Logger.Errorw("failed to create recurring rule owner name index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_recurring_rule_owner_name_unique")
+
+	// Unique index on budget category and period
+	_, err = connection.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_budget_category_period_unique ON budget(CATEGORY_ID, PERIOD)")
+	if err != nil {
+		util.Logger.Errorw("failed to create budget category period index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_budget_category_period_unique")
+
+	// Index on budget owner
+	_, err = connection.Exec("CREATE INDEX IF NOT EXISTS idx_budget_owner_id ON budget(OWNER_ID)")
+	if err != nil {
+		util.Logger.Errorw("failed to create budget owner_id index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_budget_owner_id")
+
+	// Unique index on goal owner and name, names were unique across owners before
+	err = dropMySQLIndexIfExists(connection, "goal", "idx_goal_name_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop goal name index", "error", err)
+		return err
+	}
+	_, err = connection.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_goal_owner_name_unique ON goal(OWNER_ID, NAME)")
+	if err != nil {
+		util.Logger.Errorw("failed to create goal owner name index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_goal_owner_name_unique")
+
+	// Unique index on payee owner and name, names were unique across owners before
+	err = dropMySQLIndexIfExists(connection, "payee", "idx_payee_name_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop payee name index", "error", err)
+		return err
+	}
+	_, err = connection.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_payee_owner_name_unique ON payee(OWNER_ID, NAME)")
+	if err != nil {
+		util.Logger.Errorw("failed to create payee owner name index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_payee_owner_name_unique")
+
+	// Unique index on category rule owner and name, names were unique across owners before
+	err = dropMySQLIndexIfExists(connection, "category_rule", "idx_category_rule_name_unique")
+	if err != nil {
+		util.Logger.Errorw("failed to drop category rule name index", "error", err)
+		return err
+	}
+	_, err = connection.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_category_rule_owner_name_unique ON category_rule(OWNER_ID, NAME)")
+	if err != nil {
+		util.Logger.Errorw("failed to create category rule owner name index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_category_rule_owner_name_unique")
+
+	// Unique index on username
+	_, err = connection.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_user_username_unique ON user(USERNAME)")
+	if err != nil {
+		util.Logger.Errorw("failed to create user username index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_user_username_unique")
+
+	// Unique index on the api token hash, looked up on every request
+	_, err = connection.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_api_token_hash_unique ON api_token(TOKEN_HASH)")
+	if err != nil {
+		util.Logger.Errorw("failed to create api token hash index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_api_token_hash_unique")
+
+	// Index on the api token user
+	_, err = connection.Exec("CREATE INDEX IF NOT EXISTS idx_api_token_user_id ON api_token(USER_ID)")
+	if err != nil {
+		util.Logger.Errorw("failed to create api token user_id index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_api_token_user_id")
+
+	// A user joins a ledger once
+	_, err = connection.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_member_ledger_user_unique ON ledger_member(LEDGER_ID, USER_ID)")
+	if err != nil {
+		util.Logger.Errorw("failed to create ledger member index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_ledger_member_ledger_user_unique")
+
+	// Index on the ledger member user, their ledgers are listed on sign-in
+	_, err = connection.Exec("CREATE INDEX IF NOT EXISTS idx_ledger_member_user_id ON ledger_member(USER_ID)")
+	if err != nil {
+		util.Logger.Errorw("failed to create ledger member user_id index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created index: idx_ledger_member_user_id")
+
+	// Unique index on the ledger invitation token hash
+	_, err = connection.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_invitation_hash_unique ON ledger_invitation(TOKEN_HASH)")
+	if err != nil {
+		util.Logger.Errorw("failed to create ledger invitation hash index", "error", err)
+		return err
+	}
+	util.Logger.Info("✓ Created unique index: idx_ledger_invitation_hash_unique")
+
+	util.Logger.Info("All indexes created successfully")
+	return nil
+}
+
+func createSQLiteIndexes() error {
+	util.Logger.Info("Creating SQLite indexes...")
+
+	connection := database.GetSqliteConnection()
+
+	// Names and exchange rates were unique across owners before, they are unique per owner now
+	droppedIndexList := []string{
+		"idx_category_name_unique",
+		"idx_account_name_unique",
+		"idx_exchange_rate_pair_date_unique",
+		"idx_recurring_rule_name_unique",
+		"idx_goal_name_unique",
+		"idx_payee_name_unique",
+		"idx_category_rule_name_unique",
+	}
+	for _, indexName := range droppedIndexList {
+		if _, err := connection.Exec("DROP INDEX IF EXISTS " + indexName); err != nil {
+			util.Logger.Errorw("failed to drop index", "index", indexName, "error", err)
+			return err
+		}
+	}
+
+	indexList := []struct {
+		name      string
+		statement string
+	}{
+		{"idx_belongs_date", "CREATE INDEX IF NOT EXISTS idx_belongs_date ON cash_flow(BELONGS_DATE)"},
+		{"idx_flow_type", "CREATE INDEX IF NOT EXISTS idx_flow_type ON cash_flow(FLOW_TYPE)"},
+		{"idx_belongs_date_flow_type", "CREATE INDEX IF NOT EXISTS idx_belongs_date_flow_type ON cash_flow(BELONGS_DATE, FLOW_TYPE)"},
+		{"idx_category_id", "CREATE INDEX IF NOT EXISTS idx_category_id ON cash_flow(CATEGORY_ID)"},
+		{"idx_account_id", "CREATE INDEX IF NOT EXISTS idx_account_id ON cash_flow(ACCOUNT_ID)"},
+		{"idx_payee_id", "CREATE INDEX IF NOT EXISTS idx_payee_id ON cash_flow(PAYEE_ID)"},
+		{"idx_cash_flow_tag", "CREATE INDEX IF NOT EXISTS idx_cash_flow_tag ON cash_flow_tag(TAG)"},
+		{"idx_cash_flow_split_category", "CREATE INDEX IF NOT EXISTS idx_cash_flow_split_category ON cash_flow_split(CATEGORY_ID)"},
+		{"idx_owner_id_belongs_date", "CREATE INDEX IF NOT EXISTS idx_owner_id_belongs_date ON cash_flow(OWNER_ID, BELONGS_DATE)"},
+		{"idx_category_owner_name_unique", "CREATE UNIQUE INDEX IF NOT EXISTS idx_category_owner_name_unique ON category(OWNER_ID, NAME)"},
+		{"idx_account_owner_name_unique", "CREATE UNIQUE INDEX IF NOT EXISTS idx_account_owner_name_unique ON account(OWNER_ID, NAME)"},
+		{"idx_exchange_rate_owner_pair_date_unique", "CREATE UNIQUE INDEX IF NOT EXISTS idx_exchange_rate_owner_pair_date_unique ON exchange_rate(OWNER_ID, FROM_CURRENCY, TO_CURRENCY, EFFECTIVE_DATE)"},
+		{"idx_recurring_rule_owner_name_unique", "CREATE UNIQUE INDEX IF NOT EXISTS idx_recurring_rule_owner_name_unique ON recurring_rule(OWNER_ID, NAME)"},
+		{"idx_budget_category_period_unique", "CREATE UNIQUE INDEX IF NOT EXISTS idx_budget_category_period_unique ON budget(CATEGORY_ID, PERIOD)"},
+		{"idx_budget_owner_id", "CREATE INDEX IF NOT EXISTS idx_budget_owner_id ON budget(OWNER_ID)"},
+		{"idx_goal_owner_name_unique", "CREATE UNIQUE INDEX IF NOT EXISTS idx_goal_owner_name_unique ON goal(OWNER_ID, NAME)"},
+		{"idx_payee_owner_name_unique", "CREATE UNIQUE INDEX IF NOT EXISTS idx_payee_owner_name_unique ON payee(OWNER_ID, NAME)"},
+		{"idx_category_rule_owner_name_unique", "CREATE UNIQUE INDEX IF NOT EXISTS idx_category_rule_owner_name_unique ON category_rule(OWNER_ID, NAME)"},
+		{"idx_api_token_user_id", "CREATE INDEX IF NOT EXISTS idx_api_token_user_id ON api_token(USER_ID)"},
+		{"idx_ledger_member_user_id", "CREATE INDEX IF NOT EXISTS idx_ledger_member_user_id ON ledger_member(USER_ID)"},
+	}
+
+	for _, index := range indexList {
+		if _, err := connection.Exec(index.statement); err != nil {
+			util.Logger.Errorw("failed to create index", "index", index.name, "error", err)
+			return err
+		}
+		util.Logger.Info("✓ Created index: " + index.name)
+	}
 
 	util.Logger.Info("All indexes created successfully")
 	return nil
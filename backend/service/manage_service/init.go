@@ -4,13 +4,16 @@ import (
 	"time"
 
 	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/account_service"
 	"github.com/macar-x/cashlens/service/cash_flow_service"
 	"github.com/macar-x/cashlens/service/category_service"
 	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
 )
 
-// InitializeDemoData initializes the database with demo categories and transactions
-func InitializeDemoData() error {
+// InitializeDemoData initializes the database with demo categories, accounts and transactions,
+// the categories and transactions go into the owner's ledger
+func InitializeDemoData(ownerPlainId string) error {
 	// Create default categories
 	categories := []string{
 		"Salary",
@@ -25,42 +28,79 @@ func InitializeDemoData() error {
 
 	for _, catName := range categories {
 		// Check if category already exists
-		err := category_service.CreateService("", catName)
+		_, err := category_service.CreateService(ownerPlainId, "", catName)
 		if err != nil {
 			util.Logger.Warnw("category creation skipped", "category", catName, "error", err)
 		}
 	}
 
+	// Create default accounts
+	accounts := []struct {
+		name           string
+		accountType    string
+		openingBalance float64
+	}{
+		{"Bank", model.AccountTypeBank, 1000.00},
+		{"Wallet", model.AccountTypeCash, 200.00},
+	}
+
+	for _, account := range accounts {
+		_, err := account_service.CreateService(ownerPlainId,
+			account.name, account.accountType, "", decimal.NewFromFloat(account.openingBalance), "")
+		if err != nil {
+			util.Logger.Warnw("account creation skipped", "account", account.name, "error", err)
+		}
+	}
+
 	// Create sample transactions for the past week
 	today := time.Now()
 
 	// Sample income
 	_, _ = cash_flow_service.SaveIncome(
+		ownerPlainId,
+		"",
 		today.AddDate(0, 0, -7).Format(model.DateFormatYYYYMMDD),
 		"Salary",
-		5000.00,
+		"Bank",
+		"",
+		decimal.NewFromInt(5000),
 		"Monthly salary",
+		"",
+		nil,
+	)
+
+	// Sample transfer, topping up the wallet from the bank
+	_, _ = cash_flow_service.SaveTransfer(
+		ownerPlainId,
+		"",
+		today.AddDate(0, 0, -6).Format(model.DateFormatYYYYMMDD),
+		"Bank",
+		"Wallet",
+		decimal.NewFromInt(100),
+		"ATM withdrawal",
 	)
 
 	// Sample expenses
 	expenses := []struct {
 		daysAgo     int
 		category    string
+		account     string
 		amount      float64
 		description string
 	}{
-		{1, "Food & Dining", 45.50, "Lunch"},
-		{1, "Transportation", 20.00, "Bus fare"},
-		{2, "Food & Dining", 32.00, "Groceries"},
-		{3, "Entertainment", 50.00, "Movie tickets"},
-		{4, "Shopping", 120.00, "Clothes"},
-		{5, "Healthcare", 80.00, "Pharmacy"},
-		{6, "Utilities", 150.00, "Electricity bill"},
+		{1, "Food & Dining", "Wallet", 45.50, "Lunch"},
+		{1, "Transportation", "Wallet", 20.00, "Bus fare"},
+		{2, "Food & Dining", "Bank", 32.00, "Groceries"},
+		{3, "Entertainment", "Wallet", 50.00, "Movie tickets"},
+		{4, "Shopping", "Bank", 120.00, "Clothes"},
+		{5, "Healthcare", "Bank", 80.00, "Pharmacy"},
+		{6, "Utilities", "Bank", 150.00, "Electricity bill"},
 	}
 
 	for _, exp := range expenses {
 		date := today.AddDate(0, 0, -exp.daysAgo).Format(model.DateFormatYYYYMMDD)
-		_, _ = cash_flow_service.SaveOutcome(date, exp.category, exp.amount, exp.description)
+		_, _ = cash_flow_service.SaveOutcome(
+			ownerPlainId, "", date, exp.category, exp.account, "", decimal.NewFromFloat(exp.amount), exp.description, "", nil)
 	}
 
 	return nil
@@ -2,20 +2,162 @@ package manage_service
 
 import (
 	"errors"
+	"fmt"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/budget_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/util"
+)
+
+// Reset scopes
+const (
+	// ResetScopeAll clears cash flows, categories, accounts, exchange rates, recurring rules, budgets, goals, payees and category rules
+	ResetScopeAll = "all"
+	// ResetScopeCashFlows clears only cash flows
+	ResetScopeCashFlows = "cash_flows"
+	// ResetScopeCategories clears only categories
+	ResetScopeCategories = "categories"
 )
 
-// ResetDatabase clears all data from the database
-func ResetDatabase() error {
-	// Note: To properly implement this, we need:
-	// 1. Mapper method to delete all cash flows: DeleteAllCashFlows()
-	// 2. Mapper method to delete all categories: DeleteAllCategories()
+// ResetResult reports what a reset removed from the database
+type ResetResult struct {
+	Scope                 string
+	BackupPath            string
+	CashFlowsDeleted      int64
+	CategoriesDeleted     int64
+	AccountsDeleted       int64
+	ExchangeRatesDeleted  int64
+	RecurringRulesDeleted int64
+	BudgetsDeleted        int64
+	GoalsDeleted          int64
+	PayeesDeleted         int64
+	CategoryRulesDeleted  int64
+}
+
+// ResetDatabase clears data from the database after writing a backup of it to backupPath.
+// Nothing is deleted when the backup cannot be created.
+func ResetDatabase(scope, backupPath string) (*ResetResult, error) {
+	if scope == "" {
+		scope = ResetScopeAll
+	}
+	if scope != ResetScopeAll && scope != ResetScopeCashFlows && scope != ResetScopeCategories {
+		return nil, errors.New("invalid reset scope: must be all, cash_flows or categories")
+	}
+	if backupPath == "" {
+		return nil, errors.New("pre-reset backup path cannot be empty")
+	}
+
+	// Categories still referenced by cash flows cannot be removed on their own
+	if scope == ResetScopeCategories {
+		cashFlowCount := cash_flow_mapper.INSTANCE.CountAllCashFlows()
+		if cashFlowCount > 0 {
+			return nil, fmt.Errorf("%d cash_flows still refer to categories, reset cash_flows first or reset all",
+				cashFlowCount)
+		}
+		recurringRuleCount := recurring_rule_mapper.INSTANCE.CountAllRecurringRules()
+		if recurringRuleCount > 0 {
+			return nil, fmt.Errorf("%d recurring rules still refer to categories, delete them first or reset all",
+				recurringRuleCount)
+		}
+		budgetCount := budget_mapper.INSTANCE.CountAllBudgets()
+		if budgetCount > 0 {
+			return nil, fmt.Errorf("%d budgets still refer to categories, delete them first or reset all",
+				budgetCount)
+		}
+		categoryRuleCount := category_rule_mapper.INSTANCE.CountAllCategoryRules()
+		if categoryRuleCount > 0 {
+			return nil, fmt.Errorf("%d category rules still refer to categories, delete them first or reset all",
+				categoryRuleCount)
+		}
+	}
+
+	if _, err := CreateBackup(backupPath); err != nil {
+		return nil, fmt.Errorf("pre-reset backup failed, nothing was deleted: %w", err)
+	}
+
+	result := &ResetResult{
+		Scope:      scope,
+		BackupPath: backupPath,
+	}
+
+	if scope == ResetScopeAll || scope == ResetScopeCashFlows {
+		deletedCount, err := cash_flow_mapper.INSTANCE.DeleteAllCashFlows()
+		result.CashFlowsDeleted = deletedCount
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if scope == ResetScopeAll || scope == ResetScopeCategories {
+		deletedCount, err := category_mapper.INSTANCE.DeleteAllCategories()
+		result.CategoriesDeleted = deletedCount
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if scope == ResetScopeAll {
+		deletedCount, err := recurring_rule_mapper.INSTANCE.DeleteAllRecurringRules()
+		result.RecurringRulesDeleted = deletedCount
+		if err != nil {
+			return result, err
+		}
+
+		deletedCount, err = budget_mapper.INSTANCE.DeleteAllBudgets()
+		result.BudgetsDeleted = deletedCount
+		if err != nil {
+			return result, err
+		}
+
+		deletedCount, err = goal_mapper.INSTANCE.DeleteAllGoals()
+		result.GoalsDeleted = deletedCount
+		if err != nil {
+			return result, err
+		}
+
+		deletedCount, err = category_rule_mapper.INSTANCE.DeleteAllCategoryRules()
+		result.CategoryRulesDeleted = deletedCount
+		if err != nil {
+			return result, err
+		}
+
+		deletedCount, err = payee_mapper.INSTANCE.DeleteAllPayees()
+		result.PayeesDeleted = deletedCount
+		if err != nil {
+			return result, err
+		}
 
-	// This is a dangerous operation and should be implemented carefully
-	// with proper transaction support and confirmation
+		deletedCount, err = account_mapper.INSTANCE.DeleteAllAccounts()
+		result.AccountsDeleted = deletedCount
+		if err != nil {
+			return result, err
+		}
 
-	// TODO: Add to mapper interfaces:
-	//   - DeleteAllCashFlows() int64
-	//   - DeleteAllCategories() int64
+		deletedCount, err = exchange_rate_mapper.INSTANCE.DeleteAllExchangeRates()
+		result.ExchangeRatesDeleted = deletedCount
+		if err != nil {
+			return result, err
+		}
+	}
 
-	return errors.New("database reset requires mapper enhancement - need DeleteAll methods")
+	util.Logger.Infow("database reset",
+		"scope", scope,
+		"backup_path", backupPath,
+		"cash_flows", result.CashFlowsDeleted,
+		"categories", result.CategoriesDeleted,
+		"accounts", result.AccountsDeleted,
+		"exchange_rates", result.ExchangeRatesDeleted,
+		"recurring_rules", result.RecurringRulesDeleted,
+		"budgets", result.BudgetsDeleted,
+		"goals", result.GoalsDeleted,
+		"payees", result.PayeesDeleted,
+		"category_rules", result.CategoryRulesDeleted)
+	return result, nil
 }
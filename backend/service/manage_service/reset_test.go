@@ -0,0 +1,26 @@
+package manage_service
+
+import "testing"
+
+func TestResetDatabaseRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name       string
+		scope      string
+		backupPath string
+	}{
+		{"unknown scope", "everything", "backup.json"},
+		{"missing backup path", ResetScopeAll, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ResetDatabase(tt.scope, tt.backupPath)
+			if err == nil {
+				t.Errorf("ResetDatabase(%q, %q) expected error, got nil", tt.scope, tt.backupPath)
+			}
+			if result != nil {
+				t.Errorf("ResetDatabase(%q, %q) expected nil result, got %+v", tt.scope, tt.backupPath, result)
+			}
+		})
+	}
+}
@@ -3,38 +3,2218 @@ package manage_service
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/api_token_mapper"
+	"github.com/macar-x/cashlens/mapper/budget_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/mapper/ledger_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/user_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Restore modes
+const (
+	// RestoreModeReplace clears existing data before restoring the backup
+	RestoreModeReplace = "replace"
+	// RestoreModeMerge keeps existing data and only adds records missing from the database
+	RestoreModeMerge = "merge"
 )
 
-// RestoreBackup restores database from a backup file
-func RestoreBackup(filePath string) error {
+// RestoreResult reports what a restore applied to the database
+type RestoreResult struct {
+	Mode                   string
+	CategoriesCleared      int
+	AccountsCleared        int
+	CashFlowsCleared       int
+	ExchangeRatesCleared   int
+	RecurringRulesCleared  int
+	BudgetsCleared         int
+	GoalsCleared           int
+	PayeesCleared          int
+	CategoryRulesCleared   int
+	CategoriesRestored     int
+	CategoriesSkipped      int
+	AccountsRestored       int
+	AccountsSkipped        int
+	CashFlowsRestored      int
+	CashFlowsSkipped       int
+	ExchangeRatesRestored  int
+	ExchangeRatesSkipped   int
+	RecurringRulesRestored int
+	RecurringRulesSkipped  int
+	BudgetsRestored        int
+	BudgetsSkipped         int
+	GoalsRestored          int
+	GoalsSkipped           int
+	PayeesRestored         int
+	PayeesSkipped          int
+	CategoryRulesRestored  int
+	CategoryRulesSkipped   int
+	// Access data is only cleared when the backup carries it
+	UsersCleared              int
+	ApiTokensCleared          int
+	LedgersCleared            int
+	LedgerMembersCleared      int
+	LedgerInvitationsCleared  int
+	UsersRestored             int
+	UsersSkipped              int
+	ApiTokensRestored         int
+	ApiTokensSkipped          int
+	LedgersRestored           int
+	LedgersSkipped            int
+	LedgerMembersRestored     int
+	LedgerMembersSkipped      int
+	LedgerInvitationsRestored int
+	LedgerInvitationsSkipped  int
+	RolledBack                bool
+}
+
+// restoreRun keeps track of everything written during one restore, so it can be undone
+type restoreRun struct {
+	result              *RestoreResult
+	snapshot            *BackupData
+	insertedCategoryIds []primitive.ObjectID
+	insertedAccountIds  []primitive.ObjectID
+	insertedCashFlowIds []primitive.ObjectID
+	// exchange rates are not referred to by other records, so they are simply restored last
+	insertedExchangeRateIds  []primitive.ObjectID
+	insertedRecurringRuleIds []primitive.ObjectID
+	insertedBudgetIds        []primitive.ObjectID
+	insertedGoalIds          []primitive.ObjectID
+	insertedPayeeIds         []primitive.ObjectID
+	insertedCategoryRuleIds  []primitive.ObjectID
+	// access data is restored first, as every other record belongs to a user or ledger;
+	// tokens, members and invitations are kept whole so rollback can look them up
+	insertedUserIds           []primitive.ObjectID
+	insertedLedgerIds         []primitive.ObjectID
+	insertedLedgerMembers     []model.LedgerMemberEntity
+	insertedLedgerInvitations []model.LedgerInvitationEntity
+	insertedApiTokens         []model.ApiTokenEntity
+}
+
+// RestoreBackup restores database from a backup file.
+// If restoring fails partway, every change is rolled back; when the rollback
+// itself fails the returned result reports what is still applied.
+func RestoreBackup(filePath, mode string) (*RestoreResult, error) {
 	if filePath == "" {
-		return errors.New("file path cannot be empty")
+		return nil, errors.New("file path cannot be empty")
+	}
+	if mode == "" {
+		mode = RestoreModeReplace
+	}
+	if mode != RestoreModeReplace && mode != RestoreModeMerge {
+		return nil, errors.New("invalid restore mode: must be replace or merge")
+	}
+
+	backup, err := readBackupFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateBackup(backup); err != nil {
+		return nil, err
 	}
 
-	// Read backup file
+	// Snapshot existing data first, it is needed to skip duplicates and to roll back
+	snapshot, err := collectSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	run := &restoreRun{
+		result:   &RestoreResult{Mode: mode},
+		snapshot: snapshot,
+	}
+
+	if err := run.apply(backup); err != nil {
+		util.Logger.Errorw("restore failed, rolling back", "error", err)
+		if rollbackErr := run.rollback(); rollbackErr != nil {
+			return run.result, fmt.Errorf("restore failed: %v; rollback failed: %v "+
+				"(still applied: %d categories, %d accounts, %d cash_flows, %d exchange rates, %d recurring rules, %d budgets, %d goals, %d payees and %d category rules restored, "+
+				"%d categories, %d accounts, %d cash_flows, %d exchange rates, %d recurring rules, %d budgets, %d goals, %d payees and %d category rules cleared; "+
+				"%d users, %d api tokens, %d ledgers, %d ledger members and %d ledger invitations restored, "+
+				"%d users, %d api tokens, %d ledgers, %d ledger members and %d ledger invitations cleared)",
+				err, rollbackErr,
+				run.result.CategoriesRestored, run.result.AccountsRestored, run.result.CashFlowsRestored,
+				run.result.ExchangeRatesRestored, run.result.RecurringRulesRestored, run.result.BudgetsRestored,
+				run.result.GoalsRestored, run.result.PayeesRestored, run.result.CategoryRulesRestored,
+				run.result.CategoriesCleared, run.result.AccountsCleared, run.result.CashFlowsCleared,
+				run.result.ExchangeRatesCleared, run.result.RecurringRulesCleared, run.result.BudgetsCleared,
+				run.result.GoalsCleared, run.result.PayeesCleared, run.result.CategoryRulesCleared,
+				run.result.UsersRestored, run.result.ApiTokensRestored, run.result.LedgersRestored,
+				run.result.LedgerMembersRestored, run.result.LedgerInvitationsRestored,
+				run.result.UsersCleared, run.result.ApiTokensCleared, run.result.LedgersCleared,
+				run.result.LedgerMembersCleared, run.result.LedgerInvitationsCleared)
+		}
+		run.result.RolledBack = true
+		return run.result, fmt.Errorf("restore failed and was rolled back: %v", err)
+	}
+
+	util.Logger.Infow("backup restored",
+		"file_path", filePath,
+		"mode", mode,
+		"categories_restored", run.result.CategoriesRestored,
+		"accounts_restored", run.result.AccountsRestored,
+		"cash_flows_restored", run.result.CashFlowsRestored,
+		"exchange_rates_restored", run.result.ExchangeRatesRestored,
+		"recurring_rules_restored", run.result.RecurringRulesRestored,
+		"budgets_restored", run.result.BudgetsRestored,
+		"goals_restored", run.result.GoalsRestored,
+		"payees_restored", run.result.PayeesRestored,
+		"category_rules_restored", run.result.CategoryRulesRestored,
+		"users_restored", run.result.UsersRestored,
+		"api_tokens_restored", run.result.ApiTokensRestored,
+		"ledgers_restored", run.result.LedgersRestored,
+		"ledger_members_restored", run.result.LedgerMembersRestored,
+		"ledger_invitations_restored", run.result.LedgerInvitationsRestored)
+	return run.result, nil
+}
+
+func readBackupFile(filePath string) (*BackupData, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer file.Close()
 
-	// Parse JSON
 	var backup BackupData
 	decoder := json.NewDecoder(file)
 	if err := decoder.Decode(&backup); err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// validateBackup checks the format version and every record before anything is written
+func validateBackup(backup *BackupData) error {
+	if backup.Version == "" {
+		return errors.New("backup version is missing")
+	}
+	backupVersion, isValid := parseVersion(backup.Version)
+	if !isValid {
+		return fmt.Errorf("invalid backup version %s", backup.Version)
+	}
+	currentVersion, _ := parseVersion(BackupVersion)
+	if backupVersion[0] != currentVersion[0] {
+		return fmt.Errorf("unsupported backup version %s, expected %d.x", backup.Version, currentVersion[0])
+	}
+	// 新版本的備份可能帶有這裡不認得的欄位, 還原會默默丟掉它們
+	if isVersionNewer(backupVersion, currentVersion) {
+		return fmt.Errorf("backup version %s is newer than %s, upgrade before restoring it", backup.Version, BackupVersion)
+	}
+
+	categoryIds := make(map[string]bool)
+	for index, category := range backup.Categories {
+		if err := validation.ValidateID(category.Id); err != nil {
+			return fmt.Errorf("category %d: %v", index, err)
+		}
+		if categoryIds[category.Id] {
+			return fmt.Errorf("category %d: duplicated id %s", index, category.Id)
+		}
+		categoryIds[category.Id] = true
+		if category.ParentId != "" {
+			if err := validation.ValidateID(category.ParentId); err != nil {
+				return fmt.Errorf("category %d: parent %v", index, err)
+			}
+		}
+		if category.OwnerId != "" {
+			if err := validation.ValidateID(category.OwnerId); err != nil {
+				return fmt.Errorf("category %d: owner %v", index, err)
+			}
+		}
+		if category.Name == "" {
+			return fmt.Errorf("category %d: name cannot be empty", index)
+		}
+	}
+
+	accountIds := make(map[string]bool)
+	for index, account := range backup.Accounts {
+		if err := validation.ValidateID(account.Id); err != nil {
+			return fmt.Errorf("account %d: %v", index, err)
+		}
+		if accountIds[account.Id] {
+			return fmt.Errorf("account %d: duplicated id %s", index, account.Id)
+		}
+		accountIds[account.Id] = true
+		if account.OwnerId != "" {
+			if err := validation.ValidateID(account.OwnerId); err != nil {
+				return fmt.Errorf("account %d: owner %v", index, err)
+			}
+		}
+		if account.Name == "" {
+			return fmt.Errorf("account %d: name cannot be empty", index)
+		}
+		// Accounts written before currencies existed have none and use the default currency
+		if account.Currency != "" {
+			if err := validation.ValidateCurrency(account.Currency); err != nil {
+				return fmt.Errorf("account %d: %v", index, err)
+			}
+		}
+	}
+
+	payeeIds := make(map[string]bool)
+	payeeNames := make(map[string]bool)
+	for index, payee := range backup.Payees {
+		if err := validation.ValidateID(payee.Id); err != nil {
+			return fmt.Errorf("payee %d: %v", index, err)
+		}
+		if payeeIds[payee.Id] {
+			return fmt.Errorf("payee %d: duplicated id %s", index, payee.Id)
+		}
+		payeeIds[payee.Id] = true
+		if payee.OwnerId != "" {
+			if err := validation.ValidateID(payee.OwnerId); err != nil {
+				return fmt.Errorf("payee %d: owner %v", index, err)
+			}
+		}
+		if err := validation.ValidatePayeeName(payee.Name); err != nil {
+			return fmt.Errorf("payee %d: %v", index, err)
+		}
+		if payeeNames[payee.OwnerId+"/"+payee.Name] {
+			return fmt.Errorf("payee %d: duplicated name %s", index, payee.Name)
+		}
+		payeeNames[payee.OwnerId+"/"+payee.Name] = true
+		for _, rule := range payee.Rules {
+			if err := validation.ValidatePayeeRule(rule.MatchType, rule.Pattern); err != nil {
+				return fmt.Errorf("payee %d: %v", index, err)
+			}
+		}
+	}
+
+	cashFlowIds := make(map[string]bool)
+	for index, cashFlow := range backup.CashFlows {
+		if err := validation.ValidateID(cashFlow.Id); err != nil {
+			return fmt.Errorf("cash_flow %d: %v", index, err)
+		}
+		if cashFlowIds[cashFlow.Id] {
+			return fmt.Errorf("cash_flow %d: duplicated id %s", index, cashFlow.Id)
+		}
+		cashFlowIds[cashFlow.Id] = true
+		if err := validation.ValidateFlowType(cashFlow.FlowType); err != nil {
+			return fmt.Errorf("cash_flow %d: %v", index, err)
+		}
+		if err := validation.ValidateDate(cashFlow.BelongsDate); err != nil {
+			return fmt.Errorf("cash_flow %d: %v", index, err)
+		}
+		if cashFlow.Currency != "" {
+			if err := validation.ValidateCurrency(cashFlow.Currency); err != nil {
+				return fmt.Errorf("cash_flow %d: %v", index, err)
+			}
+		}
+		tagSet := make(map[string]bool, len(cashFlow.Tags))
+		for _, tag := range cashFlow.Tags {
+			if err := validation.ValidateTag(tag); err != nil {
+				return fmt.Errorf("cash_flow %d: %v", index, err)
+			}
+			if tagSet[tag] {
+				return fmt.Errorf("cash_flow %d: duplicated tag %s", index, tag)
+			}
+			tagSet[tag] = true
+		}
+
+		// Transfers have no category, they link to their other leg instead
+		if cashFlow.FlowType == model.FlowTypeTransfer {
+			if err := validation.ValidateID(cashFlow.LinkedId); err != nil {
+				return fmt.Errorf("cash_flow %d: linked %v", index, err)
+			}
+		} else {
+			if err := validation.ValidateID(cashFlow.CategoryId); err != nil {
+				return fmt.Errorf("cash_flow %d: category %v", index, err)
+			}
+			if !categoryIds[cashFlow.CategoryId] {
+				util.Logger.Warnw("cash_flow refers to a category missing from backup",
+					"cash_flow_id", cashFlow.Id, "category_id", cashFlow.CategoryId)
+			}
+		}
+		if cashFlow.OwnerId != "" {
+			if err := validation.ValidateID(cashFlow.OwnerId); err != nil {
+				return fmt.Errorf("cash_flow %d: owner %v", index, err)
+			}
+		}
+		if cashFlow.CreatedBy != "" {
+			if err := validation.ValidateID(cashFlow.CreatedBy); err != nil {
+				return fmt.Errorf("cash_flow %d: created_by %v", index, err)
+			}
+		}
+		if cashFlow.ModifiedBy != "" {
+			if err := validation.ValidateID(cashFlow.ModifiedBy); err != nil {
+				return fmt.Errorf("cash_flow %d: modified_by %v", index, err)
+			}
+		}
+		if len(cashFlow.Splits) > 0 {
+			if cashFlow.FlowType == model.FlowTypeTransfer {
+				return fmt.Errorf("cash_flow %d: a transfer can not be split", index)
+			}
+			lineAmounts := make([]decimal.Decimal, 0, len(cashFlow.Splits))
+			for _, split := range cashFlow.Splits {
+				if err := validation.ValidateID(split.CategoryId); err != nil {
+					return fmt.Errorf("cash_flow %d: split category %v", index, err)
+				}
+				if !categoryIds[split.CategoryId] {
+					util.Logger.Warnw("cash_flow split refers to a category missing from backup",
+						"cash_flow_id", cashFlow.Id, "category_id", split.CategoryId)
+				}
+				lineAmounts = append(lineAmounts, split.Amount)
+			}
+			if err := validation.ValidateSplitLines(cashFlow.Amount, lineAmounts); err != nil {
+				return fmt.Errorf("cash_flow %d: %v", index, err)
+			}
+		}
+		if cashFlow.AccountId != "" {
+			if err := validation.ValidateID(cashFlow.AccountId); err != nil {
+				return fmt.Errorf("cash_flow %d: account %v", index, err)
+			}
+			if !accountIds[cashFlow.AccountId] {
+				util.Logger.Warnw("cash_flow refers to an account missing from backup",
+					"cash_flow_id", cashFlow.Id, "account_id", cashFlow.AccountId)
+			}
+		}
+		if cashFlow.PayeeId != "" {
+			if cashFlow.FlowType == model.FlowTypeTransfer {
+				return fmt.Errorf("cash_flow %d: a transfer can not have a payee", index)
+			}
+			if err := validation.ValidateID(cashFlow.PayeeId); err != nil {
+				return fmt.Errorf("cash_flow %d: payee %v", index, err)
+			}
+			if !payeeIds[cashFlow.PayeeId] {
+				util.Logger.Warnw("cash_flow refers to a payee missing from backup",
+					"cash_flow_id", cashFlow.Id, "payee_id", cashFlow.PayeeId)
+			}
+		}
+	}
+
+	exchangeRateIds := make(map[string]bool)
+	for index, exchangeRate := range backup.ExchangeRates {
+		if err := validation.ValidateID(exchangeRate.Id); err != nil {
+			return fmt.Errorf("exchange rate %d: %v", index, err)
+		}
+		if exchangeRateIds[exchangeRate.Id] {
+			return fmt.Errorf("exchange rate %d: duplicated id %s", index, exchangeRate.Id)
+		}
+		exchangeRateIds[exchangeRate.Id] = true
+		if exchangeRate.OwnerId != "" {
+			if err := validation.ValidateID(exchangeRate.OwnerId); err != nil {
+				return fmt.Errorf("exchange rate %d: owner %v", index, err)
+			}
+		}
+		if err := validation.ValidateCurrency(exchangeRate.FromCurrency); err != nil {
+			return fmt.Errorf("exchange rate %d: %v", index, err)
+		}
+		if err := validation.ValidateCurrency(exchangeRate.ToCurrency); err != nil {
+			return fmt.Errorf("exchange rate %d: %v", index, err)
+		}
+		if err := validation.ValidateExchangeRate(exchangeRate.Rate); err != nil {
+			return fmt.Errorf("exchange rate %d: %v", index, err)
+		}
+		if err := validation.ValidateDate(exchangeRate.EffectiveDate); err != nil {
+			return fmt.Errorf("exchange rate %d: %v", index, err)
+		}
+	}
+
+	recurringRuleIds := make(map[string]bool)
+	for index, recurringRule := range backup.RecurringRules {
+		if err := validation.ValidateID(recurringRule.Id); err != nil {
+			return fmt.Errorf("recurring rule %d: %v", index, err)
+		}
+		if recurringRuleIds[recurringRule.Id] {
+			return fmt.Errorf("recurring rule %d: duplicated id %s", index, recurringRule.Id)
+		}
+		recurringRuleIds[recurringRule.Id] = true
+		if recurringRule.OwnerId != "" {
+			if err := validation.ValidateID(recurringRule.OwnerId); err != nil {
+				return fmt.Errorf("recurring rule %d: owner %v", index, err)
+			}
+		}
+		if err := validation.ValidateRecurringRuleName(recurringRule.Name); err != nil {
+			return fmt.Errorf("recurring rule %d: %v", index, err)
+		}
+		if recurringRule.FlowType != model.FlowTypeIncome && recurringRule.FlowType != model.FlowTypeOutcome {
+			return fmt.Errorf("recurring rule %d: flow type must be INCOME or OUTCOME", index)
+		}
+		if err := validation.ValidateFrequency(recurringRule.Frequency, recurringRule.DayOfMonth); err != nil {
+			return fmt.Errorf("recurring rule %d: %v", index, err)
+		}
+		if err := validation.ValidateOccurrenceLimit(recurringRule.OccurrenceLimit); err != nil {
+			return fmt.Errorf("recurring rule %d: %v", index, err)
+		}
+		if err := validation.ValidateDate(recurringRule.StartDate); err != nil {
+			return fmt.Errorf("recurring rule %d: %v", index, err)
+		}
+		for _, optionalDate := range []string{recurringRule.EndDate, recurringRule.LastRunDate} {
+			if optionalDate == "" {
+				continue
+			}
+			if err := validation.ValidateDate(optionalDate); err != nil {
+				return fmt.Errorf("recurring rule %d: %v", index, err)
+			}
+		}
+		if err := validation.ValidateCurrency(recurringRule.Currency); err != nil {
+			return fmt.Errorf("recurring rule %d: %v", index, err)
+		}
+		if err := validation.ValidateID(recurringRule.CategoryId); err != nil {
+			return fmt.Errorf("recurring rule %d: category %v", index, err)
+		}
+		if !categoryIds[recurringRule.CategoryId] {
+			util.Logger.Warnw("recurring rule refers to a category missing from backup",
+				"recurring_rule_id", recurringRule.Id, "category_id", recurringRule.CategoryId)
+		}
+		if recurringRule.AccountId != "" {
+			if err := validation.ValidateID(recurringRule.AccountId); err != nil {
+				return fmt.Errorf("recurring rule %d: account %v", index, err)
+			}
+			if !accountIds[recurringRule.AccountId] {
+				util.Logger.Warnw("recurring rule refers to an account missing from backup",
+					"recurring_rule_id", recurringRule.Id, "account_id", recurringRule.AccountId)
+			}
+		}
+	}
+
+	budgetIds := make(map[string]bool)
+	budgetKeys := make(map[string]bool)
+	for index, budget := range backup.Budgets {
+		if err := validation.ValidateID(budget.Id); err != nil {
+			return fmt.Errorf("budget %d: %v", index, err)
+		}
+		if budgetIds[budget.Id] {
+			return fmt.Errorf("budget %d: duplicated id %s", index, budget.Id)
+		}
+		budgetIds[budget.Id] = true
+		if budget.OwnerId != "" {
+			if err := validation.ValidateID(budget.OwnerId); err != nil {
+				return fmt.Errorf("budget %d: owner %v", index, err)
+			}
+		}
+		if err := validation.ValidateBudgetPeriod(budget.Period); err != nil {
+			return fmt.Errorf("budget %d: %v", index, err)
+		}
+		if err := validation.ValidateAmount(budget.Limit); err != nil {
+			return fmt.Errorf("budget %d: %v", index, err)
+		}
+		if err := validation.ValidateCurrency(budget.Currency); err != nil {
+			return fmt.Errorf("budget %d: %v", index, err)
+		}
+		if err := validation.ValidateDate(budget.StartDate); err != nil {
+			return fmt.Errorf("budget %d: %v", index, err)
+		}
+		if err := validation.ValidateID(budget.CategoryId); err != nil {
+			return fmt.Errorf("budget %d: category %v", index, err)
+		}
+		if budgetKeys[budget.CategoryId+"/"+budget.Period] {
+			return fmt.Errorf("budget %d: duplicated %s budget of category %s", index, budget.Period, budget.CategoryId)
+		}
+		budgetKeys[budget.CategoryId+"/"+budget.Period] = true
+		if !categoryIds[budget.CategoryId] {
+			util.Logger.Warnw("budget refers to a category missing from backup",
+				"budget_id", budget.Id, "category_id", budget.CategoryId)
+		}
+	}
+
+	goalIds := make(map[string]bool)
+	goalNames := make(map[string]bool)
+	for index, goal := range backup.Goals {
+		if err := validation.ValidateID(goal.Id); err != nil {
+			return fmt.Errorf("goal %d: %v", index, err)
+		}
+		if goalIds[goal.Id] {
+			return fmt.Errorf("goal %d: duplicated id %s", index, goal.Id)
+		}
+		goalIds[goal.Id] = true
+		if goal.OwnerId != "" {
+			if err := validation.ValidateID(goal.OwnerId); err != nil {
+				return fmt.Errorf("goal %d: owner %v", index, err)
+			}
+		}
+		if err := validation.ValidateGoalName(goal.Name); err != nil {
+			return fmt.Errorf("goal %d: %v", index, err)
+		}
+		if goalNames[goal.OwnerId+"/"+goal.Name] {
+			return fmt.Errorf("goal %d: duplicated name %s", index, goal.Name)
+		}
+		goalNames[goal.OwnerId+"/"+goal.Name] = true
+		if err := validation.ValidateAmount(goal.TargetAmount); err != nil {
+			return fmt.Errorf("goal %d: %v", index, err)
+		}
+		if err := validation.ValidateCurrency(goal.Currency); err != nil {
+			return fmt.Errorf("goal %d: %v", index, err)
+		}
+		for _, date := range []string{goal.StartDate, goal.Deadline} {
+			if err := validation.ValidateDate(date); err != nil {
+				return fmt.Errorf("goal %d: %v", index, err)
+			}
+		}
+		if err := validation.ValidateID(goal.AccountId); err != nil {
+			return fmt.Errorf("goal %d: account %v", index, err)
+		}
+		if !accountIds[goal.AccountId] {
+			util.Logger.Warnw("goal refers to an account missing from backup",
+				"goal_id", goal.Id, "account_id", goal.AccountId)
+		}
+	}
+
+	categoryRuleIds := make(map[string]bool)
+	categoryRuleNames := make(map[string]bool)
+	for index, categoryRule := range backup.CategoryRules {
+		if err := validation.ValidateID(categoryRule.Id); err != nil {
+			return fmt.Errorf("category_rule %d: %v", index, err)
+		}
+		if categoryRuleIds[categoryRule.Id] {
+			return fmt.Errorf("category_rule %d: duplicated id %s", index, categoryRule.Id)
+		}
+		categoryRuleIds[categoryRule.Id] = true
+		if categoryRule.OwnerId != "" {
+			if err := validation.ValidateID(categoryRule.OwnerId); err != nil {
+				return fmt.Errorf("category_rule %d: owner %v", index, err)
+			}
+		}
+		if err := validation.ValidateCategoryRuleName(categoryRule.Name); err != nil {
+			return fmt.Errorf("category_rule %d: %v", index, err)
+		}
+		if categoryRuleNames[categoryRule.OwnerId+"/"+categoryRule.Name] {
+			return fmt.Errorf("category_rule %d: duplicated name %s", index, categoryRule.Name)
+		}
+		categoryRuleNames[categoryRule.OwnerId+"/"+categoryRule.Name] = true
+		if categoryRule.FlowType != "" && categoryRule.FlowType != model.FlowTypeIncome &&
+			categoryRule.FlowType != model.FlowTypeOutcome {
+			return fmt.Errorf("category_rule %d: flow_type must be INCOME or OUTCOME", index)
+		}
+		if categoryRule.Pattern != "" {
+			if err := validation.ValidatePayeeRule(categoryRule.MatchType, categoryRule.Pattern); err != nil {
+				return fmt.Errorf("category_rule %d: %v", index, err)
+			}
+		}
+		if err := validation.ValidateAmountRange(categoryRule.MinAmount, categoryRule.MaxAmount); err != nil {
+			return fmt.Errorf("category_rule %d: %v", index, err)
+		}
+		if err := validation.ValidateID(categoryRule.CategoryId); err != nil {
+			return fmt.Errorf("category_rule %d: category %v", index, err)
+		}
+		if !categoryIds[categoryRule.CategoryId] {
+			util.Logger.Warnw("category rule refers to a category missing from backup",
+				"category_rule_id", categoryRule.Id, "category_id", categoryRule.CategoryId)
+		}
+		if categoryRule.PayeeId != "" {
+			if err := validation.ValidateID(categoryRule.PayeeId); err != nil {
+				return fmt.Errorf("category_rule %d: payee %v", index, err)
+			}
+			if !payeeIds[categoryRule.PayeeId] {
+				util.Logger.Warnw("category rule refers to a payee missing from backup",
+					"category_rule_id", categoryRule.Id, "payee_id", categoryRule.PayeeId)
+			}
+		}
+		if categoryRule.AccountId != "" {
+			if err := validation.ValidateID(categoryRule.AccountId); err != nil {
+				return fmt.Errorf("category_rule %d: account %v", index, err)
+			}
+			if !accountIds[categoryRule.AccountId] {
+				util.Logger.Warnw("category rule refers to an account missing from backup",
+					"category_rule_id", categoryRule.Id, "account_id", categoryRule.AccountId)
+			}
+		}
+	}
+	return validateAccessData(backup)
+}
+
+// validateAccessData checks users, api tokens and ledgers, backups older than 1.12.0 carry none of them
+func validateAccessData(backup *BackupData) error {
+	if backup.Users == nil {
+		if len(backup.ApiTokens) > 0 || len(backup.Ledgers) > 0 ||
+			len(backup.LedgerMembers) > 0 || len(backup.LedgerInvitations) > 0 {
+			return errors.New("backup has api tokens or ledgers but no users")
+		}
+		return nil
+	}
+
+	userIds := make(map[string]bool)
+	usernames := make(map[string]bool)
+	for index, user := range backup.Users {
+		if err := validation.ValidateID(user.Id); err != nil {
+			return fmt.Errorf("user %d: %v", index, err)
+		}
+		if userIds[user.Id] {
+			return fmt.Errorf("user %d: duplicated id %s", index, user.Id)
+		}
+		userIds[user.Id] = true
+		if err := validation.ValidateUsername(user.Username); err != nil {
+			return fmt.Errorf("user %d: %v", index, err)
+		}
+		if usernames[user.Username] {
+			return fmt.Errorf("user %d: duplicated username %s", index, user.Username)
+		}
+		usernames[user.Username] = true
+		if user.PasswordHash == "" {
+			return fmt.Errorf("user %d: password hash cannot be empty", index)
+		}
+	}
+
+	apiTokenIds := make(map[string]bool)
+	apiTokenHashes := make(map[string]bool)
+	for index, apiToken := range backup.ApiTokens {
+		if err := validation.ValidateID(apiToken.Id); err != nil {
+			return fmt.Errorf("api token %d: %v", index, err)
+		}
+		if apiTokenIds[apiToken.Id] {
+			return fmt.Errorf("api token %d: duplicated id %s", index, apiToken.Id)
+		}
+		apiTokenIds[apiToken.Id] = true
+		if apiToken.TokenHash == "" {
+			return fmt.Errorf("api token %d: token hash cannot be empty", index)
+		}
+		if apiTokenHashes[apiToken.TokenHash] {
+			return fmt.Errorf("api token %d: duplicated token hash", index)
+		}
+		apiTokenHashes[apiToken.TokenHash] = true
+		if err := validation.ValidateID(apiToken.UserId); err != nil {
+			return fmt.Errorf("api token %d: user %v", index, err)
+		}
+		if !userIds[apiToken.UserId] {
+			util.Logger.Warnw("api token refers to a user missing from backup",
+				"api_token_id", apiToken.Id, "user_id", apiToken.UserId)
+		}
+	}
+
+	ledgerIds := make(map[string]bool)
+	for index, ledger := range backup.Ledgers {
+		if err := validation.ValidateID(ledger.Id); err != nil {
+			return fmt.Errorf("ledger %d: %v", index, err)
+		}
+		if ledgerIds[ledger.Id] {
+			return fmt.Errorf("ledger %d: duplicated id %s", index, ledger.Id)
+		}
+		ledgerIds[ledger.Id] = true
+		if err := validation.ValidateLedgerName(ledger.Name); err != nil {
+			return fmt.Errorf("ledger %d: %v", index, err)
+		}
+	}
+
+	ledgerMemberIds := make(map[string]bool)
+	memberships := make(map[string]bool)
+	for index, ledgerMember := range backup.LedgerMembers {
+		if err := validation.ValidateID(ledgerMember.Id); err != nil {
+			return fmt.Errorf("ledger member %d: %v", index, err)
+		}
+		if ledgerMemberIds[ledgerMember.Id] {
+			return fmt.Errorf("ledger member %d: duplicated id %s", index, ledgerMember.Id)
+		}
+		ledgerMemberIds[ledgerMember.Id] = true
+		if err := validation.ValidateID(ledgerMember.LedgerId); err != nil {
+			return fmt.Errorf("ledger member %d: ledger %v", index, err)
+		}
+		if err := validation.ValidateID(ledgerMember.UserId); err != nil {
+			return fmt.Errorf("ledger member %d: user %v", index, err)
+		}
+		if err := validation.ValidateLedgerRole(ledgerMember.Role); err != nil {
+			return fmt.Errorf("ledger member %d: %v", index, err)
+		}
+		if memberships[ledgerMember.LedgerId+"/"+ledgerMember.UserId] {
+			return fmt.Errorf("ledger member %d: user %s joins ledger %s twice", index, ledgerMember.UserId, ledgerMember.LedgerId)
+		}
+		memberships[ledgerMember.LedgerId+"/"+ledgerMember.UserId] = true
+		if !ledgerIds[ledgerMember.LedgerId] {
+			util.Logger.Warnw("ledger member refers to a ledger missing from backup",
+				"ledger_member_id", ledgerMember.Id, "ledger_id", ledgerMember.LedgerId)
+		}
+		if !userIds[ledgerMember.UserId] {
+			util.Logger.Warnw("ledger member refers to a user missing from backup",
+				"ledger_member_id", ledgerMember.Id, "user_id", ledgerMember.UserId)
+		}
+	}
+
+	ledgerInvitationIds := make(map[string]bool)
+	ledgerInvitationHashes := make(map[string]bool)
+	for index, ledgerInvitation := range backup.LedgerInvitations {
+		if err := validation.ValidateID(ledgerInvitation.Id); err != nil {
+			return fmt.Errorf("ledger invitation %d: %v", index, err)
+		}
+		if ledgerInvitationIds[ledgerInvitation.Id] {
+			return fmt.Errorf("ledger invitation %d: duplicated id %s", index, ledgerInvitation.Id)
+		}
+		ledgerInvitationIds[ledgerInvitation.Id] = true
+		if ledgerInvitation.TokenHash == "" {
+			return fmt.Errorf("ledger invitation %d: token hash cannot be empty", index)
+		}
+		if ledgerInvitationHashes[ledgerInvitation.TokenHash] {
+			return fmt.Errorf("ledger invitation %d: duplicated token hash", index)
+		}
+		ledgerInvitationHashes[ledgerInvitation.TokenHash] = true
+		if err := validation.ValidateID(ledgerInvitation.LedgerId); err != nil {
+			return fmt.Errorf("ledger invitation %d: ledger %v", index, err)
+		}
+		if err := validation.ValidateLedgerRole(ledgerInvitation.Role); err != nil {
+			return fmt.Errorf("ledger invitation %d: %v", index, err)
+		}
+		if ledgerInvitation.InvitedBy != "" {
+			if err := validation.ValidateID(ledgerInvitation.InvitedBy); err != nil {
+				return fmt.Errorf("ledger invitation %d: invited_by %v", index, err)
+			}
+		}
+		if !ledgerIds[ledgerInvitation.LedgerId] {
+			util.Logger.Warnw("ledger invitation refers to a ledger missing from backup",
+				"ledger_invitation_id", ledgerInvitation.Id, "ledger_id", ledgerInvitation.LedgerId)
+		}
+	}
+	return nil
+}
+
+// parseVersion reads a major.minor.patch version
+func parseVersion(version string) ([3]int, bool) {
+	var parsedVersion [3]int
+	parts := strings.Split(version, ".")
+	if len(parts) != len(parsedVersion) {
+		return parsedVersion, false
+	}
+	for index, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return parsedVersion, false
+		}
+		parsedVersion[index] = number
+	}
+	return parsedVersion, true
+}
+
+// isVersionNewer tells whether version comes after baseVersion
+func isVersionNewer(version, baseVersion [3]int) bool {
+	for index := range version {
+		if version[index] != baseVersion[index] {
+			return version[index] > baseVersion[index]
+		}
+	}
+	return false
+}
+
+func collectSnapshot() (*BackupData, error) {
+	categories, err := collectCategories()
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := collectAccounts()
+	if err != nil {
+		return nil, err
+	}
+	cashFlows, err := collectCashFlows()
+	if err != nil {
+		return nil, err
+	}
+	exchangeRates, err := collectExchangeRates()
+	if err != nil {
+		return nil, err
+	}
+	recurringRules, err := collectRecurringRules()
+	if err != nil {
+		return nil, err
+	}
+	budgets, err := collectBudgets()
+	if err != nil {
+		return nil, err
+	}
+	goals, err := collectGoals()
+	if err != nil {
+		return nil, err
+	}
+	payees, err := collectPayees()
+	if err != nil {
+		return nil, err
+	}
+	categoryRules, err := collectCategoryRules()
+	if err != nil {
+		return nil, err
+	}
+	users, err := collectUsers()
+	if err != nil {
+		return nil, err
+	}
+	apiTokens, err := collectApiTokens()
+	if err != nil {
+		return nil, err
+	}
+	ledgers, err := collectLedgers()
+	if err != nil {
+		return nil, err
+	}
+	ledgerMembers, err := collectLedgerMembers()
+	if err != nil {
+		return nil, err
+	}
+	ledgerInvitations, err := collectLedgerInvitations()
+	if err != nil {
+		return nil, err
+	}
+	return &BackupData{
+		Version:        BackupVersion,
+		CashFlows:      cashFlows,
+		Categories:     categories,
+		Accounts:       accounts,
+		ExchangeRates:  exchangeRates,
+		RecurringRules: recurringRules,
+		Budgets:        budgets,
+		Goals:          goals,
+		Payees:         payees,
+		CategoryRules:  categoryRules,
+
+		Users:             users,
+		ApiTokens:         apiTokens,
+		Ledgers:           ledgers,
+		LedgerMembers:     ledgerMembers,
+		LedgerInvitations: ledgerInvitations,
+	}, nil
+}
+
+func (run *restoreRun) apply(backup *BackupData) (err error) {
+	// mapper helpers panic on driver errors, surface them as a failed restore instead
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+	}()
+
+	// Backups older than 1.12.0 carry no access data, existing users and ledgers are kept then
+	hasAccessData := backup.Users != nil
+	if !hasAccessData {
+		util.Logger.Warnln("backup has no users, api tokens or ledgers, keeping the existing ones")
+	}
+
+	if run.result.Mode == RestoreModeReplace {
+		if err := run.clearExistingData(hasAccessData); err != nil {
+			return err
+		}
+	}
+
+	if hasAccessData {
+		if err := run.restoreAccessData(backup); err != nil {
+			return err
+		}
+	}
+
+	categoryIdMapping, err := run.restoreCategories(convertBackup2CategoryEntities(backup.Categories))
+	if err != nil {
+		return err
+	}
+	accountIdMapping, err := run.restoreAccounts(convertBackup2AccountEntities(backup.Accounts))
+	if err != nil {
+		return err
+	}
+	payeeIdMapping, err := run.restorePayees(convertBackup2PayeeEntities(backup.Payees))
+	if err != nil {
+		return err
+	}
+	if err := run.restoreCashFlows(convertBackup2CashFlowEntities(backup.CashFlows),
+		categoryIdMapping, accountIdMapping, payeeIdMapping); err != nil {
 		return err
 	}
+	if err := run.restoreExchangeRates(convertBackup2ExchangeRateEntities(backup.ExchangeRates)); err != nil {
+		return err
+	}
+	if err := run.restoreRecurringRules(convertBackup2RecurringRuleEntities(backup.RecurringRules),
+		categoryIdMapping, accountIdMapping); err != nil {
+		return err
+	}
+	if err := run.restoreBudgets(convertBackup2BudgetEntities(backup.Budgets), categoryIdMapping); err != nil {
+		return err
+	}
+	if err := run.restoreGoals(convertBackup2GoalEntities(backup.Goals), accountIdMapping); err != nil {
+		return err
+	}
+	return run.restoreCategoryRules(convertBackup2CategoryRuleEntities(backup.CategoryRules),
+		categoryIdMapping, accountIdMapping, payeeIdMapping)
+}
 
-	// Note: To properly implement this, we need:
-	// 1. Clear existing data (ResetDatabase)
-	// 2. Insert categories from backup
-	// 3. Insert cash flows from backup
-	// 4. Handle errors and rollback if needed
+func (run *restoreRun) clearExistingData(clearAccessData bool) error {
+	deletedCategoryRules, err := category_rule_mapper.INSTANCE.DeleteAllCategoryRules()
+	run.result.CategoryRulesCleared = int(deletedCategoryRules)
+	if err != nil {
+		return err
+	}
 
-	// TODO: Implement actual data restoration when:
-	//   - ResetDatabase is implemented
-	//   - Bulk insert methods are available in mappers
+	deletedGoals, err := goal_mapper.INSTANCE.DeleteAllGoals()
+	run.result.GoalsCleared = int(deletedGoals)
+	if err != nil {
+		return err
+	}
 
-	return errors.New("restore functionality requires mapper enhancement and ResetDatabase implementation")
+	deletedBudgets, err := budget_mapper.INSTANCE.DeleteAllBudgets()
+	run.result.BudgetsCleared = int(deletedBudgets)
+	if err != nil {
+		return err
+	}
+
+	deletedRecurringRules, err := recurring_rule_mapper.INSTANCE.DeleteAllRecurringRules()
+	run.result.RecurringRulesCleared = int(deletedRecurringRules)
+	if err != nil {
+		return err
+	}
+
+	deletedCashFlows, err := cash_flow_mapper.INSTANCE.DeleteAllCashFlows()
+	run.result.CashFlowsCleared = int(deletedCashFlows)
+	if err != nil {
+		return err
+	}
+
+	deletedPayees, err := payee_mapper.INSTANCE.DeleteAllPayees()
+	run.result.PayeesCleared = int(deletedPayees)
+	if err != nil {
+		return err
+	}
+
+	deletedCategories, err := category_mapper.INSTANCE.DeleteAllCategories()
+	run.result.CategoriesCleared = int(deletedCategories)
+	if err != nil {
+		return err
+	}
+
+	deletedAccounts, err := account_mapper.INSTANCE.DeleteAllAccounts()
+	run.result.AccountsCleared = int(deletedAccounts)
+	if err != nil {
+		return err
+	}
+
+	deletedExchangeRates, err := exchange_rate_mapper.INSTANCE.DeleteAllExchangeRates()
+	run.result.ExchangeRatesCleared = int(deletedExchangeRates)
+	if err != nil || !clearAccessData {
+		return err
+	}
+
+	deletedApiTokens, err := api_token_mapper.INSTANCE.DeleteAllApiTokens()
+	run.result.ApiTokensCleared = int(deletedApiTokens)
+	if err != nil {
+		return err
+	}
+
+	// Members and invitations go with their ledgers, the snapshot tells how many there were
+	deletedLedgers, err := ledger_mapper.INSTANCE.DeleteAllLedgers()
+	if err != nil {
+		return err
+	}
+	run.result.LedgersCleared = int(deletedLedgers)
+	run.result.LedgerMembersCleared = len(run.snapshot.LedgerMembers)
+	run.result.LedgerInvitationsCleared = len(run.snapshot.LedgerInvitations)
+
+	deletedUsers, err := user_mapper.INSTANCE.DeleteAllUsers()
+	run.result.UsersCleared = int(deletedUsers)
+	return err
+}
+
+// restoreAccessData inserts users, ledgers with their members and invitations, then api tokens;
+// in merge mode a user whose name is taken by another id is refused, as its records would change hands.
+func (run *restoreRun) restoreAccessData(backup *BackupData) error {
+	if err := run.restoreUsers(convertBackup2UserEntities(backup.Users)); err != nil {
+		return err
+	}
+	if err := run.restoreLedgers(convertBackup2LedgerEntities(backup.Ledgers)); err != nil {
+		return err
+	}
+	if err := run.restoreLedgerMembers(convertBackup2LedgerMemberEntities(backup.LedgerMembers)); err != nil {
+		return err
+	}
+	if err := run.restoreLedgerInvitations(convertBackup2LedgerInvitationEntities(backup.LedgerInvitations)); err != nil {
+		return err
+	}
+	return run.restoreApiTokens(convertBackup2ApiTokenEntities(backup.ApiTokens))
+}
+
+func (run *restoreRun) restoreUsers(users []model.UserEntity) error {
+	existingIds := make(map[primitive.ObjectID]bool)
+	existingIdsByUsername := make(map[string]primitive.ObjectID)
+	if run.result.Mode == RestoreModeMerge {
+		for _, user := range convertBackup2UserEntities(run.snapshot.Users) {
+			existingIds[user.Id] = true
+			existingIdsByUsername[user.Username] = user.Id
+		}
+	}
+
+	var pendingUsers []model.UserEntity
+	for _, user := range users {
+		if existingIds[user.Id] {
+			run.result.UsersSkipped++
+			continue
+		}
+		if existingId, ok := existingIdsByUsername[user.Username]; ok {
+			return fmt.Errorf("user %s already exists with id %s, not %s", user.Username, existingId.Hex(), user.Id.Hex())
+		}
+		pendingUsers = append(pendingUsers, user)
+	}
+
+	for start := 0; start < len(pendingUsers); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingUsers) {
+			end = len(pendingUsers)
+		}
+		batch := pendingUsers[start:end]
+		for _, user := range batch {
+			run.insertedUserIds = append(run.insertedUserIds, user.Id)
+		}
+		if _, err := user_mapper.INSTANCE.BulkInsertUsers(batch); err != nil {
+			return err
+		}
+		run.result.UsersRestored += len(batch)
+	}
+	return nil
+}
+
+func (run *restoreRun) restoreLedgers(ledgers []model.LedgerEntity) error {
+	existingIds := make(map[primitive.ObjectID]bool)
+	if run.result.Mode == RestoreModeMerge {
+		for _, ledger := range run.snapshot.Ledgers {
+			existingIds[util.Convert2ObjectId(ledger.Id)] = true
+		}
+	}
+
+	var pendingLedgers []model.LedgerEntity
+	for _, ledger := range ledgers {
+		if existingIds[ledger.Id] {
+			run.result.LedgersSkipped++
+			continue
+		}
+		pendingLedgers = append(pendingLedgers, ledger)
+	}
+
+	for start := 0; start < len(pendingLedgers); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingLedgers) {
+			end = len(pendingLedgers)
+		}
+		batch := pendingLedgers[start:end]
+		for _, ledger := range batch {
+			run.insertedLedgerIds = append(run.insertedLedgerIds, ledger.Id)
+		}
+		if _, err := ledger_mapper.INSTANCE.BulkInsertLedgers(batch); err != nil {
+			return err
+		}
+		run.result.LedgersRestored += len(batch)
+	}
+	return nil
+}
+
+// restoreLedgerMembers inserts memberships; in merge mode a member is skipped when one with
+// the same id exists or the user already belongs to the ledger, its current role is kept.
+func (run *restoreRun) restoreLedgerMembers(ledgerMembers []model.LedgerMemberEntity) error {
+	existingIds := make(map[primitive.ObjectID]bool)
+	existingMemberships := make(map[string]bool)
+	if run.result.Mode == RestoreModeMerge {
+		for _, ledgerMember := range convertBackup2LedgerMemberEntities(run.snapshot.LedgerMembers) {
+			existingIds[ledgerMember.Id] = true
+			existingMemberships[ledgerMember.LedgerId.Hex()+"/"+ledgerMember.UserId.Hex()] = true
+		}
+	}
+
+	var pendingLedgerMembers []model.LedgerMemberEntity
+	for _, ledgerMember := range ledgerMembers {
+		if existingIds[ledgerMember.Id] || existingMemberships[ledgerMember.LedgerId.Hex()+"/"+ledgerMember.UserId.Hex()] {
+			run.result.LedgerMembersSkipped++
+			continue
+		}
+		pendingLedgerMembers = append(pendingLedgerMembers, ledgerMember)
+	}
+
+	for start := 0; start < len(pendingLedgerMembers); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingLedgerMembers) {
+			end = len(pendingLedgerMembers)
+		}
+		batch := pendingLedgerMembers[start:end]
+		run.insertedLedgerMembers = append(run.insertedLedgerMembers, batch...)
+		if _, err := ledger_mapper.INSTANCE.BulkInsertLedgerMembers(batch); err != nil {
+			return err
+		}
+		run.result.LedgerMembersRestored += len(batch)
+	}
+	return nil
+}
+
+func (run *restoreRun) restoreLedgerInvitations(ledgerInvitations []model.LedgerInvitationEntity) error {
+	existingIds := make(map[primitive.ObjectID]bool)
+	existingHashes := make(map[string]bool)
+	if run.result.Mode == RestoreModeMerge {
+		for _, ledgerInvitation := range run.snapshot.LedgerInvitations {
+			existingIds[util.Convert2ObjectId(ledgerInvitation.Id)] = true
+			existingHashes[ledgerInvitation.TokenHash] = true
+		}
+	}
+
+	var pendingLedgerInvitations []model.LedgerInvitationEntity
+	for _, ledgerInvitation := range ledgerInvitations {
+		if existingIds[ledgerInvitation.Id] || existingHashes[ledgerInvitation.TokenHash] {
+			run.result.LedgerInvitationsSkipped++
+			continue
+		}
+		pendingLedgerInvitations = append(pendingLedgerInvitations, ledgerInvitation)
+	}
+
+	for start := 0; start < len(pendingLedgerInvitations); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingLedgerInvitations) {
+			end = len(pendingLedgerInvitations)
+		}
+		batch := pendingLedgerInvitations[start:end]
+		run.insertedLedgerInvitations = append(run.insertedLedgerInvitations, batch...)
+		if _, err := ledger_mapper.INSTANCE.BulkInsertLedgerInvitations(batch); err != nil {
+			return err
+		}
+		run.result.LedgerInvitationsRestored += len(batch)
+	}
+	return nil
+}
+
+func (run *restoreRun) restoreApiTokens(apiTokens []model.ApiTokenEntity) error {
+	existingIds := make(map[primitive.ObjectID]bool)
+	existingHashes := make(map[string]bool)
+	if run.result.Mode == RestoreModeMerge {
+		for _, apiToken := range run.snapshot.ApiTokens {
+			existingIds[util.Convert2ObjectId(apiToken.Id)] = true
+			existingHashes[apiToken.TokenHash] = true
+		}
+	}
+
+	var pendingApiTokens []model.ApiTokenEntity
+	for _, apiToken := range apiTokens {
+		if existingIds[apiToken.Id] || existingHashes[apiToken.TokenHash] {
+			run.result.ApiTokensSkipped++
+			continue
+		}
+		pendingApiTokens = append(pendingApiTokens, apiToken)
+	}
+
+	for start := 0; start < len(pendingApiTokens); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingApiTokens) {
+			end = len(pendingApiTokens)
+		}
+		batch := pendingApiTokens[start:end]
+		run.insertedApiTokens = append(run.insertedApiTokens, batch...)
+		if _, err := api_token_mapper.INSTANCE.BulkInsertApiTokens(batch); err != nil {
+			return err
+		}
+		run.result.ApiTokensRestored += len(batch)
+	}
+	return nil
+}
+
+// restoreCategories inserts categories parents-first and returns how backup ids map onto
+// database ids; in merge mode a category may resolve to an existing one of the same owner with the same name.
+func (run *restoreRun) restoreCategories(categories []model.CategoryEntity) (map[primitive.ObjectID]primitive.ObjectID, error) {
+	idMapping := make(map[primitive.ObjectID]primitive.ObjectID)
+
+	existingIds := make(map[primitive.ObjectID]bool)
+	existingIdsByName := make(map[string]primitive.ObjectID)
+	if run.result.Mode == RestoreModeMerge {
+		for _, category := range convertBackup2CategoryEntities(run.snapshot.Categories) {
+			existingIds[category.Id] = true
+			existingIdsByName[category.OwnerId.Hex()+"/"+category.Name] = category.Id
+		}
+	}
+
+	var pendingCategories []model.CategoryEntity
+	for _, category := range sortCategoriesByHierarchy(categories) {
+		if existingIds[category.Id] {
+			idMapping[category.Id] = category.Id
+			run.result.CategoriesSkipped++
+			continue
+		}
+		if existingId, ok := existingIdsByName[category.OwnerId.Hex()+"/"+category.Name]; ok {
+			idMapping[category.Id] = existingId
+			run.result.CategoriesSkipped++
+			continue
+		}
+
+		idMapping[category.Id] = category.Id
+		if mappedParentId, ok := idMapping[category.ParentId]; ok {
+			category.ParentId = mappedParentId
+		}
+		pendingCategories = append(pendingCategories, category)
+	}
+
+	for start := 0; start < len(pendingCategories); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingCategories) {
+			end = len(pendingCategories)
+		}
+		batch := pendingCategories[start:end]
+		for _, category := range batch {
+			run.insertedCategoryIds = append(run.insertedCategoryIds, category.Id)
+		}
+		if _, err := category_mapper.INSTANCE.BulkInsertCategories(batch); err != nil {
+			return nil, err
+		}
+		run.result.CategoriesRestored += len(batch)
+	}
+	return idMapping, nil
+}
+
+// restoreAccounts inserts accounts and returns how backup ids map onto database ids;
+// in merge mode an account may resolve to an existing one of the same owner with the same name.
+func (run *restoreRun) restoreAccounts(accounts []model.AccountEntity) (map[primitive.ObjectID]primitive.ObjectID, error) {
+	idMapping := make(map[primitive.ObjectID]primitive.ObjectID)
+
+	existingIds := make(map[primitive.ObjectID]bool)
+	existingIdsByName := make(map[string]primitive.ObjectID)
+	if run.result.Mode == RestoreModeMerge {
+		for _, account := range convertBackup2AccountEntities(run.snapshot.Accounts) {
+			existingIds[account.Id] = true
+			existingIdsByName[account.OwnerId.Hex()+"/"+account.Name] = account.Id
+		}
+	}
+
+	var pendingAccounts []model.AccountEntity
+	for _, account := range accounts {
+		if existingIds[account.Id] {
+			idMapping[account.Id] = account.Id
+			run.result.AccountsSkipped++
+			continue
+		}
+		if existingId, ok := existingIdsByName[account.OwnerId.Hex()+"/"+account.Name]; ok {
+			idMapping[account.Id] = existingId
+			run.result.AccountsSkipped++
+			continue
+		}
+
+		idMapping[account.Id] = account.Id
+		pendingAccounts = append(pendingAccounts, account)
+	}
+
+	for start := 0; start < len(pendingAccounts); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingAccounts) {
+			end = len(pendingAccounts)
+		}
+		batch := pendingAccounts[start:end]
+		for _, account := range batch {
+			run.insertedAccountIds = append(run.insertedAccountIds, account.Id)
+		}
+		if _, err := account_mapper.INSTANCE.BulkInsertAccounts(batch); err != nil {
+			return nil, err
+		}
+		run.result.AccountsRestored += len(batch)
+	}
+	return idMapping, nil
+}
+
+// restorePayees inserts payees with their rules and returns how backup ids map onto
+// database ids; in merge mode a payee may resolve to an existing one of the same owner with the same name.
+func (run *restoreRun) restorePayees(payees []model.PayeeEntity) (map[primitive.ObjectID]primitive.ObjectID, error) {
+	idMapping := make(map[primitive.ObjectID]primitive.ObjectID)
+
+	existingIds := make(map[primitive.ObjectID]bool)
+	existingIdsByName := make(map[string]primitive.ObjectID)
+	if run.result.Mode == RestoreModeMerge {
+		for _, payee := range convertBackup2PayeeEntities(run.snapshot.Payees) {
+			existingIds[payee.Id] = true
+			existingIdsByName[payee.OwnerId.Hex()+"/"+payee.Name] = payee.Id
+		}
+	}
+
+	var pendingPayees []model.PayeeEntity
+	for _, payee := range payees {
+		if existingIds[payee.Id] {
+			idMapping[payee.Id] = payee.Id
+			run.result.PayeesSkipped++
+			continue
+		}
+		if existingId, ok := existingIdsByName[payee.OwnerId.Hex()+"/"+payee.Name]; ok {
+			idMapping[payee.Id] = existingId
+			run.result.PayeesSkipped++
+			continue
+		}
+
+		idMapping[payee.Id] = payee.Id
+		pendingPayees = append(pendingPayees, payee)
+	}
+
+	for start := 0; start < len(pendingPayees); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingPayees) {
+			end = len(pendingPayees)
+		}
+		batch := pendingPayees[start:end]
+		for _, payee := range batch {
+			run.insertedPayeeIds = append(run.insertedPayeeIds, payee.Id)
+		}
+		if _, err := payee_mapper.INSTANCE.BulkInsertPayees(batch); err != nil {
+			return nil, err
+		}
+		run.result.PayeesRestored += len(batch)
+	}
+	return idMapping, nil
+}
+
+func (run *restoreRun) restoreCashFlows(cashFlows []model.CashFlowEntity,
+	categoryIdMapping, accountIdMapping, payeeIdMapping map[primitive.ObjectID]primitive.ObjectID) error {
+
+	existingIds := make(map[primitive.ObjectID]bool)
+	if run.result.Mode == RestoreModeMerge {
+		for _, cashFlow := range convertBackup2CashFlowEntities(run.snapshot.CashFlows) {
+			existingIds[cashFlow.Id] = true
+		}
+	}
+
+	var pendingCashFlows []model.CashFlowEntity
+	for _, cashFlow := range cashFlows {
+		if existingIds[cashFlow.Id] {
+			run.result.CashFlowsSkipped++
+			continue
+		}
+		if mappedCategoryId, ok := categoryIdMapping[cashFlow.CategoryId]; ok {
+			cashFlow.CategoryId = mappedCategoryId
+		}
+		for i, split := range cashFlow.Splits {
+			if mappedCategoryId, ok := categoryIdMapping[split.CategoryId]; ok {
+				cashFlow.Splits[i].CategoryId = mappedCategoryId
+			}
+		}
+		if mappedAccountId, ok := accountIdMapping[cashFlow.AccountId]; ok {
+			cashFlow.AccountId = mappedAccountId
+		}
+		if mappedPayeeId, ok := payeeIdMapping[cashFlow.PayeeId]; ok {
+			cashFlow.PayeeId = mappedPayeeId
+		}
+		pendingCashFlows = append(pendingCashFlows, cashFlow)
+	}
+
+	for start := 0; start < len(pendingCashFlows); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingCashFlows) {
+			end = len(pendingCashFlows)
+		}
+		batch := pendingCashFlows[start:end]
+		for _, cashFlow := range batch {
+			run.insertedCashFlowIds = append(run.insertedCashFlowIds, cashFlow.Id)
+		}
+		if _, err := cash_flow_mapper.INSTANCE.BulkInsertCashFlows(batch); err != nil {
+			return err
+		}
+		run.result.CashFlowsRestored += len(batch)
+	}
+	return nil
+}
+
+// restoreExchangeRates inserts exchange rates; in merge mode a rate is skipped
+// when one with the same id, or of the same owner with the same pair and effective date, already exists.
+func (run *restoreRun) restoreExchangeRates(exchangeRates []model.ExchangeRateEntity) error {
+	existingIds := make(map[primitive.ObjectID]bool)
+	existingPairDates := make(map[string]bool)
+	if run.result.Mode == RestoreModeMerge {
+		for _, exchangeRate := range convertBackup2ExchangeRateEntities(run.snapshot.ExchangeRates) {
+			existingIds[exchangeRate.Id] = true
+			existingPairDates[exchangeRatePairDateKey(exchangeRate)] = true
+		}
+	}
+
+	var pendingExchangeRates []model.ExchangeRateEntity
+	for _, exchangeRate := range exchangeRates {
+		if existingIds[exchangeRate.Id] || existingPairDates[exchangeRatePairDateKey(exchangeRate)] {
+			run.result.ExchangeRatesSkipped++
+			continue
+		}
+		pendingExchangeRates = append(pendingExchangeRates, exchangeRate)
+	}
+
+	for start := 0; start < len(pendingExchangeRates); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingExchangeRates) {
+			end = len(pendingExchangeRates)
+		}
+		batch := pendingExchangeRates[start:end]
+		for _, exchangeRate := range batch {
+			run.insertedExchangeRateIds = append(run.insertedExchangeRateIds, exchangeRate.Id)
+		}
+		if _, err := exchange_rate_mapper.INSTANCE.BulkInsertExchangeRates(batch); err != nil {
+			return err
+		}
+		run.result.ExchangeRatesRestored += len(batch)
+	}
+	return nil
+}
+
+// restoreRecurringRules inserts recurring rules with their booking progress;
+// in merge mode a rule is skipped when one with the same id, or of the same owner with the same name, already exists.
+func (run *restoreRun) restoreRecurringRules(recurringRules []model.RecurringRuleEntity,
+	categoryIdMapping, accountIdMapping map[primitive.ObjectID]primitive.ObjectID) error {
+
+	existingIds := make(map[primitive.ObjectID]bool)
+	existingNames := make(map[string]bool)
+	if run.result.Mode == RestoreModeMerge {
+		for _, recurringRule := range run.snapshot.RecurringRules {
+			existingIds[util.Convert2ObjectId(recurringRule.Id)] = true
+			existingNames[util.ConvertOwner2ObjectId(recurringRule.OwnerId).Hex()+"/"+recurringRule.Name] = true
+		}
+	}
+
+	var pendingRecurringRules []model.RecurringRuleEntity
+	for _, recurringRule := range recurringRules {
+		if existingIds[recurringRule.Id] || existingNames[recurringRule.OwnerId.Hex()+"/"+recurringRule.Name] {
+			run.result.RecurringRulesSkipped++
+			continue
+		}
+		if mappedCategoryId, ok := categoryIdMapping[recurringRule.CategoryId]; ok {
+			recurringRule.CategoryId = mappedCategoryId
+		}
+		if mappedAccountId, ok := accountIdMapping[recurringRule.AccountId]; ok {
+			recurringRule.AccountId = mappedAccountId
+		}
+		pendingRecurringRules = append(pendingRecurringRules, recurringRule)
+	}
+
+	for start := 0; start < len(pendingRecurringRules); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingRecurringRules) {
+			end = len(pendingRecurringRules)
+		}
+		batch := pendingRecurringRules[start:end]
+		for _, recurringRule := range batch {
+			run.insertedRecurringRuleIds = append(run.insertedRecurringRuleIds, recurringRule.Id)
+		}
+		if _, err := recurring_rule_mapper.INSTANCE.BulkInsertRecurringRules(batch); err != nil {
+			return err
+		}
+		run.result.RecurringRulesRestored += len(batch)
+	}
+	return nil
+}
+
+// restoreBudgets inserts budgets; in merge mode a budget is skipped when one with the
+// same id, or for the same category and period, already exists.
+func (run *restoreRun) restoreBudgets(budgets []model.BudgetEntity,
+	categoryIdMapping map[primitive.ObjectID]primitive.ObjectID) error {
+
+	existingIds := make(map[primitive.ObjectID]bool)
+	existingKeys := make(map[string]bool)
+	if run.result.Mode == RestoreModeMerge {
+		for _, budget := range run.snapshot.Budgets {
+			existingIds[util.Convert2ObjectId(budget.Id)] = true
+			existingKeys[budget.CategoryId+"/"+budget.Period] = true
+		}
+	}
+
+	var pendingBudgets []model.BudgetEntity
+	for _, budget := range budgets {
+		if mappedCategoryId, ok := categoryIdMapping[budget.CategoryId]; ok {
+			budget.CategoryId = mappedCategoryId
+		}
+		if existingIds[budget.Id] || existingKeys[budget.CategoryId.Hex()+"/"+budget.Period] {
+			run.result.BudgetsSkipped++
+			continue
+		}
+		pendingBudgets = append(pendingBudgets, budget)
+	}
+
+	for start := 0; start < len(pendingBudgets); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingBudgets) {
+			end = len(pendingBudgets)
+		}
+		batch := pendingBudgets[start:end]
+		for _, budget := range batch {
+			run.insertedBudgetIds = append(run.insertedBudgetIds, budget.Id)
+		}
+		if _, err := budget_mapper.INSTANCE.BulkInsertBudgets(batch); err != nil {
+			return err
+		}
+		run.result.BudgetsRestored += len(batch)
+	}
+	return nil
+}
+
+// restoreGoals inserts goals; in merge mode a goal is skipped when one with the
+// same id, or of the same owner with the same name, already exists.
+func (run *restoreRun) restoreGoals(goals []model.GoalEntity,
+	accountIdMapping map[primitive.ObjectID]primitive.ObjectID) error {
+
+	existingIds := make(map[primitive.ObjectID]bool)
+	existingNames := make(map[string]bool)
+	if run.result.Mode == RestoreModeMerge {
+		for _, goal := range run.snapshot.Goals {
+			existingIds[util.Convert2ObjectId(goal.Id)] = true
+			existingNames[util.ConvertOwner2ObjectId(goal.OwnerId).Hex()+"/"+goal.Name] = true
+		}
+	}
+
+	var pendingGoals []model.GoalEntity
+	for _, goal := range goals {
+		if mappedAccountId, ok := accountIdMapping[goal.AccountId]; ok {
+			goal.AccountId = mappedAccountId
+		}
+		if existingIds[goal.Id] || existingNames[goal.OwnerId.Hex()+"/"+goal.Name] {
+			run.result.GoalsSkipped++
+			continue
+		}
+		pendingGoals = append(pendingGoals, goal)
+	}
+
+	for start := 0; start < len(pendingGoals); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingGoals) {
+			end = len(pendingGoals)
+		}
+		batch := pendingGoals[start:end]
+		for _, goal := range batch {
+			run.insertedGoalIds = append(run.insertedGoalIds, goal.Id)
+		}
+		if _, err := goal_mapper.INSTANCE.BulkInsertGoals(batch); err != nil {
+			return err
+		}
+		run.result.GoalsRestored += len(batch)
+	}
+	return nil
+}
+
+// restoreCategoryRules inserts categorization rules; in merge mode a rule is skipped
+// when one with the same id, or of the same owner with the same name, already exists.
+func (run *restoreRun) restoreCategoryRules(categoryRules []model.CategoryRuleEntity,
+	categoryIdMapping, accountIdMapping, payeeIdMapping map[primitive.ObjectID]primitive.ObjectID) error {
+
+	existingIds := make(map[primitive.ObjectID]bool)
+	existingNames := make(map[string]bool)
+	if run.result.Mode == RestoreModeMerge {
+		for _, categoryRule := range run.snapshot.CategoryRules {
+			existingIds[util.Convert2ObjectId(categoryRule.Id)] = true
+			existingNames[util.ConvertOwner2ObjectId(categoryRule.OwnerId).Hex()+"/"+categoryRule.Name] = true
+		}
+	}
+
+	var pendingCategoryRules []model.CategoryRuleEntity
+	for _, categoryRule := range categoryRules {
+		if existingIds[categoryRule.Id] || existingNames[categoryRule.OwnerId.Hex()+"/"+categoryRule.Name] {
+			run.result.CategoryRulesSkipped++
+			continue
+		}
+		if mappedCategoryId, ok := categoryIdMapping[categoryRule.CategoryId]; ok {
+			categoryRule.CategoryId = mappedCategoryId
+		}
+		if mappedAccountId, ok := accountIdMapping[categoryRule.AccountId]; ok {
+			categoryRule.AccountId = mappedAccountId
+		}
+		if mappedPayeeId, ok := payeeIdMapping[categoryRule.PayeeId]; ok {
+			categoryRule.PayeeId = mappedPayeeId
+		}
+		pendingCategoryRules = append(pendingCategoryRules, categoryRule)
+	}
+
+	for start := 0; start < len(pendingCategoryRules); start += backupPageSize {
+		end := start + backupPageSize
+		if end > len(pendingCategoryRules) {
+			end = len(pendingCategoryRules)
+		}
+		batch := pendingCategoryRules[start:end]
+		for _, categoryRule := range batch {
+			run.insertedCategoryRuleIds = append(run.insertedCategoryRuleIds, categoryRule.Id)
+		}
+		if _, err := category_rule_mapper.INSTANCE.BulkInsertCategoryRules(batch); err != nil {
+			return err
+		}
+		run.result.CategoryRulesRestored += len(batch)
+	}
+	return nil
+}
+
+func exchangeRatePairDateKey(exchangeRate model.ExchangeRateEntity) string {
+	return exchangeRate.OwnerId.Hex() + "/" + exchangeRate.FromCurrency + "/" + exchangeRate.ToCurrency + "@" +
+		util.FormatDateToStringWithDash(exchangeRate.EffectiveDate)
+}
+
+// rollback removes every record this run attempted to insert and, in replace mode,
+// puts the snapshot taken before clearing back in place.
+func (run *restoreRun) rollback() (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+	}()
+
+	for _, categoryRuleId := range run.insertedCategoryRuleIds {
+		if !category_rule_mapper.INSTANCE.GetCategoryRuleByObjectId(categoryRuleId.Hex()).IsEmpty() {
+			if category_rule_mapper.INSTANCE.DeleteCategoryRuleByObjectId(categoryRuleId.Hex()).IsEmpty() {
+				return fmt.Errorf("failed to remove restored category rule %s", categoryRuleId.Hex())
+			}
+		}
+	}
+	run.result.CategoryRulesRestored = 0
+
+	for _, goalId := range run.insertedGoalIds {
+		if !goal_mapper.INSTANCE.GetGoalByObjectId(goalId.Hex()).IsEmpty() {
+			if goal_mapper.INSTANCE.DeleteGoalByObjectId(goalId.Hex()).IsEmpty() {
+				return fmt.Errorf("failed to remove restored goal %s", goalId.Hex())
+			}
+		}
+	}
+	run.result.GoalsRestored = 0
+
+	for _, budgetId := range run.insertedBudgetIds {
+		if !budget_mapper.INSTANCE.GetBudgetByObjectId(budgetId.Hex()).IsEmpty() {
+			if budget_mapper.INSTANCE.DeleteBudgetByObjectId(budgetId.Hex()).IsEmpty() {
+				return fmt.Errorf("failed to remove restored budget %s", budgetId.Hex())
+			}
+		}
+	}
+	run.result.BudgetsRestored = 0
+
+	for _, recurringRuleId := range run.insertedRecurringRuleIds {
+		if !recurring_rule_mapper.INSTANCE.GetRecurringRuleByObjectId(recurringRuleId.Hex()).IsEmpty() {
+			if recurring_rule_mapper.INSTANCE.DeleteRecurringRuleByObjectId(recurringRuleId.Hex()).IsEmpty() {
+				return fmt.Errorf("failed to remove restored recurring rule %s", recurringRuleId.Hex())
+			}
+		}
+	}
+	run.result.RecurringRulesRestored = 0
+
+	for _, exchangeRateId := range run.insertedExchangeRateIds {
+		if !exchange_rate_mapper.INSTANCE.GetExchangeRateByObjectId(exchangeRateId.Hex()).IsEmpty() {
+			if exchange_rate_mapper.INSTANCE.DeleteExchangeRateByObjectId(exchangeRateId.Hex()).IsEmpty() {
+				return fmt.Errorf("failed to remove restored exchange rate %s", exchangeRateId.Hex())
+			}
+		}
+	}
+	run.result.ExchangeRatesRestored = 0
+
+	for _, cashFlowId := range run.insertedCashFlowIds {
+		if !cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(cashFlowId.Hex()).IsEmpty() {
+			if cash_flow_mapper.INSTANCE.DeleteCashFlowByObjectId(cashFlowId.Hex()).IsEmpty() {
+				return fmt.Errorf("failed to remove restored cash_flow %s", cashFlowId.Hex())
+			}
+		}
+	}
+	run.result.CashFlowsRestored = 0
+
+	for _, payeeId := range run.insertedPayeeIds {
+		if !payee_mapper.INSTANCE.GetPayeeByObjectId(payeeId.Hex()).IsEmpty() {
+			if payee_mapper.INSTANCE.DeletePayeeByObjectId(payeeId.Hex()).IsEmpty() {
+				return fmt.Errorf("failed to remove restored payee %s", payeeId.Hex())
+			}
+		}
+	}
+	run.result.PayeesRestored = 0
+
+	for index := len(run.insertedCategoryIds) - 1; index >= 0; index-- {
+		plainId := run.insertedCategoryIds[index].Hex()
+		if !category_mapper.INSTANCE.GetCategoryByObjectId(plainId).IsEmpty() {
+			if category_mapper.INSTANCE.DeleteCategoryByObjectId(plainId).IsEmpty() {
+				return fmt.Errorf("failed to remove restored category %s", plainId)
+			}
+		}
+	}
+	run.result.CategoriesRestored = 0
+
+	for _, accountId := range run.insertedAccountIds {
+		if !account_mapper.INSTANCE.GetAccountByObjectId(accountId.Hex()).IsEmpty() {
+			if account_mapper.INSTANCE.DeleteAccountByObjectId(accountId.Hex()).IsEmpty() {
+				return fmt.Errorf("failed to remove restored account %s", accountId.Hex())
+			}
+		}
+	}
+	run.result.AccountsRestored = 0
+
+	if err := run.rollbackAccessData(); err != nil {
+		return err
+	}
+
+	if run.result.CategoriesCleared == 0 && run.result.AccountsCleared == 0 &&
+		run.result.CashFlowsCleared == 0 && run.result.ExchangeRatesCleared == 0 &&
+		run.result.RecurringRulesCleared == 0 && run.result.BudgetsCleared == 0 &&
+		run.result.GoalsCleared == 0 && run.result.PayeesCleared == 0 &&
+		run.result.CategoryRulesCleared == 0 {
+		return nil
+	}
+
+	// Put back what replace mode cleared, the snapshot keeps original ids
+	categories := sortCategoriesByHierarchy(convertBackup2CategoryEntities(run.snapshot.Categories))
+	if _, err := category_mapper.INSTANCE.BulkInsertCategories(categories); err != nil {
+		return err
+	}
+	run.result.CategoriesCleared = 0
+
+	if _, err := account_mapper.INSTANCE.BulkInsertAccounts(
+		convertBackup2AccountEntities(run.snapshot.Accounts)); err != nil {
+		return err
+	}
+	run.result.AccountsCleared = 0
+
+	if _, err := payee_mapper.INSTANCE.BulkInsertPayees(
+		convertBackup2PayeeEntities(run.snapshot.Payees)); err != nil {
+		return err
+	}
+	run.result.PayeesCleared = 0
+
+	if _, err := cash_flow_mapper.INSTANCE.BulkInsertCashFlows(
+		convertBackup2CashFlowEntities(run.snapshot.CashFlows)); err != nil {
+		return err
+	}
+	run.result.CashFlowsCleared = 0
+
+	if _, err := exchange_rate_mapper.INSTANCE.BulkInsertExchangeRates(
+		convertBackup2ExchangeRateEntities(run.snapshot.ExchangeRates)); err != nil {
+		return err
+	}
+	run.result.ExchangeRatesCleared = 0
+
+	if _, err := recurring_rule_mapper.INSTANCE.BulkInsertRecurringRules(
+		convertBackup2RecurringRuleEntities(run.snapshot.RecurringRules)); err != nil {
+		return err
+	}
+	run.result.RecurringRulesCleared = 0
+
+	if _, err := budget_mapper.INSTANCE.BulkInsertBudgets(
+		convertBackup2BudgetEntities(run.snapshot.Budgets)); err != nil {
+		return err
+	}
+	run.result.BudgetsCleared = 0
+
+	if _, err := goal_mapper.INSTANCE.BulkInsertGoals(
+		convertBackup2GoalEntities(run.snapshot.Goals)); err != nil {
+		return err
+	}
+	run.result.GoalsCleared = 0
+
+	if _, err := category_rule_mapper.INSTANCE.BulkInsertCategoryRules(
+		convertBackup2CategoryRuleEntities(run.snapshot.CategoryRules)); err != nil {
+		return err
+	}
+	run.result.CategoryRulesCleared = 0
+	return nil
+}
+
+// rollbackAccessData removes the restored access data, api tokens first and users last,
+// then puts back each part replace mode cleared.
+func (run *restoreRun) rollbackAccessData() error {
+	for _, apiToken := range run.insertedApiTokens {
+		if !api_token_mapper.INSTANCE.GetApiTokenByHash(apiToken.TokenHash).IsEmpty() {
+			if api_token_mapper.INSTANCE.DeleteApiTokenByObjectId(apiToken.Id.Hex()).IsEmpty() {
+				return fmt.Errorf("failed to remove restored api token %s", apiToken.Id.Hex())
+			}
+		}
+	}
+	run.result.ApiTokensRestored = 0
+
+	for _, ledgerInvitation := range run.insertedLedgerInvitations {
+		if !ledger_mapper.INSTANCE.GetLedgerInvitationByHash(ledgerInvitation.TokenHash).IsEmpty() {
+			if ledger_mapper.INSTANCE.DeleteLedgerInvitationByObjectId(ledgerInvitation.Id.Hex()).IsEmpty() {
+				return fmt.Errorf("failed to remove restored ledger invitation %s", ledgerInvitation.Id.Hex())
+			}
+		}
+	}
+	run.result.LedgerInvitationsRestored = 0
+
+	for _, ledgerMember := range run.insertedLedgerMembers {
+		existingMember := ledger_mapper.INSTANCE.GetLedgerMember(ledgerMember.LedgerId.Hex(), ledgerMember.UserId.Hex())
+		if existingMember.Id == ledgerMember.Id {
+			if ledger_mapper.INSTANCE.DeleteLedgerMemberByObjectId(ledgerMember.Id.Hex()).IsEmpty() {
+				return fmt.Errorf("failed to remove restored ledger member %s", ledgerMember.Id.Hex())
+			}
+		}
+	}
+	run.result.LedgerMembersRestored = 0
+
+	for _, ledgerId := range run.insertedLedgerIds {
+		if !ledger_mapper.INSTANCE.GetLedgerByObjectId(ledgerId.Hex()).IsEmpty() {
+			if ledger_mapper.INSTANCE.DeleteLedgerByObjectId(ledgerId.Hex()).IsEmpty() {
+				return fmt.Errorf("failed to remove restored ledger %s", ledgerId.Hex())
+			}
+		}
+	}
+	run.result.LedgersRestored = 0
+
+	for _, userId := range run.insertedUserIds {
+		if !user_mapper.INSTANCE.GetUserByObjectId(userId.Hex()).IsEmpty() {
+			if user_mapper.INSTANCE.DeleteUserByObjectId(userId.Hex()).IsEmpty() {
+				return fmt.Errorf("failed to remove restored user %s", userId.Hex())
+			}
+		}
+	}
+	run.result.UsersRestored = 0
+
+	// Put back what replace mode cleared, the snapshot keeps original ids
+	if run.result.UsersCleared > 0 {
+		if _, err := user_mapper.INSTANCE.BulkInsertUsers(convertBackup2UserEntities(run.snapshot.Users)); err != nil {
+			return err
+		}
+		run.result.UsersCleared = 0
+	}
+	if run.result.LedgersCleared > 0 || run.result.LedgerMembersCleared > 0 || run.result.LedgerInvitationsCleared > 0 {
+		if _, err := ledger_mapper.INSTANCE.BulkInsertLedgers(
+			convertBackup2LedgerEntities(run.snapshot.Ledgers)); err != nil {
+			return err
+		}
+		run.result.LedgersCleared = 0
+		if _, err := ledger_mapper.INSTANCE.BulkInsertLedgerMembers(
+			convertBackup2LedgerMemberEntities(run.snapshot.LedgerMembers)); err != nil {
+			return err
+		}
+		run.result.LedgerMembersCleared = 0
+		if _, err := ledger_mapper.INSTANCE.BulkInsertLedgerInvitations(
+			convertBackup2LedgerInvitationEntities(run.snapshot.LedgerInvitations)); err != nil {
+			return err
+		}
+		run.result.LedgerInvitationsCleared = 0
+	}
+	if run.result.ApiTokensCleared > 0 {
+		if _, err := api_token_mapper.INSTANCE.BulkInsertApiTokens(
+			convertBackup2ApiTokenEntities(run.snapshot.ApiTokens)); err != nil {
+			return err
+		}
+		run.result.ApiTokensCleared = 0
+	}
+	return nil
+}
+
+// sortCategoriesByHierarchy orders categories so that every parent comes before its children
+func sortCategoriesByHierarchy(categories []model.CategoryEntity) []model.CategoryEntity {
+	knownIds := make(map[primitive.ObjectID]bool)
+	for _, category := range categories {
+		knownIds[category.Id] = true
+	}
+
+	sortedCategories := make([]model.CategoryEntity, 0, len(categories))
+	placedIds := make(map[primitive.ObjectID]bool)
+	remaining := categories
+	for len(remaining) > 0 {
+		var deferred []model.CategoryEntity
+		for _, category := range remaining {
+			if !knownIds[category.ParentId] || placedIds[category.ParentId] {
+				sortedCategories = append(sortedCategories, category)
+				placedIds[category.Id] = true
+			} else {
+				deferred = append(deferred, category)
+			}
+		}
+		if len(deferred) == len(remaining) {
+			// circular parent links, keep the rest in their original order
+			sortedCategories = append(sortedCategories, deferred...)
+			break
+		}
+		remaining = deferred
+	}
+	return sortedCategories
+}
+
+func convertBackup2CategoryEntities(categories []BackupCategory) []model.CategoryEntity {
+	entities := make([]model.CategoryEntity, 0, len(categories))
+	for _, category := range categories {
+		entity := model.CategoryEntity{
+			Id:         util.Convert2ObjectId(category.Id),
+			Name:       category.Name,
+			Remark:     category.Remark,
+			CreateTime: category.CreateTime,
+			ModifyTime: category.ModifyTime,
+		}
+		if category.ParentId != "" {
+			entity.ParentId = util.Convert2ObjectId(category.ParentId)
+		}
+		if category.OwnerId != "" {
+			entity.OwnerId = util.Convert2ObjectId(category.OwnerId)
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func convertBackup2AccountEntities(accounts []BackupAccount) []model.AccountEntity {
+	entities := make([]model.AccountEntity, 0, len(accounts))
+	for _, account := range accounts {
+		entity := model.AccountEntity{
+			Id:             util.Convert2ObjectId(account.Id),
+			Name:           account.Name,
+			Type:           account.Type,
+			OpeningBalance: account.OpeningBalance,
+			Currency:       account.Currency,
+			Remark:         account.Remark,
+			CreateTime:     account.CreateTime,
+			ModifyTime:     account.ModifyTime,
+		}
+		if account.OwnerId != "" {
+			entity.OwnerId = util.Convert2ObjectId(account.OwnerId)
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func convertBackup2CashFlowEntities(cashFlows []BackupCashFlow) []model.CashFlowEntity {
+	entities := make([]model.CashFlowEntity, 0, len(cashFlows))
+	for _, cashFlow := range cashFlows {
+		// belongs_date is written as YYYY-MM-DD, YYYYMMDD is accepted as well
+		belongsDate := util.FormatDateFromStringWithDash(cashFlow.BelongsDate)
+		if len(cashFlow.BelongsDate) == len(model.DateFormatYYYYMMDD) {
+			belongsDate = util.FormatDateFromStringWithoutDash(cashFlow.BelongsDate)
+		}
+		entity := model.CashFlowEntity{
+			Id:          util.Convert2ObjectId(cashFlow.Id),
+			BelongsDate: belongsDate,
+			FlowType:    cashFlow.FlowType,
+			Amount:      cashFlow.Amount,
+			Currency:    cashFlow.Currency,
+			Description: cashFlow.Description,
+			Tags:        cashFlow.Tags,
+			Remark:      cashFlow.Remark,
+			CreateTime:  cashFlow.CreateTime,
+			ModifyTime:  cashFlow.ModifyTime,
+		}
+		if cashFlow.OwnerId != "" {
+			entity.OwnerId = util.Convert2ObjectId(cashFlow.OwnerId)
+		}
+		if cashFlow.CreatedBy != "" {
+			entity.CreatedBy = util.Convert2ObjectId(cashFlow.CreatedBy)
+		}
+		if cashFlow.ModifiedBy != "" {
+			entity.ModifiedBy = util.Convert2ObjectId(cashFlow.ModifiedBy)
+		}
+		if cashFlow.CategoryId != "" {
+			entity.CategoryId = util.Convert2ObjectId(cashFlow.CategoryId)
+		}
+		for _, split := range cashFlow.Splits {
+			entity.Splits = append(entity.Splits, model.CashFlowSplit{
+				CategoryId:  util.Convert2ObjectId(split.CategoryId),
+				Amount:      split.Amount,
+				Description: split.Description,
+			})
+		}
+		if cashFlow.AccountId != "" {
+			entity.AccountId = util.Convert2ObjectId(cashFlow.AccountId)
+		}
+		if cashFlow.LinkedId != "" {
+			entity.LinkedId = util.Convert2ObjectId(cashFlow.LinkedId)
+		}
+		if cashFlow.PayeeId != "" {
+			entity.PayeeId = util.Convert2ObjectId(cashFlow.PayeeId)
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func convertBackup2ExchangeRateEntities(exchangeRates []BackupExchangeRate) []model.ExchangeRateEntity {
+	entities := make([]model.ExchangeRateEntity, 0, len(exchangeRates))
+	for _, exchangeRate := range exchangeRates {
+		entity := model.ExchangeRateEntity{
+			Id:            util.Convert2ObjectId(exchangeRate.Id),
+			FromCurrency:  exchangeRate.FromCurrency,
+			ToCurrency:    exchangeRate.ToCurrency,
+			Rate:          exchangeRate.Rate,
+			EffectiveDate: util.FormatDateFromStringWithOptionalDash(exchangeRate.EffectiveDate),
+			CreateTime:    exchangeRate.CreateTime,
+			ModifyTime:    exchangeRate.ModifyTime,
+		}
+		if exchangeRate.OwnerId != "" {
+			entity.OwnerId = util.Convert2ObjectId(exchangeRate.OwnerId)
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func convertBackup2RecurringRuleEntities(recurringRules []BackupRecurringRule) []model.RecurringRuleEntity {
+	entities := make([]model.RecurringRuleEntity, 0, len(recurringRules))
+	for _, recurringRule := range recurringRules {
+		entity := model.RecurringRuleEntity{
+			Id:              util.Convert2ObjectId(recurringRule.Id),
+			Name:            recurringRule.Name,
+			FlowType:        recurringRule.FlowType,
+			CategoryId:      util.Convert2ObjectId(recurringRule.CategoryId),
+			Amount:          recurringRule.Amount,
+			Currency:        recurringRule.Currency,
+			Description:     recurringRule.Description,
+			Frequency:       recurringRule.Frequency,
+			DayOfMonth:      recurringRule.DayOfMonth,
+			StartDate:       util.FormatDateFromStringWithOptionalDash(recurringRule.StartDate),
+			OccurrenceLimit: recurringRule.OccurrenceLimit,
+			OccurrenceCount: recurringRule.OccurrenceCount,
+			CreateTime:      recurringRule.CreateTime,
+			ModifyTime:      recurringRule.ModifyTime,
+		}
+		if recurringRule.OwnerId != "" {
+			entity.OwnerId = util.Convert2ObjectId(recurringRule.OwnerId)
+		}
+		if recurringRule.AccountId != "" {
+			entity.AccountId = util.Convert2ObjectId(recurringRule.AccountId)
+		}
+		if recurringRule.EndDate != "" {
+			entity.EndDate = util.FormatDateFromStringWithOptionalDash(recurringRule.EndDate)
+		}
+		if recurringRule.LastRunDate != "" {
+			entity.LastRunDate = util.FormatDateFromStringWithOptionalDash(recurringRule.LastRunDate)
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func convertBackup2BudgetEntities(budgets []BackupBudget) []model.BudgetEntity {
+	entities := make([]model.BudgetEntity, 0, len(budgets))
+	for _, budget := range budgets {
+		entity := model.BudgetEntity{
+			Id:         util.Convert2ObjectId(budget.Id),
+			CategoryId: util.Convert2ObjectId(budget.CategoryId),
+			Period:     budget.Period,
+			Limit:      budget.Limit,
+			Currency:   budget.Currency,
+			Rollover:   budget.Rollover,
+			StartDate:  util.FormatDateFromStringWithOptionalDash(budget.StartDate),
+			CreateTime: budget.CreateTime,
+			ModifyTime: budget.ModifyTime,
+		}
+		if budget.OwnerId != "" {
+			entity.OwnerId = util.Convert2ObjectId(budget.OwnerId)
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func convertBackup2GoalEntities(goals []BackupGoal) []model.GoalEntity {
+	entities := make([]model.GoalEntity, 0, len(goals))
+	for _, goal := range goals {
+		entity := model.GoalEntity{
+			Id:           util.Convert2ObjectId(goal.Id),
+			Name:         goal.Name,
+			AccountId:    util.Convert2ObjectId(goal.AccountId),
+			TargetAmount: goal.TargetAmount,
+			Currency:     goal.Currency,
+			StartDate:    util.FormatDateFromStringWithOptionalDash(goal.StartDate),
+			Deadline:     util.FormatDateFromStringWithOptionalDash(goal.Deadline),
+			CreateTime:   goal.CreateTime,
+			ModifyTime:   goal.ModifyTime,
+		}
+		if goal.OwnerId != "" {
+			entity.OwnerId = util.Convert2ObjectId(goal.OwnerId)
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func convertBackup2PayeeEntities(payees []BackupPayee) []model.PayeeEntity {
+	entities := make([]model.PayeeEntity, 0, len(payees))
+	for _, payee := range payees {
+		entity := model.PayeeEntity{
+			Id:         util.Convert2ObjectId(payee.Id),
+			Name:       payee.Name,
+			CreateTime: payee.CreateTime,
+			ModifyTime: payee.ModifyTime,
+		}
+		if payee.OwnerId != "" {
+			entity.OwnerId = util.Convert2ObjectId(payee.OwnerId)
+		}
+		for _, rule := range payee.Rules {
+			entity.Rules = append(entity.Rules, model.PayeeRule{MatchType: rule.MatchType, Pattern: rule.Pattern})
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func convertBackup2CategoryRuleEntities(categoryRules []BackupCategoryRule) []model.CategoryRuleEntity {
+	entities := make([]model.CategoryRuleEntity, 0, len(categoryRules))
+	for _, categoryRule := range categoryRules {
+		entity := model.CategoryRuleEntity{
+			Id:         util.Convert2ObjectId(categoryRule.Id),
+			Name:       categoryRule.Name,
+			Priority:   categoryRule.Priority,
+			FlowType:   categoryRule.FlowType,
+			MatchType:  categoryRule.MatchType,
+			Pattern:    categoryRule.Pattern,
+			MinAmount:  categoryRule.MinAmount,
+			MaxAmount:  categoryRule.MaxAmount,
+			CategoryId: util.Convert2ObjectId(categoryRule.CategoryId),
+			Tags:       categoryRule.Tags,
+			CreateTime: categoryRule.CreateTime,
+			ModifyTime: categoryRule.ModifyTime,
+		}
+		if categoryRule.OwnerId != "" {
+			entity.OwnerId = util.Convert2ObjectId(categoryRule.OwnerId)
+		}
+		if categoryRule.PayeeId != "" {
+			entity.PayeeId = util.Convert2ObjectId(categoryRule.PayeeId)
+		}
+		if categoryRule.AccountId != "" {
+			entity.AccountId = util.Convert2ObjectId(categoryRule.AccountId)
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func convertBackup2UserEntities(users []BackupUser) []model.UserEntity {
+	entities := make([]model.UserEntity, 0, len(users))
+	for _, user := range users {
+		entities = append(entities, model.UserEntity{
+			Id:           util.Convert2ObjectId(user.Id),
+			Username:     user.Username,
+			PasswordHash: user.PasswordHash,
+			CreateTime:   user.CreateTime,
+			ModifyTime:   user.ModifyTime,
+		})
+	}
+	return entities
+}
+
+func convertBackup2ApiTokenEntities(apiTokens []BackupApiToken) []model.ApiTokenEntity {
+	entities := make([]model.ApiTokenEntity, 0, len(apiTokens))
+	for _, apiToken := range apiTokens {
+		entities = append(entities, model.ApiTokenEntity{
+			Id:         util.Convert2ObjectId(apiToken.Id),
+			UserId:     util.Convert2ObjectId(apiToken.UserId),
+			Name:       apiToken.Name,
+			TokenHash:  apiToken.TokenHash,
+			CreateTime: apiToken.CreateTime,
+			ModifyTime: apiToken.ModifyTime,
+		})
+	}
+	return entities
+}
+
+func convertBackup2LedgerEntities(ledgers []BackupLedger) []model.LedgerEntity {
+	entities := make([]model.LedgerEntity, 0, len(ledgers))
+	for _, ledger := range ledgers {
+		entities = append(entities, model.LedgerEntity{
+			Id:         util.Convert2ObjectId(ledger.Id),
+			Name:       ledger.Name,
+			CreateTime: ledger.CreateTime,
+			ModifyTime: ledger.ModifyTime,
+		})
+	}
+	return entities
+}
+
+func convertBackup2LedgerMemberEntities(ledgerMembers []BackupLedgerMember) []model.LedgerMemberEntity {
+	entities := make([]model.LedgerMemberEntity, 0, len(ledgerMembers))
+	for _, ledgerMember := range ledgerMembers {
+		entities = append(entities, model.LedgerMemberEntity{
+			Id:         util.Convert2ObjectId(ledgerMember.Id),
+			LedgerId:   util.Convert2ObjectId(ledgerMember.LedgerId),
+			UserId:     util.Convert2ObjectId(ledgerMember.UserId),
+			Role:       ledgerMember.Role,
+			CreateTime: ledgerMember.CreateTime,
+			ModifyTime: ledgerMember.ModifyTime,
+		})
+	}
+	return entities
+}
+
+func convertBackup2LedgerInvitationEntities(ledgerInvitations []BackupLedgerInvitation) []model.LedgerInvitationEntity {
+	entities := make([]model.LedgerInvitationEntity, 0, len(ledgerInvitations))
+	for _, ledgerInvitation := range ledgerInvitations {
+		entity := model.LedgerInvitationEntity{
+			Id:         util.Convert2ObjectId(ledgerInvitation.Id),
+			LedgerId:   util.Convert2ObjectId(ledgerInvitation.LedgerId),
+			Role:       ledgerInvitation.Role,
+			TokenHash:  ledgerInvitation.TokenHash,
+			ExpireTime: ledgerInvitation.ExpireTime,
+			CreateTime: ledgerInvitation.CreateTime,
+			ModifyTime: ledgerInvitation.ModifyTime,
+		}
+		if ledgerInvitation.InvitedBy != "" {
+			entity.InvitedBy = util.Convert2ObjectId(ledgerInvitation.InvitedBy)
+		}
+		entities = append(entities, entity)
+	}
+	return entities
 }
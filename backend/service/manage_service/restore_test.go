@@ -0,0 +1,833 @@
+package manage_service
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/api_token_mapper"
+	"github.com/macar-x/cashlens/mapper/budget_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/mapper/ledger_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/user_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateBackup(t *testing.T) {
+	categoryId := primitive.NewObjectID().Hex()
+	payeeId := primitive.NewObjectID().Hex()
+	validCashFlow := BackupCashFlow{
+		Id:          primitive.NewObjectID().Hex(),
+		CategoryId:  categoryId,
+		BelongsDate: "2024-01-15",
+		FlowType:    model.FlowTypeOutcome,
+		Amount:      decimal.NewFromInt(10),
+	}
+
+	tests := []struct {
+		name    string
+		backup  BackupData
+		wantErr bool
+	}{
+		{
+			name: "Valid backup",
+			backup: BackupData{
+				Version:    BackupVersion,
+				Categories: []BackupCategory{{Id: categoryId, Name: "Food"}},
+				CashFlows:  []BackupCashFlow{validCashFlow},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Missing version",
+			backup:  BackupData{},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported major version",
+			backup:  BackupData{Version: "2.0.0"},
+			wantErr: true,
+		},
+		{
+			name:    "Newer minor version",
+			backup:  BackupData{Version: "1.99.0"},
+			wantErr: true,
+		},
+		{
+			name:    "Malformed version",
+			backup:  BackupData{Version: "1.x"},
+			wantErr: true,
+		},
+		{
+			name:    "Older minor version",
+			backup:  BackupData{Version: "1.0.0"},
+			wantErr: false,
+		},
+		{
+			name: "Duplicated category id",
+			backup: BackupData{
+				Version: BackupVersion,
+				Categories: []BackupCategory{
+					{Id: categoryId, Name: "Food"},
+					{Id: categoryId, Name: "Drinks"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid transfer without category",
+			backup: BackupData{
+				Version: BackupVersion,
+				CashFlows: []BackupCashFlow{{
+					Id:          validCashFlow.Id,
+					LinkedId:    primitive.NewObjectID().Hex(),
+					BelongsDate: "2024-01-15",
+					FlowType:    model.FlowTypeTransfer,
+					Amount:      decimal.NewFromInt(-10),
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Transfer without linked id",
+			backup: BackupData{
+				Version: BackupVersion,
+				CashFlows: []BackupCashFlow{{
+					Id:          validCashFlow.Id,
+					BelongsDate: "2024-01-15",
+					FlowType:    model.FlowTypeTransfer,
+					Amount:      decimal.NewFromInt(10),
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Account without name",
+			backup: BackupData{
+				Version:  BackupVersion,
+				Accounts: []BackupAccount{{Id: primitive.NewObjectID().Hex()}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid cash_flow account id",
+			backup: BackupData{
+				Version: BackupVersion,
+				CashFlows: []BackupCashFlow{{
+					Id:          validCashFlow.Id,
+					CategoryId:  categoryId,
+					AccountId:   "not-an-id",
+					BelongsDate: "2024-01-15",
+					FlowType:    model.FlowTypeOutcome,
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid cash_flow currency",
+			backup: BackupData{
+				Version: BackupVersion,
+				CashFlows: []BackupCashFlow{{
+					Id:          validCashFlow.Id,
+					CategoryId:  categoryId,
+					BelongsDate: "2024-01-15",
+					FlowType:    model.FlowTypeOutcome,
+					Currency:    "usd",
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid cash_flow tags",
+			backup: BackupData{
+				Version:    BackupVersion,
+				Categories: []BackupCategory{{Id: categoryId, Name: "Food"}},
+				CashFlows: []BackupCashFlow{{
+					Id:          validCashFlow.Id,
+					CategoryId:  categoryId,
+					BelongsDate: "2024-01-15",
+					FlowType:    model.FlowTypeOutcome,
+					Tags:        []string{"reimbursable", "trip-japan"},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Duplicated cash_flow tag",
+			backup: BackupData{
+				Version:    BackupVersion,
+				Categories: []BackupCategory{{Id: categoryId, Name: "Food"}},
+				CashFlows: []BackupCashFlow{{
+					Id:          validCashFlow.Id,
+					CategoryId:  categoryId,
+					BelongsDate: "2024-01-15",
+					FlowType:    model.FlowTypeOutcome,
+					Tags:        []string{"trip-japan", "trip-japan"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid cash_flow splits",
+			backup: BackupData{
+				Version:    BackupVersion,
+				Categories: []BackupCategory{{Id: categoryId, Name: "Food"}},
+				CashFlows: []BackupCashFlow{{
+					Id:          validCashFlow.Id,
+					CategoryId:  categoryId,
+					BelongsDate: "2024-01-15",
+					FlowType:    model.FlowTypeOutcome,
+					Amount:      decimal.NewFromInt(10),
+					Splits: []BackupSplit{
+						{CategoryId: categoryId, Amount: decimal.NewFromInt(7)},
+						{CategoryId: categoryId, Amount: decimal.NewFromInt(3)},
+					},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Split lines not adding up",
+			backup: BackupData{
+				Version:    BackupVersion,
+				Categories: []BackupCategory{{Id: categoryId, Name: "Food"}},
+				CashFlows: []BackupCashFlow{{
+					Id:          validCashFlow.Id,
+					CategoryId:  categoryId,
+					BelongsDate: "2024-01-15",
+					FlowType:    model.FlowTypeOutcome,
+					Amount:      decimal.NewFromInt(10),
+					Splits: []BackupSplit{
+						{CategoryId: categoryId, Amount: decimal.NewFromInt(7)},
+						{CategoryId: categoryId, Amount: decimal.NewFromInt(2)},
+					},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid exchange rate",
+			backup: BackupData{
+				Version: BackupVersion,
+				ExchangeRates: []BackupExchangeRate{{
+					Id:            primitive.NewObjectID().Hex(),
+					FromCurrency:  "EUR",
+					ToCurrency:    "USD",
+					Rate:          decimal.RequireFromString("1.1"),
+					EffectiveDate: "2024-01-01",
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Exchange rate without rate",
+			backup: BackupData{
+				Version: BackupVersion,
+				ExchangeRates: []BackupExchangeRate{{
+					Id:            primitive.NewObjectID().Hex(),
+					FromCurrency:  "EUR",
+					ToCurrency:    "USD",
+					EffectiveDate: "2024-01-01",
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid recurring rule",
+			backup: BackupData{
+				Version: BackupVersion,
+				RecurringRules: []BackupRecurringRule{{
+					Id:          primitive.NewObjectID().Hex(),
+					Name:        "Rent",
+					FlowType:    model.FlowTypeOutcome,
+					CategoryId:  categoryId,
+					Currency:    "USD",
+					Frequency:   model.FrequencyMonthly,
+					DayOfMonth:  1,
+					StartDate:   "2024-01-01",
+					LastRunDate: "2024-03-01",
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Monthly recurring rule without day",
+			backup: BackupData{
+				Version: BackupVersion,
+				RecurringRules: []BackupRecurringRule{{
+					Id:         primitive.NewObjectID().Hex(),
+					Name:       "Rent",
+					FlowType:   model.FlowTypeOutcome,
+					CategoryId: categoryId,
+					Currency:   "USD",
+					Frequency:  model.FrequencyMonthly,
+					StartDate:  "2024-01-01",
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid budget",
+			backup: BackupData{
+				Version: BackupVersion,
+				Budgets: []BackupBudget{{
+					Id:         primitive.NewObjectID().Hex(),
+					CategoryId: categoryId,
+					Period:     model.BudgetPeriodMonthly,
+					Limit:      decimal.NewFromInt(300),
+					Currency:   "USD",
+					Rollover:   true,
+					StartDate:  "2024-01-01",
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Two monthly budgets of one category",
+			backup: BackupData{
+				Version: BackupVersion,
+				Budgets: []BackupBudget{
+					{Id: primitive.NewObjectID().Hex(), CategoryId: categoryId, Period: model.BudgetPeriodMonthly,
+						Limit: decimal.NewFromInt(300), Currency: "USD", StartDate: "2024-01-01"},
+					{Id: primitive.NewObjectID().Hex(), CategoryId: categoryId, Period: model.BudgetPeriodMonthly,
+						Limit: decimal.NewFromInt(200), Currency: "USD", StartDate: "2024-02-01"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid goal",
+			backup: BackupData{
+				Version: BackupVersion,
+				Goals: []BackupGoal{{
+					Id:           primitive.NewObjectID().Hex(),
+					Name:         "Vacation",
+					AccountId:    primitive.NewObjectID().Hex(),
+					TargetAmount: decimal.NewFromInt(1200),
+					Currency:     "USD",
+					StartDate:    "2024-01-01",
+					Deadline:     "2024-12-31",
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Goal without account",
+			backup: BackupData{
+				Version: BackupVersion,
+				Goals: []BackupGoal{{
+					Id:           primitive.NewObjectID().Hex(),
+					Name:         "Vacation",
+					TargetAmount: decimal.NewFromInt(1200),
+					Currency:     "USD",
+					StartDate:    "2024-01-01",
+					Deadline:     "2024-12-31",
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid payee",
+			backup: BackupData{
+				Version:    BackupVersion,
+				Categories: []BackupCategory{{Id: categoryId, Name: "Food"}},
+				Payees: []BackupPayee{{
+					Id:    payeeId,
+					Name:  "Starbucks",
+					Rules: []BackupPayeeRule{{MatchType: model.PayeeRuleRegex, Pattern: `(?i)starbucks`}},
+				}},
+				CashFlows: []BackupCashFlow{{
+					Id:          validCashFlow.Id,
+					CategoryId:  categoryId,
+					PayeeId:     payeeId,
+					BelongsDate: "2024-01-15",
+					FlowType:    model.FlowTypeOutcome,
+					Amount:      decimal.NewFromInt(10),
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Payee rule not compiling",
+			backup: BackupData{
+				Version: BackupVersion,
+				Payees: []BackupPayee{{
+					Id:    payeeId,
+					Name:  "Starbucks",
+					Rules: []BackupPayeeRule{{MatchType: model.PayeeRuleRegex, Pattern: "starbucks("}},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Duplicated payee name",
+			backup: BackupData{
+				Version: BackupVersion,
+				Payees: []BackupPayee{
+					{Id: payeeId, Name: "Starbucks"},
+					{Id: primitive.NewObjectID().Hex(), Name: "Starbucks"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid category rule",
+			backup: BackupData{
+				Version:    BackupVersion,
+				Categories: []BackupCategory{{Id: categoryId, Name: "Food"}},
+				Payees:     []BackupPayee{{Id: payeeId, Name: "Starbucks"}},
+				CategoryRules: []BackupCategoryRule{{
+					Id:         primitive.NewObjectID().Hex(),
+					Name:       "Coffee",
+					Priority:   10,
+					FlowType:   model.FlowTypeOutcome,
+					MatchType:  model.PayeeRuleContains,
+					Pattern:    "coffee",
+					MaxAmount:  decimal.NewFromInt(20),
+					PayeeId:    payeeId,
+					CategoryId: categoryId,
+					Tags:       []string{"coffee"},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Category rule without category",
+			backup: BackupData{
+				Version: BackupVersion,
+				CategoryRules: []BackupCategoryRule{{
+					Id:   primitive.NewObjectID().Hex(),
+					Name: "Coffee",
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Category rule with reversed amount range",
+			backup: BackupData{
+				Version: BackupVersion,
+				CategoryRules: []BackupCategoryRule{{
+					Id:         primitive.NewObjectID().Hex(),
+					Name:       "Coffee",
+					MinAmount:  decimal.NewFromInt(20),
+					MaxAmount:  decimal.NewFromInt(5),
+					CategoryId: categoryId,
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Duplicated category rule name",
+			backup: BackupData{
+				Version: BackupVersion,
+				CategoryRules: []BackupCategoryRule{
+					{Id: primitive.NewObjectID().Hex(), Name: "Coffee", CategoryId: categoryId},
+					{Id: primitive.NewObjectID().Hex(), Name: "Coffee", CategoryId: categoryId},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid flow type",
+			backup: BackupData{
+				Version: BackupVersion,
+				CashFlows: []BackupCashFlow{{
+					Id:          validCashFlow.Id,
+					CategoryId:  categoryId,
+					BelongsDate: "2024-01-15",
+					FlowType:    "UNKNOWN",
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid access data",
+			backup: BackupData{
+				Version:       BackupVersion,
+				Users:         []BackupUser{{Id: categoryId, Username: "alice", PasswordHash: "hash-a"}},
+				Ledgers:       []BackupLedger{{Id: payeeId, Name: "Household"}},
+				LedgerMembers: []BackupLedgerMember{{Id: validCashFlow.Id, LedgerId: payeeId, UserId: categoryId, Role: model.LedgerRoleOwner}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Api tokens without users",
+			backup: BackupData{
+				Version:   BackupVersion,
+				ApiTokens: []BackupApiToken{{Id: categoryId, UserId: payeeId, TokenHash: "hash-t"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Duplicated username",
+			backup: BackupData{
+				Version: BackupVersion,
+				Users: []BackupUser{
+					{Id: categoryId, Username: "alice", PasswordHash: "hash-a"},
+					{Id: payeeId, Username: "alice", PasswordHash: "hash-b"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Ledger member with unknown role",
+			backup: BackupData{
+				Version:       BackupVersion,
+				Users:         []BackupUser{},
+				LedgerMembers: []BackupLedgerMember{{Id: validCashFlow.Id, LedgerId: payeeId, UserId: categoryId, Role: "ADMIN"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBackup(&tt.backup)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateBackup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSortCategoriesByHierarchy(t *testing.T) {
+	root := model.CategoryEntity{Id: primitive.NewObjectID(), Name: "Root"}
+	child := model.CategoryEntity{Id: primitive.NewObjectID(), ParentId: root.Id, Name: "Child"}
+	grandChild := model.CategoryEntity{Id: primitive.NewObjectID(), ParentId: child.Id, Name: "GrandChild"}
+
+	sorted := sortCategoriesByHierarchy([]model.CategoryEntity{grandChild, child, root})
+	if len(sorted) != 3 {
+		t.Fatalf("Expected 3 categories, got %d", len(sorted))
+	}
+
+	position := make(map[primitive.ObjectID]int)
+	for index, category := range sorted {
+		position[category.Id] = index
+	}
+	if position[root.Id] > position[child.Id] || position[child.Id] > position[grandChild.Id] {
+		t.Errorf("Expected parents before children, got %v", sorted)
+	}
+}
+
+// failingCashFlowMapper stores the first record of the next failures batches and then fails,
+// like a database that breaks off in the middle of a bulk insert
+type failingCashFlowMapper struct {
+	cash_flow_mapper.CashFlowMemoryMapper
+	failures *int
+}
+
+func (mapper failingCashFlowMapper) BulkInsertCashFlows(entities []model.CashFlowEntity) ([]string, error) {
+	if *mapper.failures == 0 {
+		return mapper.CashFlowMemoryMapper.BulkInsertCashFlows(entities)
+	}
+	*mapper.failures--
+	if _, err := mapper.CashFlowMemoryMapper.BulkInsertCashFlows(entities[:1]); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("connection lost")
+}
+
+// restoreFixture is the data already in the database and the backup restored over it
+type restoreFixture struct {
+	existingCategory model.CategoryEntity
+	existingAccount  model.AccountEntity
+	existingCashFlow model.CashFlowEntity
+	existingUser     model.UserEntity
+	backup           *BackupData
+	backupFilePath   string
+	backupFoodId     string
+	backupTravelId   string
+	backupAccountId  string
+	backupCashFlows  []BackupCashFlow
+	backupUserId     string
+	backupLedgerId   string
+}
+
+func setUpRestore(t *testing.T) restoreFixture {
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	account_mapper.INSTANCE = account_mapper.NewAccountMemoryMapper()
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	exchange_rate_mapper.INSTANCE = exchange_rate_mapper.NewExchangeRateMemoryMapper()
+	recurring_rule_mapper.INSTANCE = recurring_rule_mapper.NewRecurringRuleMemoryMapper()
+	budget_mapper.INSTANCE = budget_mapper.NewBudgetMemoryMapper()
+	goal_mapper.INSTANCE = goal_mapper.NewGoalMemoryMapper()
+	payee_mapper.INSTANCE = payee_mapper.NewPayeeMemoryMapper()
+	category_rule_mapper.INSTANCE = category_rule_mapper.NewCategoryRuleMemoryMapper()
+	user_mapper.INSTANCE = user_mapper.NewUserMemoryMapper()
+	api_token_mapper.INSTANCE = api_token_mapper.NewApiTokenMemoryMapper()
+	ledger_mapper.INSTANCE = ledger_mapper.NewLedgerMemoryMapper()
+
+	fixture := restoreFixture{
+		existingCategory: model.CategoryEntity{Id: primitive.NewObjectID(), Name: "Food"},
+		existingAccount:  model.AccountEntity{Id: primitive.NewObjectID(), Name: "Wallet"},
+		existingUser:     model.UserEntity{Id: primitive.NewObjectID(), Username: "alice", PasswordHash: "hash-a"},
+	}
+	fixture.existingCashFlow = model.CashFlowEntity{
+		Id:          primitive.NewObjectID(),
+		CategoryId:  fixture.existingCategory.Id,
+		AccountId:   fixture.existingAccount.Id,
+		BelongsDate: util.FormatDateFromStringWithDash("2024-01-10"),
+		FlowType:    model.FlowTypeOutcome,
+		Amount:      decimal.NewFromInt(8),
+		Description: "breakfast",
+	}
+	if _, err := category_mapper.INSTANCE.BulkInsertCategories([]model.CategoryEntity{fixture.existingCategory}); err != nil {
+		t.Fatalf("seed categories failed: %v", err)
+	}
+	if _, err := account_mapper.INSTANCE.BulkInsertAccounts([]model.AccountEntity{fixture.existingAccount}); err != nil {
+		t.Fatalf("seed accounts failed: %v", err)
+	}
+	if _, err := cash_flow_mapper.INSTANCE.BulkInsertCashFlows([]model.CashFlowEntity{fixture.existingCashFlow}); err != nil {
+		t.Fatalf("seed cash_flows failed: %v", err)
+	}
+	if _, err := user_mapper.INSTANCE.BulkInsertUsers([]model.UserEntity{fixture.existingUser}); err != nil {
+		t.Fatalf("seed users failed: %v", err)
+	}
+
+	// The backup has its own Food, which merge resolves by name, and a cash_flow already in the database
+	fixture.backupFoodId = primitive.NewObjectID().Hex()
+	fixture.backupTravelId = primitive.NewObjectID().Hex()
+	fixture.backupAccountId = primitive.NewObjectID().Hex()
+	fixture.backupCashFlows = []BackupCashFlow{
+		{Id: fixture.existingCashFlow.Id.Hex(), CategoryId: fixture.backupFoodId, BelongsDate: "2024-01-10",
+			FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromInt(8), Description: "breakfast"},
+		{Id: primitive.NewObjectID().Hex(), CategoryId: fixture.backupFoodId, AccountId: fixture.backupAccountId,
+			BelongsDate: "2024-01-11", FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromInt(12), Description: "lunch"},
+		{Id: primitive.NewObjectID().Hex(), CategoryId: fixture.backupTravelId, AccountId: fixture.backupAccountId,
+			BelongsDate: "2024-01-12", FlowType: model.FlowTypeOutcome, Amount: decimal.NewFromInt(90), Description: "train"},
+	}
+	// alice is already in the database, bob shares her ledger
+	fixture.backupUserId = primitive.NewObjectID().Hex()
+	fixture.backupLedgerId = primitive.NewObjectID().Hex()
+	fixture.backup = &BackupData{
+		Version: BackupVersion,
+		Categories: []BackupCategory{
+			{Id: fixture.backupFoodId, Name: "Food"},
+			{Id: fixture.backupTravelId, Name: "Travel"},
+		},
+		Accounts:  []BackupAccount{{Id: fixture.backupAccountId, Name: "Bank"}},
+		CashFlows: fixture.backupCashFlows,
+		Users: []BackupUser{
+			{Id: fixture.existingUser.Id.Hex(), Username: "alice", PasswordHash: "hash-a"},
+			{Id: fixture.backupUserId, Username: "bob", PasswordHash: "hash-b"},
+		},
+		ApiTokens: []BackupApiToken{{Id: primitive.NewObjectID().Hex(), UserId: fixture.backupUserId, Name: "sync", TokenHash: "hash-t"}},
+		Ledgers:   []BackupLedger{{Id: fixture.backupLedgerId, Name: "Household"}},
+		LedgerMembers: []BackupLedgerMember{
+			{Id: primitive.NewObjectID().Hex(), LedgerId: fixture.backupLedgerId, UserId: fixture.existingUser.Id.Hex(), Role: model.LedgerRoleOwner},
+			{Id: primitive.NewObjectID().Hex(), LedgerId: fixture.backupLedgerId, UserId: fixture.backupUserId, Role: model.LedgerRoleEditor},
+		},
+	}
+	fixture.writeBackup(t)
+	return fixture
+}
+
+func (fixture *restoreFixture) writeBackup(t *testing.T) {
+	fixture.backupFilePath = filepath.Join(t.TempDir(), "backup.json")
+	if err := writeBackupFile(fixture.backupFilePath, fixture.backup); err != nil {
+		t.Fatalf("writeBackupFile() error = %v", err)
+	}
+}
+
+func TestRestoreBackupMerge(t *testing.T) {
+	fixture := setUpRestore(t)
+
+	result, err := RestoreBackup(fixture.backupFilePath, RestoreModeMerge)
+	if err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if result.CategoriesRestored != 1 || result.CategoriesSkipped != 1 ||
+		result.AccountsRestored != 1 || result.AccountsSkipped != 0 ||
+		result.CashFlowsRestored != 2 || result.CashFlowsSkipped != 1 ||
+		result.CategoriesCleared != 0 || result.CashFlowsCleared != 0 || result.RolledBack {
+		t.Errorf("RestoreBackup() result = %+v", result)
+	}
+
+	if count := cash_flow_mapper.INSTANCE.CountAllCashFlows(); count != 3 {
+		t.Errorf("CountAllCashFlows() = %d, want 3", count)
+	}
+	lunch := cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(fixture.backupCashFlows[1].Id)
+	if lunch.CategoryId != fixture.existingCategory.Id {
+		t.Errorf("merged cash_flow category = %s, want the existing Food %s",
+			lunch.CategoryId.Hex(), fixture.existingCategory.Id.Hex())
+	}
+	if !category_mapper.INSTANCE.GetCategoryByObjectId(fixture.backupFoodId).IsEmpty() {
+		t.Errorf("backup Food should not be inserted next to the existing one")
+	}
+
+	if result.UsersRestored != 1 || result.UsersSkipped != 1 || result.LedgersRestored != 1 ||
+		result.LedgerMembersRestored != 2 || result.ApiTokensRestored != 1 {
+		t.Errorf("RestoreBackup() access data result = %+v", result)
+	}
+	if bob := user_mapper.INSTANCE.GetUserByUsername("bob"); bob.Id.Hex() != fixture.backupUserId || bob.PasswordHash != "hash-b" {
+		t.Errorf("restored user = %+v, want bob with his id and password hash", bob)
+	}
+	if member := ledger_mapper.INSTANCE.GetLedgerMember(fixture.backupLedgerId, fixture.backupUserId); member.Role != model.LedgerRoleEditor {
+		t.Errorf("restored ledger member = %+v, want bob as editor", member)
+	}
+	if apiToken := api_token_mapper.INSTANCE.GetApiTokenByHash("hash-t"); apiToken.UserId.Hex() != fixture.backupUserId {
+		t.Errorf("restored api token = %+v, want bob's", apiToken)
+	}
+}
+
+func TestRestoreBackupMergeTakenUsername(t *testing.T) {
+	fixture := setUpRestore(t)
+	fixture.backup.Users[0].Id = primitive.NewObjectID().Hex()
+	fixture.writeBackup(t)
+
+	result, err := RestoreBackup(fixture.backupFilePath, RestoreModeMerge)
+	if err == nil {
+		t.Fatalf("RestoreBackup() should refuse alice under another id")
+	}
+	if result == nil || !result.RolledBack || result.UsersRestored != 0 {
+		t.Errorf("RestoreBackup() result = %+v, want rolled back", result)
+	}
+	if alice := user_mapper.INSTANCE.GetUserByUsername("alice"); alice.Id != fixture.existingUser.Id {
+		t.Errorf("existing user = %+v, want alice unchanged", alice)
+	}
+	if bob := user_mapper.INSTANCE.GetUserByUsername("bob"); !bob.IsEmpty() {
+		t.Errorf("bob should not be restored when the restore is refused")
+	}
+}
+
+func TestRestoreBackupReplace(t *testing.T) {
+	fixture := setUpRestore(t)
+
+	result, err := RestoreBackup(fixture.backupFilePath, RestoreModeReplace)
+	if err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if result.CategoriesCleared != 1 || result.AccountsCleared != 1 || result.CashFlowsCleared != 1 ||
+		result.CategoriesRestored != 2 || result.AccountsRestored != 1 || result.CashFlowsRestored != 3 ||
+		result.CategoriesSkipped != 0 || result.CashFlowsSkipped != 0 || result.RolledBack {
+		t.Errorf("RestoreBackup() result = %+v", result)
+	}
+
+	if !category_mapper.INSTANCE.GetCategoryByObjectId(fixture.existingCategory.Id.Hex()).IsEmpty() {
+		t.Errorf("replace should clear the existing category")
+	}
+	if !account_mapper.INSTANCE.GetAccountByObjectId(fixture.existingAccount.Id.Hex()).IsEmpty() {
+		t.Errorf("replace should clear the existing account")
+	}
+	breakfast := cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(fixture.existingCashFlow.Id.Hex())
+	if breakfast.CategoryId.Hex() != fixture.backupFoodId {
+		t.Errorf("restored cash_flow category = %s, want the backup Food %s", breakfast.CategoryId.Hex(), fixture.backupFoodId)
+	}
+
+	if result.UsersCleared != 1 || result.UsersRestored != 2 || result.LedgerMembersRestored != 2 {
+		t.Errorf("RestoreBackup() access data result = %+v", result)
+	}
+	if count := user_mapper.INSTANCE.CountAllUsers(); count != 2 {
+		t.Errorf("CountAllUsers() = %d, want 2", count)
+	}
+}
+
+func TestRestoreBackupReplaceKeepsUsersOfOlderBackups(t *testing.T) {
+	fixture := setUpRestore(t)
+	fixture.backup.Version = "1.11.0"
+	fixture.backup.Users, fixture.backup.ApiTokens = nil, nil
+	fixture.backup.Ledgers, fixture.backup.LedgerMembers = nil, nil
+	fixture.writeBackup(t)
+
+	result, err := RestoreBackup(fixture.backupFilePath, RestoreModeReplace)
+	if err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if result.UsersCleared != 0 || result.UsersRestored != 0 || result.CategoriesCleared != 1 {
+		t.Errorf("RestoreBackup() result = %+v", result)
+	}
+	if alice := user_mapper.INSTANCE.GetUserByObjectId(fixture.existingUser.Id.Hex()); alice.Username != "alice" {
+		t.Errorf("existing user = %+v, want alice kept", alice)
+	}
+}
+
+func TestRestoreBackupRollback(t *testing.T) {
+	tests := []struct {
+		name string
+		mode string
+	}{
+		{"Merge", RestoreModeMerge},
+		{"Replace", RestoreModeReplace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := setUpRestore(t)
+			failures := 1
+			cash_flow_mapper.INSTANCE = failingCashFlowMapper{
+				CashFlowMemoryMapper: cash_flow_mapper.INSTANCE.(cash_flow_mapper.CashFlowMemoryMapper),
+				failures:             &failures,
+			}
+
+			result, err := RestoreBackup(fixture.backupFilePath, tt.mode)
+			if err == nil {
+				t.Fatalf("RestoreBackup() should fail when inserting cash_flows breaks off")
+			}
+			if result == nil || !result.RolledBack {
+				t.Fatalf("RestoreBackup() result = %+v, want rolled back", result)
+			}
+			if result.CategoriesRestored != 0 || result.AccountsRestored != 0 || result.CashFlowsRestored != 0 ||
+				result.CategoriesCleared != 0 || result.AccountsCleared != 0 || result.CashFlowsCleared != 0 {
+				t.Errorf("RestoreBackup() result = %+v, want nothing left applied", result)
+			}
+
+			// Categories and accounts were restored before cash_flows failed, they must be gone again
+			if !category_mapper.INSTANCE.GetCategoryByObjectId(fixture.backupTravelId).IsEmpty() {
+				t.Errorf("restored category Travel was not rolled back")
+			}
+			if !account_mapper.INSTANCE.GetAccountByObjectId(fixture.backupAccountId).IsEmpty() {
+				t.Errorf("restored account Bank was not rolled back")
+			}
+			for _, cashFlow := range fixture.backupCashFlows[1:] {
+				if !cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(cashFlow.Id).IsEmpty() {
+					t.Errorf("restored cash_flow %s was not rolled back", cashFlow.Description)
+				}
+			}
+
+			// What was in the database before is back as it was
+			if category := category_mapper.INSTANCE.GetCategoryByObjectId(fixture.existingCategory.Id.Hex()); category.Name != "Food" {
+				t.Errorf("existing category = %+v, want Food", category)
+			}
+			if account := account_mapper.INSTANCE.GetAccountByObjectId(fixture.existingAccount.Id.Hex()); account.Name != "Wallet" {
+				t.Errorf("existing account = %+v, want Wallet", account)
+			}
+			breakfast := cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(fixture.existingCashFlow.Id.Hex())
+			if breakfast.CategoryId != fixture.existingCategory.Id || breakfast.AccountId != fixture.existingAccount.Id {
+				t.Errorf("existing cash_flow = %+v, want it unchanged", breakfast)
+			}
+			if count := cash_flow_mapper.INSTANCE.CountAllCashFlows(); count != 1 {
+				t.Errorf("CountAllCashFlows() = %d after rollback, want 1", count)
+			}
+
+			// Access data is restored first, so it is rolled back too
+			if result.UsersRestored != 0 || result.UsersCleared != 0 || result.LedgerMembersRestored != 0 {
+				t.Errorf("RestoreBackup() result = %+v, want no access data left applied", result)
+			}
+			if bob := user_mapper.INSTANCE.GetUserByUsername("bob"); !bob.IsEmpty() {
+				t.Errorf("restored user bob was not rolled back")
+			}
+			if ledger := ledger_mapper.INSTANCE.GetLedgerByObjectId(fixture.backupLedgerId); !ledger.IsEmpty() {
+				t.Errorf("restored ledger was not rolled back")
+			}
+			if members := ledger_mapper.INSTANCE.GetAllLedgerMembers(0, 0); len(members) != 0 {
+				t.Errorf("GetAllLedgerMembers() = %+v after rollback, want none", members)
+			}
+			if apiToken := api_token_mapper.INSTANCE.GetApiTokenByHash("hash-t"); !apiToken.IsEmpty() {
+				t.Errorf("restored api token was not rolled back")
+			}
+			if alice := user_mapper.INSTANCE.GetUserByObjectId(fixture.existingUser.Id.Hex()); alice.Username != "alice" {
+				t.Errorf("existing user = %+v, want alice", alice)
+			}
+		})
+	}
+}
@@ -1,39 +1,141 @@
 package manage_service
 
-// DatabaseStats represents database statistics
+import (
+	"sort"
+
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DatabaseStats represents database statistics,
+// amounts are never added across currencies so every total is reported per currency
 type DatabaseStats struct {
-	CashFlowCount int
-	IncomeCount   int
-	ExpenseCount  int
-	CategoryCount int
-	TotalIncome   float64
-	TotalExpense  float64
-	Balance       float64
-	EarliestDate  string
-	LatestDate    string
+	CashFlowCount int64           `json:"cash_flow_count"`
+	IncomeCount   int64           `json:"income_count"`
+	ExpenseCount  int64           `json:"expense_count"`
+	CategoryCount int64           `json:"category_count"`
+	Totals        []CurrencyStats `json:"totals"`
+	EarliestDate  string          `json:"earliest_date"`
+	LatestDate    string          `json:"latest_date"`
+	Categories    []CategoryStats `json:"categories"`
+}
+
+// CurrencyStats represents the income, expense and balance recorded in one currency
+type CurrencyStats struct {
+	Currency     string          `json:"currency"`
+	TotalIncome  decimal.Decimal `json:"total_income"`
+	TotalExpense decimal.Decimal `json:"total_expense"`
+	Balance      decimal.Decimal `json:"balance"`
 }
 
-// GetDatabaseStats returns statistics about the database
-// Note: This is a simplified implementation that queries recent data
-// For production, this should use database aggregation for better performance
-func GetDatabaseStats() (*DatabaseStats, error) {
+// CategoryStats represents the cash flows recorded under one category in one currency
+type CategoryStats struct {
+	CategoryId    string          `json:"category_id"`
+	CategoryName  string          `json:"category_name"`
+	Currency      string          `json:"currency"`
+	CashFlowCount int64           `json:"cash_flow_count"`
+	TotalAmount   decimal.Decimal `json:"total_amount"`
+}
+
+// GetDatabaseStats returns statistics about the owner's ledger.
+// Every figure is aggregated by the database, no cash_flow is loaded into memory.
+func GetDatabaseStats(ownerPlainId string) (*DatabaseStats, error) {
 	stats := &DatabaseStats{
-		EarliestDate: "N/A",
-		LatestDate:   "N/A",
+		CategoryCount: category_mapper.INSTANCE.CountCategoriesByOwnerId(ownerPlainId),
+		EarliestDate:  "N/A",
+		LatestDate:    "N/A",
 	}
 
-	// Note: To properly implement this, we need:
-	// 1. Mapper methods to count by type
-	// 2. Mapper methods to get earliest/latest dates
-	// 3. Mapper method to get all categories count
+	applyTypeStats(stats, cash_flow_mapper.INSTANCE.GetCashFlowTypeStats(ownerPlainId))
 
-	// For now, return empty stats with a note
-	// TODO: Add aggregation methods to mappers:
-	//   - CountCashFlowsByType(flowType string) int64
-	//   - GetEarliestCashFlowDate() time.Time
-	//   - GetLatestCashFlowDate() time.Time
-	//   - CountAllCategories() int64
-	//   - GetAllCashFlowsForStats() []model.CashFlowEntity
+	earliest, latest := cash_flow_mapper.INSTANCE.GetCashFlowDateSpan(ownerPlainId)
+	if !earliest.IsZero() {
+		stats.EarliestDate = util.FormatDateToStringWithDash(earliest)
+	}
+	if !latest.IsZero() {
+		stats.LatestDate = util.FormatDateToStringWithDash(latest)
+	}
+
+	applyCategoryStats(stats, cash_flow_mapper.INSTANCE.GetCashFlowCategoryStats(ownerPlainId))
 
 	return stats, nil
 }
+
+// applyTypeStats fills counts and the per currency totals from the per flow type and currency aggregation,
+// records without a currency count towards the default currency
+func applyTypeStats(stats *DatabaseStats, typeStatList []model.CashFlowTypeStat) {
+	stats.Totals = make([]CurrencyStats, 0)
+	currencyIndexMap := make(map[string]int)
+	for _, typeStat := range typeStatList {
+		stats.CashFlowCount += typeStat.Count
+
+		currency := exchange_rate_service.NormalizeCurrency(typeStat.Currency)
+		index, isExist := currencyIndexMap[currency]
+		if !isExist {
+			index = len(stats.Totals)
+			currencyIndexMap[currency] = index
+			stats.Totals = append(stats.Totals, CurrencyStats{Currency: currency})
+		}
+
+		totals := &stats.Totals[index]
+		switch typeStat.FlowType {
+		case model.FlowTypeIncome:
+			stats.IncomeCount += typeStat.Count
+			totals.TotalIncome = totals.TotalIncome.Add(typeStat.TotalAmount)
+		case model.FlowTypeOutcome:
+			stats.ExpenseCount += typeStat.Count
+			totals.TotalExpense = totals.TotalExpense.Add(typeStat.TotalAmount)
+		}
+	}
+
+	for index := range stats.Totals {
+		stats.Totals[index].Balance = stats.Totals[index].TotalIncome.Sub(stats.Totals[index].TotalExpense)
+	}
+	sort.Slice(stats.Totals, func(i, j int) bool {
+		return stats.Totals[i].Currency < stats.Totals[j].Currency
+	})
+}
+
+// applyCategoryStats lists the per category and currency aggregation,
+// records without a currency are merged into the default currency of their category
+func applyCategoryStats(stats *DatabaseStats, categoryStatList []model.CashFlowCategoryStat) {
+	type categoryStatKey struct {
+		categoryId primitive.ObjectID
+		currency   string
+	}
+
+	stats.Categories = make([]CategoryStats, 0, len(categoryStatList))
+	categoryIndexMap := make(map[categoryStatKey]int)
+	for _, categoryStat := range categoryStatList {
+		key := categoryStatKey{
+			categoryId: categoryStat.CategoryId,
+			currency:   exchange_rate_service.NormalizeCurrency(categoryStat.Currency),
+		}
+		if index, isExist := categoryIndexMap[key]; isExist {
+			stats.Categories[index].CashFlowCount += categoryStat.Count
+			stats.Categories[index].TotalAmount = stats.Categories[index].TotalAmount.Add(categoryStat.TotalAmount)
+			continue
+		}
+
+		categoryIndexMap[key] = len(stats.Categories)
+		stats.Categories = append(stats.Categories, CategoryStats{
+			CategoryId:    convertObjectId2Plain(categoryStat.CategoryId),
+			CategoryName:  getCategoryName(categoryStat.CategoryId),
+			Currency:      key.currency,
+			CashFlowCount: categoryStat.Count,
+			TotalAmount:   categoryStat.TotalAmount,
+		})
+	}
+}
+
+func getCategoryName(categoryId primitive.ObjectID) string {
+	if categoryId == primitive.NilObjectID {
+		return ""
+	}
+	return category_mapper.INSTANCE.GetCategoryByObjectId(categoryId.Hex()).Name
+}
@@ -0,0 +1,97 @@
+package manage_service
+
+import (
+	"testing"
+
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestApplyTypeStats(t *testing.T) {
+	stats := &DatabaseStats{}
+	applyTypeStats(stats, []model.CashFlowTypeStat{
+		{FlowType: model.FlowTypeIncome, Currency: "EUR", Count: 1, TotalAmount: decimal.NewFromInt(200)},
+		{FlowType: model.FlowTypeIncome, Currency: "JPY", Count: 2, TotalAmount: decimal.NewFromInt(1300)},
+		{FlowType: model.FlowTypeOutcome, Currency: "EUR", Count: 4, TotalAmount: decimal.NewFromFloat(20.5)},
+		{FlowType: model.FlowTypeOutcome, Currency: "JPY", Count: 1, TotalAmount: decimal.NewFromInt(400)},
+	})
+
+	if stats.CashFlowCount != 8 {
+		t.Errorf("CashFlowCount = %d, want 8", stats.CashFlowCount)
+	}
+	if stats.IncomeCount != 3 || stats.ExpenseCount != 5 {
+		t.Errorf("IncomeCount/ExpenseCount = %d/%d, want 3/5", stats.IncomeCount, stats.ExpenseCount)
+	}
+
+	tests := []struct {
+		currency     string
+		totalIncome  string
+		totalExpense string
+		balance      string
+	}{
+		{"EUR", "200", "20.5", "179.5"},
+		{"JPY", "1300", "400", "900"},
+	}
+	if len(stats.Totals) != len(tests) {
+		t.Fatalf("Totals = %+v, want one entry per currency", stats.Totals)
+	}
+	for index, tt := range tests {
+		totals := stats.Totals[index]
+		if totals.Currency != tt.currency ||
+			!totals.TotalIncome.Equal(decimal.RequireFromString(tt.totalIncome)) ||
+			!totals.TotalExpense.Equal(decimal.RequireFromString(tt.totalExpense)) ||
+			!totals.Balance.Equal(decimal.RequireFromString(tt.balance)) {
+			t.Errorf("Totals[%d] = %+v, want %s %s/%s/%s", index, totals, tt.currency, tt.totalIncome, tt.totalExpense, tt.balance)
+		}
+	}
+}
+
+func TestApplyTypeStatsBlankCurrency(t *testing.T) {
+	defaultCurrency := util.GetConfigByKey("currency.default")
+
+	stats := &DatabaseStats{}
+	applyTypeStats(stats, []model.CashFlowTypeStat{
+		{FlowType: model.FlowTypeIncome, Currency: "", Count: 1, TotalAmount: decimal.NewFromInt(100)},
+		{FlowType: model.FlowTypeIncome, Currency: defaultCurrency, Count: 1, TotalAmount: decimal.NewFromInt(50)},
+	})
+
+	if len(stats.Totals) != 1 || stats.Totals[0].Currency != defaultCurrency || !stats.Totals[0].TotalIncome.Equal(decimal.NewFromInt(150)) {
+		t.Errorf("Totals = %+v, want 150 in %s", stats.Totals, defaultCurrency)
+	}
+}
+
+func TestApplyTypeStatsEmpty(t *testing.T) {
+	stats := &DatabaseStats{}
+	applyTypeStats(stats, nil)
+
+	if stats.CashFlowCount != 0 || stats.Totals == nil || len(stats.Totals) != 0 {
+		t.Errorf("expected zero stats, got %+v", stats)
+	}
+}
+
+func TestApplyCategoryStats(t *testing.T) {
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	defaultCurrency := util.GetConfigByKey("currency.default")
+	categoryId := primitive.NewObjectID()
+
+	stats := &DatabaseStats{}
+	applyCategoryStats(stats, []model.CashFlowCategoryStat{
+		{CategoryId: categoryId, Currency: "", Count: 2, TotalAmount: decimal.NewFromInt(30)},
+		{CategoryId: categoryId, Currency: "EUR", Count: 1, TotalAmount: decimal.NewFromInt(7)},
+		{CategoryId: categoryId, Currency: defaultCurrency, Count: 1, TotalAmount: decimal.NewFromInt(5)},
+	})
+
+	if len(stats.Categories) != 2 {
+		t.Fatalf("Categories = %+v, want one entry per currency", stats.Categories)
+	}
+	if stats.Categories[0].Currency != defaultCurrency || stats.Categories[0].CashFlowCount != 3 ||
+		!stats.Categories[0].TotalAmount.Equal(decimal.NewFromInt(35)) {
+		t.Errorf("Categories[0] = %+v, want 3 records totalling 35 %s", stats.Categories[0], defaultCurrency)
+	}
+	if stats.Categories[1].Currency != "EUR" || !stats.Categories[1].TotalAmount.Equal(decimal.NewFromInt(7)) {
+		t.Errorf("Categories[1] = %+v, want 7 EUR", stats.Categories[1])
+	}
+}
@@ -0,0 +1,42 @@
+package payee_service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+)
+
+// ApplyRulesService runs the payee rules over the owner's cash_flows and returns how many were changed.
+// Only cash_flows without a payee are looked at, unless overwrite also lets the rules replace a payee.
+// A cash_flow no rule matches keeps its payee, transfers never get one.
+func ApplyRulesService(ownerPlainId string, overwrite bool) (int, error) {
+	matcher := NewPayeeMatcher(ownerPlainId)
+
+	updatedCount := 0
+	for _, cashFlow := range cash_flow_mapper.INSTANCE.GetCashFlowsByOwnerId(ownerPlainId, 0, 0) {
+		if cashFlow.FlowType == model.FlowTypeTransfer {
+			continue
+		}
+		if !cashFlow.PayeeId.IsZero() && !overwrite {
+			continue
+		}
+
+		payeeId := matcher.Match(cashFlow.Description)
+		if payeeId.IsZero() || payeeId == cashFlow.PayeeId {
+			continue
+		}
+
+		cashFlow.PayeeId = payeeId
+		cashFlow.ModifyTime = time.Now()
+		if cash_flow_mapper.INSTANCE.UpdateCashFlowByEntity(cashFlow.Id.Hex(), cashFlow).IsEmpty() {
+			return updatedCount, errors.New("failed to update cash_flow " + cashFlow.Id.Hex())
+		}
+		updatedCount++
+	}
+
+	util.Logger.Infow("payee rules applied", "overwrite", overwrite, "updated", updatedCount)
+	return updatedCount, nil
+}
@@ -0,0 +1,49 @@
+package payee_service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// CreateService creates a payee in the owner's ledger, its rules are tried in the order given
+func CreateService(ownerPlainId string, payeeDTO model.PayeeDTO) (model.PayeeEntity, error) {
+	if err := validation.ValidatePayeeName(payeeDTO.Name); err != nil {
+		return model.PayeeEntity{}, err
+	}
+	if !payee_mapper.INSTANCE.GetPayeeByName(ownerPlainId, payeeDTO.Name).IsEmpty() {
+		return model.PayeeEntity{}, errors.New("payee already exists")
+	}
+
+	ruleList, err := normalizeRules(payeeDTO.Rules)
+	if err != nil {
+		return model.PayeeEntity{}, err
+	}
+
+	newPlainId := payee_mapper.INSTANCE.InsertPayeeByEntity(model.PayeeEntity{
+		OwnerId: util.ConvertOwner2ObjectId(ownerPlainId),
+		Name:    payeeDTO.Name,
+		Rules:   ruleList,
+	})
+	if newPlainId == "" {
+		return model.PayeeEntity{}, errors.New("payee create failed")
+	}
+	return payee_mapper.INSTANCE.GetPayeeByObjectId(newPlainId), nil
+}
+
+// normalizeRules upper-cases the match types and validates every rule, no rules at all gives nil
+func normalizeRules(ruleList []model.PayeeRule) ([]model.PayeeRule, error) {
+	var normalizedRules []model.PayeeRule
+	for _, rule := range ruleList {
+		rule.MatchType = strings.ToUpper(strings.TrimSpace(rule.MatchType))
+		if err := validation.ValidatePayeeRule(rule.MatchType, rule.Pattern); err != nil {
+			return nil, err
+		}
+		normalizedRules = append(normalizedRules, rule)
+	}
+	return normalizedRules, nil
+}
@@ -0,0 +1,36 @@
+package payee_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// DeleteService deletes one of the owner's payees which no cash_flow or category rule refers to
+func DeleteService(ownerPlainId, plainId string) (model.PayeeEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.PayeeEntity{}, err
+	}
+
+	existingPayee := payee_mapper.INSTANCE.GetPayeeByObjectId(plainId)
+	if existingPayee.IsEmpty() || !existingPayee.IsOwnedBy(ownerPlainId) {
+		return model.PayeeEntity{}, errors.New("payee not found")
+	}
+
+	if cash_flow_mapper.INSTANCE.CountCashFlowsByPayeeId(plainId) != 0 {
+		return model.PayeeEntity{}, errors.New("can not delete a payee which has cash_flows refer to")
+	}
+	if category_rule_mapper.INSTANCE.CountCategoryRulesByPayeeId(plainId) != 0 {
+		return model.PayeeEntity{}, errors.New("can not delete a payee which has category rules refer to")
+	}
+
+	deletedPayee := payee_mapper.INSTANCE.DeletePayeeByObjectId(plainId)
+	if deletedPayee.IsEmpty() {
+		return model.PayeeEntity{}, errors.New("payee delete failed")
+	}
+	return deletedPayee, nil
+}
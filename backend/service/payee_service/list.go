@@ -0,0 +1,17 @@
+package payee_service
+
+import (
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/model"
+)
+
+// ListAllService lists the owner's payees with pagination
+func ListAllService(ownerPlainId string, limit, offset int) ([]model.PayeeEntity, int64, error) {
+	totalCount := payee_mapper.INSTANCE.CountPayeesByOwnerId(ownerPlainId)
+
+	payeeEntityList := payee_mapper.INSTANCE.GetPayeesByOwnerId(ownerPlainId, limit, offset)
+	if payeeEntityList == nil {
+		payeeEntityList = []model.PayeeEntity{}
+	}
+	return payeeEntityList, totalCount, nil
+}
@@ -0,0 +1,81 @@
+package payee_service
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PayeeMatcher recognises payees in cash_flow descriptions, it is built once and reused across many records
+type PayeeMatcher struct {
+	ruleList []compiledRule
+}
+
+type compiledRule struct {
+	payeeId  primitive.ObjectID
+	contains string         // lowercase pattern of a CONTAINS rule
+	regex    *regexp.Regexp // compiled pattern of a REGEX rule
+}
+
+// NewPayeeMatcher loads the rules of the owner's payees, payees are tried by name and their rules in the order stored
+func NewPayeeMatcher(ownerPlainId string) PayeeMatcher {
+	payeeList := payee_mapper.INSTANCE.GetPayeesByOwnerId(ownerPlainId, 0, 0)
+	sort.SliceStable(payeeList, func(i, j int) bool {
+		return payeeList[i].Name < payeeList[j].Name
+	})
+
+	var matcher PayeeMatcher
+	for _, payee := range payeeList {
+		for _, rule := range payee.Rules {
+			switch rule.MatchType {
+			case model.PayeeRuleContains:
+				matcher.ruleList = append(matcher.ruleList, compiledRule{
+					payeeId:  payee.Id,
+					contains: strings.ToLower(rule.Pattern),
+				})
+			case model.PayeeRuleRegex:
+				regex, err := regexp.Compile(rule.Pattern)
+				if err != nil {
+					// Stored before validation or restored from a backup, it can not match anything
+					util.Logger.Warnw("payee rule skipped, invalid regular expression",
+						"payee", payee.Name, "pattern", rule.Pattern)
+					continue
+				}
+				matcher.ruleList = append(matcher.ruleList, compiledRule{payeeId: payee.Id, regex: regex})
+			}
+		}
+	}
+	return matcher
+}
+
+// Match gives the payee of the first rule matching description, or the nil id when none does
+func (matcher PayeeMatcher) Match(description string) primitive.ObjectID {
+	if description == "" {
+		return primitive.NilObjectID
+	}
+
+	lowerDescription := strings.ToLower(description)
+	for _, rule := range matcher.ruleList {
+		if rule.regex != nil {
+			if rule.regex.MatchString(description) {
+				return rule.payeeId
+			}
+		} else if strings.Contains(lowerDescription, rule.contains) {
+			return rule.payeeId
+		}
+	}
+	return primitive.NilObjectID
+}
+
+// MatchPayeeId applies the owner's current payee rules to a single description
+func MatchPayeeId(ownerPlainId, description string) primitive.ObjectID {
+	if description == "" {
+		return primitive.NilObjectID
+	}
+	return NewPayeeMatcher(ownerPlainId).Match(description)
+}
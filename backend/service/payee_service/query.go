@@ -0,0 +1,31 @@
+package payee_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// QueryService finds one of the owner's payees by either its id or its name
+func QueryService(ownerPlainId, plainId, payeeName string) (model.PayeeEntity, error) {
+	if (plainId == "") == (payeeName == "") {
+		return model.PayeeEntity{}, errors.New("should have one and only one query type")
+	}
+
+	var payeeEntity model.PayeeEntity
+	if plainId != "" {
+		if err := validation.ValidateID(plainId); err != nil {
+			return model.PayeeEntity{}, err
+		}
+		payeeEntity = payee_mapper.INSTANCE.GetPayeeByObjectId(plainId)
+	} else {
+		payeeEntity = payee_mapper.INSTANCE.GetPayeeByName(ownerPlainId, payeeName)
+	}
+
+	if payeeEntity.IsEmpty() || !payeeEntity.IsOwnedBy(ownerPlainId) {
+		return model.PayeeEntity{}, errors.New("payee not found")
+	}
+	return payeeEntity, nil
+}
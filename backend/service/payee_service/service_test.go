@@ -0,0 +1,167 @@
+package payee_service
+
+import (
+	"testing"
+
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// resetMappers gives each test empty in-memory storage
+func resetMappers() {
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	category_rule_mapper.INSTANCE = category_rule_mapper.NewCategoryRuleMemoryMapper()
+	payee_mapper.INSTANCE = payee_mapper.NewPayeeMemoryMapper()
+	exchange_rate_mapper.INSTANCE = exchange_rate_mapper.NewExchangeRateMemoryMapper()
+}
+
+func book(t *testing.T, belongsDate, flowType, amount, description string, payeeId primitive.ObjectID) string {
+	t.Helper()
+	plainId := cash_flow_mapper.INSTANCE.InsertCashFlowByEntity(model.CashFlowEntity{
+		BelongsDate: util.FormatDateFromStringWithDash(belongsDate),
+		FlowType:    flowType,
+		Amount:      decimal.RequireFromString(amount),
+		Description: description,
+		PayeeId:     payeeId,
+	})
+	if plainId == "" {
+		t.Fatalf("insert cash_flow %q failed", description)
+	}
+	return plainId
+}
+
+func TestPayeeLifecycle(t *testing.T) {
+	resetMappers()
+
+	created, err := CreateService("", model.PayeeDTO{Name: "Starbucks",
+		Rules: []model.PayeeRule{{MatchType: "contains", Pattern: "starbucks"}}})
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+	if len(created.Rules) != 1 || created.Rules[0].MatchType != model.PayeeRuleContains {
+		t.Errorf("CreateService() rules = %+v, want one CONTAINS rule", created.Rules)
+	}
+
+	tests := []struct {
+		name     string
+		payeeDTO model.PayeeDTO
+	}{
+		{"duplicate name", model.PayeeDTO{Name: "Starbucks"}},
+		{"invalid name", model.PayeeDTO{Name: "Star*bucks"}},
+		{"invalid regex", model.PayeeDTO{Name: "Amazon",
+			Rules: []model.PayeeRule{{MatchType: model.PayeeRuleRegex, Pattern: "amzn("}}}},
+		{"unknown match type", model.PayeeDTO{Name: "Amazon",
+			Rules: []model.PayeeRule{{MatchType: "PREFIX", Pattern: "amzn"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CreateService("", tt.payeeDTO); err == nil {
+				t.Errorf("CreateService() expected an error")
+			}
+		})
+	}
+
+	// Nil rules keep the current ones
+	renamed, err := UpdateService("", created.Id.Hex(), model.PayeeDTO{Name: "Starbucks Coffee"})
+	if err != nil {
+		t.Fatalf("UpdateService() error = %v", err)
+	}
+	if renamed.Name != "Starbucks Coffee" || len(renamed.Rules) != 1 {
+		t.Errorf("UpdateService() = %+v, want renamed with its rule kept", renamed)
+	}
+	if _, err := QueryService("", "", "Starbucks Coffee"); err != nil {
+		t.Errorf("QueryService() by name error = %v", err)
+	}
+
+	book(t, "2024-05-01", model.FlowTypeOutcome, "4.5", "coffee", created.Id)
+	if _, err := DeleteService("", created.Id.Hex()); err == nil {
+		t.Errorf("DeleteService() of a payee in use expected an error")
+	}
+}
+
+func TestPayeeMatcher(t *testing.T) {
+	resetMappers()
+
+	starbucks, _ := CreateService("", model.PayeeDTO{Name: "Starbucks",
+		Rules: []model.PayeeRule{{MatchType: model.PayeeRuleContains, Pattern: "Starbucks"}}})
+	amazon, _ := CreateService("", model.PayeeDTO{Name: "Amazon",
+		Rules: []model.PayeeRule{{MatchType: model.PayeeRuleRegex, Pattern: `(?i)^(amzn|amazon)\b`}}})
+
+	tests := []struct {
+		description string
+		want        primitive.ObjectID
+	}{
+		{"STARBUCKS #1234", starbucks.Id},
+		{"Starbucks Coffee", starbucks.Id},
+		{"AMZN Mktp US", amazon.Id},
+		{"amazon.com order", amazon.Id},
+		{"Paid back to amazon", primitive.NilObjectID},
+		{"", primitive.NilObjectID},
+	}
+	matcher := NewPayeeMatcher("")
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			if got := matcher.Match(tt.description); got != tt.want {
+				t.Errorf("Match(%q) = %s, want %s", tt.description, got.Hex(), tt.want.Hex())
+			}
+		})
+	}
+}
+
+func TestApplyRulesAndSpendByPayee(t *testing.T) {
+	resetMappers()
+
+	starbucks, _ := CreateService("", model.PayeeDTO{Name: "Starbucks",
+		Rules: []model.PayeeRule{{MatchType: model.PayeeRuleContains, Pattern: "starbucks"}}})
+	manual, _ := CreateService("", model.PayeeDTO{Name: "Corner Cafe"})
+
+	book(t, "2024-05-01", model.FlowTypeOutcome, "4.5", "STARBUCKS #1234", primitive.NilObjectID)
+	book(t, "2024-05-02", model.FlowTypeOutcome, "6", "Starbucks Coffee", primitive.NilObjectID)
+	book(t, "2024-05-03", model.FlowTypeOutcome, "12", "starbucks beans", manual.Id)
+	book(t, "2024-05-04", model.FlowTypeOutcome, "3", "parking", primitive.NilObjectID)
+	book(t, "2024-05-05", model.FlowTypeIncome, "100", "Starbucks refund", primitive.NilObjectID)
+	book(t, "2024-06-01", model.FlowTypeOutcome, "5", "STARBUCKS #99", primitive.NilObjectID)
+
+	// The income also matches, a payee is not limited to outcomes
+	if updated, err := ApplyRulesService("", false); err != nil || updated != 4 {
+		t.Fatalf("ApplyRulesService(false) = %d, %v, want 4 updated", updated, err)
+	}
+	if count := cash_flow_mapper.INSTANCE.CountCashFlowsByPayeeId(manual.Id.Hex()); count != 1 {
+		t.Errorf("a payee set by hand was overwritten, Corner Cafe count = %d", count)
+	}
+	if updated, _ := ApplyRulesService("", false); updated != 0 {
+		t.Errorf("ApplyRulesService(false) second run = %d, want 0", updated)
+	}
+
+	spendList, err := SpendByPayeeService("", "2024-05-01", "2024-05-31", "")
+	if err != nil {
+		t.Fatalf("SpendByPayeeService() error = %v", err)
+	}
+	want := []struct {
+		payeeName string
+		total     string
+		count     int
+	}{{"Corner Cafe", "12", 1}, {"Starbucks", "10.5", 2}, {"", "3", 1}}
+	if len(spendList) != len(want) {
+		t.Fatalf("SpendByPayeeService() = %+v, want %d payees", spendList, len(want))
+	}
+	for i, payeeSpend := range spendList {
+		if payeeSpend.PayeeName != want[i].payeeName || payeeSpend.TransactionCount != want[i].count ||
+			!payeeSpend.TotalAmount.Equal(decimal.RequireFromString(want[i].total)) {
+			t.Errorf("SpendByPayeeService()[%d] = %+v, want %+v", i, payeeSpend, want[i])
+		}
+	}
+	if spendList[1].PayeeId != starbucks.Id.Hex() {
+		t.Errorf("SpendByPayeeService()[1] payee = %s, want %s", spendList[1].PayeeId, starbucks.Id.Hex())
+	}
+
+	if updated, _ := ApplyRulesService("", true); updated != 1 {
+		t.Errorf("ApplyRulesService(true) = %d, want the Corner Cafe record moved to Starbucks", updated)
+	}
+}
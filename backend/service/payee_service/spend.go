@@ -0,0 +1,75 @@
+package payee_service
+
+import (
+	"sort"
+
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/exchange_rate_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PayeeSpend is what was spent on one payee, cash_flows without a payee are grouped under a blank PayeeId
+type PayeeSpend struct {
+	PayeeId          string          `json:"payee_id"`
+	PayeeName        string          `json:"payee_name"`
+	Currency         string          `json:"currency"`
+	TotalAmount      decimal.Decimal `json:"total_amount"`
+	TransactionCount int             `json:"transaction_count"`
+}
+
+// SpendByPayeeService adds up the owner's outcomes between from and to per payee, biggest spend first.
+// Every amount is converted into currency (the default currency when blank) at the rate of its BelongsDate.
+func SpendByPayeeService(ownerPlainId, from, to, currency string) ([]PayeeSpend, error) {
+	if err := validation.ValidateDateRange(from, to); err != nil {
+		return nil, err
+	}
+	currency = exchange_rate_service.NormalizeCurrency(currency)
+	if err := validation.ValidateCurrency(currency); err != nil {
+		return nil, err
+	}
+
+	fromDate := util.FormatDateFromStringWithOptionalDash(from)
+	toDate := util.FormatDateFromStringWithOptionalDash(to)
+
+	spendMap := make(map[primitive.ObjectID]*PayeeSpend)
+	for _, cashFlow := range cash_flow_mapper.INSTANCE.GetCashFlowsByDateRange(ownerPlainId, fromDate, toDate) {
+		if cashFlow.FlowType != model.FlowTypeOutcome {
+			continue
+		}
+
+		convertedAmount, err := exchange_rate_service.ConvertAmount(
+			ownerPlainId, cashFlow.Amount, cashFlow.Currency, currency, cashFlow.BelongsDate)
+		if err != nil {
+			return nil, err
+		}
+
+		payeeSpend, found := spendMap[cashFlow.PayeeId]
+		if !found {
+			payeeSpend = &PayeeSpend{Currency: currency}
+			if !cashFlow.PayeeId.IsZero() {
+				payeeSpend.PayeeId = cashFlow.PayeeId.Hex()
+				payeeSpend.PayeeName = payee_mapper.INSTANCE.GetPayeeByObjectId(cashFlow.PayeeId.Hex()).Name
+			}
+			spendMap[cashFlow.PayeeId] = payeeSpend
+		}
+		payeeSpend.TotalAmount = payeeSpend.TotalAmount.Add(convertedAmount)
+		payeeSpend.TransactionCount++
+	}
+
+	spendList := make([]PayeeSpend, 0, len(spendMap))
+	for _, payeeSpend := range spendMap {
+		spendList = append(spendList, *payeeSpend)
+	}
+	sort.SliceStable(spendList, func(i, j int) bool {
+		if !spendList[i].TotalAmount.Equal(spendList[j].TotalAmount) {
+			return spendList[i].TotalAmount.GreaterThan(spendList[j].TotalAmount)
+		}
+		return spendList[i].PayeeName < spendList[j].PayeeName
+	})
+	return spendList, nil
+}
@@ -0,0 +1,48 @@
+package payee_service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// UpdateService updates one of the owner's payees by ID, a blank name keeps the current one.
+// Nil rules keep the current ones and empty rules clear them, cash_flows already assigned keep their payee.
+func UpdateService(ownerPlainId, plainId string, payeeDTO model.PayeeDTO) (model.PayeeEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.PayeeEntity{}, err
+	}
+
+	existingPayee := payee_mapper.INSTANCE.GetPayeeByObjectId(plainId)
+	if existingPayee.IsEmpty() || !existingPayee.IsOwnedBy(ownerPlainId) {
+		return model.PayeeEntity{}, errors.New("payee not found")
+	}
+
+	if payeeDTO.Name != "" && payeeDTO.Name != existingPayee.Name {
+		if err := validation.ValidatePayeeName(payeeDTO.Name); err != nil {
+			return model.PayeeEntity{}, err
+		}
+		if !payee_mapper.INSTANCE.GetPayeeByName(ownerPlainId, payeeDTO.Name).IsEmpty() {
+			return model.PayeeEntity{}, errors.New("payee already exists")
+		}
+		existingPayee.Name = payeeDTO.Name
+	}
+
+	if payeeDTO.Rules != nil {
+		ruleList, err := normalizeRules(payeeDTO.Rules)
+		if err != nil {
+			return model.PayeeEntity{}, err
+		}
+		existingPayee.Rules = ruleList
+	}
+	existingPayee.ModifyTime = time.Now()
+
+	updatedEntity := payee_mapper.INSTANCE.UpdatePayeeByEntity(plainId, existingPayee)
+	if updatedEntity.IsEmpty() {
+		return model.PayeeEntity{}, errors.New("failed to update payee")
+	}
+	return updatedEntity, nil
+}
@@ -0,0 +1,135 @@
+package recurring_rule_service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// CreateService creates a recurring rule in the owner's ledger on one of the owner's categories,
+// its occurrences are booked into that ledger when the rules run.
+// The start date defaults to today, the end date and occurrence limit are optional.
+func CreateService(ownerPlainId string, ruleDTO model.RecurringRuleDTO) (model.RecurringRuleEntity, error) {
+	if err := validation.ValidateRecurringRuleName(ruleDTO.Name); err != nil {
+		return model.RecurringRuleEntity{}, err
+	}
+	if !recurring_rule_mapper.INSTANCE.GetRecurringRuleByName(ownerPlainId, ruleDTO.Name).IsEmpty() {
+		return model.RecurringRuleEntity{}, errors.New("recurring rule already exists")
+	}
+
+	flowType, err := normalizeFlowType(ruleDTO.FlowType)
+	if err != nil {
+		return model.RecurringRuleEntity{}, err
+	}
+
+	if err := validation.ValidateAmount(ruleDTO.Amount); err != nil {
+		return model.RecurringRuleEntity{}, err
+	}
+	if err := validation.ValidateDescription(ruleDTO.Description); err != nil {
+		return model.RecurringRuleEntity{}, err
+	}
+
+	// 必填參數: 類別
+	if err := validation.ValidateCategoryName(ruleDTO.CategoryName); err != nil {
+		return model.RecurringRuleEntity{}, err
+	}
+	categoryEntity := category_mapper.INSTANCE.GetCategoryByName(ownerPlainId, ruleDTO.CategoryName)
+	if categoryEntity.IsEmpty() {
+		return model.RecurringRuleEntity{}, errors.New("category does not exist")
+	}
+
+	// 選填參數: 帳戶與幣別（有帳戶時跟隨帳戶）
+	accountId, err := cash_flow_service.GetAccountIdByName(ownerPlainId, ruleDTO.AccountName)
+	if err != nil {
+		return model.RecurringRuleEntity{}, err
+	}
+	currency, err := cash_flow_service.ResolveCurrency(accountId, ruleDTO.Currency)
+	if err != nil {
+		return model.RecurringRuleEntity{}, err
+	}
+
+	newEntity := model.RecurringRuleEntity{
+		OwnerId:     util.ConvertOwner2ObjectId(ownerPlainId),
+		Name:        ruleDTO.Name,
+		FlowType:    flowType,
+		CategoryId:  categoryEntity.Id,
+		AccountId:   accountId,
+		Amount:      ruleDTO.Amount.Round(2),
+		Currency:    currency,
+		Description: ruleDTO.Description,
+		// 選填參數: 開始日期（默認當天）
+		StartDate: util.FormatDateFromStringWithoutDash(util.FormatDateToStringWithoutDash(time.Now())),
+	}
+	if err := applySchedule(&newEntity, ruleDTO.Frequency, ruleDTO.DayOfMonth, ruleDTO.StartDate); err != nil {
+		return model.RecurringRuleEntity{}, err
+	}
+	if err := applyEnding(&newEntity, ruleDTO.EndDate, ruleDTO.OccurrenceLimit); err != nil {
+		return model.RecurringRuleEntity{}, err
+	}
+
+	newPlainId := recurring_rule_mapper.INSTANCE.InsertRecurringRuleByEntity(newEntity)
+	if newPlainId == "" {
+		return model.RecurringRuleEntity{}, errors.New("recurring rule create failed")
+	}
+	return recurring_rule_mapper.INSTANCE.GetRecurringRuleByObjectId(newPlainId), nil
+}
+
+// normalizeFlowType accepts INCOME or OUTCOME in any case, transfers do not recur
+func normalizeFlowType(flowType string) (string, error) {
+	flowType = strings.ToUpper(strings.TrimSpace(flowType))
+	if flowType != model.FlowTypeIncome && flowType != model.FlowTypeOutcome {
+		return "", validation.NewValidationError("flow_type", "must be INCOME or OUTCOME")
+	}
+	return flowType, nil
+}
+
+// applySchedule sets the frequency, day of month and start date, a blank start date keeps the current one
+func applySchedule(entity *model.RecurringRuleEntity, frequency string, dayOfMonth int, startDate string) error {
+	frequency = strings.ToUpper(strings.TrimSpace(frequency))
+	if err := validation.ValidateFrequency(frequency, dayOfMonth); err != nil {
+		return err
+	}
+	// the day of month only means something to monthly rules
+	if frequency != model.FrequencyMonthly {
+		dayOfMonth = 0
+	}
+
+	if startDate != "" {
+		if err := validation.ValidateDate(startDate); err != nil {
+			return err
+		}
+		entity.StartDate = util.FormatDateFromStringWithOptionalDash(startDate)
+	}
+
+	entity.Frequency = frequency
+	entity.DayOfMonth = dayOfMonth
+	return nil
+}
+
+// applyEnding sets the optional end date and occurrence limit, blank or nil values keep the current ones
+func applyEnding(entity *model.RecurringRuleEntity, endDate string, occurrenceLimit *int) error {
+	if endDate != "" {
+		if err := validation.ValidateDate(endDate); err != nil {
+			return err
+		}
+		entity.EndDate = util.FormatDateFromStringWithOptionalDash(endDate)
+	}
+	if !entity.EndDate.IsZero() && entity.EndDate.Before(entity.StartDate) {
+		return validation.NewValidationError("end_date", "cannot be before start_date")
+	}
+
+	if occurrenceLimit != nil {
+		if err := validation.ValidateOccurrenceLimit(*occurrenceLimit); err != nil {
+			return err
+		}
+		entity.OccurrenceLimit = *occurrenceLimit
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package recurring_rule_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// DeleteService deletes one of the owner's recurring rules, the cash_flows it already booked are kept
+func DeleteService(ownerPlainId, plainId string) (model.RecurringRuleEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.RecurringRuleEntity{}, err
+	}
+
+	existingRule := recurring_rule_mapper.INSTANCE.GetRecurringRuleByObjectId(plainId)
+	if existingRule.IsEmpty() || !existingRule.IsOwnedBy(ownerPlainId) {
+		return model.RecurringRuleEntity{}, errors.New("recurring rule not found")
+	}
+
+	deletedRule := recurring_rule_mapper.INSTANCE.DeleteRecurringRuleByObjectId(plainId)
+	if deletedRule.IsEmpty() {
+		return model.RecurringRuleEntity{}, errors.New("recurring rule delete failed")
+	}
+	return deletedRule, nil
+}
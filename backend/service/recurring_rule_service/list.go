@@ -0,0 +1,17 @@
+package recurring_rule_service
+
+import (
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/model"
+)
+
+// ListAllService lists the owner's recurring rules with pagination
+func ListAllService(ownerPlainId string, limit, offset int) ([]model.RecurringRuleEntity, int64, error) {
+	totalCount := recurring_rule_mapper.INSTANCE.CountRecurringRulesByOwnerId(ownerPlainId)
+
+	ruleEntityList := recurring_rule_mapper.INSTANCE.GetRecurringRulesByOwnerId(ownerPlainId, limit, offset)
+	if ruleEntityList == nil {
+		ruleEntityList = []model.RecurringRuleEntity{}
+	}
+	return ruleEntityList, totalCount, nil
+}
@@ -0,0 +1,31 @@
+package recurring_rule_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// QueryService finds one of the owner's recurring rules by either its id or its name
+func QueryService(ownerPlainId, plainId, ruleName string) (model.RecurringRuleEntity, error) {
+	if (plainId == "") == (ruleName == "") {
+		return model.RecurringRuleEntity{}, errors.New("should have one and only one query type")
+	}
+
+	var ruleEntity model.RecurringRuleEntity
+	if plainId != "" {
+		if err := validation.ValidateID(plainId); err != nil {
+			return model.RecurringRuleEntity{}, err
+		}
+		ruleEntity = recurring_rule_mapper.INSTANCE.GetRecurringRuleByObjectId(plainId)
+	} else {
+		ruleEntity = recurring_rule_mapper.INSTANCE.GetRecurringRuleByName(ownerPlainId, ruleName)
+	}
+
+	if ruleEntity.IsEmpty() || !ruleEntity.IsOwnedBy(ownerPlainId) {
+		return model.RecurringRuleEntity{}, errors.New("recurring rule not found")
+	}
+	return ruleEntity, nil
+}
@@ -0,0 +1,125 @@
+package recurring_rule_service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/payee_service"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// runMutex keeps the server's ticker and a run requested through the API from booking side by side
+var runMutex sync.Mutex
+
+// RunService books the occurrences of the owner's rules due on or before untilDate (today when blank) as cash_flows.
+// It is safe to run again: each occurrence is booked under an id derived from the rule and the date,
+// and an occurrence whose cash_flow already exists is not booked twice.
+func RunService(ownerPlainId, untilDate string) ([]model.CashFlowEntity, error) {
+	until, err := parseUntilDate(untilDate)
+	if err != nil {
+		return nil, err
+	}
+
+	runMutex.Lock()
+	defer runMutex.Unlock()
+	return runRules(recurring_rule_mapper.INSTANCE.GetRecurringRulesByOwnerId(ownerPlainId, 0, 0), until)
+}
+
+// RunAllService books the due occurrences of every owner's rules, for the server's ticker and the command line
+func RunAllService(untilDate string) ([]model.CashFlowEntity, error) {
+	until, err := parseUntilDate(untilDate)
+	if err != nil {
+		return nil, err
+	}
+
+	runMutex.Lock()
+	defer runMutex.Unlock()
+	return runRules(recurring_rule_mapper.INSTANCE.GetAllRecurringRules(0, 0), until)
+}
+
+// parseUntilDate reads the date to book up to, today when blank
+func parseUntilDate(untilDate string) (time.Time, error) {
+	if untilDate == "" {
+		return util.FormatDateFromStringWithoutDash(util.FormatDateToStringWithoutDash(time.Now())), nil
+	}
+	if err := validation.ValidateDate(untilDate); err != nil {
+		return time.Time{}, err
+	}
+	return util.FormatDateFromStringWithOptionalDash(untilDate), nil
+}
+
+// runRules books the due occurrences of the rules, the caller holds runMutex
+func runRules(ruleList []model.RecurringRuleEntity, until time.Time) ([]model.CashFlowEntity, error) {
+	// Booked occurrences get their payee from the rule's description, like a cash_flow created by hand,
+	// so every owner's payee rules are loaded once
+	payeeMatcherMap := make(map[primitive.ObjectID]payee_service.PayeeMatcher)
+	bookedList := []model.CashFlowEntity{}
+	for _, ruleEntity := range ruleList {
+		payeeMatcher, found := payeeMatcherMap[ruleEntity.OwnerId]
+		if !found {
+			payeeMatcher = payee_service.NewPayeeMatcher(ruleEntity.OwnerId.Hex())
+			payeeMatcherMap[ruleEntity.OwnerId] = payeeMatcher
+		}
+		ruleBookedList, err := bookDueOccurrences(ruleEntity, until, payeeMatcher)
+		bookedList = append(bookedList, ruleBookedList...)
+		if err != nil {
+			return bookedList, errors.New("recurring rule " + ruleEntity.Name + ": " + err.Error())
+		}
+	}
+
+	util.Logger.Infow("recurring rules run", "until", util.FormatDateToStringWithDash(until), "booked", len(bookedList))
+	return bookedList, nil
+}
+
+// bookDueOccurrences books the rule's due occurrences in date order into the ledger of its owner,
+// then saves how far the rule got even when an occurrence fails.
+func bookDueOccurrences(ruleEntity model.RecurringRuleEntity, until time.Time, payeeMatcher payee_service.PayeeMatcher) ([]model.CashFlowEntity, error) {
+	if err := validation.ValidateFrequency(ruleEntity.Frequency, ruleEntity.DayOfMonth); err != nil {
+		return nil, err
+	}
+
+	payeeId := payeeMatcher.Match(ruleEntity.Description)
+	var bookedList []model.CashFlowEntity
+	var bookErr error
+	progressedRule := ruleEntity
+	for _, occurrence := range dueOccurrences(ruleEntity, until) {
+		cashFlowPlainId := occurrenceCashFlowId(ruleEntity.Id, occurrence).Hex()
+		if cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(cashFlowPlainId).IsEmpty() {
+			newPlainId := cash_flow_mapper.INSTANCE.InsertCashFlowByEntity(model.CashFlowEntity{
+				Id:          util.Convert2ObjectId(cashFlowPlainId),
+				OwnerId:     ruleEntity.OwnerId,
+				CategoryId:  ruleEntity.CategoryId,
+				AccountId:   ruleEntity.AccountId,
+				BelongsDate: occurrence,
+				FlowType:    ruleEntity.FlowType,
+				Amount:      ruleEntity.Amount,
+				Currency:    ruleEntity.Currency,
+				Description: ruleEntity.Description,
+				PayeeId:     payeeId,
+				Remark:      "recurring rule " + ruleEntity.Id.Hex(),
+			})
+			if newPlainId == "" {
+				bookErr = errors.New("cash_flow create failed on " + util.FormatDateToStringWithDash(occurrence))
+				break
+			}
+			bookedList = append(bookedList, cash_flow_mapper.INSTANCE.GetCashFlowByObjectId(newPlainId))
+		}
+
+		progressedRule.LastRunDate = occurrence
+		progressedRule.OccurrenceCount++
+	}
+
+	if progressedRule.OccurrenceCount != ruleEntity.OccurrenceCount {
+		updatedRule := recurring_rule_mapper.INSTANCE.UpdateRecurringRuleByEntity(ruleEntity.Id.Hex(), progressedRule)
+		if updatedRule.IsEmpty() && bookErr == nil {
+			bookErr = errors.New("failed to save the progress")
+		}
+	}
+	return bookedList, bookErr
+}
@@ -0,0 +1,96 @@
+package recurring_rule_service
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// dueOccurrences lists the dates not booked yet that fall on or before until,
+// stopping at the rule's end date or occurrence limit.
+func dueOccurrences(rule model.RecurringRuleEntity, until time.Time) []time.Time {
+	occurrence := firstOccurrence(rule)
+	if !rule.LastRunDate.IsZero() {
+		occurrence = nextOccurrence(rule, rule.LastRunDate)
+	}
+
+	var occurrenceList []time.Time
+	for count := rule.OccurrenceCount; !occurrence.After(until); count++ {
+		if !rule.EndDate.IsZero() && occurrence.After(rule.EndDate) {
+			break
+		}
+		if rule.OccurrenceLimit > 0 && count >= rule.OccurrenceLimit {
+			break
+		}
+		occurrenceList = append(occurrenceList, occurrence)
+		occurrence = nextOccurrence(rule, occurrence)
+	}
+	return occurrenceList
+}
+
+// firstOccurrence is the first date on or after the start date the rule falls on
+func firstOccurrence(rule model.RecurringRuleEntity) time.Time {
+	startDate := rule.StartDate
+
+	var occurrence time.Time
+	switch rule.Frequency {
+	case model.FrequencyMonthly:
+		occurrence = clampedDayOfMonth(startDate.Year(), startDate.Month(), rule.DayOfMonth, startDate.Location())
+	case model.FrequencyLastBusinessDay:
+		occurrence = lastBusinessDay(startDate.Year(), startDate.Month(), startDate.Location())
+	default:
+		return startDate
+	}
+
+	if occurrence.Before(startDate) {
+		return nextOccurrence(rule, occurrence)
+	}
+	return occurrence
+}
+
+// nextOccurrence is the date the rule falls on after the given occurrence
+func nextOccurrence(rule model.RecurringRuleEntity, occurrence time.Time) time.Time {
+	switch rule.Frequency {
+	case model.FrequencyWeekly:
+		return occurrence.AddDate(0, 0, 7)
+	case model.FrequencyMonthly:
+		return clampedDayOfMonth(occurrence.Year(), occurrence.Month()+1, rule.DayOfMonth, occurrence.Location())
+	case model.FrequencyLastBusinessDay:
+		return lastBusinessDay(occurrence.Year(), occurrence.Month()+1, occurrence.Location())
+	default:
+		return occurrence.AddDate(0, 0, 1)
+	}
+}
+
+// clampedDayOfMonth returns the day in the month, or the month's last day when the month is shorter.
+// A month past December rolls over into the next year.
+func clampedDayOfMonth(year int, month time.Month, day int, location *time.Location) time.Time {
+	firstDay := time.Date(year, month, 1, 0, 0, 0, 0, location)
+	if lastDay := firstDay.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstDay.Year(), firstDay.Month(), day, 0, 0, 0, 0, location)
+}
+
+// lastBusinessDay returns the last Monday to Friday of the month, holidays are not considered
+func lastBusinessDay(year int, month time.Month, location *time.Location) time.Time {
+	date := clampedDayOfMonth(year, month, 31, location)
+	for date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		date = date.AddDate(0, 0, -1)
+	}
+	return date
+}
+
+// occurrenceCashFlowId derives the id of the cash_flow booked for an occurrence from the rule and the date,
+// so an occurrence booked twice maps to the same id instead of a second record.
+func occurrenceCashFlowId(ruleId primitive.ObjectID, occurrence time.Time) primitive.ObjectID {
+	var objectId primitive.ObjectID
+	binary.BigEndian.PutUint32(objectId[0:4], uint32(occurrence.Unix()))
+	digest := sha256.Sum256([]byte(ruleId.Hex() + util.FormatDateToStringWithoutDash(occurrence)))
+	copy(objectId[4:], digest[:8])
+	return objectId
+}
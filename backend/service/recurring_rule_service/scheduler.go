@@ -0,0 +1,23 @@
+package recurring_rule_service
+
+import (
+	"time"
+
+	"github.com/macar-x/cashlens/util"
+)
+
+// StartScheduler runs the recurring rules right away and then on every tick of interval,
+// in the background for as long as the process lives.
+func StartScheduler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := RunAllService(""); err != nil {
+				util.Logger.Errorw("scheduled recurring rules run failed", "error", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
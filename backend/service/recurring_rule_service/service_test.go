@@ -0,0 +1,254 @@
+package recurring_rule_service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// resetMappers gives each test empty in-memory storage with a Rent and a Salary category
+func resetMappers(t *testing.T) {
+	t.Helper()
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	account_mapper.INSTANCE = account_mapper.NewAccountMemoryMapper()
+	recurring_rule_mapper.INSTANCE = recurring_rule_mapper.NewRecurringRuleMemoryMapper()
+	payee_mapper.INSTANCE = payee_mapper.NewPayeeMemoryMapper()
+	for _, categoryName := range []string{"Rent", "Salary"} {
+		if category_mapper.INSTANCE.InsertCategoryByEntity(model.CategoryEntity{Name: categoryName}) == "" {
+			t.Fatalf("insert category %q failed", categoryName)
+		}
+	}
+}
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func TestDueOccurrences(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  model.RecurringRuleEntity
+		until string
+		want  []string
+	}{
+		{
+			name:  "daily up to until",
+			rule:  model.RecurringRuleEntity{Frequency: model.FrequencyDaily, StartDate: util.FormatDateFromStringWithDash("2024-01-30")},
+			until: "2024-02-02",
+			want:  []string{"2024-01-30", "2024-01-31", "2024-02-01", "2024-02-02"},
+		},
+		{
+			name:  "weekly on the start weekday",
+			rule:  model.RecurringRuleEntity{Frequency: model.FrequencyWeekly, StartDate: util.FormatDateFromStringWithDash("2024-01-03")},
+			until: "2024-01-24",
+			want:  []string{"2024-01-03", "2024-01-10", "2024-01-17", "2024-01-24"},
+		},
+		{
+			name: "monthly on day 31 clamps to short months",
+			rule: model.RecurringRuleEntity{Frequency: model.FrequencyMonthly, DayOfMonth: 31,
+				StartDate: util.FormatDateFromStringWithDash("2024-01-15")},
+			until: "2024-05-01",
+			want:  []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30"},
+		},
+		{
+			name: "monthly day already passed in the start month",
+			rule: model.RecurringRuleEntity{Frequency: model.FrequencyMonthly, DayOfMonth: 1,
+				StartDate: util.FormatDateFromStringWithDash("2024-11-15")},
+			until: "2025-01-31",
+			want:  []string{"2024-12-01", "2025-01-01"},
+		},
+		{
+			name: "last business day skips weekends",
+			rule: model.RecurringRuleEntity{Frequency: model.FrequencyLastBusinessDay,
+				StartDate: util.FormatDateFromStringWithDash("2024-03-01")},
+			until: "2024-07-01",
+			want:  []string{"2024-03-29", "2024-04-30", "2024-05-31", "2024-06-28"},
+		},
+		{
+			name: "end date stops the rule",
+			rule: model.RecurringRuleEntity{Frequency: model.FrequencyDaily,
+				StartDate: util.FormatDateFromStringWithDash("2024-01-01"), EndDate: util.FormatDateFromStringWithDash("2024-01-02")},
+			until: "2024-01-31",
+			want:  []string{"2024-01-01", "2024-01-02"},
+		},
+		{
+			name: "occurrence limit counts what was booked before",
+			rule: model.RecurringRuleEntity{Frequency: model.FrequencyDaily, OccurrenceLimit: 3, OccurrenceCount: 1,
+				StartDate: util.FormatDateFromStringWithDash("2024-01-01"), LastRunDate: util.FormatDateFromStringWithDash("2024-01-01")},
+			until: "2024-01-31",
+			want:  []string{"2024-01-02", "2024-01-03"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, occurrence := range dueOccurrences(tt.rule, util.FormatDateFromStringWithDash(tt.until)) {
+				got = append(got, util.FormatDateToStringWithDash(occurrence))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("dueOccurrences() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("dueOccurrences() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestRecurringRuleLifecycle(t *testing.T) {
+	resetMappers(t)
+
+	ruleDTO := model.RecurringRuleDTO{
+		Name:         "Rent",
+		FlowType:     "outcome",
+		CategoryName: "Rent",
+		Amount:       decimal.RequireFromString("1200.505"),
+		Frequency:    "monthly",
+		DayOfMonth:   1,
+		StartDate:    "2024-01-01",
+	}
+	rule, err := CreateService("", ruleDTO)
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+	if rule.FlowType != model.FlowTypeOutcome || rule.Frequency != model.FrequencyMonthly ||
+		!rule.Amount.Equal(decimal.RequireFromString("1200.51")) || rule.Currency != "USD" {
+		t.Errorf("CreateService() = %+v", rule)
+	}
+	if _, err := CreateService("", ruleDTO); err == nil {
+		t.Errorf("CreateService() with duplicated name expected error, got nil")
+	}
+
+	invalidDTOList := []model.RecurringRuleDTO{
+		{Name: "Move", FlowType: model.FlowTypeTransfer, CategoryName: "Rent", Amount: decimal.NewFromInt(1), Frequency: model.FrequencyDaily},
+		{Name: "Gym", FlowType: model.FlowTypeOutcome, CategoryName: "Gym", Amount: decimal.NewFromInt(1), Frequency: model.FrequencyDaily},
+		{Name: "Tax", FlowType: model.FlowTypeOutcome, CategoryName: "Rent", Amount: decimal.NewFromInt(1), Frequency: model.FrequencyMonthly},
+		{Name: "Fee", FlowType: model.FlowTypeOutcome, CategoryName: "Rent", Amount: decimal.NewFromInt(1), Frequency: model.FrequencyDaily,
+			StartDate: "2024-02-01", EndDate: "2024-01-01"},
+		{Name: "Tip", FlowType: model.FlowTypeOutcome, CategoryName: "Rent", Amount: decimal.NewFromInt(1), Frequency: model.FrequencyDaily,
+			OccurrenceLimit: intPointer(-1)},
+	}
+	for _, invalidDTO := range invalidDTOList {
+		if _, err := CreateService("", invalidDTO); err == nil {
+			t.Errorf("CreateService(%+v) expected error, got nil", invalidDTO)
+		}
+	}
+
+	updated, err := UpdateService("", rule.Id.Hex(), model.RecurringRuleDTO{Name: "Flat Rent", OccurrenceLimit: intPointer(3)})
+	if err != nil || updated.Name != "Flat Rent" || updated.OccurrenceLimit != 3 || updated.DayOfMonth != 1 {
+		t.Errorf("UpdateService() = %+v, %v", updated, err)
+	}
+	if queried, err := QueryService("", "", "Flat Rent"); err != nil || queried.Id != rule.Id {
+		t.Errorf("QueryService() = %+v, %v", queried, err)
+	}
+
+	// The limit of 3 stops the rule after March
+	bookedList, err := RunService("", "2024-06-30")
+	if err != nil || len(bookedList) != 3 {
+		t.Fatalf("RunService() booked %d, %v, want 3", len(bookedList), err)
+	}
+	if _, err := UpdateService("", rule.Id.Hex(), model.RecurringRuleDTO{DayOfMonth: 15}); err == nil {
+		t.Errorf("UpdateService() changing the schedule of a booked rule expected error, got nil")
+	}
+
+	if _, err := DeleteService("", rule.Id.Hex()); err != nil {
+		t.Errorf("DeleteService() error = %v", err)
+	}
+	if count := cash_flow_mapper.INSTANCE.CountAllCashFlows(); count != 3 {
+		t.Errorf("DeleteService() left %d cash_flows, want the 3 booked ones kept", count)
+	}
+}
+
+func TestRunServiceKeepsToTheOwner(t *testing.T) {
+	resetMappers(t)
+	ownerId := primitive.NewObjectID()
+	category_mapper.INSTANCE.InsertCategoryByEntity(model.CategoryEntity{OwnerId: ownerId, Name: "Rent"})
+	ruleDTO := model.RecurringRuleDTO{Name: "Rent", FlowType: "outcome", CategoryName: "Rent",
+		Amount: decimal.NewFromInt(1200), Frequency: "monthly", DayOfMonth: 1, StartDate: "2024-01-01"}
+	for _, ownerPlainId := range []string{"", ownerId.Hex()} {
+		if _, err := CreateService(ownerPlainId, ruleDTO); err != nil {
+			t.Fatalf("CreateService(%q) error = %v", ownerPlainId, err)
+		}
+	}
+
+	bookedList, err := RunService(ownerId.Hex(), "2024-02-29")
+	if err != nil || len(bookedList) != 2 {
+		t.Fatalf("RunService() booked %d, %v, want 2", len(bookedList), err)
+	}
+	for _, bookedEntity := range bookedList {
+		if bookedEntity.OwnerId != ownerId {
+			t.Errorf("RunService() booked %+v into another ledger", bookedEntity)
+		}
+	}
+	if bookedList, err := RunAllService("2024-02-29"); err != nil || len(bookedList) != 2 {
+		t.Errorf("RunAllService() booked %d, %v, want the 2 of the other rule", len(bookedList), err)
+	}
+}
+
+func TestRunServiceIsIdempotent(t *testing.T) {
+	resetMappers(t)
+	employerId := payee_mapper.INSTANCE.InsertPayeeByEntity(model.PayeeEntity{Name: "Acme",
+		Rules: []model.PayeeRule{{MatchType: model.PayeeRuleContains, Pattern: "acme"}}})
+
+	rule, err := CreateService("", model.RecurringRuleDTO{
+		Name:         "Salary",
+		FlowType:     model.FlowTypeIncome,
+		CategoryName: "Salary",
+		Description:  "ACME payroll",
+		Amount:       decimal.NewFromInt(5000),
+		Frequency:    model.FrequencyLastBusinessDay,
+		StartDate:    "2024-01-01",
+	})
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+
+	bookedList, err := RunService("", "2024-03-31")
+	if err != nil || len(bookedList) != 3 {
+		t.Fatalf("RunService() booked %d, %v, want 3", len(bookedList), err)
+	}
+	if bookedList[0].FlowType != model.FlowTypeIncome || util.FormatDateToStringWithDash(bookedList[0].BelongsDate) != "2024-01-31" {
+		t.Errorf("RunService() first booked = %+v", bookedList[0])
+	}
+	if bookedList[0].PayeeId.Hex() != employerId {
+		t.Errorf("RunService() booked payee = %s, want %s", bookedList[0].PayeeId.Hex(), employerId)
+	}
+
+	// Running again books nothing new
+	if bookedList, err := RunService("", "2024-03-31"); err != nil || len(bookedList) != 0 {
+		t.Errorf("RunService() again booked %d, %v, want 0", len(bookedList), err)
+	}
+
+	// A rule whose progress was lost skips the occurrences already booked
+	lostRule := recurring_rule_mapper.INSTANCE.GetRecurringRuleByObjectId(rule.Id.Hex())
+	lostRule.OccurrenceCount = 0
+	lostRule.LastRunDate = time.Time{}
+	recurring_rule_mapper.INSTANCE.UpdateRecurringRuleByEntity(rule.Id.Hex(), lostRule)
+	bookedList, err = RunService("", "2024-04-30")
+	if err != nil || len(bookedList) != 1 || util.FormatDateToStringWithDash(bookedList[0].BelongsDate) != "2024-04-30" {
+		t.Errorf("RunService() after lost progress = %+v, %v, want only 2024-04-30 booked", bookedList, err)
+	}
+	if count := cash_flow_mapper.INSTANCE.CountAllCashFlows(); count != 4 {
+		t.Errorf("cash_flow count = %d, want 4", count)
+	}
+
+	progressed := recurring_rule_mapper.INSTANCE.GetRecurringRuleByObjectId(rule.Id.Hex())
+	if progressed.OccurrenceCount != 4 || util.FormatDateToStringWithDash(progressed.LastRunDate) != "2024-04-30" {
+		t.Errorf("rule progress = %d until %s, want 4 until 2024-04-30",
+			progressed.OccurrenceCount, util.FormatDateToStringWithDash(progressed.LastRunDate))
+	}
+}
@@ -0,0 +1,110 @@
+package recurring_rule_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/service/cash_flow_service"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// UpdateService updates one of the owner's recurring rules by ID, blank fields are kept and a new category is the owner's too.
+// Cash_flows already booked are left as they are, and the schedule
+// (frequency, day of month and start date) is fixed once an occurrence is booked.
+func UpdateService(ownerPlainId, plainId string, ruleDTO model.RecurringRuleDTO) (model.RecurringRuleEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.RecurringRuleEntity{}, err
+	}
+
+	existingRule := recurring_rule_mapper.INSTANCE.GetRecurringRuleByObjectId(plainId)
+	if existingRule.IsEmpty() || !existingRule.IsOwnedBy(ownerPlainId) {
+		return model.RecurringRuleEntity{}, errors.New("recurring rule not found")
+	}
+
+	if ruleDTO.Name != "" && ruleDTO.Name != existingRule.Name {
+		if err := validation.ValidateRecurringRuleName(ruleDTO.Name); err != nil {
+			return model.RecurringRuleEntity{}, err
+		}
+		if !recurring_rule_mapper.INSTANCE.GetRecurringRuleByName(ownerPlainId, ruleDTO.Name).IsEmpty() {
+			return model.RecurringRuleEntity{}, errors.New("recurring rule already exists")
+		}
+		existingRule.Name = ruleDTO.Name
+	}
+
+	if ruleDTO.FlowType != "" {
+		flowType, err := normalizeFlowType(ruleDTO.FlowType)
+		if err != nil {
+			return model.RecurringRuleEntity{}, err
+		}
+		existingRule.FlowType = flowType
+	}
+
+	if ruleDTO.CategoryName != "" {
+		if err := validation.ValidateCategoryName(ruleDTO.CategoryName); err != nil {
+			return model.RecurringRuleEntity{}, err
+		}
+		categoryEntity := category_mapper.INSTANCE.GetCategoryByName(ownerPlainId, ruleDTO.CategoryName)
+		if categoryEntity.IsEmpty() {
+			return model.RecurringRuleEntity{}, errors.New("category does not exist")
+		}
+		existingRule.CategoryId = categoryEntity.Id
+	}
+
+	if ruleDTO.AccountName != "" || ruleDTO.Currency != "" {
+		if ruleDTO.AccountName != "" {
+			accountId, err := cash_flow_service.GetAccountIdByName(ownerPlainId, ruleDTO.AccountName)
+			if err != nil {
+				return model.RecurringRuleEntity{}, err
+			}
+			existingRule.AccountId = accountId
+		}
+		currency, err := cash_flow_service.ResolveCurrency(existingRule.AccountId, ruleDTO.Currency)
+		if err != nil {
+			return model.RecurringRuleEntity{}, err
+		}
+		existingRule.Currency = currency
+	}
+
+	if !ruleDTO.Amount.IsZero() {
+		if err := validation.ValidateAmount(ruleDTO.Amount); err != nil {
+			return model.RecurringRuleEntity{}, err
+		}
+		existingRule.Amount = ruleDTO.Amount.Round(2)
+	}
+
+	if ruleDTO.Description != "" {
+		if err := validation.ValidateDescription(ruleDTO.Description); err != nil {
+			return model.RecurringRuleEntity{}, err
+		}
+		existingRule.Description = ruleDTO.Description
+	}
+
+	if ruleDTO.Frequency != "" || ruleDTO.DayOfMonth != 0 || ruleDTO.StartDate != "" {
+		if existingRule.OccurrenceCount != 0 {
+			return model.RecurringRuleEntity{}, errors.New("can not change the schedule of a recurring rule which has booked cash_flows")
+		}
+		frequency := ruleDTO.Frequency
+		if frequency == "" {
+			frequency = existingRule.Frequency
+		}
+		dayOfMonth := ruleDTO.DayOfMonth
+		if dayOfMonth == 0 {
+			dayOfMonth = existingRule.DayOfMonth
+		}
+		if err := applySchedule(&existingRule, frequency, dayOfMonth, ruleDTO.StartDate); err != nil {
+			return model.RecurringRuleEntity{}, err
+		}
+	}
+
+	if err := applyEnding(&existingRule, ruleDTO.EndDate, ruleDTO.OccurrenceLimit); err != nil {
+		return model.RecurringRuleEntity{}, err
+	}
+
+	updatedEntity := recurring_rule_mapper.INSTANCE.UpdateRecurringRuleByEntity(plainId, existingRule)
+	if updatedEntity.IsEmpty() {
+		return model.RecurringRuleEntity{}, errors.New("failed to update recurring rule")
+	}
+	return updatedEntity, nil
+}
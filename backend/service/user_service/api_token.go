@@ -0,0 +1,82 @@
+package user_service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/macar-x/cashlens/mapper/api_token_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// apiTokenPrefix tells API tokens apart from sign-in tokens
+const apiTokenPrefix = "cl_"
+
+// CreateApiTokenService makes a personal API token for the user. The token is returned only here,
+// what is stored is its hash.
+func CreateApiTokenService(userPlainId string, apiTokenDTO model.ApiTokenDTO) (model.ApiTokenEntity, string, error) {
+	apiTokenDTO.Name = strings.TrimSpace(apiTokenDTO.Name)
+	if err := validation.ValidateRequired("name", apiTokenDTO.Name); err != nil {
+		return model.ApiTokenEntity{}, "", err
+	}
+	if len(apiTokenDTO.Name) > 100 {
+		return model.ApiTokenEntity{}, "", validation.NewValidationError("name", "too long (max 100 characters)")
+	}
+	userEntity, err := QueryUserById(userPlainId)
+	if err != nil {
+		return model.ApiTokenEntity{}, "", err
+	}
+
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return model.ApiTokenEntity{}, "", err
+	}
+	token := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(randomBytes)
+
+	newPlainId := api_token_mapper.INSTANCE.InsertApiTokenByEntity(model.ApiTokenEntity{
+		UserId:    userEntity.Id,
+		Name:      apiTokenDTO.Name,
+		TokenHash: hashApiToken(token),
+	})
+	if newPlainId == "" {
+		return model.ApiTokenEntity{}, "", errors.New("api token create failed")
+	}
+	return api_token_mapper.INSTANCE.GetApiTokenByObjectId(newPlainId), token, nil
+}
+
+// ListApiTokensService returns the user's API tokens oldest first, without the tokens themselves
+func ListApiTokensService(userPlainId string) []model.ApiTokenEntity {
+	apiTokenList := api_token_mapper.INSTANCE.GetApiTokensByUserId(userPlainId)
+	if apiTokenList == nil {
+		return []model.ApiTokenEntity{}
+	}
+	return apiTokenList
+}
+
+// RevokeApiTokenService deletes one of the user's API tokens, scripts using it are turned away from then on
+func RevokeApiTokenService(userPlainId, apiTokenPlainId string) (model.ApiTokenEntity, error) {
+	if err := validation.ValidateID(apiTokenPlainId); err != nil {
+		return model.ApiTokenEntity{}, err
+	}
+
+	// Someone else's token is reported as missing, not as forbidden
+	apiTokenEntity := api_token_mapper.INSTANCE.GetApiTokenByObjectId(apiTokenPlainId)
+	if apiTokenEntity.IsEmpty() || apiTokenEntity.UserId.Hex() != userPlainId {
+		return model.ApiTokenEntity{}, errors.New("api token does not exist")
+	}
+
+	deletedEntity := api_token_mapper.INSTANCE.DeleteApiTokenByObjectId(apiTokenPlainId)
+	if deletedEntity.IsEmpty() {
+		return model.ApiTokenEntity{}, errors.New("api token delete failed")
+	}
+	return deletedEntity, nil
+}
+
+func hashApiToken(token string) string {
+	tokenHash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(tokenHash[:])
+}
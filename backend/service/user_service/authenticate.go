@@ -0,0 +1,39 @@
+package user_service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/macar-x/cashlens/errors"
+	"github.com/macar-x/cashlens/mapper/api_token_mapper"
+	"github.com/macar-x/cashlens/mapper/user_mapper"
+	"github.com/macar-x/cashlens/model"
+)
+
+// AuthenticateService finds the user behind a sign-in token or a personal API token
+func AuthenticateService(token string) (model.UserEntity, error) {
+	if token == "" {
+		return model.UserEntity{}, errors.NewUnauthorizedError("missing token")
+	}
+
+	var userPlainId string
+	if strings.HasPrefix(token, apiTokenPrefix) {
+		apiTokenEntity := api_token_mapper.INSTANCE.GetApiTokenByHash(hashApiToken(token))
+		if apiTokenEntity.IsEmpty() {
+			return model.UserEntity{}, errors.NewUnauthorizedError("unknown or revoked api token")
+		}
+		userPlainId = apiTokenEntity.UserId.Hex()
+	} else {
+		var err error
+		if userPlainId, err = parseAccessToken(token, time.Now()); err != nil {
+			return model.UserEntity{}, errors.NewUnauthorizedError(err.Error())
+		}
+	}
+
+	// The user may have been removed after the token was issued
+	userEntity := user_mapper.INSTANCE.GetUserByObjectId(userPlainId)
+	if userEntity.IsEmpty() {
+		return model.UserEntity{}, errors.NewUnauthorizedError("user does not exist")
+	}
+	return userEntity, nil
+}
@@ -0,0 +1,95 @@
+package user_service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/util"
+)
+
+// Sign-in tokens are JWTs signed with HMAC-SHA256, the subject is the user's id
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+type jwtClaims struct {
+	Subject   string `json:"sub"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+var (
+	generatedSecretOnce sync.Once
+	generatedSecret     []byte
+)
+
+// jwtSecret reads the signing key from the configuration. Without one, a random key is made
+// that lasts until the server stops, so every sign-in ends with it.
+func jwtSecret() []byte {
+	if secret := util.GetConfigByKey("auth.jwt.secret"); secret != "" {
+		return []byte(secret)
+	}
+	generatedSecretOnce.Do(func() {
+		generatedSecret = make([]byte, 32)
+		if _, err := rand.Read(generatedSecret); err != nil {
+			panic(err)
+		}
+		util.Logger.Warnln("JWT_SECRET is not set, sign-in tokens will not survive a restart")
+	})
+	return generatedSecret
+}
+
+// jwtTtl is how long a sign-in token lasts, 24 hours unless configured
+func jwtTtl() time.Duration {
+	ttl, err := time.ParseDuration(util.GetConfigByKey("auth.jwt.ttl"))
+	if err != nil || ttl <= 0 {
+		return 24 * time.Hour
+	}
+	return ttl
+}
+
+func signAccessToken(userPlainId string, now time.Time) (string, time.Time, error) {
+	expireTime := now.Add(jwtTtl()).Truncate(time.Second)
+	claimsJson, err := json.Marshal(jwtClaims{Subject: userPlainId, IssuedAt: now.Unix(), ExpiresAt: expireTime.Unix()})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	signingInput := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(claimsJson)
+	return signingInput + "." + signJwt(signingInput), expireTime, nil
+}
+
+// parseAccessToken checks the signature and expiry of a sign-in token and returns its user's id
+func parseAccessToken(token string, now time.Time) (string, error) {
+	partList := strings.Split(token, ".")
+	if len(partList) != 3 || partList[0] != jwtHeader {
+		return "", errors.New("malformed token")
+	}
+	if !hmac.Equal([]byte(partList[2]), []byte(signJwt(partList[0]+"."+partList[1]))) {
+		return "", errors.New("bad token signature")
+	}
+
+	claimsJson, err := base64.RawURLEncoding.DecodeString(partList[1])
+	if err != nil {
+		return "", errors.New("malformed token")
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(claimsJson, &claims); err != nil || claims.Subject == "" {
+		return "", errors.New("malformed token")
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return "", errors.New("token expired")
+	}
+	return claims.Subject, nil
+}
+
+func signJwt(signingInput string) string {
+	mac := hmac.New(sha256.New, jwtSecret())
+	mac.Write([]byte(signingInput))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
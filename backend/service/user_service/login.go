@@ -0,0 +1,49 @@
+package user_service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/macar-x/cashlens/errors"
+	"github.com/macar-x/cashlens/mapper/user_mapper"
+	"github.com/macar-x/cashlens/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// placeholderHash is compared against when the username is unknown, so both failures take as long
+	placeholderHash     []byte
+	placeholderHashErr  error
+	placeholderHashOnce sync.Once
+)
+
+// LoginService checks the password and hands out a signed sign-in token
+func LoginService(userDTO model.UserDTO) (model.AccessTokenDTO, error) {
+	userEntity := user_mapper.INSTANCE.GetUserByUsername(userDTO.Username)
+	var passwordHash []byte
+	if userEntity.IsEmpty() {
+		var err error
+		if passwordHash, err = getPlaceholderHash(); err != nil {
+			return model.AccessTokenDTO{}, err
+		}
+	} else {
+		passwordHash = []byte(userEntity.PasswordHash)
+	}
+	if err := bcrypt.CompareHashAndPassword(passwordHash, []byte(userDTO.Password)); err != nil || userEntity.IsEmpty() {
+		return model.AccessTokenDTO{}, errors.NewUnauthorizedError("invalid username or password")
+	}
+
+	token, expireTime, err := signAccessToken(userEntity.Id.Hex(), time.Now())
+	if err != nil {
+		return model.AccessTokenDTO{}, err
+	}
+	return model.AccessTokenDTO{Token: token, TokenType: "Bearer", ExpireTime: &expireTime}, nil
+}
+
+// getPlaceholderHash hashes the placeholder on the first unknown username, not on every start
+func getPlaceholderHash() ([]byte, error) {
+	placeholderHashOnce.Do(func() {
+		placeholderHash, placeholderHashErr = bcrypt.GenerateFromPassword([]byte("cashlens-placeholder"), bcrypt.DefaultCost)
+	})
+	return placeholderHash, placeholderHashErr
+}
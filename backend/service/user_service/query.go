@@ -0,0 +1,35 @@
+package user_service
+
+import (
+	"errors"
+
+	"github.com/macar-x/cashlens/mapper/user_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/validation"
+)
+
+// QueryUserById returns a user by id
+func QueryUserById(plainId string) (model.UserEntity, error) {
+	if err := validation.ValidateID(plainId); err != nil {
+		return model.UserEntity{}, err
+	}
+
+	userEntity := user_mapper.INSTANCE.GetUserByObjectId(plainId)
+	if userEntity.IsEmpty() {
+		return model.UserEntity{}, errors.New("user does not exist")
+	}
+	return userEntity, nil
+}
+
+// QueryUserByUsername returns a user by username
+func QueryUserByUsername(username string) (model.UserEntity, error) {
+	if username == "" {
+		return model.UserEntity{}, errors.New("username cannot be empty")
+	}
+
+	userEntity := user_mapper.INSTANCE.GetUserByUsername(username)
+	if userEntity.IsEmpty() {
+		return model.UserEntity{}, errors.New("user does not exist")
+	}
+	return userEntity, nil
+}
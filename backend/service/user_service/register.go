@@ -0,0 +1,128 @@
+package user_service
+
+import (
+	"sync"
+
+	"github.com/macar-x/cashlens/errors"
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/budget_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/user_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+	"github.com/macar-x/cashlens/validation"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// registerMutex keeps registrations apart, so only one of them can find registration open
+// and take over the records from before there were users
+var registerMutex sync.Mutex
+
+// RegisterService creates a user, only the bcrypt hash of the password is stored.
+// The first user takes over the cash flows, categories and budgets recorded before there were users.
+func RegisterService(userDTO model.UserDTO) (model.UserEntity, error) {
+	return register(userDTO, false)
+}
+
+// RegisterFirstService creates a user like RegisterService, but only while registration is open,
+// for sign-ups by no one signed in
+func RegisterFirstService(userDTO model.UserDTO) (model.UserEntity, error) {
+	return register(userDTO, true)
+}
+
+func register(userDTO model.UserDTO, onlyWhileOpen bool) (model.UserEntity, error) {
+	if err := validation.ValidateUsername(userDTO.Username); err != nil {
+		return model.UserEntity{}, err
+	}
+	if err := validation.ValidatePassword(userDTO.Password); err != nil {
+		return model.UserEntity{}, err
+	}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(userDTO.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return model.UserEntity{}, err
+	}
+
+	registerMutex.Lock()
+	defer registerMutex.Unlock()
+
+	isFirstUser := IsRegistrationOpen()
+	if onlyWhileOpen && !isFirstUser {
+		return model.UserEntity{}, errors.NewUnauthorizedError("sign in to add more users")
+	}
+	if !user_mapper.INSTANCE.GetUserByUsername(userDTO.Username).IsEmpty() {
+		return model.UserEntity{}, errors.NewAlreadyExistsError("username already exists")
+	}
+
+	newPlainId := user_mapper.INSTANCE.InsertUserByEntity(model.UserEntity{
+		Username:     userDTO.Username,
+		PasswordHash: string(passwordHash),
+	})
+	if newPlainId == "" {
+		return model.UserEntity{}, errors.NewInternalError("user create failed", nil)
+	}
+
+	if isFirstUser {
+		if err := assignUnownedRecords(newPlainId); err != nil {
+			return model.UserEntity{}, err
+		}
+	}
+	return user_mapper.INSTANCE.GetUserByObjectId(newPlainId), nil
+}
+
+// assignUnownedRecords hands the records no user owns over to the owner
+func assignUnownedRecords(ownerPlainId string) error {
+	cashFlowCount, err := cash_flow_mapper.INSTANCE.AssignUnownedCashFlows(ownerPlainId)
+	if err != nil {
+		return err
+	}
+	categoryCount, err := category_mapper.INSTANCE.AssignUnownedCategories(ownerPlainId)
+	if err != nil {
+		return err
+	}
+	budgetCount, err := budget_mapper.INSTANCE.AssignUnownedBudgets(ownerPlainId)
+	if err != nil {
+		return err
+	}
+	accountCount, err := account_mapper.INSTANCE.AssignUnownedAccounts(ownerPlainId)
+	if err != nil {
+		return err
+	}
+	goalCount, err := goal_mapper.INSTANCE.AssignUnownedGoals(ownerPlainId)
+	if err != nil {
+		return err
+	}
+	payeeCount, err := payee_mapper.INSTANCE.AssignUnownedPayees(ownerPlainId)
+	if err != nil {
+		return err
+	}
+	categoryRuleCount, err := category_rule_mapper.INSTANCE.AssignUnownedCategoryRules(ownerPlainId)
+	if err != nil {
+		return err
+	}
+	recurringRuleCount, err := recurring_rule_mapper.INSTANCE.AssignUnownedRecurringRules(ownerPlainId)
+	if err != nil {
+		return err
+	}
+	exchangeRateCount, err := exchange_rate_mapper.INSTANCE.AssignUnownedExchangeRates(ownerPlainId)
+	if err != nil {
+		return err
+	}
+
+	util.Logger.Infow("unowned records assigned", "owner_id", ownerPlainId,
+		"cash_flows", cashFlowCount, "categories", categoryCount, "budgets", budgetCount,
+		"accounts", accountCount, "goals", goalCount, "payees", payeeCount,
+		"category_rules", categoryRuleCount, "recurring_rules", recurringRuleCount, "exchange_rates", exchangeRateCount)
+	return nil
+}
+
+// IsRegistrationOpen tells whether anyone may register, which is only until the first user exists.
+// After that new users are added by a signed-in user or from the command line.
+func IsRegistrationOpen() bool {
+	return user_mapper.INSTANCE.CountAllUsers() == 0
+}
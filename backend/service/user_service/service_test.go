@@ -0,0 +1,231 @@
+package user_service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/macar-x/cashlens/errors"
+	"github.com/macar-x/cashlens/mapper/account_mapper"
+	"github.com/macar-x/cashlens/mapper/api_token_mapper"
+	"github.com/macar-x/cashlens/mapper/budget_mapper"
+	"github.com/macar-x/cashlens/mapper/cash_flow_mapper"
+	"github.com/macar-x/cashlens/mapper/category_mapper"
+	"github.com/macar-x/cashlens/mapper/category_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/exchange_rate_mapper"
+	"github.com/macar-x/cashlens/mapper/goal_mapper"
+	"github.com/macar-x/cashlens/mapper/payee_mapper"
+	"github.com/macar-x/cashlens/mapper/recurring_rule_mapper"
+	"github.com/macar-x/cashlens/mapper/user_mapper"
+	"github.com/macar-x/cashlens/model"
+	"github.com/macar-x/cashlens/util"
+)
+
+// resetMappers gives each test empty storage
+func resetMappers(t *testing.T) {
+	t.Helper()
+	user_mapper.INSTANCE = user_mapper.NewUserMemoryMapper()
+	api_token_mapper.INSTANCE = api_token_mapper.NewApiTokenMemoryMapper()
+	cash_flow_mapper.INSTANCE = cash_flow_mapper.NewCashFlowMemoryMapper()
+	category_mapper.INSTANCE = category_mapper.NewCategoryMemoryMapper()
+	budget_mapper.INSTANCE = budget_mapper.NewBudgetMemoryMapper()
+	account_mapper.INSTANCE = account_mapper.NewAccountMemoryMapper()
+	goal_mapper.INSTANCE = goal_mapper.NewGoalMemoryMapper()
+	payee_mapper.INSTANCE = payee_mapper.NewPayeeMemoryMapper()
+	category_rule_mapper.INSTANCE = category_rule_mapper.NewCategoryRuleMemoryMapper()
+	recurring_rule_mapper.INSTANCE = recurring_rule_mapper.NewRecurringRuleMemoryMapper()
+	exchange_rate_mapper.INSTANCE = exchange_rate_mapper.NewExchangeRateMemoryMapper()
+	util.SetConfigByKey("auth.jwt.secret", "test-secret")
+	util.SetConfigByKey("auth.jwt.ttl", "1h")
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	resetMappers(t)
+	if !IsRegistrationOpen() {
+		t.Errorf("IsRegistrationOpen() = false on an empty database, want true")
+	}
+
+	alice, err := RegisterService(model.UserDTO{Username: "alice", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("RegisterService() error = %v", err)
+	}
+	if alice.PasswordHash == "" || strings.Contains(alice.PasswordHash, "correct horse") {
+		t.Errorf("RegisterService() stored password hash %q, want a bcrypt hash", alice.PasswordHash)
+	}
+	if IsRegistrationOpen() {
+		t.Errorf("IsRegistrationOpen() = true after the first user, want false")
+	}
+	for _, userDTO := range []model.UserDTO{
+		{Username: "alice", Password: "battery staple"},
+		{Username: "Bob", Password: "battery staple"},
+		{Username: "bob", Password: "short"},
+	} {
+		if _, err := RegisterService(userDTO); err == nil {
+			t.Errorf("RegisterService(%q) expected error, got nil", userDTO.Username)
+		}
+	}
+
+	accessToken, err := LoginService(model.UserDTO{Username: "alice", Password: "correct horse"})
+	if err != nil || accessToken.TokenType != "Bearer" || accessToken.ExpireTime == nil {
+		t.Fatalf("LoginService() = %+v, %v", accessToken, err)
+	}
+	if user, err := AuthenticateService(accessToken.Token); err != nil || user.Id != alice.Id {
+		t.Errorf("AuthenticateService() = %+v, %v, want alice", user, err)
+	}
+
+	for _, userDTO := range []model.UserDTO{
+		{Username: "alice", Password: "wrong horse"},
+		{Username: "bob", Password: "correct horse"},
+	} {
+		if _, err := LoginService(userDTO); !errors.IsUnauthorized(err) {
+			t.Errorf("LoginService(%q) error = %v, want unauthorized", userDTO.Username, err)
+		}
+	}
+}
+
+func TestRegisterTakesOverUnownedRecords(t *testing.T) {
+	resetMappers(t)
+	category_mapper.INSTANCE.InsertCategoryByEntity(model.CategoryEntity{Name: "Food"})
+	account_mapper.INSTANCE.InsertAccountByEntity(model.AccountEntity{Name: "Wallet", Type: model.AccountTypeCash})
+	payee_mapper.INSTANCE.InsertPayeeByEntity(model.PayeeEntity{Name: "Bakery"})
+
+	alice, err := RegisterService(model.UserDTO{Username: "alice", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("RegisterService() error = %v", err)
+	}
+	if category := category_mapper.INSTANCE.GetCategoryByName(alice.Id.Hex(), "Food"); category.IsEmpty() {
+		t.Errorf("GetCategoryByName(alice, Food) is empty, want the category kept from before")
+	}
+	if account := account_mapper.INSTANCE.GetAccountByName(alice.Id.Hex(), "Wallet"); account.IsEmpty() {
+		t.Errorf("GetAccountByName(alice, Wallet) is empty, want the account kept from before")
+	}
+	if payee := payee_mapper.INSTANCE.GetPayeeByName(alice.Id.Hex(), "Bakery"); payee.IsEmpty() {
+		t.Errorf("GetPayeeByName(alice, Bakery) is empty, want the payee kept from before")
+	}
+
+	bob, err := RegisterService(model.UserDTO{Username: "bob", Password: "battery staple"})
+	if err != nil {
+		t.Fatalf("RegisterService() error = %v", err)
+	}
+	if category := category_mapper.INSTANCE.GetCategoryByName(bob.Id.Hex(), "Food"); !category.IsEmpty() {
+		t.Errorf("GetCategoryByName(bob, Food) = %+v, want alice's category hidden", category)
+	}
+	if account := account_mapper.INSTANCE.GetAccountByName(bob.Id.Hex(), "Wallet"); !account.IsEmpty() {
+		t.Errorf("GetAccountByName(bob, Wallet) = %+v, want alice's account hidden", account)
+	}
+}
+
+func TestRegisterFirstServiceHasOneWinner(t *testing.T) {
+	resetMappers(t)
+	category_mapper.INSTANCE.InsertCategoryByEntity(model.CategoryEntity{Name: "Food"})
+
+	var waitGroup sync.WaitGroup
+	resultList := make([]model.UserEntity, 5)
+	errList := make([]error, len(resultList))
+	for i := range resultList {
+		waitGroup.Add(1)
+		go func(i int) {
+			defer waitGroup.Done()
+			resultList[i], errList[i] = RegisterFirstService(model.UserDTO{Username: fmt.Sprintf("user%d", i), Password: "correct horse"})
+		}(i)
+	}
+	waitGroup.Wait()
+
+	var winnerList []model.UserEntity
+	for i, err := range errList {
+		if err == nil {
+			winnerList = append(winnerList, resultList[i])
+		} else if !errors.IsUnauthorized(err) {
+			t.Errorf("RegisterFirstService() error = %v, want unauthorized", err)
+		}
+	}
+	if len(winnerList) != 1 || user_mapper.INSTANCE.CountAllUsers() != 1 {
+		t.Fatalf("RegisterFirstService() created %d users, want 1", len(winnerList))
+	}
+	if category := category_mapper.INSTANCE.GetCategoryByName(winnerList[0].Id.Hex(), "Food"); category.IsEmpty() {
+		t.Errorf("GetCategoryByName(winner, Food) is empty, want the category kept from before")
+	}
+}
+
+func TestAccessToken(t *testing.T) {
+	resetMappers(t)
+	alice, err := RegisterService(model.UserDTO{Username: "alice", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("RegisterService() error = %v", err)
+	}
+
+	issueTime := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	token, expireTime, err := signAccessToken(alice.Id.Hex(), issueTime)
+	if err != nil || !expireTime.Equal(issueTime.Add(time.Hour)) {
+		t.Fatalf("signAccessToken() = %v, %v, want an hour later", expireTime, err)
+	}
+	if userPlainId, err := parseAccessToken(token, issueTime.Add(59*time.Minute)); err != nil || userPlainId != alice.Id.Hex() {
+		t.Errorf("parseAccessToken() before expiry = %q, %v", userPlainId, err)
+	}
+	if _, err := parseAccessToken(token, expireTime); err == nil {
+		t.Errorf("parseAccessToken() at expiry expected error, got nil")
+	}
+
+	partList := strings.Split(token, ".")
+	// The same signature over longer-lasting claims
+	forgedClaims := base64.RawURLEncoding.EncodeToString([]byte(
+		`{"sub":"` + alice.Id.Hex() + `","iat":1717228800,"exp":4102444800}`))
+	for _, badToken := range []string{
+		partList[0] + "." + forgedClaims + "." + partList[2],
+		partList[0] + "." + partList[1],
+		"not a token",
+	} {
+		if _, err := parseAccessToken(badToken, issueTime); err == nil {
+			t.Errorf("parseAccessToken(%q) expected error, got nil", badToken)
+		}
+	}
+
+	// Another key does not accept the token
+	util.SetConfigByKey("auth.jwt.secret", "other-secret")
+	if _, err := parseAccessToken(token, issueTime); err == nil {
+		t.Errorf("parseAccessToken() with another secret expected error, got nil")
+	}
+}
+
+func TestApiTokenLifecycle(t *testing.T) {
+	resetMappers(t)
+	alice, _ := RegisterService(model.UserDTO{Username: "alice", Password: "correct horse"})
+	bob, _ := RegisterService(model.UserDTO{Username: "bob", Password: "battery staple"})
+
+	apiToken, token, err := CreateApiTokenService(alice.Id.Hex(), model.ApiTokenDTO{Name: " nightly import "})
+	if err != nil || apiToken.Name != "nightly import" || !strings.HasPrefix(token, apiTokenPrefix) {
+		t.Fatalf("CreateApiTokenService() = %+v, %q, %v", apiToken, token, err)
+	}
+	if apiToken.TokenHash == token || apiToken.TokenHash != hashApiToken(token) {
+		t.Errorf("CreateApiTokenService() stored %q, want the hash of the token", apiToken.TokenHash)
+	}
+	if _, _, err := CreateApiTokenService(alice.Id.Hex(), model.ApiTokenDTO{Name: " "}); err == nil {
+		t.Errorf("CreateApiTokenService() without a name expected error, got nil")
+	}
+
+	if user, err := AuthenticateService(token); err != nil || user.Id != alice.Id {
+		t.Errorf("AuthenticateService() with the api token = %+v, %v, want alice", user, err)
+	}
+	if tokenList := ListApiTokensService(alice.Id.Hex()); len(tokenList) != 1 {
+		t.Errorf("ListApiTokensService() = %+v, want one token", tokenList)
+	}
+	if tokenList := ListApiTokensService(bob.Id.Hex()); len(tokenList) != 0 {
+		t.Errorf("ListApiTokensService() of bob = %+v, want none", tokenList)
+	}
+
+	if _, err := RevokeApiTokenService(bob.Id.Hex(), apiToken.Id.Hex()); err == nil {
+		t.Errorf("RevokeApiTokenService() of someone else's token expected error, got nil")
+	}
+	if _, err := RevokeApiTokenService(alice.Id.Hex(), apiToken.Id.Hex()); err != nil {
+		t.Errorf("RevokeApiTokenService() error = %v", err)
+	}
+	if _, err := AuthenticateService(token); !errors.IsUnauthorized(err) {
+		t.Errorf("AuthenticateService() with a revoked api token error = %v, want unauthorized", err)
+	}
+	if _, err := AuthenticateService(""); !errors.IsUnauthorized(err) {
+		t.Errorf("AuthenticateService() without a token error = %v, want unauthorized", err)
+	}
+}
@@ -24,7 +24,7 @@ func initDefaultValues() {
 	}
 	configurationMap["db.name"] = dbName
 
-	// Database type: mongodb / mysql
+	// Database type: mongodb / mysql / sqlite / memory
 	dbType := os.Getenv("DB_TYPE")
 	if dbType == "" {
 		dbType = "mongodb"
@@ -36,6 +36,35 @@ func initDefaultValues() {
 
 	// MySQL URI format: username:password@tcp(host:port)/database
 	configurationMap["db.mysql.url"] = os.Getenv("MYSQL_DB_URI")
+
+	// SQLite database file, created on first use
+	sqlitePath := os.Getenv("SQLITE_PATH")
+	if sqlitePath == "" {
+		sqlitePath = "./cashlens.db"
+	}
+	configurationMap["db.sqlite.path"] = sqlitePath
+
+	// Currency of records saved without one, and of records saved before currencies existed
+	defaultCurrency := os.Getenv("DEFAULT_CURRENCY")
+	if defaultCurrency == "" {
+		defaultCurrency = "USD"
+	}
+	configurationMap["currency.default"] = defaultCurrency
+
+	// Key signing the API sign-in tokens, a random one is made at start-up when empty
+	configurationMap["auth.jwt.secret"] = os.Getenv("JWT_SECRET")
+
+	// How long a sign-in token stays valid, as a Go duration such as 24h or 30m
+	jwtTtl := os.Getenv("JWT_TTL")
+	if jwtTtl == "" {
+		jwtTtl = "24h"
+	}
+	configurationMap["auth.jwt.ttl"] = jwtTtl
+
+	// User whose ledger the command line works on, overridden by --user; blank for the records no user owns
+	configurationMap["cli.user"] = os.Getenv("CLI_USER")
+	// Shared ledger of that user the command line works on, overridden by --ledger; blank for their personal ledger
+	configurationMap["cli.ledger"] = os.Getenv("CLI_LEDGER")
 }
 
 func GetConfigByKey(configKey string) string {
@@ -15,8 +15,23 @@ var (
 )
 
 var (
-	CashFlowTableName = "cash_flow"
-	CategoryTableName = "category"
+	CashFlowTableName         = "cash_flow"
+	CashFlowTagTableName      = "cash_flow_tag"
+	CashFlowSplitTableName    = "cash_flow_split"
+	CategoryTableName         = "category"
+	AccountTableName          = "account"
+	ExchangeRateTableName     = "exchange_rate"
+	RecurringRuleTableName    = "recurring_rule"
+	BudgetTableName           = "budget"
+	GoalTableName             = "goal"
+	PayeeTableName            = "payee"
+	PayeeRuleTableName        = "payee_rule"
+	CategoryRuleTableName     = "category_rule"
+	UserTableName             = "user"
+	ApiTokenTableName         = "api_token"
+	LedgerTableName           = "ledger"
+	LedgerMemberTableName     = "ledger_member"
+	LedgerInvitationTableName = "ledger_invitation"
 )
 
 func initMongoDbConnection() {
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var decimalType = reflect.TypeOf(decimal.Decimal{})
+
+var mongoRegistry = newMongoRegistry()
+
+// newMongoRegistry stores decimal.Decimal as Decimal128, so amounts keep every cent
+// and $sum adds them up exactly.
+func newMongoRegistry() *bsoncodec.Registry {
+	registry := bson.NewRegistry()
+	registry.RegisterTypeEncoder(decimalType, bsoncodec.ValueEncoderFunc(encodeDecimal))
+	registry.RegisterTypeDecoder(decimalType, bsoncodec.ValueDecoderFunc(decodeDecimal))
+	return registry
+}
+
+// DecodeBsonM decodes a loosely typed document into an entity with the same codecs
+// the client uses, so Decimal128 amounts land in decimal.Decimal fields.
+func DecodeBsonM(bsonM bson.M, target interface{}) error {
+	bsonBytes, err := bson.MarshalWithRegistry(mongoRegistry, bsonM)
+	if err != nil {
+		return err
+	}
+	return bson.UnmarshalWithRegistry(mongoRegistry, bsonBytes, target)
+}
+
+func encodeDecimal(_ bsoncodec.EncodeContext, writer bsonrw.ValueWriter, value reflect.Value) error {
+	if !value.IsValid() || value.Type() != decimalType {
+		return bsoncodec.ValueEncoderError{Name: "encodeDecimal", Types: []reflect.Type{decimalType}, Received: value}
+	}
+
+	decimal128, err := primitive.ParseDecimal128(value.Interface().(decimal.Decimal).String())
+	if err != nil {
+		return err
+	}
+	return writer.WriteDecimal128(decimal128)
+}
+
+// decodeDecimal also reads the doubles and integers saved before amounts were Decimal128
+func decodeDecimal(_ bsoncodec.DecodeContext, reader bsonrw.ValueReader, value reflect.Value) error {
+	if !value.CanSet() || value.Type() != decimalType {
+		return bsoncodec.ValueDecoderError{Name: "decodeDecimal", Types: []reflect.Type{decimalType}, Received: value}
+	}
+
+	var result decimal.Decimal
+	switch reader.Type() {
+	case bsontype.Decimal128:
+		decimal128, err := reader.ReadDecimal128()
+		if err != nil {
+			return err
+		}
+		if result, err = decimal.NewFromString(decimal128.String()); err != nil {
+			return err
+		}
+	case bsontype.Double:
+		double, err := reader.ReadDouble()
+		if err != nil {
+			return err
+		}
+		result = decimal.NewFromFloat(double)
+	case bsontype.Int32:
+		int32Value, err := reader.ReadInt32()
+		if err != nil {
+			return err
+		}
+		result = decimal.NewFromInt32(int32Value)
+	case bsontype.Int64:
+		int64Value, err := reader.ReadInt64()
+		if err != nil {
+			return err
+		}
+		result = decimal.NewFromInt(int64Value)
+	case bsontype.String:
+		stringValue, err := reader.ReadString()
+		if err != nil {
+			return err
+		}
+		if result, err = decimal.NewFromString(stringValue); err != nil {
+			return err
+		}
+	case bsontype.Null:
+		if err := reader.ReadNull(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot decode %v into a decimal", reader.Type())
+	}
+
+	value.Set(reflect.ValueOf(result))
+	return nil
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMongoDecimalCodec(t *testing.T) {
+	type amountHolder struct {
+		Amount decimal.Decimal `bson:"amount"`
+	}
+	registry := newMongoRegistry()
+
+	encoded, err := bson.MarshalWithRegistry(registry, amountHolder{Amount: decimal.RequireFromString("1234567.89")})
+	if err != nil {
+		t.Fatalf("MarshalWithRegistry() error = %v", err)
+	}
+	if amountType := bson.Raw(encoded).Lookup("amount").Type; amountType != bsontype.Decimal128 {
+		t.Errorf("amount stored as %v, want Decimal128", amountType)
+	}
+
+	// Documents saved before amounts were Decimal128 hold doubles or integers
+	tests := []struct {
+		name     string
+		document bson.M
+		want     string
+	}{
+		{"decimal128", nil, "1234567.89"},
+		{"double", bson.M{"amount": 12.35}, "12.35"},
+		{"int32", bson.M{"amount": int32(7)}, "7"},
+		{"int64", bson.M{"amount": int64(3000)}, "3000"},
+		{"missing", bson.M{}, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := encoded
+			if tt.document != nil {
+				if raw, err = bson.Marshal(tt.document); err != nil {
+					t.Fatalf("Marshal() error = %v", err)
+				}
+			}
+
+			var decoded amountHolder
+			if err := bson.UnmarshalWithRegistry(registry, raw, &decoded); err != nil {
+				t.Fatalf("UnmarshalWithRegistry() error = %v", err)
+			}
+			if !decoded.Amount.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("decoded amount = %s, want %s", decoded.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeBsonM(t *testing.T) {
+	type amountHolder struct {
+		Amount decimal.Decimal `bson:"amount"`
+	}
+
+	decimal128, _ := primitive.ParseDecimal128("0.10")
+	var decoded amountHolder
+	if err := DecodeBsonM(bson.M{"amount": decimal128}, &decoded); err != nil {
+		t.Fatalf("DecodeBsonM() error = %v", err)
+	}
+	if !decoded.Amount.Equal(decimal.RequireFromString("0.1")) {
+		t.Errorf("DecodeBsonM() amount = %s, want 0.1", decoded.Amount)
+	}
+}
@@ -37,7 +37,8 @@ func InitMongoDbConnection() error {
 		ApplyURI(defaultDatabaseUri).
 		SetMaxPoolSize(50).
 		SetMinPoolSize(10).
-		SetMaxConnIdleTime(5 * time.Minute)
+		SetMaxConnIdleTime(5 * time.Minute).
+		SetRegistry(mongoRegistry)
 
 	var err error
 	mongoClient, err = mongo.Connect(ctx, clientOptions)
@@ -189,6 +190,21 @@ func DeleteManyInMongoDB(filter bson.D) int64 {
 	return result.DeletedCount
 }
 
+// OwnerFilterInMongoDB matches the records of an owner, blank for the records no user owns.
+// Documents saved before there were owners have no owner_id and belong to no user.
+func OwnerFilterInMongoDB(ownerPlainId string) primitive.E {
+	ownerId := util.ConvertOwner2ObjectId(ownerPlainId)
+	if ownerId == primitive.NilObjectID {
+		return primitive.E{Key: "owner_id", Value: bson.M{"$in": bson.A{primitive.NilObjectID, nil}}}
+	}
+	return primitive.E{Key: "owner_id", Value: ownerId}
+}
+
+// GetFindOptions returns a fresh set of find options for paginated and sorted queries
+func GetFindOptions() *options.FindOptions {
+	return options.Find()
+}
+
 // GetMongoDbCollection returns the current MongoDB collection for advanced operations
 func GetMongoDbCollection() *mongo.Collection {
 	checkDbConnection()
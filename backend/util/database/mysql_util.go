@@ -2,8 +2,10 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/macar-x/cashlens/util"
@@ -11,7 +13,63 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 )
 
-var connection *sql.DB
+var (
+	connection      *sql.DB
+	isMySqlUpgraded bool
+)
+
+// mysqlAddedColumns are added to tables created by the v1 scripts before the column existed
+var mysqlAddedColumns = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{CashFlowTableName, "ACCOUNT_ID", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000'"},
+	{CashFlowTableName, "LINKED_ID", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000' COMMENT 'OTHER LEG OF A TRANSFER'"},
+	{CashFlowTableName, "CURRENCY", "CHAR(3) NOT NULL DEFAULT '' COMMENT 'BLANK FOR THE DEFAULT CURRENCY'"},
+	{CashFlowTableName, "PAYEE_ID", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000'"},
+	{AccountTableName, "CURRENCY", "CHAR(3) NOT NULL DEFAULT '' COMMENT 'BLANK FOR THE DEFAULT CURRENCY'"},
+	{CategoryTableName, "OWNER_ID", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000' COMMENT 'LEDGER, NAMES ARE UNIQUE PER LEDGER'"},
+	{CashFlowTableName, "OWNER_ID", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000' COMMENT 'LEDGER IT IS KEPT IN'"},
+	{BudgetTableName, "OWNER_ID", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000' COMMENT 'LEDGER OF THE CATEGORY'"},
+	{CashFlowTableName, "CREATED_BY", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000' COMMENT 'USER WHO RECORDED IT'"},
+	{CashFlowTableName, "MODIFIED_BY", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000' COMMENT 'USER WHO LAST CHANGED IT'"},
+	{AccountTableName, "OWNER_ID", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000' COMMENT 'LEDGER, NAMES ARE UNIQUE PER LEDGER'"},
+	{ExchangeRateTableName, "OWNER_ID", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000' COMMENT 'LEDGER IT IS KEPT IN'"},
+	{RecurringRuleTableName, "OWNER_ID", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000' COMMENT 'LEDGER, NAMES ARE UNIQUE PER LEDGER'"},
+	{GoalTableName, "OWNER_ID", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000' COMMENT 'LEDGER, NAMES ARE UNIQUE PER LEDGER'"},
+	{PayeeTableName, "OWNER_ID", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000' COMMENT 'LEDGER, NAMES ARE UNIQUE PER LEDGER'"},
+	{CategoryRuleTableName, "OWNER_ID", "VARCHAR(24) NOT NULL DEFAULT '000000000000000000000000' COMMENT 'LEDGER, NAMES ARE UNIQUE PER LEDGER'"},
+}
+
+// mysqlChangedColumns are given their current type where the v1 scripts created them with another one
+var mysqlChangedColumns = []struct {
+	table      string
+	column     string
+	columnType string
+	definition string
+}{
+	{CashFlowTableName, "AMOUNT", "decimal(15,2)", "DECIMAL(15, 2) NOT NULL"},
+}
+
+// mysqlReplacedIndexes swap the indexes of the v1 scripts for the ones queries rely on now,
+// a blank dropped index is only added
+var mysqlReplacedIndexes = []struct {
+	table        string
+	droppedIndex string
+	addedIndex   string
+	definition   string
+}{
+	{CategoryTableName, "category_name_unique_index", "category_owner_name_unique_index", "UNIQUE INDEX category_owner_name_unique_index (OWNER_ID, NAME)"},
+	{AccountTableName, "account_name_unique_index", "account_owner_name_unique_index", "UNIQUE INDEX account_owner_name_unique_index (OWNER_ID, NAME)"},
+	{GoalTableName, "goal_name_unique_index", "goal_owner_name_unique_index", "UNIQUE INDEX goal_owner_name_unique_index (OWNER_ID, NAME)"},
+	{PayeeTableName, "payee_name_unique_index", "payee_owner_name_unique_index", "UNIQUE INDEX payee_owner_name_unique_index (OWNER_ID, NAME)"},
+	{CategoryRuleTableName, "category_rule_name_unique_index", "category_rule_owner_name_unique_index", "UNIQUE INDEX category_rule_owner_name_unique_index (OWNER_ID, NAME)"},
+	{RecurringRuleTableName, "recurring_rule_name_unique_index", "recurring_rule_owner_name_unique_index", "UNIQUE INDEX recurring_rule_owner_name_unique_index (OWNER_ID, NAME)"},
+	{ExchangeRateTableName, "exchange_rate_pair_date_unique_index", "exchange_rate_owner_pair_date_unique_index",
+		"UNIQUE INDEX exchange_rate_owner_pair_date_unique_index (OWNER_ID, FROM_CURRENCY, TO_CURRENCY, EFFECTIVE_DATE)"},
+	{CashFlowTableName, "", "cash_flow_description_fulltext", "FULLTEXT INDEX cash_flow_description_fulltext (DESCRIPTION)"},
+}
 
 func GetMySqlConnection() *sql.DB {
 	// check and init database setting
@@ -38,10 +96,120 @@ func openMySqlConnection() {
 	connection.SetMaxOpenConns(10)
 	connection.SetMaxIdleConns(10)
 
+	// 舊表升級失敗時不可繼續使用, 否則讀寫會落在缺欄位的表上
+	if !isMySqlUpgraded {
+		if err := upgradeMySqlSchema(connection); err != nil {
+			connection.Close()
+			panic(fmt.Errorf("failed to upgrade MySQL schema: %w", err))
+		}
+		isMySqlUpgraded = true
+	}
+
 	isConnected = true
 	util.Logger.Debugln("database connection created")
 }
 
+// upgradeMySqlSchema brings tables created by older v1 scripts up to date, tables not created yet are left alone
+func upgradeMySqlSchema(connection *sql.DB) error {
+	for _, addedColumn := range mysqlAddedColumns {
+		columnType, tableExists, err := queryMySqlColumnType(connection, addedColumn.table, addedColumn.column)
+		if err != nil {
+			return err
+		}
+		if !tableExists || columnType != "" {
+			continue
+		}
+		if _, err := connection.Exec("ALTER TABLE " + addedColumn.table +
+			" ADD COLUMN " + addedColumn.column + " " + addedColumn.definition); err != nil {
+			return err
+		}
+		util.Logger.Infow("mysql column added", "table", addedColumn.table, "column", addedColumn.column)
+	}
+
+	for _, changedColumn := range mysqlChangedColumns {
+		columnType, _, err := queryMySqlColumnType(connection, changedColumn.table, changedColumn.column)
+		if err != nil {
+			return err
+		}
+		if columnType == "" || strings.EqualFold(columnType, changedColumn.columnType) {
+			continue
+		}
+		if _, err := connection.Exec("ALTER TABLE " + changedColumn.table +
+			" MODIFY COLUMN " + changedColumn.column + " " + changedColumn.definition); err != nil {
+			return err
+		}
+		util.Logger.Infow("mysql column changed", "table", changedColumn.table, "column", changedColumn.column)
+	}
+
+	for _, replacedIndex := range mysqlReplacedIndexes {
+		tableExists, err := isMySqlTableExists(connection, replacedIndex.table)
+		if err != nil {
+			return err
+		}
+		if !tableExists {
+			continue
+		}
+		if replacedIndex.droppedIndex != "" {
+			if exists, err := isMySqlIndexExists(connection, replacedIndex.table, replacedIndex.droppedIndex); err != nil {
+				return err
+			} else if exists {
+				if _, err := connection.Exec("DROP INDEX " + replacedIndex.droppedIndex + " ON " + replacedIndex.table); err != nil {
+					return err
+				}
+			}
+		}
+		exists, err := isMySqlIndexExists(connection, replacedIndex.table, replacedIndex.addedIndex)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			if _, err := connection.Exec("ALTER TABLE " + replacedIndex.table + " ADD " + replacedIndex.definition); err != nil {
+				return err
+			}
+			util.Logger.Infow("mysql index added", "table", replacedIndex.table, "index", replacedIndex.addedIndex)
+		}
+	}
+	return nil
+}
+
+// queryMySqlColumnType returns the type of the column, blank when it is missing, and whether the table exists
+func queryMySqlColumnType(connection *sql.DB, table, column string) (string, bool, error) {
+	rows, err := connection.Query("SELECT COLUMN_NAME, COLUMN_TYPE FROM information_schema.columns "+
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", table)
+	if err != nil {
+		return "", false, err
+	}
+	defer rows.Close()
+
+	tableExists := false
+	columnType := ""
+	for rows.Next() {
+		var existColumn, existColumnType string
+		if err := rows.Scan(&existColumn, &existColumnType); err != nil {
+			return "", false, err
+		}
+		tableExists = true
+		if strings.EqualFold(existColumn, column) {
+			columnType = existColumnType
+		}
+	}
+	return columnType, tableExists, rows.Err()
+}
+
+func isMySqlTableExists(connection *sql.DB, table string) (bool, error) {
+	var tableCount int
+	err := connection.QueryRow("SELECT COUNT(*) FROM information_schema.tables "+
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", table).Scan(&tableCount)
+	return tableCount > 0, err
+}
+
+func isMySqlIndexExists(connection *sql.DB, table, index string) (bool, error) {
+	var indexCount int
+	err := connection.QueryRow("SELECT COUNT(*) FROM information_schema.statistics "+
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ?", table, index).Scan(&indexCount)
+	return indexCount > 0, err
+}
+
 func CloseMySqlConnection() {
 	// do nothing if not connected
 	if !isConnected || reflect.DeepEqual(connection, sql.DB{}) {
@@ -0,0 +1,291 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/macar-x/cashlens/util"
+
+	_ "modernc.org/sqlite"
+)
+
+var (
+	sqliteOnce       = sync.Once{}
+	sqliteConnection *sql.DB
+)
+
+// sqliteSchema is applied on first connection, dates are stored as TEXT so they compare lexically
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS ` + CategoryTableName + ` (
+		ID          TEXT NOT NULL PRIMARY KEY,
+		OWNER_ID    TEXT NOT NULL DEFAULT '000000000000000000000000',
+		PARENT_ID   TEXT,
+		NAME        TEXT NOT NULL,
+		REMARK      TEXT,
+		CREATE_TIME TEXT NOT NULL,
+		MODIFY_TIME TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + CashFlowTableName + ` (
+		ID           TEXT NOT NULL PRIMARY KEY,
+		OWNER_ID     TEXT NOT NULL DEFAULT '000000000000000000000000',
+		CATEGORY_ID  TEXT NOT NULL,
+		ACCOUNT_ID   TEXT NOT NULL DEFAULT '000000000000000000000000',
+		LINKED_ID    TEXT NOT NULL DEFAULT '000000000000000000000000',
+		PAYEE_ID     TEXT NOT NULL DEFAULT '000000000000000000000000',
+		BELONGS_DATE TEXT NOT NULL,
+		FLOW_TYPE    TEXT NOT NULL,
+		AMOUNT       REAL NOT NULL,
+		CURRENCY     TEXT NOT NULL DEFAULT '',
+		DESCRIPTION  TEXT NOT NULL,
+		REMARK       TEXT,
+		CREATED_BY   TEXT NOT NULL DEFAULT '000000000000000000000000',
+		MODIFIED_BY  TEXT NOT NULL DEFAULT '000000000000000000000000',
+		CREATE_TIME  TEXT NOT NULL,
+		MODIFY_TIME  TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + CashFlowTagTableName + ` (
+		CASH_FLOW_ID TEXT NOT NULL,
+		TAG          TEXT NOT NULL,
+		PRIMARY KEY (CASH_FLOW_ID, TAG)
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + CashFlowSplitTableName + ` (
+		CASH_FLOW_ID TEXT NOT NULL,
+		LINE_NO      INTEGER NOT NULL,
+		CATEGORY_ID  TEXT NOT NULL,
+		AMOUNT       REAL NOT NULL,
+		DESCRIPTION  TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (CASH_FLOW_ID, LINE_NO)
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + AccountTableName + ` (
+		ID              TEXT NOT NULL PRIMARY KEY,
+		OWNER_ID        TEXT NOT NULL DEFAULT '000000000000000000000000',
+		NAME            TEXT NOT NULL,
+		TYPE            TEXT NOT NULL,
+		OPENING_BALANCE REAL NOT NULL DEFAULT 0,
+		CURRENCY        TEXT NOT NULL DEFAULT '',
+		REMARK          TEXT,
+		CREATE_TIME     TEXT NOT NULL,
+		MODIFY_TIME     TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + ExchangeRateTableName + ` (
+		ID             TEXT NOT NULL PRIMARY KEY,
+		OWNER_ID       TEXT NOT NULL DEFAULT '000000000000000000000000',
+		FROM_CURRENCY  TEXT NOT NULL,
+		TO_CURRENCY    TEXT NOT NULL,
+		RATE           DECIMAL(19, 6) NOT NULL,
+		EFFECTIVE_DATE TEXT NOT NULL,
+		CREATE_TIME    TEXT NOT NULL,
+		MODIFY_TIME    TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + RecurringRuleTableName + ` (
+		ID               TEXT NOT NULL PRIMARY KEY,
+		OWNER_ID         TEXT NOT NULL DEFAULT '000000000000000000000000',
+		NAME             TEXT NOT NULL,
+		FLOW_TYPE        TEXT NOT NULL,
+		CATEGORY_ID      TEXT NOT NULL,
+		ACCOUNT_ID       TEXT NOT NULL DEFAULT '000000000000000000000000',
+		AMOUNT           REAL NOT NULL,
+		CURRENCY         TEXT NOT NULL DEFAULT '',
+		DESCRIPTION      TEXT,
+		FREQUENCY        TEXT NOT NULL,
+		DAY_OF_MONTH     INTEGER NOT NULL DEFAULT 0,
+		START_DATE       TEXT NOT NULL,
+		END_DATE         TEXT,
+		OCCURRENCE_LIMIT INTEGER NOT NULL DEFAULT 0,
+		OCCURRENCE_COUNT INTEGER NOT NULL DEFAULT 0,
+		LAST_RUN_DATE    TEXT,
+		CREATE_TIME      TEXT NOT NULL,
+		MODIFY_TIME      TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + BudgetTableName + ` (
+		ID           TEXT NOT NULL PRIMARY KEY,
+		OWNER_ID     TEXT NOT NULL DEFAULT '000000000000000000000000',
+		CATEGORY_ID  TEXT NOT NULL,
+		PERIOD       TEXT NOT NULL,
+		LIMIT_AMOUNT REAL NOT NULL,
+		CURRENCY     TEXT NOT NULL DEFAULT '',
+		ROLLOVER     INTEGER NOT NULL DEFAULT 0,
+		START_DATE   TEXT NOT NULL,
+		CREATE_TIME  TEXT NOT NULL,
+		MODIFY_TIME  TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + GoalTableName + ` (
+		ID            TEXT NOT NULL PRIMARY KEY,
+		OWNER_ID      TEXT NOT NULL DEFAULT '000000000000000000000000',
+		NAME          TEXT NOT NULL,
+		ACCOUNT_ID    TEXT NOT NULL,
+		TARGET_AMOUNT REAL NOT NULL,
+		CURRENCY      TEXT NOT NULL DEFAULT '',
+		START_DATE    TEXT NOT NULL,
+		DEADLINE      TEXT NOT NULL,
+		CREATE_TIME   TEXT NOT NULL,
+		MODIFY_TIME   TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + PayeeTableName + ` (
+		ID          TEXT NOT NULL PRIMARY KEY,
+		OWNER_ID    TEXT NOT NULL DEFAULT '000000000000000000000000',
+		NAME        TEXT NOT NULL,
+		CREATE_TIME TEXT NOT NULL,
+		MODIFY_TIME TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + PayeeRuleTableName + ` (
+		PAYEE_ID   TEXT NOT NULL,
+		RULE_NO    INTEGER NOT NULL,
+		MATCH_TYPE TEXT NOT NULL,
+		PATTERN    TEXT NOT NULL,
+		PRIMARY KEY (PAYEE_ID, RULE_NO)
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + CategoryRuleTableName + ` (
+		ID          TEXT NOT NULL PRIMARY KEY,
+		OWNER_ID    TEXT NOT NULL DEFAULT '000000000000000000000000',
+		NAME        TEXT NOT NULL,
+		PRIORITY    INTEGER NOT NULL DEFAULT 0,
+		FLOW_TYPE   TEXT NOT NULL DEFAULT '',
+		MATCH_TYPE  TEXT NOT NULL DEFAULT '',
+		PATTERN     TEXT NOT NULL DEFAULT '',
+		MIN_AMOUNT  REAL NOT NULL DEFAULT 0,
+		MAX_AMOUNT  REAL NOT NULL DEFAULT 0,
+		PAYEE_ID    TEXT NOT NULL DEFAULT '000000000000000000000000',
+		ACCOUNT_ID  TEXT NOT NULL DEFAULT '000000000000000000000000',
+		CATEGORY_ID TEXT NOT NULL,
+		TAGS        TEXT NOT NULL DEFAULT '',
+		CREATE_TIME TEXT NOT NULL,
+		MODIFY_TIME TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + UserTableName + ` (
+		ID            TEXT NOT NULL PRIMARY KEY,
+		USERNAME      TEXT NOT NULL UNIQUE,
+		PASSWORD_HASH TEXT NOT NULL,
+		CREATE_TIME   TEXT NOT NULL,
+		MODIFY_TIME   TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + ApiTokenTableName + ` (
+		ID          TEXT NOT NULL PRIMARY KEY,
+		USER_ID     TEXT NOT NULL,
+		NAME        TEXT NOT NULL,
+		TOKEN_HASH  TEXT NOT NULL UNIQUE,
+		CREATE_TIME TEXT NOT NULL,
+		MODIFY_TIME TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + LedgerTableName + ` (
+		ID          TEXT NOT NULL PRIMARY KEY,
+		NAME        TEXT NOT NULL,
+		CREATE_TIME TEXT NOT NULL,
+		MODIFY_TIME TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + LedgerMemberTableName + ` (
+		ID          TEXT NOT NULL PRIMARY KEY,
+		LEDGER_ID   TEXT NOT NULL,
+		USER_ID     TEXT NOT NULL,
+		ROLE        TEXT NOT NULL,
+		CREATE_TIME TEXT NOT NULL,
+		MODIFY_TIME TEXT NOT NULL,
+		UNIQUE (LEDGER_ID, USER_ID)
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + LedgerInvitationTableName + ` (
+		ID          TEXT NOT NULL PRIMARY KEY,
+		LEDGER_ID   TEXT NOT NULL,
+		ROLE        TEXT NOT NULL,
+		TOKEN_HASH  TEXT NOT NULL UNIQUE,
+		INVITED_BY  TEXT NOT NULL,
+		EXPIRE_TIME TEXT NOT NULL,
+		CREATE_TIME TEXT NOT NULL,
+		MODIFY_TIME TEXT NOT NULL
+	)`,
+}
+
+// sqliteAddedColumns are added to database files created before the column existed
+var sqliteAddedColumns = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{CashFlowTableName, "ACCOUNT_ID", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+	{CashFlowTableName, "LINKED_ID", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+	{CashFlowTableName, "CURRENCY", "TEXT NOT NULL DEFAULT ''"},
+	{CashFlowTableName, "PAYEE_ID", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+	{AccountTableName, "CURRENCY", "TEXT NOT NULL DEFAULT ''"},
+	{CategoryTableName, "OWNER_ID", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+	{CashFlowTableName, "OWNER_ID", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+	{BudgetTableName, "OWNER_ID", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+	{CashFlowTableName, "CREATED_BY", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+	{CashFlowTableName, "MODIFIED_BY", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+	{AccountTableName, "OWNER_ID", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+	{ExchangeRateTableName, "OWNER_ID", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+	{RecurringRuleTableName, "OWNER_ID", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+	{GoalTableName, "OWNER_ID", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+	{PayeeTableName, "OWNER_ID", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+	{CategoryRuleTableName, "OWNER_ID", "TEXT NOT NULL DEFAULT '000000000000000000000000'"},
+}
+
+// GetSqliteConnection opens the database file on first use and creates the schema.
+// The connection stays open for the lifetime of the application.
+func GetSqliteConnection() *sql.DB {
+	sqliteOnce.Do(openSqliteConnection)
+	return sqliteConnection
+}
+
+func openSqliteConnection() {
+	sqlitePath := util.GetConfigByKey("db.sqlite.path")
+	if sqlitePath == "" {
+		log.Fatal("environment value 'SQLITE_PATH' not set")
+	}
+
+	connection, err := sql.Open("sqlite", sqlitePath)
+	if err != nil {
+		log.Fatal("Failed to open SQLite database:", err)
+	}
+	// SQLite allows a single writer, serialize access instead of failing with SQLITE_BUSY
+	connection.SetMaxOpenConns(1)
+
+	for _, statement := range sqliteSchema {
+		if _, err := connection.Exec(statement); err != nil {
+			log.Fatal("Failed to create SQLite schema:", err)
+		}
+	}
+	for _, addedColumn := range sqliteAddedColumns {
+		if err := addSqliteColumnIfMissing(connection, addedColumn.table, addedColumn.column, addedColumn.definition); err != nil {
+			log.Fatal("Failed to upgrade SQLite schema:", err)
+		}
+	}
+
+	sqliteConnection = connection
+	util.Logger.Debugln("sqlite connection created: ", sqlitePath)
+}
+
+func addSqliteColumnIfMissing(connection *sql.DB, table, column, definition string) error {
+	rows, err := connection.Query(`SELECT NAME FROM PRAGMA_TABLE_INFO(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var existColumn string
+		if err := rows.Scan(&existColumn); err != nil {
+			return err
+		}
+		if strings.EqualFold(existColumn, column) {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = connection.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column + ` ` + definition)
+	return err
+}
+
+// ShutdownSqliteConnection closes the database file (called only on application shutdown)
+func ShutdownSqliteConnection() {
+	if sqliteConnection == nil {
+		return
+	}
+	if err := sqliteConnection.Close(); err != nil {
+		util.Logger.Errorw("close sqlite connection failed", "error", err)
+	}
+}
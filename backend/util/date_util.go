@@ -2,12 +2,14 @@ package util
 
 import (
 	"reflect"
+	"strings"
 	"time"
 )
 
 var (
 	defaultDateFormatInString  = "20060102"
 	dateFormatInStringWithDash = "2006-01-02"
+	dateTimeFormatInString     = "2006-01-02 15:04:05"
 )
 
 func FormatDateFromStringWithoutDash(dateString string) time.Time {
@@ -18,6 +20,12 @@ func FormatDateFromStringWithDash(dateString string) time.Time {
 	return formatDateFromString(dateString, dateFormatInStringWithDash)
 }
 
+// FormatDateFromStringWithOptionalDash accepts both YYYYMMDD and YYYY-MM-DD,
+// the two formats validation.ValidateDate allows.
+func FormatDateFromStringWithOptionalDash(dateString string) time.Time {
+	return formatDateFromString(strings.ReplaceAll(dateString, "-", ""), defaultDateFormatInString)
+}
+
 func formatDateFromString(dateString, format string) time.Time {
 	date, err := time.Parse(format, dateString)
 	if err != nil {
@@ -26,6 +34,15 @@ func formatDateFromString(dateString, format string) time.Time {
 	return date
 }
 
+// FormatDateTimeFromString parses date-time columns returned by the database driver,
+// falling back to the date-only layout for DATE columns.
+func FormatDateTimeFromString(dateTimeString string) time.Time {
+	if dateTime, err := time.Parse(dateTimeFormatInString, dateTimeString); err == nil {
+		return dateTime
+	}
+	return formatDateFromString(dateTimeString, dateFormatInStringWithDash)
+}
+
 func FormatDateToStringWithoutDash(date time.Time) string {
 	return formatDateToString(date, defaultDateFormatInString)
 }
@@ -34,6 +51,11 @@ func FormatDateToStringWithDash(date time.Time) string {
 	return formatDateToString(date, dateFormatInStringWithDash)
 }
 
+// FormatDateTimeToString formats a date-time the way FormatDateTimeFromString parses it
+func FormatDateTimeToString(dateTime time.Time) string {
+	return formatDateToString(dateTime, dateTimeFormatInString)
+}
+
 func formatDateToString(date time.Time, format string) string {
 	return date.Format(format)
 }
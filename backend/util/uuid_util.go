@@ -9,3 +9,12 @@ func Convert2ObjectId(plainId string) primitive.ObjectID {
 	}
 	return objectId
 }
+
+// ConvertOwner2ObjectId turns the plain id of a record's owner into an ObjectID,
+// blank stands for the records no user owns and gives the nil ObjectID without a warning.
+func ConvertOwner2ObjectId(ownerPlainId string) primitive.ObjectID {
+	if ownerPlainId == "" {
+		return primitive.NilObjectID
+	}
+	return Convert2ObjectId(ownerPlainId)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/macar-x/cashlens/errors"
+	"github.com/shopspring/decimal"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -81,13 +82,16 @@ func ValidateDateRange(fromStr, toStr string) error {
 	return nil
 }
 
+// maxAmount is the largest amount accepted, it fits the DECIMAL(15,2) columns
+var maxAmount = decimal.RequireFromString("999999999.99")
+
 // ValidateAmount validates monetary amount (must be positive)
-func ValidateAmount(amount float64) error {
-	if amount <= 0 {
+func ValidateAmount(amount decimal.Decimal) error {
+	if !amount.IsPositive() {
 		return NewValidationError("amount", "must be positive")
 	}
 
-	if amount > 999999999.99 {
+	if amount.GreaterThan(maxAmount) {
 		return NewValidationError("amount", "exceeds maximum allowed value")
 	}
 
@@ -130,6 +134,232 @@ func ValidateCategoryName(name string) error {
 	return nil
 }
 
+// ValidateAccountName validates account name
+func ValidateAccountName(name string) error {
+	if name == "" {
+		return NewValidationError("account", "cannot be empty")
+	}
+
+	if len(name) > 100 {
+		return NewValidationError("account", "name too long (max 100 characters)")
+	}
+
+	// Same character set as category names
+	if matched, _ := regexp.MatchString(`^[a-zA-Z0-9\s\-_&]+$`, name); !matched {
+		return NewValidationError("account", "contains invalid characters")
+	}
+
+	return nil
+}
+
+// ValidateAccountType validates account type (BANK, CARD, CASH or OTHER)
+func ValidateAccountType(accountType string) error {
+	switch accountType {
+	case "BANK", "CARD", "CASH", "OTHER":
+		return nil
+	}
+	return NewValidationError("account_type", "must be BANK, CARD, CASH or OTHER")
+}
+
+// ValidateCurrency validates an ISO 4217 style currency code (three upper-case letters)
+func ValidateCurrency(currency string) error {
+	if matched, _ := regexp.MatchString(`^[A-Z]{3}$`, currency); !matched {
+		return NewValidationError("currency", "must be a three-letter code like USD")
+	}
+
+	return nil
+}
+
+// ValidateExchangeRate validates the amount of one currency bought by another
+func ValidateExchangeRate(rate decimal.Decimal) error {
+	if !rate.IsPositive() {
+		return NewValidationError("rate", "must be positive")
+	}
+
+	return nil
+}
+
+// ValidateRecurringRuleName validates recurring rule name
+func ValidateRecurringRuleName(name string) error {
+	if name == "" {
+		return NewValidationError("recurring_rule", "cannot be empty")
+	}
+
+	if len(name) > 100 {
+		return NewValidationError("recurring_rule", "name too long (max 100 characters)")
+	}
+
+	// Same character set as category names
+	if matched, _ := regexp.MatchString(`^[a-zA-Z0-9\s\-_&]+$`, name); !matched {
+		return NewValidationError("recurring_rule", "contains invalid characters")
+	}
+
+	return nil
+}
+
+// ValidateFrequency validates how often a recurring rule repeats
+// (DAILY, WEEKLY, MONTHLY or LAST_BUSINESS_DAY), MONTHLY also needs a day of month
+func ValidateFrequency(frequency string, dayOfMonth int) error {
+	switch frequency {
+	case "DAILY", "WEEKLY", "LAST_BUSINESS_DAY":
+		return nil
+	case "MONTHLY":
+		if dayOfMonth < 1 || dayOfMonth > 31 {
+			return NewValidationError("day_of_month", "must be between 1 and 31")
+		}
+		return nil
+	}
+	return NewValidationError("frequency", "must be DAILY, WEEKLY, MONTHLY or LAST_BUSINESS_DAY")
+}
+
+// ValidateOccurrenceLimit validates how many times a recurring rule is booked, zero means unlimited
+func ValidateOccurrenceLimit(occurrenceLimit int) error {
+	if occurrenceLimit < 0 {
+		return NewValidationError("occurrence_limit", "cannot be negative")
+	}
+
+	return nil
+}
+
+// ValidateBudgetPeriod validates how long one budget limit lasts (MONTHLY or YEARLY)
+func ValidateBudgetPeriod(period string) error {
+	if period != "MONTHLY" && period != "YEARLY" {
+		return NewValidationError("period", "must be MONTHLY or YEARLY")
+	}
+
+	return nil
+}
+
+// ValidateGoalName validates savings goal name
+func ValidateGoalName(name string) error {
+	if name == "" {
+		return NewValidationError("goal", "cannot be empty")
+	}
+
+	if len(name) > 100 {
+		return NewValidationError("goal", "name too long (max 100 characters)")
+	}
+
+	// Same character set as category names
+	if matched, _ := regexp.MatchString(`^[a-zA-Z0-9\s\-_&]+$`, name); !matched {
+		return NewValidationError("goal", "contains invalid characters")
+	}
+
+	return nil
+}
+
+// ValidatePayeeName validates payee name
+func ValidatePayeeName(name string) error {
+	if name == "" {
+		return NewValidationError("payee", "cannot be empty")
+	}
+
+	if len(name) > 100 {
+		return NewValidationError("payee", "name too long (max 100 characters)")
+	}
+
+	// Same character set as category names
+	if matched, _ := regexp.MatchString(`^[a-zA-Z0-9\s\-_&]+$`, name); !matched {
+		return NewValidationError("payee", "contains invalid characters")
+	}
+
+	return nil
+}
+
+// ValidatePayeeRule validates one payee normalization rule (CONTAINS or REGEX), a REGEX pattern has to compile
+func ValidatePayeeRule(matchType, pattern string) error {
+	if matchType != "CONTAINS" && matchType != "REGEX" {
+		return NewValidationError("match_type", "must be CONTAINS or REGEX")
+	}
+
+	if pattern == "" {
+		return NewValidationError("pattern", "cannot be empty")
+	}
+
+	if len(pattern) > 200 {
+		return NewValidationError("pattern", "too long (max 200 characters)")
+	}
+
+	if matchType == "REGEX" {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return NewValidationError("pattern", "invalid regular expression")
+		}
+	}
+
+	return nil
+}
+
+// ValidateCategoryRuleName validates categorization rule name
+func ValidateCategoryRuleName(name string) error {
+	if name == "" {
+		return NewValidationError("category_rule", "cannot be empty")
+	}
+
+	if len(name) > 100 {
+		return NewValidationError("category_rule", "name too long (max 100 characters)")
+	}
+
+	// Same character set as category names
+	if matched, _ := regexp.MatchString(`^[a-zA-Z0-9\s\-_&]+$`, name); !matched {
+		return NewValidationError("category_rule", "contains invalid characters")
+	}
+
+	return nil
+}
+
+// ValidateAmountRange validates the bounds of an amount condition, zero leaves that side open
+func ValidateAmountRange(minAmount, maxAmount decimal.Decimal) error {
+	if minAmount.IsNegative() || maxAmount.IsNegative() {
+		return NewValidationError("amount_range", "bounds cannot be negative")
+	}
+
+	if !maxAmount.IsZero() && maxAmount.LessThan(minAmount) {
+		return NewValidationError("amount_range", "max amount is below min amount")
+	}
+
+	return nil
+}
+
+// ValidateTag validates one cash flow tag, expected lowercase already
+func ValidateTag(tag string) error {
+	if tag == "" {
+		return NewValidationError("tag", "cannot be empty")
+	}
+
+	if len(tag) > 32 {
+		return NewValidationError("tag", "too long (max 32 characters)")
+	}
+
+	// No comma or space, so tags can be passed as a comma separated list
+	if matched, _ := regexp.MatchString(`^[a-z0-9][a-z0-9\-_]*$`, tag); !matched {
+		return NewValidationError("tag", "must be letters, digits, '-' or '_'")
+	}
+
+	return nil
+}
+
+// ValidateSplitLines validates the line amounts of a split cash flow against its total
+func ValidateSplitLines(total decimal.Decimal, lineAmounts []decimal.Decimal) error {
+	if len(lineAmounts) < 2 {
+		return NewValidationError("split", "needs at least two lines")
+	}
+
+	lineTotal := decimal.Zero
+	for _, lineAmount := range lineAmounts {
+		if err := ValidateAmount(lineAmount); err != nil {
+			return NewValidationError("split", "every line amount must be positive")
+		}
+		lineTotal = lineTotal.Add(lineAmount)
+	}
+
+	if !lineTotal.Equal(total) {
+		return NewValidationError("split",
+			fmt.Sprintf("lines add up to %s instead of %s", lineTotal.StringFixed(2), total.StringFixed(2)))
+	}
+
+	return nil
+}
+
 // ValidateDescription validates description text
 func ValidateDescription(desc string) error {
 	if len(desc) > 500 {
@@ -139,10 +369,58 @@ func ValidateDescription(desc string) error {
 	return nil
 }
 
-// ValidateFlowType validates flow type (INCOME or OUTCOME)
+// ValidateFlowType validates flow type (INCOME, OUTCOME or TRANSFER)
 func ValidateFlowType(flowType string) error {
-	if flowType != "INCOME" && flowType != "OUTCOME" {
-		return NewValidationError("flow_type", "must be INCOME or OUTCOME")
+	if flowType != "INCOME" && flowType != "OUTCOME" && flowType != "TRANSFER" {
+		return NewValidationError("flow_type", "must be INCOME, OUTCOME or TRANSFER")
+	}
+
+	return nil
+}
+
+// ValidateUsername validates a login name: 3 to 50 lower-case letters, digits, dots, dashes or underscores
+func ValidateUsername(username string) error {
+	if len(username) < 3 || len(username) > 50 {
+		return NewValidationError("username", "must be 3 to 50 characters")
+	}
+
+	if matched, _ := regexp.MatchString(`^[a-z0-9._-]+$`, username); !matched {
+		return NewValidationError("username", "may only contain lower-case letters, digits, '.', '-' and '_'")
+	}
+
+	return nil
+}
+
+// ValidatePassword validates a new password, bcrypt only reads the first 72 bytes
+func ValidatePassword(password string) error {
+	if len(password) < 8 {
+		return NewValidationError("password", "too short (min 8 characters)")
+	}
+
+	if len(password) > 72 {
+		return NewValidationError("password", "too long (max 72 bytes)")
+	}
+
+	return nil
+}
+
+// ValidateLedgerName validates the name of a shared ledger
+func ValidateLedgerName(name string) error {
+	if name == "" {
+		return NewValidationError("ledger", "cannot be empty")
+	}
+
+	if len(name) > 100 {
+		return NewValidationError("ledger", "name too long (max 100 characters)")
+	}
+
+	return nil
+}
+
+// ValidateLedgerRole validates a member's role in a shared ledger
+func ValidateLedgerRole(role string) error {
+	if role != "OWNER" && role != "EDITOR" && role != "VIEWER" {
+		return NewValidationError("role", "must be OWNER, EDITOR or VIEWER")
 	}
 
 	return nil
@@ -1,7 +1,10 @@
 package validation
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/shopspring/decimal"
 )
 
 func TestValidateDate(t *testing.T) {
@@ -58,20 +61,21 @@ func TestValidateDateRange(t *testing.T) {
 func TestValidateAmount(t *testing.T) {
 	tests := []struct {
 		name    string
-		amount  float64
+		amount  string
 		wantErr bool
 	}{
-		{"Valid amount", 100.50, false},
-		{"Zero amount", 0, true},
-		{"Negative amount", -50.00, true},
-		{"Very large amount", 1000000000.00, true},
-		{"Small positive", 0.01, false},
-		{"Maximum valid", 999999999.99, false},
+		{"Valid amount", "100.50", false},
+		{"Zero amount", "0", true},
+		{"Negative amount", "-50.00", true},
+		{"Very large amount", "1000000000.00", true},
+		{"Small positive", "0.01", false},
+		{"Maximum valid", "999999999.99", false},
+		{"Just over maximum", "999999999.991", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := ValidateAmount(tt.amount)
+			err := ValidateAmount(decimal.RequireFromString(tt.amount))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ValidateAmount() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -127,6 +131,217 @@ func TestValidateCategoryName(t *testing.T) {
 	}
 }
 
+func TestValidateAccountName(t *testing.T) {
+	tests := []struct {
+		name    string
+		account string
+		wantErr bool
+	}{
+		{"Valid name", "Main Bank", false},
+		{"Valid with dash", "Visa-Card", false},
+		{"Empty name", "", true},
+		{"Too long", string(make([]byte, 101)), true},
+		{"Invalid characters", "Wallet@Home", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAccountName(tt.account)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAccountName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAccountType(t *testing.T) {
+	tests := []struct {
+		name        string
+		accountType string
+		wantErr     bool
+	}{
+		{"Valid BANK", "BANK", false},
+		{"Valid CARD", "CARD", false},
+		{"Valid CASH", "CASH", false},
+		{"Valid OTHER", "OTHER", false},
+		{"Invalid lowercase", "bank", true},
+		{"Empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAccountType(tt.accountType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAccountType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateFrequency(t *testing.T) {
+	tests := []struct {
+		name       string
+		frequency  string
+		dayOfMonth int
+		wantErr    bool
+	}{
+		{"Valid DAILY", "DAILY", 0, false},
+		{"Valid WEEKLY", "WEEKLY", 0, false},
+		{"Valid LAST_BUSINESS_DAY", "LAST_BUSINESS_DAY", 0, false},
+		{"Valid MONTHLY", "MONTHLY", 31, false},
+		{"MONTHLY without day", "MONTHLY", 0, true},
+		{"MONTHLY day out of range", "MONTHLY", 32, true},
+		{"Invalid lowercase", "daily", 0, true},
+		{"Empty", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFrequency(tt.frequency, tt.dayOfMonth)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFrequency() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBudgetPeriod(t *testing.T) {
+	tests := []struct {
+		name    string
+		period  string
+		wantErr bool
+	}{
+		{"Valid MONTHLY", "MONTHLY", false},
+		{"Valid YEARLY", "YEARLY", false},
+		{"Invalid WEEKLY", "WEEKLY", true},
+		{"Invalid lowercase", "monthly", true},
+		{"Empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBudgetPeriod(tt.period)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBudgetPeriod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePayeeRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		matchType string
+		pattern   string
+		wantErr   bool
+	}{
+		{"Valid CONTAINS", "CONTAINS", "starbucks", false},
+		{"Valid REGEX", "REGEX", `(?i)^starbucks\b`, false},
+		{"Invalid REGEX", "REGEX", "starbucks(", true},
+		{"Unknown match type", "PREFIX", "starbucks", true},
+		{"Lowercase match type", "contains", "starbucks", true},
+		{"Empty pattern", "CONTAINS", "", true},
+		{"Too long", "CONTAINS", strings.Repeat("a", 201), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePayeeRule(tt.matchType, tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePayeeRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAmountRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		minAmount decimal.Decimal
+		maxAmount decimal.Decimal
+		wantErr   bool
+	}{
+		{"Open range", decimal.Zero, decimal.Zero, false},
+		{"Min only", decimal.NewFromInt(10), decimal.Zero, false},
+		{"Max only", decimal.Zero, decimal.NewFromInt(10), false},
+		{"Both bounds", decimal.NewFromInt(10), decimal.NewFromInt(50), false},
+		{"Single amount", decimal.NewFromInt(10), decimal.NewFromInt(10), false},
+		{"Max below min", decimal.NewFromInt(50), decimal.NewFromInt(10), true},
+		{"Negative min", decimal.NewFromInt(-1), decimal.Zero, true},
+		{"Negative max", decimal.Zero, decimal.NewFromInt(-1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAmountRange(tt.minAmount, tt.maxAmount)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAmountRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		wantErr bool
+	}{
+		{"Valid tag", "trip-japan", false},
+		{"Valid with underscore and digits", "q3_2024", false},
+		{"Empty", "", true},
+		{"Uppercase", "Trip", true},
+		{"Leading dash", "-trip", true},
+		{"Comma", "trip,japan", true},
+		{"Space", "trip japan", true},
+		{"Too long", strings.Repeat("a", 33), true},
+		{"Maximum length", strings.Repeat("a", 32), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTag(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSplitLines(t *testing.T) {
+	amounts := func(values ...string) []decimal.Decimal {
+		var amountList []decimal.Decimal
+		for _, value := range values {
+			amountList = append(amountList, decimal.RequireFromString(value))
+		}
+		return amountList
+	}
+
+	tests := []struct {
+		name        string
+		total       string
+		lineAmounts []decimal.Decimal
+		wantErr     bool
+	}{
+		{"Lines add up", "42.50", amounts("30.00", "10.50", "2.00"), false},
+		{"Lines short by a cent", "42.50", amounts("30.00", "12.49"), true},
+		{"Lines over the total", "42.50", amounts("30.00", "13.00"), true},
+		{"Single line", "42.50", amounts("42.50"), true},
+		{"No lines", "42.50", nil, true},
+		{"Zero line", "42.50", amounts("42.50", "0"), true},
+		{"Negative line", "42.50", amounts("50.00", "-7.50"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSplitLines(decimal.RequireFromString(tt.total), tt.lineAmounts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSplitLines() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateDescription(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -157,6 +372,7 @@ func TestValidateFlowType(t *testing.T) {
 	}{
 		{"Valid INCOME", "INCOME", false},
 		{"Valid OUTCOME", "OUTCOME", false},
+		{"Valid TRANSFER", "TRANSFER", false},
 		{"Invalid lowercase", "income", true},
 		{"Invalid type", "EXPENSE", true},
 		{"Empty type", "", true},
@@ -171,3 +387,92 @@ func TestValidateFlowType(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		wantErr  bool
+	}{
+		{"Valid name", "alice", false},
+		{"Valid with dot and dash", "alice.w-2", false},
+		{"Too short", "al", true},
+		{"Too long", strings.Repeat("a", 51), true},
+		{"Upper case", "Alice", true},
+		{"Space", "alice w", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUsername(tt.username)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUsername() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{"Valid password", "correct horse", false},
+		{"Too short", "1234567", true},
+		{"Too long for bcrypt", strings.Repeat("x", 73), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePassword(tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLedgerName(t *testing.T) {
+	tests := []struct {
+		name       string
+		ledgerName string
+		wantErr    bool
+	}{
+		{"Valid name", "Smith's household", false},
+		{"Empty", "", true},
+		{"Too long", strings.Repeat("a", 101), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLedgerName(tt.ledgerName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLedgerName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLedgerRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		wantErr bool
+	}{
+		{"Owner", "OWNER", false},
+		{"Editor", "EDITOR", false},
+		{"Viewer", "VIEWER", false},
+		{"Lower case", "viewer", true},
+		{"Unknown", "ADMIN", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLedgerRole(tt.role)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLedgerRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -26,6 +26,16 @@ class ApiEndpoints {
   static const String backup = '/api/backup';
   static const String restore = '/api/restore';
   
+  static const String authRegister = '/api/auth/register';
+  static const String authLogin = '/api/auth/login';
+  static const String authMe = '/api/auth/me';
+  static const String authTokens = '/api/auth/tokens';
+
+  static const String ledgers = '/api/ledgers';
+  static const String ledgerAcceptInvitation = '/api/ledgers/invitations/accept';
+  // Sent with every request to work on a shared ledger, the personal one when left out
+  static const String ledgerHeader = 'X-Ledger-Id';
+
   static const String health = '/api/health';
   static const String version = '/api/version';
   static const String config = '/api/config';